@@ -36,6 +36,20 @@ const (
 	// reconciler for that resource. This condition indicates the state of the
 	// reconciler.
 	ResourceGraphDefinitionConditionTypeReconcilerReady ConditionType = "ReconcilerReady"
+	// ResourceGraphDefinitionConditionTypeSchemaCompatible indicates whether the
+	// schema of a ResourceGraphDefinition is compatible with the schema served
+	// by its CustomResourceDefinition. It is only set when breaking changes
+	// prevent the CustomResourceDefinition from being updated.
+	ResourceGraphDefinitionConditionTypeSchemaCompatible ConditionType = "SchemaCompatible"
+	// ResourceGraphDefinitionConditionTypeInstancesReconciled reports the
+	// progress of the rollout of a generation of a ResourceGraphDefinition:
+	// the number of its instances reconciled with the graph of the generation.
+	ResourceGraphDefinitionConditionTypeInstancesReconciled ConditionType = "InstancesReconciled"
+	// ResourceGraphDefinitionConditionTypeWatchesHealthy indicates whether kro
+	// can watch the instances of a ResourceGraphDefinition and the resources
+	// they depend on. It is False while a watch fails, e.g because a CRD was
+	// deleted or kro isn't allowed to list a resource anymore.
+	ResourceGraphDefinitionConditionTypeWatchesHealthy ConditionType = "WatchesHealthy"
 )
 
 const (
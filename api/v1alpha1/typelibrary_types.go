@@ -0,0 +1,55 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ResourceGraphTypeLibrarySpec defines the desired state of ResourceGraphTypeLibrary
+type ResourceGraphTypeLibrarySpec struct {
+	// Types is a map of custom types that can be referenced by name in the
+	// schema of every ResourceGraphDefinition. Each type is an object adhering
+	// to the SimpleSchema spec.
+	//
+	// +kubebuilder:validation:Required
+	Types runtime.RawExtension `json:"types,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="AGE",type="date",priority=0,JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:shortName=rgtl,scope=Cluster
+
+// ResourceGraphTypeLibrary is the Schema for the resourcegraphtypelibraries API.
+// It declares custom types shared by all the ResourceGraphDefinitions.
+type ResourceGraphTypeLibrary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ResourceGraphTypeLibrarySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ResourceGraphTypeLibraryList contains a list of ResourceGraphTypeLibrary
+type ResourceGraphTypeLibraryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceGraphTypeLibrary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ResourceGraphTypeLibrary{}, &ResourceGraphTypeLibraryList{})
+}
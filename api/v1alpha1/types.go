@@ -22,6 +22,9 @@ const (
 	// DefaultServiceAccountKey is the key to use for the default service account
 	// in the serviceAccounts map.
 	DefaultServiceAccountKey = "*"
+	// DefaultKubeconfigKey is the default key of the kubeconfig in the Secret
+	// of a target cluster.
+	DefaultKubeconfigKey = "kubeconfig"
 )
 
 // ResourceGraphDefinitionSpec defines the desired state of ResourceGraphDefinition
@@ -43,6 +46,303 @@ type ResourceGraphDefinitionSpec struct {
 	//
 	// +kubebuilder:validation:Optional
 	DefaultServiceAccounts map[string]string `json:"defaultServiceAccounts,omitempty"`
+	// ServiceAccount is the service account kro impersonates to create,
+	// update and delete the resources of every instance, whatever its
+	// namespace, so that the resource graph definition is limited to the
+	// permissions of this service account rather than those of kro. It
+	// can't be set along with defaultServiceAccounts.
+	//
+	// +kubebuilder:validation:Optional
+	ServiceAccount *ServiceAccountReference `json:"serviceAccount,omitempty"`
+	// Retry is the policy of the retries of the instances, while they wait
+	// for their resources or after a reconciliation error. By default, kro
+	// retries every few seconds, and backs off exponentially on errors.
+	//
+	// +kubebuilder:validation:Optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// Reconcile configures how often, how concurrently and with which client
+	// rate limits the instances are reconciled.
+	//
+	// +kubebuilder:validation:Optional
+	Reconcile *ReconcilePolicy `json:"reconcile,omitempty"`
+	// TTL deletes the instances, along with their resources, once they've
+	// been ready or failed for a while, e.g for preview environments or
+	// one-shot provisioning. The instances can set their own with the
+	// kro.run/ttl-after-ready and kro.run/ttl-after-failure annotations.
+	//
+	// +kubebuilder:validation:Optional
+	TTL *TTLPolicy `json:"ttl,omitempty"`
+	// Quota limits the number of instances, and the number of resources each
+	// of them manages, so that the teams creating instances can't exhaust
+	// the cluster. The instances exceeding it are rejected.
+	//
+	// +kubebuilder:validation:Optional
+	Quota *QuotaPolicy `json:"quota,omitempty"`
+	// Watch restricts the instances, and the objects they refer to, watched
+	// by kro, so that its controller doesn't cache every object of their kinds
+	// in the cluster.
+	//
+	// +kubebuilder:validation:Optional
+	Watch *WatchPolicy `json:"watch,omitempty"`
+	// RollbackOnFailure rolls the resources of an instance back to the last
+	// generation of the instance whose resources all became ready, when the
+	// resources of a new generation don't become ready within their readiness
+	// timeout. The last ready resources are recorded in a Secret owned by the
+	// instance, in its namespace, so it's not supported for cluster-scoped
+	// instances.
+	//
+	// +kubebuilder:validation:Optional
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
+	// Hooks are run around the resources of the instances: the preApply
+	// hooks before any resource is applied, and the postApply hooks once all
+	// the resources are ready.
+	//
+	// +kubebuilder:validation:Optional
+	Hooks *Hooks `json:"hooks,omitempty"`
+	// NamespaceSelector restricts the namespaces the instances are honored in
+	// by their labels. The instances created in other namespaces aren't
+	// reconciled, and are reported as rejected. It can't be set for
+	// cluster-scoped instances.
+	//
+	// +kubebuilder:validation:Optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Target is the cluster the resources are applied to and watched in,
+	// unless they set their own. By default, they're applied to the cluster
+	// kro runs in.
+	//
+	// +kubebuilder:validation:Optional
+	Target *Target `json:"target,omitempty"`
+	// ValuesFrom loads the keys of ConfigMaps and Secrets into the values
+	// variable of the expressions, e.g `${values.region}`, for the settings
+	// shared by the instances of an environment rather than set in the spec
+	// of each of them. The sources are merged in order, the keys of a source
+	// overriding those of the previous ones. The instances are reconciled
+	// again whenever a source changes.
+	//
+	// +kubebuilder:validation:Optional
+	ValuesFrom []ValuesSource `json:"valuesFrom,omitempty"`
+	// Locals are computed once per reconciliation of an instance, before its
+	// resources, and are available to the expressions as the locals variable,
+	// e.g `${locals.fullName}`, so that the expressions shared by several
+	// resources are only written once.
+	//
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	Locals []Local `json:"locals,omitempty"`
+}
+
+// Local is a named expression computed from the instance.
+type Local struct {
+	// Name is the name of the local in the locals variable, e.g `fullName`.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	Name string `json:"name"`
+	// Expression is a standalone expression computing the local, e.g
+	// `${schema.metadata.name + '-' + schema.spec.env}`. It can refer to the
+	// instance, the cluster, the values and the locals declared before it,
+	// but not to the resources.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression"`
+}
+
+// ValuesSource is a ConfigMap or a Secret whose keys are loaded into the
+// values variable. The keys of a Secret are decoded.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.configMapRef) != has(self.secretRef)",message="exactly one of configMapRef and secretRef must be set"
+type ValuesSource struct {
+	// ConfigMapRef refers to a ConfigMap.
+	//
+	// +kubebuilder:validation:Optional
+	ConfigMapRef *ValuesReference `json:"configMapRef,omitempty"`
+	// SecretRef refers to a Secret.
+	//
+	// +kubebuilder:validation:Optional
+	SecretRef *ValuesReference `json:"secretRef,omitempty"`
+	// Optional sources are skipped while they don't exist. The instances
+	// wait for the sources which aren't optional.
+	//
+	// +kubebuilder:validation:Optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// ValuesReference refers to the ConfigMap or Secret of a values source.
+type ValuesReference struct {
+	// Name is the name of the object.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace is the namespace of the object. Defaults to the namespace of
+	// the instance, so that each namespace can hold its own values. It's
+	// required for cluster-scoped instances.
+	//
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Hooks are run around the apply of resources, e.g a Job migrating a database
+// before a Deployment is updated.
+type Hooks struct {
+	// PreApply hooks must succeed before the resources are applied.
+	//
+	// +kubebuilder:validation:Optional
+	PreApply []*Hook `json:"preApply,omitempty"`
+	// PostApply hooks must succeed once the resources are ready, before the
+	// resources depending on them are applied.
+	//
+	// +kubebuilder:validation:Optional
+	PostApply []*Hook `json:"postApply,omitempty"`
+}
+
+// Hook is either a CEL assertion that must evaluate to true, or a Job that
+// must complete successfully.
+type Hook struct {
+	// ID identifies the Job of the hook, which is reconciled like a resource
+	// with this id. It's required for the Job hooks.
+	//
+	// +kubebuilder:validation:Optional
+	ID string `json:"id,omitempty"`
+	// Assert is a CEL expression evaluating to a boolean, e.g
+	// `${database.status.endpoint != ""}`. It can refer to the instance and
+	// to the resources, like the resource templates.
+	//
+	// +kubebuilder:validation:Optional
+	Assert string `json:"assert,omitempty"`
+	// Job is the template of a batch/v1 Job. It can refer to the instance and
+	// to the resources, like the resource templates.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Job *runtime.RawExtension `json:"job,omitempty"`
+}
+
+// RetryPolicy configures the exponential backoff of the retries of an
+// instance: the delay starts at InitialDelay and doubles with each
+// consecutive retry, up to MaxDelay.
+type RetryPolicy struct {
+	// InitialDelay is the delay before the first retry, e.g `5s`. Defaults to
+	// 5s.
+	//
+	// +kubebuilder:validation:Optional
+	InitialDelay *metav1.Duration `json:"initialDelay,omitempty"`
+	// MaxDelay is the maximum delay between two retries, e.g `10m`. Defaults
+	// to 5m.
+	//
+	// +kubebuilder:validation:Optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+	// MaxAttempts is the number of consecutive retries after which kro stops
+	// retrying, until the instance is reconciled again for another reason,
+	// e.g a change or a resync. Unlimited by default.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+}
+
+// ReconcilePolicy configures how the instances are reconciled.
+type ReconcilePolicy struct {
+	// ResyncPeriod is the interval at which the instances are reconciled,
+	// even if neither them nor their resources changed, e.g `30s` to correct
+	// the drift of resources often changed by other controllers, or `24h`
+	// for static resources. Defaults to the resync period of the controller.
+	//
+	// +kubebuilder:validation:Optional
+	ResyncPeriod *metav1.Duration `json:"resyncPeriod,omitempty"`
+	// Concurrency is the number of workers dedicated to the instances, rather
+	// than the workers shared by the other resource graph definitions, so
+	// that many instances don't hold back the instances of the others.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Concurrency int32 `json:"concurrency,omitempty"`
+	// ClientQPS gives the instances a dedicated client, limited to the given
+	// number of queries per second, rather than the client shared by the
+	// other resource graph definitions.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ClientQPS int32 `json:"clientQPS,omitempty"`
+	// ClientBurst is the burst of the dedicated client. It defaults to
+	// clientQPS, and is only used if clientQPS is set.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ClientBurst int32 `json:"clientBurst,omitempty"`
+}
+
+// QuotaPolicy limits the instances of a resource graph definition. The oldest
+// instances are within the quota, the instances created once it's reached are
+// rejected until older instances are deleted.
+type QuotaPolicy struct {
+	// MaxInstances is the maximum number of instances, across namespaces.
+	// Unlimited by default.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxInstances int32 `json:"maxInstances,omitempty"`
+	// MaxInstancesPerNamespace is the maximum number of instances in each
+	// namespace. Unlimited by default.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxInstancesPerNamespace int32 `json:"maxInstancesPerNamespace,omitempty"`
+	// MaxResourcesPerInstance is the maximum number of resources an instance
+	// manages, counting each item of the collections. External references
+	// aren't counted. Unlimited by default.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxResourcesPerInstance int32 `json:"maxResourcesPerInstance,omitempty"`
+}
+
+// TTLPolicy configures when the instances are deleted.
+type TTLPolicy struct {
+	// AfterReady is the time after which the instances are deleted once
+	// they're ready, e.g `72h`. The instances aren't deleted by default.
+	//
+	// +kubebuilder:validation:Optional
+	AfterReady *metav1.Duration `json:"afterReady,omitempty"`
+	// AfterFailure is the time after which the instances are deleted once
+	// they've failed, e.g `24h`, leaving time to investigate the failure.
+	// The instances aren't deleted by default.
+	//
+	// +kubebuilder:validation:Optional
+	AfterFailure *metav1.Duration `json:"afterFailure,omitempty"`
+}
+
+// ServiceAccountReference refers to a service account.
+type ServiceAccountReference struct {
+	// Namespace is the namespace of the service account.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// Name is the name of the service account.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// WatchPolicy restricts the objects kro watches for the instances of a
+// resource graph definition. Once set, the resources of the instances are only
+// watched through the labels kro sets on them.
+type WatchPolicy struct {
+	// Namespaces are the namespaces the instances, and the namespaced objects
+	// they refer to, are watched in. The instances in other namespaces aren't
+	// reconciled. Defaults to all the namespaces.
+	//
+	// +kubebuilder:validation:Optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector selects the instances reconciled by their labels. Defaults to
+	// all the instances.
+	//
+	// +kubebuilder:validation:Optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 // Schema represents the attributes that define an instance of
@@ -68,6 +368,35 @@ type Schema struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default="kro.run"
 	Group string `json:"group,omitempty"`
+	// The scope of the instances of the resourcegraphdefinition, either
+	// Namespaced or Cluster. Resources of cluster-scoped instances aren't
+	// created in the namespace of the instance, namespaced resources must set
+	// their namespace explicitly.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Namespaced;Cluster
+	// +kubebuilder:default=Namespaced
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="scope is immutable"
+	Scope InstanceScope `json:"scope,omitempty"`
+	// ShortNames are the short names of the generated CRD, e.g `wapp`, which
+	// can be used instead of the kind with kubectl.
+	//
+	// +kubebuilder:validation:Optional
+	ShortNames []string `json:"shortNames,omitempty"`
+	// Categories are the categories the generated CRD belongs to, e.g `all`,
+	// which lets `kubectl get <category>` list the instances.
+	//
+	// +kubebuilder:validation:Optional
+	Categories []string `json:"categories,omitempty"`
+	// Labels are set on the generated CRD. Labels in the kro.run domain are
+	// reserved for kro.
+	//
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are set on the generated CRD.
+	//
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 	// The spec of the resourcegraphdefinition. Typically, this is the spec of
 	// the CRD that the resourcegraphdefinition is managing. This is adhering
 	// to the SimpleSchema spec
@@ -76,26 +405,488 @@ type Schema struct {
 	// that the resourcegraphdefinition is managing. This is adhering to the
 	// SimpleSchema spec.
 	Status runtime.RawExtension `json:"status,omitempty"`
-	// Validation is a list of validation rules that are applied to the
-	// resourcegraphdefinition.
-	// Not implemented yet.
-	Validation []string `json:"validation,omitempty"`
+	// Types is a map of custom types that can be referenced by name in the
+	// spec. Each type is an object adhering to the SimpleSchema spec.
+	Types runtime.RawExtension `json:"types,omitempty"`
+	// Validations is a list of CEL validation rules that are applied to the
+	// spec of the instances. In the expressions, `self` refers to the spec of
+	// the instance, which allows enforcing cross-field invariants, e.g
+	// `self.minReplicas <= self.maxReplicas`.
+	//
+	// +kubebuilder:validation:Optional
+	Validations []Validation `json:"validations,omitempty"`
+	// Versions are additional versions of the instance API, served next to
+	// the version declared by APIVersion. APIVersion remains the storage
+	// version, which kro reconciles, and instances of the additional
+	// versions are converted to and from it.
+	//
+	// +kubebuilder:validation:Optional
+	Versions []SchemaVersion `json:"versions,omitempty"`
+	// Scale maps fields of the instances to the scale subresource of the
+	// generated CRD, which lets HPAs and `kubectl scale` scale instances.
+	//
+	// +kubebuilder:validation:Optional
+	Scale *ScaleSubresource `json:"scale,omitempty"`
+	// Conditions are conditions maintained by kro in the status of the
+	// instances, next to the InstanceSynced condition, e.g DatabaseReady.
+	//
+	// +kubebuilder:validation:Optional
+	Conditions []StatusCondition `json:"conditions,omitempty"`
 }
 
+// StatusCondition is a condition of the instances, whose status is decided by
+// a CEL expression.
+type StatusCondition struct {
+	// Type is the type of the condition, e.g DatabaseReady.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[A-Z][a-zA-Z0-9]*$`
+	Type string `json:"type"`
+	// Expression is a standalone CEL expression evaluating to a boolean, e.g
+	// `${database.status.state == 'available'}`. It can reference the
+	// instance as `schema` and the resources by their id. The condition is
+	// Unknown until the resources it references are created.
+	//
+	// +kubebuilder:validation:Required
+	Expression string `json:"expression"`
+	// Message is the message of the condition when the expression evaluates
+	// to false.
+	//
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// ScaleSubresource maps fields of the instances to the scale subresource.
+type ScaleSubresource struct {
+	// SpecReplicasPath is the path of the desired replicas in the spec of
+	// the instances, e.g `.spec.replicas`. It must be an integer field.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^\.spec\.`
+	SpecReplicasPath string `json:"specReplicasPath"`
+	// StatusReplicasPath is the path of the observed replicas in the status
+	// of the instances, e.g `.status.replicas`. It must be an integer field.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^\.status\.`
+	StatusReplicasPath string `json:"statusReplicasPath"`
+	// LabelSelectorPath is the path of the label selector of the scaled
+	// pods in the status of the instances, e.g `.status.selector`. It is
+	// required by HPAs and must be a string field.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^\.status\.`
+	LabelSelectorPath *string `json:"labelSelectorPath,omitempty"`
+}
+
+// InstanceScope is the scope of the instances of a resourcegraphdefinition.
+type InstanceScope string
+
+const (
+	// InstanceScopeNamespaced is the scope of namespaced instances.
+	InstanceScopeNamespaced InstanceScope = "Namespaced"
+	// InstanceScopeCluster is the scope of cluster-scoped instances.
+	InstanceScopeCluster InstanceScope = "Cluster"
+)
+
+// SchemaVersion is an additional version of the instance API of a
+// resourcegraphdefinition.
+type SchemaVersion struct {
+	// Name is the name of the version, e.g v1alpha1.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^v[0-9]+(alpha[0-9]+|beta[0-9]+)?$`
+	Name string `json:"name"`
+	// The spec of the instances of this version, adhering to the SimpleSchema
+	// spec. It can reference the types of the schema.
+	Spec runtime.RawExtension `json:"spec,omitempty"`
+	// Deprecated marks the version as deprecated, clients using it get a
+	// warning.
+	//
+	// +kubebuilder:validation:Optional
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Conversion declares how the instances of this version are converted to
+	// and from the storage version. Without conversion, only the apiVersion
+	// of the instances is changed.
+	//
+	// +kubebuilder:validation:Optional
+	Conversion *VersionConversion `json:"conversion,omitempty"`
+}
+
+// VersionConversion declares how the spec of instances is converted between
+// a version and the storage version. Renames are applied first, then the
+// expressions.
+type VersionConversion struct {
+	// Renames are the fields renamed between the version and the storage
+	// version.
+	//
+	// +kubebuilder:validation:Optional
+	Renames []FieldRename `json:"renames,omitempty"`
+	// ToStorage are the fields of the storage version computed from the
+	// version.
+	//
+	// +kubebuilder:validation:Optional
+	ToStorage []FieldConversion `json:"toStorage,omitempty"`
+	// FromStorage are the fields of the version computed from the storage
+	// version.
+	//
+	// +kubebuilder:validation:Optional
+	FromStorage []FieldConversion `json:"fromStorage,omitempty"`
+}
+
+// FieldRename is a field renamed between two versions. Paths are relative to
+// the spec, e.g `network.cidr`.
+type FieldRename struct {
+	// From is the path of the field in the version.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from,omitempty"`
+	// To is the path of the field in the storage version.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to,omitempty"`
+}
+
+// FieldConversion computes a field of the converted spec with a CEL
+// expression.
+type FieldConversion struct {
+	// Field is the path of the field to set, relative to the spec of the
+	// converted instance.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Field string `json:"field,omitempty"`
+	// Expression is the CEL expression computing the value of the field. In
+	// the expression, `self` refers to the spec of the instance being
+	// converted. Evaluating to null unsets the field.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Expression string `json:"expression,omitempty"`
+}
+
+// Validation is a CEL validation rule, compiled into an x-kubernetes-validations
+// rule of the generated CRD.
 type Validation struct {
+	// Expression is the CEL expression to evaluate.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	Expression string `json:"expression,omitempty"`
-	Message    string `json:"message,omitempty"`
+	// Message is the message returned to the user when the validation fails.
+	//
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
 }
 
 type Resource struct {
 	// +kubebuilder:validation:Required
 	ID string `json:"id,omitempty"`
-	// +kubebuilder:validation:Required
+	// Template is the object to create. Either a template, an external
+	// reference or a patch must be declared.
+	//
+	// +kubebuilder:validation:Optional
 	Template runtime.RawExtension `json:"template,omitempty"`
+	// ExternalRef refers to an existing object, e.g an instance of another
+	// ResourceGraphDefinition, which is read instead of being created.
+	//
+	// +kubebuilder:validation:Optional
+	ExternalRef *ExternalRef `json:"externalRef,omitempty"`
+	// Patch patches an existing object owned by another controller, e.g
+	// to add an annotation to the default ServiceAccount, instead of
+	// creating it. The patch is reverted when the instance is deleted.
+	//
+	// +kubebuilder:validation:Optional
+	Patch *Patch `json:"patch,omitempty"`
 	// +kubebuilder:validation:Optional
 	ReadyWhen []string `json:"readyWhen,omitempty"`
 	// +kubebuilder:validation:Optional
 	IncludeWhen []string `json:"includeWhen,omitempty"`
+	// DependsOn lists the ids of the resources this resource must be created
+	// after, in addition to the resources its expressions refer to.
+	//
+	// +kubebuilder:validation:Optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// ForEach expands the template into one resource per item of a list or
+	// a map of the instance.
+	//
+	// +kubebuilder:validation:Optional
+	ForEach *ForEach `json:"forEach,omitempty"`
+	// ReadinessTimeout is how long the resource can take to become ready once
+	// created, e.g `10m`. By default, kro waits for it forever.
+	//
+	// +kubebuilder:validation:Optional
+	ReadinessTimeout *metav1.Duration `json:"readinessTimeout,omitempty"`
+	// OnFailure is what kro does once the readiness timeout of the resource
+	// has passed: Retry keeps waiting for it, holding back the resources
+	// depending on it, Continue reconciles them anyway, and Abort fails the
+	// reconciliation of the instance. In any case the resource is reported
+	// in the Degraded condition of the instance.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Abort;Continue;Retry
+	// +kubebuilder:default=Retry
+	OnFailure FailurePolicy `json:"onFailure,omitempty"`
+	// Retry overrides the retry policy of the resourcegraphdefinition while
+	// the instances wait for the resource, e.g to poll slowly provisioned
+	// cloud resources less often.
+	//
+	// +kubebuilder:validation:Optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// AutoHeal reverts the changes made to the resource outside of kro, e.g
+	// with `kubectl edit`, as soon as they happen. When disabled, these
+	// changes are kept and the resource is reported in the Drifted condition
+	// of the instance, until the desired state of the resource changes.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	AutoHeal *bool `json:"autoHeal,omitempty"`
+	// ConflictPolicy is what kro does with the fields of the resource it
+	// applies that are managed by other field managers, e.g the replicas of a
+	// Deployment scaled by a HorizontalPodAutoscaler: Force takes them over,
+	// Fail fails the reconciliation of the instance, and IgnoreFields leaves
+	// them to the other field managers.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Fail;Force;IgnoreFields
+	// +kubebuilder:default=Force
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+	// IgnoreFields are the paths of the fields of the resource changed by
+	// other systems, e.g `spec.replicas` of a Deployment scaled by a
+	// HorizontalPodAutoscaler, or `metadata.annotations["example.com/id"]`.
+	// They're set when the resource is created, and then left to the field
+	// managers changing them: their changes are neither reverted nor reported
+	// as drift.
+	//
+	// +kubebuilder:validation:Optional
+	IgnoreFields []string `json:"ignoreFields,omitempty"`
+	// DeletionPolicy is what kro does with the resource when the instance is
+	// deleted, or when the item it was expanded from is removed: Delete
+	// deletes it, Orphan removes the kro labels from it and leaves it in the
+	// cluster, and Retain leaves it in the cluster as is, so that an instance
+	// with the same name adopts it again.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// AdoptionPolicy is what kro does when the resource already exists but
+	// isn't managed by the instance: Adopt takes it over, AdoptOrFail only
+	// takes it over if no other instance manages it, and Fail fails the
+	// reconciliation of the instance.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Adopt;AdoptOrFail;Fail
+	// +kubebuilder:default=AdoptOrFail
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// Hooks are run around the apply of the resource: the preApply hooks
+	// before it's applied, and the postApply hooks once it's ready, before
+	// the resources depending on it are applied.
+	//
+	// +kubebuilder:validation:Optional
+	Hooks *Hooks `json:"hooks,omitempty"`
+	// WaitFor lists objects kro doesn't manage that the resources depending
+	// on the resource wait for, e.g the Certificate issued for an Ingress.
+	//
+	// +kubebuilder:validation:Optional
+	WaitFor []*WaitFor `json:"waitFor,omitempty"`
+	// Target is the cluster the resource, the Jobs of its hooks and the
+	// objects it waits for are applied to and watched in. It defaults to
+	// the target of the resource graph definition.
+	//
+	// +kubebuilder:validation:Optional
+	Target *Target `json:"target,omitempty"`
+}
+
+// WaitFor is an object kro doesn't manage, watched until it exists and is
+// ready. Its name and namespace can refer to the resource waiting for it.
+type WaitFor struct {
+	// ID is the id the object is referred to with in expressions.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ID          string `json:"id"`
+	ExternalRef `json:",inline"`
+	// ReadyWhen are the conditions the object must meet to be ready.
+	//
+	// +kubebuilder:validation:Optional
+	ReadyWhen []string `json:"readyWhen,omitempty"`
+}
+
+// AdoptionPolicy is what kro does with an existing resource that isn't
+// managed by the instance.
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyAdopt takes the resource over, even from another
+	// instance.
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+	// AdoptionPolicyAdoptOrFail takes the resource over, unless another
+	// instance manages it.
+	AdoptionPolicyAdoptOrFail AdoptionPolicy = "AdoptOrFail"
+	// AdoptionPolicyFail fails the reconciliation of the instance.
+	AdoptionPolicyFail AdoptionPolicy = "Fail"
+)
+
+// DeletionPolicy is what kro does with a resource when it's no longer part of
+// an instance.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the resource.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves the resource in the cluster, without the
+	// kro labels.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyRetain leaves the resource in the cluster as is.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// ConflictPolicy is what kro does with the fields of a resource managed by
+// other field managers.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyFail fails the reconciliation of the instance.
+	ConflictPolicyFail ConflictPolicy = "Fail"
+	// ConflictPolicyForce takes over the fields.
+	ConflictPolicyForce ConflictPolicy = "Force"
+	// ConflictPolicyIgnoreFields leaves the fields to the other field
+	// managers.
+	ConflictPolicyIgnoreFields ConflictPolicy = "IgnoreFields"
+)
+
+// FailurePolicy is what kro does with a resource that didn't become ready
+// within its readiness timeout.
+type FailurePolicy string
+
+const (
+	// FailurePolicyAbort fails the reconciliation of the instance.
+	FailurePolicyAbort FailurePolicy = "Abort"
+	// FailurePolicyContinue reconciles the resources depending on the
+	// resource anyway.
+	FailurePolicyContinue FailurePolicy = "Continue"
+	// FailurePolicyRetry keeps waiting for the resource.
+	FailurePolicyRetry FailurePolicy = "Retry"
+)
+
+// ForEach declares the collection a resource template iterates over. In the
+// template, the current item is available as `each`: `each.key` is its key
+// and `each.value` its value.
+type ForEach struct {
+	// Items is a standalone expression returning the list or the map to
+	// iterate over, e.g `${schema.spec.volumes}`. It can only refer to the
+	// instance.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Items string `json:"items,omitempty"`
+	// Key is a standalone expression returning the key identifying an item,
+	// e.g `${each.value.name}`. Keys must be unique strings. It defaults to
+	// the index of list items and the key of map entries.
+	//
+	// +kubebuilder:validation:Optional
+	Key string `json:"key,omitempty"`
+}
+
+// Target is a remote cluster resources are applied to and watched in, e.g a
+// spoke cluster managed from a hub cluster.
+type Target struct {
+	// KubeconfigSecretRef refers to the Secret holding the kubeconfig of the
+	// cluster. The Secret is read with the service account kro impersonates
+	// for the instance, if any.
+	//
+	// +kubebuilder:validation:Required
+	KubeconfigSecretRef KubeconfigSecretReference `json:"kubeconfigSecretRef"`
+}
+
+// KubeconfigSecretReference refers to the key of a Secret holding a
+// kubeconfig.
+type KubeconfigSecretReference struct {
+	// Namespace is the namespace of the Secret.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// Name is the name of the Secret.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key is the key of the kubeconfig in the Secret.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=kubeconfig
+	Key string `json:"key,omitempty"`
+}
+
+// ExternalRef is a reference to an object kro doesn't manage. The object is
+// watched, and the expressions referring to it are re-evaluated whenever it
+// changes.
+type ExternalRef struct {
+	// APIVersion is the API version of the referenced object.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+	// Kind is the kind of the referenced object.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+	// Metadata identifies the referenced object.
+	//
+	// +kubebuilder:validation:Required
+	Metadata ExternalRefMetadata `json:"metadata"`
+}
+
+// ExternalRefMetadata holds the name and namespace of a referenced object.
+// Both can refer to the instance, e.g `${schema.spec.network}`.
+type ExternalRefMetadata struct {
+	// Name is the name of the referenced object.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace is the namespace of the referenced object. It defaults to
+	// the namespace of the instance.
+	//
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// PatchType is the type of a patch.
+type PatchType string
+
+const (
+	// PatchTypeStrategicMerge is a strategic merge patch, a partial object
+	// whose lists are merged by key when their schema declares one.
+	PatchTypeStrategicMerge PatchType = "StrategicMerge"
+	// PatchTypeJSON is a JSON patch, a list of operations.
+	PatchTypeJSON PatchType = "JSON"
+)
+
+// Patch is a patch kro applies to an object it doesn't own. The fields it
+// changes are restored to their original values when the instance is
+// deleted, unless its deletion policy is Orphan or Retain.
+type Patch struct {
+	ExternalRef `json:",inline"`
+	// Type is the type of the patch, StrategicMerge or JSON.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=StrategicMerge;JSON
+	// +kubebuilder:default=StrategicMerge
+	Type PatchType `json:"type,omitempty"`
+	// Patch is the content of the patch: a partial object for a strategic
+	// merge patch, or a list of operations for a JSON patch. Its values can
+	// refer to the instance and the other resources.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Patch runtime.RawExtension `json:"patch"`
 }
 
 // ResourceGraphDefinitionState defines the state of the resource graph definition.
@@ -118,6 +909,29 @@ type ResourceGraphDefinitionStatus struct {
 	Conditions []Condition `json:"conditions,omitempty"`
 	// Resources represents the resources, and their information (dependencies for now)
 	Resources []ResourceInformation `json:"resources,omitempty"`
+	// Graph is the dependency graph of the resources, rendered in the format
+	// requested by the kro.run/graph-format annotation.
+	Graph string `json:"graph,omitempty"`
+	// Deletion is set while the resourcegraphdefinition is being deleted, and
+	// reports the instances blocking its deletion.
+	// +optional
+	Deletion *DeletionStatus `json:"deletion,omitempty"`
+	// Warnings are the issues found in the resourcegraphdefinition which
+	// don't prevent it from working, but are likely mistakes, e.g fields of
+	// the schema no expression uses.
+	// +optional
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// DeletionStatus reports the instances blocking the deletion of a
+// resourcegraphdefinition.
+type DeletionStatus struct {
+	// RemainingInstances is the number of instances left.
+	RemainingInstances int64 `json:"remainingInstances"`
+	// Instances are the names of the first remaining instances, as
+	// namespace/name for namespaced instances.
+	// +optional
+	Instances []string `json:"instances,omitempty"`
 }
 
 // ResourceInformation defines the information about a resource
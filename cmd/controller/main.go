@@ -15,24 +15,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"go.uber.org/zap/zapcore"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	xv1alpha1 "github.com/kro-run/kro/api/v1alpha1"
 	kroclient "github.com/kro-run/kro/pkg/client"
 	resourcegraphdefinitionctrl "github.com/kro-run/kro/pkg/controller/resourcegraphdefinition"
 	"github.com/kro-run/kro/pkg/dynamiccontroller"
 	"github.com/kro-run/kro/pkg/graph"
+	kroruntime "github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/tracing"
+	krowebhook "github.com/kro-run/kro/pkg/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -59,11 +69,15 @@ func (c customLevelEnabler) Enabled(lvl zapcore.Level) bool {
 func main() {
 	var (
 		metricsAddr                                 string
+		secureMetrics                               bool
 		enableLeaderElection                        bool
 		probeAddr                                   string
 		allowCRDDeletion                            bool
 		resourceGraphDefinitionConcurrentReconciles int
 		dynamicControllerConcurrentReconciles       int
+		instanceResourceConcurrentReconciles        int
+		fieldManager                                string
+		instanceResyncPeriod                        time.Duration
 		// dynamic controller rate limiter parameters
 		minRetryDelay time.Duration
 		maxRetryDelay time.Duration
@@ -74,12 +88,37 @@ func main() {
 		queueMaxRetries int
 		shutdownTimeout int
 		// var dynamicControllerDefaultResyncPeriod int
-		logLevel int
-		qps      float64
-		burst    int
+		logLevel        int
+		qps             float64
+		burst           int
+		clientUserAgent string
+		// webhook parameters
+		enableDefaultingWebhook bool
+		webhookPort             int
+		webhookCertDir          string
+		enableConversionWebhook bool
+		webhookServiceName      string
+		webhookServiceNamespace string
+		// cluster facts exposed to the expressions
+		clusterDomain      string
+		clusterRegion      string
+		clusterEnvironment string
+		// tracing parameters
+		tracingEndpoint    string
+		tracingSampleRatio float64
+		// instance schema publication
+		schemaConfigMapNamespace string
+		// debug report of the dynamic controller
+		enableDebugEndpoint bool
+		// resource graph definitions reconciled by this deployment
+		resourceGraphDefinitionSelector       string
+		resourceGraphDefinitionLeases         bool
+		resourceGraphDefinitionLeaseNamespace string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8078", "The address the metric endpoint binds to.")
+	flag.BoolVar(&secureMetrics, "metrics-secure", false,
+		"Serve the metrics endpoint over HTTPS, to the users the API server allows to get its paths.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8079", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
@@ -93,6 +132,15 @@ func main() {
 		"dynamic-controller-concurrent-reconciles", 1,
 		"The number of dynamic controller reconciles to run in parallel",
 	)
+	flag.IntVar(&instanceResourceConcurrentReconciles,
+		"instance-resource-concurrent-reconciles", 4,
+		"The number of resources of an instance to reconcile in parallel",
+	)
+	flag.StringVar(&fieldManager, "field-manager", "kro",
+		"The field manager the resources of the instances are applied with")
+	flag.DurationVar(&instanceResyncPeriod, "instance-resync-period", 10*time.Hour,
+		"The interval at which instances are reconciled even without changes, unless their resource graph "+
+			"definition sets its own. 0 disables it")
 
 	// rate limiter parameters
 	flag.DurationVar(&minRetryDelay, "dynamic-controller-rate-limiter-min-delay", 200*time.Millisecond,
@@ -117,6 +165,48 @@ func main() {
 	flag.Float64Var(&qps, "client-qps", 100, "The number of queries per second to allow")
 	flag.IntVar(&burst, "client-burst", 150,
 		"The number of requests that can be stored for processing before the server starts enforcing the QPS limit")
+	flag.StringVar(&clientUserAgent, "client-user-agent", "",
+		"The user agent of the requests to the API server, identifying them in its audit logs and metrics. "+
+			"Defaults to kro/<version>")
+
+	// webhook
+	flag.BoolVar(&enableDefaultingWebhook, "enable-defaulting-webhook", false,
+		"Serve a mutating webhook applying the schema defaults of resource graph definitions to their instances")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server listens on")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "",
+		"The directory containing the webhook server certificate and key. Defaults to the controller-runtime default")
+	flag.BoolVar(&enableConversionWebhook, "enable-conversion-webhook", false,
+		"Serve a webhook converting instances between the versions of resource graph definitions declaring conversions")
+	flag.StringVar(&webhookServiceName, "webhook-service-name", "kro-webhook",
+		"The name of the service exposing the webhook server, used by the API server to call the conversion webhook")
+	flag.StringVar(&webhookServiceNamespace, "webhook-service-namespace", "kro-system",
+		"The namespace of the service exposing the webhook server")
+	flag.StringVar(&clusterDomain, "cluster-domain", "cluster.local",
+		"The DNS domain of the cluster, exposed to the expressions as cluster.domain")
+	flag.StringVar(&clusterRegion, "cluster-region", "",
+		"The region of the cluster, exposed to the expressions as cluster.region")
+	flag.StringVar(&clusterEnvironment, "cluster-environment", "",
+		"The environment of the cluster, e.g production, exposed to the expressions as cluster.environment")
+	flag.StringVar(&tracingEndpoint, "tracing-endpoint", "",
+		"The OTLP/HTTP traces endpoint of the OpenTelemetry collector the reconciliations are traced to, "+
+			"e.g http://otel-collector:4318/v1/traces. It defaults to the OTEL_EXPORTER_OTLP_TRACES_ENDPOINT "+
+			"and OTEL_EXPORTER_OTLP_ENDPOINT environment variables, tracing is disabled if none is set")
+	flag.Float64Var(&tracingSampleRatio, "tracing-sample-ratio", 1,
+		"The ratio of the reconciliations traced, between 0 and 1")
+	flag.StringVar(&schemaConfigMapNamespace, "schema-configmap-namespace", "",
+		"The namespace the JSON Schema of the instances of each resource graph definition is published to, "+
+			"in a ConfigMap named after it. The schemas are not published if empty")
+	flag.BoolVar(&enableDebugEndpoint, "enable-debug-endpoint", false,
+		"Serve the debug report of the dynamic controller at "+dynamiccontroller.DebugPath+" of the metrics endpoint, "+
+			"to the users allowed to get this path. It requires --metrics-secure")
+	flag.StringVar(&resourceGraphDefinitionSelector, "resource-graph-definition-selector", "",
+		"The label selector of the resource graph definitions reconciled by this deployment, e.g tier=infra. "+
+			"All of them are reconciled if empty")
+	flag.BoolVar(&resourceGraphDefinitionLeases, "resource-graph-definition-leases", false,
+		"Reconcile each resource graph definition by the replica holding its own lease, rather than all of them "+
+			"by a single leader, so that replicas and deployments share them. It can't be combined with --leader-elect")
+	flag.StringVar(&resourceGraphDefinitionLeaseNamespace, "resource-graph-definition-lease-namespace", "kro-system",
+		"The namespace of the leases of the resource graph definitions")
 
 	flag.Parse()
 
@@ -129,9 +219,59 @@ func main() {
 
 	ctrl.SetLogger(rootLogger)
 
+	selector, err := labels.Parse(resourceGraphDefinitionSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid resource graph definition selector")
+		os.Exit(1)
+	}
+	if selector.Empty() {
+		selector = nil
+	}
+	if enableDebugEndpoint && !secureMetrics {
+		setupLog.Error(errors.New("conflicting flags"), "--enable-debug-endpoint requires --metrics-secure")
+		os.Exit(1)
+	}
+	var leaseConfig *resourcegraphdefinitionctrl.LeaseConfig
+	if resourceGraphDefinitionLeases {
+		if enableLeaderElection {
+			setupLog.Error(errors.New("conflicting flags"), "--resource-graph-definition-leases can't be combined "+
+				"with --leader-elect")
+			os.Exit(1)
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			setupLog.Error(err, "unable to get the hostname")
+			os.Exit(1)
+		}
+		leaseConfig = &resourcegraphdefinitionctrl.LeaseConfig{
+			Namespace: resourceGraphDefinitionLeaseNamespace,
+			Identity:  hostname + "_" + string(uuid.NewUUID()),
+		}
+	}
+
+	if tracingEndpoint != "" || tracing.EnvEndpoint() != "" {
+		shutdownTracing, err := tracing.Setup(rootLogger, tracing.Config{
+			Endpoint:    tracingEndpoint,
+			ServiceName: "kro",
+			SampleRatio: tracingSampleRatio,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				setupLog.Error(err, "unable to export the last spans")
+			}
+		}()
+	}
+
 	set, err := kroclient.NewSet(kroclient.Config{
-		QPS:   float32(qps),
-		Burst: burst,
+		QPS:       float32(qps),
+		Burst:     burst,
+		UserAgent: clientUserAgent,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to create client set")
@@ -139,14 +279,46 @@ func main() {
 	}
 	restConfig := set.RESTConfig()
 
+	dc := dynamiccontroller.NewDynamicController(rootLogger, dynamiccontroller.Config{
+		Workers: dynamicControllerConcurrentReconciles,
+		// TODO(a-hilaly): expose these as flags
+		ShutdownTimeout: time.Duration(shutdownTimeout) * time.Second,
+		ResyncPeriod:    time.Duration(resyncPeriod) * time.Hour,
+		QueueMaxRetries: queueMaxRetries,
+		MinRetryDelay:   minRetryDelay,
+		MaxRetryDelay:   maxRetryDelay,
+		RateLimit:       rateLimit,
+		BurstLimit:      burstLimit,
+	}, set.Dynamic())
+
+	metricsOptions := metricsserver.Options{
+		BindAddress: metricsAddr,
+	}
+	if secureMetrics {
+		// The requests are authenticated and authorized by the API server,
+		// e.g with a ClusterRole granting get on /metrics.
+		metricsOptions.SecureServing = true
+		metricsOptions.FilterProvider = dynamiccontroller.AuthorizationFilter
+	}
+	if enableDebugEndpoint {
+		// The debug report lists the objects kro watches, it's only served
+		// over the secure metrics endpoint, to the users allowed to get its
+		// path.
+		metricsOptions.ExtraHandlers = map[string]http.Handler{
+			dynamiccontroller.DebugPath: dc.DebugHandler(),
+		}
+	}
+
 	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: metricsAddr,
-		},
+		Scheme:                 scheme,
+		Metrics:                metricsOptions,
 		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "6f0f64a5.kro.run",
+		WebhookServer: ctrlwebhook.NewServer(ctrlwebhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		}),
+		LeaderElection:   enableLeaderElection,
+		LeaderElectionID: "6f0f64a5.kro.run",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -165,32 +337,66 @@ func main() {
 		os.Exit(1)
 	}
 
-	dc := dynamiccontroller.NewDynamicController(rootLogger, dynamiccontroller.Config{
-		Workers: dynamicControllerConcurrentReconciles,
-		// TODO(a-hilaly): expose these as flags
-		ShutdownTimeout: time.Duration(shutdownTimeout) * time.Second,
-		ResyncPeriod:    time.Duration(resyncPeriod) * time.Hour,
-		QueueMaxRetries: queueMaxRetries,
-		MinRetryDelay:   minRetryDelay,
-		MaxRetryDelay:   maxRetryDelay,
-		RateLimit:       rateLimit,
-		BurstLimit:      burstLimit,
-	}, set.Dynamic())
-
 	resourceGraphDefinitionGraphBuilder, err := graph.NewBuilder(
 		restConfig,
+		kroruntime.Cluster{
+			Domain:      clusterDomain,
+			Region:      clusterRegion,
+			Environment: clusterEnvironment,
+		},
 	)
 	if err != nil {
 		setupLog.Error(err, "unable to create resource graph definition graph builder")
 		os.Exit(1)
 	}
 
+	var defaultingWebhook *krowebhook.DefaultingWebhook
+	if enableDefaultingWebhook {
+		defaultingWebhook = krowebhook.NewDefaultingWebhook(rootLogger)
+		mgr.GetWebhookServer().Register(krowebhook.DefaultingWebhookPath, &ctrlwebhook.Admission{
+			Handler: defaultingWebhook,
+		})
+	}
+
+	var conversionWebhook *krowebhook.ConversionWebhook
+	if enableConversionWebhook {
+		certDir := webhookCertDir
+		if certDir == "" {
+			certDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+		}
+		caBundle, err := os.ReadFile(filepath.Join(certDir, "ca.crt"))
+		if err != nil {
+			setupLog.Error(err, "unable to read the webhook CA bundle")
+			os.Exit(1)
+		}
+		path := krowebhook.ConversionWebhookPath
+		conversionWebhook = krowebhook.NewConversionWebhook(rootLogger, extv1.WebhookClientConfig{
+			Service: &extv1.ServiceReference{
+				Name:      webhookServiceName,
+				Namespace: webhookServiceNamespace,
+				Path:      &path,
+			},
+			CABundle: caBundle,
+		})
+		mgr.GetWebhookServer().Register(krowebhook.ConversionWebhookPath, conversionWebhook)
+	}
+
 	rgd := resourcegraphdefinitionctrl.NewResourceGraphDefinitionReconciler(
 		set,
 		allowCRDDeletion,
 		dc,
 		resourceGraphDefinitionGraphBuilder,
-		resourceGraphDefinitionConcurrentReconciles,
+		resourcegraphdefinitionctrl.ReconcilerConfig{
+			MaxConcurrentReconciles:         resourceGraphDefinitionConcurrentReconciles,
+			MaxConcurrentResourceReconciles: instanceResourceConcurrentReconciles,
+			FieldManager:                    fieldManager,
+			ResyncPeriod:                    instanceResyncPeriod,
+			DefaultingWebhook:               defaultingWebhook,
+			ConversionWebhook:               conversionWebhook,
+			SchemaNamespace:                 schemaConfigMapNamespace,
+			Selector:                        selector,
+			LeaseConfig:                     leaseConfig,
+		},
 	)
 	if err := rgd.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ResourceGraphDefinition")
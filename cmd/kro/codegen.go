@@ -0,0 +1,91 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/codegen"
+)
+
+type codegenOptions struct {
+	file        string
+	output      string
+	packageName string
+}
+
+func newCodegenCommand() *cobra.Command {
+	opts := &codegenOptions{}
+	cmd := &cobra.Command{
+		Use:   "codegen",
+		Short: "Generate Go types for the instances of a ResourceGraphDefinition",
+		Long: "Generate Go types for the instances of a ResourceGraphDefinition.\n\n" +
+			"The input file is either a ResourceGraphDefinition, or the CustomResourceDefinition " +
+			"generated by kro for it, which also provides the types of the status fields.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCodegen(cmd, opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.file, "file", "f", "", "Path to a ResourceGraphDefinition or CustomResourceDefinition file")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the generated file, defaults to stdout")
+	cmd.Flags().StringVar(&opts.packageName, "package", "", "Name of the generated package, defaults to the version of the kind")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func runCodegen(cmd *cobra.Command, opts *codegenOptions) error {
+	data, err := os.ReadFile(opts.file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.file, err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", opts.file, err)
+	}
+
+	genOpts := codegen.Options{PackageName: opts.packageName}
+	var src []byte
+	switch obj.GetKind() {
+	case "ResourceGraphDefinition":
+		rgd := &v1alpha1.ResourceGraphDefinition{}
+		if err := yaml.Unmarshal(data, rgd); err != nil {
+			return fmt.Errorf("failed to parse ResourceGraphDefinition: %w", err)
+		}
+		src, err = codegen.GenerateFromResourceGraphDefinition(rgd, genOpts)
+	case "CustomResourceDefinition":
+		crd := &extv1.CustomResourceDefinition{}
+		if err := yaml.Unmarshal(data, crd); err != nil {
+			return fmt.Errorf("failed to parse CustomResourceDefinition: %w", err)
+		}
+		src, err = codegen.GenerateFromCRD(crd, genOpts)
+	default:
+		return fmt.Errorf("unsupported kind %q, expected ResourceGraphDefinition or CustomResourceDefinition", obj.GetKind())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	if opts.output == "" {
+		_, err = cmd.OutOrStdout().Write(src)
+		return err
+	}
+	return os.WriteFile(opts.output, src, 0o644)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/apidoc"
+)
+
+type docOptions struct {
+	file   string
+	output string
+	format string
+}
+
+func newDocCommand() *cobra.Command {
+	opts := &docOptions{}
+	cmd := &cobra.Command{
+		Use:   "doc",
+		Short: "Generate the API documentation of the instances of a ResourceGraphDefinition",
+		Long: "Generate the API documentation of the instances of a ResourceGraphDefinition, as Markdown or " +
+			"HTML: the fields of their spec and status, with their types, defaults, descriptions and " +
+			"validation rules, and whether they are required.\n\n" +
+			"The input file is either a ResourceGraphDefinition, or the CustomResourceDefinition " +
+			"generated by kro for it, which also provides the types of the status fields.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoc(cmd, opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.file, "file", "f", "", "Path to a ResourceGraphDefinition or CustomResourceDefinition file")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the generated file, defaults to stdout")
+	cmd.Flags().StringVar(&opts.format, "format", string(apidoc.FormatMarkdown), "Format of the documentation, one of markdown or html")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func runDoc(cmd *cobra.Command, opts *docOptions) error {
+	format := apidoc.Format(opts.format)
+	if format != apidoc.FormatMarkdown && format != apidoc.FormatHTML {
+		return fmt.Errorf("unsupported format %q, expected markdown or html", opts.format)
+	}
+
+	data, err := os.ReadFile(opts.file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.file, err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", opts.file, err)
+	}
+
+	docOpts := apidoc.Options{Format: format}
+	var doc []byte
+	switch obj.GetKind() {
+	case "ResourceGraphDefinition":
+		rgd := &v1alpha1.ResourceGraphDefinition{}
+		if err := yaml.Unmarshal(data, rgd); err != nil {
+			return fmt.Errorf("failed to parse ResourceGraphDefinition: %w", err)
+		}
+		doc, err = apidoc.GenerateFromResourceGraphDefinition(rgd, docOpts)
+	case "CustomResourceDefinition":
+		crd := &extv1.CustomResourceDefinition{}
+		if err := yaml.Unmarshal(data, crd); err != nil {
+			return fmt.Errorf("failed to parse CustomResourceDefinition: %w", err)
+		}
+		doc, err = apidoc.GenerateFromCRD(crd, docOpts)
+	default:
+		return fmt.Errorf("unsupported kind %q, expected ResourceGraphDefinition or CustomResourceDefinition", obj.GetKind())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate documentation: %w", err)
+	}
+
+	if opts.output == "" {
+		_, err = cmd.OutOrStdout().Write(doc)
+		return err
+	}
+	return os.WriteFile(opts.output, doc, 0o644)
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/graph/schema"
+)
+
+type graphOptions struct {
+	files             []string
+	crds              []string
+	output            string
+	kubernetesVersion string
+}
+
+// graphReport is the machine readable output of the dependency graph of a
+// ResourceGraphDefinition.
+type graphReport struct {
+	Name             string          `json:"name"`
+	TopologicalOrder []string        `json:"topologicalOrder"`
+	Resources        []graphResource `json:"resources"`
+}
+
+type graphResource struct {
+	ID           string            `json:"id"`
+	APIVersion   string            `json:"apiVersion"`
+	Kind         string            `json:"kind"`
+	External     bool              `json:"external,omitempty"`
+	ForEach      bool              `json:"forEach,omitempty"`
+	IncludeWhen  []string          `json:"includeWhen,omitempty"`
+	Dependencies []graphDependency `json:"dependencies,omitempty"`
+}
+
+type graphDependency struct {
+	ID      string   `json:"id"`
+	Origins []string `json:"origins"`
+}
+
+func newGraphCommand() *cobra.Command {
+	opts := &graphOptions{}
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Print the dependency graph of ResourceGraphDefinitions",
+		Long: "Print the dependency graph of ResourceGraphDefinitions, without access to a cluster: the order " +
+			"kro creates their resources in, and the expressions or dependsOn entries each dependency comes " +
+			"from.\n\n" +
+			"The text output lists the resources in topological order, followed by their dependencies. The " +
+			"dot and mermaid outputs are Graphviz and Mermaid diagrams whose edges are labeled with the origins " +
+			"of the dependencies.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGraph(cmd, opts)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&opts.files, "file", "f", nil,
+		"Path to a file or directory of ResourceGraphDefinitions, can be repeated")
+	cmd.Flags().StringSliceVar(&opts.crds, "crd", nil,
+		"Path to a file or directory of CustomResourceDefinitions of the custom kinds of the resources, can be repeated")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output format, one of text, dot, mermaid or json")
+	cmd.Flags().StringVar(&opts.kubernetesVersion, "kubernetes-version", defaultKubernetesVersion,
+		"Version of the cluster the expressions see")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func runGraph(cmd *cobra.Command, opts *graphOptions) error {
+	switch opts.output {
+	case "text", "dot", "mermaid", "json":
+	default:
+		return fmt.Errorf("unsupported output %q, expected text, dot, mermaid or json", opts.output)
+	}
+
+	loaded, err := loadManifests(append(opts.files, opts.crds...))
+	if err != nil {
+		return err
+	}
+	if len(loaded.rgds) == 0 {
+		return fmt.Errorf("no ResourceGraphDefinition found")
+	}
+	sharedTypes, err := graph.MergeTypeLibraries(loaded.libraries)
+	if err != nil {
+		return err
+	}
+
+	var reports []graphReport
+	w := cmd.OutOrStdout()
+	for i, doc := range loaded.rgds {
+		rgd := &v1alpha1.ResourceGraphDefinition{}
+		if err := yaml.Unmarshal(doc.data, rgd); err != nil {
+			return fmt.Errorf("failed to parse ResourceGraphDefinition in %s: %w", doc.file, err)
+		}
+		resolver, err := schema.NewOfflineResolver(loaded.crds)
+		if err != nil {
+			return err
+		}
+		builder := graph.NewOfflineBuilder(resolver, opts.kubernetesVersion)
+		rgdGraph, err := builder.NewResourceGraphDefinition(rgd, graph.WithSharedTypes(sharedTypes))
+		if err != nil {
+			return fmt.Errorf("invalid ResourceGraphDefinition %s: %w", rgd.Name, err)
+		}
+
+		switch opts.output {
+		case "json":
+			reports = append(reports, newGraphReport(rgd.Name, rgdGraph))
+			continue
+		case "dot", "mermaid":
+			rendered, err := rgdGraph.Render(graph.RenderFormat(opts.output), graph.WithEdgeOrigins())
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprint(w, rendered)
+		default:
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if err := writeGraphText(w, newGraphReport(rgd.Name, rgdGraph)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.output == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{"resourceGraphDefinitions": reports})
+	}
+	return nil
+}
+
+// newGraphReport returns the report of the dependency graph of the given
+// ResourceGraphDefinition.
+func newGraphReport(name string, rgdGraph *graph.Graph) graphReport {
+	report := graphReport{Name: name, TopologicalOrder: rgdGraph.TopologicalOrder}
+	for _, id := range rgdGraph.TopologicalOrder {
+		resource := rgdGraph.Resources[id]
+		obj := resource.Unstructured()
+		output := graphResource{
+			ID:          id,
+			APIVersion:  obj.GetAPIVersion(),
+			Kind:        obj.GetKind(),
+			External:    resource.IsExternalRef(),
+			ForEach:     resource.IsForEach(),
+			IncludeWhen: resource.GetIncludeWhenExpressions(),
+		}
+		// Dependencies are listed in topological order too.
+		for _, dependency := range rgdGraph.TopologicalOrder {
+			if !resource.HasDependency(dependency) {
+				continue
+			}
+			output.Dependencies = append(output.Dependencies, graphDependency{
+				ID:      dependency,
+				Origins: rgdGraph.DependencyOrigins(id, dependency),
+			})
+		}
+		report.Resources = append(report.Resources, output)
+	}
+	return report
+}
+
+func writeGraphText(w io.Writer, report graphReport) error {
+	fmt.Fprintf(w, "%s\n\n", report.Name)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ORDER\tRESOURCE\tKIND\tDEPENDS ON\tNOTES")
+	for i, resource := range report.Resources {
+		dependencies := make([]string, 0, len(resource.Dependencies))
+		for _, dependency := range resource.Dependencies {
+			dependencies = append(dependencies, dependency.ID)
+		}
+		var notes []string
+		if resource.External {
+			notes = append(notes, "external")
+		}
+		if resource.ForEach {
+			notes = append(notes, "forEach")
+		}
+		if len(resource.IncludeWhen) > 0 {
+			notes = append(notes, "includeWhen")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", i+1, resource.ID, resource.Kind,
+			orDash(strings.Join(dependencies, ", ")), orDash(strings.Join(notes, ", ")))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, resource := range report.Resources {
+		for _, dependency := range resource.Dependencies {
+			fmt.Fprintf(w, "\n%s -> %s\n", resource.ID, dependency.ID)
+			for _, origin := range dependency.Origins {
+				fmt.Fprintf(w, "  %s\n", origin)
+			}
+		}
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
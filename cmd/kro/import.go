@@ -0,0 +1,116 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/pkg/helm"
+)
+
+type importHelmOptions struct {
+	output            string
+	name              string
+	kind              string
+	apiVersion        string
+	group             string
+	kubernetesVersion string
+}
+
+func newImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Convert other packaging formats to ResourceGraphDefinitions",
+	}
+	cmd.AddCommand(newImportHelmCommand())
+	return cmd
+}
+
+func newImportHelmCommand() *cobra.Command {
+	opts := &importHelmOptions{}
+	cmd := &cobra.Command{
+		Use:   "helm CHART",
+		Short: "Convert a Helm chart to a ResourceGraphDefinition",
+		Long: "Convert the Helm chart of the given directory or archive to a ResourceGraphDefinition. The " +
+			"values of the chart become the fields of the instance spec, with their values as defaults, and " +
+			"the templates become the resources, where the values they read are replaced by expressions " +
+			"reading the instance.\n\n" +
+			"The conversion is best-effort: the templates are rendered once with the default values, so the " +
+			"resources of the conditions not met by the defaults are not imported, and the conditions are " +
+			"not converted to includeWhen expressions. The parts of the chart which need to be reviewed are " +
+			"printed as warnings.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImportHelm(cmd, args[0], opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the generated file, defaults to stdout")
+	cmd.Flags().StringVar(&opts.name, "name", "", "Name of the ResourceGraphDefinition, defaults to the name of the chart")
+	cmd.Flags().StringVar(&opts.kind, "kind", "", "Kind of the instances, defaults to the name of the chart in PascalCase")
+	cmd.Flags().StringVar(&opts.apiVersion, "api-version", "", "Version of the instances, defaults to v1alpha1")
+	cmd.Flags().StringVar(&opts.group, "group", "", "Group of the instances, defaults to kro.run")
+	cmd.Flags().StringVar(&opts.kubernetesVersion, "kubernetes-version", helm.DefaultKubernetesVersion,
+		"Version of the cluster the templates see")
+	return cmd
+}
+
+func runImportHelm(cmd *cobra.Command, chart string, opts *importHelmOptions) error {
+	cmd.SilenceUsage = true
+
+	result, err := helm.Import(chart, helm.Options{
+		Name:              opts.name,
+		Kind:              opts.kind,
+		APIVersion:        opts.apiVersion,
+		Group:             opts.group,
+		KubernetesVersion: opts.kubernetesVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import chart %s: %w", chart, err)
+	}
+	for _, warning := range result.Warnings {
+		if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+
+	// Drop the empty fields of the generated object, which are only noise in
+	// a manifest.
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(result.ResourceGraphDefinition)
+	if err != nil {
+		return err
+	}
+	delete(object, "status")
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	if schema, ok, _ := unstructured.NestedMap(object, "spec", "schema"); ok && schema["status"] == nil {
+		unstructured.RemoveNestedField(object, "spec", "schema", "status")
+	}
+	data, err := yaml.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	if opts.output == "" {
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+	return os.WriteFile(opts.output, data, 0o644)
+}
@@ -31,4 +31,13 @@ func main() {
 
 func init() {
 	// Add subcommands and configure global flags here
+	rootCmd.AddCommand(newCodegenCommand())
+	rootCmd.AddCommand(newRBACCommand())
+	rootCmd.AddCommand(newValidateCommand())
+	rootCmd.AddCommand(newRenderCommand())
+	rootCmd.AddCommand(newGraphCommand())
+	rootCmd.AddCommand(newDocCommand())
+	rootCmd.AddCommand(newSnapshotCommand())
+	rootCmd.AddCommand(newImportCommand())
+	rootCmd.AddCommand(newSchemaCommand())
 }
@@ -0,0 +1,90 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/rbac"
+)
+
+type rbacOptions struct {
+	file                    string
+	binding                 bool
+	serviceAccount          string
+	serviceAccountNamespace string
+}
+
+func newRBACCommand() *cobra.Command {
+	opts := &rbacOptions{}
+	cmd := &cobra.Command{
+		Use:   "rbac",
+		Short: "Generate the RBAC manifests kro needs for a ResourceGraphDefinition",
+		Long: "Generate a ClusterRole granting the permissions kro needs to reconcile the instances of a " +
+			"ResourceGraphDefinition. The ClusterRole is aggregated into the ClusterRole of kro when the Helm " +
+			"chart is installed with the aggregation access mode, otherwise it's bound to the service account " +
+			"of kro with --binding.\n\n" +
+			"The resources are named after the plural of their kind. Kinds whose resource isn't their plural " +
+			"must be fixed in the generated ClusterRole.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRBAC(cmd, opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.file, "file", "f", "", "Path to a ResourceGraphDefinition file")
+	cmd.Flags().BoolVar(&opts.binding, "binding", false, "Also generate a ClusterRoleBinding to the service account of kro")
+	cmd.Flags().StringVar(&opts.serviceAccount, "service-account", "kro", "Name of the service account of kro")
+	cmd.Flags().StringVar(&opts.serviceAccountNamespace, "service-account-namespace", "kro", "Namespace of the service account of kro")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func runRBAC(cmd *cobra.Command, opts *rbacOptions) error {
+	data, err := os.ReadFile(opts.file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.file, err)
+	}
+	rgd := &v1alpha1.ResourceGraphDefinition{}
+	if err := yaml.Unmarshal(data, rgd); err != nil {
+		return fmt.Errorf("failed to parse ResourceGraphDefinition: %w", err)
+	}
+
+	rules, err := rbac.Rules(rgd, rbac.PluralResolver)
+	if err != nil {
+		return fmt.Errorf("failed to compute the rules: %w", err)
+	}
+	objs := []interface{}{rbac.ClusterRole(rgd, rules)}
+	if opts.binding {
+		serviceAccount := types.NamespacedName{Namespace: opts.serviceAccountNamespace, Name: opts.serviceAccount}
+		objs = append(objs, rbac.ClusterRoleBinding(rgd, serviceAccount))
+	}
+	for i, obj := range objs {
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			manifest = append([]byte("---\n"), manifest...)
+		}
+		if _, err := cmd.OutOrStdout().Write(manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
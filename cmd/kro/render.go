@@ -0,0 +1,231 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/golden"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/graph/crd"
+	"github.com/kro-run/kro/pkg/graph/schema"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+type renderOptions struct {
+	files             []string
+	instance          string
+	crds              []string
+	output            string
+	kubernetesVersion string
+}
+
+// renderedResource is the machine readable output of a rendered resource.
+type renderedResource struct {
+	ID             string                 `json:"id"`
+	Object         map[string]interface{} `json:"object,omitempty"`
+	External       bool                   `json:"external,omitempty"`
+	Excluded       bool                   `json:"excluded,omitempty"`
+	ExcludedReason string                 `json:"excludedReason,omitempty"`
+	Unresolved     []unresolvedExpression `json:"unresolved,omitempty"`
+}
+
+type unresolvedExpression struct {
+	Field      string `json:"field"`
+	Expression string `json:"expression"`
+}
+
+func newRenderCommand() *cobra.Command {
+	opts := &renderOptions{}
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render the resources of an instance offline",
+		Long: "Render the resources kro creates for an instance of a ResourceGraphDefinition, without access to " +
+			"a cluster. The defaults of the instance schema are applied to the instance, and the expressions " +
+			"are evaluated against it and against the resources rendered before them.\n\n" +
+			"The expressions reading what only the cluster sets, such as the status of the resources or the " +
+			"external references, are evaluated at runtime: they are left in the rendered resources, and " +
+			"listed in a comment above them. The resources excluded by their includeWhen expressions are " +
+			"only listed in a comment.\n\n" +
+			"The ResourceGraphDefinition of the instance is looked up by the kind of the instance among the " +
+			"given files, which can also hold the CustomResourceDefinitions of the custom kinds of the resources " +
+			"and ResourceGraphTypeLibraries. The ConfigMaps and Secrets the values of the instance are " +
+			"loaded from are also looked up among the given files.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRender(cmd, opts)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&opts.files, "file", "f", nil,
+		"Path to a file or directory of ResourceGraphDefinitions, can be repeated")
+	cmd.Flags().StringVarP(&opts.instance, "instance", "i", "", "Path to the instance to render")
+	cmd.Flags().StringSliceVar(&opts.crds, "crd", nil,
+		"Path to a file or directory of CustomResourceDefinitions of the custom kinds of the resources, can be repeated")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "yaml", "Output format, one of yaml or json")
+	cmd.Flags().StringVar(&opts.kubernetesVersion, "kubernetes-version", defaultKubernetesVersion,
+		"Version of the cluster the expressions see")
+	_ = cmd.MarkFlagRequired("file")
+	_ = cmd.MarkFlagRequired("instance")
+	return cmd
+}
+
+func runRender(cmd *cobra.Command, opts *renderOptions) error {
+	if opts.output != "yaml" && opts.output != "json" {
+		return fmt.Errorf("unsupported output %q, expected yaml or json", opts.output)
+	}
+
+	instances, err := readDocuments([]string{opts.instance})
+	if err != nil {
+		return err
+	}
+	if len(instances) != 1 {
+		return fmt.Errorf("expected a single instance in %s, found %d", opts.instance, len(instances))
+	}
+	instance := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(instances[0].data, &instance.Object); err != nil {
+		return fmt.Errorf("failed to parse instance: %w", err)
+	}
+	// Instances are applied to the default namespace unless told otherwise.
+	if instance.GetNamespace() == "" {
+		instance.SetNamespace("default")
+	}
+
+	loaded, err := loadManifests(append(opts.files, opts.crds...))
+	if err != nil {
+		return err
+	}
+	sharedTypes, err := graph.MergeTypeLibraries(loaded.libraries)
+	if err != nil {
+		return err
+	}
+	resolver, err := schema.NewOfflineResolver(loaded.crds)
+	if err != nil {
+		return err
+	}
+	builder := graph.NewOfflineBuilder(resolver, opts.kubernetesVersion)
+
+	gvk := instance.GroupVersionKind()
+	var rgdGraph *graph.Graph
+	for _, doc := range loaded.rgds {
+		rgd := &v1alpha1.ResourceGraphDefinition{}
+		if err := yaml.Unmarshal(doc.data, rgd); err != nil {
+			return fmt.Errorf("failed to parse ResourceGraphDefinition in %s: %w", doc.file, err)
+		}
+		if !servesInstance(rgd, gvk.Group, gvk.Kind) {
+			continue
+		}
+		rgdGraph, err = builder.NewResourceGraphDefinition(rgd, graph.WithSharedTypes(sharedTypes))
+		if err != nil {
+			return fmt.Errorf("invalid ResourceGraphDefinition %s: %w", rgd.Name, err)
+		}
+		break
+	}
+	if rgdGraph == nil {
+		return fmt.Errorf("no ResourceGraphDefinition found for the instances of %s", gvk.GroupKind())
+	}
+
+	if err := crd.ApplyDefaults(instance, rgdGraph.Instance.GetCRD(), gvk.Version); err != nil {
+		return err
+	}
+	objects, err := valuesObjects(loaded.others)
+	if err != nil {
+		return err
+	}
+	values, err := rgdGraph.ResolveValues(instance, objects)
+	if err != nil {
+		return fmt.Errorf("failed to render instance: %w", err)
+	}
+	rt, err := rgdGraph.NewGraphRuntime(instance, graph.WithValues(values))
+	if err != nil {
+		return fmt.Errorf("failed to render instance: %w", err)
+	}
+	rendered, err := rt.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render instance: %w", err)
+	}
+
+	if opts.output == "json" {
+		return writeRenderedJSON(cmd.OutOrStdout(), rendered)
+	}
+	return writeRenderedYAML(cmd.OutOrStdout(), rendered)
+}
+
+// valuesObjects returns the ConfigMaps and Secrets among the given manifests,
+// the values of the instances are loaded from.
+func valuesObjects(documents []document) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, doc := range documents {
+		object := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(doc.data, &object.Object); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest in %s: %w", doc.file, err)
+		}
+		if object.GetAPIVersion() == "v1" && (object.GetKind() == "ConfigMap" || object.GetKind() == "Secret") {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// servesInstance returns true if the instances of the given kind are
+// instances of the given ResourceGraphDefinition.
+func servesInstance(rgd *v1alpha1.ResourceGraphDefinition, group, kind string) bool {
+	if rgd.Spec.Schema == nil || rgd.Spec.Schema.Kind != kind {
+		return false
+	}
+	rgdGroup := rgd.Spec.Schema.Group
+	if rgdGroup == "" {
+		rgdGroup = v1alpha1.KRODomainName
+	}
+	return rgdGroup == group
+}
+
+func writeRenderedYAML(w io.Writer, rendered []runtime.RenderedResource) error {
+	data, err := golden.Marshal(rendered)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func writeRenderedJSON(w io.Writer, rendered []runtime.RenderedResource) error {
+	resources := make([]renderedResource, 0, len(rendered))
+	for _, resource := range rendered {
+		output := renderedResource{
+			ID:             resource.ID,
+			External:       resource.External,
+			Excluded:       resource.Excluded,
+			ExcludedReason: resource.ExcludedReason,
+		}
+		if !resource.External && !resource.Excluded {
+			output.Object = resource.Object.Object
+		}
+		for _, expr := range resource.Unresolved {
+			output.Unresolved = append(output.Unresolved, unresolvedExpression{
+				Field:      expr.Field,
+				Expression: expr.Expression,
+			})
+		}
+		resources = append(resources, output)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{"resources": resources})
+}
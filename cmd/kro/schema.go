@@ -0,0 +1,100 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/jsonschema"
+)
+
+type schemaOptions struct {
+	files  []string
+	output string
+}
+
+func newSchemaCommand() *cobra.Command {
+	opts := &schemaOptions{}
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Generate the JSON Schema of the instances of ResourceGraphDefinitions",
+		Long: "Generate the JSON Schema of the instances of the given ResourceGraphDefinitions, from the OpenAPI " +
+			"schema kro generates for them, so that IDEs and client-side validators such as kubeconform can " +
+			"validate instance manifests before they reach the cluster.\n\n" +
+			"The schemas are written to the output directory, named after the kind and version of the " +
+			"instances, e.g webapp_v1alpha1.json, which kubeconform reads with the " +
+			"'{{.ResourceKind}}_{{.ResourceAPIVersion}}.json' schema location. Without output directory, the " +
+			"schema of a single ResourceGraphDefinition is written to stdout.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSchema(cmd, opts)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&opts.files, "file", "f", nil,
+		"Path to a file or directory of ResourceGraphDefinitions, can be repeated")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the directory the schemas are written to")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func runSchema(cmd *cobra.Command, opts *schemaOptions) error {
+	cmd.SilenceUsage = true
+
+	loaded, err := loadManifests(opts.files)
+	if err != nil {
+		return err
+	}
+	if len(loaded.rgds) == 0 {
+		return fmt.Errorf("no ResourceGraphDefinition found")
+	}
+
+	var documents []jsonschema.Document
+	for _, doc := range loaded.rgds {
+		rgd := &v1alpha1.ResourceGraphDefinition{}
+		if err := yaml.Unmarshal(doc.data, rgd); err != nil {
+			return fmt.Errorf("failed to parse ResourceGraphDefinition in %s: %w", doc.file, err)
+		}
+		generated, err := jsonschema.GenerateFromResourceGraphDefinition(rgd)
+		if err != nil {
+			return fmt.Errorf("failed to generate the schema of %s: %w", rgd.Name, err)
+		}
+		documents = append(documents, generated...)
+	}
+
+	if opts.output == "" {
+		if len(documents) > 1 {
+			return fmt.Errorf("found %d schemas, set an output directory to write them", len(documents))
+		}
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), string(documents[0].Data))
+		return err
+	}
+	if err := os.MkdirAll(opts.output, 0o755); err != nil {
+		return err
+	}
+	for _, document := range documents {
+		file := filepath.Join(opts.output, document.FileName)
+		if err := os.WriteFile(file, append(document.Data, '\n'), 0o644); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%s %s: %s\n", document.APIVersion, document.Kind, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/golden"
+)
+
+type snapshotOptions struct {
+	files             []string
+	instances         []string
+	crds              []string
+	golden            string
+	update            bool
+	kubernetesVersion string
+}
+
+func newSnapshotCommand() *cobra.Command {
+	opts := &snapshotOptions{}
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Compare the rendered resources of instances to golden files",
+		Long: "Render the resources of each of the given instances, as the render command does, and compare " +
+			"them to the golden files checked in the given directory. The golden file of an instance is named " +
+			"after its kind and name, e.g webapp-my-app.yaml.\n\n" +
+			"The command fails if the rendered resources differ from their golden files, printing the " +
+			"differences, so that the unintended changes to the templates of ResourceGraphDefinitions are " +
+			"caught in continuous integration. When the changes are intended, run the command with --update " +
+			"to write the golden files, and review them along with the ResourceGraphDefinitions.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSnapshot(cmd, opts)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&opts.files, "file", "f", nil,
+		"Path to a file or directory of ResourceGraphDefinitions, can be repeated")
+	cmd.Flags().StringSliceVarP(&opts.instances, "instances", "i", nil,
+		"Path to a file or directory of instances, can be repeated")
+	cmd.Flags().StringSliceVar(&opts.crds, "crd", nil,
+		"Path to a file or directory of CustomResourceDefinitions of the custom kinds of the resources, can be repeated")
+	cmd.Flags().StringVar(&opts.golden, "golden", "", "Path to the directory of the golden files")
+	cmd.Flags().BoolVar(&opts.update, "update", false,
+		"Write the rendered resources to the golden files instead of comparing them")
+	cmd.Flags().StringVar(&opts.kubernetesVersion, "kubernetes-version", defaultKubernetesVersion,
+		"Version of the cluster the expressions see")
+	_ = cmd.MarkFlagRequired("file")
+	_ = cmd.MarkFlagRequired("instances")
+	_ = cmd.MarkFlagRequired("golden")
+	return cmd
+}
+
+func runSnapshot(cmd *cobra.Command, opts *snapshotOptions) error {
+	cmd.SilenceUsage = true
+
+	documents, err := readDocuments(opts.instances)
+	if err != nil {
+		return err
+	}
+	if len(documents) == 0 {
+		return fmt.Errorf("no instance found")
+	}
+	instances := make([]*unstructured.Unstructured, 0, len(documents))
+	for _, doc := range documents {
+		instance := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(doc.data, &instance.Object); err != nil {
+			return fmt.Errorf("failed to parse instance in %s: %w", doc.file, err)
+		}
+		instances = append(instances, instance)
+	}
+
+	loaded, err := loadManifests(append(opts.files, opts.crds...))
+	if err != nil {
+		return err
+	}
+	values, err := valuesObjects(loaded.others)
+	if err != nil {
+		return err
+	}
+	rgds := make([]*v1alpha1.ResourceGraphDefinition, 0, len(loaded.rgds))
+	for _, doc := range loaded.rgds {
+		rgd := &v1alpha1.ResourceGraphDefinition{}
+		if err := yaml.Unmarshal(doc.data, rgd); err != nil {
+			return fmt.Errorf("failed to parse ResourceGraphDefinition in %s: %w", doc.file, err)
+		}
+		rgds = append(rgds, rgd)
+	}
+
+	results, err := golden.Check(rgds, instances, opts.golden, golden.Options{
+		CRDs:              loaded.crds,
+		TypeLibraries:     loaded.libraries,
+		KubernetesVersion: opts.kubernetesVersion,
+		Values:            values,
+		Update:            opts.update,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	differ := 0
+	for _, result := range results {
+		status := "ok"
+		switch {
+		case result.Updated:
+			status = "updated"
+		case result.Diff != "":
+			status = "FAIL"
+			differ++
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", status, result.Instance, result.File); err != nil {
+			return err
+		}
+		if result.Diff != "" {
+			if _, err := fmt.Fprint(w, result.Diff); err != nil {
+				return err
+			}
+		}
+	}
+	if differ > 0 {
+		return fmt.Errorf("%d of %d golden files differ, run with --update to update them", differ, len(results))
+	}
+	return nil
+}
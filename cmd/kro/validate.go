@@ -0,0 +1,315 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/graph/schema"
+)
+
+// defaultKubernetesVersion is the version of the cluster the expressions see
+// offline.
+const defaultKubernetesVersion = "v1.31.0"
+
+type validateOptions struct {
+	files             []string
+	crds              []string
+	output            string
+	kubernetesVersion string
+}
+
+// validationResult is the result of the validation of a
+// ResourceGraphDefinition.
+type validationResult struct {
+	File     string   `json:"file"`
+	Name     string   `json:"name"`
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// validationReport is the machine readable output of the validation.
+type validationReport struct {
+	Valid                    bool               `json:"valid"`
+	ResourceGraphDefinitions []validationResult `json:"resourceGraphDefinitions"`
+}
+
+// document is a manifest read from a file.
+type document struct {
+	file string
+	data []byte
+}
+
+func newValidateCommand() *cobra.Command {
+	opts := &validateOptions{}
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate ResourceGraphDefinitions offline",
+		Long: "Validate ResourceGraphDefinitions without access to a cluster, the way kro validates them once " +
+			"applied: the simpleschema of the instances is transformed, the expressions are compiled and type " +
+			"checked against the schemas of the instances and of the resources, the dependency graph is checked " +
+			"for cycles and the templates are checked to be valid Kubernetes objects.\n\n" +
+			"The schemas of the built-in kinds are known offline. The schemas of the custom kinds are read from " +
+			"their CustomResourceDefinitions, given with --crd or along with the ResourceGraphDefinitions, and " +
+			"ResourceGraphTypeLibraries found along with them provide their shared types. The kinds whose schema " +
+			"isn't known are reported as warnings: their templates aren't type checked, and the expressions " +
+			"reading their fields fail to be dry-run.\n\n" +
+			"The command fails if any ResourceGraphDefinition is invalid.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidate(cmd, opts)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&opts.files, "file", "f", nil,
+		"Path to a file or directory of ResourceGraphDefinitions, can be repeated")
+	cmd.Flags().StringSliceVar(&opts.crds, "crd", nil,
+		"Path to a file or directory of CustomResourceDefinitions of the custom kinds of the resources, can be repeated")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output format, one of text or json")
+	cmd.Flags().StringVar(&opts.kubernetesVersion, "kubernetes-version", defaultKubernetesVersion,
+		"Version of the cluster the expressions see")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func runValidate(cmd *cobra.Command, opts *validateOptions) error {
+	if opts.output != "text" && opts.output != "json" {
+		return fmt.Errorf("unsupported output %q, expected text or json", opts.output)
+	}
+	cmd.SilenceUsage = true
+
+	loaded, err := loadManifests(append(opts.files, opts.crds...))
+	if err != nil {
+		return err
+	}
+	if len(loaded.rgds) == 0 {
+		return fmt.Errorf("no ResourceGraphDefinition found")
+	}
+	sharedTypes, err := graph.MergeTypeLibraries(loaded.libraries)
+	if err != nil {
+		return err
+	}
+
+	report := validationReport{Valid: true}
+	for _, doc := range loaded.rgds {
+		result, err := validateResourceGraphDefinition(doc, loaded.crds, sharedTypes, opts.kubernetesVersion)
+		if err != nil {
+			return err
+		}
+		report.Valid = report.Valid && result.Valid
+		report.ResourceGraphDefinitions = append(report.ResourceGraphDefinitions, result)
+	}
+
+	if err := writeReport(cmd.OutOrStdout(), opts.output, report); err != nil {
+		return err
+	}
+	if !report.Valid {
+		invalid := 0
+		for _, result := range report.ResourceGraphDefinitions {
+			if !result.Valid {
+				invalid++
+			}
+		}
+		return fmt.Errorf("%d of %d ResourceGraphDefinitions are invalid", invalid, len(report.ResourceGraphDefinitions))
+	}
+	return nil
+}
+
+// validateResourceGraphDefinition builds the graph of the given
+// ResourceGraphDefinition offline.
+func validateResourceGraphDefinition(
+	doc document,
+	crds []*extv1.CustomResourceDefinition,
+	sharedTypes map[string]interface{},
+	kubernetesVersion string,
+) (validationResult, error) {
+	result := validationResult{File: doc.file}
+	rgd := &v1alpha1.ResourceGraphDefinition{}
+	if err := yaml.Unmarshal(doc.data, rgd); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to parse ResourceGraphDefinition: %v", err))
+		return result, nil
+	}
+	result.Name = rgd.Name
+
+	resolver, err := schema.NewOfflineResolver(crds)
+	if err != nil {
+		return result, err
+	}
+	builder := graph.NewOfflineBuilder(resolver, kubernetesVersion)
+	g, err := builder.NewResourceGraphDefinition(rgd, graph.WithSharedTypes(sharedTypes))
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	} else {
+		result.Warnings = append(result.Warnings, g.Warnings...)
+	}
+	for _, gvk := range resolver.Unresolved() {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("schema of %s is unknown, its CustomResourceDefinition can be given with --crd", gvk))
+	}
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+func writeReport(w io.Writer, output string, report validationReport) error {
+	if output == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	for _, result := range report.ResourceGraphDefinitions {
+		name := result.Name
+		if name == "" {
+			name = "<unnamed>"
+		}
+		status := "valid"
+		if !result.Valid {
+			status = "invalid"
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s\n", result.File, name, status); err != nil {
+			return err
+		}
+		for _, message := range result.Errors {
+			if _, err := fmt.Fprintf(w, "  error: %s\n", message); err != nil {
+				return err
+			}
+		}
+		for _, message := range result.Warnings {
+			if _, err := fmt.Fprintf(w, "  warning: %s\n", message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// manifests are the manifests read from files, by kind.
+type manifests struct {
+	rgds      []document
+	crds      []*extv1.CustomResourceDefinition
+	libraries []v1alpha1.ResourceGraphTypeLibrary
+	// others are the manifests of any other kind.
+	others []document
+}
+
+// loadManifests reads the manifests of the given files and directories. The
+// ResourceGraphDefinitions are parsed later on, so that their errors are
+// reported along with the other validation errors.
+func loadManifests(paths []string) (*manifests, error) {
+	documents, err := readDocuments(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &manifests{}
+	for _, doc := range documents {
+		var meta struct {
+			Kind string `json:"kind"`
+		}
+		if err := yaml.Unmarshal(doc.data, &meta); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", doc.file, err)
+		}
+		switch meta.Kind {
+		case "ResourceGraphDefinition":
+			loaded.rgds = append(loaded.rgds, doc)
+		case "CustomResourceDefinition":
+			crd := &extv1.CustomResourceDefinition{}
+			if err := yaml.Unmarshal(doc.data, crd); err != nil {
+				return nil, fmt.Errorf("failed to parse CustomResourceDefinition in %s: %w", doc.file, err)
+			}
+			loaded.crds = append(loaded.crds, crd)
+		case "ResourceGraphTypeLibrary":
+			library := v1alpha1.ResourceGraphTypeLibrary{}
+			if err := yaml.Unmarshal(doc.data, &library); err != nil {
+				return nil, fmt.Errorf("failed to parse ResourceGraphTypeLibrary in %s: %w", doc.file, err)
+			}
+			loaded.libraries = append(loaded.libraries, library)
+		default:
+			loaded.others = append(loaded.others, doc)
+		}
+	}
+	return loaded, nil
+}
+
+// readDocuments reads the YAML documents of the given files, and of the YAML
+// files of the given directories.
+func readDocuments(paths []string) ([]document, error) {
+	var documents []document
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			// Only the YAML files of the directories are read.
+			if file != path && !isYAML(file) {
+				return nil
+			}
+			docs, err := readFile(file)
+			if err != nil {
+				return err
+			}
+			documents = append(documents, docs...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return documents, nil
+}
+
+func readFile(file string) ([]document, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var documents []document
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		documents = append(documents, document{file: file, data: doc})
+	}
+}
+
+func isYAML(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".yaml" || ext == ".yml"
+}
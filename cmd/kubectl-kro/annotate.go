@@ -0,0 +1,154 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// instanceStatePlanned is the state of the instances whose changes are
+// planned, as reported by kro.
+const instanceStatePlanned = "PLANNED"
+
+// newPauseCommand returns the command pausing, or resuming, the
+// reconciliation of an instance.
+func newPauseCommand(flags *clusterFlags, pause bool) *cobra.Command {
+	use, short, long := "pause", "Pause the reconciliation of an instance",
+		"Pause the reconciliation of an instance: kro stops creating, updating and deleting its resources, "+
+			"but keeps reporting their state in the status of the instance."
+	if !pause {
+		use, short, long = "resume", "Resume the reconciliation of a paused instance",
+			"Resume the reconciliation of a paused instance: kro applies the changes made in the meantime."
+	}
+	return &cobra.Command{
+		Use:     use + " RGD NAME",
+		Short:   short,
+		Long:    long,
+		Example: fmt.Sprintf("  kubectl kro %s webapplication my-app -n team-a", use),
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dyn, rgd, instance, err := getInstance(cmd.Context(), flags, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if _, err := annotateInstance(cmd.Context(), dyn, rgd, instance, metadata.PausedAnnotation, pause); err != nil {
+				return err
+			}
+			state := "paused"
+			if !pause {
+				state = "resumed"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s/%s %s\n", strings.ToLower(instance.GetKind()), instance.GetName(), state)
+			return nil
+		},
+	}
+}
+
+type planOptions struct {
+	clear   bool
+	timeout time.Duration
+}
+
+func newPlanCommand(flags *clusterFlags) *cobra.Command {
+	opts := &planOptions{}
+	cmd := &cobra.Command{
+		Use:   "plan RGD NAME",
+		Short: "Preview the changes kro would make to the resources of an instance",
+		Long: "Preview the changes kro would make to the resources of an instance, without applying them. The " +
+			"instance is switched to plan mode, and the changes are printed once kro has planned them. They are " +
+			"applied once the plan mode is cleared with --clear.",
+		Example: "  kubectl kro plan webapplication my-app\n" +
+			"  kubectl kro plan webapplication my-app --clear",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlan(cmd, flags, opts, args[0], args[1])
+		},
+	}
+	cmd.Flags().BoolVar(&opts.clear, "clear", false, "Leave the plan mode, letting kro apply the changes")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 30*time.Second, "How long to wait for kro to plan the changes")
+	return cmd
+}
+
+func runPlan(cmd *cobra.Command, flags *clusterFlags, opts *planOptions, ref, name string) error {
+	ctx := cmd.Context()
+	dyn, rgd, instance, err := getInstance(ctx, flags, ref, name)
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	kindName := strings.ToLower(instance.GetKind()) + "/" + instance.GetName()
+
+	if opts.clear {
+		if _, err := annotateInstance(ctx, dyn, rgd, instance, metadata.PlanAnnotation, false); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s left the plan mode, its changes are applied\n", kindName)
+		return nil
+	}
+
+	if !metadata.IsPlanned(instance) {
+		if instance, err = annotateInstance(ctx, dyn, rgd, instance, metadata.PlanAnnotation, true); err != nil {
+			return err
+		}
+	}
+	// The plan is reported once kro has reconciled the instance in plan mode.
+	gvr := instanceGVR(rgd)
+	err = wait.PollUntilContextTimeout(ctx, time.Second, opts.timeout, true, func(ctx context.Context) (bool, error) {
+		if instanceState(instance) == instanceStatePlanned {
+			return true, nil
+		}
+		instance, err = dyn.Resource(gvr).Namespace(instance.GetNamespace()).Get(ctx, instance.GetName(), metav1.GetOptions{})
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wait for the plan of %s: %w", kindName, err)
+	}
+	return writePlan(w, kindName, instance)
+}
+
+func writePlan(w io.Writer, kindName string, instance *unstructured.Unstructured) error {
+	changes := statusEntries(instance, "plan")
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "No changes planned for %s.\n", kindName)
+		return nil
+	}
+	fmt.Fprintf(w, "Changes planned for %s, applied with --clear:\n\n", kindName)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "ID\tACTION\tKIND\tNAME\tDETAILS")
+	for _, change := range changes {
+		name := stringField(change, "name")
+		if namespace := stringField(change, "namespace"); namespace != "" && name != "" {
+			name = namespace + "/" + name
+		}
+		details := stringField(change, "message")
+		if fields, _, _ := unstructured.NestedStringSlice(change, "fields"); len(fields) > 0 {
+			details = strings.Join(fields, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", stringField(change, "id"), stringField(change, "action"),
+			orDash(stringField(change, "kind")), orDash(name), orDash(details))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/client"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// rgdGVR is the resource of the ResourceGraphDefinitions.
+var rgdGVR = v1alpha1.GroupVersion.WithResource("resourcegraphdefinitions")
+
+// clusterFlags are the flags selecting the cluster and the namespace, as
+// kubectl has them.
+type clusterFlags struct {
+	config clientcmd.ClientConfig
+}
+
+func newClusterFlags(cmd *cobra.Command) *clusterFlags {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{}
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&loadingRules.ExplicitPath, clientcmd.RecommendedConfigPathFlag, "",
+		"Path to the kubeconfig file to use")
+	clientcmd.BindOverrideFlags(overrides, flags, clientcmd.RecommendedConfigOverrideFlags(""))
+	return &clusterFlags{config: clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)}
+}
+
+// dynamicClient returns a client of the selected cluster.
+func (f *clusterFlags) dynamicClient() (dynamic.Interface, error) {
+	config, err := f.config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	set, err := client.NewSet(client.Config{RestConfig: config})
+	if err != nil {
+		return nil, err
+	}
+	return set.Dynamic(), nil
+}
+
+// namespace returns the selected namespace, defaulting to the namespace of
+// the current context.
+func (f *clusterFlags) namespace() (string, error) {
+	namespace, _, err := f.config.Namespace()
+	return namespace, err
+}
+
+// listRGDs returns the ResourceGraphDefinitions of the cluster, sorted by
+// name.
+func listRGDs(ctx context.Context, dyn dynamic.Interface) ([]*v1alpha1.ResourceGraphDefinition, error) {
+	list, err := dyn.Resource(rgdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ResourceGraphDefinitions: %w", err)
+	}
+	rgds := make([]*v1alpha1.ResourceGraphDefinition, 0, len(list.Items))
+	for _, item := range list.Items {
+		rgd := &v1alpha1.ResourceGraphDefinition{}
+		if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(item.Object, rgd); err != nil {
+			return nil, fmt.Errorf("failed to parse ResourceGraphDefinition %s: %w", item.GetName(), err)
+		}
+		rgds = append(rgds, rgd)
+	}
+	sort.Slice(rgds, func(i, j int) bool { return rgds[i].Name < rgds[j].Name })
+	return rgds, nil
+}
+
+// findRGD returns the ResourceGraphDefinition referenced by its name, or by
+// the kind of its instances, in any case, singular or plural.
+func findRGD(ctx context.Context, dyn dynamic.Interface, ref string) (*v1alpha1.ResourceGraphDefinition, error) {
+	rgds, err := listRGDs(ctx, dyn)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*v1alpha1.ResourceGraphDefinition
+	for _, rgd := range rgds {
+		if rgd.Name == ref {
+			return rgd, nil
+		}
+		if rgd.Spec.Schema == nil {
+			continue
+		}
+		kind := strings.ToLower(rgd.Spec.Schema.Kind)
+		if lower := strings.ToLower(ref); lower == kind || lower == instanceGVR(rgd).Resource {
+			matches = append(matches, rgd)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no ResourceGraphDefinition named %q or serving the %q kind", ref, ref)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, rgd := range matches {
+			names = append(names, rgd.Name)
+		}
+		return nil, fmt.Errorf("%q is served by several ResourceGraphDefinitions, use one of their names: %s",
+			ref, strings.Join(names, ", "))
+	}
+}
+
+// instanceGVR returns the resource of the instances of the given
+// ResourceGraphDefinition.
+func instanceGVR(rgd *v1alpha1.ResourceGraphDefinition) schema.GroupVersionResource {
+	group := rgd.Spec.Schema.Group
+	if group == "" {
+		group = v1alpha1.KRODomainName
+	}
+	return metadata.GetResourceGraphDefinitionInstanceGVR(group, rgd.Spec.Schema.APIVersion, rgd.Spec.Schema.Kind)
+}
+
+// getInstance returns the instance referenced by the kind of its instances, or
+// the name of its ResourceGraphDefinition, and its name.
+func getInstance(
+	ctx context.Context,
+	flags *clusterFlags,
+	ref, name string,
+) (dynamic.Interface, *v1alpha1.ResourceGraphDefinition, *unstructured.Unstructured, error) {
+	dyn, err := flags.dynamicClient()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	namespace, err := flags.namespace()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rgd, err := findRGD(ctx, dyn, ref)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	instance, err := dyn.Resource(instanceGVR(rgd)).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return dyn, rgd, instance, nil
+}
+
+// annotateInstance sets the given boolean annotation of the instance to true,
+// or removes it.
+func annotateInstance(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	instance *unstructured.Unstructured,
+	annotation string,
+	set bool,
+) (*unstructured.Unstructured, error) {
+	// Annotations are removed by a merge patch setting them to null.
+	var value interface{}
+	if set {
+		value = "true"
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{annotation: value},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dyn.Resource(instanceGVR(rgd)).Namespace(instance.GetNamespace()).
+		Patch(ctx, instance.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// instanceState returns the state kro reports for the instance.
+func instanceState(instance *unstructured.Unstructured) string {
+	state, _, _ := unstructured.NestedString(instance.Object, "status", "state")
+	return state
+}
+
+// instanceConditionStatus returns the status of the given condition of the
+// instance.
+func instanceConditionStatus(instance *unstructured.Unstructured, conditionType string) string {
+	for _, condition := range statusEntries(instance, "conditions") {
+		if condition["type"] == conditionType {
+			return stringField(condition, "status")
+		}
+	}
+	return ""
+}
+
+func age(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newRGDsCommand(flags *clusterFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:     "rgds",
+		Aliases: []string{"rgd", "resourcegraphdefinitions"},
+		Short:   "List the ResourceGraphDefinitions installed in the cluster",
+		Long: "List the ResourceGraphDefinitions installed in the cluster, with the kind of their instances, " +
+			"their state and their number of instances across all namespaces.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dyn, err := flags.dynamicClient()
+			if err != nil {
+				return err
+			}
+			rgds, err := listRGDs(cmd.Context(), dyn)
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tAPIVERSION\tKIND\tSTATE\tINSTANCES\tAGE")
+			for _, rgd := range rgds {
+				apiVersion, kind, instances := "", "", ""
+				if rgd.Spec.Schema != nil {
+					gvr := instanceGVR(rgd)
+					apiVersion = gvr.GroupVersion().String()
+					kind = rgd.Spec.Schema.Kind
+					// The instances can't be listed until the CRD is created.
+					list, err := dyn.Resource(gvr).List(cmd.Context(), metav1.ListOptions{})
+					if err == nil {
+						instances = strconv.Itoa(len(list.Items))
+					}
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", rgd.Name, orDash(apiVersion), orDash(kind),
+					orDash(string(rgd.Status.State)), orDash(instances), age(rgd.CreationTimestamp))
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+type instancesOptions struct {
+	allNamespaces bool
+}
+
+func newInstancesCommand(flags *clusterFlags) *cobra.Command {
+	opts := &instancesOptions{}
+	cmd := &cobra.Command{
+		Use:     "instances RGD",
+		Aliases: []string{"instance", "ls"},
+		Short:   "List the instances of a ResourceGraphDefinition",
+		Long: "List the instances of a ResourceGraphDefinition, referenced by its name or by the kind of its " +
+			"instances, with the state kro reports for them and whether their resources are synced.",
+		Example: "  kubectl kro instances webapplication -A",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dyn, err := flags.dynamicClient()
+			if err != nil {
+				return err
+			}
+			namespace := metav1.NamespaceAll
+			if !opts.allNamespaces {
+				if namespace, err = flags.namespace(); err != nil {
+					return err
+				}
+			}
+			rgd, err := findRGD(cmd.Context(), dyn, args[0])
+			if err != nil {
+				return err
+			}
+			list, err := dyn.Resource(instanceGVR(rgd)).Namespace(namespace).List(cmd.Context(), metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list the instances of %s: %w", rgd.Name, err)
+			}
+			instances := list.Items
+			sort.Slice(instances, func(i, j int) bool {
+				if instances[i].GetNamespace() != instances[j].GetNamespace() {
+					return instances[i].GetNamespace() < instances[j].GetNamespace()
+				}
+				return instances[i].GetName() < instances[j].GetName()
+			})
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+			if opts.allNamespaces {
+				fmt.Fprint(tw, "NAMESPACE\t")
+			}
+			fmt.Fprintln(tw, "NAME\tSTATE\tSYNCED\tAGE")
+			for i := range instances {
+				instance := &instances[i]
+				if opts.allNamespaces {
+					fmt.Fprintf(tw, "%s\t", instance.GetNamespace())
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", instance.GetName(), orDash(instanceState(instance)),
+					orDash(instanceConditionStatus(instance, "InstanceSynced")), age(instance.GetCreationTimestamp()))
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false, "List the instances of all namespaces")
+	return cmd
+}
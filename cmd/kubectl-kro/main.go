@@ -0,0 +1,54 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// kubectl-kro is a kubectl plugin operating ResourceGraphDefinitions and their
+// instances. It is installed by putting it in the PATH, and run as
+// `kubectl kro`.
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "kubectl-kro",
+	Short: "Operate kro ResourceGraphDefinitions and their instances",
+	Long: "Operate kro ResourceGraphDefinitions and their instances: list them, inspect the resources of an " +
+		"instance, pause and resume its reconciliation, preview the changes kro would make to it, and export the " +
+		"JSON Schema of the instances.\n\n" +
+		"Instances are referenced by the kind of their instances, or the name of their ResourceGraphDefinition, " +
+		"followed by their name.",
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cobra.CommandDisplayNameAnnotation: "kubectl kro",
+	},
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	flags := newClusterFlags(rootCmd)
+	rootCmd.AddCommand(newRGDsCommand(flags))
+	rootCmd.AddCommand(newInstancesCommand(flags))
+	rootCmd.AddCommand(newStatusCommand(flags))
+	rootCmd.AddCommand(newPauseCommand(flags, true))
+	rootCmd.AddCommand(newPauseCommand(flags, false))
+	rootCmd.AddCommand(newPlanCommand(flags))
+	rootCmd.AddCommand(newSchemaCommand(flags))
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kro-run/kro/pkg/jsonschema"
+)
+
+// crdGVR is the resource of the CustomResourceDefinitions.
+var crdGVR = extv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+
+type schemaOptions struct {
+	version string
+	output  string
+}
+
+func newSchemaCommand(flags *clusterFlags) *cobra.Command {
+	opts := &schemaOptions{}
+	cmd := &cobra.Command{
+		Use:   "schema RGD",
+		Short: "Export the JSON Schema of the instances of a ResourceGraphDefinition",
+		Long: "Export the JSON Schema of the instances of a ResourceGraphDefinition, converted from the " +
+			"CustomResourceDefinition kro generated for it, so that IDEs and client-side validators such as " +
+			"kubeconform can validate instance manifests before they reach the cluster.\n\n" +
+			"With an output directory, the schema of each version is written to a file named after the kind " +
+			"and version of the instances, e.g webapp_v1alpha1.json. Otherwise the schema of a single version " +
+			"is written to stdout.",
+		Example: "  kubectl kro schema webapplication > webapplication.json\n" +
+			"  kubectl kro schema webapplication -o schemas/",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSchema(cmd, flags, opts, args[0])
+		},
+	}
+	cmd.Flags().StringVar(&opts.version, "version", "", "Version of the instances, defaults to all of them")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the directory the schemas are written to")
+	return cmd
+}
+
+func runSchema(cmd *cobra.Command, flags *clusterFlags, opts *schemaOptions, ref string) error {
+	dyn, err := flags.dynamicClient()
+	if err != nil {
+		return err
+	}
+	rgd, err := findRGD(cmd.Context(), dyn, ref)
+	if err != nil {
+		return err
+	}
+	if rgd.Spec.Schema == nil {
+		return fmt.Errorf("ResourceGraphDefinition %s has no schema", rgd.Name)
+	}
+	gvr := instanceGVR(rgd)
+	name := gvr.Resource + "." + gvr.Group
+	object, err := dyn.Resource(crdGVR).Get(cmd.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the CustomResourceDefinition of %s: %w", rgd.Name, err)
+	}
+	crd := &extv1.CustomResourceDefinition{}
+	if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(object.Object, crd); err != nil {
+		return fmt.Errorf("failed to parse CustomResourceDefinition %s: %w", name, err)
+	}
+
+	documents, err := jsonschema.GenerateFromCRD(crd)
+	if err != nil {
+		return err
+	}
+	if opts.version != "" {
+		var selected []jsonschema.Document
+		for _, document := range documents {
+			if document.APIVersion == crd.Spec.Group+"/"+opts.version {
+				selected = append(selected, document)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("ResourceGraphDefinition %s doesn't serve version %s", rgd.Name, opts.version)
+		}
+		documents = selected
+	}
+
+	if opts.output == "" {
+		if len(documents) > 1 {
+			return fmt.Errorf("%s serves %d versions, select one with --version or set an output directory",
+				rgd.Name, len(documents))
+		}
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), string(documents[0].Data))
+		return err
+	}
+	if err := os.MkdirAll(opts.output, 0o755); err != nil {
+		return err
+	}
+	for _, document := range documents {
+		file := filepath.Join(opts.output, document.FileName)
+		if err := os.WriteFile(file, append(document.Data, '\n'), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s: %s\n", document.APIVersion, document.Kind, file)
+	}
+	return nil
+}
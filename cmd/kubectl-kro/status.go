@@ -0,0 +1,129 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+func newStatusCommand(flags *clusterFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status RGD NAME",
+		Short: "Show the status of an instance and of its resources",
+		Long: "Show the status of an instance: its state and conditions, and the state of each of its resources, " +
+			"in the order kro creates them, with the resources they depend on.",
+		Example: "  kubectl kro status webapplication my-app -n team-a",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, rgd, instance, err := getInstance(cmd.Context(), flags, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return writeStatus(cmd.OutOrStdout(), rgd, instance)
+		},
+	}
+}
+
+func writeStatus(w io.Writer, rgd *v1alpha1.ResourceGraphDefinition, instance *unstructured.Unstructured) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", instance.GetName())
+	fmt.Fprintf(tw, "Namespace:\t%s\n", instance.GetNamespace())
+	fmt.Fprintf(tw, "Kind:\t%s\n", instance.GroupVersionKind().GroupKind())
+	fmt.Fprintf(tw, "ResourceGraphDefinition:\t%s\n", rgd.Name)
+	fmt.Fprintf(tw, "State:\t%s\n", orDash(instanceState(instance)))
+	var requests []string
+	if metadata.IsPaused(instance) {
+		requests = append(requests, "paused")
+	}
+	if metadata.IsPlanned(instance) {
+		requests = append(requests, "plan")
+	}
+	if len(requests) > 0 {
+		fmt.Fprintf(tw, "Requested:\t%s\n", strings.Join(requests, ", "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	conditions := statusEntries(instance, "conditions")
+	if len(conditions) > 0 {
+		fmt.Fprintln(w, "\nConditions:")
+		tw = tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(tw, "  TYPE\tSTATUS\tREASON\tMESSAGE")
+		for _, condition := range conditions {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", stringField(condition, "type"), stringField(condition, "status"),
+				orDash(stringField(condition, "reason")), orDash(stringField(condition, "message")))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	// The dependencies of the resources are reported by the
+	// ResourceGraphDefinition.
+	dependencies := make(map[string][]string, len(rgd.Status.Resources))
+	for _, resource := range rgd.Status.Resources {
+		for _, dependency := range resource.Dependencies {
+			dependencies[resource.ID] = append(dependencies[resource.ID], dependency.ID)
+		}
+	}
+
+	resources := statusEntries(instance, "resources")
+	fmt.Fprintln(w, "\nResources:")
+	if len(resources) == 0 {
+		fmt.Fprintln(w, "  No resource reported yet.")
+		return nil
+	}
+	tw = tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "  ID\tKIND\tNAME\tSTATE\tREADY\tDEPENDS ON\tMESSAGE")
+	for _, resource := range resources {
+		id := stringField(resource, "id")
+		name := stringField(resource, "name")
+		if namespace := stringField(resource, "namespace"); namespace != "" && name != "" {
+			name = namespace + "/" + name
+		}
+		ready, _ := resource["ready"].(bool)
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%t\t%s\t%s\n", id, orDash(stringField(resource, "kind")), orDash(name),
+			orDash(stringField(resource, "state")), ready, orDash(strings.Join(dependencies[id], ", ")),
+			orDash(stringField(resource, "message")))
+	}
+	return tw.Flush()
+}
+
+// statusEntries returns the entries of the given list of the status of the
+// instance.
+func statusEntries(instance *unstructured.Unstructured, field string) []map[string]interface{} {
+	list, _, _ := unstructured.NestedSlice(instance.Object, "status", field)
+	entries := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if entry, ok := item.(map[string]interface{}); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func stringField(entry map[string]interface{}, field string) string {
+	value, _ := entry[field].(string)
+	return value
+}
@@ -0,0 +1,300 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package apidoc generates the reference documentation of the instances of
+// ResourceGraphDefinitions from their schema, for the consumers of the APIs
+// platform teams publish with kro.
+package apidoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/codegen"
+	"github.com/kro-run/kro/pkg/simpleschema"
+)
+
+// Format is the format of the generated documentation.
+type Format string
+
+const (
+	// FormatMarkdown generates a Markdown document.
+	FormatMarkdown Format = "markdown"
+	// FormatHTML generates a standalone HTML page.
+	FormatHTML Format = "html"
+)
+
+// immutableRule is the validation rule of the fields marked immutable.
+const immutableRule = "self == oldSelf"
+
+// Options configures the generated documentation.
+type Options struct {
+	// Format is the format of the documentation. Defaults to Markdown.
+	Format Format
+}
+
+// API is the documentation of the instances of a kind.
+type API struct {
+	// Kind is the kind of the instances.
+	Kind string
+	// APIVersion is the group and version of the instances, e.g
+	// kro.run/v1alpha1.
+	APIVersion string
+	// Spec and Status are the fields of the spec and of the status of the
+	// instances, in depth-first order.
+	Spec   []Field
+	Status []Field
+	// Validations are the validation rules of the whole spec.
+	Validations []string
+}
+
+// Field is the documentation of a field of the instances.
+type Field struct {
+	// Path is the path of the field, e.g `spec.ingress.host`. The items of
+	// lists are denoted by `[]`, and the values of maps by `*`.
+	Path string
+	// Type is the type of the field, e.g `string`, `[]object` or
+	// `map[string]integer`.
+	Type string
+	// Required is true if the field must be set when its parent is.
+	Required bool
+	// Default is the JSON encoded default value of the field.
+	Default string
+	// Description is the description of the field.
+	Description string
+	// Validations are the constraints on the values of the field.
+	Validations []string
+}
+
+// GenerateFromResourceGraphDefinition generates the documentation of the
+// instances of the given ResourceGraphDefinition.
+//
+// The types of status fields are inferred from the resources of the graph,
+// which requires access to a cluster. Status fields set by a standalone
+// expression are documented with the `any` type. To get fully typed status
+// fields, use GenerateFromCRD with the CRD generated by kro.
+func GenerateFromResourceGraphDefinition(rgd *v1alpha1.ResourceGraphDefinition, opts Options) ([]byte, error) {
+	instanceCRD, err := codegen.InstanceCRD(rgd)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateFromCRD(instanceCRD, opts)
+}
+
+// GenerateFromCRD generates the documentation of the storage version of the
+// given CustomResourceDefinition.
+func GenerateFromCRD(crd *extv1.CustomResourceDefinition, opts Options) ([]byte, error) {
+	api, err := NewAPI(crd)
+	if err != nil {
+		return nil, err
+	}
+	switch opts.Format {
+	case FormatMarkdown, "":
+		return renderMarkdown(api), nil
+	case FormatHTML:
+		return renderHTML(api)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", opts.Format)
+	}
+}
+
+// NewAPI returns the documentation of the storage version of the given
+// CustomResourceDefinition.
+func NewAPI(crd *extv1.CustomResourceDefinition) (*API, error) {
+	var version *extv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Storage {
+			version = &crd.Spec.Versions[i]
+			break
+		}
+	}
+	if version == nil {
+		return nil, fmt.Errorf("CRD %s has no storage version", crd.Name)
+	}
+	if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+		return nil, fmt.Errorf("CRD %s version %s has no schema", crd.Name, version.Name)
+	}
+
+	api := &API{
+		Kind:       crd.Spec.Names.Kind,
+		APIVersion: crd.Spec.Group + "/" + version.Name,
+	}
+	schema := version.Schema.OpenAPIV3Schema
+	if spec, ok := schema.Properties["spec"]; ok {
+		api.Spec = fields(&spec, "spec")
+		api.Validations = validationRules(&spec)
+	}
+	if status, ok := schema.Properties["status"]; ok {
+		api.Status = fields(&status, "status")
+	}
+	return api, nil
+}
+
+// fields returns the documentation of the fields of the given object schema,
+// and of their own fields, in depth-first order.
+func fields(schema *extv1.JSONSchemaProps, path string) []Field {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []Field
+	for _, name := range names {
+		property := schema.Properties[name]
+		fieldPath := path + "." + name
+		field := Field{
+			Path:        fieldPath,
+			Type:        typeName(&property),
+			Required:    required[name],
+			Description: property.Description,
+			Validations: validations(&property),
+		}
+		if property.Default != nil {
+			field.Default = string(property.Default.Raw)
+		}
+		result = append(result, field)
+		result = append(result, nestedFields(&property, fieldPath)...)
+	}
+	return result
+}
+
+// nestedFields returns the documentation of the fields of the objects held by
+// the given field.
+func nestedFields(schema *extv1.JSONSchemaProps, path string) []Field {
+	switch {
+	case len(schema.Properties) > 0:
+		return fields(schema, path)
+	case schema.Items != nil && schema.Items.Schema != nil:
+		return nestedFields(schema.Items.Schema, path+"[]")
+	case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+		return nestedFields(schema.AdditionalProperties.Schema, path+".*")
+	}
+	return nil
+}
+
+// typeName returns the name of the type of the given schema.
+func typeName(schema *extv1.JSONSchemaProps) string {
+	switch {
+	case schema.XIntOrString:
+		return "int-or-string"
+	case schema.Type == "array":
+		if schema.Items != nil && schema.Items.Schema != nil {
+			return "[]" + typeName(schema.Items.Schema)
+		}
+		return "[]any"
+	case schema.Type == "object" && len(schema.Properties) == 0 &&
+		schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+		return "map[string]" + typeName(schema.AdditionalProperties.Schema)
+	case schema.Type == "":
+		return "any"
+	}
+	return schema.Type
+}
+
+// validations returns the constraints of the given schema on the values of
+// its field.
+func validations(schema *extv1.JSONSchemaProps) []string {
+	var result []string
+	if len(schema.Enum) > 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			values = append(values, string(value.Raw))
+		}
+		result = append(result, "one of: "+strings.Join(values, ", "))
+	}
+	if schema.Minimum != nil {
+		result = append(result, bound("minimum", *schema.Minimum, schema.ExclusiveMinimum))
+	}
+	if schema.Maximum != nil {
+		result = append(result, bound("maximum", *schema.Maximum, schema.ExclusiveMaximum))
+	}
+	if schema.MultipleOf != nil {
+		result = append(result, fmt.Sprintf("multiple of: %g", *schema.MultipleOf))
+	}
+	if schema.MinLength != nil {
+		result = append(result, fmt.Sprintf("min length: %d", *schema.MinLength))
+	}
+	if schema.MaxLength != nil {
+		result = append(result, fmt.Sprintf("max length: %d", *schema.MaxLength))
+	}
+	if schema.Pattern != "" {
+		result = append(result, "pattern: "+schema.Pattern)
+	}
+	switch schema.Format {
+	case "":
+	case simpleschema.SensitiveFormat:
+		result = append(result, "sensitive")
+	default:
+		result = append(result, "format: "+schema.Format)
+	}
+	if schema.MinItems != nil {
+		result = append(result, fmt.Sprintf("min items: %d", *schema.MinItems))
+	}
+	if schema.MaxItems != nil {
+		result = append(result, fmt.Sprintf("max items: %d", *schema.MaxItems))
+	}
+	if schema.UniqueItems {
+		result = append(result, "unique items")
+	}
+	if schema.XListType != nil && *schema.XListType != "atomic" {
+		listType := "list type: " + *schema.XListType
+		if len(schema.XListMapKeys) > 0 {
+			listType += ", keys: " + strings.Join(schema.XListMapKeys, ", ")
+		}
+		result = append(result, listType)
+	}
+	if schema.MinProperties != nil {
+		result = append(result, fmt.Sprintf("min properties: %d", *schema.MinProperties))
+	}
+	if schema.MaxProperties != nil {
+		result = append(result, fmt.Sprintf("max properties: %d", *schema.MaxProperties))
+	}
+	if schema.Nullable {
+		result = append(result, "nullable")
+	}
+	return append(result, validationRules(schema)...)
+}
+
+// validationRules returns the CEL validation rules of the given schema, with
+// their messages.
+func validationRules(schema *extv1.JSONSchemaProps) []string {
+	var result []string
+	for _, rule := range schema.XValidations {
+		switch {
+		case rule.Rule == immutableRule:
+			result = append(result, "immutable")
+		case rule.Message != "":
+			result = append(result, fmt.Sprintf("rule: %s (%s)", rule.Rule, rule.Message))
+		default:
+			result = append(result, "rule: "+rule.Rule)
+		}
+	}
+	return result
+}
+
+func bound(name string, value float64, exclusive bool) string {
+	if exclusive {
+		return fmt.Sprintf("%s: %g (exclusive)", name, value)
+	}
+	return fmt.Sprintf("%s: %g", name, value)
+}
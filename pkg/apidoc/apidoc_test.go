@@ -0,0 +1,135 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package apidoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/codegen"
+)
+
+func newWebAppRGD() *v1alpha1.ResourceGraphDefinition {
+	return &v1alpha1.ResourceGraphDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "webapp"},
+		Spec: v1alpha1.ResourceGraphDefinitionSpec{
+			Schema: &v1alpha1.Schema{
+				APIVersion: "v1alpha1",
+				Kind:       "WebApp",
+				Spec: runtime.RawExtension{Raw: []byte(`{
+					"name": "string | required=true description=\"Name of the application.\"",
+					"replicas": "integer | default=1 minimum=1 maximum=10",
+					"tier": "string | enum=\"web,worker\" immutable=true",
+					"password": "string | sensitive=true",
+					"labels": "map[string]string",
+					"ports": "[]Port"
+				}`)},
+				Types: runtime.RawExtension{Raw: []byte(`{"Port": {"port": "integer | required=true"}}`)},
+				Status: runtime.RawExtension{Raw: []byte(`{
+					"url": "https://${service.spec.clusterIP}",
+					"replicas": "${deployment.status.availableReplicas}"
+				}`)},
+				Validations: []v1alpha1.Validation{{
+					Expression: "self.replicas <= 5 || self.tier == 'web'",
+					Message:    "workers are limited to 5 replicas",
+				}},
+			},
+		},
+	}
+}
+
+func TestNewAPI(t *testing.T) {
+	instanceCRD, err := codegen.InstanceCRD(newWebAppRGD())
+	require.NoError(t, err)
+
+	api, err := NewAPI(instanceCRD)
+	require.NoError(t, err)
+	assert.Equal(t, "WebApp", api.Kind)
+	assert.Equal(t, "kro.run/v1alpha1", api.APIVersion)
+
+	spec := make(map[string]Field, len(api.Spec))
+	var paths []string
+	for _, field := range api.Spec {
+		spec[field.Path] = field
+		paths = append(paths, field.Path)
+	}
+	assert.Equal(t, []string{
+		"spec.labels",
+		"spec.name",
+		"spec.password",
+		"spec.ports",
+		"spec.ports[].port",
+		"spec.replicas",
+		"spec.tier",
+	}, paths)
+
+	assert.Equal(t, Field{
+		Path:        "spec.name",
+		Type:        "string",
+		Required:    true,
+		Description: "Name of the application.",
+	}, spec["spec.name"])
+	assert.Equal(t, "map[string]string", spec["spec.labels"].Type)
+	assert.Equal(t, "[]object", spec["spec.ports"].Type)
+	assert.True(t, spec["spec.ports[].port"].Required)
+	assert.Equal(t, "1", spec["spec.replicas"].Default)
+	assert.Equal(t, []string{"minimum: 1", "maximum: 10"}, spec["spec.replicas"].Validations)
+	assert.Equal(t, []string{`one of: "web", "worker"`, "immutable"}, spec["spec.tier"].Validations)
+	assert.Equal(t, []string{"sensitive"}, spec["spec.password"].Validations)
+	assert.Equal(t, []string{
+		"rule: self.replicas <= 5 || self.tier == 'web' (workers are limited to 5 replicas)",
+	}, api.Validations)
+
+	status := make(map[string]Field, len(api.Status))
+	for _, field := range api.Status {
+		status[field.Path] = field
+	}
+	assert.Equal(t, "string", status["status.url"].Type)
+	assert.Equal(t, "any", status["status.replicas"].Type)
+	assert.Contains(t, status, "status.state")
+	assert.Contains(t, status, "status.conditions[].type")
+}
+
+func TestGenerateFromResourceGraphDefinition(t *testing.T) {
+	markdown, err := GenerateFromResourceGraphDefinition(newWebAppRGD(), Options{})
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"# WebApp",
+		"API version: `kro.run/v1alpha1`",
+		"| `spec.name` | `string` | yes |  | Name of the application. |  |",
+		"| `spec.replicas` | `integer` | no | `1` |  | `minimum: 1`<br>`maximum: 10` |",
+		"- `rule: self.replicas <= 5 \\|\\| self.tier == 'web' (workers are limited to 5 replicas)`",
+		"| `status.url` | `string` |  |",
+	} {
+		assert.Contains(t, string(markdown), expected)
+	}
+
+	html, err := GenerateFromResourceGraphDefinition(newWebAppRGD(), Options{Format: FormatHTML})
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"<h1>WebApp</h1>",
+		"<tr><td><code>spec.name</code></td><td><code>string</code></td><td>yes</td>",
+		"<code>rule: self.replicas &lt;= 5 || self.tier == &#39;web&#39; (workers are limited to 5 replicas)</code>",
+	} {
+		assert.Contains(t, string(html), expected)
+	}
+
+	_, err = GenerateFromResourceGraphDefinition(newWebAppRGD(), Options{Format: "pdf"})
+	assert.Error(t, err)
+}
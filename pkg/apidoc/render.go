@@ -0,0 +1,146 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package apidoc
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// markdownEscaper escapes the text of Markdown table cells.
+var markdownEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"<", "&lt;",
+	">", "&gt;",
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+func renderMarkdown(api *API) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", api.Kind)
+	fmt.Fprintf(&b, "API version: `%s`\n", api.APIVersion)
+
+	b.WriteString("\n## Spec\n\n")
+	if len(api.Spec) == 0 {
+		b.WriteString("The spec has no fields.\n")
+	} else {
+		b.WriteString("| Field | Type | Required | Default | Description | Validation |\n")
+		b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		for _, field := range api.Spec {
+			required := "no"
+			if field.Required {
+				required = "yes"
+			}
+			defaultValue := ""
+			if field.Default != "" {
+				defaultValue = markdownCode(field.Default)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+				markdownCode(field.Path), markdownCode(field.Type), required, defaultValue,
+				markdownEscaper.Replace(field.Description), markdownCodeList(field.Validations))
+		}
+	}
+	if len(api.Validations) > 0 {
+		b.WriteString("\nThe spec is validated by the following rules:\n\n")
+		for _, validation := range api.Validations {
+			fmt.Fprintf(&b, "- %s\n", markdownCode(validation))
+		}
+	}
+
+	if len(api.Status) > 0 {
+		b.WriteString("\n## Status\n\n")
+		b.WriteString("| Field | Type | Description |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, field := range api.Status {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n",
+				markdownCode(field.Path), markdownCode(field.Type), markdownEscaper.Replace(field.Description))
+		}
+	}
+	return b.Bytes()
+}
+
+// markdownCode returns the given text as an inline code span of a table cell.
+func markdownCode(s string) string {
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "\n", " "), "|", `\|`)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+func markdownCodeList(values []string) string {
+	codes := make([]string, 0, len(values))
+	for _, value := range values {
+		codes = append(codes, markdownCode(value))
+	}
+	return strings.Join(codes, "<br>")
+}
+
+var htmlTemplate = template.Must(template.New("apidoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Kind }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+td.description { white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>{{ .Kind }}</h1>
+<p>API version: <code>{{ .APIVersion }}</code></p>
+<h2>Spec</h2>
+{{- if .Spec }}
+<table>
+<tr><th>Field</th><th>Type</th><th>Required</th><th>Default</th><th>Description</th><th>Validation</th></tr>
+{{- range .Spec }}
+<tr><td><code>{{ .Path }}</code></td><td><code>{{ .Type }}</code></td><td>{{ if .Required }}yes{{ else }}no{{ end }}</td><td>{{ if .Default }}<code>{{ .Default }}</code>{{ end }}</td><td class="description">{{ .Description }}</td><td>{{ range $i, $v := .Validations }}{{ if $i }}<br>{{ end }}<code>{{ $v }}</code>{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>The spec has no fields.</p>
+{{- end }}
+{{- if .Validations }}
+<p>The spec is validated by the following rules:</p>
+<ul>
+{{- range .Validations }}
+<li><code>{{ . }}</code></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Status }}
+<h2>Status</h2>
+<table>
+<tr><th>Field</th><th>Type</th><th>Description</th></tr>
+{{- range .Status }}
+<tr><td><code>{{ .Path }}</code></td><td><code>{{ .Type }}</code></td><td class="description">{{ .Description }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+</body>
+</html>
+`))
+
+func renderHTML(api *API) ([]byte, error) {
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, api); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
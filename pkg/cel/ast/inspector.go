@@ -159,7 +159,7 @@ func NewInspectorWithEnv(env *cel.Env, resources []string, functions []string) *
 func (a *Inspector) Inspect(expression string) (ExpressionInspection, error) {
 	ast, iss := a.env.Parse(expression)
 	if iss.Err() != nil {
-		return ExpressionInspection{}, fmt.Errorf("failed to parse expression: %v", iss.Err())
+		return ExpressionInspection{}, fmt.Errorf("failed to parse expression: %w", krocel.NewCompileError(iss))
 	}
 
 	parsed, err := cel.AstToParsedExpr(ast)
@@ -220,6 +220,15 @@ func (a *Inspector) inspectCall(call *exprpb.Expr_Call, currentPath string) Expr
 		inspection.FunctionCalls = append(inspection.FunctionCalls, functionCall)
 	}
 
+	// Functions declared in a namespace of the environment, e.g base64.decode,
+	// are parsed as method calls on an identifier.
+	if ident, ok := call.Target.GetExprKind().(*exprpb.Expr_IdentExpr); ok && a.isNamespacedFunction(ident.IdentExpr.Name, call.Function) {
+		inspection.FunctionCalls = append(inspection.FunctionCalls, FunctionCall{
+			Name: ident.IdentExpr.Name + "." + call.Function,
+		})
+		return inspection
+	}
+
 	// Then handle the target if it exists
 	if call.Target != nil {
 		targetInspection := a.inspectAst(call.Target, currentPath)
@@ -232,14 +241,28 @@ func (a *Inspector) inspectCall(call *exprpb.Expr_Call, currentPath string) Expr
 		inspection.FunctionCalls = append(inspection.FunctionCalls, FunctionCall{
 			Name: fmt.Sprintf("%s.%s", a.exprToString(call.Target), call.Function),
 		})
-	} else if !isInternalFunction(call.Function) {
-		// This is an unknown function, but not an internal one
+	} else if !isInternalFunction(call.Function) && !a.env.HasFunction(call.Function) {
+		// This is an unknown function, neither an internal one nor one
+		// declared by the environment, e.g registered by the operator.
 		inspection.UnknownFunctions = append(inspection.UnknownFunctions, UnknownFunction{Name: call.Function})
 	}
 
 	return inspection
 }
 
+// isNamespacedFunction returns true if the given call on an identifier is a
+// call to a function declared in a namespace of the environment, rather than a
+// method call on a resource or a loop variable.
+func (a *Inspector) isNamespacedFunction(namespace, function string) bool {
+	if _, isResource := a.resources[namespace]; isResource {
+		return false
+	}
+	if _, isLoopVar := a.loopVars[namespace]; isLoopVar {
+		return false
+	}
+	return a.env.HasFunction(namespace + "." + function)
+}
+
 // inspectIdent analyzes identifier expressions in CEL and determines if they are known resources
 // or unknown references. It handles the base identifiers in field access chains and distinguishes
 // between declared resources and unknown/internal identifiers.
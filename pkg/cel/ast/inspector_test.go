@@ -16,6 +16,10 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+
+	"github.com/google/cel-go/cel"
+
+	krocel "github.com/kro-run/kro/pkg/cel"
 )
 
 func TestInspector_InspectionResults(t *testing.T) {
@@ -375,6 +379,17 @@ func TestInspector_UnknownResourcesAndCalls(t *testing.T) {
 				{ID: "unknownResource", Path: "unknownResource"},
 			},
 		},
+		{
+			name:       "namespaced function",
+			resources:  []string{"secret"},
+			expression: `string(base64.decode(secret.data.password))`,
+			wantResources: []ResourceDependency{
+				{ID: "secret", Path: "secret.data.password"},
+			},
+			wantFunctions: []FunctionCall{
+				{Name: "base64.decode"},
+			},
+		},
 		{
 			name:          "chained method calls on unknown resource",
 			resources:     []string{},
@@ -526,3 +541,29 @@ func Test_InvalidExpression(t *testing.T) {
 		t.Errorf("Expected error")
 	}
 }
+
+func TestInspector_EnvironmentFunctions(t *testing.T) {
+	env, err := krocel.DefaultEnvironment(
+		krocel.WithResourceIDs([]string{"schema"}),
+		krocel.WithCustomDeclarations([]cel.EnvOption{
+			cel.Function("costCenterFor",
+				cel.Overload("cost_center_for_string", []*cel.Type{cel.StringType}, cel.StringType)),
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create environment: %v", err)
+	}
+	inspector := NewInspectorWithEnv(env, []string{"schema"}, nil)
+
+	got, err := inspector.Inspect(`costCenterFor(schema.spec.team)`)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if len(got.UnknownFunctions) != 0 {
+		t.Errorf("UnknownFunctions = %v, want none", got.UnknownFunctions)
+	}
+	want := []ResourceDependency{{ID: "schema", Path: "schema.spec.team"}}
+	if !reflect.DeepEqual(got.ResourceDependencies, want) {
+		t.Errorf("ResourceDependencies = %v, want %v", got.ResourceDependencies, want)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+// ProgramCache caches the programs of the expressions of a resource graph
+// definition, so that the expressions are compiled once rather than every
+// time an instance is reconciled. The programs are compiled in the default
+// environment, with the PerExpressionCostLimit.
+//
+// A cache is built along with the graph of a generation of the resource graph
+// definition, and dropped with it. It is safe for concurrent use. A nil cache
+// compiles the expressions on every call.
+type ProgramCache struct {
+	mu sync.Mutex
+	// envs are the environments, keyed by their sorted variables.
+	envs map[string]*cel.Env
+	// programs are the programs, keyed by the key of their environment and
+	// their expression.
+	programs map[programKey]cel.Program
+}
+
+type programKey struct {
+	env        string
+	expression string
+}
+
+// NewProgramCache returns an empty program cache.
+func NewProgramCache() *ProgramCache {
+	return &ProgramCache{
+		envs:     make(map[string]*cel.Env),
+		programs: make(map[programKey]cel.Program),
+	}
+}
+
+// Program returns the program of the given expression, compiled in the
+// default environment declaring the given variables.
+func (c *ProgramCache) Program(variables []string, expression string) (cel.Program, error) {
+	// Variables can be listed twice, e.g a resource shadowing a variable.
+	variables = slices.Compact(slices.Sorted(slices.Values(variables)))
+	if c == nil {
+		env, err := DefaultEnvironment(WithResourceIDs(variables))
+		if err != nil {
+			return nil, fmt.Errorf("failed creating environment: %w", err)
+		}
+		return compileProgram(env, expression)
+	}
+
+	key := programKey{env: strings.Join(variables, ","), expression: expression}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if program, ok := c.programs[key]; ok {
+		return program, nil
+	}
+	env, ok := c.envs[key.env]
+	if !ok {
+		var err error
+		if env, err = DefaultEnvironment(WithResourceIDs(variables)); err != nil {
+			return nil, fmt.Errorf("failed creating environment: %w", err)
+		}
+		c.envs[key.env] = env
+	}
+	program, err := compileProgram(env, expression)
+	if err != nil {
+		return nil, err
+	}
+	c.programs[key] = program
+	return program, nil
+}
+
+func compileProgram(env *cel.Env, expression string) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, NewCompileError(issues)
+	}
+	return NewProgram(env, ast, PerExpressionCostLimit)
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgramCache(t *testing.T) {
+	cache := NewProgramCache()
+
+	program, err := cache.Program([]string{"schema", "vpc"}, "schema.spec.name + vpc.status.id")
+	require.NoError(t, err)
+
+	// The same expression in an environment declaring the same variables
+	// reuses the program.
+	cached, err := cache.Program([]string{"vpc", "schema"}, "schema.spec.name + vpc.status.id")
+	require.NoError(t, err)
+	assert.Same(t, program, cached)
+	assert.Len(t, cache.envs, 1)
+
+	other, err := cache.Program([]string{"schema"}, "schema.spec.name")
+	require.NoError(t, err)
+	assert.NotSame(t, program, other)
+	assert.Len(t, cache.envs, 2)
+	assert.Len(t, cache.programs, 2)
+
+	val, _, err := Evaluate(program, map[string]interface{}{
+		"schema": map[string]interface{}{"spec": map[string]interface{}{"name": "a"}},
+		"vpc":    map[string]interface{}{"status": map[string]interface{}{"id": "b"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ab", val.Value())
+
+	// Undeclared variables fail to compile, and aren't cached.
+	_, err = cache.Program([]string{"schema"}, "vpc.status.id")
+	assert.Error(t, err)
+	assert.Len(t, cache.programs, 2)
+
+	// A nil cache compiles the expressions on every call.
+	var nilCache *ProgramCache
+	program, err = nilCache.Program([]string{"schema"}, "schema.spec.name")
+	require.NoError(t, err)
+	val, _, err = Evaluate(program, map[string]interface{}{
+		"schema": map[string]interface{}{"spec": map[string]interface{}{"name": "a"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a", val.Value())
+}
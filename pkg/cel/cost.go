@@ -0,0 +1,59 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+const (
+	// PerExpressionCostLimit is the maximum cost of a single evaluation of an
+	// expression. It matches the per call limit of the CEL validation rules
+	// of Kubernetes.
+	PerExpressionCostLimit uint64 = 1_000_000
+	// InstanceCostBudget is the maximum total cost of the expressions
+	// evaluated while reconciling an instance.
+	InstanceCostBudget uint64 = 10 * PerExpressionCostLimit
+)
+
+// ErrCostLimitExceeded is returned when the evaluation of an expression is
+// cancelled because its cost exceeded its limit.
+var ErrCostLimitExceeded = errors.New("cost limit exceeded")
+
+// NewProgram returns the program of the given checked expression. Its
+// evaluations are cancelled as soon as their cost exceeds the given limit.
+func NewProgram(env *cel.Env, ast *cel.Ast, costLimit uint64) (cel.Program, error) {
+	return env.Program(ast, cel.CostLimit(costLimit))
+}
+
+// Evaluate evaluates the given program, returning the value of the expression
+// along with the cost of the evaluation. Evaluations cancelled because their
+// cost exceeded the limit of the program return ErrCostLimitExceeded.
+func Evaluate(program cel.Program, vars interface{}) (ref.Val, uint64, error) {
+	val, details, err := program.Eval(vars)
+	var cost uint64
+	if details != nil && details.ActualCost() != nil {
+		cost = *details.ActualCost()
+	}
+	var cancelled interpreter.EvalCancelledError
+	if errors.As(err, &cancelled) && cancelled.Cause == interpreter.CostLimitExceeded {
+		return nil, cost, fmt.Errorf("%w: evaluation cancelled after a cost of %d", ErrCostLimitExceeded, cost)
+	}
+	return val, cost, err
+}
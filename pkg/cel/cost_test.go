@@ -0,0 +1,74 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	env, err := DefaultEnvironment(WithResourceIDs([]string{"schema"}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		expression string
+		costLimit  uint64
+		want       interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "cheap expression",
+			expression: `schema.spec.replicas + 1`,
+			costLimit:  PerExpressionCostLimit,
+			want:       int64(4),
+		},
+		{
+			name:       "nested comprehensions exceeding the limit",
+			expression: `lists.range(1000).map(i, lists.range(1000).map(j, i * j)).size()`,
+			costLimit:  PerExpressionCostLimit,
+			wantErr:    true,
+		},
+		{
+			name:       "cheap expression exceeding a small limit",
+			expression: `[1, 2, 3].map(x, x * 2).size()`,
+			costLimit:  2,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, issues := env.Compile(tt.expression)
+			require.NoError(t, issues.Err())
+			program, err := NewProgram(env, ast, tt.costLimit)
+			require.NoError(t, err)
+
+			val, cost, err := Evaluate(program, map[string]interface{}{
+				"schema": map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(3)}},
+			})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrCostLimitExceeded)
+				assert.Greater(t, cost, tt.costLimit)
+				return
+			}
+			require.NoError(t, err)
+			assert.Positive(t, cost)
+			assert.Equal(t, tt.want, val.Value())
+		})
+	}
+}
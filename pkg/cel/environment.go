@@ -46,21 +46,30 @@ func WithCustomDeclarations(declarations []cel.EnvOption) EnvOption {
 	}
 }
 
-// DefaultEnvironment returns the default CEL environment.
+// DefaultEnvironment returns the default CEL environment. It declares the
+// functions registered with RegisterFunctions along with the functions of kro.
 func DefaultEnvironment(options ...EnvOption) (*cel.Env, error) {
 	opts := &envOptions{}
 	for _, opt := range options {
 		opt(opts)
 	}
 
-	declarations := []cel.EnvOption{
-		// default stdlibs
-		ext.Lists(),
-		ext.Strings(),
-	}
-
+	declarations := append(defaultDeclarations(), registeredFunctions()...)
 	for _, name := range opts.resourceIDs {
 		declarations = append(declarations, cel.Variable(name, cel.AnyType))
 	}
+	declarations = append(declarations, opts.customDeclarations...)
 	return cel.NewEnv(declarations...)
 }
+
+// defaultDeclarations returns the libraries of the default environment.
+func defaultDeclarations() []cel.EnvOption {
+	return []cel.EnvOption{
+		// default stdlibs
+		ext.Lists(),
+		ext.Strings(),
+		ext.Encoders(),
+		// kro helpers
+		Helpers(),
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+// CompileError is the error of an expression that failed to parse or type
+// check. It locates the first issue found in the expression.
+type CompileError struct {
+	// Line and Column are the position of the issue in the expression,
+	// starting at 1. They're 0 if the issue isn't located.
+	Line   int
+	Column int
+	Err    error
+}
+
+// NewCompileError returns the error of the given issues of an expression.
+func NewCompileError(issues *cel.Issues) error {
+	err := &CompileError{Err: issues.Err()}
+	if errs := issues.Errors(); len(errs) > 0 && errs[0].Location != nil && errs[0].Location.Line() > 0 {
+		// CEL columns start at 0.
+		err.Line, err.Column = errs[0].Location.Line(), errs[0].Location.Column()+1
+	}
+	return err
+}
+
+func (e *CompileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CompileError) Unwrap() error {
+	return e.Err
+}
+
+// ExpressionError is an error of an expression of a resource graph definition,
+// located by the resource and the field declaring it. Expressions are often
+// repeated across resources, the expression alone doesn't tell which one
+// failed.
+type ExpressionError struct {
+	// ResourceID is the id of the resource declaring the expression. It's
+	// empty for the expressions of the schema, e.g its status.
+	ResourceID string
+	// Field is the path of the field declaring the expression, relative to
+	// the resource or to the schema, e.g `template.spec.replicas` or
+	// `readyWhen[0]`.
+	Field      string
+	Expression string
+	// Line and Column are the position of the error in the expression,
+	// starting at 1. They're only known for compilation errors.
+	Line   int
+	Column int
+	Err    error
+}
+
+// NewExpressionError returns the given error of the expression declared by
+// the given field of a resource. Errors already located are returned as is.
+func NewExpressionError(resourceID, field, expression string, err error) error {
+	var located *ExpressionError
+	if errors.As(err, &located) {
+		return err
+	}
+	exprErr := &ExpressionError{
+		ResourceID: resourceID,
+		Field:      field,
+		Expression: expression,
+		Err:        err,
+	}
+	var compileErr *CompileError
+	if errors.As(err, &compileErr) {
+		exprErr.Line, exprErr.Column = compileErr.Line, compileErr.Column
+	}
+	return exprErr
+}
+
+func (e *ExpressionError) Error() string {
+	location := "field " + e.Field
+	if e.ResourceID != "" {
+		location = fmt.Sprintf("resource %s, %s", e.ResourceID, location)
+	}
+	if e.Line > 0 {
+		location += fmt.Sprintf(", line %d, column %d of the expression", e.Line, e.Column)
+	}
+	return fmt.Sprintf("%s: %v", location, e.Err)
+}
+
+func (e *ExpressionError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressionError(t *testing.T) {
+	env, err := DefaultEnvironment(WithResourceIDs([]string{"schema"}))
+	require.NoError(t, err)
+
+	_, issues := env.Compile("schema.spec.name +\n  vpc.status.id")
+	require.Error(t, issues.Err())
+	compileErr := NewCompileError(issues)
+
+	tests := []struct {
+		name       string
+		resourceID string
+		field      string
+		err        error
+		want       string
+		wantLine   int
+		wantColumn int
+	}{
+		{
+			name:       "compilation error",
+			resourceID: "vpc",
+			field:      "template.metadata.name",
+			err:        fmt.Errorf("failed to compile expression: %w", compileErr),
+			want:       "resource vpc, field template.metadata.name, line 2, column 3 of the expression: failed to compile expression: ",
+			wantLine:   2,
+			wantColumn: 3,
+		},
+		{
+			name:       "evaluation error",
+			resourceID: "vpc",
+			field:      "readyWhen[0]",
+			err:        errors.New("no such key: status"),
+			want:       "resource vpc, field readyWhen[0]: no such key: status",
+		},
+		{
+			name:  "expression of the schema",
+			field: "schema.status.vpcID",
+			err:   errors.New("no such key: status"),
+			want:  "field schema.status.vpcID: no such key: status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewExpressionError(tt.resourceID, tt.field, "schema.spec.name", tt.err)
+			assert.Contains(t, err.Error(), tt.want)
+			assert.ErrorIs(t, err, tt.err)
+
+			var exprErr *ExpressionError
+			require.ErrorAs(t, err, &exprErr)
+			assert.Equal(t, tt.wantLine, exprErr.Line)
+			assert.Equal(t, tt.wantColumn, exprErr.Column)
+
+			// Located errors aren't located again.
+			assert.Same(t, err, NewExpressionError("other", "includeWhen[0]", "true", err))
+		})
+	}
+}
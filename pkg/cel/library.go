@@ -0,0 +1,347 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"k8s.io/apimachinery/pkg/util/version"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Helpers returns the helper functions kro adds to the CEL environment of the
+// expressions. They're declared in namespaces, like the encoders of the CEL
+// extensions:
+//
+//	base64.encode(<string>) -> <string>
+//	hash.sha256(<string>) -> <string>, the hex encoded digest
+//	hash.stable(<dyn>, <int>) -> <string>, the first hex digits of the digest of a value
+//	names.truncate(<string>, <int>) -> <string>, the name shortened with a hash suffix
+//	regex.replace(<string>, <pattern>, <replacement>) -> <string>
+//	yaml.marshal(<dyn>) -> <string>
+//	yaml.unmarshal(<string>) -> <dyn>
+//	maps.merge(<map>, <map>) -> <map>, the values of the second map winning
+//	semver.compare(<string>, <string>) -> <int>, -1, 0 or 1
+//	cidr.host(<prefix>, <int>) -> <string>, the nth address of the prefix
+//	cidr.subnet(<prefix>, <newbits>, <int>) -> <string>, the nth subnet of the prefix
+//	cidr.contains(<prefix>, <address>) -> <bool>
+func Helpers() cel.EnvOption {
+	return cel.Lib(helpersLib{})
+}
+
+type helpersLib struct{}
+
+func (helpersLib) LibraryName() string {
+	return "kro.run.helpers"
+}
+
+func (helpersLib) CompileOptions() []cel.EnvOption {
+	mapType := cel.MapType(cel.DynType, cel.DynType)
+	return []cel.EnvOption{
+		cel.Function("base64.encode",
+			cel.Overload("base64_encode_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(stringFunction(func(s string) (ref.Val, error) {
+					return types.String(base64.StdEncoding.EncodeToString([]byte(s))), nil
+				})))),
+		cel.Function("hash.sha256",
+			cel.Overload("hash_sha256_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(stringFunction(func(s string) (ref.Val, error) {
+					sum := sha256.Sum256([]byte(s))
+					return types.String(hex.EncodeToString(sum[:])), nil
+				})))),
+		cel.Function("hash.stable",
+			cel.Overload("hash_stable_dyn_int", []*cel.Type{cel.DynType, cel.IntType}, cel.StringType,
+				cel.BinaryBinding(stableHash))),
+		cel.Function("names.truncate",
+			cel.Overload("names_truncate_string_int", []*cel.Type{cel.StringType, cel.IntType}, cel.StringType,
+				cel.BinaryBinding(truncateName))),
+		cel.Function("regex.replace",
+			cel.Overload("regex_replace_string_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType, cel.StringType}, cel.StringType,
+				cel.FunctionBinding(regexReplace))),
+		cel.Function("yaml.marshal",
+			cel.Overload("yaml_marshal_dyn", []*cel.Type{cel.DynType}, cel.StringType,
+				cel.UnaryBinding(yamlMarshal))),
+		cel.Function("yaml.unmarshal",
+			cel.Overload("yaml_unmarshal_string", []*cel.Type{cel.StringType}, cel.DynType,
+				cel.UnaryBinding(stringFunction(yamlUnmarshal)))),
+		cel.Function("maps.merge",
+			cel.Overload("maps_merge_map_map", []*cel.Type{mapType, mapType}, mapType,
+				cel.BinaryBinding(mergeMaps))),
+		cel.Function("semver.compare",
+			cel.Overload("semver_compare_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+				cel.BinaryBinding(semverCompare))),
+		cel.Function("cidr.host",
+			cel.Overload("cidr_host_string_int", []*cel.Type{cel.StringType, cel.IntType}, cel.StringType,
+				cel.BinaryBinding(cidrHost))),
+		cel.Function("cidr.subnet",
+			cel.Overload("cidr_subnet_string_int_int", []*cel.Type{cel.StringType, cel.IntType, cel.IntType}, cel.StringType,
+				cel.FunctionBinding(cidrSubnet))),
+		cel.Function("cidr.contains",
+			cel.Overload("cidr_contains_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(cidrContains))),
+	}
+}
+
+func (helpersLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+// stringFunction returns a unary binding of the given function of a string,
+// reporting its errors as CEL errors.
+func stringFunction(fn func(string) (ref.Val, error)) func(ref.Val) ref.Val {
+	return func(arg ref.Val) ref.Val {
+		s, ok := arg.(types.String)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(arg)
+		}
+		result, err := fn(string(s))
+		if err != nil {
+			return types.WrapErr(err)
+		}
+		return result
+	}
+}
+
+// stableHashDigits is the number of hex digits of the hash suffix of the
+// truncated names.
+const stableHashDigits = 8
+
+// digest returns the hex encoded SHA-256 digest of the JSON encoding of the
+// given value. Maps are encoded with sorted keys, the digest of a value
+// doesn't depend on the order of its entries.
+func digest(value ref.Val) (string, error) {
+	native, err := value.ConvertToNative(reflect.TypeOf((*any)(nil)).Elem())
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(native)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func stableHash(value, lengthVal ref.Val) ref.Val {
+	length, ok := lengthVal.(types.Int)
+	if !ok {
+		return types.NoSuchOverloadErr()
+	}
+	if length < 1 || length > sha256.Size*2 {
+		return types.NewErr("hash.stable: length %d must be between 1 and %d", length, sha256.Size*2)
+	}
+	sum, err := digest(value)
+	if err != nil {
+		return types.NewErr("hash.stable: %v", err)
+	}
+	return types.String(sum[:length])
+}
+
+// truncateName returns the given name if it's at most length characters
+// long. Longer names are cut, and suffixed with a hash of the whole name so
+// that names sharing a prefix don't collide once truncated.
+func truncateName(nameVal, lengthVal ref.Val) ref.Val {
+	name, ok1 := nameVal.(types.String)
+	length, ok2 := lengthVal.(types.Int)
+	if !ok1 || !ok2 {
+		return types.NoSuchOverloadErr()
+	}
+	if length <= stableHashDigits+1 {
+		return types.NewErr("names.truncate: length %d must be greater than %d", length, stableHashDigits+1)
+	}
+	if int64(len(name)) <= int64(length) {
+		return name
+	}
+	sum, err := digest(name)
+	if err != nil {
+		return types.NewErr("names.truncate: %v", err)
+	}
+	// The prefix doesn't end with a separator, the truncated name stays a
+	// valid DNS name.
+	prefix := strings.TrimRight(string(name[:int(length)-stableHashDigits-1]), "-.")
+	return types.String(prefix + "-" + sum[:stableHashDigits])
+}
+
+func regexReplace(args ...ref.Val) ref.Val {
+	s, ok1 := args[0].(types.String)
+	pattern, ok2 := args[1].(types.String)
+	replacement, ok3 := args[2].(types.String)
+	if !ok1 || !ok2 || !ok3 {
+		return types.NoSuchOverloadErr()
+	}
+	re, err := regexp.Compile(string(pattern))
+	if err != nil {
+		return types.NewErr("regex.replace: invalid pattern %q: %v", pattern, err)
+	}
+	return types.String(re.ReplaceAllString(string(s), string(replacement)))
+}
+
+func yamlMarshal(arg ref.Val) ref.Val {
+	value, err := arg.ConvertToNative(reflect.TypeOf((*any)(nil)).Elem())
+	if err != nil {
+		return types.NewErr("yaml.marshal: %v", err)
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return types.NewErr("yaml.marshal: %v", err)
+	}
+	return types.String(data)
+}
+
+func yamlUnmarshal(s string) (ref.Val, error) {
+	// Integers are decoded as integers, rather than as floats like JSON
+	// numbers.
+	var value interface{}
+	if err := utilyaml.Unmarshal([]byte(s), &value); err != nil {
+		return nil, fmt.Errorf("yaml.unmarshal: %w", err)
+	}
+	return types.DefaultTypeAdapter.NativeToValue(value), nil
+}
+
+func mergeMaps(base, override ref.Val) ref.Val {
+	baseMap, ok1 := base.(traits.Mapper)
+	overrideMap, ok2 := override.(traits.Mapper)
+	if !ok1 || !ok2 {
+		return types.NoSuchOverloadErr()
+	}
+	merged := make(map[ref.Val]ref.Val)
+	for _, m := range []traits.Mapper{baseMap, overrideMap} {
+		for it := m.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			merged[key] = m.Get(key)
+		}
+	}
+	return types.NewRefValMap(types.DefaultTypeAdapter, merged)
+}
+
+func semverCompare(a, b ref.Val) ref.Val {
+	s1, ok1 := a.(types.String)
+	s2, ok2 := b.(types.String)
+	if !ok1 || !ok2 {
+		return types.NoSuchOverloadErr()
+	}
+	v, err := version.ParseSemantic(string(s1))
+	if err != nil {
+		return types.NewErr("semver.compare: %v", err)
+	}
+	result, err := v.Compare(string(s2))
+	if err != nil {
+		return types.NewErr("semver.compare: %v", err)
+	}
+	return types.Int(result)
+}
+
+// parsePrefix parses the given CIDR prefix, masking its host bits.
+func parsePrefix(s ref.Val) (netip.Prefix, error) {
+	str, ok := s.(types.String)
+	if !ok {
+		return netip.Prefix{}, fmt.Errorf("no such overload")
+	}
+	prefix, err := netip.ParsePrefix(string(str))
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return prefix.Masked(), nil
+}
+
+// addressAt returns the address at the given offset from the given address,
+// in units of 2^shift addresses.
+func addressAt(addr netip.Addr, offset *big.Int, shift int) (netip.Addr, bool) {
+	value := new(big.Int).SetBytes(addr.AsSlice())
+	value.Add(value, new(big.Int).Lsh(offset, uint(shift)))
+	bytes := value.Bytes()
+	size := addr.BitLen() / 8
+	if len(bytes) > size {
+		return netip.Addr{}, false
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(bytes):], bytes)
+	result, _ := netip.AddrFromSlice(padded)
+	return result, true
+}
+
+func cidrHost(prefixVal, numVal ref.Val) ref.Val {
+	prefix, err := parsePrefix(prefixVal)
+	if err != nil {
+		return types.NewErr("cidr.host: %v", err)
+	}
+	num, ok := numVal.(types.Int)
+	if !ok {
+		return types.NoSuchOverloadErr()
+	}
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+	offset := big.NewInt(int64(num))
+	if offset.Sign() < 0 {
+		offset.Add(offset, size)
+	}
+	if offset.Sign() < 0 || offset.Cmp(size) >= 0 {
+		return types.NewErr("cidr.host: prefix %s has no host %d", prefix, num)
+	}
+	addr, _ := addressAt(prefix.Addr(), offset, 0)
+	return types.String(addr.String())
+}
+
+func cidrSubnet(args ...ref.Val) ref.Val {
+	prefix, err := parsePrefix(args[0])
+	if err != nil {
+		return types.NewErr("cidr.subnet: %v", err)
+	}
+	newBits, ok1 := args[1].(types.Int)
+	num, ok2 := args[2].(types.Int)
+	if !ok1 || !ok2 {
+		return types.NoSuchOverloadErr()
+	}
+	// newBits is checked before being added, so that it can't overflow.
+	if newBits < 0 || int64(newBits) > int64(prefix.Addr().BitLen()-prefix.Bits()) {
+		return types.NewErr("cidr.subnet: can't extend prefix %s by %d bits", prefix, newBits)
+	}
+	bits := prefix.Bits() + int(newBits)
+	if num < 0 || big.NewInt(int64(num)).Cmp(new(big.Int).Lsh(big.NewInt(1), uint(newBits))) >= 0 {
+		return types.NewErr("cidr.subnet: prefix %s has no subnet %d of %d bits", prefix, num, bits)
+	}
+	addr, _ := addressAt(prefix.Addr(), big.NewInt(int64(num)), prefix.Addr().BitLen()-bits)
+	return types.String(netip.PrefixFrom(addr, bits).String())
+}
+
+func cidrContains(prefixVal, addrVal ref.Val) ref.Val {
+	prefix, err := parsePrefix(prefixVal)
+	if err != nil {
+		return types.NewErr("cidr.contains: %v", err)
+	}
+	addr, ok := addrVal.(types.String)
+	if !ok {
+		return types.NoSuchOverloadErr()
+	}
+	ip, err := netip.ParseAddr(string(addr))
+	if err != nil {
+		return types.NewErr("cidr.contains: %v", err)
+	}
+	return types.Bool(prefix.Contains(ip))
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelpers(t *testing.T) {
+	env, err := DefaultEnvironment(WithResourceIDs([]string{"schema"}))
+	require.NoError(t, err)
+
+	schema := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"labels":  map[string]interface{}{"app": "web", "team": "a"},
+			"version": "v1.28.3",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       interface{}
+		wantErr    string
+	}{
+		{
+			name:       "base64 encode a string",
+			expression: `base64.encode("hello")`,
+			want:       "aGVsbG8=",
+		},
+		{
+			name:       "base64 encode bytes",
+			expression: `base64.encode(b"hello")`,
+			want:       "aGVsbG8=",
+		},
+		{
+			name:       "sha256",
+			expression: `hash.sha256("hello")`,
+			want:       "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		{
+			name:       "stable hash",
+			expression: `hash.stable("hello", 8)`,
+			want:       "5aa762ae",
+		},
+		{
+			name:       "stable hash of a map",
+			expression: `hash.stable({"team": "a", "app": "web"}, 12) == hash.stable(schema.spec.labels, 12)`,
+			want:       true,
+		},
+		{
+			name:       "stable hash too long",
+			expression: `hash.stable("hello", 65)`,
+			wantErr:    "must be between 1 and 64",
+		},
+		{
+			name:       "truncate short name",
+			expression: `names.truncate("web", 63)`,
+			want:       "web",
+		},
+		{
+			name:       "truncate long name",
+			expression: `names.truncate("my-very-long-application-name", 20)`,
+			want:       "my-very-lon-bd6601b1",
+		},
+		{
+			name:       "truncated names stay distinct",
+			expression: `names.truncate("my-very-long-name-a", 15) != names.truncate("my-very-long-name-b", 15)`,
+			want:       true,
+		},
+		{
+			name:       "truncate to a length shorter than the hash",
+			expression: `names.truncate("my-very-long-name", 9)`,
+			wantErr:    "must be greater than 9",
+		},
+		{
+			name:       "regex replace",
+			expression: `regex.replace("my_app-v2", "[^a-z0-9]+", "-")`,
+			want:       "my-app-v2",
+		},
+		{
+			name:       "regex replace with groups",
+			expression: `regex.replace("eu-west-1", "^([a-z]+)-.*$", "$1")`,
+			want:       "eu",
+		},
+		{
+			name:       "invalid regex",
+			expression: `regex.replace("a", "(", "")`,
+			wantErr:    "invalid pattern",
+		},
+		{
+			name:       "yaml marshal",
+			expression: `yaml.marshal(schema.spec.labels)`,
+			want:       "app: web\nteam: a\n",
+		},
+		{
+			name:       "yaml unmarshal",
+			expression: `yaml.unmarshal("replicas: 3\nports: [80]")`,
+			want:       map[string]interface{}{"replicas": int64(3), "ports": []interface{}{int64(80)}},
+		},
+		{
+			name:       "yaml round trip",
+			expression: `yaml.unmarshal(yaml.marshal(schema.spec.labels)).app`,
+			want:       "web",
+		},
+		{
+			name:       "merge maps",
+			expression: `maps.merge(schema.spec.labels, {"team": "b", "tier": "frontend"})`,
+			want:       map[string]interface{}{"app": "web", "team": "b", "tier": "frontend"},
+		},
+		{
+			name:       "semver compare",
+			expression: `semver.compare(schema.spec.version, "1.29.0")`,
+			want:       int64(-1),
+		},
+		{
+			name:       "semver equal",
+			expression: `semver.compare("1.2.3", "v1.2.3") == 0`,
+			want:       true,
+		},
+		{
+			name:       "invalid semver",
+			expression: `semver.compare("latest", "1.0.0")`,
+			wantErr:    "semver.compare",
+		},
+		{
+			name:       "cidr host",
+			expression: `cidr.host("10.0.0.0/16", 5)`,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "cidr last host",
+			expression: `cidr.host("10.0.0.0/24", -2)`,
+			want:       "10.0.0.254",
+		},
+		{
+			name:       "cidr host out of range",
+			expression: `cidr.host("10.0.0.0/24", 256)`,
+			wantErr:    "has no host 256",
+		},
+		{
+			name:       "cidr subnet",
+			expression: `cidr.subnet("10.0.0.0/16", 8, 2)`,
+			want:       "10.0.2.0/24",
+		},
+		{
+			name:       "cidr ipv6 subnet",
+			expression: `cidr.subnet("fd00::/56", 8, 1)`,
+			want:       "fd00:0:0:1::/64",
+		},
+		{
+			name:       "cidr subnet out of range",
+			expression: `cidr.subnet("10.0.0.0/16", 2, 4)`,
+			wantErr:    "has no subnet 4",
+		},
+		{
+			name:       "cidr subnet with too many new bits",
+			expression: `cidr.subnet("10.0.0.0/24", 9223372036854775807, 1)`,
+			wantErr:    "can't extend prefix 10.0.0.0/24 by 9223372036854775807 bits",
+		},
+		{
+			name:       "cidr contains",
+			expression: `cidr.contains("10.0.0.0/16", "10.0.42.1") && !cidr.contains("10.0.0.0/16", "10.1.0.1")`,
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, issues := env.Compile(tt.expression)
+			require.NoError(t, issues.Err())
+			program, err := env.Program(ast)
+			require.NoError(t, err)
+
+			out, _, err := program.Eval(map[string]interface{}{"schema": schema})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got, err := GoNativeType(out)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
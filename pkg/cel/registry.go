@@ -0,0 +1,70 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+var (
+	registryMu sync.RWMutex
+	// registered are the declarations of the functions registered by the
+	// operator of the controller.
+	registered []cel.EnvOption
+)
+
+// RegisterFunctions registers functions in the environment of the expressions
+// of every resource graph definition. It lets the operators of kro building
+// their own controller add the functions of their organization, declared with
+// cel.Function or bundled in a cel.Lib, e.g:
+//
+//	err := krocel.RegisterFunctions(
+//		cel.Function("costCenterFor",
+//			cel.Overload("cost_center_for_string", []*cel.Type{cel.StringType}, cel.StringType,
+//				cel.UnaryBinding(costCenterFor))),
+//	)
+//
+// The functions must be registered before the controller starts: the
+// expressions already compiled don't see them. An error is returned, and
+// nothing is registered, if the functions conflict with each other or with the
+// functions of kro.
+func RegisterFunctions(functions ...cel.EnvOption) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	declarations := append(slices.Clone(registered), functions...)
+	if _, err := cel.NewEnv(append(defaultDeclarations(), declarations...)...); err != nil {
+		return fmt.Errorf("invalid functions: %w", err)
+	}
+	registered = declarations
+	return nil
+}
+
+// registeredFunctions returns the declarations of the registered functions.
+func registeredFunctions() []cel.EnvOption {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(registered)
+}
+
+// resetRegisteredFunctions drops the registered functions, for the tests.
+func resetRegisteredFunctions() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registered = nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterFunctions(t *testing.T) {
+	t.Cleanup(resetRegisteredFunctions)
+
+	costCenterFor := cel.Function("costCenterFor",
+		cel.Overload("cost_center_for_string", []*cel.Type{cel.StringType}, cel.StringType,
+			cel.UnaryBinding(func(team ref.Val) ref.Val {
+				return types.String("cc-" + string(team.(types.String)))
+			})))
+	require.NoError(t, RegisterFunctions(costCenterFor))
+
+	program, err := NewProgramCache().Program([]string{"schema"}, "costCenterFor(schema.team)")
+	require.NoError(t, err)
+	val, _, err := Evaluate(program, map[string]interface{}{"schema": map[string]interface{}{"team": "web"}})
+	require.NoError(t, err)
+	assert.Equal(t, "cc-web", val.Value())
+
+	// A conflicting overload isn't registered.
+	err = RegisterFunctions(cel.Function("costCenterFor",
+		cel.Overload("cost_center_for_string", []*cel.Type{cel.StringType}, cel.IntType)))
+	assert.Error(t, err)
+	assert.Len(t, registeredFunctions(), 1)
+
+	resetRegisteredFunctions()
+	env, err := DefaultEnvironment()
+	require.NoError(t, err)
+	assert.False(t, env.HasFunction("costCenterFor"))
+}
@@ -16,6 +16,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -41,6 +42,9 @@ type CRDClient interface {
 	// EnsureCreated ensures a CRD exists and is ready
 	Ensure(ctx context.Context, crd v1.CustomResourceDefinition) error
 
+	// Apply creates or updates a CRD without waiting for it to be ready
+	Apply(ctx context.Context, crd v1.CustomResourceDefinition) (*v1.CustomResourceDefinition, error)
+
 	// Delete removes a CRD if it exists
 	Delete(ctx context.Context, name string) error
 
@@ -92,25 +96,56 @@ func newCRDWrapper(cfg CRDWrapperConfig) *CRDWrapper {
 // The caller is responsible for ensuring the CRD, isn't introducing
 // breaking changes.
 func (w *CRDWrapper) Ensure(ctx context.Context, crd v1.CustomResourceDefinition) error {
+	if _, err := w.Apply(ctx, crd); err != nil {
+		return err
+	}
+	return w.waitForReady(ctx, crd.Name)
+}
+
+// Apply creates a CRD, or updates it if it already exists, and returns it as
+// stored by the API server. Unlike Ensure, it doesn't wait for the CRD to be
+// established, see CRDEstablished.
+//
+// The caller is responsible for ensuring the CRD, isn't introducing
+// breaking changes.
+func (w *CRDWrapper) Apply(ctx context.Context, crd v1.CustomResourceDefinition) (*v1.CustomResourceDefinition, error) {
 	log := logr.FromContext(ctx)
 	_, err := w.Get(ctx, crd.Name)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to check for existing CRD: %w", err)
+			return nil, fmt.Errorf("failed to check for existing CRD: %w", err)
 		}
 
 		log.Info("Creating CRD", "name", crd.Name)
-		if err := w.create(ctx, crd); err != nil {
-			return fmt.Errorf("failed to create CRD: %w", err)
-		}
-	} else {
-		log.Info("Updating existing CRD", "name", crd.Name)
-		if err := w.patch(ctx, crd); err != nil {
-			return fmt.Errorf("failed to patch CRD: %w", err)
+		created, err := w.create(ctx, crd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CRD: %w", err)
 		}
+		return created, nil
 	}
 
-	return w.waitForReady(ctx, crd.Name)
+	log.V(1).Info("Updating existing CRD", "name", crd.Name)
+	patched, err := w.patch(ctx, crd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch CRD: %w", err)
+	}
+	return patched, nil
+}
+
+// CRDEstablished returns whether the given CRD is established, i.e served by
+// the API server. When it isn't, it also returns the reason reported by the
+// API server in the conditions of the CRD, if any.
+func CRDEstablished(crd *v1.CustomResourceDefinition) (bool, string) {
+	var reasons []string
+	for _, cond := range crd.Status.Conditions {
+		switch {
+		case cond.Type == v1.Established && cond.Status == v1.ConditionTrue:
+			return true, ""
+		case (cond.Type == v1.Established || cond.Type == v1.NamesAccepted) && cond.Status == v1.ConditionFalse:
+			reasons = append(reasons, fmt.Sprintf("%s: %s", cond.Reason, cond.Message))
+		}
+	}
+	return false, strings.Join(reasons, "; ")
 }
 
 // Get retrieves a CRD by name
@@ -118,25 +153,23 @@ func (w *CRDWrapper) Get(ctx context.Context, name string) (*v1.CustomResourceDe
 	return w.client.Get(ctx, name, metav1.GetOptions{})
 }
 
-func (w *CRDWrapper) create(ctx context.Context, crd v1.CustomResourceDefinition) error {
-	_, err := w.client.Create(ctx, &crd, metav1.CreateOptions{})
-	return err
+func (w *CRDWrapper) create(ctx context.Context, crd v1.CustomResourceDefinition) (*v1.CustomResourceDefinition, error) {
+	return w.client.Create(ctx, &crd, metav1.CreateOptions{})
 }
 
-func (w *CRDWrapper) patch(ctx context.Context, newCRD v1.CustomResourceDefinition) error {
+func (w *CRDWrapper) patch(ctx context.Context, newCRD v1.CustomResourceDefinition) (*v1.CustomResourceDefinition, error) {
 	patchBytes, err := json.Marshal(newCRD)
 	if err != nil {
-		return fmt.Errorf("failed to marshal CRD for patch: %w", err)
+		return nil, fmt.Errorf("failed to marshal CRD for patch: %w", err)
 	}
 
-	_, err = w.client.Patch(
+	return w.client.Patch(
 		ctx,
 		newCRD.Name,
 		types.MergePatchType,
 		patchBytes,
 		metav1.PatchOptions{},
 	)
-	return err
 }
 
 // Delete removes a CRD if it exists
@@ -166,12 +199,7 @@ func (w *CRDWrapper) waitForReady(ctx context.Context, name string) error {
 				return false, err
 			}
 
-			for _, cond := range crd.Status.Conditions {
-				if cond.Type == v1.Established && cond.Status == v1.ConditionTrue {
-					return true, nil
-				}
-			}
-
-			return false, nil
+			established, _ := CRDEstablished(crd)
+			return established, nil
 		})
 }
@@ -0,0 +1,47 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestCRDEstablished(t *testing.T) {
+	crd := func(conditions ...v1.CustomResourceDefinitionCondition) *v1.CustomResourceDefinition {
+		return &v1.CustomResourceDefinition{Status: v1.CustomResourceDefinitionStatus{Conditions: conditions}}
+	}
+
+	established, reason := CRDEstablished(crd())
+	assert.False(t, established)
+	assert.Empty(t, reason)
+
+	established, reason = CRDEstablished(crd(
+		v1.CustomResourceDefinitionCondition{Type: v1.NamesAccepted, Status: v1.ConditionFalse,
+			Reason: "MultipleNamesNotAllowed", Message: `"webapps" is already in use`},
+		v1.CustomResourceDefinitionCondition{Type: v1.Established, Status: v1.ConditionFalse,
+			Reason: "NotAccepted", Message: "not all names are accepted"},
+	))
+	assert.False(t, established)
+	assert.Equal(t, `MultipleNamesNotAllowed: "webapps" is already in use; NotAccepted: not all names are accepted`, reason)
+
+	established, reason = CRDEstablished(crd(
+		v1.CustomResourceDefinitionCondition{Type: v1.NamesAccepted, Status: v1.ConditionTrue},
+		v1.CustomResourceDefinitionCondition{Type: v1.Established, Status: v1.ConditionTrue},
+	))
+	assert.True(t, established)
+	assert.Empty(t, reason)
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// RemoteClients builds the clients of the remote clusters targeted by the
+// resource graph definitions, from the kubeconfigs stored in Secrets. The
+// clients are cached until their Secret changes. It is safe for concurrent
+// use.
+type RemoteClients struct {
+	mu      sync.Mutex
+	clients map[v1alpha1.KubeconfigSecretReference]*remoteClient
+}
+
+type remoteClient struct {
+	// resourceVersion is the version of the Secret the client was built
+	// from.
+	resourceVersion string
+	set             *Set
+}
+
+// NewRemoteClients returns an empty cache of remote clients.
+func NewRemoteClients() *RemoteClients {
+	return &RemoteClients{
+		clients: make(map[v1alpha1.KubeconfigSecretReference]*remoteClient),
+	}
+}
+
+// Get returns the client set of the given target cluster. Its Secret is read
+// with the given client, so that only the callers allowed to read the Secret
+// get the client of the cluster. It also returns the version of the Secret,
+// which changes whenever the client does.
+func (r *RemoteClients) Get(ctx context.Context, client dynamic.Interface, target *v1alpha1.Target) (*Set, string, error) {
+	ref := target.KubeconfigSecretRef
+	ref.Key = cmp.Or(ref.Key, v1alpha1.DefaultKubeconfigKey)
+
+	secret, err := client.Resource(secretsGVR).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cached, ok := r.clients[ref]; ok && cached.resourceVersion == secret.GetResourceVersion() {
+		return cached.set, cached.resourceVersion, nil
+	}
+
+	set, err := newRemoteSet(secret, ref.Key)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid kubeconfig secret %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	r.clients[ref] = &remoteClient{resourceVersion: secret.GetResourceVersion(), set: set}
+	return set, secret.GetResourceVersion(), nil
+}
+
+// ClusterName returns the name identifying the given target cluster: the
+// namespace, name and key of the Secret holding its kubeconfig, e.g
+// `clusters/spoke/kubeconfig`.
+func ClusterName(target *v1alpha1.Target) string {
+	ref := target.KubeconfigSecretRef
+	return ref.Namespace + "/" + ref.Name + "/" + cmp.Or(ref.Key, v1alpha1.DefaultKubeconfigKey)
+}
+
+// newRemoteSet returns a client set built from the kubeconfig stored in the
+// given key of the given Secret.
+func newRemoteSet(secret *unstructured.Unstructured, key string) (*Set, error) {
+	encoded, found, err := unstructured.NestedString(secret.Object, "data", key)
+	if err != nil || !found {
+		return nil, fmt.Errorf("key %q not found", key)
+	}
+	kubeconfig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key %q: %w", key, err)
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	return NewSet(Config{RestConfig: config})
+}
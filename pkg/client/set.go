@@ -19,6 +19,7 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
 	ctrlrtconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/release-utils/version"
 )
@@ -37,6 +38,9 @@ type Config struct {
 	ImpersonateUser string
 	QPS             float32
 	Burst           int
+	// UserAgent identifies the requests of kro to the API server, e.g in its
+	// audit logs. It defaults to kro/<version>.
+	UserAgent string
 }
 
 // NewSet creates a new client Set with the given config
@@ -65,7 +69,10 @@ func NewSet(cfg Config) (*Set, error) {
 	if config.Burst == 0 {
 		config.Burst = cfg.Burst
 	}
-	config.UserAgent = fmt.Sprintf("kro/%s", version.GetVersionInfo().GitVersion)
+	config.UserAgent = cfg.UserAgent
+	if config.UserAgent == "" {
+		config.UserAgent = fmt.Sprintf("kro/%s", version.GetVersionInfo().GitVersion)
+	}
 
 	c := &Set{config: config}
 	if err := c.init(); err != nil {
@@ -132,3 +139,19 @@ func (c *Set) WithImpersonation(user string) (*Set, error) {
 		ImpersonateUser: user,
 	})
 }
+
+// WithRateLimits returns a new client with its own client side rate limiter,
+// configured with the given QPS and burst. The returned client doesn't share
+// its rate limiting budget with the parent client, but the clients derived
+// from it, e.g with WithImpersonation, share its budget.
+func (c *Set) WithRateLimits(qps float32, burst int) (*Set, error) {
+	config := rest.CopyConfig(c.config)
+	config.QPS = qps
+	config.Burst = burst
+	// Replace the parent rate limiter with one shared by all the clients
+	// built from this config, rather than one per client.
+	config.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	return NewSet(Config{
+		RestConfig: config,
+	})
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestSetWithRateLimits(t *testing.T) {
+	parent, err := NewSet(Config{RestConfig: &rest.Config{Host: "https://localhost:6443"}})
+	require.NoError(t, err)
+
+	limited, err := parent.WithRateLimits(5, 10)
+	require.NoError(t, err)
+	limiter := limited.RESTConfig().RateLimiter
+	require.NotNil(t, limiter)
+	assert.Equal(t, float32(5), limiter.QPS())
+	assert.Nil(t, parent.RESTConfig().RateLimiter)
+
+	// The clients impersonating service accounts share the budget.
+	impersonated, err := limited.WithImpersonation("system:serviceaccount:default:app")
+	require.NoError(t, err)
+	assert.Same(t, limiter, impersonated.RESTConfig().RateLimiter)
+}
@@ -0,0 +1,315 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package codegen generates typed Go structs from the schema of kro instances,
+// for controllers and tests that would otherwise have to work with
+// unstructured objects.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+const (
+	metav1Import   = "k8s.io/apimachinery/pkg/apis/meta/v1"
+	resourceImport = "k8s.io/apimachinery/pkg/api/resource"
+	intstrImport   = "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// importAliases are the names used to reference the imported packages.
+var importAliases = map[string]string{
+	metav1Import:   "metav1",
+	resourceImport: "resource",
+	intstrImport:   "intstr",
+}
+
+// Options configures the generated code.
+type Options struct {
+	// PackageName is the name of the generated package. Defaults to the
+	// version of the generated kind, e.g v1alpha1.
+	PackageName string
+}
+
+// GenerateFromCRD generates the Go types of the storage version of the given
+// CustomResourceDefinition.
+func GenerateFromCRD(crd *extv1.CustomResourceDefinition, opts Options) ([]byte, error) {
+	var version *extv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Storage {
+			version = &crd.Spec.Versions[i]
+			break
+		}
+	}
+	if version == nil {
+		return nil, fmt.Errorf("CRD %s has no storage version", crd.Name)
+	}
+	if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+		return nil, fmt.Errorf("CRD %s version %s has no schema", crd.Name, version.Name)
+	}
+
+	if opts.PackageName == "" {
+		opts.PackageName = version.Name
+	}
+	schema := version.Schema.OpenAPIV3Schema
+	return generate(crd.Spec.Names.Kind, propertySchema(schema, "spec"), propertySchema(schema, "status"), opts)
+}
+
+func propertySchema(schema *extv1.JSONSchemaProps, name string) *extv1.JSONSchemaProps {
+	property, ok := schema.Properties[name]
+	if !ok {
+		return nil
+	}
+	return &property
+}
+
+// generator accumulates the generated types of a kind.
+type generator struct {
+	body    bytes.Buffer
+	imports map[string]struct{}
+	// typeNames holds the names of the generated types, to avoid duplicates.
+	typeNames map[string]struct{}
+	// pending holds the struct types that are yet to be generated.
+	pending []pendingType
+}
+
+type pendingType struct {
+	name   string
+	schema *extv1.JSONSchemaProps
+}
+
+// generate generates the root, list, spec and status types of the given kind,
+// and the types of their nested objects.
+func generate(kind string, spec, status *extv1.JSONSchemaProps, opts Options) ([]byte, error) {
+	if kind == "" {
+		return nil, fmt.Errorf("kind cannot be empty")
+	}
+	g := &generator{
+		imports:   map[string]struct{}{metav1Import: {}},
+		typeNames: map[string]struct{}{kind: {}, kind + "List": {}},
+	}
+
+	fmt.Fprintf(&g.body, "// %s is the Schema for the %s API.\n", kind, kind)
+	fmt.Fprintf(&g.body, "type %s struct {\n", kind)
+	g.body.WriteString("metav1.TypeMeta `json:\",inline\"`\n")
+	g.body.WriteString("metav1.ObjectMeta `json:\"metadata,omitempty\"`\n\n")
+	if spec != nil {
+		spec = withDefaultDescription(spec, fmt.Sprintf("%sSpec defines the desired state of %s.", kind, kind))
+		fmt.Fprintf(&g.body, "Spec %s `json:\"spec,omitempty\"`\n", g.queueType(kind+"Spec", spec))
+	}
+	if status != nil {
+		status = withDefaultDescription(status, fmt.Sprintf("%sStatus defines the observed state of %s.", kind, kind))
+		fmt.Fprintf(&g.body, "Status %s `json:\"status,omitempty\"`\n", g.queueType(kind+"Status", status))
+	}
+	g.body.WriteString("}\n\n")
+
+	fmt.Fprintf(&g.body, "// %sList contains a list of %s.\n", kind, kind)
+	fmt.Fprintf(&g.body, "type %sList struct {\n", kind)
+	g.body.WriteString("metav1.TypeMeta `json:\",inline\"`\n")
+	g.body.WriteString("metav1.ListMeta `json:\"metadata,omitempty\"`\n")
+	fmt.Fprintf(&g.body, "Items []%s `json:\"items\"`\n", kind)
+	g.body.WriteString("}\n")
+
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		g.writeStruct(next.name, next.schema)
+	}
+
+	return g.source(opts.PackageName)
+}
+
+// source returns the formatted source of the generated file.
+func (g *generator) source(packageName string) ([]byte, error) {
+	var src bytes.Buffer
+	src.WriteString("// Code generated by kro codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", packageName)
+
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	src.WriteString("import (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&src, "%s %q\n", importAliases[path], path)
+	}
+	src.WriteString(")\n\n")
+	src.Write(g.body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// queueType reserves a unique type name for the given object schema, and queues
+// the generation of its struct.
+func (g *generator) queueType(name string, schema *extv1.JSONSchemaProps) string {
+	unique := name
+	for i := 2; ; i++ {
+		if _, ok := g.typeNames[unique]; !ok {
+			break
+		}
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.typeNames[unique] = struct{}{}
+	g.pending = append(g.pending, pendingType{name: unique, schema: schema})
+	return unique
+}
+
+// writeStruct writes the struct of the given object schema.
+func (g *generator) writeStruct(name string, schema *extv1.JSONSchemaProps) {
+	g.body.WriteString("\n")
+	writeComment(&g.body, schema.Description)
+	fmt.Fprintf(&g.body, "type %s struct {\n", name)
+
+	properties := make([]string, 0, len(schema.Properties))
+	for property := range schema.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	for _, property := range properties {
+		propertySchema := schema.Properties[property]
+		required := false
+		for _, r := range schema.Required {
+			if r == property {
+				required = true
+				break
+			}
+		}
+
+		fieldName := goFieldName(property)
+		fieldType := g.goType(name+fieldName, &propertySchema)
+		if propertySchema.Nullable || (!required && isStructType(&propertySchema)) {
+			fieldType = "*" + fieldType
+		}
+		tag := property
+		if !required {
+			tag += ",omitempty"
+		}
+
+		writeComment(&g.body, propertySchema.Description)
+		fmt.Fprintf(&g.body, "%s %s `json:\"%s\"`\n", fieldName, fieldType, tag)
+	}
+	g.body.WriteString("}\n")
+}
+
+// goType returns the Go type of the given schema. Nested objects are generated
+// as structs named after the given name.
+func (g *generator) goType(name string, schema *extv1.JSONSchemaProps) string {
+	switch {
+	case schema.XIntOrString:
+		// Quantities are the only int-or-string values validated by a pattern.
+		if schema.Pattern != "" {
+			g.imports[resourceImport] = struct{}{}
+			return "resource.Quantity"
+		}
+		g.imports[intstrImport] = struct{}{}
+		return "intstr.IntOrString"
+	case isStructType(schema):
+		return g.queueType(name, schema)
+	}
+
+	switch schema.Type {
+	case "string":
+		switch strings.ReplaceAll(schema.Format, "-", "") {
+		case "datetime":
+			return "metav1.Time"
+		case "duration":
+			return "metav1.Duration"
+		}
+		return "string"
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number", "float":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(name+"Item", schema.Items.Schema)
+	case "object":
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			return "map[string]" + g.goType(name+"Value", schema.AdditionalProperties.Schema)
+		}
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+// withDefaultDescription returns a copy of the given schema, described by the
+// given description if it has none.
+func withDefaultDescription(schema *extv1.JSONSchemaProps, description string) *extv1.JSONSchemaProps {
+	if schema.Description != "" {
+		return schema
+	}
+	schema = schema.DeepCopy()
+	schema.Description = description
+	return schema
+}
+
+// isStructType returns true if the given schema is generated as a struct.
+func isStructType(schema *extv1.JSONSchemaProps) bool {
+	return schema.Type == "object" && len(schema.Properties) > 0
+}
+
+// goFieldName returns the exported Go name of the given JSON field name, e.g
+// `min-replicas` becomes `MinReplicas`.
+func goFieldName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteRune('X')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
+
+// writeComment writes the given description as a Go comment.
+func writeComment(b *bytes.Buffer, description string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(b, "// %s\n", strings.TrimRightFunc(line, unicode.IsSpace))
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/crd"
+)
+
+func TestGenerateFromCRD(t *testing.T) {
+	spec := extv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: map[string]extv1.JSONSchemaProps{
+			"name":     {Type: "string", Description: "Name of the application."},
+			"replicas": {Type: "integer"},
+			"ratio":    {Type: "number"},
+			"enabled":  {Type: "boolean"},
+			"timeout":  {Type: "string", Format: "duration"},
+			"memory": {
+				AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+				Pattern:      "^[0-9]+$",
+				XIntOrString: true,
+			},
+			"port":   {XIntOrString: true},
+			"labels": {Type: "object", AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Schema: &extv1.JSONSchemaProps{Type: "string"}}},
+			"ingress": {
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"host": {Type: "string", Nullable: true},
+				},
+			},
+			"containers": {
+				Type: "array",
+				Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"image":    {Type: "string"},
+						"max-size": {Type: "integer"},
+					},
+				}},
+			},
+		},
+	}
+	status := extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"endpoint": {Type: "string"},
+		},
+	}
+	instanceCRD := crd.SynthesizeCRD("kro.run", "v1alpha1", "WebApp", spec, status, true)
+
+	src, err := GenerateFromCRD(instanceCRD, Options{})
+	require.NoError(t, err)
+
+	code := normalizeSpaces(string(src))
+	for _, expected := range []string{
+		"// Code generated by kro codegen. DO NOT EDIT.",
+		"package v1alpha1",
+		`resource "k8s.io/apimachinery/pkg/api/resource"`,
+		`intstr "k8s.io/apimachinery/pkg/util/intstr"`,
+		"type WebApp struct {",
+		"type WebAppList struct {",
+		"Spec   WebAppSpec   `json:\"spec,omitempty\"`",
+		"// WebAppSpec defines the desired state of WebApp.",
+		"// Name of the application. Name string `json:\"name\"`",
+		"Replicas int64 `json:\"replicas,omitempty\"`",
+		"Ratio float64 `json:\"ratio,omitempty\"`",
+		"Enabled bool `json:\"enabled,omitempty\"`",
+		"Timeout metav1.Duration `json:\"timeout,omitempty\"`",
+		"Memory resource.Quantity `json:\"memory,omitempty\"`",
+		"Port intstr.IntOrString `json:\"port,omitempty\"`",
+		"Labels map[string]string `json:\"labels,omitempty\"`",
+		"Ingress *WebAppSpecIngress `json:\"ingress,omitempty\"`",
+		"Host *string `json:\"host,omitempty\"`",
+		"Containers []WebAppSpecContainersItem `json:\"containers,omitempty\"`",
+		"MaxSize int64 `json:\"max-size,omitempty\"`",
+		"Endpoint string `json:\"endpoint,omitempty\"`",
+		"Conditions []WebAppStatusConditionsItem `json:\"conditions,omitempty\"`",
+	} {
+		assert.Contains(t, code, normalizeSpaces(expected))
+	}
+}
+
+func TestGenerateFromCRDPackageName(t *testing.T) {
+	instanceCRD := crd.SynthesizeCRD("kro.run", "v1alpha1", "WebApp", extv1.JSONSchemaProps{Type: "object"}, extv1.JSONSchemaProps{}, false)
+
+	src, err := GenerateFromCRD(instanceCRD, Options{PackageName: "webapp"})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "package webapp")
+
+	instanceCRD.Spec.Versions[0].Storage = false
+	_, err = GenerateFromCRD(instanceCRD, Options{})
+	assert.Error(t, err)
+}
+
+func TestGenerateFromResourceGraphDefinition(t *testing.T) {
+	rgd := &v1alpha1.ResourceGraphDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "webapp"},
+		Spec: v1alpha1.ResourceGraphDefinitionSpec{
+			Schema: &v1alpha1.Schema{
+				APIVersion: "v1alpha1",
+				Kind:       "WebApp",
+				Spec: runtime.RawExtension{Raw: []byte(`{
+					"name": "string | required=true",
+					"replicas": "integer | default=1",
+					"ports": "[]Port"
+				}`)},
+				Types: runtime.RawExtension{Raw: []byte(`{"Port": {"port": "integer"}}`)},
+				Status: runtime.RawExtension{Raw: []byte(`{
+					"replicas": "${deployment.status.availableReplicas}",
+					"url": "https://${service.spec.clusterIP}",
+					"network": {"ip": "${service.spec.clusterIP}"}
+				}`)},
+			},
+		},
+	}
+
+	src, err := GenerateFromResourceGraphDefinition(rgd, Options{})
+	require.NoError(t, err)
+
+	code := normalizeSpaces(string(src))
+	for _, expected := range []string{
+		"Name string `json:\"name\"`",
+		"Replicas int64 `json:\"replicas,omitempty\"`",
+		"Ports []WebAppSpecPortsItem `json:\"ports,omitempty\"`",
+		"Port int64 `json:\"port,omitempty\"`",
+		"Replicas interface{} `json:\"replicas,omitempty\"`",
+		"Url string `json:\"url,omitempty\"`",
+		"Network *WebAppStatusNetwork `json:\"network,omitempty\"`",
+		"Ip interface{} `json:\"ip,omitempty\"`",
+		"State string `json:\"state,omitempty\"`",
+	} {
+		assert.Contains(t, code, normalizeSpaces(expected))
+	}
+}
+
+func TestGoFieldName(t *testing.T) {
+	tests := map[string]string{
+		"name":         "Name",
+		"minReplicas":  "MinReplicas",
+		"min-replicas": "MinReplicas",
+		"min_replicas": "MinReplicas",
+		"8080":         "X8080",
+		"-":            "X",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, goFieldName(input), input)
+	}
+}
+
+// normalizeSpaces replaces the whitespace sequences of the given code, such as
+// the alignment of struct fields, by a single space.
+func normalizeSpaces(code string) string {
+	return strings.Join(strings.Fields(code), " ")
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/graph/crd"
+)
+
+// GenerateFromResourceGraphDefinition generates the Go types of the instances
+// of the given ResourceGraphDefinition.
+//
+// The types of status fields are inferred from the resources of the graph,
+// which requires access to a cluster. Status fields set by a standalone
+// expression, e.g `${deployment.status.availableReplicas}`, are generated as
+// interface{}. To get fully typed status fields, use GenerateFromCRD with the
+// CRD generated by kro.
+func GenerateFromResourceGraphDefinition(rgd *v1alpha1.ResourceGraphDefinition, opts Options) ([]byte, error) {
+	instanceCRD, err := InstanceCRD(rgd)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateFromCRD(instanceCRD, opts)
+}
+
+// InstanceCRD returns the CRD of the instances of the given
+// ResourceGraphDefinition, as kro would generate it, without access to a
+// cluster. Status fields set by a standalone expression have no type.
+func InstanceCRD(rgd *v1alpha1.ResourceGraphDefinition) (*extv1.CustomResourceDefinition, error) {
+	rgSchema := rgd.Spec.Schema
+	if rgSchema == nil {
+		return nil, fmt.Errorf("resource graph definition %s has no schema", rgd.Name)
+	}
+
+	spec, err := graph.BuildInstanceSpecSchema(rgSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &extv1.JSONSchemaProps{Type: "object"}
+	if len(rgSchema.Status.Raw) > 0 {
+		statusFields := map[string]interface{}{}
+		if err := yaml.UnmarshalStrict(rgSchema.Status.Raw, &statusFields); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal status schema: %w", err)
+		}
+		status = statusFieldsSchema(statusFields)
+	}
+
+	return crd.SynthesizeCRD(rgSchema.Group, rgSchema.APIVersion, rgSchema.Kind, *spec, *status, true), nil
+}
+
+// statusFieldsSchema returns a schema of the given status fields. Standalone
+// expressions can evaluate to any type, while string templates always evaluate
+// to strings.
+func statusFieldsSchema(value interface{}) *extv1.JSONSchemaProps {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		schema := &extv1.JSONSchemaProps{
+			Type:       "object",
+			Properties: make(map[string]extv1.JSONSchemaProps, len(v)),
+		}
+		for key, field := range v {
+			schema.Properties[key] = *statusFieldsSchema(field)
+		}
+		return schema
+	case string:
+		if isStandaloneExpression(v) {
+			return &extv1.JSONSchemaProps{}
+		}
+		return &extv1.JSONSchemaProps{Type: "string"}
+	default:
+		return &extv1.JSONSchemaProps{}
+	}
+}
+
+// isStandaloneExpression returns true if the given string is made of a single
+// expression, e.g `${foo}` but not `hello-${foo}` or `${foo}${bar}`.
+func isStandaloneExpression(s string) bool {
+	return strings.HasPrefix(s, "${") && strings.HasSuffix(s, "}") && strings.Count(s, "${") == 1
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v4/typed"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/tracing"
+)
+
+// applyResource applies the desired state of a resource with server-side
+// apply. Depending on the conflict policy of the resource, the fields managed
+// by other field managers are either taken over, or fail the apply.
+func (igr *instanceGraphReconciler) applyResource(
+	ctx context.Context,
+	rc dynamic.ResourceInterface,
+	resourceID string,
+	desired *unstructured.Unstructured,
+) error {
+	ctx, span := tracing.Start(ctx, "resource.apply",
+		tracing.String("kro.resource.kind", desired.GetKind()),
+		tracing.String("kro.resource.namespace", desired.GetNamespace()),
+		tracing.String("kro.resource.name", desired.GetName()),
+	)
+	defer span.End()
+
+	policy := igr.runtime.ResourceDescriptor(resourceID).GetConflictPolicy()
+	_, err := rc.Apply(ctx, desired.GetName(), desired, metav1.ApplyOptions{
+		FieldManager: igr.reconcileConfig.FieldManager,
+		Force:        policy != v1alpha1.ConflictPolicyFail && policy != v1alpha1.ConflictPolicyIgnoreFields,
+	})
+	if err != nil {
+		resourceApplyErrors.WithLabelValues(igr.rgdName, resourceID).Inc()
+		span.RecordError(igr.redactor.Error(err))
+	}
+	if apierrors.IsConflict(err) {
+		return fmt.Errorf("fields are managed by other field managers: %w", err)
+	}
+	return err
+}
+
+// withoutForeignFields returns a copy of the desired state of a resource
+// without the fields the observed resource has managed by other field
+// managers than the given one.
+func withoutForeignFields(desired, observed *unstructured.Unstructured, fieldManager string) (*unstructured.Unstructured, error) {
+	foreign, err := foreignFields(observed, fieldManager)
+	if err != nil {
+		return nil, err
+	}
+	if foreign.Empty() {
+		return desired, nil
+	}
+
+	value, err := typed.DeducedParseableType.FromUnstructured(desired.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse desired state: %w", err)
+	}
+	// Only the leaves are removed: other field managers owning a map, e.g
+	// the annotations, only own its entries they set.
+	stripped, ok := value.RemoveItems(foreign.Leaves()).AsValue().Unstructured().(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected desired state without fields managed by other field managers")
+	}
+	return &unstructured.Unstructured{Object: stripped}, nil
+}
+
+// foreignFields returns the fields of the observed resource managed by other
+// field managers than the given one.
+func foreignFields(observed *unstructured.Unstructured, fieldManager string) (*fieldpath.Set, error) {
+	foreign := &fieldpath.Set{}
+	for _, entry := range observed.GetManagedFields() {
+		if entry.Manager == fieldManager || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := &fieldpath.Set{}
+		if err := fields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return nil, fmt.Errorf("failed to parse the fields managed by %s: %w", entry.Manager, err)
+		}
+		foreign = foreign.Union(fields)
+	}
+	return foreign, nil
+}
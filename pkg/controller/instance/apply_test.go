@@ -0,0 +1,70 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestWithoutForeignFields(t *testing.T) {
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":        "app",
+			"annotations": map[string]interface{}{"team": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"paused":   false,
+		},
+	}}
+	observed := &unstructured.Unstructured{}
+	observed.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:   "kro",
+			Operation: metav1.ManagedFieldsOperationApply,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:paused":{},"f:replicas":{}}}`)},
+		},
+		{
+			Manager:   "hpa",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		},
+		{
+			Manager:   "deployment-controller",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(
+				`{"f:metadata":{"f:annotations":{".":{},"f:revision":{}}}}`,
+			)},
+		},
+	})
+
+	stripped, err := withoutForeignFields(desired, observed, "kro")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"paused": false}, stripped.Object["spec"])
+	// Owning the annotations map doesn't own the annotations set by others.
+	assert.Equal(t, map[string]string{"team": "web"}, stripped.GetAnnotations())
+	// The desired state is left untouched.
+	assert.Equal(t, int64(2), desired.Object["spec"].(map[string]interface{})["replicas"])
+
+	unmanaged, err := withoutForeignFields(desired, &unstructured.Unstructured{}, "kro")
+	require.NoError(t, err)
+	assert.Equal(t, desired, unmanaged)
+}
@@ -15,22 +15,32 @@ package instance
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/kro-run/kro/api/v1alpha1"
 	kroclient "github.com/kro-run/kro/pkg/client"
+	"github.com/kro-run/kro/pkg/dynamiccontroller"
 	"github.com/kro-run/kro/pkg/graph"
 	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/redact"
+	"github.com/kro-run/kro/pkg/requeue"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/tracing"
 )
 
 // ReconcileConfig holds configuration parameters for the reconciliation process.
@@ -39,6 +49,17 @@ type ReconcileConfig struct {
 	// DefaultRequeueDuration is the default duration to wait before requeueing a
 	// a reconciliation if no specific requeue time is set.
 	DefaultRequeueDuration time.Duration
+	// MaxConcurrentResourceReconciles is the maximum number of resources of an
+	// instance reconciled concurrently. Resources are reconciled concurrently
+	// once all of their dependencies are reconciled.
+	MaxConcurrentResourceReconciles int
+	// FieldManager is the field manager the resources of the instances are
+	// applied with.
+	FieldManager string
+	// ResyncPeriod is the interval at which the instances are reconciled,
+	// even without changes, to correct the drift of their resources. 0
+	// disables it.
+	ResyncPeriod time.Duration
 	// DeletionGraceTimeDuration is the duration to wait after initializing a resource
 	// deletion before considering it failed
 	// Not implemented.
@@ -49,6 +70,36 @@ type ReconcileConfig struct {
 	DeletionPolicy string
 }
 
+const (
+	// defaultRetryInitialDelay is the delay of the first retry of a retry
+	// policy without one.
+	defaultRetryInitialDelay = 5 * time.Second
+	// defaultRetryMaxDelay is the maximum delay of the retries of a retry
+	// policy without one.
+	defaultRetryMaxDelay = 5 * time.Minute
+)
+
+// RetryBackoff returns the backoff policy of the given retry policy, or nil if
+// there's none.
+func RetryBackoff(policy *v1alpha1.RetryPolicy) *requeue.Backoff {
+	if policy == nil {
+		return nil
+	}
+	backoff := &requeue.Backoff{
+		InitialDelay: defaultRetryInitialDelay,
+		MaxDelay:     defaultRetryMaxDelay,
+		MaxAttempts:  int(policy.MaxAttempts),
+	}
+	if policy.InitialDelay != nil {
+		backoff.InitialDelay = policy.InitialDelay.Duration
+	}
+	if policy.MaxDelay != nil {
+		backoff.MaxDelay = policy.MaxDelay.Duration
+	}
+	backoff.MaxDelay = max(backoff.MaxDelay, backoff.InitialDelay)
+	return backoff
+}
+
 // Controller manages the reconciliation of a single instance of a ResourceGraphDefinition,
 // / it is responsible for reconciling the instance and its sub-resources.
 //
@@ -71,11 +122,17 @@ type ReconcileConfig struct {
 // and its sub-resources.
 type Controller struct {
 	log logr.Logger
+	// rgdName is the name of the resource graph definition, which labels the
+	// metrics of its instances.
+	rgdName string
 	// gvr represents the Group, Version, and Resource of the custom resource
 	// this controller is responsible for.
 	gvr schema.GroupVersionResource
 	// client holds the dynamic client to use for interacting with the Kubernetes API.
 	clientSet *kroclient.Set
+	// remoteClients builds the clients of the remote clusters the resources
+	// are applied to.
+	remoteClients *kroclient.RemoteClients
 	// rgd is a read-only reference to the Graph that the controller is
 	// managing instances for.
 	// TODO: use a read-only interface for the ResourceGraphDefinition
@@ -88,85 +145,263 @@ type Controller struct {
 	reconcileConfig ReconcileConfig
 	// defaultServiceAccounts is a map of service accounts to use for controller impersonation.
 	defaultServiceAccounts map[string]string
+	// referenceTracker records the objects referenced by the instances.
+	referenceTracker ReferenceTracker
+	// instanceLister lists the instances, to enforce the quota of the
+	// resource graph definition.
+	instanceLister InstanceLister
+	// recorder records the events of the instances.
+	recorder record.EventRecorder
+}
+
+// ReferenceTracker records the objects each instance refers to, through
+// external references or as the resources it manages, so that the instance
+// is reconciled whenever one of them changes.
+type ReferenceTracker interface {
+	SetReferences(instance dynamiccontroller.ObjectIdentifiers, references []dynamiccontroller.ObjectIdentifiers)
 }
 
 // NewController creates a new Controller instance.
 func NewController(
 	log logr.Logger,
 	reconcileConfig ReconcileConfig,
+	rgdName string,
 	gvr schema.GroupVersionResource,
 	rgd *graph.Graph,
 	clientSet *kroclient.Set,
+	remoteClients *kroclient.RemoteClients,
 	defaultServiceAccounts map[string]string,
 	instanceLabeler metadata.Labeler,
+	referenceTracker ReferenceTracker,
+	instanceLister InstanceLister,
+	recorder record.EventRecorder,
 ) *Controller {
 	return &Controller{
 		log:                    log,
+		rgdName:                rgdName,
 		gvr:                    gvr,
 		clientSet:              clientSet,
+		remoteClients:          remoteClients,
 		rgd:                    rgd,
 		instanceLabeler:        instanceLabeler,
 		reconcileConfig:        reconcileConfig,
 		defaultServiceAccounts: defaultServiceAccounts,
+		referenceTracker:       referenceTracker,
+		instanceLister:         instanceLister,
+		recorder:               recorder,
 	}
 }
 
 // Reconcile is a handler function that reconciles the instance and its sub-resources.
-func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) error {
+func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (err error) {
+	start := time.Now()
+	defer func() { recordReconcile(c.rgdName, start, err) }()
 	namespace, name := getNamespaceName(req)
 
+	ctx, span := tracing.Start(ctx, "instance.reconcile",
+		tracing.String("kro.rgd", c.rgdName),
+		tracing.String("kro.gvr", c.gvr.String()),
+		tracing.String("kro.instance.namespace", namespace),
+		tracing.String("kro.instance.name", name),
+	)
+	// The redactor is set once the instance is read.
+	var redactor *redact.Redactor
+	defer func() {
+		// Requeues are part of the reconciliation of the instances.
+		if reconcileOutcome(err) == outcomeError {
+			span.RecordError(redactor.Error(err))
+		}
+		span.End()
+	}()
+
 	log := c.log.WithValues("namespace", namespace, "name", name)
 
 	instance, err := c.clientSet.Dynamic().Resource(c.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("Instance not found, it may have been deleted")
+			c.trackReferences(req, nil)
 			return nil
 		}
 		log.Error(err, "Failed to get instance")
 		return nil
 	}
 
+	span.SetAttributes(tracing.String("kro.instance.uid", string(instance.GetUID())))
+
+	// If possible, use a service account to create the execution client
+	// TODO(a-hilaly): client caching
+	executionClient, err := c.getExecutionClient(namespace)
+	if err != nil {
+		return fmt.Errorf("failed to create execution client: %w", err)
+	}
+
+	values, secretValues, valuesReferences, err := c.loadValues(ctx, executionClient, instance)
+	if err != nil {
+		c.trackReferences(req, valuesReferences)
+		return err
+	}
+
+	// Values of the sensitive fields of the instance, and the ones loaded from
+	// Secrets, must not leak into its status, the logs or the errors returned
+	// to the dynamic controller.
+	redactor = redact.New(append(redact.ValuesAt(instance.Object, c.rgd.SensitiveFields), secretValues...))
+	log = redactor.Logger(log)
+
+	namespaceSelected, err := c.namespaceSelected(ctx, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to check the namespace of the instance: %w", err)
+	}
+
+	// Defaults computed by expressions are set at admission by the defaulting
+	// webhook. They're patched here for the instances it didn't default, e.g
+	// when it's disabled, or the defaults depend on values.
+	if namespaceSelected && instance.GetDeletionTimestamp().IsZero() {
+		defaults, err := c.rgd.Defaults(instance, graph.WithValues(values))
+		if err != nil {
+			recordEvent(c.recorder, redactor, instance, corev1.EventTypeWarning, eventReasonEvaluationFailed,
+				"Failed to evaluate the defaults of the instance: %v", err)
+			return fmt.Errorf("failed to apply instance defaults: %w", err)
+		}
+		if len(defaults) > 0 {
+			patch, err := json.Marshal(defaults)
+			if err != nil {
+				return fmt.Errorf("failed to encode instance defaults: %w", err)
+			}
+			instance, err = c.clientSet.Dynamic().Resource(c.gvr).Namespace(namespace).
+				Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to apply instance defaults: %w", err)
+			}
+			log.V(1).Info("Applied instance defaults")
+			redactor = redact.New(append(redact.ValuesAt(instance.Object, c.rgd.SensitiveFields), secretValues...))
+			log = redactor.Logger(c.log.WithValues("namespace", namespace, "name", name))
+		}
+	}
+
 	// This is one of the main reasons why we're splitting the controller into
 	// two parts. The instantiator is responsible for creating a new runtime
 	// instance of the resource graph definition. The instance graph reconciler is responsible
 	// for reconciling the instance and its sub-resources, while keeping the same
 	// runtime object in it's fields.
-	rgRuntime, err := c.rgd.NewGraphRuntime(instance)
+	_, resolveSpan := tracing.Start(ctx, "instance.resolve_graph")
+	rgRuntime, err := c.rgd.NewGraphRuntime(instance, graph.WithValues(values))
 	if err != nil {
+		resolveSpan.RecordError(redactor.Error(err))
+		resolveSpan.End()
+		recordEvent(c.recorder, redactor, instance, corev1.EventTypeWarning, eventReasonEvaluationFailed,
+			"Failed to evaluate the expressions of the instance: %v", err)
 		return fmt.Errorf("failed to create runtime resource graph definition: %w", err)
 	}
+	resolveSpan.SetAttributes(tracing.Int("kro.resources", len(rgRuntime.TopologicalOrder())))
+	resolveSpan.End()
 
 	instanceSubResourcesLabeler, err := metadata.NewInstanceLabeler(instance).Merge(c.instanceLabeler)
 	if err != nil {
 		return fmt.Errorf("failed to create instance sub-resources labeler: %w", err)
 	}
 
-	// If possible, use a service account to create the execution client
-	// TODO(a-hilaly): client caching
-	executionClient, err := c.getExecutionClient(namespace)
+	targetClients, err := c.getTargetClients(ctx, executionClient)
 	if err != nil {
-		return fmt.Errorf("failed to create execution client: %w", err)
+		return fmt.Errorf("failed to create target cluster clients: %w", err)
+	}
+
+	forEachResources := make(map[string]runtime.ResourceDescriptor)
+	for id, resource := range c.rgd.Resources {
+		if resource.IsForEach() {
+			forEachResources[id] = resource
+		}
 	}
 
 	instanceGraphReconciler := &instanceGraphReconciler{
 		log:                         log,
+		rgdName:                     c.rgdName,
 		gvr:                         c.gvr,
 		client:                      executionClient,
+		targetClients:               targetClients,
 		runtime:                     rgRuntime,
 		instanceLabeler:             c.instanceLabeler,
 		instanceSubResourcesLabeler: instanceSubResourcesLabeler,
 		reconcileConfig:             c.reconcileConfig,
+		redactor:                    redactor,
+		recorder:                    c.recorder,
+		forEachResources:            forEachResources,
+		rollbackOnFailure:           c.rgd.RollbackOnFailure,
+		namespaceRejected:           !namespaceSelected,
+		quotaExceeded:               c.quotaExceeded(instance, rgRuntime),
+		lastAttempt:                 dynamiccontroller.IsLastAttempt(ctx),
 		// Fresh instance state at each reconciliation loop.
 		state: newInstanceState(),
 	}
-	return instanceGraphReconciler.reconcile(ctx)
+	err = instanceGraphReconciler.reconcile(ctx)
+	celEvaluationDuration.WithLabelValues(c.rgdName).Observe(rgRuntime.EvaluationDuration().Seconds())
+	c.trackReferences(req, append(instanceGraphReconciler.references(), valuesReferences...))
+	expiresIn, ttlErr := c.reconcileTTL(ctx, redactor, instance, instanceGraphReconciler.state.Status)
+	if err == nil {
+		err = ttlErr
+	}
+	if err == nil {
+		// Once deleted, the instance isn't found and stops being resynced.
+		requeueAfter := c.reconcileConfig.ResyncPeriod
+		if expiresIn > 0 && (requeueAfter == 0 || expiresIn < requeueAfter) {
+			requeueAfter = expiresIn
+		}
+		if requeueAfter > 0 {
+			return requeue.NeededAfter(nil, requeueAfter)
+		}
+	}
+	return redactError(redactor, err)
+}
+
+// namespaceSelected reports whether the instances of the given namespace are
+// reconciled, i.e the namespace is selected by the namespace selector of the
+// resource graph definition, if any.
+func (c *Controller) namespaceSelected(ctx context.Context, namespace string) (bool, error) {
+	if c.rgd.NamespaceSelector == nil {
+		return true, nil
+	}
+	ns, err := c.clientSet.Kubernetes().CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return c.rgd.NamespaceSelector.Matches(labels.Set(ns.GetLabels())), nil
+}
+
+// trackReferences records the objects the instance of the given request
+// refers to.
+func (c *Controller) trackReferences(req ctrl.Request, references []dynamiccontroller.ObjectIdentifiers) {
+	if c.referenceTracker == nil {
+		return
+	}
+	c.referenceTracker.SetReferences(dynamiccontroller.ObjectIdentifiers{
+		NamespacedKey: req.Name,
+		GVR:           c.gvr,
+	}, references)
 }
 
-// getNamespaceName extracts the namespace and name from the request.
+// redactError redacts the sensitive values from the given error, preserving
+// the requeue errors the dynamic controller relies on.
+func redactError(redactor *redact.Redactor, err error) error {
+	switch typedErr := err.(type) {
+	case *requeue.NoRequeue:
+		return requeue.None(redactor.Error(typedErr.Unwrap()))
+	case *requeue.RequeueNeeded:
+		return requeue.Needed(redactor.Error(typedErr.Unwrap()))
+	case *requeue.RequeueNeededAfter:
+		return requeue.NeededAfter(redactor.Error(typedErr.Unwrap()), typedErr.Duration()).WithBackoff(typedErr.Backoff())
+	default:
+		return redactor.Error(err)
+	}
+}
+
+// getNamespaceName extracts the namespace and name from the request. The
+// keys of cluster-scoped instances have no namespace.
 func getNamespaceName(req ctrl.Request) (string, string) {
 	parts := strings.Split(req.Name, "/")
 	name := parts[len(parts)-1]
+	if len(parts) == 1 {
+		return "", name
+	}
 	namespace := parts[0]
 	if namespace == "" {
 		namespace = metav1.NamespaceDefault
@@ -185,10 +420,18 @@ const (
 )
 
 // getExecutionClient determines the execution client to use for the instance.
-// If the instance is created in a namespace of which a service account is specified,
+// If the resource graph definition specifies a service account, the execution
+// client impersonates it whatever the namespace of the instance. Otherwise, if
+// the instance is created in a namespace of which a service account is specified,
 // the execution client will be created using the service account. If no service account
 // is specified for the namespace, the default client will be used.
 func (c *Controller) getExecutionClient(namespace string) (dynamic.Interface, error) {
+	if sa := c.rgd.ServiceAccount; sa != nil {
+		timer := prometheus.NewTimer(impersonationDuration.WithLabelValues(namespace, sa.Name))
+		defer timer.ObserveDuration()
+		return c.impersonatedClient(namespace, sa.Namespace, sa.Name)
+	}
+
 	// if no service accounts are specified, use the default client
 	if len(c.defaultServiceAccounts) == 0 {
 		c.log.V(1).Info("no service accounts configured, using default client")
@@ -200,43 +443,62 @@ func (c *Controller) getExecutionClient(namespace string) (dynamic.Interface, er
 
 	// Check for namespace specific service account
 	if sa, ok := c.defaultServiceAccounts[namespace]; ok {
-		userName, err := getServiceAccountUserName(namespace, sa)
-		if err != nil {
-			c.handleImpersonateError(namespace, sa, err)
-			return nil, fmt.Errorf("invalid service account configuration: %w", err)
-		}
-
-		pivotedClient, err := c.clientSet.WithImpersonation(userName)
-		if err != nil {
-			c.handleImpersonateError(namespace, sa, err)
-			return nil, fmt.Errorf("failed to create impersonated client: %w", err)
-		}
-
-		impersonationTotal.WithLabelValues(namespace, sa, "success").Inc()
-		return pivotedClient.Dynamic(), nil
+		return c.impersonatedClient(namespace, namespace, sa)
 	}
 
 	// Check for default service account (marked by "*")
 	if defaultSA, ok := c.defaultServiceAccounts[v1alpha1.DefaultServiceAccountKey]; ok {
-		userName, err := getServiceAccountUserName(namespace, defaultSA)
-		if err != nil {
-			c.handleImpersonateError(namespace, defaultSA, err)
-			return nil, fmt.Errorf("invalid default service account configuration: %w", err)
-		}
+		return c.impersonatedClient(namespace, namespace, defaultSA)
+	}
 
-		pivotedClient, err := c.clientSet.WithImpersonation(userName)
+	impersonationTotal.WithLabelValues(namespace, "", "default").Inc()
+	// Fallback to the default client
+	return c.clientSet.Dynamic(), nil
+}
+
+// getTargetClients returns the clients of the remote clusters the resources
+// are applied to, keyed by the Secret holding their kubeconfig. The Secrets
+// are read with the execution client, so that the service account
+// impersonated for the instance, if any, must be allowed to read them.
+func (c *Controller) getTargetClients(
+	ctx context.Context,
+	executionClient dynamic.Interface,
+) (map[v1alpha1.KubeconfigSecretReference]dynamic.Interface, error) {
+	clients := make(map[v1alpha1.KubeconfigSecretReference]dynamic.Interface)
+	for _, resource := range c.rgd.Resources {
+		target := resource.GetTarget()
+		if target == nil {
+			continue
+		}
+		if _, ok := clients[target.KubeconfigSecretRef]; ok {
+			continue
+		}
+		set, _, err := c.remoteClients.Get(ctx, executionClient, target)
 		if err != nil {
-			c.handleImpersonateError(namespace, defaultSA, err)
-			return nil, fmt.Errorf("failed to create impersonated client with default SA: %w", err)
+			return nil, err
 		}
+		clients[target.KubeconfigSecretRef] = set.Dynamic()
+	}
+	return clients, nil
+}
 
-		impersonationTotal.WithLabelValues(namespace, defaultSA, "success").Inc()
-		return pivotedClient.Dynamic(), nil
+// impersonatedClient returns a client impersonating the given service account,
+// for an instance in the given namespace.
+func (c *Controller) impersonatedClient(namespace, saNamespace, sa string) (dynamic.Interface, error) {
+	userName, err := getServiceAccountUserName(saNamespace, sa)
+	if err != nil {
+		c.handleImpersonateError(namespace, sa, err)
+		return nil, fmt.Errorf("invalid service account configuration: %w", err)
 	}
 
-	impersonationTotal.WithLabelValues(namespace, "", "default").Inc()
-	// Fallback to the default client
-	return c.clientSet.Dynamic(), nil
+	pivotedClient, err := c.clientSet.WithImpersonation(userName)
+	if err != nil {
+		c.handleImpersonateError(namespace, sa, err)
+		return nil, fmt.Errorf("failed to create impersonated client: %w", err)
+	}
+
+	impersonationTotal.WithLabelValues(namespace, sa, "success").Inc()
+	return pivotedClient.Dynamic(), nil
 }
 
 // handleImpersonateError logs the error and records the error in the metrics
@@ -15,20 +15,36 @@ package instance
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
 
+	"github.com/kro-run/kro/api/v1alpha1"
+	krocel "github.com/kro-run/kro/pkg/cel"
+	kroclient "github.com/kro-run/kro/pkg/client"
 	"github.com/kro-run/kro/pkg/controller/instance/delta"
+	"github.com/kro-run/kro/pkg/dynamiccontroller"
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
 	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/redact"
 	"github.com/kro-run/kro/pkg/requeue"
 	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/tracing"
 )
 
 // instanceGraphReconciler is responsible for reconciling a single instance and
@@ -36,11 +52,17 @@ import (
 // on the graph inferred from the ResourceGraphDefinition analysis.
 type instanceGraphReconciler struct {
 	log logr.Logger
+	// rgdName is the name of the resource graph definition, which labels the
+	// metrics of the instance.
+	rgdName string
 	// gvr represents the Group, Version, and Resource of the custom resource
 	// this controller is responsible for.
 	gvr schema.GroupVersionResource
 	// client is a dynamic client for interacting with the Kubernetes API server
 	client dynamic.Interface
+	// targetClients are the clients of the remote clusters the resources are
+	// applied to, keyed by the Secret holding their kubeconfig.
+	targetClients map[v1alpha1.KubeconfigSecretReference]dynamic.Interface
 	// runtime is the runtime representation of the ResourceGraphDefinition. It holds the
 	// information about the instance and its sub-resources, the CEL expressions
 	// their dependencies, and the resolved values... etc
@@ -53,6 +75,30 @@ type instanceGraphReconciler struct {
 	// reconcileConfig holds the configuration parameters for the reconciliation
 	// process.
 	reconcileConfig ReconcileConfig
+	// redactor redacts the values of the sensitive fields of the instance.
+	redactor *redact.Redactor
+	// recorder records the events of the instance, telling what kro did to
+	// its resources. Events aren't recorded if it's nil.
+	recorder record.EventRecorder
+	// forEachResources are the resource templates iterating over a collection
+	// of the instance, keyed by id.
+	forEachResources map[string]runtime.ResourceDescriptor
+	// rollbackOnFailure rolls the resources back to the last ready generation
+	// of the instance when a new generation doesn't become ready.
+	rollbackOnFailure bool
+	// rolloutStart is the time the current generation of the instance was
+	// first applied, if it's being rolled out over a ready generation that
+	// can be rolled back to.
+	rolloutStart time.Time
+	// namespaceRejected reports that the namespace of the instance isn't
+	// selected by the namespace selector of the resource graph definition.
+	namespaceRejected bool
+	// quotaExceeded tells why the instance exceeds the quota of the resource
+	// graph definition, if it does.
+	quotaExceeded string
+	// lastAttempt reports that the instance isn't retried if its
+	// reconciliation fails.
+	lastAttempt bool
 	// state holds the current state of the instance and its sub-resources.
 	state *InstanceState
 }
@@ -64,12 +110,40 @@ func (igr *instanceGraphReconciler) reconcile(ctx context.Context) error {
 	instance := igr.runtime.GetInstance()
 	igr.state = newInstanceState()
 
+	// Leave the instance alone if its namespace isn't selected, unless it's
+	// being deleted and its resources must be cleaned up
+	if igr.namespaceRejected && instance.GetDeletionTimestamp().IsZero() {
+		igr.state.State = InstanceStateRejected
+		return igr.handleReconciliation(ctx, igr.rejectInstance)
+	}
+
+	// Leave the instance alone if it exceeds the quota, unless it's being
+	// deleted
+	if igr.quotaExceeded != "" && instance.GetDeletionTimestamp().IsZero() {
+		igr.state.State = InstanceStateRejected
+		return igr.handleReconciliation(ctx, igr.rejectOverQuota)
+	}
+
+	// Only observe the resources if the reconciliation is paused, including
+	// while the instance is being deleted
+	if metadata.IsPaused(instance) {
+		igr.state.State = InstanceStatePaused
+		return igr.handleReconciliation(ctx, igr.observeResources)
+	}
+
 	// Handle instance deletion if marked for deletion
 	if !instance.GetDeletionTimestamp().IsZero() {
 		igr.state.State = "DELETING"
 		return igr.handleReconciliation(ctx, igr.handleInstanceDeletion)
 	}
 
+	// Only preview the changes to the resources if the instance requests a
+	// plan
+	if metadata.IsPlanned(instance) {
+		igr.state.State = InstanceStatePlanned
+		return igr.handleReconciliation(ctx, igr.planResources)
+	}
+
 	return igr.handleReconciliation(ctx, igr.reconcileInstance)
 }
 
@@ -82,6 +156,7 @@ func (igr *instanceGraphReconciler) handleReconciliation(ctx context.Context, re
 
 		// Prepare and patch status
 		status := igr.prepareStatus()
+		igr.state.Status = status
 		if err := igr.patchInstanceStatus(ctx, status); err != nil {
 			// Only log error if instance still exists
 			if !apierrors.IsNotFound(err) {
@@ -94,6 +169,21 @@ func (igr *instanceGraphReconciler) handleReconciliation(ctx context.Context, re
 	return igr.state.ReconcileErr
 }
 
+// rejectInstance rejects an instance created in a namespace the resource graph
+// definition doesn't select. It isn't requeued: the instance is reconciled
+// again once it changes.
+func (igr *instanceGraphReconciler) rejectInstance(_ context.Context) error {
+	return requeue.None(fmt.Errorf("namespace %s isn't selected by the namespaceSelector of the resource graph definition",
+		igr.runtime.GetInstance().GetNamespace()))
+}
+
+// rejectOverQuota rejects an instance exceeding the quota of the resource
+// graph definition. It's checked again periodically, since the deletion of
+// other instances may bring it within the quota.
+func (igr *instanceGraphReconciler) rejectOverQuota(_ context.Context) error {
+	return requeue.NeededAfter(errors.New(igr.quotaExceeded), quotaRecheckInterval)
+}
+
 // reconcileInstance handles the reconciliation of an active instance
 func (igr *instanceGraphReconciler) reconcileInstance(ctx context.Context) error {
 	instance := igr.runtime.GetInstance()
@@ -108,21 +198,213 @@ func (igr *instanceGraphReconciler) reconcileInstance(ctx context.Context) error
 		igr.state.ResourceStates[resourceID] = &ResourceState{State: "PENDING"}
 	}
 
-	// Reconcile resources in topological order
+	// The preApply hooks of the instance must hold before any resource is
+	// applied
+	if ok, reason, err := igr.runtime.CheckHooks("", runtime.HookPhasePreApply); err != nil || !ok {
+		return igr.delayedRequeue(fmt.Errorf("waiting for preApply hooks of the instance: %s: %w", reason, err))
+	}
+
+	if igr.rollbackOnFailure {
+		return igr.reconcileWithRollback(ctx)
+	}
+
+	// Reconcile resources following the dependency graph
+	if err := igr.reconcileResources(ctx); err != nil {
+		return err
+	}
+
+	return igr.pruneExpandedResources(ctx)
+}
+
+// observeResources reads the resources of a paused instance, following the
+// dependency graph, without creating, updating or deleting any of them. It
+// lets the instance status report their observed state.
+func (igr *instanceGraphReconciler) observeResources(ctx context.Context) error {
 	for _, resourceID := range igr.runtime.TopologicalOrder() {
-		if err := igr.reconcileResource(ctx, resourceID); err != nil {
-			return err
-		}
+		resourceState := &ResourceState{State: "PENDING"}
+		igr.state.ResourceStates[resourceID] = resourceState
 
-		// Synchronize runtime state after each resource
 		if _, err := igr.runtime.Synchronize(); err != nil {
-			return fmt.Errorf("failed to synchronize reconciling resource %s: %w", resourceID, err)
+			return fmt.Errorf("failed to synchronize resources: %w", err)
+		}
+		resource, state := igr.runtime.GetResource(resourceID)
+		if state != runtime.ResourceStateResolved {
+			continue
+		}
+
+		observed, err := igr.getResourceClient(resourceID).Get(ctx, resource.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			resourceState.State = "ERROR"
+			resourceState.Err = fmt.Errorf("failed to get resource: %w", err)
+			return resourceState.Err
+		}
+		igr.runtime.SetResource(resourceID, observed)
+
+		if ready, _, err := igr.runtime.IsResourceReady(resourceID); err != nil || !ready {
+			resourceState.State = "WAITING_FOR_READINESS"
+		} else {
+			resourceState.State = "SYNCED"
+			resourceState.Ready = true
 		}
 	}
 
+	if _, err := igr.runtime.Synchronize(); err != nil {
+		return fmt.Errorf("failed to synchronize resources: %w", err)
+	}
 	return nil
 }
 
+// reconcileResources reconciles the resources of the instance following the
+// dependency graph: a resource is reconciled once all of its dependencies are,
+// so that independent branches of the graph are reconciled concurrently, by
+// up to MaxConcurrentResourceReconciles workers. A resource that fails or
+// isn't ready yet only holds back the resources depending on it.
+func (igr *instanceGraphReconciler) reconcileResources(ctx context.Context) error {
+	rt := igr.runtime
+	igr.runtime = &lockedRuntime{runtime: rt}
+	defer func() { igr.runtime = rt }()
+
+	errs := reconcileInDependencyOrder(
+		igr.runtime.TopologicalOrder(),
+		func(resourceID string) []string {
+			return igr.runtime.ResourceDescriptor(resourceID).GetDependencies()
+		},
+		igr.reconcileConfig.MaxConcurrentResourceReconciles,
+		func(resourceID string) error {
+			if err := igr.reconcileResource(ctx, resourceID); err != nil {
+				return err
+			}
+
+			// Synchronize runtime state after each resource
+			if _, err := igr.runtime.Synchronize(); err != nil {
+				igr.recordEvent(corev1.EventTypeWarning, eventReasonEvaluationFailed,
+					"Failed to evaluate the expressions depending on resource %s: %v", resourceID, err)
+				return fmt.Errorf("failed to synchronize reconciling resource %s: %w", resourceID, err)
+			}
+			return nil
+		},
+	)
+	return igr.joinErrors(errs)
+}
+
+// reconcileInDependencyOrder reconciles the given resources, in topological
+// order, once all of their dependencies were reconciled without error. Up to
+// workers resources are reconciled concurrently. The resources depending on a
+// resource that failed aren't reconciled, and the errors are returned in the
+// order they happened.
+func reconcileInDependencyOrder(
+	order []string,
+	dependencies func(resourceID string) []string,
+	workers int,
+	reconcile func(resourceID string) error,
+) []error {
+	workers = max(workers, 1)
+	known := make(map[string]bool, len(order))
+	for _, resourceID := range order {
+		known[resourceID] = true
+	}
+
+	type result struct {
+		resourceID string
+		err        error
+	}
+	results := make(chan result)
+	reconciled := make(map[string]bool, len(order))
+	failed := make(map[string]bool)
+	pending := order
+	running := 0
+	var errs []error
+	for {
+		// Start the resources whose dependencies are reconciled. Pending
+		// resources are in topological order, so a failure is propagated to
+		// all of its dependents in a single pass.
+		var waiting []string
+		for _, resourceID := range pending {
+			ready, blocked := true, false
+			for _, dependency := range dependencies(resourceID) {
+				if !known[dependency] {
+					continue
+				}
+				if failed[dependency] {
+					blocked = true
+					break
+				}
+				if !reconciled[dependency] {
+					ready = false
+				}
+			}
+			switch {
+			case blocked:
+				failed[resourceID] = true
+			case ready && running < workers:
+				running++
+				go func() {
+					results <- result{resourceID: resourceID, err: reconcile(resourceID)}
+				}()
+			default:
+				waiting = append(waiting, resourceID)
+			}
+		}
+		pending = waiting
+
+		if running == 0 {
+			return errs
+		}
+		r := <-results
+		running--
+		if r.err != nil {
+			failed[r.resourceID] = true
+			errs = append(errs, r.err)
+		} else {
+			reconciled[r.resourceID] = true
+		}
+	}
+}
+
+// joinErrors combines the errors of the resources reconciled concurrently.
+// Errors take precedence over requeues, which are combined into a single
+// delayed requeue. If all of them follow a retry policy, the requeue follows
+// the one retrying the soonest.
+func (igr *instanceGraphReconciler) joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	var failures, requeues []error
+	var backoff *requeue.Backoff
+	allBackoff := true
+	for _, err := range errs {
+		switch typedErr := err.(type) {
+		case *requeue.RequeueNeeded:
+			requeues = append(requeues, typedErr.Unwrap())
+			allBackoff = false
+		case *requeue.RequeueNeededAfter:
+			requeues = append(requeues, typedErr.Unwrap())
+			switch b := typedErr.Backoff(); {
+			case b == nil:
+				allBackoff = false
+			case backoff == nil || b.InitialDelay < backoff.InitialDelay:
+				backoff = b
+			}
+		default:
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+	delayed := requeue.NeededAfter(errors.Join(requeues...), igr.reconcileConfig.DefaultRequeueDuration)
+	if allBackoff {
+		delayed.WithBackoff(backoff)
+	}
+	return delayed
+}
+
 // setupInstance prepares an instance for reconciliation by setting up necessary
 // labels and managed state.
 func (igr *instanceGraphReconciler) setupInstance(ctx context.Context, instance *unstructured.Unstructured) error {
@@ -139,27 +421,169 @@ func (igr *instanceGraphReconciler) setupInstance(ctx context.Context, instance
 // reconcileResource handles the reconciliation of a single resource within the instance
 func (igr *instanceGraphReconciler) reconcileResource(ctx context.Context, resourceID string) error {
 	log := igr.log.WithValues("resourceID", resourceID)
-	resourceState := &ResourceState{State: "IN_PROGRESS"}
-	igr.state.ResourceStates[resourceID] = resourceState
+	// Resources are reconciled concurrently, their states are initialized
+	// beforehand so that the map isn't written to.
+	resourceState := igr.state.ResourceStates[resourceID]
+	resourceState.State = "IN_PROGRESS"
+
+	ctx, span := tracing.Start(ctx, "resource.reconcile", tracing.String("kro.resource.id", resourceID))
+	defer func() {
+		span.SetAttributes(tracing.String("kro.resource.state", resourceState.State))
+		if resourceState.State == "ERROR" {
+			span.RecordError(igr.redactor.Error(resourceState.Err))
+		}
+		span.End()
+	}()
 
 	// Check if resource should be created
 	if want, err := igr.runtime.WantToCreateResource(resourceID); err != nil || !want {
 		log.V(1).Info("Skipping resource creation", "reason", err)
 		resourceState.State = "SKIPPED"
-		igr.runtime.IgnoreResource(resourceID)
-		return nil
+		igr.runtime.IgnoreResource(resourceID, err)
+		// The conditions that failed to evaluate, e.g referring to a status
+		// field that isn't set yet, don't remove the resource, nor its
+		// dependents, which get the same error.
+		var exprErr *krocel.ExpressionError
+		if errors.As(err, &exprErr) {
+			return nil
+		}
+		return igr.deleteExcludedResource(ctx, resourceID)
 	}
 
 	// Get and validate resource state
+	_, renderSpan := tracing.Start(ctx, "resource.render")
 	resource, state := igr.runtime.GetResource(resourceID)
+	renderSpan.SetAttributes(tracing.String("kro.resource.render_state", string(state)))
+	renderSpan.End()
 	if state != runtime.ResourceStateResolved {
-		return igr.delayedRequeue(fmt.Errorf("resource %s not resolved: state=%v", resourceID, state))
+		return igr.delayedResourceRequeue(resourceID, fmt.Errorf("resource %s not resolved: state=%v", resourceID, state))
+	}
+
+	// Patches change some fields of existing objects, external references
+	// are only read
+	if igr.runtime.ResourceDescriptor(resourceID).GetPatchType() != "" {
+		return igr.applyPatch(ctx, resourceID, resource, resourceState)
+	}
+	if igr.runtime.ResourceDescriptor(resourceID).IsExternalRef() {
+		return igr.readExternalRef(ctx, resourceID, resource, resourceState)
+	}
+
+	// The preApply hooks must hold before the resource is applied
+	if ok, reason, err := igr.runtime.CheckHooks(resourceID, runtime.HookPhasePreApply); err != nil || !ok {
+		log.V(1).Info("Waiting for preApply hooks", "reason", reason, "error", err)
+		resourceState.State = "WAITING_FOR_HOOKS"
+		resourceState.Err = fmt.Errorf("preApply hooks not satisfied: %s: %w", reason, err)
+		return igr.delayedResourceRequeue(resourceID, resourceState.Err)
 	}
 
 	// Handle resource reconciliation
 	return igr.handleResourceReconciliation(ctx, resourceID, resource, resourceState)
 }
 
+// deleteExcludedResource deletes the object of the given resource, excluded
+// by its includeWhen conditions or by those of its dependencies, if it was
+// created while they held. The object is deleted following the deletion
+// policy of the resource, patches are reverted. Objects whose name depends on
+// excluded resources can't be found and are left as is.
+func (igr *instanceGraphReconciler) deleteExcludedResource(ctx context.Context, resourceID string) error {
+	descriptor := igr.runtime.ResourceDescriptor(resourceID)
+	if descriptor.IsExternalRef() && descriptor.GetPatchType() == "" {
+		return nil
+	}
+	resource, state := igr.runtime.GetResource(resourceID)
+	if state != runtime.ResourceStateResolved {
+		return nil
+	}
+	observed, err := igr.getResourceClient(resourceID).Get(ctx, resource.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get excluded resource %s: %w", resourceID, err)
+	}
+	if descriptor.GetPatchType() != "" {
+		_, patched := observed.GetAnnotations()[igr.revertAnnotation()]
+		if !patched || descriptor.GetDeletionPolicy() != v1alpha1.DeletionPolicyDelete {
+			return nil
+		}
+	} else if !igr.isManaged(observed) || observed.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	igr.runtime.SetResource(resourceID, observed)
+	if err := igr.deleteResource(ctx, resourceID); err != nil {
+		return err
+	}
+	igr.state.ResourceStates[resourceID].State = "SKIPPED"
+	return nil
+}
+
+// references returns the objects the instance refers to: the objects
+// referenced by its resolved external references, and the resolved resources
+// it manages, so that changes made to them outside of kro are detected.
+func (igr *instanceGraphReconciler) references() []dynamiccontroller.ObjectIdentifiers {
+	var references []dynamiccontroller.ObjectIdentifiers
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		descriptor := igr.runtime.ResourceDescriptor(resourceID)
+		resource, state := igr.runtime.GetResource(resourceID)
+		if state != runtime.ResourceStateResolved {
+			continue
+		}
+		namespacedKey := resource.GetName()
+		if descriptor.IsNamespaced() {
+			namespacedKey = igr.getResourceNamespace(resourceID) + "/" + namespacedKey
+		}
+		reference := dynamiccontroller.ObjectIdentifiers{
+			NamespacedKey: namespacedKey,
+			GVR:           descriptor.GetGroupVersionResource(),
+		}
+		if target := descriptor.GetTarget(); target != nil {
+			reference.Cluster = kroclient.ClusterName(target)
+		}
+		references = append(references, reference)
+	}
+	return references
+}
+
+// readExternalRef reads the object referenced by an external reference. The
+// object isn't managed by kro: it's never created nor updated, and the
+// resources depending on it wait until it exists and is ready.
+func (igr *instanceGraphReconciler) readExternalRef(
+	ctx context.Context,
+	resourceID string,
+	resource *unstructured.Unstructured,
+	resourceState *ResourceState,
+) error {
+	log := igr.log.WithValues("resourceID", resourceID)
+
+	rc := igr.getResourceClient(resourceID)
+	observed, err := rc.Get(ctx, resource.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			resourceState.State = "WAITING_FOR_EXTERNAL_REF"
+			resourceState.Err = fmt.Errorf("referenced object %s not found", resource.GetName())
+			return igr.delayedResourceRequeue(resourceID, resourceState.Err)
+		}
+		resourceState.State = "ERROR"
+		resourceState.Err = fmt.Errorf("failed to get referenced object: %w", err)
+		return resourceState.Err
+	}
+
+	igr.runtime.SetResource(resourceID, observed)
+
+	if ready, reason, err := igr.runtime.IsResourceReady(resourceID); err != nil || !ready {
+		log.V(1).Info("Referenced object not ready", "reason", reason, "error", err)
+		igr.recordReadinessEvaluationFailure(resourceID, err)
+		resourceState.State = "WAITING_FOR_READINESS"
+		resourceState.Err = fmt.Errorf("referenced object not ready: %s: %w", reason, err)
+		return igr.delayedResourceRequeue(resourceID, resourceState.Err)
+	}
+
+	resourceState.State = "SYNCED"
+	resourceState.Ready = true
+	return nil
+}
+
 // handleResourceReconciliation manages the reconciliation of a specific resource,
 // including creation, updates, and readiness checks.
 func (igr *instanceGraphReconciler) handleResourceReconciliation(
@@ -184,19 +608,106 @@ func (igr *instanceGraphReconciler) handleResourceReconciliation(
 		return resourceState.Err
 	}
 
+	// Check that the instance can manage the existing resource
+	if err := igr.checkAdoption(resourceID, observed); err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = err
+		return resourceState.Err
+	}
+
 	// Update runtime with observed state
 	igr.runtime.SetResource(resourceID, observed)
 
 	// Check resource readiness
-	if ready, reason, err := igr.runtime.IsResourceReady(resourceID); err != nil || !ready {
+	_, readinessSpan := tracing.Start(ctx, "resource.readiness")
+	ready, reason, err := igr.runtime.IsResourceReady(resourceID)
+	readinessSpan.SetAttributes(tracing.Bool("kro.resource.ready", ready && err == nil))
+	readinessSpan.End()
+	if err != nil || !ready {
 		log.V(1).Info("Resource not ready", "reason", reason, "error", err)
+		igr.recordReadinessEvaluationFailure(resourceID, err)
 		resourceState.State = "WAITING_FOR_READINESS"
 		resourceState.Err = fmt.Errorf("resource not ready: %s: %w", reason, err)
-		return igr.delayedRequeue(resourceState.Err)
+		return igr.handleReadinessTimeout(resourceID, observed, resourceState)
 	}
 
 	resourceState.State = "SYNCED"
-	return igr.updateResource(ctx, rc, resource, observed, resourceID, resourceState)
+	resourceState.Ready = true
+	if err := igr.updateResource(ctx, rc, resource, observed, resourceID, resourceState); err != nil {
+		return err
+	}
+
+	// The resources depending on the resource wait for its postApply hooks
+	if ok, reason, err := igr.runtime.CheckHooks(resourceID, runtime.HookPhasePostApply); err != nil || !ok {
+		log.V(1).Info("Waiting for postApply hooks", "reason", reason, "error", err)
+		resourceState.State = "WAITING_FOR_HOOKS"
+		resourceState.Err = fmt.Errorf("postApply hooks not satisfied: %s: %w", reason, err)
+		return igr.delayedResourceRequeue(resourceID, resourceState.Err)
+	}
+	return nil
+}
+
+// isManaged returns true if the given object is managed by the instance.
+func (igr *instanceGraphReconciler) isManaged(obj *unstructured.Unstructured) bool {
+	return obj.GetLabels()[metadata.InstanceIDLabel] == string(igr.runtime.GetInstance().GetUID())
+}
+
+// checkAdoption returns an error if the given existing object of a resource
+// isn't managed by the instance, and can't be adopted following the adoption
+// policy of the resource.
+func (igr *instanceGraphReconciler) checkAdoption(resourceID string, observed *unstructured.Unstructured) error {
+	if igr.isManaged(observed) {
+		return nil
+	}
+	owner, managed := observed.GetLabels()[metadata.InstanceIDLabel]
+	switch igr.runtime.ResourceDescriptor(resourceID).GetAdoptionPolicy() {
+	case v1alpha1.AdoptionPolicyFail:
+		return fmt.Errorf("%s %s already exists and isn't managed by the instance", observed.GetKind(), observed.GetName())
+	case v1alpha1.AdoptionPolicyAdoptOrFail:
+		if managed {
+			return fmt.Errorf("%s %s is managed by another instance %s", observed.GetKind(), observed.GetName(), owner)
+		}
+	}
+	igr.log.Info("Adopting existing resource", "resourceID", resourceID, "previousInstance", owner)
+	return nil
+}
+
+// handleReadinessTimeout handles a resource that isn't ready yet. Once its
+// readiness timeout has passed since its creation, the resource is reported
+// as timed out and its failure policy decides whether to keep waiting for it,
+// to reconcile the resources depending on it anyway, or to fail the
+// reconciliation of the instance.
+func (igr *instanceGraphReconciler) handleReadinessTimeout(
+	resourceID string,
+	observed *unstructured.Unstructured,
+	resourceState *ResourceState,
+) error {
+	descriptor := igr.runtime.ResourceDescriptor(resourceID)
+	timeout := descriptor.GetReadinessTimeout()
+	// The timeout of a resource updated by a new generation of the instance
+	// starts when the generation is first applied, if it can be rolled back.
+	start := observed.GetCreationTimestamp().Time
+	if igr.rolloutStart.After(start) {
+		start = igr.rolloutStart
+	}
+	if timeout == 0 || time.Since(start) < timeout {
+		return igr.delayedResourceRequeue(resourceID, resourceState.Err)
+	}
+
+	resourceState.TimedOut = true
+	resourceState.Err = fmt.Errorf("resource not ready within %s: %w", timeout, resourceState.Err)
+	igr.recordEvent(corev1.EventTypeWarning, eventReasonReadinessTimeout,
+		"%s of resource %s not ready within %s", describeObject(observed), resourceID, timeout)
+	switch descriptor.GetOnFailure() {
+	case v1alpha1.FailurePolicyContinue:
+		resourceState.State = "TIMED_OUT"
+		return nil
+	case v1alpha1.FailurePolicyAbort:
+		resourceState.State = "ERROR"
+		return resourceState.Err
+	default:
+		return igr.delayedResourceRequeue(resourceID, resourceState.Err)
+	}
 }
 
 // getResourceClient returns the appropriate dynamic client and namespace for a resource
@@ -205,10 +716,20 @@ func (igr *instanceGraphReconciler) getResourceClient(resourceID string) dynamic
 	gvr := descriptor.GetGroupVersionResource()
 	namespace := igr.getResourceNamespace(resourceID)
 
+	client := igr.targetClient(descriptor)
 	if descriptor.IsNamespaced() {
-		return igr.client.Resource(gvr).Namespace(namespace)
+		return client.Resource(gvr).Namespace(namespace)
+	}
+	return client.Resource(gvr)
+}
+
+// targetClient returns the client of the cluster the given resource is
+// applied to, either a remote cluster or the cluster of kro.
+func (igr *instanceGraphReconciler) targetClient(descriptor runtime.ResourceDescriptor) dynamic.Interface {
+	if descriptor == nil || descriptor.GetTarget() == nil {
+		return igr.client
 	}
-	return igr.client.Resource(gvr)
+	return igr.targetClients[descriptor.GetTarget().KubeconfigSecretRef]
 }
 
 // handleResourceCreation manages the creation of a new resource
@@ -222,15 +743,23 @@ func (igr *instanceGraphReconciler) handleResourceCreation(
 	igr.log.V(1).Info("Creating new resource", "resourceID", resourceID)
 
 	// Apply labels and create resource
+	if err := setDesiredHash(resource, igr.runtime.ResourceDescriptor(resourceID).GetIgnoreFields()); err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = err
+		return resourceState.Err
+	}
 	igr.instanceSubResourcesLabeler.ApplyLabels(resource)
-	if _, err := rc.Create(ctx, resource, metav1.CreateOptions{}); err != nil {
+	igr.setNodeIDLabel(resourceID, resource)
+	if err := igr.applyResource(ctx, rc, resourceID, resource); err != nil {
 		resourceState.State = "ERROR"
 		resourceState.Err = fmt.Errorf("failed to create resource: %w", err)
 		return resourceState.Err
 	}
+	igr.recordEvent(corev1.EventTypeNormal, eventReasonResourceCreated, "Created %s of resource %s", igr.describeResource(resourceID, resource), resourceID)
 
 	resourceState.State = "CREATED"
-	return igr.delayedRequeue(fmt.Errorf("awaiting resource creation completion"))
+	resourceState.AppliedGeneration = igr.runtime.GetInstance().GetGeneration()
+	return igr.delayedResourceRequeue(resourceID, fmt.Errorf("awaiting resource creation completion"))
 }
 
 // updateResource handles updates to an existing resource, comparing the desired
@@ -244,6 +773,26 @@ func (igr *instanceGraphReconciler) updateResource(
 ) error {
 	igr.log.V(1).Info("Processing resource update", "resourceID", resourceID)
 
+	// Leave the ignored fields to their other managers
+	ignoreFields := igr.runtime.ResourceDescriptor(resourceID).GetIgnoreFields()
+	desired, err := withIgnoredFields(desired, observed, ignoreFields, igr.reconcileConfig.FieldManager)
+	if err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = err
+		return resourceState.Err
+	}
+
+	// Leave out the fields managed by other field managers, if the resource
+	// leaves them to these
+	if igr.runtime.ResourceDescriptor(resourceID).GetConflictPolicy() == v1alpha1.ConflictPolicyIgnoreFields {
+		desired, err = withoutForeignFields(desired, observed, igr.reconcileConfig.FieldManager)
+		if err != nil {
+			resourceState.State = "ERROR"
+			resourceState.Err = err
+			return resourceState.Err
+		}
+	}
+
 	// Compare desired and observed states
 	differences, err := delta.Compare(desired, observed)
 	if err != nil {
@@ -252,13 +801,38 @@ func (igr *instanceGraphReconciler) updateResource(
 		return resourceState.Err
 	}
 
-	// If no differences are found, the resource is in sync.
-	if len(differences) == 0 {
+	// If no differences are found, the resource is in sync. Adopted resources
+	// are applied anyway to label them.
+	if len(differences) == 0 && igr.isManaged(observed) {
 		resourceState.State = "SYNCED"
+		resourceState.AppliedGeneration = igr.runtime.GetInstance().GetGeneration()
 		igr.log.V(1).Info("No deltas found for resource", "resourceID", resourceID)
 		return nil
 	}
 
+	// If the desired state didn't change since it was last applied by the
+	// instance, the differences were made outside of kro. They're only
+	// reverted if the resource auto heals.
+	if err := setDesiredHash(desired, ignoreFields); err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = err
+		return resourceState.Err
+	}
+	desiredHash := desired.GetAnnotations()[metadata.DesiredHashAnnotation]
+	if igr.isManaged(observed) && desiredHash == observed.GetAnnotations()[metadata.DesiredHashAnnotation] {
+		if !igr.runtime.ResourceDescriptor(resourceID).IsAutoHeal() {
+			igr.log.V(1).Info("Keeping changes made outside of kro", "resourceID", resourceID, "delta", differences)
+			igr.recordEvent(corev1.EventTypeWarning, eventReasonDriftDetected,
+				"%s of resource %s was changed outside of kro, keeping the changes", describeObject(observed), resourceID)
+			resourceState.State = "DRIFTED"
+			resourceState.Drifted = true
+			return nil
+		}
+		igr.log.Info("Reverting changes made outside of kro", "resourceID", resourceID)
+		igr.recordEvent(corev1.EventTypeWarning, eventReasonDriftDetected,
+			"%s of resource %s was changed outside of kro, reverting the changes", describeObject(observed), resourceID)
+	}
+
 	// Proceed with the update, note that we don't need to handle each difference
 	// individually. We can apply all changes at once.
 	//
@@ -268,21 +842,20 @@ func (igr *instanceGraphReconciler) updateResource(
 		"delta", differences,
 	)
 	igr.instanceSubResourcesLabeler.ApplyLabels(desired)
+	igr.setNodeIDLabel(resourceID, desired)
 
 	// Apply changes to the resource
-	// TODO: Handle annotations
-	desired.SetResourceVersion(observed.GetResourceVersion())
-	desired.SetFinalizers(observed.GetFinalizers())
-	_, err = rc.Update(ctx, desired, metav1.UpdateOptions{})
-	if err != nil {
+	if err := igr.applyResource(ctx, rc, resourceID, desired); err != nil {
 		resourceState.State = "ERROR"
 		resourceState.Err = fmt.Errorf("failed to update resource: %w", err)
 		return resourceState.Err
 	}
+	igr.recordEvent(corev1.EventTypeNormal, eventReasonResourceUpdated, "Updated %s of resource %s", igr.describeResource(resourceID, desired), resourceID)
 
 	// Set state to UPDATING and requeue to check the update
 	resourceState.State = "UPDATING"
-	return igr.delayedRequeue(fmt.Errorf("resource update in progress"))
+	resourceState.AppliedGeneration = igr.runtime.GetInstance().GetGeneration()
+	return igr.delayedResourceRequeue(resourceID, fmt.Errorf("resource update in progress"))
 }
 
 // handleInstanceDeletion manages the deletion of an instance and its resources
@@ -300,6 +873,11 @@ func (igr *instanceGraphReconciler) handleInstanceDeletion(ctx context.Context)
 		return err
 	}
 
+	// Delete the resources whose item was removed before the instance
+	if err := igr.pruneExpandedResources(ctx); err != nil {
+		return err
+	}
+
 	// Check if all resources are deleted and cleanup instance
 	return igr.finalizeDeletion(ctx)
 }
@@ -323,6 +901,25 @@ func (igr *instanceGraphReconciler) initializeDeletionState() error {
 		// Check if resource exists
 		rc := igr.getResourceClient(resourceID)
 		observed, err := rc.Get(context.TODO(), resource.GetName(), metav1.GetOptions{})
+
+		// Referenced objects aren't owned by the instance and are left as is,
+		// they're only read to resolve the resources depending on them. The
+		// patches of the instance are reverted, unless their deletion policy
+		// keeps them.
+		if descriptor := igr.runtime.ResourceDescriptor(resourceID); descriptor.IsExternalRef() {
+			resourceState := &ResourceState{State: "SKIPPED"}
+			if err == nil {
+				igr.runtime.SetResource(resourceID, observed)
+				_, patched := observed.GetAnnotations()[igr.revertAnnotation()]
+				if patched && descriptor.GetPatchType() != "" &&
+					descriptor.GetDeletionPolicy() == v1alpha1.DeletionPolicyDelete {
+					resourceState.State = "PENDING_DELETION"
+				}
+			}
+			igr.state.ResourceStates[resourceID] = resourceState
+			continue
+		}
+
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				igr.state.ResourceStates[resourceID] = &ResourceState{
@@ -333,41 +930,120 @@ func (igr *instanceGraphReconciler) initializeDeletionState() error {
 			return fmt.Errorf("failed to check resource %s existence: %w", resourceID, err)
 		}
 
+		// Objects not managed by the instance, which failed to be adopted,
+		// are left as is.
+		if !igr.isManaged(observed) {
+			igr.state.ResourceStates[resourceID] = &ResourceState{
+				State: "SKIPPED",
+			}
+			continue
+		}
+
 		igr.runtime.SetResource(resourceID, observed)
-		igr.state.ResourceStates[resourceID] = &ResourceState{
-			State: "PENDING_DELETION",
+		// Resources being finalized were already deleted, they're only waited
+		// for.
+		resourceState := &ResourceState{State: "PENDING_DELETION"}
+		if observed.GetDeletionTimestamp() != nil {
+			resourceState.State = InstanceStateDeleting
 		}
+		igr.state.ResourceStates[resourceID] = resourceState
 	}
 	return nil
 }
 
-// deleteResourcesInOrder processes resource deletion in reverse topological order
-// to respect dependencies between resources.
+// deleteResourcesInOrder deletes the resources in stages, following the
+// reverse topological order: a resource is only deleted once all the
+// resources depending on it are gone, so that e.g a Namespace isn't deleted
+// while the resources in it are still being finalized. The resources of a
+// stage are deleted together, and the next stage waits until they actually
+// disappear.
 func (igr *instanceGraphReconciler) deleteResourcesInOrder(ctx context.Context) error {
-	// Process resources in reverse order
 	resources := igr.runtime.TopologicalOrder()
+	dependents := make(map[string][]string, len(resources))
+	for _, resourceID := range resources {
+		for _, dependency := range igr.runtime.ResourceDescriptor(resourceID).GetDependencies() {
+			dependents[dependency] = append(dependents[dependency], resourceID)
+		}
+	}
+	gone := func(resourceID string) bool {
+		return isReleased(igr.state.ResourceStates[resourceID])
+	}
+
+	// Process resources in reverse order
+	var deleting []string
+	remaining := 0
 	for i := len(resources) - 1; i >= 0; i-- {
 		resourceID := resources[i]
-		resourceState := igr.state.ResourceStates[resourceID]
-
-		if resourceState == nil || resourceState.State != "PENDING_DELETION" {
+		if gone(resourceID) {
 			continue
 		}
+		remaining++
 
-		if err := igr.deleteResource(ctx, resourceID); err != nil {
-			return err
+		resourceState := igr.state.ResourceStates[resourceID]
+		waitingForDependents := slices.ContainsFunc(dependents[resourceID], func(dependent string) bool {
+			return !gone(dependent)
+		})
+		if resourceState.State == "PENDING_DELETION" && !waitingForDependents {
+			if err := igr.deleteResource(ctx, resourceID); err != nil {
+				return err
+			}
+		}
+		if resourceState.State == InstanceStateDeleting {
+			deleting = append(deleting, resourceID)
 		}
 	}
-	return nil
+
+	if len(deleting) == 0 {
+		return nil
+	}
+	return igr.delayedRequeue(fmt.Errorf("waiting for the deletion of %s, %d of %d resources remaining",
+		strings.Join(deleting, ", "), remaining, len(resources)))
+}
+
+// isReleased returns true if the given resource is no longer managed by the
+// instance: it was deleted, left in the cluster following its deletion
+// policy, or never created.
+func isReleased(resourceState *ResourceState) bool {
+	if resourceState == nil {
+		return true
+	}
+	switch resourceState.State {
+	case "DELETED", "ORPHANED", "RETAINED", "REVERTED", "SKIPPED":
+		return true
+	default:
+		return false
+	}
 }
 
-// deleteResource handles the deletion of a single resource and updates its state.
+// deleteResource handles the deletion of a single resource and updates its
+// state. Resources whose deletion policy is Orphan or Retain are left in the
+// cluster instead, and patches are reverted.
 func (igr *instanceGraphReconciler) deleteResource(ctx context.Context, resourceID string) error {
-	igr.log.V(1).Info("Deleting resource", "resourceID", resourceID)
+	if igr.runtime.ResourceDescriptor(resourceID).GetPatchType() != "" {
+		return igr.revertPatchedObject(ctx, resourceID)
+	}
 
 	resource, _ := igr.runtime.GetResource(resourceID)
 	rc := igr.getResourceClient(resourceID)
 
+	switch igr.runtime.ResourceDescriptor(resourceID).GetDeletionPolicy() {
+	case v1alpha1.DeletionPolicyRetain:
+		igr.log.V(1).Info("Retaining resource", "resourceID", resourceID)
+		igr.state.ResourceStates[resourceID].State = "RETAINED"
+		return nil
+	case v1alpha1.DeletionPolicyOrphan:
+		igr.log.V(1).Info("Orphaning resource", "resourceID", resourceID)
+		if err := igr.orphanResource(ctx, rc, resource); err != nil {
+			igr.state.ResourceStates[resourceID].State = InstanceStateError
+			igr.state.ResourceStates[resourceID].Err = err
+			return err
+		}
+		igr.state.ResourceStates[resourceID].State = "ORPHANED"
+		return nil
+	}
+
+	igr.log.V(1).Info("Deleting resource", "resourceID", resourceID)
+
 	// Attempt to delete the resource
 	err := rc.Delete(ctx, resource.GetName(), metav1.DeleteOptions{})
 	if err != nil {
@@ -379,9 +1055,27 @@ func (igr *instanceGraphReconciler) deleteResource(ctx context.Context, resource
 		igr.state.ResourceStates[resourceID].Err = fmt.Errorf("failed to delete resource: %w", err)
 		return igr.state.ResourceStates[resourceID].Err
 	}
+	igr.recordEvent(corev1.EventTypeNormal, eventReasonResourceDeleted, "Deleted %s of resource %s", igr.describeResource(resourceID, resource), resourceID)
 
 	igr.state.ResourceStates[resourceID].State = InstanceStateDeleting
-	return igr.delayedRequeue(fmt.Errorf("resource deletion in progress"))
+	return nil
+}
+
+// orphanResource removes the labels tying the given object to the instance,
+// so that kro no longer manages it.
+func (igr *instanceGraphReconciler) orphanResource(ctx context.Context, rc dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+	orphaned := obj.DeepCopy()
+	objLabels := orphaned.GetLabels()
+	for key := range igr.instanceSubResourcesLabeler.Labels() {
+		delete(objLabels, key)
+	}
+	delete(objLabels, metadata.NodeIDLabel)
+	orphaned.SetLabels(objLabels)
+
+	if _, err := rc.Update(ctx, orphaned, metav1.UpdateOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to orphan resource: %w", err)
+	}
+	return nil
 }
 
 // finalizeDeletion checks if all resources are deleted and removes the instance finalizer
@@ -389,7 +1083,7 @@ func (igr *instanceGraphReconciler) deleteResource(ctx context.Context, resource
 func (igr *instanceGraphReconciler) finalizeDeletion(ctx context.Context) error {
 	// Check if all resources are deleted
 	for _, resourceState := range igr.state.ResourceStates {
-		if resourceState.State != "DELETED" && resourceState.State != "SKIPPED" {
+		if !isReleased(resourceState) {
 			return igr.delayedRequeue(fmt.Errorf("waiting for resource deletion completion"))
 		}
 	}
@@ -405,6 +1099,125 @@ func (igr *instanceGraphReconciler) finalizeDeletion(ctx context.Context) error
 	return nil
 }
 
+// setNodeIDLabel labels the resources expanded from a template with the id of
+// the template, so that they can be found once their item is removed from the
+// instance.
+func (igr *instanceGraphReconciler) setNodeIDLabel(resourceID string, obj *unstructured.Unstructured) {
+	expanded, ok := igr.runtime.ResourceDescriptor(resourceID).(runtime.ExpandedResource)
+	if !ok || expanded.GetEach() == nil {
+		return
+	}
+	objLabels := obj.GetLabels()
+	if objLabels == nil {
+		objLabels = make(map[string]string)
+	}
+	objLabels[metadata.NodeIDLabel] = expanded.GetTemplateID()
+	obj.SetLabels(objLabels)
+}
+
+// pruneExpandedResources deletes the resources expanded from a template whose
+// item was removed from the instance, or excluded by the includeWhen
+// expressions, following the deletion policy of the template.
+func (igr *instanceGraphReconciler) pruneExpandedResources(ctx context.Context) error {
+	stale, err := igr.staleExpandedResources(ctx)
+	if err != nil {
+		return err
+	}
+	for id, objs := range stale {
+		descriptor := igr.forEachResources[id]
+		for _, obj := range objs {
+			// Retained resources are left as is, and adopted again if their
+			// item comes back.
+			deletionPolicy := descriptor.GetDeletionPolicy()
+			if deletionPolicy == v1alpha1.DeletionPolicyRetain {
+				continue
+			}
+			igr.log.V(1).Info("Pruning resource removed from collection",
+				"resourceID", id,
+				"name", obj.GetName(),
+				"namespace", obj.GetNamespace(),
+				"deletionPolicy", deletionPolicy,
+			)
+			client := igr.targetClient(descriptor)
+			var rc dynamic.ResourceInterface = client.Resource(descriptor.GetGroupVersionResource())
+			if descriptor.IsNamespaced() {
+				rc = client.Resource(descriptor.GetGroupVersionResource()).Namespace(obj.GetNamespace())
+			}
+			if deletionPolicy == v1alpha1.DeletionPolicyOrphan {
+				if err := igr.orphanResource(ctx, rc, &obj); err != nil {
+					return fmt.Errorf("failed to prune resource %s/%s of %s: %w", obj.GetNamespace(), obj.GetName(), id, err)
+				}
+				continue
+			}
+			if err := rc.Delete(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return fmt.Errorf("failed to prune resource %s/%s of %s: %w", obj.GetNamespace(), obj.GetName(), id, err)
+			}
+			igr.recordEvent(corev1.EventTypeNormal, eventReasonResourceDeleted,
+				"Deleted %s of resource %s, removed from its collection", describeObject(&obj), id)
+		}
+	}
+	return nil
+}
+
+// staleExpandedResources returns the existing resources expanded from each
+// template whose item was removed from the instance, or excluded by the
+// includeWhen expressions, by template id. They're listed across namespaces
+// by their instance and node id labels.
+func (igr *instanceGraphReconciler) staleExpandedResources(ctx context.Context) (map[string][]unstructured.Unstructured, error) {
+	if len(igr.forEachResources) == 0 {
+		return nil, nil
+	}
+
+	// Collect the resources currently expanded from each template.
+	current := make(map[string]map[string]bool, len(igr.forEachResources))
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		descriptor := igr.runtime.ResourceDescriptor(resourceID)
+		expanded, ok := descriptor.(runtime.ExpandedResource)
+		if !ok || expanded.GetEach() == nil {
+			continue
+		}
+		if state := igr.state.ResourceStates[resourceID]; state == nil || state.State == "SKIPPED" {
+			continue
+		}
+		resource, state := igr.runtime.GetResource(resourceID)
+		if state != runtime.ResourceStateResolved {
+			continue
+		}
+		namespace := ""
+		if descriptor.IsNamespaced() {
+			namespace = igr.getResourceNamespace(resourceID)
+		}
+		if current[expanded.GetTemplateID()] == nil {
+			current[expanded.GetTemplateID()] = make(map[string]bool)
+		}
+		current[expanded.GetTemplateID()][namespace+"/"+resource.GetName()] = true
+	}
+
+	instance := igr.runtime.GetInstance()
+	stale := make(map[string][]unstructured.Unstructured)
+	for id, descriptor := range igr.forEachResources {
+		selector := labels.SelectorFromSet(labels.Set{
+			metadata.InstanceIDLabel: string(instance.GetUID()),
+			metadata.NodeIDLabel:     id,
+		})
+		list, err := igr.targetClient(descriptor).Resource(descriptor.GetGroupVersionResource()).List(ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources of %s: %w", id, err)
+		}
+		for _, obj := range list.Items {
+			if !current[id][obj.GetNamespace()+"/"+obj.GetName()] {
+				stale[id] = append(stale[id], obj)
+			}
+		}
+	}
+	return stale, nil
+}
+
 // setManaged ensures the instance has the necessary finalizer and labels.
 func (igr *instanceGraphReconciler) setManaged(ctx context.Context, obj *unstructured.Unstructured, uid types.UID) (*unstructured.Unstructured, error) {
 	if exist, _ := metadata.HasInstanceFinalizerUnstructured(obj); exist {
@@ -458,6 +1271,31 @@ func (igr *instanceGraphReconciler) delayedRequeue(err error) error {
 	return requeue.NeededAfter(err, igr.reconcileConfig.DefaultRequeueDuration)
 }
 
+// delayedResourceRequeue wraps an error with requeue information for the
+// controller runtime while waiting for the given resource, following its
+// retry policy if any.
+func (igr *instanceGraphReconciler) delayedResourceRequeue(resourceID string, err error) error {
+	policy := igr.runtime.ResourceDescriptor(resourceID).GetRetryPolicy()
+	return requeue.NeededAfter(err, igr.reconcileConfig.DefaultRequeueDuration).WithBackoff(RetryBackoff(policy))
+}
+
+// setDesiredHash sets the hash of the desired state of the given resource in
+// its annotations, so that the next reconciliations can tell whether it
+// changed. The ignored fields are left out, they take their observed values.
+func setDesiredHash(obj *unstructured.Unstructured, ignoreFields [][]fieldpath.Segment) error {
+	data, err := json.Marshal(withoutIgnoredFields(obj, ignoreFields).Object)
+	if err != nil {
+		return fmt.Errorf("failed to hash desired state: %w", err)
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[metadata.DesiredHashAnnotation] = fmt.Sprintf("%x", sha256.Sum256(data))
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
 // getResourceNamespace determines the appropriate namespace for a resource.
 // It follows this precedence order:
 // 1. Resource's explicitly specified namespace
@@ -0,0 +1,1069 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/dynamiccontroller"
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/requeue"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+func TestReconcileInDependencyOrder(t *testing.T) {
+	// vpc <- subnet <- cluster, and sg, role are independent.
+	order := []string{"vpc", "sg", "role", "subnet", "cluster"}
+	dependencies := map[string][]string{
+		"subnet":  {"vpc"},
+		"cluster": {"subnet", "sg"},
+	}
+
+	tests := []struct {
+		name           string
+		workers        int
+		failing        string
+		wantReconciled []string
+		wantErrs       int
+	}{
+		{
+			name:           "all resources",
+			workers:        2,
+			wantReconciled: []string{"vpc", "sg", "role", "subnet", "cluster"},
+		},
+		{
+			name:           "no workers",
+			workers:        0,
+			wantReconciled: []string{"vpc", "sg", "role", "subnet", "cluster"},
+		},
+		{
+			name:           "failure holds back dependents only",
+			workers:        4,
+			failing:        "vpc",
+			wantReconciled: []string{"vpc", "sg", "role"},
+			wantErrs:       1,
+		},
+		{
+			name:           "failure of a leaf",
+			workers:        4,
+			failing:        "cluster",
+			wantReconciled: []string{"vpc", "sg", "role", "subnet", "cluster"},
+			wantErrs:       1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			reconciled := map[string]bool{}
+			errs := reconcileInDependencyOrder(order, func(resourceID string) []string {
+				return dependencies[resourceID]
+			}, tt.workers, func(resourceID string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, dependency := range dependencies[resourceID] {
+					assert.True(t, reconciled[dependency], "%s reconciled before %s", resourceID, dependency)
+				}
+				reconciled[resourceID] = true
+				if resourceID == tt.failing {
+					return errors.New("failed")
+				}
+				return nil
+			})
+
+			assert.Len(t, errs, tt.wantErrs)
+			assert.Len(t, reconciled, len(tt.wantReconciled))
+			for _, resourceID := range tt.wantReconciled {
+				assert.True(t, reconciled[resourceID], "%s not reconciled", resourceID)
+			}
+		})
+	}
+}
+
+func TestReconcileInDependencyOrder_Concurrency(t *testing.T) {
+	// The independent resources are only released once all of them started,
+	// which only happens if they're reconciled concurrently.
+	order := []string{"a", "b", "c"}
+	var started sync.WaitGroup
+	started.Add(len(order))
+	errs := reconcileInDependencyOrder(order, func(string) []string { return nil }, len(order), func(string) error {
+		started.Done()
+		started.Wait()
+		return nil
+	})
+	assert.Empty(t, errs)
+}
+
+// fakeRuntime is a runtime of a single resource in the default namespace.
+type fakeRuntime struct {
+	runtime.Interface
+	descriptor  runtime.ResourceDescriptor
+	resource    *unstructured.Unstructured
+	observed    *unstructured.Unstructured
+	instanceUID types.UID
+	generation  int64
+	status      map[string]interface{}
+	// excluded is the error WantToCreateResource returns, the resource is
+	// created if it's nil.
+	excluded error
+}
+
+func (r *fakeRuntime) TopologicalOrder() []string {
+	return []string{"config"}
+}
+
+func (r *fakeRuntime) Synchronize() (bool, error) {
+	return false, nil
+}
+
+func (r *fakeRuntime) WantToCreateResource(string) (bool, error) {
+	return r.excluded == nil, r.excluded
+}
+
+func (r *fakeRuntime) IgnoreResource(string, error) {}
+
+func (r *fakeRuntime) SetResource(_ string, obj *unstructured.Unstructured) {
+	r.observed = obj
+}
+
+func (r *fakeRuntime) IsResourceReady(string) (bool, string, error) {
+	return true, "", nil
+}
+
+func (r *fakeRuntime) ResourceDescriptor(string) runtime.ResourceDescriptor {
+	return r.descriptor
+}
+
+func (r *fakeRuntime) GetResource(string) (*unstructured.Unstructured, runtime.ResourceState) {
+	return r.resource, runtime.ResourceStateResolved
+}
+
+func (r *fakeRuntime) GetInstance() *unstructured.Unstructured {
+	instance := &unstructured.Unstructured{}
+	instance.SetNamespace("default")
+	instance.SetUID(r.instanceUID)
+	instance.SetGeneration(r.generation)
+	if r.status != nil {
+		instance.Object["status"] = r.status
+	}
+	return instance
+}
+
+func (r *fakeRuntime) EvaluateConditions() []runtime.ConditionStatus {
+	return nil
+}
+
+// fakeDescriptor is a resource descriptor of namespaced ConfigMaps, only
+// reporting its policies, without a retry policy.
+type fakeDescriptor struct {
+	runtime.ResourceDescriptor
+	autoHeal       bool
+	conflictPolicy v1alpha1.ConflictPolicy
+	ignoreFields   [][]fieldpath.Segment
+	deletionPolicy v1alpha1.DeletionPolicy
+	adoptionPolicy v1alpha1.AdoptionPolicy
+	target         *v1alpha1.Target
+}
+
+func (d *fakeDescriptor) GetGroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+}
+
+func (d *fakeDescriptor) IsNamespaced() bool {
+	return true
+}
+
+func (d *fakeDescriptor) GetDeletionPolicy() v1alpha1.DeletionPolicy {
+	return d.deletionPolicy
+}
+
+func (d *fakeDescriptor) GetAdoptionPolicy() v1alpha1.AdoptionPolicy {
+	return d.adoptionPolicy
+}
+
+func (d *fakeDescriptor) IsAutoHeal() bool {
+	return d.autoHeal
+}
+
+func (d *fakeDescriptor) GetConflictPolicy() v1alpha1.ConflictPolicy {
+	return d.conflictPolicy
+}
+
+func (d *fakeDescriptor) GetIgnoreFields() [][]fieldpath.Segment {
+	return d.ignoreFields
+}
+
+func (d *fakeDescriptor) GetRetryPolicy() *v1alpha1.RetryPolicy {
+	return nil
+}
+
+func (d *fakeDescriptor) IsExternalRef() bool {
+	return false
+}
+
+func (d *fakeDescriptor) GetPatchType() v1alpha1.PatchType {
+	return ""
+}
+
+func (d *fakeDescriptor) GetTarget() *v1alpha1.Target {
+	return d.target
+}
+
+func (d *fakeDescriptor) GetReadinessTimeout() time.Duration {
+	return 0
+}
+
+func TestUpdateResource_Drift(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	configMap := func(value string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+			"data":       map[string]interface{}{"key": value},
+		}}
+	}
+	// applied returns the object as last applied by kro with the given value,
+	// then edited with the given one.
+	applied := func(value, edited string) *unstructured.Unstructured {
+		obj := configMap(value)
+		require.NoError(t, setDesiredHash(obj, nil))
+		obj.Object["data"] = map[string]interface{}{"key": edited}
+		return obj
+	}
+
+	tests := []struct {
+		name         string
+		autoHeal     bool
+		ignoreFields [][]fieldpath.Segment
+		observed     *unstructured.Unstructured
+		desired      string
+		wantState    string
+		wantValue    string
+		wantEvents   []string
+	}{
+		{
+			name:      "changed outside of kro with auto heal",
+			autoHeal:  true,
+			observed:  applied("a", "edited"),
+			desired:   "a",
+			wantState: "UPDATING",
+			wantValue: "a",
+			wantEvents: []string{
+				"Warning DriftDetected ConfigMap default/config of resource config was changed outside of kro, reverting the changes",
+				"Normal ResourceUpdated Updated ConfigMap default/config of resource config",
+			},
+		},
+		{
+			name:      "changed outside of kro without auto heal",
+			observed:  applied("a", "edited"),
+			desired:   "a",
+			wantState: "DRIFTED",
+			wantValue: "edited",
+			wantEvents: []string{
+				"Warning DriftDetected ConfigMap default/config of resource config was changed outside of kro, keeping the changes",
+			},
+		},
+		{
+			name:       "desired state changed without auto heal",
+			observed:   applied("a", "edited"),
+			desired:    "b",
+			wantState:  "UPDATING",
+			wantValue:  "b",
+			wantEvents: []string{"Normal ResourceUpdated Updated ConfigMap default/config of resource config"},
+		},
+		{
+			name:         "ignored field changed outside of kro with auto heal",
+			autoHeal:     true,
+			ignoreFields: [][]fieldpath.Segment{{fieldpath.NewNamedSegment("data"), fieldpath.NewNamedSegment("key")}},
+			observed:     applied("a", "edited"),
+			desired:      "b",
+			wantState:    "SYNCED",
+			wantValue:    "edited",
+		},
+		{
+			name:       "applied before hashing without auto heal",
+			observed:   configMap("a"),
+			desired:    "b",
+			wantState:  "UPDATING",
+			wantValue:  "b",
+			wantEvents: []string{"Normal ResourceUpdated Updated ConfigMap default/config of resource config"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, tt.observed)
+			// The object tracker can't apply unstructured objects, they're
+			// applied as updates.
+			client.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+				patch := action.(k8stesting.PatchAction)
+				obj := &unstructured.Unstructured{}
+				if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+					return true, nil, err
+				}
+				return true, obj, client.Tracker().Update(gvr, obj, patch.GetNamespace())
+			})
+			recorder := record.NewFakeRecorder(len(tt.wantEvents))
+			igr := &instanceGraphReconciler{
+				log:                         logr.Discard(),
+				runtime:                     &fakeRuntime{descriptor: &fakeDescriptor{autoHeal: tt.autoHeal, ignoreFields: tt.ignoreFields}},
+				instanceSubResourcesLabeler: metadata.GenericLabeler{},
+				recorder:                    recorder,
+			}
+			rc := client.Resource(gvr).Namespace("default")
+			state := &ResourceState{}
+
+			err := igr.updateResource(context.Background(), rc, configMap(tt.desired), tt.observed, "config", state)
+			if tt.wantState == "UPDATING" {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantState, state.State)
+			assert.Equal(t, tt.wantState == "DRIFTED", state.Drifted)
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			assert.Equal(t, tt.wantEvents, events)
+
+			obj, err := rc.Get(context.Background(), "config", metav1.GetOptions{})
+			require.NoError(t, err)
+			value, _, _ := unstructured.NestedString(obj.Object, "data", "key")
+			assert.Equal(t, tt.wantValue, value)
+		})
+	}
+}
+
+func TestDeleteResource_DeletionPolicy(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	labeler := metadata.GenericLabeler{metadata.InstanceLabel: "test"}
+
+	tests := []struct {
+		name       string
+		policy     v1alpha1.DeletionPolicy
+		wantState  string
+		wantExists bool
+		wantLabels map[string]string
+	}{
+		{
+			name:      "delete",
+			policy:    v1alpha1.DeletionPolicyDelete,
+			wantState: InstanceStateDeleting,
+		},
+		{
+			name:       "orphan",
+			policy:     v1alpha1.DeletionPolicyOrphan,
+			wantState:  "ORPHANED",
+			wantExists: true,
+			wantLabels: map[string]string{"app": "test"},
+		},
+		{
+			name:       "retain",
+			policy:     v1alpha1.DeletionPolicyRetain,
+			wantState:  "RETAINED",
+			wantExists: true,
+			wantLabels: map[string]string{"app": "test", metadata.InstanceLabel: "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      "config",
+					"namespace": "default",
+					"labels":    map[string]interface{}{"app": "test", metadata.InstanceLabel: "test"},
+				},
+			}}
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, obj)
+			igr := &instanceGraphReconciler{
+				log:                         logr.Discard(),
+				client:                      client,
+				runtime:                     &fakeRuntime{descriptor: &fakeDescriptor{deletionPolicy: tt.policy}, resource: obj},
+				instanceSubResourcesLabeler: labeler,
+				state:                       newInstanceState(),
+			}
+			igr.state.ResourceStates["config"] = &ResourceState{State: "PENDING_DELETION"}
+
+			require.NoError(t, igr.deleteResource(context.Background(), "config"))
+			assert.Equal(t, tt.wantState, igr.state.ResourceStates["config"].State)
+			assert.Equal(t, tt.wantState != InstanceStateDeleting, isReleased(igr.state.ResourceStates["config"]))
+
+			observed, err := client.Resource(gvr).Namespace("default").Get(context.Background(), "config", metav1.GetOptions{})
+			if !tt.wantExists {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLabels, observed.GetLabels())
+		})
+	}
+}
+
+func TestDeleteExcludedResource(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	tests := []struct {
+		name        string
+		instanceUID types.UID
+		wantExists  bool
+	}{
+		{
+			name:        "created by the instance",
+			instanceUID: "instance",
+		},
+		{
+			name:        "managed by another instance",
+			instanceUID: "other",
+			wantExists:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      "config",
+					"namespace": "default",
+					"labels":    map[string]interface{}{metadata.InstanceIDLabel: "instance"},
+				},
+			}}
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, obj)
+			igr := &instanceGraphReconciler{
+				log:    logr.Discard(),
+				client: client,
+				runtime: &fakeRuntime{
+					descriptor:  &fakeDescriptor{deletionPolicy: v1alpha1.DeletionPolicyDelete},
+					resource:    obj,
+					instanceUID: tt.instanceUID,
+				},
+				state: newInstanceState(),
+			}
+			igr.state.ResourceStates["config"] = &ResourceState{State: "SKIPPED"}
+
+			require.NoError(t, igr.deleteExcludedResource(context.Background(), "config"))
+			assert.Equal(t, "SKIPPED", igr.state.ResourceStates["config"].State)
+
+			_, err := client.Resource(gvr).Namespace("default").Get(context.Background(), "config", metav1.GetOptions{})
+			if tt.wantExists {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apierrors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestReconcileResource_Excluded(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	tests := []struct {
+		name       string
+		excluded   error
+		wantExists bool
+	}{
+		{
+			name:     "condition evaluated to false",
+			excluded: errors.New("Skipping resource creation due to condition false"),
+		},
+		{
+			// The dependents of a resource whose condition failed to evaluate
+			// get its expression error.
+			name: "dependency condition errored",
+			excluded: krocel.NewExpressionError("vpc", "includeWhen[0]", "vpc.status.ready",
+				errors.New("no such key: ready")),
+			wantExists: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      "config",
+					"namespace": "default",
+					"labels":    map[string]interface{}{metadata.InstanceIDLabel: "instance"},
+				},
+			}}
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, obj)
+			igr := &instanceGraphReconciler{
+				log:    logr.Discard(),
+				client: client,
+				runtime: &fakeRuntime{
+					descriptor:  &fakeDescriptor{deletionPolicy: v1alpha1.DeletionPolicyDelete},
+					resource:    obj,
+					instanceUID: "instance",
+					excluded:    tt.excluded,
+				},
+				state: newInstanceState(),
+			}
+			igr.state.ResourceStates["config"] = &ResourceState{State: "PENDING"}
+
+			require.NoError(t, igr.reconcileResource(context.Background(), "config"))
+			assert.Equal(t, "SKIPPED", igr.state.ResourceStates["config"].State)
+
+			_, err := client.Resource(gvr).Namespace("default").Get(context.Background(), "config", metav1.GetOptions{})
+			if tt.wantExists {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apierrors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestCheckAdoption(t *testing.T) {
+	object := func(instanceUID string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind("ConfigMap")
+		obj.SetName("config")
+		if instanceUID != "" {
+			obj.SetLabels(map[string]string{metadata.InstanceIDLabel: instanceUID})
+		}
+		return obj
+	}
+
+	tests := []struct {
+		name     string
+		policy   v1alpha1.AdoptionPolicy
+		observed *unstructured.Unstructured
+		wantErr  string
+	}{
+		{
+			name:     "managed by the instance",
+			policy:   v1alpha1.AdoptionPolicyFail,
+			observed: object("instance"),
+		},
+		{
+			name:     "unmanaged with adopt or fail",
+			policy:   v1alpha1.AdoptionPolicyAdoptOrFail,
+			observed: object(""),
+		},
+		{
+			name:     "managed by another instance with adopt or fail",
+			policy:   v1alpha1.AdoptionPolicyAdoptOrFail,
+			observed: object("other"),
+			wantErr:  "ConfigMap config is managed by another instance other",
+		},
+		{
+			name:     "managed by another instance with adopt",
+			policy:   v1alpha1.AdoptionPolicyAdopt,
+			observed: object("other"),
+		},
+		{
+			name:     "unmanaged with fail",
+			policy:   v1alpha1.AdoptionPolicyFail,
+			observed: object(""),
+			wantErr:  "ConfigMap config already exists and isn't managed by the instance",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			igr := &instanceGraphReconciler{
+				log: logr.Discard(),
+				runtime: &fakeRuntime{
+					descriptor:  &fakeDescriptor{adoptionPolicy: tt.policy},
+					instanceUID: "instance",
+				},
+			}
+			err := igr.checkAdoption("config", tt.observed)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestObserveResources(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+	}}
+
+	tests := []struct {
+		name      string
+		objects   []k8sruntime.Object
+		wantState string
+	}{
+		{
+			name:      "existing resource",
+			objects:   []k8sruntime.Object{configMap.DeepCopy()},
+			wantState: "SYNCED",
+		},
+		{
+			name:      "missing resource",
+			wantState: "PENDING",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, tt.objects...)
+			rt := &fakeRuntime{descriptor: &fakeDescriptor{}, resource: configMap}
+			igr := &instanceGraphReconciler{
+				log:     logr.Discard(),
+				client:  client,
+				runtime: rt,
+				state:   newInstanceState(),
+			}
+
+			require.NoError(t, igr.observeResources(context.Background()))
+			assert.Equal(t, tt.wantState, igr.state.ResourceStates["config"].State)
+			assert.Equal(t, tt.objects != nil, rt.observed != nil)
+			for _, action := range client.Actions() {
+				assert.Equal(t, "get", action.GetVerb())
+			}
+		})
+	}
+}
+
+func TestObserveResources_Target(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+	}}
+	target := &v1alpha1.Target{KubeconfigSecretRef: v1alpha1.KubeconfigSecretReference{Namespace: "clusters", Name: "spoke"}}
+
+	listKinds := map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}
+	local := fake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(), listKinds)
+	remote := fake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(), listKinds, configMap.DeepCopy())
+	rt := &fakeRuntime{descriptor: &fakeDescriptor{target: target}, resource: configMap}
+	igr := &instanceGraphReconciler{
+		log:           logr.Discard(),
+		client:        local,
+		targetClients: map[v1alpha1.KubeconfigSecretReference]dynamic.Interface{target.KubeconfigSecretRef: remote},
+		runtime:       rt,
+		state:         newInstanceState(),
+	}
+
+	// The resource is read from its target cluster.
+	require.NoError(t, igr.observeResources(context.Background()))
+	assert.Equal(t, "SYNCED", igr.state.ResourceStates["config"].State)
+	assert.Empty(t, local.Actions())
+	assert.NotEmpty(t, remote.Actions())
+	assert.Equal(t, []dynamiccontroller.ObjectIdentifiers{{
+		NamespacedKey: "default/config",
+		GVR:           gvr,
+		Cluster:       "clusters/spoke/kubeconfig",
+	}}, igr.references())
+}
+
+// applyReactor applies unstructured objects as creates or updates, which the
+// object tracker of the fake client can't apply.
+func applyReactor(client *fake.FakeDynamicClient) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		err := client.Tracker().Update(patch.GetResource(), obj, patch.GetNamespace())
+		if apierrors.IsNotFound(err) {
+			err = client.Tracker().Create(patch.GetResource(), obj, patch.GetNamespace())
+		}
+		return true, obj, err
+	}
+}
+
+func TestRollback(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	configMap := func(value string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+			"data":       map[string]interface{}{"key": value},
+		}}
+	}
+	client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), configMap("b"))
+	client.PrependReactor("patch", "*", applyReactor(client))
+	rt := &fakeRuntime{descriptor: &fakeDescriptor{}, resource: configMap("a"), instanceUID: "instance", generation: 1}
+	igr := &instanceGraphReconciler{
+		log:                         logr.Discard(),
+		client:                      client,
+		runtime:                     rt,
+		instanceSubResourcesLabeler: metadata.GenericLabeler{},
+		reconcileConfig:             ReconcileConfig{FieldManager: "kro"},
+		state:                       newInstanceState(),
+	}
+	igr.state.ResourceStates["config"] = &ResourceState{State: "SYNCED"}
+
+	rev, err := igr.getRevision(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, rev.Resources)
+
+	require.NoError(t, igr.saveRevision(context.Background(), igr.newRevision(1)))
+	rev, err = igr.getRevision(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rev.Generation)
+	require.Contains(t, rev.Resources, "config")
+	assert.Equal(t, gvr, rev.Resources["config"].GVR)
+
+	rt.generation = 2
+	err = igr.rollback(context.Background(), rev)
+	var noRequeue *requeue.NoRequeue
+	require.ErrorAs(t, err, &noRequeue)
+	assert.EqualError(t, err, "resources of generation 2 didn't become ready, rolled back to generation 1")
+	assert.Equal(t, InstanceStateRolledBack, igr.state.State)
+	assert.Equal(t, int64(1), igr.state.RolledBackTo)
+
+	obj, err := client.Resource(gvr).Namespace("default").Get(context.Background(), "config", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, _, _ := unstructured.NestedString(obj.Object, "data", "key")
+	assert.Equal(t, "a", value)
+}
+
+func TestPlanResources(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	configMap := func(value string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+			"data":       map[string]interface{}{"key": value},
+		}}
+	}
+	// applied is a ConfigMap as kro applied it.
+	applied := func(value string) *unstructured.Unstructured {
+		obj := configMap(value)
+		require.NoError(t, setDesiredHash(obj, nil))
+		return obj
+	}
+	// The dry-run returns the applied object without storing it.
+	dryRunReactor := func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		obj := &unstructured.Unstructured{}
+		err := obj.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch())
+		return true, obj, err
+	}
+
+	tests := []struct {
+		name     string
+		existing []k8sruntime.Object
+		want     []PlannedChange
+	}{
+		{
+			name: "missing resource",
+			want: []PlannedChange{{ID: "config", Kind: "ConfigMap", Name: "config", Namespace: "default", Action: PlanActionCreate}},
+		},
+		{
+			name:     "changed resource",
+			existing: []k8sruntime.Object{applied("b")},
+			want: []PlannedChange{{
+				ID: "config", Kind: "ConfigMap", Name: "config", Namespace: "default",
+				Action: PlanActionUpdate,
+				Fields: []string{"data.key"},
+			}},
+		},
+		{
+			name:     "unchanged resource",
+			existing: []k8sruntime.Object{applied("a")},
+			want:     []PlannedChange{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme(), tt.existing...)
+			client.PrependReactor("patch", "*", dryRunReactor)
+			rt := &fakeRuntime{descriptor: &fakeDescriptor{}, resource: configMap("a")}
+			igr := &instanceGraphReconciler{
+				log:                         logr.Discard(),
+				client:                      client,
+				runtime:                     rt,
+				instanceSubResourcesLabeler: metadata.GenericLabeler{},
+				reconcileConfig:             ReconcileConfig{FieldManager: "kro"},
+				state:                       newInstanceState(),
+			}
+
+			require.NoError(t, igr.planResources(context.Background()))
+			assert.Equal(t, tt.want, igr.state.Plan)
+			assert.Equal(t, "PLANNED", igr.state.ResourceStates["config"].State)
+
+			// Nothing is applied
+			obj, err := client.Resource(gvr).Namespace("default").Get(context.Background(), "config", metav1.GetOptions{})
+			if len(tt.existing) == 0 {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.existing[0], obj)
+		})
+	}
+}
+
+func TestReconcile_NamespaceRejected(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme())
+	igr := &instanceGraphReconciler{
+		log:               logr.Discard(),
+		client:            client,
+		runtime:           &fakeRuntime{descriptor: &fakeDescriptor{}},
+		namespaceRejected: true,
+	}
+
+	// The instance is rejected, and isn't requeued.
+	err := igr.reconcile(context.Background())
+	var noRequeue *requeue.NoRequeue
+	require.ErrorAs(t, err, &noRequeue)
+	assert.Equal(t, InstanceStateRejected, igr.state.State)
+	assert.Empty(t, igr.state.ResourceStates)
+
+	// Its resources are left alone, only its status is updated.
+	for _, action := range client.Actions() {
+		assert.Equal(t, "status", action.GetSubresource())
+	}
+
+	var rejected map[string]interface{}
+	for _, condition := range igr.prepareConditions(igr.state.ReconcileErr, 1) {
+		if condition := condition.(map[string]interface{}); condition["type"] == "Rejected" {
+			rejected = condition
+		}
+	}
+	require.NotNil(t, rejected)
+	assert.Equal(t, "True", rejected["status"])
+	assert.Equal(t, "NamespaceNotSelected", rejected["reason"])
+	assert.Equal(t, "Namespace default isn't selected by the namespaceSelector of the resource graph definition", rejected["message"])
+}
+
+func TestKstatusConditions(t *testing.T) {
+	tests := []struct {
+		name         string
+		state        string
+		reconcileErr error
+		lastAttempt  bool
+		timedOut     bool
+		// want maps the condition types to their status and reason.
+		want map[string][2]string
+	}{
+		{
+			name:  "active",
+			state: InstanceStateActive,
+			want:  map[string][2]string{"Ready": {"True", "ReconciliationSucceeded"}},
+		},
+		{
+			name:         "waiting for resources",
+			state:        InstanceStateInProgress,
+			reconcileErr: requeue.NeededAfter(errors.New("resource config not ready"), time.Second),
+			want: map[string][2]string{
+				"Ready":       {"False", "Progressing"},
+				"Reconciling": {"True", "Progressing"},
+			},
+		},
+		{
+			name:         "failed",
+			state:        InstanceStateError,
+			reconcileErr: errors.New("failed to apply resource config"),
+			want: map[string][2]string{
+				"Ready":       {"False", "ReconciliationFailed"},
+				"Reconciling": {"True", "ReconciliationFailed"},
+			},
+		},
+		{
+			name:         "failed without retries",
+			state:        InstanceStateError,
+			reconcileErr: requeue.None(errors.New("invalid resource config")),
+			want: map[string][2]string{
+				"Ready":   {"False", "ReconciliationFailed"},
+				"Stalled": {"True", "ReconciliationFailed"},
+			},
+		},
+		{
+			name:         "retries exhausted",
+			state:        InstanceStateError,
+			reconcileErr: errors.New("failed to apply resource config"),
+			lastAttempt:  true,
+			want: map[string][2]string{
+				"Ready":   {"False", "RetriesExhausted"},
+				"Stalled": {"True", "RetriesExhausted"},
+			},
+		},
+		{
+			name:         "readiness timeout",
+			state:        InstanceStateInProgress,
+			reconcileErr: requeue.NeededAfter(errors.New("resource config not ready"), time.Second),
+			timedOut:     true,
+			want: map[string][2]string{
+				"Ready":   {"False", "ReadinessTimeout"},
+				"Stalled": {"True", "ReadinessTimeout"},
+			},
+		},
+		{
+			name:         "deleting",
+			state:        InstanceStateDeleting,
+			reconcileErr: requeue.NeededAfter(errors.New("waiting for deletion"), time.Second),
+			want: map[string][2]string{
+				"Ready":       {"False", "Deleting"},
+				"Reconciling": {"True", "Deleting"},
+			},
+		},
+		{
+			name:  "paused",
+			state: InstanceStatePaused,
+			want:  map[string][2]string{"Ready": {"False", "ReconciliationPaused"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			igr := &instanceGraphReconciler{
+				runtime:     &fakeRuntime{descriptor: &fakeDescriptor{}},
+				lastAttempt: tt.lastAttempt,
+				state:       newInstanceState(),
+			}
+			igr.state.State = tt.state
+			igr.state.ResourceStates["config"] = &ResourceState{TimedOut: tt.timedOut}
+
+			got := map[string][2]string{}
+			for _, c := range igr.kstatusConditions(tt.reconcileErr, 1) {
+				condition := c.(map[string]interface{})
+				got[condition["type"].(string)] = [2]string{condition["status"].(string), condition["reason"].(string)}
+				assert.Equal(t, int64(1), condition["observedGeneration"])
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResourcesStatus(t *testing.T) {
+	config := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config"},
+	}}
+	previous := map[string]interface{}{
+		"resources": []interface{}{
+			map[string]interface{}{"id": "config", "lastAppliedGeneration": int64(2)},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		state *ResourceState
+		want  map[string]interface{}
+	}{
+		{
+			name:  "applied",
+			state: &ResourceState{State: "UPDATING", Ready: true, AppliedGeneration: 3},
+			want: map[string]interface{}{
+				"id":                    "config",
+				"apiVersion":            "v1",
+				"kind":                  "ConfigMap",
+				"name":                  "config",
+				"namespace":             "default",
+				"state":                 "UPDATING",
+				"ready":                 true,
+				"lastAppliedGeneration": int64(3),
+			},
+		},
+		{
+			name:  "not applied",
+			state: &ResourceState{State: "ERROR", Err: errors.New("forbidden")},
+			want: map[string]interface{}{
+				"id":                    "config",
+				"apiVersion":            "v1",
+				"kind":                  "ConfigMap",
+				"name":                  "config",
+				"namespace":             "default",
+				"state":                 "ERROR",
+				"ready":                 false,
+				"lastAppliedGeneration": int64(2),
+				"message":               "forbidden",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			igr := &instanceGraphReconciler{
+				log: logr.Discard(),
+				runtime: &fakeRuntime{
+					descriptor: &fakeDescriptor{},
+					resource:   config,
+					generation: 3,
+					status:     previous,
+				},
+				state: newInstanceState(),
+			}
+			igr.state.ResourceStates["config"] = tt.state
+
+			assert.Equal(t, []interface{}{tt.want}, igr.resourcesStatus())
+		})
+	}
+}
+
+func TestPatchInstanceStatus(t *testing.T) {
+	current := map[string]interface{}{
+		"state": "ACTIVE",
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "InstanceSynced", "status": "True", "observedGeneration": int64(2)},
+		},
+	}
+	client := fake.NewSimpleDynamicClient(k8sruntime.NewScheme())
+	igr := &instanceGraphReconciler{
+		log:     logr.Discard(),
+		client:  client,
+		runtime: &fakeRuntime{descriptor: &fakeDescriptor{}, status: current},
+	}
+
+	// The same status, with numbers decoded differently, isn't written.
+	unchanged := map[string]interface{}{
+		"state": "ACTIVE",
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "InstanceSynced", "status": "True", "observedGeneration": float64(2)},
+		},
+	}
+	require.NoError(t, igr.patchInstanceStatus(context.Background(), unchanged))
+	assert.Empty(t, client.Actions())
+
+	// A changed status replaces the status subresource as a whole.
+	changed := map[string]interface{}{"state": "IN_PROGRESS"}
+	_ = igr.patchInstanceStatus(context.Background(), changed)
+	require.Len(t, client.Actions(), 1)
+	patch, ok := client.Actions()[0].(k8stesting.PatchAction)
+	require.True(t, ok)
+	assert.Equal(t, "status", patch.GetSubresource())
+	assert.Equal(t, types.JSONPatchType, patch.GetPatchType())
+	assert.JSONEq(t, `[{"op":"add","path":"/status","value":{"state":"IN_PROGRESS"}}]`, string(patch.GetPatch()))
+}
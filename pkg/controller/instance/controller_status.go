@@ -14,15 +14,22 @@
 package instance
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
 	"github.com/kro-run/kro/pkg/requeue"
+	"github.com/kro-run/kro/pkg/runtime"
 )
 
 func createCondition(conditionType v1alpha1.ConditionType, status corev1.ConditionStatus, reason, message string, generation int64) map[string]interface{} {
@@ -42,9 +49,21 @@ func (igr *instanceGraphReconciler) prepareStatus() map[string]interface{} {
 	generation := igr.runtime.GetInstance().GetGeneration()
 
 	status["state"] = igr.state.State
+	status["observedGeneration"] = generation
 	status["conditions"] = igr.prepareConditions(igr.state.ReconcileErr, generation)
+	if igr.state.Plan != nil {
+		status["plan"] = planStatus(igr.state.Plan)
+	} else {
+		delete(status, "plan")
+	}
+	// The resources are kept as last reported if none was reconciled, e.g
+	// the instance was rejected
+	if len(igr.state.ResourceStates) > 0 {
+		status["resources"] = igr.resourcesStatus()
+	}
 
-	return status
+	// The status is readable by anyone who can read the instance.
+	return igr.redactor.Object(status)
 }
 
 // getResolvedStatus retrieves the current status while preserving non-condition fields.
@@ -91,24 +110,356 @@ func (igr *instanceGraphReconciler) prepareConditions(
 		))
 	}
 
+	// Add the conditions following the kstatus conventions
+	conditions = append(conditions, igr.kstatusConditions(reconcileErr, generation)...)
+
+	// Add the rejected condition, if the namespace of the instance isn't
+	// selected or the instance exceeds the quota
+	if igr.state.State == InstanceStateRejected {
+		reason, message := igr.rejection()
+		conditions = append(conditions, createCondition(
+			"Rejected",
+			corev1.ConditionTrue,
+			reason,
+			message,
+			generation,
+		))
+	}
+
+	// Add the paused condition, if the reconciliation is paused
+	if igr.state.State == InstanceStatePaused {
+		conditions = append(conditions, createCondition(
+			"Paused",
+			corev1.ConditionTrue,
+			"ReconciliationPaused",
+			fmt.Sprintf("Reconciliation is paused by the %s annotation", metadata.PausedAnnotation),
+			generation,
+		))
+	}
+
+	// Add the planned condition, if the instance only requests a plan
+	if igr.state.State == InstanceStatePlanned {
+		conditions = append(conditions, createCondition(
+			"Planned",
+			corev1.ConditionTrue,
+			"PlanRequested",
+			fmt.Sprintf("Changes to the resources are planned but not applied, as requested by the %s annotation", metadata.PlanAnnotation),
+			generation,
+		))
+	}
+
+	// Add the rolled back condition, if the current generation was rolled back
+	if igr.state.RolledBackTo != 0 {
+		conditions = append(conditions, createCondition(
+			"RolledBack",
+			corev1.ConditionTrue,
+			"ResourcesNotReady",
+			fmt.Sprintf("Resources were rolled back to generation %d", igr.state.RolledBackTo),
+			generation,
+		))
+	}
+
+	// Add the degraded condition, if resources have a readiness timeout
+	if degraded := igr.degradedCondition(generation); degraded != nil {
+		conditions = append(conditions, degraded)
+	}
+
+	// Add the drifted condition, if resources don't auto heal
+	if drifted := igr.driftedCondition(generation); drifted != nil {
+		conditions = append(conditions, drifted)
+	}
+
+	// Add the conditions declared by the resource graph definition
+	for _, condition := range igr.runtime.EvaluateConditions() {
+		conditions = append(conditions, createCondition(
+			v1alpha1.ConditionType(condition.Type),
+			corev1.ConditionStatus(condition.Status),
+			condition.Reason,
+			condition.Message,
+			generation,
+		))
+	}
+
+	igr.preserveTransitionTimes(conditions)
+	return conditions
+}
+
+// rejection returns the reason and the message of the rejection of the
+// instance.
+func (igr *instanceGraphReconciler) rejection() (string, string) {
+	if !igr.namespaceRejected && igr.quotaExceeded != "" {
+		return "QuotaExceeded", igr.quotaExceeded
+	}
+	return "NamespaceNotSelected", fmt.Sprintf("Namespace %s isn't selected by the namespaceSelector of the resource graph definition",
+		igr.runtime.GetInstance().GetNamespace())
+}
+
+// kstatusConditions returns the Ready condition of the instance, along with
+// the Reconciling or Stalled condition while one of them applies, following
+// the kstatus conventions, so that generic tools, e.g Flux and Argo CD health
+// checks or `kubectl wait --for=condition=Ready`, can tell whether the
+// instance is healthy. Reconciling and Stalled have an abnormal-true
+// polarity: they're left out rather than reported as False.
+func (igr *instanceGraphReconciler) kstatusConditions(reconcileErr error, generation int64) []interface{} {
+	var timedOut []string
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		if state := igr.state.ResourceStates[resourceID]; state != nil && state.TimedOut {
+			timedOut = append(timedOut, resourceID)
+		}
+	}
+
+	var progressing, terminal bool
+	switch reconcileErr.(type) {
+	case *requeue.RequeueNeeded, *requeue.RequeueNeededAfter:
+		progressing = true
+	case *requeue.NoRequeue:
+		terminal = true
+	}
+
+	// The instance is stalled when kro can't make progress without a change
+	// of the instance or of its resources, and reconciling while it waits
+	// for them or retries after a failure.
+	var reconciling, stalled map[string]interface{}
+	switch {
+	case igr.state.State == InstanceStateRejected:
+		reason, message := igr.rejection()
+		stalled = createCondition("Stalled", corev1.ConditionTrue, reason, message, generation)
+	case igr.state.RolledBackTo != 0:
+		stalled = createCondition("Stalled", corev1.ConditionTrue, "RolledBack",
+			fmt.Sprintf("Resources were rolled back to generation %d", igr.state.RolledBackTo), generation)
+	case len(timedOut) > 0:
+		stalled = createCondition("Stalled", corev1.ConditionTrue, "ReadinessTimeout",
+			fmt.Sprintf("Resources not ready within their readiness timeout: %s", strings.Join(timedOut, ", ")), generation)
+	case terminal:
+		stalled = createCondition("Stalled", corev1.ConditionTrue, "ReconciliationFailed", reconcileErr.Error(), generation)
+	case reconcileErr != nil && igr.lastAttempt:
+		stalled = createCondition("Stalled", corev1.ConditionTrue, "RetriesExhausted", reconcileErr.Error(), generation)
+	case igr.state.State == InstanceStateDeleting:
+		reconciling = createCondition("Reconciling", corev1.ConditionTrue, "Deleting", "Resources of the instance are being deleted", generation)
+	case progressing:
+		reconciling = createCondition("Reconciling", corev1.ConditionTrue, "Progressing", reconcileErr.Error(), generation)
+	case reconcileErr != nil:
+		reconciling = createCondition("Reconciling", corev1.ConditionTrue, "ReconciliationFailed", reconcileErr.Error(), generation)
+	case igr.state.State == InstanceStateInProgress:
+		reconciling = createCondition("Reconciling", corev1.ConditionTrue, "Progressing", "Instance is being reconciled", generation)
+	}
+
+	var ready map[string]interface{}
+	switch {
+	case stalled != nil:
+		ready = createCondition("Ready", corev1.ConditionFalse, stalled["reason"].(string), stalled["message"].(string), generation)
+	case reconciling != nil:
+		ready = createCondition("Ready", corev1.ConditionFalse, reconciling["reason"].(string), reconciling["message"].(string), generation)
+	case igr.state.State == InstanceStatePaused:
+		ready = createCondition("Ready", corev1.ConditionFalse, "ReconciliationPaused",
+			fmt.Sprintf("Reconciliation is paused by the %s annotation", metadata.PausedAnnotation), generation)
+	case igr.state.State == InstanceStatePlanned:
+		ready = createCondition("Ready", corev1.ConditionFalse, "PlanRequested",
+			fmt.Sprintf("Changes to the resources are planned but not applied, as requested by the %s annotation", metadata.PlanAnnotation), generation)
+	default:
+		ready = createCondition("Ready", corev1.ConditionTrue, "ReconciliationSucceeded", "Instance is ready", generation)
+	}
+
+	conditions := []interface{}{ready}
+	for _, condition := range []map[string]interface{}{reconciling, stalled} {
+		if condition != nil {
+			conditions = append(conditions, condition)
+		}
+	}
 	return conditions
 }
 
-// patchInstanceStatus updates the status subresource of the instance.
+// degradedCondition returns the Degraded condition of the instance, listing
+// the resources that didn't become ready within their readiness timeout. It's
+// only reported if resources of the instance have a readiness timeout.
+func (igr *instanceGraphReconciler) degradedCondition(generation int64) map[string]interface{} {
+	hasTimeout := false
+	var timedOut []string
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		if igr.runtime.ResourceDescriptor(resourceID).GetReadinessTimeout() > 0 {
+			hasTimeout = true
+		}
+		if state := igr.state.ResourceStates[resourceID]; state != nil && state.TimedOut {
+			timedOut = append(timedOut, resourceID)
+		}
+	}
+	if !hasTimeout {
+		return nil
+	}
+
+	if len(timedOut) == 0 {
+		return createCondition(
+			"Degraded",
+			corev1.ConditionFalse,
+			"ResourcesReady",
+			"No resource exceeded its readiness timeout",
+			generation,
+		)
+	}
+	return createCondition(
+		"Degraded",
+		corev1.ConditionTrue,
+		"ReadinessTimeout",
+		fmt.Sprintf("Resources not ready within their readiness timeout: %s", strings.Join(timedOut, ", ")),
+		generation,
+	)
+}
+
+// driftedCondition returns the Drifted condition of the instance, listing the
+// resources whose changes made outside of kro were kept. It's only reported if
+// resources of the instance don't auto heal.
+func (igr *instanceGraphReconciler) driftedCondition(generation int64) map[string]interface{} {
+	hasManualHeal := false
+	var drifted []string
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		if !igr.runtime.ResourceDescriptor(resourceID).IsAutoHeal() {
+			hasManualHeal = true
+		}
+		if state := igr.state.ResourceStates[resourceID]; state != nil && state.Drifted {
+			drifted = append(drifted, resourceID)
+		}
+	}
+	if !hasManualHeal {
+		return nil
+	}
+
+	if len(drifted) == 0 {
+		return createCondition(
+			"Drifted",
+			corev1.ConditionFalse,
+			"ResourcesInSync",
+			"No resource was changed outside of kro",
+			generation,
+		)
+	}
+	return createCondition(
+		"Drifted",
+		corev1.ConditionTrue,
+		"ChangedOutsideOfKro",
+		fmt.Sprintf("Resources changed outside of kro: %s", strings.Join(drifted, ", ")),
+		generation,
+	)
+}
+
+// resourcesStatus returns the resources managed by the instance, in
+// topological order, with their observed state. External references aren't
+// managed by the instance and aren't part of it. The generation last applied
+// to a resource is kept from the previous status until it's applied again.
+func (igr *instanceGraphReconciler) resourcesStatus() []interface{} {
+	existing, _, _ := unstructured.NestedSlice(igr.runtime.GetInstance().Object, "status", "resources")
+	lastApplied := map[interface{}]interface{}{}
+	for _, r := range existing {
+		if resource, ok := r.(map[string]interface{}); ok {
+			lastApplied[resource["id"]] = resource["lastAppliedGeneration"]
+		}
+	}
+
+	resources := []interface{}{}
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		resourceState := igr.state.ResourceStates[resourceID]
+		descriptor := igr.runtime.ResourceDescriptor(resourceID)
+		if resourceState == nil || descriptor.IsExternalRef() {
+			continue
+		}
+		entry := map[string]interface{}{
+			"id":    resourceID,
+			"state": resourceState.State,
+			"ready": resourceState.Ready,
+		}
+		if resource, state := igr.runtime.GetResource(resourceID); state == runtime.ResourceStateResolved && resource != nil {
+			entry["apiVersion"] = resource.GetAPIVersion()
+			entry["kind"] = resource.GetKind()
+			entry["name"] = resource.GetName()
+			if descriptor.IsNamespaced() {
+				entry["namespace"] = igr.getResourceNamespace(resourceID)
+			}
+		}
+		if resourceState.AppliedGeneration != 0 {
+			entry["lastAppliedGeneration"] = resourceState.AppliedGeneration
+		} else if generation, ok := lastApplied[resourceID]; ok && generation != nil {
+			entry["lastAppliedGeneration"] = generation
+		}
+		if resourceState.Err != nil {
+			entry["message"] = resourceState.Err.Error()
+		}
+		resources = append(resources, entry)
+	}
+	return resources
+}
+
+// preserveTransitionTimes keeps the last transition time of the conditions
+// whose status didn't change since the last reconciliation.
+func (igr *instanceGraphReconciler) preserveTransitionTimes(conditions []interface{}) {
+	existing, _, _ := unstructured.NestedSlice(igr.runtime.GetInstance().Object, "status", "conditions")
+	previous := map[interface{}]map[string]interface{}{}
+	for _, c := range existing {
+		if condition, ok := c.(map[string]interface{}); ok {
+			previous[condition["type"]] = condition
+		}
+	}
+
+	for _, c := range conditions {
+		condition := c.(map[string]interface{})
+		old, ok := previous[condition["type"]]
+		if ok && old["status"] == condition["status"] && old["lastTransitionTime"] != nil {
+			condition["lastTransitionTime"] = old["lastTransitionTime"]
+		}
+	}
+}
+
+// patchInstanceStatus replaces the status subresource of the instance with
+// the given status, which gathers the changes of a whole reconciliation. The
+// status isn't written if it's semantically equal to the current one, e.g
+// when a resync finds nothing to change. It's patched rather than updated,
+// so that writes to the rest of the instance since it was read don't fail it
+// with a conflict.
 func (igr *instanceGraphReconciler) patchInstanceStatus(ctx context.Context, status map[string]interface{}) error {
-	instance := igr.runtime.GetInstance().DeepCopy()
-	instance.Object["status"] = status
+	instance := igr.runtime.GetInstance()
+	current, _ := instance.Object["status"].(map[string]interface{})
+	unchanged, err := statusEqual(current, status)
+	if err != nil {
+		return fmt.Errorf("failed to compare instance status: %w", err)
+	}
+	if unchanged {
+		statusUpdates.WithLabelValues(igr.rgdName, "unchanged").Inc()
+		return nil
+	}
 
-	_, err := igr.client.Resource(igr.gvr).
+	// Adding the status replaces it as a whole, removing the fields that
+	// aren't set anymore, unlike a merge patch.
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "add", "path": "/status", "value": status},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal instance status: %w", err)
+	}
+	_, err = igr.client.Resource(igr.gvr).
 		Namespace(instance.GetNamespace()).
-		UpdateStatus(ctx, instance, metav1.UpdateOptions{})
-
+		Patch(ctx, instance.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{}, "status")
 	if err != nil {
-		return fmt.Errorf("failed to update instance status: %w", err)
+		return fmt.Errorf("failed to patch instance status: %w", err)
 	}
+	statusUpdates.WithLabelValues(igr.rgdName, "patched").Inc()
 	return nil
 }
 
+// statusEqual reports whether the given statuses have the same JSON
+// representation, regardless of the Go types of their numbers.
+func statusEqual(a, b map[string]interface{}) (bool, error) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, nil
+	}
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aJSON, bJSON), nil
+}
+
 // updateInstanceState updates the instance state based on reconciliation results
 func (igr *instanceGraphReconciler) updateInstanceState() {
 	switch igr.state.ReconcileErr.(type) {
@@ -118,7 +469,8 @@ func (igr *instanceGraphReconciler) updateInstanceState() {
 	default:
 		if igr.state.ReconcileErr != nil {
 			igr.state.State = InstanceStateError
-		} else if igr.state.State != InstanceStateDeleting {
+		} else if igr.state.State != InstanceStateDeleting && igr.state.State != InstanceStatePaused &&
+			igr.state.State != InstanceStatePlanned {
 			igr.state.State = InstanceStateActive
 		}
 	}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kro-run/kro/pkg/redact"
+)
+
+// The reasons of the events recorded on the instances, telling what kro did
+// to their resources.
+const (
+	eventReasonResourceCreated  = "ResourceCreated"
+	eventReasonResourceUpdated  = "ResourceUpdated"
+	eventReasonResourceDeleted  = "ResourceDeleted"
+	eventReasonReadinessTimeout = "ReadinessTimeout"
+	eventReasonEvaluationFailed = "EvaluationFailed"
+	eventReasonDriftDetected    = "DriftDetected"
+	eventReasonValuesNotFound   = "ValuesNotFound"
+	eventReasonExpired          = "Expired"
+	eventReasonResourcePatched  = "ResourcePatched"
+	eventReasonPatchReverted    = "PatchReverted"
+)
+
+// recordEvent records an event on the given instance, with the values of its
+// sensitive fields redacted from the message. It does nothing without a
+// recorder.
+func recordEvent(
+	recorder record.EventRecorder,
+	redactor *redact.Redactor,
+	instance *unstructured.Unstructured,
+	eventType, reason, messageFmt string,
+	args ...interface{},
+) {
+	if recorder == nil || instance == nil {
+		return
+	}
+	recorder.Event(instance, eventType, reason, redactor.String(fmt.Sprintf(messageFmt, args...)))
+}
+
+// recordEvent records an event on the instance.
+func (igr *instanceGraphReconciler) recordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	recordEvent(igr.recorder, igr.redactor, igr.runtime.GetInstance(), eventType, reason, messageFmt, args...)
+}
+
+// describeObject returns the kind and the namespaced name of the given object,
+// as shown in the events.
+func describeObject(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
+
+// describeResource returns the kind and the namespaced name of the given
+// object of a resource, whose namespace defaults to the one it's applied to.
+func (igr *instanceGraphReconciler) describeResource(resourceID string, obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" && igr.runtime.ResourceDescriptor(resourceID).IsNamespaced() {
+		return fmt.Sprintf("%s %s/%s", obj.GetKind(), igr.getResourceNamespace(resourceID), obj.GetName())
+	}
+	return describeObject(obj)
+}
+
+// recordReadinessEvaluationFailure records the failure to evaluate the
+// readiness of the given resource, if any.
+func (igr *instanceGraphReconciler) recordReadinessEvaluationFailure(resourceID string, err error) {
+	if err == nil {
+		return
+	}
+	igr.recordEvent(corev1.EventTypeWarning, eventReasonEvaluationFailed,
+		"Failed to evaluate the readiness of resource %s: %v", resourceID, err)
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	smdfieldpath "sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
+)
+
+// withIgnoredFields returns a copy of the desired state of an existing
+// resource without the ignored fields other field managers than the given one
+// manage, so that applying it leaves them to these: kro neither owns them,
+// reverts their changes nor reports them as drift. The ignored fields no other
+// field manager manages yet, and the ones in lists, whose items are only
+// identified by their index, keep their observed values, so that they aren't
+// removed. The ones the observed resource doesn't set are removed.
+func withIgnoredFields(
+	desired, observed *unstructured.Unstructured,
+	paths [][]fieldpath.Segment,
+	fieldManager string,
+) (*unstructured.Unstructured, error) {
+	if len(paths) == 0 {
+		return desired, nil
+	}
+	foreign, err := foreignFields(observed, fieldManager)
+	if err != nil {
+		return nil, err
+	}
+	result := desired.DeepCopy()
+	for _, path := range paths {
+		value, ok := lookupField(observed.Object, path)
+		if !ok || managedBy(foreign, path) {
+			removeField(result.Object, path)
+			continue
+		}
+		setField(result.Object, path, runtime.DeepCopyJSONValue(value))
+	}
+	return result, nil
+}
+
+// managedBy returns whether the given fields include the field at the given
+// path, or any of its fields. Paths going through lists never match.
+func managedBy(fields *smdfieldpath.Set, path []fieldpath.Segment) bool {
+	managed := make(smdfieldpath.Path, 0, len(path))
+	for _, segment := range path {
+		if segment.Index >= 0 {
+			return false
+		}
+		name := segment.Name
+		managed = append(managed, smdfieldpath.PathElement{FieldName: &name})
+	}
+	if fields.Has(managed) {
+		return true
+	}
+	for _, element := range managed {
+		fields = fields.WithPrefix(element)
+	}
+	return !fields.Empty()
+}
+
+// withoutIgnoredFields returns a copy of the given object without the ignored
+// fields.
+func withoutIgnoredFields(obj *unstructured.Unstructured, paths [][]fieldpath.Segment) *unstructured.Unstructured {
+	if len(paths) == 0 {
+		return obj
+	}
+	result := obj.DeepCopy()
+	for _, path := range paths {
+		removeField(result.Object, path)
+	}
+	return result
+}
+
+// lookupField returns the value of the field at the given path of the given
+// object, and whether it's set.
+func lookupField(obj map[string]interface{}, path []fieldpath.Segment) (interface{}, bool) {
+	var current interface{} = obj
+	for _, segment := range path {
+		if segment.Index >= 0 {
+			list, ok := current.([]interface{})
+			if !ok || segment.Index >= len(list) {
+				return nil, false
+			}
+			current = list[segment.Index]
+			continue
+		}
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = fields[segment.Name]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setField sets the field at the given path of the given object, creating
+// the maps leading to it. Items of lists are only set if they exist.
+func setField(obj map[string]interface{}, path []fieldpath.Segment, value interface{}) {
+	var current interface{} = obj
+	for i, segment := range path {
+		last := i == len(path)-1
+		if segment.Index >= 0 {
+			list, ok := current.([]interface{})
+			if !ok || segment.Index >= len(list) {
+				return
+			}
+			if last {
+				list[segment.Index] = value
+				return
+			}
+			current = list[segment.Index]
+			continue
+		}
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if last {
+			fields[segment.Name] = value
+			return
+		}
+		next, ok := fields[segment.Name]
+		if !ok && path[i+1].Index < 0 {
+			next = map[string]interface{}{}
+			fields[segment.Name] = next
+		}
+		current = next
+	}
+}
+
+// removeField removes the field at the given path of the given object. Items
+// of lists are left in place, as removing them would shift the others.
+func removeField(obj map[string]interface{}, path []fieldpath.Segment) {
+	parent, ok := lookupField(obj, path[:len(path)-1])
+	if !ok {
+		return
+	}
+	if fields, ok := parent.(map[string]interface{}); ok && path[len(path)-1].Index < 0 {
+		delete(fields, path[len(path)-1].Name)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
+)
+
+func TestWithIgnoredFields(t *testing.T) {
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "app"},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"paused":   false,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:v2"},
+					},
+				},
+			},
+		},
+	}}
+	observed := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":        "app",
+			"annotations": map[string]interface{}{"example.com/id": "i-123"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(5),
+			"paused":   true,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:v1"},
+					},
+				},
+			},
+		},
+	}}
+	observed.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:   "kro",
+			Operation: metav1.ManagedFieldsOperationApply,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:paused":{},"f:replicas":{},"f:template":{}}}`)},
+		},
+		{
+			Manager:   "kube-controller-manager",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		},
+		{
+			Manager:   "cloud-controller",
+			Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:example.com/id":{}}}}`)},
+		},
+	})
+	parse := func(paths ...string) [][]fieldpath.Segment {
+		var parsed [][]fieldpath.Segment
+		for _, path := range paths {
+			segments, err := fieldpath.Parse(path)
+			require.NoError(t, err)
+			parsed = append(parsed, segments)
+		}
+		return parsed
+	}
+	paths := parse(
+		"spec.replicas",
+		"spec.paused",
+		`metadata.annotations["example.com/id"]`,
+		"spec.template.spec.containers[0].image",
+		"spec.strategy",
+	)
+
+	ignored, err := withIgnoredFields(desired, observed, paths, "kro")
+	require.NoError(t, err)
+	// The fields managed by other field managers are left to them.
+	_, found, _ := unstructured.NestedFieldNoCopy(ignored.Object, "spec", "replicas")
+	assert.False(t, found)
+	assert.Empty(t, ignored.GetAnnotations())
+	// The fields only kro manages, and the ones in lists, keep their
+	// observed values.
+	assert.Equal(t, true, ignored.Object["spec"].(map[string]interface{})["paused"])
+	containers, _, _ := unstructured.NestedFieldNoCopy(ignored.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, "app:v1", containers.([]interface{})[0].(map[string]interface{})["image"])
+	// The fields the observed resource doesn't set are removed.
+	_, found, _ = unstructured.NestedFieldNoCopy(ignored.Object, "spec", "strategy")
+	assert.False(t, found)
+	// The desired state is left untouched.
+	assert.Equal(t, int64(2), desired.Object["spec"].(map[string]interface{})["replicas"])
+
+	stripped := withoutIgnoredFields(desired, parse("spec"))
+	assert.NotContains(t, stripped.Object, "spec")
+	assert.Contains(t, desired.Object, "spec")
+}
@@ -19,6 +19,10 @@ const (
 	InstanceStateActive     = "ACTIVE"
 	InstanceStateDeleting   = "DELETING"
 	InstanceStateError      = "ERROR"
+	InstanceStatePaused     = "PAUSED"
+	InstanceStateRolledBack = "ROLLED_BACK"
+	InstanceStatePlanned    = "PLANNED"
+	InstanceStateRejected   = "REJECTED"
 )
 
 // newInstanceState creates a new InstanceState with initialized fields
@@ -36,6 +40,18 @@ type ResourceState struct {
 	State string
 	// Err captures any error associated with the current state
 	Err error
+	// TimedOut reports that the resource didn't become ready within its
+	// readiness timeout.
+	TimedOut bool
+	// Drifted reports that the resource was changed outside of kro, and that
+	// these changes were kept since it doesn't auto heal.
+	Drifted bool
+	// Ready reports that the readiness conditions of the resource are met.
+	Ready bool
+	// AppliedGeneration is the generation of the instance whose desired
+	// state of the resource was found applied, or was applied, by the
+	// reconciliation. 0 if it wasn't.
+	AppliedGeneration int64
 }
 
 // InstanceState tracks the overall state of resources being managed
@@ -46,4 +62,13 @@ type InstanceState struct {
 	ResourceStates map[string]*ResourceState
 	// Any error encountered during reconciliation
 	ReconcileErr error
+	// RolledBackTo is the generation of the instance its resources were
+	// rolled back to, if the current generation didn't become ready.
+	RolledBackTo int64
+	// Plan are the changes a reconciliation would make to the resources, if
+	// the instance only requests a preview of them.
+	Plan []PlannedChange
+	// Status is the status the instance was reconciled to, once the
+	// reconciliation is done.
+	Status map[string]interface{}
 }
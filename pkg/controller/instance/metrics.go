@@ -14,8 +14,12 @@
 package instance
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kro-run/kro/pkg/requeue"
 )
 
 const (
@@ -27,6 +31,28 @@ const (
 	MetricImpersonationErrors = "controller_impersonation_errors_total"
 	// MetricImpersonationDuration tracks the duration of impersonation operations
 	MetricImpersonationDuration = "controller_impersonation_duration_seconds"
+	// MetricInstanceReconcileTotal is the total number of reconciliations of
+	// the instances by outcome
+	MetricInstanceReconcileTotal = "instance_reconcile_total"
+	// MetricInstanceReconcileDuration tracks the duration of the
+	// reconciliations of the instances
+	MetricInstanceReconcileDuration = "instance_reconcile_duration_seconds"
+	// MetricResourceApplyErrors is the total number of errors encountered
+	// while applying the resources of the instances
+	MetricResourceApplyErrors = "instance_resource_apply_errors_total"
+	// MetricCELEvaluationDuration tracks the time spent evaluating the
+	// expressions of an instance during a reconciliation
+	MetricCELEvaluationDuration = "instance_cel_evaluation_duration_seconds"
+	// MetricStatusUpdates is the total number of writes of the status of the
+	// instances, by whether the status was patched or left unchanged
+	MetricStatusUpdates = "instance_status_updates_total"
+)
+
+const (
+	// The outcomes of the reconciliations of the instances.
+	outcomeSuccess = "success"
+	outcomeRequeue = "requeue"
+	outcomeError   = "error"
 )
 
 var (
@@ -54,16 +80,90 @@ var (
 		},
 		[]string{"namespace", "service_account"},
 	)
+
+	instanceReconcileTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: MetricInstanceReconcileTotal,
+			Help: "Total number of reconciliations of the instances by resource graph definition and outcome",
+		},
+		[]string{"rgd", "outcome"},
+	)
+
+	instanceReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    MetricInstanceReconcileDuration,
+			Help:    "Duration of the reconciliations of the instances by resource graph definition",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"rgd"},
+	)
+
+	resourceApplyErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: MetricResourceApplyErrors,
+			Help: "Total number of errors applying the resources of the instances by resource graph definition and resource",
+		},
+		[]string{"rgd", "resource"},
+	)
+
+	celEvaluationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    MetricCELEvaluationDuration,
+			Help:    "Time spent evaluating the expressions of an instance per reconciliation by resource graph definition",
+			Buckets: []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1},
+		},
+		[]string{"rgd"},
+	)
+
+	statusUpdates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: MetricStatusUpdates,
+			Help: "Total number of writes of the status of the instances by resource graph definition and result",
+		},
+		[]string{"rgd", "result"},
+	)
 )
 
 func recordImpersonateError(namespace, sa string, category errorCategory) {
 	impersonationErrors.WithLabelValues(namespace, sa, string(category)).Inc()
 }
 
+// recordReconcile records a reconciliation of an instance of the given
+// resource graph definition, which started at the given time and returned the
+// given error.
+func recordReconcile(rgdName string, start time.Time, err error) {
+	instanceReconcileDuration.WithLabelValues(rgdName).Observe(time.Since(start).Seconds())
+	instanceReconcileTotal.WithLabelValues(rgdName, reconcileOutcome(err)).Inc()
+}
+
+// reconcileOutcome returns the outcome of a reconciliation returning the given
+// error: the instances waiting for their resources are requeued, while the
+// ones only resynced later succeeded.
+func reconcileOutcome(err error) string {
+	switch typedErr := err.(type) {
+	case nil:
+		return outcomeSuccess
+	case *requeue.RequeueNeededAfter:
+		if typedErr.Unwrap() == nil {
+			return outcomeSuccess
+		}
+		return outcomeRequeue
+	case *requeue.RequeueNeeded:
+		return outcomeRequeue
+	default:
+		return outcomeError
+	}
+}
+
 func init() {
 	metrics.Registry.MustRegister(
 		impersonationTotal,
 		impersonationErrors,
 		impersonationDuration,
+		instanceReconcileTotal,
+		instanceReconcileDuration,
+		resourceApplyErrors,
+		celEvaluationDuration,
+		statusUpdates,
 	)
 }
@@ -0,0 +1,49 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kro-run/kro/pkg/requeue"
+)
+
+func TestReconcileOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "success", err: nil, want: outcomeSuccess},
+		{name: "resynced after success", err: requeue.NeededAfter(nil, time.Hour), want: outcomeSuccess},
+		{name: "waiting for resources", err: requeue.NeededAfter(errors.New("not ready"), time.Second), want: outcomeRequeue},
+		{name: "requeued", err: requeue.Needed(errors.New("not ready")), want: outcomeRequeue},
+		{name: "failed", err: errors.New("failed"), want: outcomeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reconcileOutcome(tt.err))
+		})
+	}
+
+	// Successful reconciliations resynced later are counted as successes.
+	recordReconcile("outcome-test", time.Now(), requeue.NeededAfter(nil, time.Hour))
+	assert.Equal(t, float64(1), testutil.ToFloat64(instanceReconcileTotal.WithLabelValues("outcome-test", outcomeSuccess)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(instanceReconcileTotal.WithLabelValues("outcome-test", outcomeRequeue)))
+}
@@ -0,0 +1,252 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// revertAnnotation returns the annotation holding, on the objects patched by
+// the instance, the JSON merge patch restoring the values they had before.
+func (igr *instanceGraphReconciler) revertAnnotation() string {
+	return metadata.PatchRevertAnnotationPrefix + string(igr.runtime.GetInstance().GetUID())
+}
+
+// applyPatch patches the existing object of a patch resource. The patch is
+// first applied in dry run, to know the fields it changes: their original
+// values are kept in the revert annotation of the object, which is updated
+// along with the patched fields. The values kept by the previous patches of
+// the instance are preserved, so that the object is restored to its state
+// before the first one.
+func (igr *instanceGraphReconciler) applyPatch(
+	ctx context.Context,
+	resourceID string,
+	resource *unstructured.Unstructured,
+	resourceState *ResourceState,
+) error {
+	log := igr.log.WithValues("resourceID", resourceID)
+
+	rc := igr.getResourceClient(resourceID)
+	current, err := rc.Get(ctx, resource.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			resourceState.State = "WAITING_FOR_EXTERNAL_REF"
+			resourceState.Err = fmt.Errorf("patched object %s not found", resource.GetName())
+			return igr.delayedResourceRequeue(resourceID, resourceState.Err)
+		}
+		resourceState.State = "ERROR"
+		resourceState.Err = fmt.Errorf("failed to get patched object: %w", err)
+		return resourceState.Err
+	}
+
+	patchType, data, err := graph.PatchData(resource, igr.runtime.ResourceDescriptor(resourceID).GetPatchType())
+	if err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = fmt.Errorf("failed to encode patch: %w", err)
+		return resourceState.Err
+	}
+	dryRunOptions := metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}, FieldManager: igr.reconcileConfig.FieldManager}
+	patched, err := rc.Patch(ctx, current.GetName(), patchType, data, dryRunOptions)
+	if apierrors.IsUnsupportedMediaType(err) && patchType == types.StrategicMergePatchType {
+		// Custom resources don't support strategic merge patches, their
+		// lists are replaced.
+		patchType = types.MergePatchType
+		patched, err = rc.Patch(ctx, current.GetName(), patchType, data, dryRunOptions)
+	}
+	if err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = fmt.Errorf("failed to patch object: %w", err)
+		return resourceState.Err
+	}
+
+	revertAnnotation := igr.revertAnnotation()
+	revert, changed, err := revertPatch(current, patched, revertAnnotation)
+	if err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = err
+		return resourceState.Err
+	}
+	observed := current
+	if changed {
+		annotations := patched.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[revertAnnotation] = revert
+		patched.SetAnnotations(annotations)
+		// The update fails if the object changed since it was read, it's then
+		// patched again.
+		patched.SetResourceVersion(current.GetResourceVersion())
+		observed, err = rc.Update(ctx, patched, metav1.UpdateOptions{FieldManager: igr.reconcileConfig.FieldManager})
+		if err != nil {
+			resourceState.State = "ERROR"
+			resourceState.Err = fmt.Errorf("failed to patch object: %w", err)
+			return resourceState.Err
+		}
+		igr.recordEvent(corev1.EventTypeNormal, eventReasonResourcePatched, "Patched %s of resource %s",
+			igr.describeResource(resourceID, observed), resourceID)
+	}
+
+	igr.runtime.SetResource(resourceID, observed)
+
+	if ready, reason, err := igr.runtime.IsResourceReady(resourceID); err != nil || !ready {
+		log.V(1).Info("Patched object not ready", "reason", reason, "error", err)
+		igr.recordReadinessEvaluationFailure(resourceID, err)
+		resourceState.State = "WAITING_FOR_READINESS"
+		resourceState.Err = fmt.Errorf("patched object not ready: %s: %w", reason, err)
+		return igr.delayedResourceRequeue(resourceID, resourceState.Err)
+	}
+
+	resourceState.State = "SYNCED"
+	resourceState.Ready = true
+	return nil
+}
+
+// revertPatch returns the JSON merge patch restoring the fields of the given
+// current object changed by the given patched object, merged with the revert
+// patch already held by the given annotation of the current object, and
+// whether the patch changes the object at all.
+func revertPatch(current, patched *unstructured.Unstructured, annotation string) (string, bool, error) {
+	currentFields, patchedFields := patchableFields(current, annotation), patchableFields(patched, annotation)
+	if reflect.DeepEqual(currentFields, patchedFields) {
+		return "", false, nil
+	}
+	revert := mergePatch(patchedFields, currentFields)
+	if previous, ok := current.GetAnnotations()[annotation]; ok {
+		var previousRevert map[string]interface{}
+		if err := json.Unmarshal([]byte(previous), &previousRevert); err != nil {
+			return "", false, fmt.Errorf("invalid %s annotation: %w", annotation, err)
+		}
+		// The previous revert patch holds the values before the first patch.
+		revert = mergePatches(revert, previousRevert)
+	}
+	data, err := json.Marshal(revert)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to encode revert patch: %w", err)
+	}
+	return string(data), true, nil
+}
+
+// patchableFields returns the fields of the given object that a patch can
+// change, without the metadata updated by the API server nor the given
+// revert annotation.
+func patchableFields(obj *unstructured.Unstructured, annotation string) map[string]interface{} {
+	fields := obj.DeepCopy().Object
+	for _, field := range []string{"resourceVersion", "generation", "managedFields"} {
+		unstructured.RemoveNestedField(fields, "metadata", field)
+	}
+	unstructured.RemoveNestedField(fields, "metadata", "annotations", annotation)
+	if annotations, _, _ := unstructured.NestedMap(fields, "metadata", "annotations"); len(annotations) == 0 {
+		unstructured.RemoveNestedField(fields, "metadata", "annotations")
+	}
+	return fields
+}
+
+// mergePatch returns the JSON merge patch, see RFC 7386, turning the object
+// from into the object to. Lists are replaced as a whole.
+func mergePatch(from, to map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for field, value := range to {
+		fromValue, ok := from[field]
+		if ok && reflect.DeepEqual(fromValue, value) {
+			continue
+		}
+		fromMap, fromIsMap := fromValue.(map[string]interface{})
+		toMap, toIsMap := value.(map[string]interface{})
+		if fromIsMap && toIsMap {
+			patch[field] = mergePatch(fromMap, toMap)
+			continue
+		}
+		patch[field] = value
+	}
+	for field := range from {
+		if _, ok := to[field]; !ok {
+			patch[field] = nil
+		}
+	}
+	return patch
+}
+
+// mergePatches merges the JSON merge patch override into the JSON merge patch
+// base: the fields set by both are those of override.
+func mergePatches(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for field, value := range base {
+		merged[field] = value
+	}
+	for field, value := range override {
+		baseMap, baseIsMap := merged[field].(map[string]interface{})
+		overrideMap, overrideIsMap := value.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[field] = mergePatches(baseMap, overrideMap)
+			continue
+		}
+		merged[field] = value
+	}
+	return merged
+}
+
+// revertPatchedObject restores the fields of the object of a patch resource
+// to their values before the instance patched it, and removes its revert
+// annotation. Objects that weren't patched by the instance are left as is.
+func (igr *instanceGraphReconciler) revertPatchedObject(ctx context.Context, resourceID string) error {
+	resource, _ := igr.runtime.GetResource(resourceID)
+	resourceState := igr.state.ResourceStates[resourceID]
+	revertAnnotation := igr.revertAnnotation()
+
+	revert := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(resource.GetAnnotations()[revertAnnotation]), &revert); err != nil {
+		resourceState.State = InstanceStateError
+		resourceState.Err = fmt.Errorf("invalid %s annotation: %w", revertAnnotation, err)
+		return resourceState.Err
+	}
+	revert = mergePatches(revert, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{revertAnnotation: nil},
+		},
+	})
+	data, err := json.Marshal(revert)
+	if err != nil {
+		resourceState.State = InstanceStateError
+		resourceState.Err = fmt.Errorf("failed to encode revert patch: %w", err)
+		return resourceState.Err
+	}
+
+	igr.log.V(1).Info("Reverting patch", "resourceID", resourceID)
+	_, err = igr.getResourceClient(resourceID).Patch(ctx, resource.GetName(), types.MergePatchType, data,
+		metav1.PatchOptions{FieldManager: igr.reconcileConfig.FieldManager})
+	if err != nil && !apierrors.IsNotFound(err) {
+		resourceState.State = InstanceStateError
+		resourceState.Err = fmt.Errorf("failed to revert patch: %w", err)
+		return resourceState.Err
+	}
+	if err == nil {
+		igr.recordEvent(corev1.EventTypeNormal, eventReasonPatchReverted, "Reverted the patch of %s of resource %s",
+			igr.describeResource(resourceID, resource), resourceID)
+	}
+	resourceState.State = "REVERTED"
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRevertPatch(t *testing.T) {
+	const annotation = "kro.run/revert-uid"
+	object := func(resourceVersion string, annotations map[string]interface{}, replicas int64) *unstructured.Unstructured {
+		metadata := map[string]interface{}{"name": "app", "resourceVersion": resourceVersion}
+		if annotations != nil {
+			metadata["annotations"] = annotations
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   metadata,
+			"spec":       map[string]interface{}{"replicas": replicas},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		current     *unstructured.Unstructured
+		patched     *unstructured.Unstructured
+		wantRevert  string
+		wantChanged bool
+	}{
+		{
+			name:    "already patched",
+			current: object("1", map[string]interface{}{"team": "a", annotation: `{}`}, 2),
+			patched: object("2", map[string]interface{}{"team": "a", annotation: `{}`}, 2),
+		},
+		{
+			name:        "first patch",
+			current:     object("1", nil, 2),
+			patched:     object("2", map[string]interface{}{"team": "a"}, 3),
+			wantRevert:  `{"metadata":{"annotations":null},"spec":{"replicas":2}}`,
+			wantChanged: true,
+		},
+		{
+			name: "patch changed since the first one",
+			current: object("1", map[string]interface{}{
+				"team":     "a",
+				annotation: `{"metadata":{"annotations":{"team":null}},"spec":{"replicas":2}}`,
+			}, 3),
+			patched: object("2", map[string]interface{}{
+				"team":     "b",
+				"owner":    "b",
+				annotation: `{"metadata":{"annotations":{"team":null}},"spec":{"replicas":2}}`,
+			}, 4),
+			wantRevert:  `{"metadata":{"annotations":{"owner":null,"team":null}},"spec":{"replicas":2}}`,
+			wantChanged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revert, changed, err := revertPatch(tt.current, tt.patched, annotation)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantChanged, changed)
+			if tt.wantChanged {
+				assert.JSONEq(t, tt.wantRevert, revert)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"golang.org/x/exp/maps"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/controller/instance/delta"
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// Actions of the planned changes.
+const (
+	PlanActionCreate  = "Create"
+	PlanActionUpdate  = "Update"
+	PlanActionDelete  = "Delete"
+	PlanActionUnknown = "Unknown"
+)
+
+// PlannedChange is a change a reconciliation of the instance would make to
+// one of its resources.
+type PlannedChange struct {
+	// ID is the id of the resource.
+	ID        string
+	Kind      string
+	Name      string
+	Namespace string
+	// Action is the change made to the resource.
+	Action string
+	// Fields are the paths of the fields an update changes.
+	Fields []string
+	// Message explains why the change can't be planned, if it can't.
+	Message string
+}
+
+// planResources previews the changes a reconciliation would make to the
+// resources of the instance, following the dependency graph, without
+// applying any of them. The resources are rendered and applied with a server
+// side dry-run, and the result of the dry-run is used to render the resources
+// depending on them. Resources depending on values that aren't available
+// until the resources they refer to are applied can't be planned.
+func (igr *instanceGraphReconciler) planResources(ctx context.Context) error {
+	igr.state.Plan = []PlannedChange{}
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		resourceState := &ResourceState{State: "PENDING"}
+		igr.state.ResourceStates[resourceID] = resourceState
+
+		if _, err := igr.runtime.Synchronize(); err != nil {
+			return fmt.Errorf("failed to synchronize resources: %w", err)
+		}
+		if want, err := igr.runtime.WantToCreateResource(resourceID); err != nil || !want {
+			resourceState.State = "SKIPPED"
+			igr.runtime.IgnoreResource(resourceID, err)
+			continue
+		}
+		resource, state := igr.runtime.GetResource(resourceID)
+		if state != runtime.ResourceStateResolved {
+			igr.state.Plan = append(igr.state.Plan, PlannedChange{
+				ID:      resourceID,
+				Action:  PlanActionUnknown,
+				Message: "depends on values that aren't available until other resources are applied",
+			})
+			continue
+		}
+
+		change, err := igr.planResource(ctx, resourceID, resource)
+		if err != nil {
+			resourceState.State = "ERROR"
+			resourceState.Err = err
+			return err
+		}
+		resourceState.State = "PLANNED"
+		if change != nil {
+			igr.state.Plan = append(igr.state.Plan, *change)
+		}
+	}
+
+	stale, err := igr.staleExpandedResources(ctx)
+	if err != nil {
+		return err
+	}
+	ids := maps.Keys(stale)
+	slices.Sort(ids)
+	for _, id := range ids {
+		// Retained and orphaned resources aren't deleted.
+		switch igr.forEachResources[id].GetDeletionPolicy() {
+		case v1alpha1.DeletionPolicyRetain, v1alpha1.DeletionPolicyOrphan:
+			continue
+		}
+		for _, obj := range stale[id] {
+			igr.state.Plan = append(igr.state.Plan, PlannedChange{
+				ID:        id,
+				Kind:      obj.GetKind(),
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+				Action:    PlanActionDelete,
+			})
+		}
+	}
+	return nil
+}
+
+// planResource returns the change a reconciliation would make to the given
+// resource, or nil if it wouldn't change it. External references are only
+// read.
+func (igr *instanceGraphReconciler) planResource(
+	ctx context.Context,
+	resourceID string,
+	resource *unstructured.Unstructured,
+) (*PlannedChange, error) {
+	descriptor := igr.runtime.ResourceDescriptor(resourceID)
+	rc := igr.getResourceClient(resourceID)
+	change := &PlannedChange{
+		ID:        resourceID,
+		Kind:      resource.GetKind(),
+		Name:      resource.GetName(),
+		Namespace: resource.GetNamespace(),
+	}
+	if descriptor.IsNamespaced() {
+		change.Namespace = igr.getResourceNamespace(resourceID)
+	}
+
+	observed, err := rc.Get(ctx, resource.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get resource %s: %w", resourceID, err)
+	}
+	exists := err == nil
+	if descriptor.IsExternalRef() {
+		if exists {
+			igr.runtime.SetResource(resourceID, observed)
+		}
+		return nil, nil
+	}
+
+	desired := resource.DeepCopy()
+	change.Action = PlanActionCreate
+	if exists {
+		change.Action = PlanActionUpdate
+		if err := igr.checkAdoption(resourceID, observed); err != nil {
+			change.Message = err.Error()
+			return change, nil
+		}
+		if desired, err = withIgnoredFields(desired, observed, descriptor.GetIgnoreFields(), igr.reconcileConfig.FieldManager); err != nil {
+			return nil, err
+		}
+		if descriptor.GetConflictPolicy() == v1alpha1.ConflictPolicyIgnoreFields {
+			if desired, err = withoutForeignFields(desired, observed, igr.reconcileConfig.FieldManager); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := setDesiredHash(desired, descriptor.GetIgnoreFields()); err != nil {
+		return nil, err
+	}
+	igr.instanceSubResourcesLabeler.ApplyLabels(desired)
+	igr.setNodeIDLabel(resourceID, desired)
+
+	policy := descriptor.GetConflictPolicy()
+	applied, err := rc.Apply(ctx, desired.GetName(), desired, metav1.ApplyOptions{
+		FieldManager: igr.reconcileConfig.FieldManager,
+		Force:        policy != v1alpha1.ConflictPolicyFail && policy != v1alpha1.ConflictPolicyIgnoreFields,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		change.Message = fmt.Sprintf("dry-run failed: %v", err)
+		return change, nil
+	}
+	// The resources depending on the resource are rendered with the result
+	// of the dry-run.
+	igr.runtime.SetResource(resourceID, applied)
+	if !exists {
+		return change, nil
+	}
+
+	differences, err := delta.Compare(applied, observed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare resource %s: %w", resourceID, err)
+	}
+	hashPath := "metadata.annotations." + metadata.DesiredHashAnnotation
+	for _, difference := range differences {
+		if difference.Path != hashPath {
+			change.Fields = append(change.Fields, difference.Path)
+		}
+	}
+	if len(change.Fields) == 0 {
+		return nil, nil
+	}
+	slices.Sort(change.Fields)
+	return change, nil
+}
+
+// planStatus returns the planned changes as they're reported in the status of
+// the instance.
+func planStatus(plan []PlannedChange) []interface{} {
+	changes := make([]interface{}, 0, len(plan))
+	for _, change := range plan {
+		entry := map[string]interface{}{
+			"id":     change.ID,
+			"action": change.Action,
+		}
+		for key, value := range map[string]string{
+			"kind":      change.Kind,
+			"name":      change.Name,
+			"namespace": change.Namespace,
+			"message":   change.Message,
+		} {
+			if value != "" {
+				entry[key] = value
+			}
+		}
+		if len(change.Fields) > 0 {
+			fields := make([]interface{}, len(change.Fields))
+			for i, field := range change.Fields {
+				fields[i] = field
+			}
+			entry["fields"] = fields
+		}
+		changes = append(changes, entry)
+	}
+	return changes
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// quotaRecheckInterval is the interval at which the instances exceeding the
+// quota of their resource graph definition are checked again, in case older
+// instances were deleted.
+const quotaRecheckInterval = 30 * time.Second
+
+// InstanceLister lists the instances of a GVR, as cached by the controller.
+type InstanceLister interface {
+	Instances(gvr schema.GroupVersionResource) []*unstructured.Unstructured
+}
+
+// quotaExceeded returns why the given instance, with the resources of the
+// given runtime, exceeds the quota of the resource graph definition, or an
+// empty string if it doesn't. The instances are admitted in the order they
+// were created, the instances being deleted don't count.
+func (c *Controller) quotaExceeded(instance *unstructured.Unstructured, rt runtime.Interface) string {
+	quota := c.rgd.Quota
+	if quota == nil {
+		return ""
+	}
+
+	if quota.MaxResourcesPerInstance > 0 {
+		resources := 0
+		for _, resourceID := range rt.TopologicalOrder() {
+			if !rt.ResourceDescriptor(resourceID).IsExternalRef() {
+				resources++
+			}
+		}
+		if resources > int(quota.MaxResourcesPerInstance) {
+			return fmt.Sprintf("Instance has %d resources, more than the %d resources allowed per instance",
+				resources, quota.MaxResourcesPerInstance)
+		}
+	}
+
+	if (quota.MaxInstances == 0 && quota.MaxInstancesPerNamespace == 0) || c.instanceLister == nil {
+		return ""
+	}
+	older, olderInNamespace := 0, 0
+	for _, other := range c.instanceLister.Instances(c.gvr) {
+		if other.GetUID() == instance.GetUID() || !other.GetDeletionTimestamp().IsZero() ||
+			!createdBefore(other, instance) {
+			continue
+		}
+		older++
+		if other.GetNamespace() == instance.GetNamespace() {
+			olderInNamespace++
+		}
+	}
+	if quota.MaxInstances > 0 && older >= int(quota.MaxInstances) {
+		return fmt.Sprintf("The resource graph definition allows %d instances, which already exist", quota.MaxInstances)
+	}
+	if quota.MaxInstancesPerNamespace > 0 && olderInNamespace >= int(quota.MaxInstancesPerNamespace) {
+		return fmt.Sprintf("The resource graph definition allows %d instances per namespace, which already exist in namespace %s",
+			quota.MaxInstancesPerNamespace, instance.GetNamespace())
+	}
+	return ""
+}
+
+// createdBefore reports whether the instance a was created before the
+// instance b. The instances created in the same second are ordered by
+// namespace and name.
+func createdBefore(a, b *unstructured.Unstructured) bool {
+	aCreated, bCreated := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !aCreated.Equal(&bCreated) {
+		return aCreated.Before(&bCreated)
+	}
+	if a.GetNamespace() != b.GetNamespace() {
+		return a.GetNamespace() < b.GetNamespace()
+	}
+	return a.GetName() < b.GetName()
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph"
+)
+
+// collectionRuntime is a runtime of an instance with a collection of three
+// resources.
+type collectionRuntime struct{ *fakeRuntime }
+
+func (r collectionRuntime) TopologicalOrder() []string {
+	return []string{"config-0", "config-1", "config-2"}
+}
+
+type fakeInstanceLister []*unstructured.Unstructured
+
+func (l fakeInstanceLister) Instances(schema.GroupVersionResource) []*unstructured.Unstructured {
+	return l
+}
+
+func TestController_QuotaExceeded(t *testing.T) {
+	created := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	instance := func(namespace, name string, age time.Duration) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		u.SetUID(types.UID(namespace + "/" + name))
+		u.SetCreationTimestamp(metav1.NewTime(created.Add(-age)))
+		return u
+	}
+	deleting := instance("team-a", "deleting", 3*time.Hour)
+	deleting.SetDeletionTimestamp(&metav1.Time{Time: created})
+	instances := fakeInstanceLister{
+		instance("team-a", "first", 2*time.Hour),
+		instance("team-b", "second", time.Hour),
+		deleting,
+		instance("team-a", "third", 0),
+	}
+	rt := collectionRuntime{&fakeRuntime{descriptor: &fakeDescriptor{}}}
+
+	tests := []struct {
+		name     string
+		quota    *v1alpha1.QuotaPolicy
+		instance *unstructured.Unstructured
+		want     string
+	}{
+		{
+			name:     "no quota",
+			instance: instances[3],
+		},
+		{
+			name:     "within quota",
+			quota:    &v1alpha1.QuotaPolicy{MaxInstances: 2, MaxInstancesPerNamespace: 1},
+			instance: instances[1],
+		},
+		{
+			name:     "too many instances",
+			quota:    &v1alpha1.QuotaPolicy{MaxInstances: 2},
+			instance: instances[3],
+			want:     "The resource graph definition allows 2 instances, which already exist",
+		},
+		{
+			name:     "too many instances in namespace",
+			quota:    &v1alpha1.QuotaPolicy{MaxInstancesPerNamespace: 1},
+			instance: instances[3],
+			want:     "The resource graph definition allows 1 instances per namespace, which already exist in namespace team-a",
+		},
+		{
+			name:     "resources within quota",
+			quota:    &v1alpha1.QuotaPolicy{MaxResourcesPerInstance: 3},
+			instance: instances[3],
+		},
+		{
+			name:     "too many resources",
+			quota:    &v1alpha1.QuotaPolicy{MaxResourcesPerInstance: 2},
+			instance: instances[0],
+			want:     "Instance has 3 resources, more than the 2 resources allowed per instance",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{rgd: &graph.Graph{Quota: tt.quota}, instanceLister: instances}
+			assert.Equal(t, tt.want, c.quotaExceeded(tt.instance, rt))
+		})
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kro-run/kro/pkg/requeue"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+const (
+	// revisionSecretPrefix prefixes the name of the Secret recording the
+	// revision of an instance, followed by the instance UID.
+	revisionSecretPrefix = "kro-revision-"
+	// revisionKey is the key of the revision in the data of its Secret.
+	revisionKey = "revision"
+)
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// revision is the last set of resources of an instance that all became ready,
+// as they were rendered and applied. It is recorded in a Secret owned by the
+// instance, since the rendered resources can hold sensitive values.
+type revision struct {
+	// Generation is the generation of the instance the resources were
+	// rendered for.
+	Generation int64 `json:"generation"`
+	// Resources are the rendered resources, keyed by id.
+	Resources map[string]revisionResource `json:"resources,omitempty"`
+	// PendingGeneration is the generation of the instance being rolled out
+	// since PendingSince, whose resources didn't all become ready yet.
+	PendingGeneration int64        `json:"pendingGeneration,omitempty"`
+	PendingSince      *metav1.Time `json:"pendingSince,omitempty"`
+	// RolledBackGeneration is the last generation of the instance whose
+	// resources didn't become ready within their readiness timeout, and were
+	// rolled back.
+	RolledBackGeneration int64 `json:"rolledBackGeneration,omitempty"`
+}
+
+// revisionResource is a rendered resource of a revision.
+type revisionResource struct {
+	GVR    schema.GroupVersionResource `json:"gvr"`
+	Object map[string]interface{}      `json:"object"`
+}
+
+// reconcileWithRollback reconciles the resources of the instance, and rolls
+// them back to the last revision of the instance if the resources of its
+// current generation don't become ready within their readiness timeout. A
+// generation that was rolled back isn't applied again: its resources are kept
+// at the revision until the instance changes.
+func (igr *instanceGraphReconciler) reconcileWithRollback(ctx context.Context) error {
+	rev, err := igr.getRevision(ctx)
+	if err != nil {
+		return err
+	}
+	generation := igr.runtime.GetInstance().GetGeneration()
+
+	if rev.RolledBackGeneration == generation {
+		return igr.rollback(ctx, rev)
+	}
+
+	canRollback := len(rev.Resources) > 0 && rev.Generation != generation
+	if canRollback && rev.PendingGeneration != generation {
+		now := metav1.Now()
+		rev.PendingGeneration, rev.PendingSince = generation, &now
+		if err := igr.saveRevision(ctx, rev); err != nil {
+			return err
+		}
+	}
+	if canRollback {
+		igr.rolloutStart = rev.PendingSince.Time
+	}
+
+	err = igr.reconcileResources(ctx)
+	if err == nil {
+		err = igr.pruneExpandedResources(ctx)
+	}
+
+	if igr.timedOut() {
+		if !canRollback {
+			return err
+		}
+		igr.log.Info("Rolling back resources", "generation", generation, "revision", rev.Generation, "error", err)
+		rev.RolledBackGeneration = generation
+		rev.PendingGeneration, rev.PendingSince = 0, nil
+		if err := igr.saveRevision(ctx, rev); err != nil {
+			return err
+		}
+		return igr.rollback(ctx, rev)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Record the ready resources, once per generation.
+	if rev.Generation == generation && rev.PendingGeneration == 0 && len(rev.Resources) > 0 {
+		return nil
+	}
+	return igr.saveRevision(ctx, igr.newRevision(generation))
+}
+
+// timedOut returns true if resources of the instance didn't become ready
+// within their readiness timeout.
+func (igr *instanceGraphReconciler) timedOut() bool {
+	for _, resourceState := range igr.state.ResourceStates {
+		if resourceState.TimedOut {
+			return true
+		}
+	}
+	return false
+}
+
+// newRevision returns a revision of the resources of the instance, as they
+// were applied for the given generation. External references and skipped
+// resources aren't part of it.
+func (igr *instanceGraphReconciler) newRevision(generation int64) *revision {
+	rev := &revision{
+		Generation: generation,
+		Resources:  make(map[string]revisionResource),
+	}
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		descriptor := igr.runtime.ResourceDescriptor(resourceID)
+		if descriptor.IsExternalRef() {
+			continue
+		}
+		if resourceState := igr.state.ResourceStates[resourceID]; resourceState == nil || resourceState.State == "SKIPPED" {
+			continue
+		}
+		resource, state := igr.runtime.GetResource(resourceID)
+		if state != runtime.ResourceStateResolved {
+			continue
+		}
+		rev.Resources[resourceID] = revisionResource{
+			GVR:    descriptor.GetGroupVersionResource(),
+			Object: resource.DeepCopy().Object,
+		}
+	}
+	return rev
+}
+
+// rollback applies the resources of the given revision, taking over the
+// fields managed by other field managers, and reports the instance as rolled
+// back. The instance isn't requeued: it's reconciled again when it changes.
+func (igr *instanceGraphReconciler) rollback(ctx context.Context, rev *revision) error {
+	igr.state.State = InstanceStateRolledBack
+	igr.state.RolledBackTo = rev.Generation
+
+	for resourceID, resource := range rev.Resources {
+		obj := &unstructured.Unstructured{Object: resource.Object}
+		client := igr.targetClient(igr.runtime.ResourceDescriptor(resourceID))
+		var rc dynamic.ResourceInterface = client.Resource(resource.GVR)
+		if namespace := obj.GetNamespace(); namespace != "" {
+			rc = client.Resource(resource.GVR).Namespace(namespace)
+		}
+		_, err := rc.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: igr.reconcileConfig.FieldManager,
+			Force:        true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to roll back resource %s: %w", resourceID, err)
+		}
+		if resourceState := igr.state.ResourceStates[resourceID]; resourceState != nil {
+			resourceState.AppliedGeneration = rev.Generation
+		}
+	}
+
+	return requeue.None(fmt.Errorf("resources of generation %d didn't become ready, rolled back to generation %d",
+		igr.runtime.GetInstance().GetGeneration(), rev.Generation))
+}
+
+// getRevision returns the revision recorded for the instance, or an empty
+// revision if none was recorded yet.
+func (igr *instanceGraphReconciler) getRevision(ctx context.Context) (*revision, error) {
+	instance := igr.runtime.GetInstance()
+	obj, err := igr.client.Resource(secretsGVR).Namespace(instance.GetNamespace()).
+		Get(ctx, revisionSecretPrefix+string(instance.GetUID()), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return &revision{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, secret); err != nil {
+		return nil, fmt.Errorf("failed to decode revision: %w", err)
+	}
+	rev := &revision{}
+	if err := json.Unmarshal(secret.Data[revisionKey], rev); err != nil {
+		return nil, fmt.Errorf("failed to decode revision: %w", err)
+	}
+	return rev, nil
+}
+
+// saveRevision records the given revision in the Secret of the instance. The
+// Secret is owned by the instance, so that it's garbage collected along with
+// it.
+func (igr *instanceGraphReconciler) saveRevision(ctx context.Context, rev *revision) error {
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision: %w", err)
+	}
+
+	instance := igr.runtime.GetInstance()
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revisionSecretPrefix + string(instance.GetUID()),
+			Namespace: instance.GetNamespace(),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: instance.GetAPIVersion(),
+				Kind:       instance.GetKind(),
+				Name:       instance.GetName(),
+				UID:        instance.GetUID(),
+			}},
+		},
+		Data: map[string][]byte{revisionKey: data},
+	}
+	content, err := k8sruntime.DefaultUnstructuredConverter.ToUnstructured(secret)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision: %w", err)
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	igr.instanceSubResourcesLabeler.ApplyLabels(obj)
+
+	_, err = igr.client.Resource(secretsGVR).Namespace(instance.GetNamespace()).
+		Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: igr.reconcileConfig.FieldManager, Force: true})
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// lockedRuntime serializes the calls to a runtime shared by the workers
+// reconciling the resources of an instance concurrently.
+//
+// The runtime resolves the resources and the instance in place while
+// synchronizing, so the objects are copied in and out of it: a worker never
+// holds an object another worker's synchronization could write to.
+type lockedRuntime struct {
+	mu      sync.Mutex
+	runtime runtime.Interface
+}
+
+var _ runtime.Interface = &lockedRuntime{}
+
+func (r *lockedRuntime) Synchronize() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runtime.Synchronize()
+}
+
+func (r *lockedRuntime) TopologicalOrder() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runtime.TopologicalOrder()
+}
+
+func (r *lockedRuntime) ResourceDescriptor(resourceID string) runtime.ResourceDescriptor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runtime.ResourceDescriptor(resourceID)
+}
+
+func (r *lockedRuntime) GetResource(resourceID string) (*unstructured.Unstructured, runtime.ResourceState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resource, state := r.runtime.GetResource(resourceID)
+	if resource != nil {
+		resource = resource.DeepCopy()
+	}
+	return resource, state
+}
+
+func (r *lockedRuntime) SetResource(resourceID string, obj *unstructured.Unstructured) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runtime.SetResource(resourceID, obj.DeepCopy())
+}
+
+func (r *lockedRuntime) GetInstance() *unstructured.Unstructured {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runtime.GetInstance().DeepCopy()
+}
+
+func (r *lockedRuntime) SetInstance(obj *unstructured.Unstructured) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runtime.SetInstance(obj.DeepCopy())
+}
+
+func (r *lockedRuntime) IsResourceReady(resourceID string) (bool, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runtime.IsResourceReady(resourceID)
+}
+
+func (r *lockedRuntime) WantToCreateResource(resourceID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runtime.WantToCreateResource(resourceID)
+}
+
+func (r *lockedRuntime) IgnoreResource(resourceID string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runtime.IgnoreResource(resourceID, err)
+}
+
+func (r *lockedRuntime) EvaluateConditions() []runtime.ConditionStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runtime.EvaluateConditions()
+}
+
+func (r *lockedRuntime) CheckHooks(resourceID string, phase runtime.HookPhase) (bool, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runtime.CheckHooks(resourceID, phase)
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/redact"
+)
+
+// instanceTTLs returns the times after which the given instance is deleted
+// once ready or failed, set by its annotations or else by the resource graph
+// definition. 0 keeps the instance.
+func (c *Controller) instanceTTLs(instance *unstructured.Unstructured) (time.Duration, time.Duration, error) {
+	afterReady, set, err := metadata.GetTTL(instance, metadata.TTLAfterReadyAnnotation)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !set {
+		afterReady = c.rgd.TTLAfterReady
+	}
+	afterFailure, set, err := metadata.GetTTL(instance, metadata.TTLAfterFailureAnnotation)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !set {
+		afterFailure = c.rgd.TTLAfterFailure
+	}
+	return afterReady, afterFailure, nil
+}
+
+// expiration returns the time at which an instance with the given status
+// expires, according to the given TTLs, and whether it expires at all. An
+// instance is failed since its reconciliation failed with an error, or since
+// its resources were rolled back or didn't become ready within their readiness
+// timeout. It's ready since it was reconciled successfully, unless it failed.
+// Instances that aren't reconciled, e.g paused, don't expire.
+func expiration(status map[string]interface{}, afterReady, afterFailure time.Duration) (time.Time, bool) {
+	conditions := map[string]map[string]interface{}{}
+	rawConditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	for _, c := range rawConditions {
+		if condition, ok := c.(map[string]interface{}); ok {
+			conditions[fmt.Sprint(condition["type"])] = condition
+		}
+	}
+	since := func(conditionType string, conditionStatus corev1.ConditionStatus) (time.Time, bool) {
+		condition, ok := conditions[conditionType]
+		if !ok || condition["status"] != string(conditionStatus) {
+			return time.Time{}, false
+		}
+		transition, err := time.Parse(time.RFC3339, fmt.Sprint(condition["lastTransitionTime"]))
+		return transition, err == nil
+	}
+
+	state, _, _ := unstructured.NestedString(status, "state")
+	switch state {
+	case InstanceStatePaused, InstanceStatePlanned, InstanceStateRejected:
+		// The instance isn't reconciled, it doesn't expire.
+		return time.Time{}, false
+	}
+	var failedSince time.Time
+	for _, failure := range []struct {
+		condition string
+		status    corev1.ConditionStatus
+		applies   bool
+	}{
+		{condition: "InstanceSynced", status: corev1.ConditionFalse, applies: state == InstanceStateError},
+		{condition: "RolledBack", status: corev1.ConditionTrue, applies: true},
+		{condition: "Degraded", status: corev1.ConditionTrue, applies: true},
+	} {
+		if !failure.applies {
+			continue
+		}
+		if transition, ok := since(failure.condition, failure.status); ok &&
+			(failedSince.IsZero() || transition.Before(failedSince)) {
+			failedSince = transition
+		}
+	}
+	if !failedSince.IsZero() {
+		return failedSince.Add(afterFailure), afterFailure > 0
+	}
+
+	if state != InstanceStateActive {
+		return time.Time{}, false
+	}
+	readySince, ok := since("InstanceSynced", corev1.ConditionTrue)
+	return readySince.Add(afterReady), ok && afterReady > 0
+}
+
+// reconcileTTL deletes the given instance, and so its resources, once it
+// expired according to the given status it was reconciled to. Otherwise, it
+// returns the delay after which it expires, or 0 if it doesn't expire.
+func (c *Controller) reconcileTTL(
+	ctx context.Context,
+	redactor *redact.Redactor,
+	instance *unstructured.Unstructured,
+	status map[string]interface{},
+) (time.Duration, error) {
+	if status == nil || !instance.GetDeletionTimestamp().IsZero() {
+		return 0, nil
+	}
+	afterReady, afterFailure, err := c.instanceTTLs(instance)
+	if err != nil {
+		return 0, err
+	}
+	expiresAt, ok := expiration(status, afterReady, afterFailure)
+	if !ok {
+		return 0, nil
+	}
+	if remaining := time.Until(expiresAt); remaining > 0 {
+		return remaining, nil
+	}
+
+	recordEvent(c.recorder, redactor, instance, corev1.EventTypeNormal, eventReasonExpired,
+		"Deleting the instance, its TTL expired at %s", expiresAt.Format(time.RFC3339))
+	// The instance is deleted with the identity of kro, which owns it, its
+	// resources are then deleted by its reconciliation.
+	err = c.clientSet.Dynamic().Resource(c.gvr).Namespace(instance.GetNamespace()).
+		Delete(ctx, instance.GetName(), metav1.DeleteOptions{
+			Preconditions: metav1.NewUIDPreconditions(string(instance.GetUID())),
+		})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return 0, fmt.Errorf("failed to delete expired instance: %w", err)
+	}
+	return 0, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiration(t *testing.T) {
+	readyAt := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	failedAt := readyAt.Add(time.Hour)
+	condition := func(conditionType, status string, transition time.Time) interface{} {
+		return map[string]interface{}{
+			"type":               conditionType,
+			"status":             status,
+			"lastTransitionTime": transition.Format(time.RFC3339),
+		}
+	}
+	status := func(state string, conditions ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"state": state, "conditions": conditions}
+	}
+
+	tests := []struct {
+		name         string
+		status       map[string]interface{}
+		afterReady   time.Duration
+		afterFailure time.Duration
+		want         time.Time
+		wantExpires  bool
+	}{
+		{
+			name:        "ready",
+			status:      status(InstanceStateActive, condition("InstanceSynced", "True", readyAt)),
+			afterReady:  time.Hour,
+			want:        readyAt.Add(time.Hour),
+			wantExpires: true,
+		},
+		{
+			name:         "ready without ttl after ready",
+			status:       status(InstanceStateActive, condition("InstanceSynced", "True", readyAt)),
+			afterFailure: time.Hour,
+		},
+		{
+			name:         "in progress",
+			status:       status(InstanceStateInProgress, condition("InstanceSynced", "False", readyAt)),
+			afterReady:   time.Hour,
+			afterFailure: time.Hour,
+		},
+		{
+			name:         "error",
+			status:       status(InstanceStateError, condition("InstanceSynced", "False", failedAt)),
+			afterReady:   time.Hour,
+			afterFailure: 2 * time.Hour,
+			want:         failedAt.Add(2 * time.Hour),
+			wantExpires:  true,
+		},
+		{
+			name: "degraded",
+			status: status(InstanceStateActive,
+				condition("InstanceSynced", "True", readyAt),
+				condition("Degraded", "True", failedAt),
+			),
+			afterReady:   time.Hour,
+			afterFailure: 2 * time.Hour,
+			want:         failedAt.Add(2 * time.Hour),
+			wantExpires:  true,
+		},
+		{
+			name: "degraded without ttl after failure",
+			status: status(InstanceStateActive,
+				condition("InstanceSynced", "True", readyAt),
+				condition("Degraded", "True", failedAt),
+			),
+			afterReady: time.Hour,
+		},
+		{
+			name:         "paused",
+			status:       status(InstanceStatePaused, condition("Degraded", "True", failedAt)),
+			afterReady:   time.Hour,
+			afterFailure: time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, expires := expiration(tt.status, tt.afterReady, tt.afterFailure)
+			assert.Equal(t, tt.wantExpires, expires)
+			if tt.wantExpires {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
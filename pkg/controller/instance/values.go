@@ -0,0 +1,88 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kro-run/kro/pkg/dynamiccontroller"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/requeue"
+)
+
+// loadValues loads the values of the instance from the ConfigMaps and
+// Secrets of the resource graph definition, along with the values loaded
+// from Secrets, to be redacted. It returns the references to the ConfigMaps
+// and Secrets even when they can't be loaded, so that the instance is
+// reconciled again once they're created or changed.
+func (c *Controller) loadValues(
+	ctx context.Context,
+	client dynamic.Interface,
+	instance *unstructured.Unstructured,
+) (map[string]string, []string, []dynamiccontroller.ObjectIdentifiers, error) {
+	if len(c.rgd.ValuesFrom) == 0 {
+		return nil, nil, nil, nil
+	}
+	sources, err := c.rgd.ValuesObjects(instance)
+	if err != nil {
+		return nil, nil, nil, requeue.None(err)
+	}
+
+	references := make([]dynamiccontroller.ObjectIdentifiers, 0, len(sources))
+	objects := make([]*unstructured.Unstructured, 0, len(sources))
+	for _, source := range sources {
+		references = append(references, dynamiccontroller.ObjectIdentifiers{
+			NamespacedKey: source.Namespace + "/" + source.Name,
+			GVR:           source.GVR,
+		})
+		object, err := client.Resource(source.GVR).Namespace(source.Namespace).Get(ctx, source.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) && source.Optional {
+				continue
+			}
+			if apierrors.IsNotFound(err) {
+				err = fmt.Errorf("waiting for %s %s/%s of valuesFrom", source.GVR.Resource, source.Namespace, source.Name)
+				recordEvent(c.recorder, nil, instance, corev1.EventTypeWarning, eventReasonValuesNotFound, "%v", err)
+				return nil, nil, references, requeue.NeededAfter(err, c.reconcileConfig.DefaultRequeueDuration)
+			}
+			return nil, nil, references, fmt.Errorf("failed to get %s %s/%s of valuesFrom: %w",
+				source.GVR.Resource, source.Namespace, source.Name, err)
+		}
+		objects = append(objects, object)
+	}
+
+	values, err := graph.LoadValues(objects)
+	if err != nil {
+		return nil, nil, references, err
+	}
+	var secretValues []string
+	for _, object := range objects {
+		if object.GetKind() != "Secret" {
+			continue
+		}
+		// The object was already decoded successfully.
+		decoded, _ := graph.LoadValues([]*unstructured.Unstructured{object})
+		for _, value := range decoded {
+			secretValues = append(secretValues, value)
+		}
+	}
+	return values, secretValues, references, nil
+}
@@ -15,41 +15,150 @@ package resourcegraphdefinition
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlrtcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/kro-run/kro/api/v1alpha1"
 	kroclient "github.com/kro-run/kro/pkg/client"
 	"github.com/kro-run/kro/pkg/dynamiccontroller"
 	"github.com/kro-run/kro/pkg/graph"
 	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/webhook"
 )
 
 //+kubebuilder:rbac:groups=kro.run,resources=resourcegraphdefinitions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kro.run,resources=resourcegraphdefinitions/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kro.run,resources=resourcegraphdefinitions/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kro.run,resources=resourcegraphtypelibraries,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // ResourceGraphDefinitionReconciler reconciles a ResourceGraphDefinition object
 type ResourceGraphDefinitionReconciler struct {
 	allowCRDDeletion bool
 
-	// Client and instanceLogger are set with SetupWithManager
+	// Client, instanceLogger and instanceRecorder are set with
+	// SetupWithManager
 
 	client.Client
-	instanceLogger logr.Logger
+	instanceLogger   logr.Logger
+	instanceRecorder record.EventRecorder
 
 	clientSet  *kroclient.Set
 	crdManager kroclient.CRDClient
+	// remoteClients builds the clients of the remote clusters targeted by
+	// the resource graph definitions.
+	remoteClients *kroclient.RemoteClients
 
 	metadataLabeler         metadata.Labeler
 	rgBuilder               *graph.Builder
 	dynamicController       *dynamiccontroller.DynamicController
 	maxConcurrentReconciles int
+	// maxConcurrentResourceReconciles is the maximum number of resources of an
+	// instance reconciled concurrently.
+	maxConcurrentResourceReconciles int
+	// fieldManager is the field manager the resources of the instances are
+	// applied with.
+	fieldManager string
+	// resyncPeriod is the interval at which the instances are reconciled
+	// even without changes, unless their resource graph definition sets its
+	// own. 0 disables it.
+	resyncPeriod time.Duration
+	// defaultingWebhook is optional, when set the instance schemas are
+	// registered with it so that defaults get applied at admission.
+	defaultingWebhook *webhook.DefaultingWebhook
+	// conversionWebhook is optional, it is required to serve instance APIs
+	// with versions declaring conversions.
+	conversionWebhook *webhook.ConversionWebhook
+	// schemaNamespace is the namespace the JSON Schema of the instances is
+	// published to, in a ConfigMap per resource graph definition. Empty
+	// disables the publication.
+	schemaNamespace string
+	// graphs caches the graphs built for the generations of the resource
+	// graph definitions.
+	graphs *graph.Cache
+
+	// rollouts holds the functions stopping the tracking of the rollout of
+	// the resource graph definitions, keyed by their name. It's guarded by
+	// rolloutsMu.
+	rollouts   map[string]context.CancelFunc
+	rolloutsMu sync.Mutex
+
+	// pendingCRDs tracks the CRDs of the resource graph definitions that
+	// aren't established yet, keyed by the name of the resource graph
+	// definitions. It's guarded by pendingCRDsMu.
+	pendingCRDs   map[string]*pendingCRD
+	pendingCRDsMu sync.Mutex
+
+	// clientSets holds the dedicated clients of the resource graph
+	// definitions requesting their own rate limits, keyed by their name. It's
+	// guarded by clientSetsMu.
+	clientSets   map[string]*dedicatedClientSet
+	clientSetsMu sync.Mutex
+
+	// webhookKinds holds the kinds registered with the webhooks for each
+	// resource graph definition, keyed by their name. It's guarded by
+	// webhookKindsMu.
+	webhookKinds   map[string]webhookKinds
+	webhookKindsMu sync.Mutex
+
+	// selector selects the resource graph definitions reconciled by this
+	// deployment, nil selects all of them.
+	selector labels.Selector
+	// leaseConfig is optional, when set each resource graph definition is
+	// reconciled by the replica holding its lease. The leases are run by
+	// leases, which queues the resource graph definitions whose lease is
+	// acquired to leaseEvents. They're set with SetupWithManager.
+	leaseConfig *LeaseConfig
+	leases      *leases
+	leaseEvents chan event.GenericEvent
+}
+
+// ReconcilerConfig holds the optional configuration of the
+// ResourceGraphDefinitionReconciler.
+type ReconcilerConfig struct {
+	// MaxConcurrentReconciles is the maximum number of resource graph
+	// definitions reconciled concurrently.
+	MaxConcurrentReconciles int
+	// MaxConcurrentResourceReconciles is the maximum number of resources of an
+	// instance reconciled concurrently.
+	MaxConcurrentResourceReconciles int
+	// FieldManager is the field manager the resources of the instances are
+	// applied with.
+	FieldManager string
+	// ResyncPeriod is the interval at which the instances are reconciled
+	// even without changes, unless their resource graph definition sets its
+	// own. 0 disables it.
+	ResyncPeriod time.Duration
+	// DefaultingWebhook is optional, when set the instance schemas are
+	// registered with it so that defaults get applied at admission.
+	DefaultingWebhook *webhook.DefaultingWebhook
+	// ConversionWebhook is optional, it is required to serve instance APIs
+	// with versions declaring conversions.
+	ConversionWebhook *webhook.ConversionWebhook
+	// SchemaNamespace is the namespace the JSON Schema of the instances is
+	// published to. Empty disables the publication.
+	SchemaNamespace string
+	// Selector selects the resource graph definitions reconciled by this
+	// deployment, nil selects all of them.
+	Selector labels.Selector
+	// LeaseConfig is optional, when set each resource graph definition is
+	// reconciled by the replica holding its lease.
+	LeaseConfig *LeaseConfig
 }
 
 func NewResourceGraphDefinitionReconciler(
@@ -57,18 +166,32 @@ func NewResourceGraphDefinitionReconciler(
 	allowCRDDeletion bool,
 	dynamicController *dynamiccontroller.DynamicController,
 	builder *graph.Builder,
-	maxConcurrentReconciles int,
+	config ReconcilerConfig,
 ) *ResourceGraphDefinitionReconciler {
 	crdWrapper := clientSet.CRD(kroclient.CRDWrapperConfig{})
 
 	return &ResourceGraphDefinitionReconciler{
-		clientSet:               clientSet,
-		allowCRDDeletion:        allowCRDDeletion,
-		crdManager:              crdWrapper,
-		dynamicController:       dynamicController,
-		metadataLabeler:         metadata.NewKROMetaLabeler(),
-		rgBuilder:               builder,
-		maxConcurrentReconciles: maxConcurrentReconciles,
+		clientSet:                       clientSet,
+		allowCRDDeletion:                allowCRDDeletion,
+		crdManager:                      crdWrapper,
+		remoteClients:                   kroclient.NewRemoteClients(),
+		dynamicController:               dynamicController,
+		metadataLabeler:                 metadata.NewKROMetaLabeler(),
+		rgBuilder:                       builder,
+		graphs:                          graph.NewCache(),
+		maxConcurrentReconciles:         config.MaxConcurrentReconciles,
+		maxConcurrentResourceReconciles: config.MaxConcurrentResourceReconciles,
+		fieldManager:                    config.FieldManager,
+		resyncPeriod:                    config.ResyncPeriod,
+		defaultingWebhook:               config.DefaultingWebhook,
+		conversionWebhook:               config.ConversionWebhook,
+		schemaNamespace:                 config.SchemaNamespace,
+		rollouts:                        make(map[string]context.CancelFunc),
+		pendingCRDs:                     make(map[string]*pendingCRD),
+		clientSets:                      make(map[string]*dedicatedClientSet),
+		webhookKinds:                    make(map[string]webhookKinds),
+		selector:                        config.Selector,
+		leaseConfig:                     config.LeaseConfig,
 	}
 }
 
@@ -76,6 +199,11 @@ func NewResourceGraphDefinitionReconciler(
 func (r *ResourceGraphDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Client = mgr.GetClient()
 	r.instanceLogger = mgr.GetLogger()
+	r.instanceRecorder = mgr.GetEventRecorderFor("kro")
+	r.dynamicController.OnWatchHealthChange(r.reportWatchHealth)
+	if err := r.setupWebhooksWithManager(mgr); err != nil {
+		return err
+	}
 
 	logConstructor := func(req *reconcile.Request) logr.Logger {
 		log := mgr.GetLogger().WithName("rgd-controller").WithValues(
@@ -89,10 +217,37 @@ func (r *ResourceGraphDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) e
 		return log
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	eventFilter := predicate.Predicate(predicate.GenerationChangedPredicate{})
+	if r.selector != nil {
+		// The resource graph definitions are released once their labels no
+		// longer match.
+		eventFilter = predicate.Or(eventFilter, predicate.LabelChangedPredicate{})
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named("ResourceGraphDefinition").
-		For(&v1alpha1.ResourceGraphDefinition{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&v1alpha1.ResourceGraphDefinition{}, builder.WithPredicates(predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return r.selects(e.ObjectOld) || r.selects(e.ObjectNew)
+			},
+			CreateFunc:  func(e event.CreateEvent) bool { return r.selects(e.Object) },
+			DeleteFunc:  func(e event.DeleteEvent) bool { return r.selects(e.Object) },
+			GenericFunc: func(e event.GenericEvent) bool { return r.selects(e.Object) },
+		})).
+		Watches(
+			&v1alpha1.ResourceGraphTypeLibrary{},
+			handler.EnqueueRequestsFromMapFunc(r.findResourceGraphDefinitionsForTypeLibrary),
+		).
+		WithEventFilter(eventFilter)
+	if r.leaseConfig != nil {
+		r.leaseEvents = make(chan event.GenericEvent)
+		r.leases = newLeases(mgr.GetLogger().WithName("rgd-leases"), *r.leaseConfig,
+			r.clientSet.Kubernetes().CoordinationV1(), r.leaseAcquired, r.stopServing)
+		if err := mgr.Add(r.leases); err != nil {
+			return err
+		}
+		b = b.WatchesRawSource(source.Channel(r.leaseEvents, &handler.EnqueueRequestForObject{}))
+	}
+	return b.
 		WithOptions(
 			ctrlrtcontroller.Options{
 				LogConstructor:          logConstructor,
@@ -102,11 +257,71 @@ func (r *ResourceGraphDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) e
 		Complete(reconcile.AsReconciler[*v1alpha1.ResourceGraphDefinition](mgr.GetClient(), r))
 }
 
+// findResourceGraphDefinitionsForTypeLibrary returns a request for every
+// resource graph definition, since any of them may use the types of the
+// given type library. Their cached graphs are dropped, to be rebuilt with the
+// new types.
+func (r *ResourceGraphDefinitionReconciler) findResourceGraphDefinitionsForTypeLibrary(
+	ctx context.Context,
+	_ client.Object,
+) []reconcile.Request {
+	r.graphs.Reset()
+	var rgds v1alpha1.ResourceGraphDefinitionList
+	if err := r.List(ctx, &rgds); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list resource graph definitions")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(rgds.Items))
+	for _, rgd := range rgds.Items {
+		if !r.selects(&rgd) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: rgd.Name},
+		})
+	}
+	return requests
+}
+
 func (r *ResourceGraphDefinitionReconciler) Reconcile(ctx context.Context, o *v1alpha1.ResourceGraphDefinition) (ctrl.Result, error) {
+	if !r.selects(o) {
+		// It's left to the deployments selecting it.
+		r.stopServing(ctx, o.Name)
+		if r.leases != nil {
+			r.leases.Release(o.Name)
+		}
+		return ctrl.Result{}, nil
+	}
+	if r.leases != nil && !r.leases.Held(o.Name) {
+		// It's reconciled by the replica holding its lease, this one queues
+		// it once it acquires the lease.
+		return ctrl.Result{}, nil
+	}
+
 	if !o.DeletionTimestamp.IsZero() {
+		deletion, err := r.remainingInstances(ctx, o)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deletion != nil {
+			// Keep serving the instances until they're deleted, so that their
+			// resources are cleaned up.
+			ctrl.LoggerFrom(ctx).Info("deletion blocked by remaining instances", "instances", deletion.RemainingInstances)
+			topologicalOrder, resourcesInformation, renderedGraph, reconcileErr := r.reconcileResourceGraphDefinition(ctx, o)
+			if err := r.setResourceGraphDefinitionStatus(ctx, o, topologicalOrder, resourcesInformation, renderedGraph, deletion, reconcileErr); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: deletionRecheckInterval}, nil
+		}
 		if err := r.cleanupResourceGraphDefinition(ctx, o); err != nil {
 			return ctrl.Result{}, err
 		}
+		if r.leases != nil {
+			if err := r.leases.Delete(ctx, o.Name); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		if err := r.setUnmanaged(ctx, o); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -117,8 +332,21 @@ func (r *ResourceGraphDefinitionReconciler) Reconcile(ctx context.Context, o *v1
 		return ctrl.Result{}, err
 	}
 
-	topologicalOrder, resourcesInformation, reconcileErr := r.reconcileResourceGraphDefinition(ctx, o)
+	topologicalOrder, resourcesInformation, renderedGraph, reconcileErr := r.reconcileResourceGraphDefinition(ctx, o)
 
-	return ctrl.Result{},
-		r.setResourceGraphDefinitionStatus(ctx, o, topologicalOrder, resourcesInformation, reconcileErr)
+	if err := r.setResourceGraphDefinitionStatus(ctx, o, topologicalOrder, resourcesInformation, renderedGraph, nil, reconcileErr); err != nil {
+		return ctrl.Result{}, err
+	}
+	var pendingErr *crdPendingError
+	if errors.As(reconcileErr, &pendingErr) {
+		// Check the CRD again later rather than blocking the worker until
+		// it's established.
+		return ctrl.Result{RequeueAfter: r.nextCRDCheck(o.Name)}, nil
+	}
+	if reconcileErr == nil {
+		// The instances are queued again with the new graph, report their
+		// progress.
+		r.trackRollout(ctx, o)
+	}
+	return ctrl.Result{}, nil
 }
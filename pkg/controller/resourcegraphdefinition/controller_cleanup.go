@@ -16,9 +16,13 @@ package resourcegraphdefinition
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gobuffalo/flect"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
 
@@ -26,6 +30,52 @@ import (
 	"github.com/kro-run/kro/pkg/metadata"
 )
 
+const (
+	// deletionRecheckInterval is the interval at which the instances of a
+	// resource graph definition whose deletion is blocked are checked again.
+	deletionRecheckInterval = 10 * time.Second
+	// maxReportedInstances is the maximum number of instances blocking the
+	// deletion of a resource graph definition listed in its status.
+	maxReportedInstances = 10
+)
+
+// remainingInstances returns the instances blocking the deletion of the
+// resource graph definition, or nil if it can be deleted: either no instance
+// remains, or its deletion is forced with the kro.run/force-delete annotation.
+func (r *ResourceGraphDefinitionReconciler) remainingInstances(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) (*v1alpha1.DeletionStatus, error) {
+	if metadata.IsForceDeleted(rgd) {
+		ctrl.LoggerFrom(ctx).Info("forcing deletion regardless of the remaining instances")
+		return nil, nil
+	}
+
+	gvr := metadata.GetResourceGraphDefinitionInstanceGVR(rgd.Spec.Schema.Group, rgd.Spec.Schema.APIVersion, rgd.Spec.Schema.Kind)
+	instances, err := r.clientSet.Dynamic().Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The CRD is gone, and its instances with it.
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	if len(instances.Items) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(instances.Items))
+	for _, instance := range instances.Items {
+		name := instance.GetName()
+		if namespace := instance.GetNamespace(); namespace != "" {
+			name = namespace + "/" + name
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return &v1alpha1.DeletionStatus{
+		RemainingInstances: int64(len(names)),
+		Instances:          names[:min(len(names), maxReportedInstances)],
+	}, nil
+}
+
 // cleanupResourceGraphDefinition handles the deletion of a ResourceGraphDefinition by shutting down its associated
 // microcontroller and cleaning up the CRD if enabled. It executes cleanup operations in order:
 // 1. Shuts down the microcontroller
@@ -33,6 +83,13 @@ import (
 func (r *ResourceGraphDefinitionReconciler) cleanupResourceGraphDefinition(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) error {
 	ctrl.LoggerFrom(ctx).V(1).Info("cleaning up resource graph definition", "name", rgd.Name)
 
+	r.stopTrackingRollout(rgd.Name)
+	r.stopTrackingCRD(rgd.Name)
+	r.graphs.Invalidate(rgd.UID)
+	r.clientSetsMu.Lock()
+	delete(r.clientSets, rgd.Name)
+	r.clientSetsMu.Unlock()
+
 	// shutdown microcontroller
 	gvr := metadata.GetResourceGraphDefinitionInstanceGVR(rgd.Spec.Schema.Group, rgd.Spec.Schema.APIVersion, rgd.Spec.Schema.Kind)
 	if err := r.shutdownResourceGraphDefinitionMicroController(ctx, &gvr); err != nil {
@@ -43,6 +100,10 @@ func (r *ResourceGraphDefinitionReconciler) cleanupResourceGraphDefinition(ctx c
 	if group == "" {
 		group = v1alpha1.KRODomainName
 	}
+
+	// stop defaulting and converting instances
+	r.unregisterWebhooks(rgd.Name)
+
 	// cleanup CRD
 	crdName := extractCRDName(group, rgd.Spec.Schema.Kind)
 	if err := r.cleanupResourceGraphDefinitionCRD(ctx, crdName); err != nil {
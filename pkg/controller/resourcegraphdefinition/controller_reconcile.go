@@ -14,63 +14,131 @@
 package resourcegraphdefinition
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/kro-run/kro/api/v1alpha1"
+	kroclient "github.com/kro-run/kro/pkg/client"
 	instancectrl "github.com/kro-run/kro/pkg/controller/instance"
 	"github.com/kro-run/kro/pkg/dynamiccontroller"
 	"github.com/kro-run/kro/pkg/graph"
+	crdgraph "github.com/kro-run/kro/pkg/graph/crd"
 	"github.com/kro-run/kro/pkg/metadata"
 )
 
 // reconcileResourceGraphDefinition orchestrates the reconciliation of a ResourceGraphDefinition by:
 // 1. Processing the resource graph
-// 2. Ensuring CRDs are present
+// 2. Ensuring CRDs are present, and publishing their schema if enabled
 // 3. Setting up and starting the microcontroller
-func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinition(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) ([]string, []v1alpha1.ResourceInformation, error) {
+func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinition(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) ([]string, []v1alpha1.ResourceInformation, string, error) {
 	log := ctrl.LoggerFrom(ctx)
 
 	// Process resource graph definition graph first to validate structure
 	log.V(1).Info("reconciling resource graph definition graph")
 	processedRGD, resourcesInfo, err := r.reconcileResourceGraphDefinitionGraph(ctx, rgd)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
+	}
+	for _, warning := range processedRGD.Warnings {
+		log.Info("resource graph definition warning", "warning", warning)
+	}
+
+	renderedGraph, err := renderGraph(rgd, processedRGD)
+	if err != nil {
+		return processedRGD.TopologicalOrder, resourcesInfo, "", newGraphError(err)
 	}
 
 	// Setup metadata labeling
 	graphExecLabeler, err := r.setupLabeler(rgd)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to setup labeler: %w", err)
+		return nil, nil, "", fmt.Errorf("failed to setup labeler: %w", err)
 	}
 
 	crd := processedRGD.Instance.GetCRD()
 	graphExecLabeler.ApplyLabels(&crd.ObjectMeta)
 
+	if processedRGD.Converter != nil && processedRGD.Converter.RequiresWebhook() {
+		if r.conversionWebhook == nil {
+			return processedRGD.TopologicalOrder, resourcesInfo, renderedGraph, newCRDError(
+				fmt.Errorf("versions declaring conversions require the conversion webhook to be enabled"),
+			)
+		}
+		crd.Spec.Conversion = r.conversionWebhook.CustomResourceConversion()
+		// Register the converter before ensuring the CRD, the API server may
+		// start converting instances as soon as the CRD is updated. The
+		// other replicas register it with setupWebhooksWithManager.
+		r.conversionWebhook.Register(schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}, processedRGD.Converter)
+	}
+
 	// Ensure CRD exists and is up to date
 	log.V(1).Info("reconciling resource graph definition CRD")
-	if err := r.reconcileResourceGraphDefinitionCRD(ctx, crd); err != nil {
-		return processedRGD.TopologicalOrder, resourcesInfo, err
+	if err := r.reconcileResourceGraphDefinitionCRD(ctx, rgd, crd); err != nil {
+		return processedRGD.TopologicalOrder, resourcesInfo, renderedGraph, err
+	}
+
+	if r.schemaNamespace != "" {
+		log.V(1).Info("reconciling resource graph definition schema ConfigMap")
+		if err := r.reconcileSchemaConfigMap(ctx, rgd, crd, graphExecLabeler); err != nil {
+			return processedRGD.TopologicalOrder, resourcesInfo, renderedGraph, newCRDError(err)
+		}
 	}
 
 	// Setup and start microcontroller
+	clientSet, err := r.instanceClientSet(rgd)
+	if err != nil {
+		return processedRGD.TopologicalOrder, resourcesInfo, renderedGraph, newMicroControllerError(err)
+	}
+
+	var concurrency int
+	if rgd.Spec.Reconcile != nil {
+		concurrency = int(rgd.Spec.Reconcile.Concurrency)
+	}
+	clusters, err := r.targetClusters(ctx, processedRGD)
+	if err != nil {
+		return processedRGD.TopologicalOrder, resourcesInfo, renderedGraph, newMicroControllerError(err)
+	}
+	instanceScope, dependencies, err := watchScopes(rgd, processedRGD, clusters)
+	if err != nil {
+		return processedRGD.TopologicalOrder, resourcesInfo, renderedGraph, newMicroControllerError(err)
+	}
+
 	gvr := processedRGD.Instance.GetGroupVersionResource()
-	controller := r.setupMicroController(gvr, processedRGD, rgd.Spec.DefaultServiceAccounts, graphExecLabeler)
+	controller := r.setupMicroController(rgd.Name, gvr, processedRGD, clientSet, rgd.Spec.DefaultServiceAccounts, graphExecLabeler)
 
 	log.V(1).Info("reconciling resource graph definition micro controller")
 	// TODO: the context that is passed here is tied to the reconciliation of the rgd, we might need to make
 	// a new context with our own cancel function here to allow us to cleanly term the dynamic controller
 	// rather than have it ignore this context and use the background context.
-	if err := r.reconcileResourceGraphDefinitionMicroController(ctx, &gvr, controller.Reconcile); err != nil {
-		return processedRGD.TopologicalOrder, resourcesInfo, err
+	if err := r.reconcileResourceGraphDefinitionMicroController(ctx, rgd.Name, &gvr, controller.Reconcile, instanceScope, dependencies, rgd.Spec.Retry, concurrency); err != nil {
+		return processedRGD.TopologicalOrder, resourcesInfo, renderedGraph, err
 	}
 
-	return processedRGD.TopologicalOrder, resourcesInfo, nil
+	return processedRGD.TopologicalOrder, resourcesInfo, renderedGraph, nil
+}
+
+// renderGraph renders the dependency graph of the processed resource graph
+// definition in the format requested by its annotations, if any.
+func renderGraph(rgd *v1alpha1.ResourceGraphDefinition, processedRGD *graph.Graph) (string, error) {
+	format, ok := rgd.GetAnnotations()[metadata.GraphFormatAnnotation]
+	if !ok {
+		return "", nil
+	}
+	rendered, err := processedRGD.Render(graph.RenderFormat(format))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s annotation: %w", metadata.GraphFormatAnnotation, err)
+	}
+	return rendered, nil
 }
 
 // setupLabeler creates and merges the required labelers for the resource graph definition
@@ -79,10 +147,68 @@ func (r *ResourceGraphDefinitionReconciler) setupLabeler(rgd *v1alpha1.ResourceG
 	return r.metadataLabeler.Merge(rgLabeler)
 }
 
+// instanceClientSet returns the client set the instance controller of the given
+// resource graph definition should use. Resource graph definitions requesting
+// their own rate limits get a dedicated client, so that a heavy graph can't
+// exhaust the client budget shared by the other graphs. The dedicated client
+// is kept across reconciliations until its limits change, so that its rate
+// limiter, shared by the clients impersonating service accounts, keeps its
+// budget.
+func (r *ResourceGraphDefinitionReconciler) instanceClientSet(rgd *v1alpha1.ResourceGraphDefinition) (*kroclient.Set, error) {
+	limits := getClientRateLimits(rgd)
+
+	r.clientSetsMu.Lock()
+	defer r.clientSetsMu.Unlock()
+	if limits == nil {
+		delete(r.clientSets, rgd.Name)
+		return r.clientSet, nil
+	}
+	if dedicated, ok := r.clientSets[rgd.Name]; ok && dedicated.limits == *limits {
+		return dedicated.clientSet, nil
+	}
+	clientSet, err := r.clientSet.WithRateLimits(limits.QPS, limits.Burst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dedicated client: %w", err)
+	}
+	r.clientSets[rgd.Name] = &dedicatedClientSet{limits: *limits, clientSet: clientSet}
+	return clientSet, nil
+}
+
+// clientRateLimits holds the client side rate limits requested by a resource
+// graph definition.
+type clientRateLimits struct {
+	QPS   float32
+	Burst int
+}
+
+// getClientRateLimits returns the client rate limits requested by the resource
+// graph definition. It returns nil if it doesn't request a dedicated client. If
+// the burst is not set, it defaults to the QPS.
+func getClientRateLimits(rgd *v1alpha1.ResourceGraphDefinition) *clientRateLimits {
+	policy := rgd.Spec.Reconcile
+	if policy == nil || policy.ClientQPS <= 0 {
+		return nil
+	}
+	limits := &clientRateLimits{QPS: float32(policy.ClientQPS), Burst: int(policy.ClientQPS)}
+	if policy.ClientBurst > 0 {
+		limits.Burst = int(policy.ClientBurst)
+	}
+	return limits
+}
+
+// dedicatedClientSet is the dedicated client of a resource graph definition,
+// built for the given rate limits.
+type dedicatedClientSet struct {
+	limits    clientRateLimits
+	clientSet *kroclient.Set
+}
+
 // setupMicroController creates a new controller instance with the required configuration
 func (r *ResourceGraphDefinitionReconciler) setupMicroController(
+	name string,
 	gvr schema.GroupVersionResource,
 	processedRGD *graph.Graph,
+	clientSet *kroclient.Set,
 	defaultSVCs map[string]string,
 	labeler metadata.Labeler,
 ) *instancectrl.Controller {
@@ -95,24 +221,47 @@ func (r *ResourceGraphDefinitionReconciler) setupMicroController(
 	return instancectrl.NewController(
 		instanceLogger,
 		instancectrl.ReconcileConfig{
-			DefaultRequeueDuration:    3 * time.Second,
-			DeletionGraceTimeDuration: 30 * time.Second,
-			DeletionPolicy:            "Delete",
+			DefaultRequeueDuration:          3 * time.Second,
+			MaxConcurrentResourceReconciles: r.maxConcurrentResourceReconciles,
+			FieldManager:                    r.fieldManager,
+			ResyncPeriod:                    cmp.Or(processedRGD.ResyncPeriod, r.resyncPeriod),
+			DeletionGraceTimeDuration:       30 * time.Second,
+			DeletionPolicy:                  "Delete",
 		},
+		name,
 		gvr,
 		processedRGD,
-		r.clientSet,
+		clientSet,
+		r.remoteClients,
 		defaultSVCs,
 		labeler,
+		r.dynamicController,
+		r.dynamicController,
+		r.instanceRecorder,
 	)
 }
 
 // reconcileResourceGraphDefinitionGraph processes the resource graph definition to build a dependency graph
-// and extract resource information
-func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionGraph(_ context.Context, rgd *v1alpha1.ResourceGraphDefinition) (*graph.Graph, []v1alpha1.ResourceInformation, error) {
-	processedRGD, err := r.rgBuilder.NewResourceGraphDefinition(rgd)
-	if err != nil {
-		return nil, nil, newGraphError(err)
+// and extract resource information. The graph is only built once per generation of the resource graph
+// definition, the reconciliations of the same generation reuse the cached one.
+func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionGraph(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) (*graph.Graph, []v1alpha1.ResourceInformation, error) {
+	processedRGD, ok := r.graphs.Get(rgd)
+	if ok {
+		graphCacheLookups.WithLabelValues(rgd.Name, "hit").Inc()
+	} else {
+		graphCacheLookups.WithLabelValues(rgd.Name, "miss").Inc()
+		sharedTypes, err := r.loadSharedTypes(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		start := time.Now()
+		processedRGD, err = r.rgBuilder.NewResourceGraphDefinition(rgd, graph.WithSharedTypes(sharedTypes))
+		graphBuildDuration.WithLabelValues(rgd.Name).Observe(time.Since(start).Seconds())
+		if err != nil {
+			return nil, nil, newGraphError(err)
+		}
+		r.graphs.Set(rgd, processedRGD)
 	}
 
 	resourcesInfo := make([]v1alpha1.ResourceInformation, 0, len(processedRGD.Resources))
@@ -126,6 +275,21 @@ func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionGrap
 	return processedRGD, resourcesInfo, nil
 }
 
+// loadSharedTypes returns the custom types defined by all the type libraries
+// in the cluster, which are available to every resource graph definition.
+func (r *ResourceGraphDefinitionReconciler) loadSharedTypes(ctx context.Context) (map[string]interface{}, error) {
+	var libraries v1alpha1.ResourceGraphTypeLibraryList
+	if err := r.List(ctx, &libraries); err != nil {
+		return nil, fmt.Errorf("failed to list type libraries: %w", err)
+	}
+
+	sharedTypes, err := graph.MergeTypeLibraries(libraries.Items)
+	if err != nil {
+		return nil, newGraphError(err)
+	}
+	return sharedTypes, nil
+}
+
 // buildResourceInfo creates a ResourceInformation struct from name and dependencies
 func buildResourceInfo(name string, deps []string) v1alpha1.ResourceInformation {
 	dependencies := make([]v1alpha1.Dependency, 0, len(deps))
@@ -138,23 +302,267 @@ func buildResourceInfo(name string, deps []string) v1alpha1.ResourceInformation
 	}
 }
 
-// reconcileResourceGraphDefinitionCRD ensures the CRD is present and up to date in the cluster
-func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionCRD(ctx context.Context, crd *v1.CustomResourceDefinition) error {
-	if err := r.crdManager.Ensure(ctx, *crd); err != nil {
+// reconcileResourceGraphDefinitionCRD ensures the CRD is present and up to date in the cluster.
+// Updates introducing breaking schema changes are rejected, unless the resource graph definition
+// explicitly allows them. A crdPendingError is returned while the CRD isn't established, or while
+// the API server is too slow to apply it.
+func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionCRD(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	crd *v1.CustomResourceDefinition,
+) error {
+	start := r.crdPendingSince(rgd.Name)
+	existing, err := r.crdManager.Get(ctx, crd.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		if isTransientAPIError(err) {
+			return &crdPendingError{name: crd.Name, reason: err.Error()}
+		}
+		r.stopTrackingCRD(rgd.Name)
+		return newCRDError(fmt.Errorf("failed to get existing CRD: %w", err))
+	}
+	if err == nil {
+		report := crdgraph.CompareCRDs(existing, crd)
+		if report.IsBreaking() {
+			if !metadata.IsBreakingChangesAllowed(rgd) {
+				r.stopTrackingCRD(rgd.Name)
+				return newCRDError(&breakingChangesError{report})
+			}
+			ctrl.LoggerFrom(ctx).Info("applying breaking schema changes", "crd", crd.Name, "changes", report.String())
+		}
+	}
+
+	// The CRD isn't waited for, the resource graph definition is reconciled
+	// again until the CRD is established.
+	applied, err := r.crdManager.Apply(ctx, *crd)
+	if err != nil {
+		if isTransientAPIError(err) {
+			return &crdPendingError{name: crd.Name, reason: err.Error()}
+		}
+		r.stopTrackingCRD(rgd.Name)
 		return newCRDError(err)
 	}
+	if established, reason := kroclient.CRDEstablished(applied); !established {
+		return &crdPendingError{name: crd.Name, reason: reason}
+	}
+	r.stopTrackingCRD(rgd.Name)
+	crdReadyDuration.WithLabelValues(rgd.Name).Observe(time.Since(start).Seconds())
 	return nil
 }
 
-// reconcileResourceGraphDefinitionMicroController starts the microcontroller for handling the resources
-func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionMicroController(ctx context.Context, gvr *schema.GroupVersionResource, handler dynamiccontroller.Handler) error {
+const (
+	// crdPollInterval is the initial interval at which a CRD that isn't
+	// established yet is checked again, doubled at every check.
+	crdPollInterval = 250 * time.Millisecond
+	// maxCRDPollInterval is the maximum interval at which a CRD that isn't
+	// established yet is checked again.
+	maxCRDPollInterval = 30 * time.Second
+)
+
+// pendingCRD tracks the establishment of the CRD of a resource graph
+// definition.
+type pendingCRD struct {
+	since  time.Time
+	checks int
+}
+
+// crdPendingSince returns the time the CRD of the given resource graph
+// definition started to be applied, starting to track it if needed.
+func (r *ResourceGraphDefinitionReconciler) crdPendingSince(name string) time.Time {
+	r.pendingCRDsMu.Lock()
+	defer r.pendingCRDsMu.Unlock()
+	pending, ok := r.pendingCRDs[name]
+	if !ok {
+		pending = &pendingCRD{since: time.Now()}
+		r.pendingCRDs[name] = pending
+	}
+	return pending.since
+}
+
+// nextCRDCheck returns the delay after which the CRD of the given resource
+// graph definition is checked again, backing off exponentially.
+func (r *ResourceGraphDefinitionReconciler) nextCRDCheck(name string) time.Duration {
+	r.pendingCRDsMu.Lock()
+	defer r.pendingCRDsMu.Unlock()
+	pending, ok := r.pendingCRDs[name]
+	if !ok {
+		return crdPollInterval
+	}
+	delay := min(crdPollInterval<<min(pending.checks, 8), maxCRDPollInterval)
+	pending.checks++
+	return delay
+}
+
+// stopTrackingCRD stops tracking the establishment of the CRD of the given
+// resource graph definition.
+func (r *ResourceGraphDefinitionReconciler) stopTrackingCRD(name string) {
+	r.pendingCRDsMu.Lock()
+	defer r.pendingCRDsMu.Unlock()
+	delete(r.pendingCRDs, name)
+}
+
+// isTransientAPIError returns whether the given error is returned by an API
+// server too slow or too busy to handle the request, which is worth retrying.
+func isTransientAPIError(err error) bool {
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// reconcileResourceGraphDefinitionMicroController starts the microcontroller for handling the resources,
+// and watches the objects referenced by the instances so that they're reconciled when these change.
+// Instances failing to reconcile are retried following the retry policy, if any,
+// and are reconciled by dedicated workers if the concurrency is set.
+func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionMicroController(
+	ctx context.Context,
+	name string,
+	gvr *schema.GroupVersionResource,
+	handler dynamiccontroller.Handler,
+	instanceScope *dynamiccontroller.WatchScope,
+	dependencies []dynamiccontroller.Dependency,
+	retryPolicy *v1alpha1.RetryPolicy,
+	concurrency int,
+) error {
+	r.dynamicController.SetResourceGraphDefinition(*gvr, name)
+	r.dynamicController.SetRetryPolicy(*gvr, instancectrl.RetryBackoff(retryPolicy))
+	r.dynamicController.SetWatchScope(*gvr, instanceScope)
+	// StartServingGVK resyncs the instances, in case their queue changed.
+	r.dynamicController.SetConcurrency(*gvr, concurrency)
 	err := r.dynamicController.StartServingGVK(ctx, *gvr, handler)
 	if err != nil {
 		return newMicroControllerError(err)
 	}
+	if err := r.dynamicController.WatchDependencies(*gvr, dependencies); err != nil {
+		return newMicroControllerError(err)
+	}
 	return nil
 }
 
+// targetClusters returns the remote clusters the resources of the processed
+// resource graph definition are applied to, keyed by the Secret holding their
+// kubeconfig.
+func (r *ResourceGraphDefinitionReconciler) targetClusters(
+	ctx context.Context,
+	processedRGD *graph.Graph,
+) (map[v1alpha1.KubeconfigSecretReference]*dynamiccontroller.Cluster, error) {
+	clusters := make(map[v1alpha1.KubeconfigSecretReference]*dynamiccontroller.Cluster)
+	for _, resource := range processedRGD.Resources {
+		target := resource.GetTarget()
+		if target == nil {
+			continue
+		}
+		if _, ok := clusters[target.KubeconfigSecretRef]; ok {
+			continue
+		}
+		set, version, err := r.remoteClients.Get(ctx, r.clientSet.Dynamic(), target)
+		if err != nil {
+			return nil, err
+		}
+		clusters[target.KubeconfigSecretRef] = &dynamiccontroller.Cluster{
+			Name:    kroclient.ClusterName(target),
+			Version: version,
+			Client:  set.Dynamic(),
+		}
+	}
+	return clusters, nil
+}
+
+// watchScopes returns the scope the instances of the processed resource graph
+// definition are watched in, and the objects they refer to: the objects
+// referenced by its external references, the resources it manages, in the
+// given target clusters for the resources applied to a remote cluster, and
+// the ConfigMaps and Secrets their values are loaded from. The
+// scope is nil, and the objects are watched cluster wide, unless the resource
+// graph definition sets a watch policy. The resources it manages are then only
+// watched through the label kro sets on them.
+func watchScopes(
+	rgd *v1alpha1.ResourceGraphDefinition,
+	processedRGD *graph.Graph,
+	clusters map[v1alpha1.KubeconfigSecretReference]*dynamiccontroller.Cluster,
+) (*dynamiccontroller.WatchScope, []dynamiccontroller.Dependency, error) {
+	policy := rgd.Spec.Watch
+	var instanceScope *dynamiccontroller.WatchScope
+	if policy != nil {
+		instanceScope = &dynamiccontroller.WatchScope{}
+		if processedRGD.Instance.IsNamespaced() {
+			instanceScope.Namespaces = policy.Namespaces
+		}
+		if policy.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(policy.Selector)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid watch selector: %w", err)
+			}
+			instanceScope.LabelSelector = selector.String()
+		}
+	}
+
+	var dependencies []dynamiccontroller.Dependency
+	add := func(dependency dynamiccontroller.Dependency) {
+		i := slices.IndexFunc(dependencies, func(d dynamiccontroller.Dependency) bool {
+			return d.GVR == dependency.GVR && d.Cluster == dependency.Cluster
+		})
+		if i < 0 {
+			dependencies = append(dependencies, dependency)
+			return
+		}
+		if dependencies[i].Scope.LabelSelector != dependency.Scope.LabelSelector {
+			// The GVR is both managed and referenced, all of its objects
+			// are watched.
+			dependencies[i].Scope.LabelSelector = ""
+		}
+		switch {
+		case len(dependencies[i].Scope.Namespaces) == 0:
+		case len(dependency.Scope.Namespaces) == 0:
+			// The GVR is watched in every namespace.
+			dependencies[i].Scope.Namespaces = nil
+		default:
+			// The namespaces may be the ones of the watch policy.
+			namespaces := slices.Clone(dependencies[i].Scope.Namespaces)
+			for _, namespace := range dependency.Scope.Namespaces {
+				if !slices.Contains(namespaces, namespace) {
+					namespaces = append(namespaces, namespace)
+				}
+			}
+			dependencies[i].Scope.Namespaces = namespaces
+		}
+	}
+	for _, id := range processedRGD.TopologicalOrder {
+		resource := processedRGD.Resources[id]
+		dependency := dynamiccontroller.Dependency{GVR: resource.GetGroupVersionResource()}
+		if target := resource.GetTarget(); target != nil {
+			dependency.Cluster = clusters[target.KubeconfigSecretRef]
+		}
+		if policy != nil {
+			if resource.IsNamespaced() {
+				dependency.Scope.Namespaces = policy.Namespaces
+			}
+			if !resource.IsExternalRef() {
+				dependency.Scope.LabelSelector = labels.SelectorFromSet(labels.Set{
+					metadata.ResourceGraphDefinitionIDLabel: string(rgd.GetUID()),
+				}).String()
+			}
+		}
+		add(dependency)
+	}
+	for _, source := range rgd.Spec.ValuesFrom {
+		dependency := dynamiccontroller.Dependency{GVR: graph.ConfigMapGVR}
+		reference := source.ConfigMapRef
+		if source.SecretRef != nil {
+			dependency.GVR = graph.SecretGVR
+			reference = source.SecretRef
+		}
+		if policy != nil {
+			// Sources without namespace are read in the namespace of each
+			// instance.
+			dependency.Scope.Namespaces = policy.Namespaces
+			if reference.Namespace != "" {
+				dependency.Scope.Namespaces = []string{reference.Namespace}
+			}
+		}
+		add(dependency)
+	}
+	return instanceScope, dependencies, nil
+}
+
 // Error types for the resourcegraphdefinition controller
 type (
 	graphError           struct{ err error }
@@ -172,6 +580,29 @@ func (e *graphError) Unwrap() error           { return e.err }
 func (e *crdError) Unwrap() error             { return e.err }
 func (e *microControllerError) Unwrap() error { return e.err }
 
+// breakingChangesError is returned when a CRD update contains breaking schema changes.
+type breakingChangesError struct{ report *crdgraph.CompatibilityReport }
+
+func (e *breakingChangesError) Error() string {
+	return fmt.Sprintf("schema changes are not compatible with existing instances (%s), set the %s annotation to \"true\" to apply them",
+		e.report.String(), metadata.AllowBreakingChangesAnnotation)
+}
+
+// crdPendingError is returned while the CRD of a resource graph definition
+// isn't established yet.
+type crdPendingError struct {
+	name string
+	// reason is the reason reported by the API server, if any.
+	reason string
+}
+
+func (e *crdPendingError) Error() string {
+	if e.reason == "" {
+		return fmt.Sprintf("waiting for CRD %s to be established", e.name)
+	}
+	return fmt.Sprintf("waiting for CRD %s to be established: %s", e.name, e.reason)
+}
+
 // Error constructors
 func newGraphError(err error) error           { return &graphError{err} }
 func newCRDError(err error) error             { return &crdError{err} }
@@ -0,0 +1,75 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	kroclient "github.com/kro-run/kro/pkg/client"
+)
+
+func TestGetClientRateLimits(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   *v1alpha1.ReconcilePolicy
+		expected *clientRateLimits
+	}{
+		{name: "no policy", policy: nil, expected: nil},
+		{name: "burst without qps is ignored", policy: &v1alpha1.ReconcilePolicy{ClientBurst: 10}, expected: nil},
+		{name: "qps only", policy: &v1alpha1.ReconcilePolicy{ClientQPS: 5}, expected: &clientRateLimits{QPS: 5, Burst: 5}},
+		{name: "qps and burst", policy: &v1alpha1.ReconcilePolicy{ClientQPS: 20, ClientBurst: 40}, expected: &clientRateLimits{QPS: 20, Burst: 40}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rgd := &v1alpha1.ResourceGraphDefinition{}
+			rgd.Spec.Reconcile = tc.policy
+			assert.Equal(t, tc.expected, getClientRateLimits(rgd))
+		})
+	}
+}
+
+func TestInstanceClientSet(t *testing.T) {
+	clientSet, err := kroclient.NewSet(kroclient.Config{RestConfig: &rest.Config{Host: "https://localhost:6443"}})
+	require.NoError(t, err)
+	r := &ResourceGraphDefinitionReconciler{
+		clientSet:  clientSet,
+		clientSets: make(map[string]*dedicatedClientSet),
+	}
+
+	rgd := &v1alpha1.ResourceGraphDefinition{}
+	rgd.Name = "webapp"
+	got, err := r.instanceClientSet(rgd)
+	require.NoError(t, err)
+	assert.Same(t, clientSet, got)
+
+	// The dedicated client is kept until its limits change.
+	rgd.Spec.Reconcile = &v1alpha1.ReconcilePolicy{ClientQPS: 5}
+	dedicated, err := r.instanceClientSet(rgd)
+	require.NoError(t, err)
+	assert.NotSame(t, clientSet, dedicated)
+	got, err = r.instanceClientSet(rgd)
+	require.NoError(t, err)
+	assert.Same(t, dedicated, got)
+
+	rgd.Spec.Reconcile.ClientBurst = 20
+	got, err = r.instanceClientSet(rgd)
+	require.NoError(t, err)
+	assert.NotSame(t, dedicated, got)
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/jsonschema"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;patch
+
+// schemaConfigMapName returns the name of the ConfigMap publishing the JSON
+// Schema of the instances of the given resource graph definition.
+func schemaConfigMapName(rgd *v1alpha1.ResourceGraphDefinition) string {
+	return rgd.Name + "-schema"
+}
+
+// reconcileSchemaConfigMap publishes the JSON Schema of each version of the
+// instances in a ConfigMap of the schema namespace, keyed by their file
+// name, e.g webapp_v1alpha1.json. The ConfigMap is owned by the resource
+// graph definition, so that it is garbage collected along with it.
+func (r *ResourceGraphDefinitionReconciler) reconcileSchemaConfigMap(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	crd *v1.CustomResourceDefinition,
+	labeler metadata.Labeler,
+) error {
+	documents, err := jsonschema.GenerateFromCRD(crd)
+	if err != nil {
+		return fmt.Errorf("failed to generate instance JSON schema: %w", err)
+	}
+	data := make(map[string]string, len(documents))
+	for _, document := range documents {
+		data[document.FileName] = string(document.Data)
+	}
+
+	configMap := corev1ac.ConfigMap(schemaConfigMapName(rgd), r.schemaNamespace).
+		WithLabels(labeler.Labels()).
+		WithOwnerReferences(metav1ac.OwnerReference().
+			WithAPIVersion(v1alpha1.GroupVersion.String()).
+			WithKind("ResourceGraphDefinition").
+			WithName(rgd.Name).
+			WithUID(rgd.UID)).
+		WithData(data)
+	_, err = r.clientSet.Kubernetes().CoreV1().ConfigMaps(r.schemaNamespace).Apply(ctx, configMap,
+		metav1.ApplyOptions{FieldManager: r.fieldManager, Force: true})
+	if err != nil {
+		return fmt.Errorf("failed to apply instance JSON schema ConfigMap: %w", err)
+	}
+	return nil
+}
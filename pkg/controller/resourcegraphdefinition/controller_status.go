@@ -17,14 +17,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/go-logr/logr"
 	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/dynamiccontroller"
 	"github.com/kro-run/kro/pkg/metadata"
 )
 
@@ -68,6 +72,21 @@ func (sp *StatusProcessor) processCRDError(err error) {
 		newCustomResourceDefinitionSyncedCondition(metav1.ConditionFalse, err.Error()),
 		newReconcilerReadyCondition(metav1.ConditionUnknown, "CRD not-synced"),
 	}
+	var breakingChangesErr *breakingChangesError
+	if errors.As(err, &breakingChangesErr) {
+		sp.conditions = append(sp.conditions, newSchemaCompatibleCondition(metav1.ConditionFalse, breakingChangesErr.report.String()))
+	}
+	sp.state = v1alpha1.ResourceGraphDefinitionStateInactive
+}
+
+// processCRDPending handles CRDs that aren't established yet
+func (sp *StatusProcessor) processCRDPending(err error) {
+	sp.conditions = []v1alpha1.Condition{
+		newGraphVerifiedCondition(metav1.ConditionTrue, ""),
+		v1alpha1.NewCondition(v1alpha1.ResourceGraphDefinitionConditionTypeCustomResourceDefinitionSynced,
+			metav1.ConditionFalse, "CRDPending", err.Error()),
+		newReconcilerReadyCondition(metav1.ConditionUnknown, "CRD pending"),
+	}
 	sp.state = v1alpha1.ResourceGraphDefinitionStateInactive
 }
 
@@ -88,6 +107,8 @@ func (r *ResourceGraphDefinitionReconciler) setResourceGraphDefinitionStatus(
 	resourcegraphdefinition *v1alpha1.ResourceGraphDefinition,
 	topologicalOrder []string,
 	resources []v1alpha1.ResourceInformation,
+	renderedGraph string,
+	deletion *v1alpha1.DeletionStatus,
 	reconcileErr error,
 ) error {
 	log, _ := logr.FromContext(ctx)
@@ -97,16 +118,26 @@ func (r *ResourceGraphDefinitionReconciler) setResourceGraphDefinitionStatus(
 
 	if reconcileErr == nil {
 		processor.setDefaultConditions()
+		gvr := metadata.GetResourceGraphDefinitionInstanceGVR(
+			resourcegraphdefinition.Spec.Schema.Group,
+			resourcegraphdefinition.Spec.Schema.APIVersion,
+			resourcegraphdefinition.Spec.Schema.Kind,
+		)
+		processor.conditions = append(processor.conditions,
+			newWatchesHealthyCondition(r.dynamicController.WatchFailures(gvr)))
 	} else {
 		log.V(1).Info("processing reconciliation error", "error", reconcileErr)
 
 		var graphErr *graphError
+		var crdPendingErr *crdPendingError
 		var crdErr *crdError
 		var microControllerErr *microControllerError
 
 		switch {
 		case errors.As(reconcileErr, &graphErr):
 			processor.processGraphError(reconcileErr)
+		case errors.As(reconcileErr, &crdPendingErr):
+			processor.processCRDPending(reconcileErr)
 		case errors.As(reconcileErr, &crdErr):
 			processor.processCRDError(reconcileErr)
 		case errors.As(reconcileErr, &microControllerErr):
@@ -117,6 +148,13 @@ func (r *ResourceGraphDefinitionReconciler) setResourceGraphDefinitionStatus(
 		}
 	}
 
+	// The warnings are those of the graph built for the current generation,
+	// if it could be built.
+	var warnings []string
+	if processedRGD, ok := r.graphs.Get(resourcegraphdefinition); ok {
+		warnings = processedRGD.Warnings
+	}
+
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Get fresh copy to avoid conflicts
 		current := &v1alpha1.ResourceGraphDefinition{}
@@ -130,6 +168,9 @@ func (r *ResourceGraphDefinitionReconciler) setResourceGraphDefinitionStatus(
 		dc.Status.State = processor.state
 		dc.Status.TopologicalOrder = topologicalOrder
 		dc.Status.Resources = resources
+		dc.Status.Graph = renderedGraph
+		dc.Status.Deletion = deletion
+		dc.Status.Warnings = warnings
 
 		log.V(1).Info("updating resource graph definition status",
 			"state", dc.Status.State,
@@ -140,6 +181,123 @@ func (r *ResourceGraphDefinitionReconciler) setResourceGraphDefinitionStatus(
 	})
 }
 
+// rolloutPollInterval is the interval at which the progress of the rollout of
+// a resource graph definition is checked.
+const rolloutPollInterval = 2 * time.Second
+
+// trackRollout reports the progress of the rollout of the current generation
+// of the resource graph definition in its InstancesReconciled condition, until
+// all its instances are reconciled or the generation changes. It replaces the
+// tracking of the previous generation.
+func (r *ResourceGraphDefinitionReconciler) trackRollout(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) {
+	log := ctrl.LoggerFrom(ctx)
+	gvr := metadata.GetResourceGraphDefinitionInstanceGVR(rgd.Spec.Schema.Group, rgd.Spec.Schema.APIVersion, rgd.Spec.Schema.Kind)
+
+	r.stopTrackingRollout(rgd.Name)
+	ctx, cancel := context.WithCancel(ctx)
+	r.rolloutsMu.Lock()
+	r.rollouts[rgd.Name] = cancel
+	r.rolloutsMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(rolloutPollInterval)
+		defer ticker.Stop()
+
+		lastReconciled := -1
+		for {
+			reconciled, total := r.dynamicController.RolloutProgress(gvr)
+			if reconciled != lastReconciled {
+				current, err := r.setInstancesReconciledCondition(ctx, rgd, reconciled, total)
+				if err != nil {
+					log.Error(err, "failed to report rollout progress")
+				} else if !current {
+					return
+				} else {
+					lastReconciled = reconciled
+				}
+			}
+			if reconciled == total {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopTrackingRollout stops tracking the rollout of the resource graph
+// definition with the given name.
+func (r *ResourceGraphDefinitionReconciler) stopTrackingRollout(name string) {
+	r.rolloutsMu.Lock()
+	defer r.rolloutsMu.Unlock()
+	if cancel, ok := r.rollouts[name]; ok {
+		cancel()
+		delete(r.rollouts, name)
+	}
+}
+
+// setInstancesReconciledCondition sets the InstancesReconciled condition of
+// the resource graph definition. It returns false, without updating it, if the
+// generation of the resource graph definition changed.
+func (r *ResourceGraphDefinitionReconciler) setInstancesReconciledCondition(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	reconciled, total int,
+) (bool, error) {
+	condition := newInstancesReconciledCondition(rgd.Generation, reconciled, total)
+	current := true
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &v1alpha1.ResourceGraphDefinition{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(rgd), latest); err != nil {
+			return fmt.Errorf("failed to get current resource graph definition: %w", err)
+		}
+		if latest.Generation != rgd.Generation {
+			current = false
+			return nil
+		}
+
+		dc := latest.DeepCopy()
+		dc.Status.Conditions = v1alpha1.SetCondition(dc.Status.Conditions, condition)
+		return r.Status().Patch(ctx, dc, client.MergeFrom(latest))
+	})
+	return current, err
+}
+
+// reportWatchHealth updates the WatchesHealthy condition of the resource graph
+// definition serving the given GVR, whenever one of its watches starts failing
+// or recovers.
+func (r *ResourceGraphDefinitionReconciler) reportWatchHealth(gvr schema.GroupVersionResource) {
+	ctx := context.Background()
+	log := r.instanceLogger.WithName("rgd-controller").WithValues("gvr", gvr)
+
+	var rgds v1alpha1.ResourceGraphDefinitionList
+	if err := r.List(ctx, &rgds); err != nil {
+		log.Error(err, "failed to list resource graph definitions")
+		return
+	}
+	for _, rgd := range rgds.Items {
+		if metadata.GetResourceGraphDefinitionInstanceGVR(rgd.Spec.Schema.Group, rgd.Spec.Schema.APIVersion, rgd.Spec.Schema.Kind) != gvr {
+			continue
+		}
+		condition := newWatchesHealthyCondition(r.dynamicController.WatchFailures(gvr))
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			current := &v1alpha1.ResourceGraphDefinition{}
+			if err := r.Get(ctx, client.ObjectKeyFromObject(&rgd), current); err != nil {
+				return fmt.Errorf("failed to get current resource graph definition: %w", err)
+			}
+			dc := current.DeepCopy()
+			dc.Status.Conditions = v1alpha1.SetCondition(dc.Status.Conditions, condition)
+			return r.Status().Patch(ctx, dc, client.MergeFrom(current))
+		})
+		if err != nil {
+			log.Error(err, "failed to report watch health", "name", rgd.Name)
+		}
+	}
+}
+
 // setManaged sets the resourcegraphdefinition as managed, by adding the
 // default finalizer if it doesn't exist.
 func (r *ResourceGraphDefinitionReconciler) setManaged(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) error {
@@ -181,3 +339,37 @@ func newGraphVerifiedCondition(status metav1.ConditionStatus, reason string) v1a
 func newCustomResourceDefinitionSyncedCondition(status metav1.ConditionStatus, reason string) v1alpha1.Condition {
 	return v1alpha1.NewCondition(v1alpha1.ResourceGraphDefinitionConditionTypeCustomResourceDefinitionSynced, status, reason, "Custom Resource Definition is synced")
 }
+
+func newInstancesReconciledCondition(generation int64, reconciled, total int) v1alpha1.Condition {
+	status, reason := metav1.ConditionFalse, "RolloutInProgress"
+	if reconciled == total {
+		status, reason = metav1.ConditionTrue, "RolloutComplete"
+	}
+	condition := v1alpha1.NewCondition(v1alpha1.ResourceGraphDefinitionConditionTypeInstancesReconciled, status, reason,
+		fmt.Sprintf("%d/%d instances reconciled with generation %d", reconciled, total, generation))
+	condition.ObservedGeneration = generation
+	return condition
+}
+
+func newWatchesHealthyCondition(failures []dynamiccontroller.WatchFailure) v1alpha1.Condition {
+	if len(failures) == 0 {
+		return v1alpha1.NewCondition(v1alpha1.ResourceGraphDefinitionConditionTypeWatchesHealthy, metav1.ConditionTrue, "", "Watches are healthy")
+	}
+	messages := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		watch := failure.GVR.String()
+		if failure.Namespace != "" {
+			watch += " in namespace " + failure.Namespace
+		}
+		if failure.Cluster != "" {
+			watch += " of cluster " + failure.Cluster
+		}
+		messages = append(messages, fmt.Sprintf("watch of %s failing since %s (%d failures): %s",
+			watch, failure.Since.UTC().Format(time.RFC3339), failure.Failures, failure.Message))
+	}
+	return v1alpha1.NewCondition(v1alpha1.ResourceGraphDefinitionConditionTypeWatchesHealthy, metav1.ConditionFalse, "WatchFailed", strings.Join(messages, "; "))
+}
+
+func newSchemaCompatibleCondition(status metav1.ConditionStatus, reason string) v1alpha1.Condition {
+	return v1alpha1.NewCondition(v1alpha1.ResourceGraphDefinitionConditionTypeSchemaCompatible, status, reason, "Schema is compatible with existing instances")
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlrtcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph"
+)
+
+// webhookKinds are the kinds of the instances of a resource graph definition
+// registered with the webhooks.
+type webhookKinds struct {
+	defaulted []schema.GroupVersionKind
+	converted []schema.GroupKind
+}
+
+// setupWebhooksWithManager sets up the controller registering the instance
+// schemas of the resource graph definitions with the webhooks. Unlike the
+// ResourceGraphDefinition controller, it runs on every replica, leader or not,
+// as the webhooks are served by all of them. It doesn't filter the resource
+// graph definitions by the selector either, so that any replica can serve
+// the instances of any of them.
+func (r *ResourceGraphDefinitionReconciler) setupWebhooksWithManager(mgr ctrl.Manager) error {
+	if r.defaultingWebhook == nil && r.conversionWebhook == nil {
+		return nil
+	}
+	needLeaderElection := false
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("ResourceGraphDefinitionWebhooks").
+		For(&v1alpha1.ResourceGraphDefinition{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&v1alpha1.ResourceGraphTypeLibrary{},
+			handler.EnqueueRequestsFromMapFunc(r.findAllResourceGraphDefinitions),
+		).
+		WithOptions(ctrlrtcontroller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := mgr.GetLogger().WithName("rgd-webhooks")
+				if req != nil {
+					log = log.WithValues("name", req.Name)
+				}
+				return log
+			},
+			NeedLeaderElection: &needLeaderElection,
+		}).
+		Complete(reconcile.Func(r.reconcileWebhooks))
+}
+
+// instanceDefaulter sets the defaults computed by expressions of the
+// instances at admission. The ones of resource graph definitions loading
+// values are left to the instance controller, as the values are read with the
+// identity the instances are reconciled with.
+type instanceDefaulter struct {
+	graph *graph.Graph
+}
+
+func (d instanceDefaulter) Default(_ context.Context, instance *unstructured.Unstructured) error {
+	if len(d.graph.ValuesFrom) > 0 {
+		return nil
+	}
+	_, err := d.graph.ApplyDefaults(instance)
+	return err
+}
+
+// findAllResourceGraphDefinitions returns a request for every resource graph
+// definition, once the types of a type library change.
+func (r *ResourceGraphDefinitionReconciler) findAllResourceGraphDefinitions(
+	ctx context.Context,
+	_ client.Object,
+) []reconcile.Request {
+	r.graphs.Reset()
+	var rgds v1alpha1.ResourceGraphDefinitionList
+	if err := r.List(ctx, &rgds); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list resource graph definitions")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(rgds.Items))
+	for _, rgd := range rgds.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: rgd.Name}})
+	}
+	return requests
+}
+
+// reconcileWebhooks registers the instance schema of the resource graph
+// definition with the webhooks, and unregisters it once the resource graph
+// definition is gone. The schema of the previous generation is kept while
+// the graph of the current one can't be built.
+func (r *ResourceGraphDefinitionReconciler) reconcileWebhooks(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
+	var rgd v1alpha1.ResourceGraphDefinition
+	if err := r.Get(ctx, req.NamespacedName, &rgd); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.unregisterWebhooks(req.Name)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	processedRGD, _, err := r.reconcileResourceGraphDefinitionGraph(ctx, &rgd)
+	if err != nil {
+		var graphErr *graphError
+		if errors.As(err, &graphErr) {
+			// It's reported by the ResourceGraphDefinition controller, and
+			// the resource graph definition has to change to fix it.
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if err := r.registerWebhooks(rgd.Name, processedRGD); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// registerWebhooks registers the instance schema of the given graph with the
+// webhooks, and unregisters the kinds of the resource graph definition with
+// the given name it no longer serves.
+func (r *ResourceGraphDefinitionReconciler) registerWebhooks(name string, processedRGD *graph.Graph) error {
+	crd := processedRGD.Instance.GetCRD()
+	var kinds webhookKinds
+	if r.defaultingWebhook != nil {
+		for _, version := range crd.Spec.Versions {
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			if err := r.defaultingWebhook.Register(gvk, crd, instanceDefaulter{graph: processedRGD}); err != nil {
+				return fmt.Errorf("failed to register %s with the defaulting webhook: %w", gvk, err)
+			}
+			kinds.defaulted = append(kinds.defaulted, gvk)
+		}
+	}
+	if r.conversionWebhook != nil && processedRGD.Converter != nil && processedRGD.Converter.RequiresWebhook() {
+		gk := schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+		r.conversionWebhook.Register(gk, processedRGD.Converter)
+		kinds.converted = append(kinds.converted, gk)
+	}
+
+	r.webhookKindsMu.Lock()
+	defer r.webhookKindsMu.Unlock()
+	previous := r.webhookKinds[name]
+	for _, gvk := range previous.defaulted {
+		if !slices.Contains(kinds.defaulted, gvk) {
+			r.defaultingWebhook.Unregister(gvk)
+		}
+	}
+	for _, gk := range previous.converted {
+		if !slices.Contains(kinds.converted, gk) {
+			r.conversionWebhook.Unregister(gk)
+		}
+	}
+	r.webhookKinds[name] = kinds
+	return nil
+}
+
+// unregisterWebhooks unregisters the kinds of the resource graph definition
+// with the given name from the webhooks.
+func (r *ResourceGraphDefinitionReconciler) unregisterWebhooks(name string) {
+	r.webhookKindsMu.Lock()
+	defer r.webhookKindsMu.Unlock()
+	kinds := r.webhookKinds[name]
+	for _, gvk := range kinds.defaulted {
+		r.defaultingWebhook.Unregister(gvk)
+	}
+	for _, gk := range kinds.converted {
+		r.conversionWebhook.Unregister(gk)
+	}
+	delete(r.webhookKinds, name)
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update;delete
+
+const (
+	// leasePrefix prefixes the name of the resource graph definitions in the
+	// name of their lease.
+	leasePrefix = "kro-rgd-"
+
+	leaseDuration      = 15 * time.Second
+	leaseRenewDeadline = 10 * time.Second
+	leaseRetryPeriod   = 2 * time.Second
+)
+
+// LeaseConfig configures the leases of the resource graph definitions. With
+// them, each resource graph definition is reconciled, and its instances
+// served, by the replica holding its lease, rather than all of them by the
+// leader of the replicas.
+type LeaseConfig struct {
+	// Namespace is the namespace of the leases, named kro-rgd-<name>.
+	Namespace string
+	// Identity identifies the replica holding the leases. It must be unique
+	// across the replicas of every deployment competing for them.
+	Identity string
+}
+
+// leases runs the elections of the leases of the resource graph definitions.
+// An election starts the first time the lease of a resource graph definition
+// is checked, and runs until it's released, so that the lease is taken over
+// when its holder stops renewing it.
+type leases struct {
+	log    logr.Logger
+	config LeaseConfig
+	client coordinationv1client.LeasesGetter
+	// acquired is called with the name of a resource graph definition once
+	// its lease is acquired, and lost once the lease is lost or released. The
+	// context of acquired is cancelled when the lease is lost.
+	acquired func(ctx context.Context, name string)
+	lost     func(ctx context.Context, name string)
+
+	// elections are keyed by the name of the resource graph definitions.
+	// elections and stopped are guarded by mu.
+	elections map[string]*election
+	stopped   bool
+	mu        sync.Mutex
+}
+
+// election is the election of the lease of a resource graph definition.
+type election struct {
+	cancel context.CancelFunc
+	held   atomic.Bool
+}
+
+func newLeases(
+	log logr.Logger,
+	config LeaseConfig,
+	client coordinationv1client.LeasesGetter,
+	acquired func(ctx context.Context, name string),
+	lost func(ctx context.Context, name string),
+) *leases {
+	return &leases{
+		log:       log,
+		config:    config,
+		client:    client,
+		acquired:  acquired,
+		lost:      lost,
+		elections: make(map[string]*election),
+	}
+}
+
+// Held returns whether this replica holds the lease of the resource graph
+// definition with the given name, and starts its election if it isn't
+// running yet.
+func (l *leases) Held(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return false
+	}
+	e, ok := l.elections[name]
+	if !ok {
+		e = l.run(name)
+		l.elections[name] = e
+	}
+	return e.held.Load()
+}
+
+// Release ends the election of the lease of the resource graph definition
+// with the given name, and releases the lease if it's held.
+func (l *leases) Release(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.elections[name]; ok {
+		e.cancel()
+		delete(l.elections, name)
+	}
+}
+
+// Delete ends the election of the lease of the resource graph definition with
+// the given name, and deletes the lease once the resource graph definition is
+// deleted. The replicas still competing for it delete it again once they
+// acquire it.
+func (l *leases) Delete(ctx context.Context, name string) error {
+	l.Release(name)
+	err := l.client.Leases(l.config.Namespace).Delete(ctx, leasePrefix+name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// Start waits for the given context to be done, and then releases the leases,
+// so that the other replicas take them over without waiting for them to
+// expire.
+func (l *leases) Start(ctx context.Context) error {
+	<-ctx.Done()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopped = true
+	for name, e := range l.elections {
+		e.cancel()
+		delete(l.elections, name)
+	}
+	return nil
+}
+
+// run starts the election of the lease of the resource graph definition with
+// the given name.
+func (l *leases) run(name string) *election {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &election{cancel: cancel}
+	log := l.log.WithValues("name", name)
+
+	config := leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Namespace: l.config.Namespace,
+				Name:      leasePrefix + name,
+			},
+			Client:     l.client,
+			LockConfig: resourcelock.ResourceLockConfig{Identity: l.config.Identity},
+		},
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   leaseRenewDeadline,
+		RetryPeriod:     leaseRetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            leasePrefix + name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Info("acquired lease")
+				e.held.Store(true)
+				l.acquired(ctx, name)
+			},
+			// OnStoppedLeading is called whenever an election ends, even
+			// if the lease was never held.
+			OnStoppedLeading: func() {
+				if e.held.Swap(false) {
+					log.Info("lost lease")
+					l.lost(logr.NewContext(context.Background(), log), name)
+				}
+			},
+		},
+	}
+
+	go func() {
+		// An election ends once the lease is lost, keep competing for it
+		// until it's released.
+		for ctx.Err() == nil {
+			elector, err := leaderelection.NewLeaderElector(config)
+			if err != nil {
+				log.Error(err, "failed to create lease elector")
+				return
+			}
+			elector.Run(ctx)
+		}
+	}()
+	return e
+}
+
+// selects returns whether the given resource graph definition is reconciled
+// by this deployment, as it matches its selector.
+func (r *ResourceGraphDefinitionReconciler) selects(o client.Object) bool {
+	return r.selector == nil || r.selector.Matches(labels.Set(o.GetLabels()))
+}
+
+// leaseAcquired queues the resource graph definition with the given name once
+// its lease is acquired, to serve its instances. The lease is deleted if the
+// resource graph definition is gone.
+func (r *ResourceGraphDefinitionReconciler) leaseAcquired(ctx context.Context, name string) {
+	var rgd v1alpha1.ResourceGraphDefinition
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, &rgd); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Deleting the lease ends the election, cancelling ctx.
+			if err := r.leases.Delete(context.WithoutCancel(ctx), name); err != nil {
+				ctrl.LoggerFrom(ctx).Error(err, "failed to delete lease", "name", name)
+			}
+			return
+		}
+		// Queue it anyway, the reconciliation gets it again.
+		rgd.Name = name
+	}
+	select {
+	case r.leaseEvents <- event.GenericEvent{Object: &rgd}:
+	case <-ctx.Done():
+	}
+}
+
+// stopServing stops serving the instances of the resource graph definition
+// with the given name, leaving its CRD and its instances in place, once its
+// lease is lost or it's no longer selected.
+func (r *ResourceGraphDefinitionReconciler) stopServing(ctx context.Context, name string) {
+	r.stopTrackingRollout(name)
+	r.stopTrackingCRD(name)
+	for _, gvr := range r.dynamicController.ServedGVRs(name) {
+		if err := r.shutdownResourceGraphDefinitionMicroController(ctx, &gvr); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to stop serving instances", "gvr", gvr)
+		}
+	}
+}
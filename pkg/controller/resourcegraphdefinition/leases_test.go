@@ -0,0 +1,83 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLeases(t *testing.T) {
+	client := kubefake.NewSimpleClientset().CoordinationV1()
+
+	var mu sync.Mutex
+	events := []string{}
+	record := func(event string) func(context.Context, string) {
+		return func(_ context.Context, name string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event+" "+name)
+		}
+	}
+	newReplica := func(identity string) *leases {
+		return newLeases(logr.Discard(), LeaseConfig{Namespace: "kro-system", Identity: identity}, client,
+			record(identity+" acquired"), record(identity+" lost"))
+	}
+	a, b := newReplica("a"), newReplica("b")
+
+	require.Eventually(t, func() bool { return a.Held("webapp") }, 5*time.Second, 10*time.Millisecond)
+	lease, err := client.Leases("kro-system").Get(context.Background(), "kro-rgd-webapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", *lease.Spec.HolderIdentity)
+	assert.Never(t, func() bool { return b.Held("webapp") }, 500*time.Millisecond, 10*time.Millisecond)
+
+	// The lease is taken over once released.
+	a.Release("webapp")
+	require.Eventually(t, func() bool { return b.Held("webapp") }, 10*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, b.Start(ctx))
+	assert.False(t, b.Held("webapp"))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"a acquired webapp", "a lost webapp", "b acquired webapp", "b lost webapp"}, events)
+}
+
+func TestLeasesDelete(t *testing.T) {
+	client := kubefake.NewSimpleClientset().CoordinationV1()
+	noop := func(context.Context, string) {}
+	l := newLeases(logr.Discard(), LeaseConfig{Namespace: "kro-system", Identity: "a"}, client, noop, noop)
+
+	require.Eventually(t, func() bool { return l.Held("webapp") }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, l.Delete(context.Background(), "webapp"))
+	_, err := client.Leases("kro-system").Get(context.Background(), "kro-rgd-webapp", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NotContains(t, l.elections, "webapp")
+
+	// Deleting a lease that doesn't exist succeeds.
+	require.NoError(t, l.Delete(context.Background(), "webapp"))
+}
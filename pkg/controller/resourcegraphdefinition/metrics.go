@@ -0,0 +1,67 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// MetricGraphBuildDuration tracks the duration of the builds of the
+	// graphs of the resource graph definitions
+	MetricGraphBuildDuration = "rgd_graph_build_duration_seconds"
+	// MetricCRDReadyDuration tracks the time the CRDs of the resource graph
+	// definitions take to be applied and established
+	MetricCRDReadyDuration = "rgd_crd_ready_duration_seconds"
+	// MetricGraphCacheLookups counts the lookups of the graphs of the
+	// resource graph definitions in the graph cache, by result
+	MetricGraphCacheLookups = "rgd_graph_cache_lookups_total"
+)
+
+var (
+	graphBuildDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    MetricGraphBuildDuration,
+			Help:    "Duration of the builds of the graph of a resource graph definition",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"rgd"},
+	)
+
+	crdReadyDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    MetricCRDReadyDuration,
+			Help:    "Time the CRD of a resource graph definition takes to be applied and established",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"rgd"},
+	)
+
+	graphCacheLookups = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: MetricGraphCacheLookups,
+			Help: "Number of lookups of the graph of a resource graph definition in the graph cache, by result",
+		},
+		[]string{"rgd", "result"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		graphBuildDuration,
+		crdReadyDuration,
+		graphCacheLookups,
+	)
+}
@@ -0,0 +1,292 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+// DebugPath is the path the debug report of the controller is served at.
+const DebugPath = "/debug/dynamic-controller"
+
+// DebugReport is a snapshot of the internals of the controller, to tell why
+// the instances of a GVR aren't reconciled, e.g because its informer isn't
+// running or its queue is backed up.
+type DebugReport struct {
+	// GVRs are the GVRs served by the controller, sorted.
+	GVRs []GVRReport `json:"gvrs"`
+	// Dependencies are the watches of the objects the instances refer to,
+	// sorted by GVR and cluster.
+	Dependencies []DependencyReport `json:"dependencies"`
+	// Queues are the workqueues of the controller, the shared queue first.
+	Queues []QueueReport `json:"queues"`
+}
+
+// GVRReport is the state of a GVR served by the controller.
+type GVRReport struct {
+	GVR string `json:"gvr"`
+	// ResourceGraphDefinition is the name of the resource graph definition
+	// serving the GVR.
+	ResourceGraphDefinition string `json:"resourceGraphDefinition,omitempty"`
+	// Handler tells whether a handler is registered for the GVR. Its items
+	// are dropped without one.
+	Handler bool `json:"handler"`
+	// Informers are the informers of the GVR, one per namespace of its
+	// scope. It's empty if the GVR isn't watched.
+	Informers []InformerReport `json:"informers"`
+	// Queue is the name of the queue the items of the GVR are processed from.
+	Queue string `json:"queue"`
+	// Reconciled is the number of instances reconciled with the current
+	// handler, out of the Total instances that existed when it was set.
+	Reconciled int `json:"reconciled"`
+	Total      int `json:"total"`
+}
+
+// DependencyReport is the state of the watch of the objects of a GVR the
+// instances of other GVRs refer to.
+type DependencyReport struct {
+	GVR string `json:"gvr"`
+	// Cluster is the remote cluster the objects are watched in, empty for
+	// the cluster of the controller.
+	Cluster string `json:"cluster,omitempty"`
+	// Parents are the served GVRs whose instances refer to the objects.
+	Parents   []string         `json:"parents"`
+	Informers []InformerReport `json:"informers"`
+}
+
+// InformerReport is the state of an informer.
+type InformerReport struct {
+	// Namespace is the namespace the informer watches, empty for all of them.
+	Namespace     string `json:"namespace,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// Synced tells whether the cache of the informer has synced.
+	Synced bool `json:"synced"`
+	// Objects is the number of objects in the cache of the informer.
+	Objects int `json:"objects"`
+	// Failures is the number of consecutive failures of the watch of the
+	// informer, and LastError the last of them.
+	Failures  int    `json:"failures,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// QueueReport is the state of a workqueue.
+type QueueReport struct {
+	Name string `json:"name"`
+	// Depth is the number of items waiting to be processed, not counting
+	// the items waiting for their next retry.
+	Depth int `json:"depth"`
+	// Workers is the number of workers of a dedicated queue. The shared
+	// queue is processed by the workers of the configuration.
+	Workers int `json:"workers"`
+}
+
+// sharedQueueName is the name of the shared queue in the debug report.
+const sharedQueueName = "shared"
+
+// Debug returns a snapshot of the internals of the controller.
+func (dc *DynamicController) Debug() DebugReport {
+	report := DebugReport{
+		GVRs:         []GVRReport{},
+		Dependencies: []DependencyReport{},
+		Queues:       []QueueReport{{Name: sharedQueueName, Depth: dc.queue.Len(), Workers: dc.config.Workers}},
+	}
+
+	// The GVRs are either watched, or have a handler while their informer
+	// starts.
+	served := map[schema.GroupVersionResource]struct{}{}
+	dc.informers.Range(func(key, _ interface{}) bool {
+		served[key.(schema.GroupVersionResource)] = struct{}{}
+		return true
+	})
+	dc.handlers.Range(func(key, _ interface{}) bool {
+		served[key.(schema.GroupVersionResource)] = struct{}{}
+		return true
+	})
+	for gvr := range served {
+		gvrReport := GVRReport{GVR: gvr.String(), Queue: sharedQueueName, Informers: []InformerReport{}}
+		if name, ok := dc.names.Load(gvr); ok {
+			gvrReport.ResourceGraphDefinition = name.(string)
+		}
+		if entry, ok := dc.handlers.Load(gvr); ok {
+			handlerEntry := entry.(*handlerEntry)
+			handlerEntry.mu.RLock()
+			gvrReport.Handler = handlerEntry.handler != nil
+			handlerEntry.mu.RUnlock()
+		}
+		if wrapper, ok := dc.informers.Load(gvr); ok {
+			gvrReport.Informers = dc.informerReports(gvr, wrapper.(*informerWrapper))
+		}
+		if dedicated, ok := dc.dedicatedQueues.Load(gvr); ok {
+			gvrReport.Queue = gvr.String()
+			report.Queues = append(report.Queues, QueueReport{
+				Name:    gvr.String(),
+				Depth:   dedicated.(*dedicatedQueue).queue.Len(),
+				Workers: dedicated.(*dedicatedQueue).workers,
+			})
+		}
+		gvrReport.Reconciled, gvrReport.Total = dc.RolloutProgress(gvr)
+		report.GVRs = append(report.GVRs, gvrReport)
+	}
+
+	dc.dependenciesMu.Lock()
+	for key, watch := range dc.dependencies {
+		dependency := DependencyReport{
+			GVR:       key.gvr.String(),
+			Cluster:   watch.informer.cluster,
+			Parents:   []string{},
+			Informers: dc.informerReports(key.gvr, watch.informer),
+		}
+		for parent := range watch.parents {
+			dependency.Parents = append(dependency.Parents, parent.String())
+		}
+		slices.Sort(dependency.Parents)
+		report.Dependencies = append(report.Dependencies, dependency)
+	}
+	dc.dependenciesMu.Unlock()
+
+	slices.SortFunc(report.GVRs, func(a, b GVRReport) int {
+		return strings.Compare(a.GVR, b.GVR)
+	})
+	slices.SortFunc(report.Dependencies, func(a, b DependencyReport) int {
+		if c := strings.Compare(a.GVR, b.GVR); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Cluster, b.Cluster)
+	})
+	slices.SortFunc(report.Queues[1:], func(a, b QueueReport) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return report
+}
+
+// informerReports returns the states of the informers of the given GVR held
+// by the given wrapper.
+func (dc *DynamicController) informerReports(gvr schema.GroupVersionResource, wrapper *informerWrapper) []InformerReport {
+	dc.watchFailuresMu.Lock()
+	defer dc.watchFailuresMu.Unlock()
+
+	reports := make([]InformerReport, 0, len(wrapper.namespaces))
+	for i, namespace := range wrapper.namespaces {
+		report := InformerReport{
+			Namespace:     namespace,
+			LabelSelector: wrapper.labelSelector,
+			Synced:        wrapper.synced[i](),
+			Objects:       len(wrapper.stores[i].ListKeys()),
+		}
+		if failure, ok := dc.watchFailures[watchKey{cluster: wrapper.cluster, gvr: gvr, namespace: namespace}]; ok {
+			report.Failures = failure.Failures
+			report.LastError = failure.Message
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// DebugHandler returns the handler serving the debug report of the
+// controller as JSON. It doesn't authenticate the requests, see
+// WithAuthorization.
+func (dc *DynamicController) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(dc.Debug()); err != nil {
+			dc.log.Error(err, "Failed to write the debug report")
+		}
+	})
+}
+
+// WithAuthorization returns a handler serving the requests with the given
+// handler once the API server authenticated their bearer token, and allowed
+// their user to use their method on their path, like the non-resource URLs of
+// the API server, e.g with a ClusterRole granting `get` on
+// `/debug/dynamic-controller`.
+func WithAuthorization(log logr.Logger, client kubernetes.Interface, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		review, err := client.AuthenticationV1().TokenReviews().Create(req.Context(), &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			log.Error(err, "Failed to review the token of a request", "path", req.URL.Path)
+			http.Error(w, "Authentication failed", http.StatusInternalServerError)
+			return
+		}
+		if !review.Status.Authenticated {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user := review.Status.User
+		extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for key, values := range user.Extra {
+			extra[key] = authorizationv1.ExtraValue(values)
+		}
+		access, err := client.AuthorizationV1().SubjectAccessReviews().Create(req.Context(), &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				UID:    user.UID,
+				Groups: user.Groups,
+				Extra:  extra,
+				NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+					Path: req.URL.Path,
+					Verb: strings.ToLower(req.Method),
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			log.Error(err, "Failed to review the access of a request", "path", req.URL.Path, "user", user.Username)
+			http.Error(w, "Authorization failed", http.StatusInternalServerError)
+			return
+		}
+		if !access.Status.Allowed {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// AuthorizationFilter provides the filter of the metrics server serving the
+// metrics and the debug report with WithAuthorization, so that both are only
+// served to the users the API server allows to get their path.
+func AuthorizationFilter(config *rest.Config, httpClient *http.Client) (metricsserver.Filter, error) {
+	client, err := kubernetes.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return func(log logr.Logger, handler http.Handler) (http.Handler, error) {
+		return WithAuthorization(log, client, handler), nil
+	}, nil
+}
@@ -58,10 +58,13 @@ package dynamiccontroller
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/time/rate"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -130,18 +133,253 @@ type DynamicController struct {
 	// handlers is a safe map of GVR to workflow operators. Each
 	// handler is responsible for managing a specific GVR.
 	handlers sync.Map
+	// rollouts is a map of GVR to the rollout of its current handler. It's
+	// guarded by rolloutsMu.
+	rollouts   map[schema.GroupVersionResource]*rollout
+	rolloutsMu sync.Mutex
+
+	// scopes is a safe map of GVR to the WatchScope its instances are
+	// watched in. GVRs without a scope are watched cluster wide.
+	scopes sync.Map
+
+	// dependencies is a map of GVR and scope to the watch of the objects of
+	// that GVR the instances of other GVRs refer to. It's guarded by
+	// dependenciesMu, like the references below.
+	dependencies   map[dependencyKey]*dependencyWatch
+	dependenciesMu sync.Mutex
+	// references is a map of the objects instances refer to, to the set of
+	// instances referring to them.
+	references map[ObjectIdentifiers]map[ObjectIdentifiers]struct{}
+	// instanceReferences is a map of instances to the objects they refer to.
+	instanceReferences map[ObjectIdentifiers][]ObjectIdentifiers
+
+	// retryPolicies is a safe map of GVR to the backoff policy the items of
+	// that GVR are retried with after an error, instead of the rate limiter.
+	retryPolicies sync.Map
+	// attempts is the number of consecutive retries of the items requeued
+	// with a backoff policy. It's guarded by attemptsMu.
+	attempts   map[ObjectIdentifiers]int
+	attemptsMu sync.Mutex
+
+	// names is a safe map of GVR to the name of the resource graph
+	// definition serving it, which labels the metrics of its items.
+	names sync.Map
 
 	// queue is the workqueue used to process items
-	queue workqueue.TypedRateLimitingInterface[ObjectIdentifiers]
+	queue *itemQueue
+	// dedicatedQueues is a safe map of GVR to the dedicated queue of the
+	// GVRs requesting their own number of workers. The items of these GVRs
+	// are processed by their own workers, rather than the shared ones, so
+	// that a busy GVR can't starve the others. Changes are guarded by
+	// dedicatedQueuesMu, which is read locked while items are added to the
+	// queue of their GVR, so that they aren't added to a queue its items were
+	// moved from.
+	dedicatedQueues   sync.Map
+	dedicatedQueuesMu sync.RWMutex
+	// ctx is the context the controller runs with, nil until it's started.
+	// The dedicated workers are started with it, once the controller runs.
+	// It's guarded by dedicatedQueuesMu.
+	ctx context.Context
+	// dedicatedWorkers tracks the workers of the dedicated queues, which
+	// are waited for on shutdown.
+	dedicatedWorkers sync.WaitGroup
+
+	// watchFailures is a map of the watches failing to their failure. It's
+	// guarded by watchFailuresMu.
+	watchFailures   map[watchKey]*WatchFailure
+	watchFailuresMu sync.Mutex
+	// watchHealthHandler is called whenever a watch starts failing or
+	// recovers.
+	watchHealthHandler func(gvr schema.GroupVersionResource)
 
 	log logr.Logger
 }
 
 type Handler func(ctx context.Context, req ctrl.Request) error
 
+// handlerEntry holds the handler of a GVR. Its lock is read locked while an
+// item is reconciled, so that the handler is only replaced, or removed, once
+// the reconciles in flight are done: no item is reconciled with a previous
+// handler once it's replaced.
+type handlerEntry struct {
+	mu sync.RWMutex
+	// handler is nil once the GVR isn't served anymore.
+	handler Handler
+}
+
+// rollout tracks the items of a GVR that weren't reconciled yet with its
+// current handler.
+type rollout struct {
+	pending map[string]struct{}
+	total   int
+}
+
+// itemQueue is a rate limited queue of items to reconcile. It records the
+// items it holds, including those waiting to be added after a delay, so that
+// the items of a GVR can be moved to another queue.
+type itemQueue struct {
+	workqueue.TypedRateLimitingInterface[ObjectIdentifiers]
+	rateLimiter workqueue.TypedRateLimiter[ObjectIdentifiers]
+
+	// pending maps the items added to the queue, and not taken by a worker
+	// yet, to the time they're ready to be processed. It's guarded by
+	// pendingMu.
+	pending   map[ObjectIdentifiers]time.Time
+	pendingMu sync.Mutex
+}
+
+func (q *itemQueue) Add(item ObjectIdentifiers) {
+	q.track(item, time.Now())
+	q.TypedRateLimitingInterface.Add(item)
+}
+
+func (q *itemQueue) AddAfter(item ObjectIdentifiers, duration time.Duration) {
+	q.track(item, time.Now().Add(duration))
+	q.TypedRateLimitingInterface.AddAfter(item, duration)
+}
+
+func (q *itemQueue) AddRateLimited(item ObjectIdentifiers) {
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+func (q *itemQueue) Get() (ObjectIdentifiers, bool) {
+	item, shutdown := q.TypedRateLimitingInterface.Get()
+	if !shutdown {
+		q.pendingMu.Lock()
+		delete(q.pending, item)
+		q.pendingMu.Unlock()
+	}
+	return item, shutdown
+}
+
+// track records the item as pending until the given time, unless it's ready
+// earlier already.
+func (q *itemQueue) track(item ObjectIdentifiers, readyAt time.Time) {
+	q.pendingMu.Lock()
+	defer q.pendingMu.Unlock()
+	if current, ok := q.pending[item]; !ok || readyAt.Before(current) {
+		q.pending[item] = readyAt
+	}
+}
+
+// moveTo adds the pending items of the given GVR to the given queue, when
+// they're ready. They're left in this queue, whose workers skip them.
+func (q *itemQueue) moveTo(gvr schema.GroupVersionResource, to *itemQueue) {
+	q.pendingMu.Lock()
+	defer q.pendingMu.Unlock()
+	for item, readyAt := range q.pending {
+		if item.GVR != gvr {
+			continue
+		}
+		delete(q.pending, item)
+		if delay := time.Until(readyAt); delay > 0 {
+			to.AddAfter(item, delay)
+		} else {
+			to.Add(item)
+		}
+	}
+}
+
+// informerWrapper holds the informers of a GVR, one per namespace of its
+// scope.
 type informerWrapper struct {
-	informer dynamicinformer.DynamicSharedInformerFactory
-	shutdown func()
+	informers []dynamicinformer.DynamicSharedInformerFactory
+	stores    []cache.Store
+	shutdown  func()
+	// scope is the key of the scope the informers watch.
+	scope string
+	// cluster is the name of the cluster the informers watch, empty for the
+	// cluster of the controller.
+	cluster string
+	// namespaces are the namespaces the informers watch, in order, and
+	// labelSelector the selector of the objects they watch.
+	namespaces    []string
+	labelSelector string
+	// synced report whether the caches of the informers have synced.
+	synced []cache.InformerSynced
+}
+
+// stop stops the informers and waits for them to shut down.
+func (w *informerWrapper) stop() {
+	w.shutdown()
+	for _, informer := range w.informers {
+		informer.Shutdown()
+	}
+}
+
+// keys returns the keys of the objects in the caches of the informers.
+func (w *informerWrapper) keys() []string {
+	var keys []string
+	for _, store := range w.stores {
+		keys = append(keys, store.ListKeys()...)
+	}
+	return keys
+}
+
+// WatchScope restricts the objects of a GVR watched by the controller, so that
+// it doesn't cache every object of the GVR in the cluster.
+type WatchScope struct {
+	// Namespaces are the namespaces the objects are watched in. They're
+	// watched in all the namespaces if it's empty.
+	Namespaces []string
+	// LabelSelector selects the objects watched, e.g `tier=production`. All
+	// the objects are watched if it's empty.
+	LabelSelector string
+}
+
+// key returns a key identifying the scope.
+func (s WatchScope) key() string {
+	return strings.Join(slices.Sorted(slices.Values(s.Namespaces)), ",") + "|" + s.LabelSelector
+}
+
+// namespaces returns the namespaces to watch, the empty namespace standing
+// for all of them.
+func (s WatchScope) namespaces() []string {
+	if len(s.Namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return slices.Compact(slices.Sorted(slices.Values(s.Namespaces)))
+}
+
+// Dependency is a GVR of the objects the instances of another GVR refer to,
+// and the scope they're watched in.
+type Dependency struct {
+	GVR   schema.GroupVersionResource
+	Scope WatchScope
+	// Cluster is the remote cluster the objects are watched in, nil for the
+	// cluster of the controller.
+	Cluster *Cluster
+}
+
+// Cluster is a remote cluster objects are watched in.
+type Cluster struct {
+	// Name identifies the cluster. It's the Cluster of the identifiers of
+	// the objects of the cluster.
+	Name string
+	// Version is the version of the credentials of the cluster. The objects
+	// are watched again with the new client when it changes.
+	Version string
+	// Client is the client of the cluster.
+	Client dynamic.Interface
+}
+
+type dependencyKey struct {
+	gvr     schema.GroupVersionResource
+	scope   string
+	cluster string
+}
+
+// dedicatedQueue is the queue of a GVR processed by its own workers.
+type dedicatedQueue struct {
+	queue   *itemQueue
+	workers int
+}
+
+// dependencyWatch watches the objects of a GVR the instances of other GVRs,
+// its parents, refer to.
+type dependencyWatch struct {
+	informer *informerWrapper
+	parents  map[schema.GroupVersionResource]struct{}
 }
 
 // NewDynamicController creates a new DynamicController instance.
@@ -152,19 +390,92 @@ func NewDynamicController(
 	logger := log.WithName("dynamic-controller")
 
 	dc := &DynamicController{
-		config:     config,
-		kubeClient: kubeClient,
-		queue: workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.NewTypedMaxOfRateLimiter(
-			workqueue.NewTypedItemExponentialFailureRateLimiter[ObjectIdentifiers](config.MinRetryDelay, config.MaxRetryDelay),
-			&workqueue.TypedBucketRateLimiter[ObjectIdentifiers]{Limiter: rate.NewLimiter(rate.Limit(config.RateLimit), config.BurstLimit)},
-		), workqueue.TypedRateLimitingQueueConfig[ObjectIdentifiers]{Name: "dynamic-controller-queue"}),
-		log: logger,
+		config:             config,
+		kubeClient:         kubeClient,
+		dependencies:       make(map[dependencyKey]*dependencyWatch),
+		references:         make(map[ObjectIdentifiers]map[ObjectIdentifiers]struct{}),
+		instanceReferences: make(map[ObjectIdentifiers][]ObjectIdentifiers),
+		attempts:           make(map[ObjectIdentifiers]int),
+		rollouts:           make(map[schema.GroupVersionResource]*rollout),
+		watchFailures:      make(map[watchKey]*WatchFailure),
+		log:                logger,
 		// pass version and pod id from env
 	}
+	dc.queue = newQueue(config, "dynamic-controller-queue", dc.depthGauge)
 
 	return dc
 }
 
+// newQueue returns a queue rate limited following the given configuration,
+// reporting the number of items of each GVR waiting to be processed to the
+// gauge returned by the given function.
+func newQueue(config Config, name string, depth func(gvr schema.GroupVersionResource) prometheus.Gauge) *itemQueue {
+	storage := &depthQueue{Queue: workqueue.DefaultQueue[ObjectIdentifiers](), depth: depth}
+	rateLimiter := workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[ObjectIdentifiers](config.MinRetryDelay, config.MaxRetryDelay),
+		&workqueue.TypedBucketRateLimiter[ObjectIdentifiers]{Limiter: rate.NewLimiter(rate.Limit(config.RateLimit), config.BurstLimit)},
+	)
+	return &itemQueue{
+		TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[ObjectIdentifiers]{
+			Name: name,
+			DelayingQueue: workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[ObjectIdentifiers]{
+				Name:  name,
+				Queue: workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[ObjectIdentifiers]{Name: name, Queue: storage}),
+			}),
+		}),
+		rateLimiter: rateLimiter,
+		pending:     make(map[ObjectIdentifiers]time.Time),
+	}
+}
+
+// depthQueue is the storage of a queue counting the items of each GVR it
+// holds. The items being delayed, e.g until their next retry, are only
+// counted once they're ready to be processed.
+type depthQueue struct {
+	workqueue.Queue[ObjectIdentifiers]
+	depth func(gvr schema.GroupVersionResource) prometheus.Gauge
+}
+
+func (q *depthQueue) Push(item ObjectIdentifiers) {
+	q.Queue.Push(item)
+	q.depth(item.GVR).Inc()
+}
+
+func (q *depthQueue) Pop() ObjectIdentifiers {
+	item := q.Queue.Pop()
+	q.depth(item.GVR).Dec()
+	return item
+}
+
+// SetResourceGraphDefinition sets the name of the resource graph definition
+// serving the given GVR, which labels the metrics of its items. It must be
+// set before the GVR is served.
+func (dc *DynamicController) SetResourceGraphDefinition(gvr schema.GroupVersionResource, name string) {
+	dc.names.Store(gvr, name)
+}
+
+// ServedGVRs returns the GVRs watched for the resource graph definition with
+// the given name.
+func (dc *DynamicController) ServedGVRs(name string) []schema.GroupVersionResource {
+	var gvrs []schema.GroupVersionResource
+	dc.informers.Range(func(key, _ interface{}) bool {
+		gvr := key.(schema.GroupVersionResource)
+		if served, ok := dc.names.Load(gvr); ok && served == name {
+			gvrs = append(gvrs, gvr)
+		}
+		return true
+	})
+	return gvrs
+}
+
+// depthGauge returns the gauge of the number of items of the given GVR
+// waiting to be processed.
+func (dc *DynamicController) depthGauge(gvr schema.GroupVersionResource) prometheus.Gauge {
+	name, _ := dc.names.Load(gvr)
+	rgd, _ := name.(string)
+	return queueDepth.WithLabelValues(gvr.String(), rgd)
+}
+
 // AllInformerHaveSynced checks if all registered informers have synced, returns
 // true if they have.
 func (dc *DynamicController) AllInformerHaveSynced() bool {
@@ -224,32 +535,51 @@ func (dc *DynamicController) Run(ctx context.Context) error {
 	//
 	// TODO(a-hilaly): Allow for dynamic scaling of workers.
 	for i := 0; i < dc.config.Workers; i++ {
-		go wait.UntilWithContext(ctx, dc.worker, time.Second)
+		go wait.UntilWithContext(ctx, func(ctx context.Context) { dc.worker(ctx, dc.queue) }, time.Second)
 	}
+	dc.dedicatedQueuesMu.Lock()
+	dc.ctx = ctx
+	dc.dedicatedQueues.Range(func(_, value interface{}) bool {
+		dedicated := value.(*dedicatedQueue)
+		dc.startDedicatedWorkers(dedicated.queue, dedicated.workers)
+		return true
+	})
+	dc.dedicatedQueuesMu.Unlock()
 
 	<-ctx.Done()
 	return dc.gracefulShutdown(dc.config.ShutdownTimeout)
 }
 
-// worker processes items from the queue.
-func (dc *DynamicController) worker(ctx context.Context) {
-	for dc.processNextWorkItem(ctx) {
+// worker processes items from the given queue, until it's shut down.
+func (dc *DynamicController) worker(ctx context.Context, queue *itemQueue) {
+	for dc.processNextWorkItem(ctx, queue) {
 	}
 }
 
-// processNextWorkItem processes a single item from the queue.
-func (dc *DynamicController) processNextWorkItem(ctx context.Context) bool {
-	item, shutdown := dc.queue.Get()
+// processNextWorkItem processes a single item from the given queue.
+func (dc *DynamicController) processNextWorkItem(ctx context.Context, queue *itemQueue) bool {
+	item, shutdown := queue.Get()
 	if shutdown {
 		return false
 	}
-	defer dc.queue.Done(item)
+	defer queue.Done(item)
 
-	queueLength.Set(float64(dc.queue.Len()))
+	queueLength.Set(float64(dc.queueLen()))
 
-	err := dc.syncFunc(ctx, item)
+	// The items of a GVR that moved to another queue were added to it, they
+	// are left behind in this one.
+	if dc.queueFor(item.GVR) != queue {
+		return true
+	}
+
+	err := dc.syncFunc(withLastAttempt(ctx, dc.lastAttempt(queue, item)), item)
+	// The item is requeued in the current queue of its GVR, which may have
+	// changed while it was processed.
+	dc.dedicatedQueuesMu.RLock()
+	defer dc.dedicatedQueuesMu.RUnlock()
+	queue = dc.queueFor(item.GVR)
 	if err == nil || apierrors.IsNotFound(err) {
-		dc.queue.Forget(item)
+		dc.forget(queue, item)
 		return true
 	}
 
@@ -260,31 +590,195 @@ func (dc *DynamicController) processNextWorkItem(ctx context.Context) bool {
 	case *requeue.NoRequeue:
 		dc.log.Error(typedErr, "Error syncing item, not requeuing", "item", item)
 		requeueTotal.WithLabelValues(gvrKey, "no_requeue").Inc()
-		dc.queue.Forget(item)
+		dc.forget(queue, item)
 	case *requeue.RequeueNeeded:
 		dc.log.V(1).Info("Requeue needed", "item", item, "error", typedErr)
 		requeueTotal.WithLabelValues(gvrKey, "requeue").Inc()
-		dc.queue.Add(item) // Add without rate limiting
+		queue.Add(item) // Add without rate limiting
 	case *requeue.RequeueNeededAfter:
+		// Items resynced after a success start their retries over.
+		if typedErr.Unwrap() == nil {
+			dc.forget(queue, item)
+		}
+		if backoff := typedErr.Backoff(); backoff != nil {
+			requeueTotal.WithLabelValues(gvrKey, "backoff").Inc()
+			dc.requeueWithBackoff(queue, item, *backoff, typedErr)
+			break
+		}
 		dc.log.V(1).Info("Requeue needed after delay", "item", item, "error", typedErr, "delay", typedErr.Duration())
 		requeueTotal.WithLabelValues(gvrKey, "requeue_after").Inc()
-		dc.queue.AddAfter(item, typedErr.Duration())
+		queue.AddAfter(item, typedErr.Duration())
 	default:
+		// Items of GVRs with a retry policy are retried following it.
+		if backoff, ok := dc.retryPolicies.Load(item.GVR); ok {
+			requeueTotal.WithLabelValues(gvrKey, "backoff").Inc()
+			dc.log.Error(err, "Error syncing item, requeuing with backoff", "item", item)
+			dc.requeueWithBackoff(queue, item, backoff.(requeue.Backoff), err)
+			break
+		}
+
 		// Arriving here means we have an unexpected error, we should requeue the item
 		// with rate limiting.
 		requeueTotal.WithLabelValues(gvrKey, "rate_limited").Inc()
-		if dc.queue.NumRequeues(item) < dc.config.QueueMaxRetries {
+		if queue.NumRequeues(item) < dc.config.QueueMaxRetries {
 			dc.log.Error(err, "Error syncing item, requeuing with rate limit", "item", item)
-			dc.queue.AddRateLimited(item)
+			queue.AddRateLimited(item)
 		} else {
 			dc.log.Error(err, "Dropping item from queue after max retries", "item", item)
-			dc.queue.Forget(item)
+			queue.Forget(item)
 		}
 	}
 
 	return true
 }
 
+// lastAttempt returns whether the item is dropped if its processing fails
+// again, as it was retried the maximum number of times.
+func (dc *DynamicController) lastAttempt(queue *itemQueue, item ObjectIdentifiers) bool {
+	if backoff, ok := dc.retryPolicies.Load(item.GVR); ok {
+		dc.attemptsMu.Lock()
+		attempt := dc.attempts[item]
+		dc.attemptsMu.Unlock()
+		maxAttempts := backoff.(requeue.Backoff).MaxAttempts
+		return maxAttempts > 0 && attempt >= maxAttempts
+	}
+	return queue.NumRequeues(item) >= dc.config.QueueMaxRetries
+}
+
+type lastAttemptKey struct{}
+
+func withLastAttempt(ctx context.Context, last bool) context.Context {
+	return context.WithValue(ctx, lastAttemptKey{}, last)
+}
+
+// IsLastAttempt returns whether the item being processed with the given
+// context won't be retried if its processing fails, e.g so that handlers can
+// report the failure as terminal.
+func IsLastAttempt(ctx context.Context) bool {
+	last, _ := ctx.Value(lastAttemptKey{}).(bool)
+	return last
+}
+
+// requeueWithBackoff requeues the item after the delay of its next retry
+// following the given backoff policy, or drops it once it was retried the
+// maximum number of times.
+func (dc *DynamicController) requeueWithBackoff(queue *itemQueue, item ObjectIdentifiers, backoff requeue.Backoff, err error) {
+	dc.attemptsMu.Lock()
+	attempt := dc.attempts[item]
+	dc.attempts[item] = attempt + 1
+	dc.attemptsMu.Unlock()
+
+	if backoff.MaxAttempts > 0 && attempt >= backoff.MaxAttempts {
+		dc.log.Error(err, "Dropping item from queue after max attempts", "item", item, "attempts", attempt)
+		dc.forget(queue, item)
+		return
+	}
+	delay := backoff.Delay(attempt)
+	dc.log.V(1).Info("Requeue needed with backoff", "item", item, "error", err, "delay", delay, "attempt", attempt)
+	queue.AddAfter(item, delay)
+}
+
+// forget stops tracking the retries of the item.
+func (dc *DynamicController) forget(queue *itemQueue, item ObjectIdentifiers) {
+	queue.Forget(item)
+	dc.attemptsMu.Lock()
+	delete(dc.attempts, item)
+	dc.attemptsMu.Unlock()
+}
+
+// SetRetryPolicy sets the backoff policy the items of the given GVR are
+// retried with after an error, instead of the rate limiter. A nil policy
+// restores the rate limiter.
+func (dc *DynamicController) SetRetryPolicy(gvr schema.GroupVersionResource, backoff *requeue.Backoff) {
+	if backoff == nil {
+		dc.retryPolicies.Delete(gvr)
+		return
+	}
+	dc.retryPolicies.Store(gvr, *backoff)
+}
+
+// SetConcurrency sets the number of workers processing the items of the given
+// GVR. GVRs with a number of workers get a dedicated queue, processed by their
+// own workers, instead of the workers shared by the other GVRs. A number of 0
+// moves the GVR back to the shared workers.
+//
+// The items of the GVR pending in its previous queue, including those waiting
+// for a retry, are moved to its new queue.
+func (dc *DynamicController) SetConcurrency(gvr schema.GroupVersionResource, workers int) {
+	dc.dedicatedQueuesMu.Lock()
+	defer dc.dedicatedQueuesMu.Unlock()
+
+	previous := dc.queue
+	var existing *dedicatedQueue
+	if value, ok := dc.dedicatedQueues.Load(gvr); ok {
+		existing = value.(*dedicatedQueue)
+		if existing.workers == workers {
+			return
+		}
+		previous = existing.queue
+	} else if workers <= 0 {
+		return
+	}
+
+	queue := dc.queue
+	if workers > 0 {
+		dc.log.V(1).Info("Starting dedicated workers", "gvr", gvr, "workers", workers)
+		queue = newQueue(dc.config, fmt.Sprintf("dynamic-controller-queue-%s", gvr.String()), dc.depthGauge)
+		dc.dedicatedQueues.Store(gvr, &dedicatedQueue{queue: queue, workers: workers})
+		if dc.ctx != nil {
+			dc.startDedicatedWorkers(queue, workers)
+		}
+	} else {
+		dc.dedicatedQueues.Delete(gvr)
+	}
+	previous.moveTo(gvr, queue)
+	if existing != nil {
+		// The workers exit once they skipped the items left in the queue.
+		existing.queue.ShutDown()
+	}
+}
+
+// startDedicatedWorkers starts the given number of workers processing the
+// given dedicated queue, until it's shut down.
+func (dc *DynamicController) startDedicatedWorkers(queue *itemQueue, workers int) {
+	ctx := dc.ctx
+	dc.dedicatedWorkers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer dc.dedicatedWorkers.Done()
+			defer utilruntime.HandleCrash()
+			dc.worker(ctx, queue)
+		}()
+	}
+}
+
+// queueLen returns the number of items ready to be processed in the shared
+// queue and the dedicated queues.
+func (dc *DynamicController) queueLen() int {
+	length := dc.queue.Len()
+	dc.dedicatedQueues.Range(func(_, value interface{}) bool {
+		length += value.(*dedicatedQueue).queue.Len()
+		return true
+	})
+	return length
+}
+
+// enqueue adds the given item to the queue of its GVR.
+func (dc *DynamicController) enqueue(item ObjectIdentifiers) {
+	dc.dedicatedQueuesMu.RLock()
+	defer dc.dedicatedQueuesMu.RUnlock()
+	dc.queueFor(item.GVR).Add(item)
+}
+
+// queueFor returns the queue of the items of the given GVR: its dedicated
+// queue if it has one, the shared queue otherwise.
+func (dc *DynamicController) queueFor(gvr schema.GroupVersionResource) *itemQueue {
+	if dedicated, ok := dc.dedicatedQueues.Load(gvr); ok {
+		return dedicated.(*dedicatedQueue).queue
+	}
+	return dc.queue
+}
+
 // syncFunc reconciles a single item.
 func (dc *DynamicController) syncFunc(ctx context.Context, oi ObjectIdentifiers) error {
 	gvrKey := fmt.Sprintf("%s/%s/%s", oi.GVR.Group, oi.GVR.Version, oi.GVR.Resource)
@@ -301,25 +795,97 @@ func (dc *DynamicController) syncFunc(ctx context.Context, oi ObjectIdentifiers)
 			"duration", duration)
 	}()
 
-	genericHandler, ok := dc.handlers.Load(oi.GVR)
+	entry, ok := dc.handlers.Load(oi.GVR)
 	if !ok {
-		// NOTE(a-hilaly): this might mean that the GVR is not registered, or the workflow operator
-		// is not found. We should probably handle this in a better way.
-		return fmt.Errorf("no handler found for GVR: %s", gvrKey)
+		// The GVR isn't served anymore, e.g its resource graph definition
+		// was deleted while the item was queued.
+		dc.log.V(1).Info("Dropping item of a GVR that isn't served", "gvr", gvrKey, "namespacedKey", oi.NamespacedKey)
+		return nil
 	}
-
-	// this is worth a panic if it fails...
-	handlerFunc, ok := genericHandler.(Handler)
-	if !ok {
-		return fmt.Errorf("invalid handler type for GVR: %s", gvrKey)
+	handler := entry.(*handlerEntry)
+	handler.mu.RLock()
+	defer handler.mu.RUnlock()
+	if handler.handler == nil {
+		dc.log.V(1).Info("Dropping item of a GVR that isn't served", "gvr", gvrKey, "namespacedKey", oi.NamespacedKey)
+		return nil
 	}
-	err := handlerFunc(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: oi.NamespacedKey}})
+
+	err := handler.handler(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: oi.NamespacedKey}})
 	if err != nil {
 		handlerErrorsTotal.WithLabelValues(gvrKey).Inc()
 	}
+	// Items waiting for their resources were still reconciled with the
+	// current handler.
+	if !isFailure(err) {
+		dc.rolledOut(oi)
+	}
 	return err
 }
 
+// isFailure returns true if the error of a reconcile isn't a requeue.
+func isFailure(err error) bool {
+	switch err.(type) {
+	case nil, *requeue.NoRequeue, *requeue.RequeueNeeded, *requeue.RequeueNeededAfter:
+		return false
+	}
+	return !apierrors.IsNotFound(err)
+}
+
+// setHandler sets the handler of the given GVR. The handler it replaces is
+// removed once the reconciles in flight are done, a nil handler stops serving
+// the GVR.
+func (dc *DynamicController) setHandler(gvr schema.GroupVersionResource, handler Handler) {
+	entry, ok := dc.handlers.Load(gvr)
+	if !ok {
+		if handler != nil {
+			dc.handlers.Store(gvr, &handlerEntry{handler: handler})
+		}
+		return
+	}
+	existing := entry.(*handlerEntry)
+	existing.mu.Lock()
+	existing.handler = handler
+	existing.mu.Unlock()
+	if handler == nil {
+		dc.handlers.Delete(gvr)
+	}
+}
+
+// startRollout starts tracking the reconciles of the given items of the GVR
+// with its current handler.
+func (dc *DynamicController) startRollout(gvr schema.GroupVersionResource, keys []string) {
+	pending := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		pending[key] = struct{}{}
+	}
+	dc.rolloutsMu.Lock()
+	defer dc.rolloutsMu.Unlock()
+	dc.rollouts[gvr] = &rollout{pending: pending, total: len(pending)}
+}
+
+// rolledOut records that the item was reconciled with the current handler of
+// its GVR.
+func (dc *DynamicController) rolledOut(oi ObjectIdentifiers) {
+	dc.rolloutsMu.Lock()
+	defer dc.rolloutsMu.Unlock()
+	if r, ok := dc.rollouts[oi.GVR]; ok {
+		delete(r.pending, oi.NamespacedKey)
+	}
+}
+
+// RolloutProgress returns the number of instances of the given GVR reconciled
+// with its current handler, out of the instances that existed when it was
+// set by StartServingGVK.
+func (dc *DynamicController) RolloutProgress(gvr schema.GroupVersionResource) (reconciled, total int) {
+	dc.rolloutsMu.Lock()
+	defer dc.rolloutsMu.Unlock()
+	r, ok := dc.rollouts[gvr]
+	if !ok {
+		return 0, 0
+	}
+	return r.total - len(r.pending), r.total
+}
+
 // gracefulShutdown performs a graceful shutdown of the controller.
 func (dc *DynamicController) gracefulShutdown(timeout time.Duration) error {
 	dc.log.Info("Starting graceful shutdown")
@@ -332,10 +898,28 @@ func (dc *DynamicController) gracefulShutdown(timeout time.Duration) error {
 		wg.Add(1)
 		go func(informer *informerWrapper) {
 			defer wg.Done()
-			informer.informer.Shutdown()
+			informer.stop()
 		}(value.(*informerWrapper))
 		return true
 	})
+	dc.dependenciesMu.Lock()
+	for _, watch := range dc.dependencies {
+		wg.Add(1)
+		go func(informer *informerWrapper) {
+			defer wg.Done()
+			informer.stop()
+		}(watch.informer)
+	}
+	dc.dependenciesMu.Unlock()
+	dc.dedicatedQueues.Range(func(key, value interface{}) bool {
+		value.(*dedicatedQueue).queue.ShutDown()
+		return true
+	})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dc.dedicatedWorkers.Wait()
+	}()
 
 	// Wait for all informers to shut down or timeout
 	done := make(chan struct{})
@@ -364,6 +948,10 @@ type ObjectIdentifiers struct {
 	// `namespace/name`.
 	NamespacedKey string
 	GVR           schema.GroupVersionResource
+	// Cluster is the name of the remote cluster of the object, empty for the
+	// cluster of the controller. Only the objects instances refer to can be
+	// in a remote cluster.
+	Cluster string
 }
 
 // updateFunc is the update event handler for the GVR informers
@@ -379,7 +967,11 @@ func (dc *DynamicController) updateFunc(old, new interface{}) {
 		return
 	}
 
-	if newObj.GetGeneration() == oldObj.GetGeneration() {
+	// Pausing, resuming or planning an instance doesn't change its
+	// generation, but needs to be reconciled.
+	if newObj.GetGeneration() == oldObj.GetGeneration() &&
+		metadata.IsPaused(newObj) == metadata.IsPaused(oldObj) &&
+		metadata.IsPlanned(newObj) == metadata.IsPlanned(oldObj) {
 		dc.log.V(2).Info("Skipping update due to unchanged generation",
 			"name", newObj.GetName(),
 			"namespace", newObj.GetNamespace(),
@@ -418,85 +1010,325 @@ func (dc *DynamicController) enqueueObject(obj interface{}, eventType string) {
 		"eventType", eventType)
 
 	informerEventsTotal.WithLabelValues(gvr.String(), eventType).Inc()
-	dc.queue.Add(objectIdentifiers)
+	dc.enqueue(objectIdentifiers)
 }
 
-// StartServingGVK registers a new GVK to the informers map safely.
+// SetWatchScope sets the scope the instances of the given GVR are watched in.
+// It applies the next time the GVR is served. A nil scope watches the
+// instances cluster wide.
+func (dc *DynamicController) SetWatchScope(gvr schema.GroupVersionResource, scope *WatchScope) {
+	if scope == nil {
+		dc.scopes.Delete(gvr)
+		return
+	}
+	dc.scopes.Store(gvr, *scope)
+}
+
+// watchScope returns the scope the instances of the given GVR are watched in.
+func (dc *DynamicController) watchScope(gvr schema.GroupVersionResource) WatchScope {
+	if scope, ok := dc.scopes.Load(gvr); ok {
+		return scope.(WatchScope)
+	}
+	return WatchScope{}
+}
+
+// StartServingGVK registers a new GVK to the informers map safely. If the GVK
+// is already served, its handler is replaced once the reconciles in flight
+// with the previous one are done, and all of its instances are reconciled
+// again with the new one. RolloutProgress tells how many were.
 func (dc *DynamicController) StartServingGVK(ctx context.Context, gvr schema.GroupVersionResource, handler Handler) error {
 	dc.log.V(1).Info("Registering new GVK", "gvr", gvr)
 
-	_, exists := dc.informers.Load(gvr)
-	if exists {
-		// Even thought the informer is already registered, we should still
-		// update the handler, as it might have changed.
-		dc.handlers.Store(gvr, handler)
-		// trigger reconciliation of the corresponding gvr's
-		objs, err := dc.kubeClient.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to list objects for GVR %s: %w", gvr, err)
-		}
-		for _, obj := range objs.Items {
-			dc.enqueueObject(&obj, "update")
+	scope := dc.watchScope(gvr)
+	if existing, exists := dc.informers.Load(gvr); exists {
+		wrapper := existing.(*informerWrapper)
+		if wrapper.scope == scope.key() {
+			// Even thought the informer is already registered, we should still
+			// update the handler, as it might have changed.
+			dc.setHandler(gvr, handler)
+			// trigger reconciliation of the corresponding gvr's
+			var keys []string
+			var items []unstructured.Unstructured
+			for _, namespace := range scope.namespaces() {
+				objs, err := dc.kubeClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{
+					LabelSelector: scope.LabelSelector,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to list objects for GVR %s: %w", gvr, err)
+				}
+				for _, obj := range objs.Items {
+					key, err := cache.MetaNamespaceKeyFunc(&obj)
+					if err != nil {
+						return fmt.Errorf("failed to get key for object of GVR %s: %w", gvr, err)
+					}
+					keys = append(keys, key)
+				}
+				items = append(items, objs.Items...)
+			}
+			dc.startRollout(gvr, keys)
+			for _, obj := range items {
+				dc.enqueueObject(&obj, "update")
+			}
+			return nil
 		}
-		return nil
+		// The scope changed, the informer is replaced by one watching the
+		// new scope, which enqueues the instances in it as they're added.
+		dc.log.V(1).Info("Restarting informer in new scope", "gvr", gvr)
+		wrapper.stop()
+		dc.informers.Delete(gvr)
+		gvrCount.Dec()
 	}
 
-	// Create a new informer
-	gvkInformer := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
-		dc.kubeClient,
-		dc.config.ResyncPeriod,
-		// Maybe we can make this configurable in the future. Thinking that
-		// we might want to filter out some resources, by namespace or labels
-		"",
-		nil,
-	)
-	informer := gvkInformer.ForResource(gvr).Informer()
-
-	// Set up event handlers
-	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	dc.setHandler(gvr, handler)
+	wrapper, err := dc.startInformer(nil, gvr, scope, cache.ResourceEventHandlerFuncs{
 		AddFunc:    func(obj interface{}) { dc.enqueueObject(obj, "add") },
 		UpdateFunc: dc.updateFunc,
 		DeleteFunc: func(obj interface{}) { dc.enqueueObject(obj, "delete") },
 	})
 	if err != nil {
-		dc.log.Error(err, "Failed to add event handler", "gvr", gvr)
-		return fmt.Errorf("failed to add event handler for GVR %s: %w", gvr, err)
+		return err
 	}
-	informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
-		dc.log.Error(err, "Watch error", "gvr", gvr)
-	})
-	dc.handlers.Store(gvr, handler)
+	dc.startRollout(gvr, wrapper.keys())
+
+	dc.informers.Store(gvr, wrapper)
+	gvrCount.Inc()
+	dc.log.V(1).Info("Successfully registered GVK", "gvr", gvr)
+	return nil
+}
 
+// startInformer creates the informers of the given GVR in the given scope of
+// the given cluster, nil for the cluster of the controller, with the given
+// event handler, starts them and waits for their caches to sync. The caches
+// of remote clusters, which may be unreachable, aren't waited for.
+func (dc *DynamicController) startInformer(
+	cluster *Cluster,
+	gvr schema.GroupVersionResource,
+	scope WatchScope,
+	handler cache.ResourceEventHandler,
+) (*informerWrapper, error) {
 	informerContext := context.Background()
 	cancelableContext, cancel := context.WithCancel(informerContext)
-	// Start the informer
-	go func() {
-		dc.log.V(1).Info("Starting informer", "gvr", gvr)
-		// time.Sleep(5 * time.Millisecond)
-		informer.Run(cancelableContext.Done())
-	}()
+
+	kubeClient, clusterName := dc.kubeClient, ""
+	if cluster != nil {
+		kubeClient, clusterName = cluster.Client, cluster.Name
+	}
+	// The successful lists tell that the watches recovered from their
+	// failures.
+	client := &listReportingClient{
+		Interface: kubeClient,
+		onList: func(namespace string) {
+			dc.watchRecovered(watchKey{cluster: clusterName, gvr: gvr, namespace: namespace})
+		},
+	}
+
+	var factories []dynamicinformer.DynamicSharedInformerFactory
+	var informers []cache.SharedIndexInformer
+	var stores []cache.Store
+	var hasSynced []cache.InformerSynced
+	for _, namespace := range scope.namespaces() {
+		// Create a new informer
+		gvkInformer := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+			client,
+			dc.config.ResyncPeriod,
+			namespace,
+			func(options *metav1.ListOptions) {
+				options.LabelSelector = scope.LabelSelector
+			},
+		)
+		informer := gvkInformer.ForResource(gvr).Informer()
+
+		// Set up event handlers
+		_, err := informer.AddEventHandler(handler)
+		if err != nil {
+			cancel()
+			dc.log.Error(err, "Failed to add event handler", "gvr", gvr)
+			return nil, fmt.Errorf("failed to add event handler for GVR %s: %w", gvr, err)
+		}
+		if err := informer.SetWatchErrorHandler(dc.watchErrorHandler(clusterName, gvr, namespace, cancelableContext.Done())); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to set watch error handler for GVR %s: %w", gvr, err)
+		}
+
+		// Start the informer
+		go func() {
+			dc.log.V(1).Info("Starting informer", "gvr", gvr, "namespace", namespace)
+			informer.Run(cancelableContext.Done())
+		}()
+		factories = append(factories, gvkInformer)
+		informers = append(informers, informer)
+		stores = append(stores, informer.GetStore())
+		hasSynced = append(hasSynced, informer.HasSynced)
+	}
+
+	shutdown := func() {
+		cancel()
+		dc.forgetWatches(clusterName, gvr, scope.namespaces())
+	}
+	wrapper := &informerWrapper{
+		informers:     factories,
+		stores:        stores,
+		shutdown:      shutdown,
+		scope:         scope.key(),
+		cluster:       clusterName,
+		namespaces:    scope.namespaces(),
+		labelSelector: scope.LabelSelector,
+		synced:        hasSynced,
+	}
+	if cluster != nil {
+		return wrapper, nil
+	}
 
 	dc.log.V(1).Info("Waiting for cache sync", "gvr", gvr)
 	startTime := time.Now()
 	// Wait for cache sync with a timeout
-	synced := cache.WaitForCacheSync(cancelableContext.Done(), informer.HasSynced)
+	synced := true
+	for _, informer := range informers {
+		synced = synced && cache.WaitForCacheSync(cancelableContext.Done(), informer.HasSynced)
+	}
 	syncDuration := time.Since(startTime)
 	informerSyncDuration.WithLabelValues(gvr.String()).Observe(syncDuration.Seconds())
 
 	if !synced {
 		cancel()
-		return fmt.Errorf("failed to sync informer cache for GVR %s", gvr)
+		return nil, fmt.Errorf("failed to sync informer cache for GVR %s", gvr)
 	}
+	return wrapper, nil
+}
 
-	dc.informers.Store(gvr, &informerWrapper{
-		informer: gvkInformer,
-		shutdown: cancel,
-	})
-	gvrCount.Inc()
-	dc.log.V(1).Info("Successfully registered GVK", "gvr", gvr)
+// Instances returns the objects of the given GVR served by the controller,
+// from the caches of its informers. It returns nil if the GVR isn't served.
+func (dc *DynamicController) Instances(gvr schema.GroupVersionResource) []*unstructured.Unstructured {
+	existing, ok := dc.informers.Load(gvr)
+	if !ok {
+		return nil
+	}
+	var instances []*unstructured.Unstructured
+	for _, store := range existing.(*informerWrapper).stores {
+		for _, obj := range store.List() {
+			if instance, ok := obj.(*unstructured.Unstructured); ok {
+				instances = append(instances, instance)
+			}
+		}
+	}
+	return instances
+}
+
+// WatchDependencies sets the objects the instances of the given parent GVR
+// can refer to, such as the instances of other resource graph definitions.
+// Whenever one of these objects changes, the instances referring to it are
+// enqueued, so that they pick up its new state. Dependencies the parent GVR
+// no longer refers to stop being watched once no other GVR refers to them.
+// The objects of a GVR are watched once per scope.
+func (dc *DynamicController) WatchDependencies(parent schema.GroupVersionResource, dependencies []Dependency) error {
+	dc.dependenciesMu.Lock()
+	defer dc.dependenciesMu.Unlock()
+
+	wanted := make(map[dependencyKey]struct{}, len(dependencies))
+	for _, dependency := range dependencies {
+		gvr := dependency.GVR
+		var cluster string
+		key := dependencyKey{gvr: gvr, scope: dependency.Scope.key()}
+		if dependency.Cluster != nil {
+			cluster = dependency.Cluster.Name
+			key.cluster = cluster + "@" + dependency.Cluster.Version
+		}
+		wanted[key] = struct{}{}
+		watch, ok := dc.dependencies[key]
+		if !ok {
+			dc.log.V(1).Info("Watching dependency", "gvr", gvr, "parent", parent, "cluster", cluster)
+			wrapper, err := dc.startInformer(dependency.Cluster, gvr, dependency.Scope, cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) { dc.enqueueDependents(cluster, gvr, obj, "add") },
+				UpdateFunc: func(old, new interface{}) {
+					// Status updates don't change the generation, any new
+					// version of the object is relevant.
+					oldObj, oldOK := old.(*unstructured.Unstructured)
+					newObj, newOK := new.(*unstructured.Unstructured)
+					if oldOK && newOK && oldObj.GetResourceVersion() == newObj.GetResourceVersion() {
+						return
+					}
+					dc.enqueueDependents(cluster, gvr, new, "update")
+				},
+				DeleteFunc: func(obj interface{}) { dc.enqueueDependents(cluster, gvr, obj, "delete") },
+			})
+			if err != nil {
+				return fmt.Errorf("failed to watch dependency %s: %w", gvr, err)
+			}
+			watch = &dependencyWatch{
+				informer: wrapper,
+				parents:  make(map[schema.GroupVersionResource]struct{}),
+			}
+			dc.dependencies[key] = watch
+		}
+		watch.parents[parent] = struct{}{}
+	}
+
+	for key, watch := range dc.dependencies {
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+		delete(watch.parents, parent)
+		if len(watch.parents) == 0 {
+			dc.log.V(1).Info("Stopping dependency watch", "gvr", key.gvr)
+			watch.informer.stop()
+			delete(dc.dependencies, key)
+		}
+	}
 	return nil
 }
 
+// SetReferences sets the objects the given instance refers to. Changes to
+// these objects enqueue the instance, as long as their GVR is watched as a
+// dependency of the GVR of the instance.
+func (dc *DynamicController) SetReferences(instance ObjectIdentifiers, references []ObjectIdentifiers) {
+	dc.dependenciesMu.Lock()
+	defer dc.dependenciesMu.Unlock()
+
+	for _, reference := range dc.instanceReferences[instance] {
+		delete(dc.references[reference], instance)
+		if len(dc.references[reference]) == 0 {
+			delete(dc.references, reference)
+		}
+	}
+	if len(references) == 0 {
+		delete(dc.instanceReferences, instance)
+		return
+	}
+	dc.instanceReferences[instance] = references
+	for _, reference := range references {
+		if dc.references[reference] == nil {
+			dc.references[reference] = make(map[ObjectIdentifiers]struct{})
+		}
+		dc.references[reference][instance] = struct{}{}
+	}
+}
+
+// enqueueDependents enqueues the instances referring to the given object of
+// the given GVR in the given cluster.
+func (dc *DynamicController) enqueueDependents(cluster string, gvr schema.GroupVersionResource, obj interface{}, eventType string) {
+	namespacedKey, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		dc.log.Error(err, "Failed to get key for dependency", "gvr", gvr, "eventType", eventType)
+		return
+	}
+
+	dc.dependenciesMu.Lock()
+	var instances []ObjectIdentifiers
+	for instance := range dc.references[ObjectIdentifiers{NamespacedKey: namespacedKey, GVR: gvr, Cluster: cluster}] {
+		instances = append(instances, instance)
+	}
+	dc.dependenciesMu.Unlock()
+
+	informerEventsTotal.WithLabelValues(gvr.String(), eventType).Inc()
+	for _, instance := range instances {
+		dc.log.V(1).Info("Enqueueing object referring to a dependency",
+			"objectIdentifiers", instance,
+			"dependency", namespacedKey,
+			"eventType", eventType)
+		dc.enqueue(instance)
+	}
+}
+
 // UnregisterGVK safely removes a GVK from the controller and cleans up associated resources.
 func (dc *DynamicController) StopServiceGVK(ctx context.Context, gvr schema.GroupVersionResource) error {
 	dc.log.Info("Unregistering GVK", "gvr", gvr)
@@ -513,19 +1345,48 @@ func (dc *DynamicController) StopServiceGVK(ctx context.Context, gvr schema.Grou
 		return fmt.Errorf("invalid informer type for GVR: %s", gvr)
 	}
 
+	// Unregister the handler once the reconciles in flight are done, the
+	// items still queued are dropped.
+	dc.setHandler(gvr, nil)
+	dc.rolloutsMu.Lock()
+	delete(dc.rollouts, gvr)
+	dc.rolloutsMu.Unlock()
+
 	// Stop the informer
 	dc.log.V(1).Info("Stopping informer", "gvr", gvr)
 
-	// Cancel the context to stop the informer
-	wrapper.shutdown()
-	// Wait for the informer to shut down
-	wrapper.informer.Shutdown()
+	// Cancel the context to stop the informer, and wait for it to shut down
+	wrapper.stop()
 
 	// Remove the informer from the map
 	dc.informers.Delete(gvr)
 
-	// Unregister the handler if any
-	dc.handlers.Delete(gvr)
+	dc.scopes.Delete(gvr)
+	dc.retryPolicies.Delete(gvr)
+	dc.SetConcurrency(gvr, 0)
+	dc.attemptsMu.Lock()
+	for item := range dc.attempts {
+		if item.GVR == gvr {
+			delete(dc.attempts, item)
+		}
+	}
+	dc.attemptsMu.Unlock()
+
+	// Stop watching the objects its instances refer to
+	if err := dc.WatchDependencies(gvr, nil); err != nil {
+		return err
+	}
+	dc.dependenciesMu.Lock()
+	instances := make([]ObjectIdentifiers, 0)
+	for instance := range dc.instanceReferences {
+		if instance.GVR == gvr {
+			instances = append(instances, instance)
+		}
+	}
+	dc.dependenciesMu.Unlock()
+	for _, instance := range instances {
+		dc.SetReferences(instance, nil)
+	}
 
 	gvrCount.Dec()
 	// Clean up any pending items in the queue for this GVR
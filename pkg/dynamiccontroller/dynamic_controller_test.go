@@ -14,22 +14,35 @@ package dynamiccontroller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"maps"
+	"net/http"
+	"net/http/httptest"
 	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/kro-run/kro/pkg/requeue"
 )
 
 // NOTE(a-hilaly): I'm just playing around with the dynamic controller code here
@@ -211,3 +224,561 @@ func TestInstanceUpdatePolicy(t *testing.T) {
 		assert.True(t, ok)
 	}
 }
+
+func TestWatchScope(t *testing.T) {
+	scheme := runtime.NewScheme()
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	gvk := schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Test"}
+
+	newObject := func(namespace, name string, labels map[string]string) runtime.Object {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetLabels(labels)
+		return obj
+	}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		gvr: "TestList",
+	},
+		newObject("default", "selected", map[string]string{"tier": "production"}),
+		newObject("default", "unselected", nil),
+		newObject("other", "elsewhere", map[string]string{"tier": "production"}),
+	)
+
+	dc := NewDynamicController(noopLogger(), Config{}, client)
+	handlerFunc := Handler(func(ctx context.Context, req controllerruntime.Request) error {
+		return nil
+	})
+	queued := func() []string {
+		var keys []string
+		for dc.queue.Len() > 0 {
+			item, _ := dc.queue.Get()
+			keys = append(keys, item.NamespacedKey)
+			dc.queue.Done(item)
+			dc.queue.Forget(item)
+		}
+		slices.Sort(keys)
+		return keys
+	}
+
+	// Only the instances in the scope are watched.
+	dc.SetWatchScope(gvr, &WatchScope{Namespaces: []string{"default"}, LabelSelector: "tier=production"})
+	require.NoError(t, dc.StartServingGVK(context.Background(), gvr, handlerFunc))
+	assert.Eventually(t, func() bool { return dc.queue.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"default/selected"}, queued())
+
+	// Resyncing lists the instances in the scope.
+	require.NoError(t, dc.StartServingGVK(context.Background(), gvr, handlerFunc))
+	assert.Equal(t, []string{"default/selected"}, queued())
+
+	// Changing the scope restarts the informer in the new scope.
+	dc.SetWatchScope(gvr, nil)
+	require.NoError(t, dc.StartServingGVK(context.Background(), gvr, handlerFunc))
+	assert.Eventually(t, func() bool { return dc.queue.Len() == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"default/selected", "default/unselected", "other/elsewhere"}, queued())
+
+	require.NoError(t, dc.StopServiceGVK(context.Background(), gvr))
+}
+
+func TestWatchDependencies(t *testing.T) {
+	logger := noopLogger()
+
+	scheme := runtime.NewScheme()
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	gvk := schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Test"}
+	networkGVR := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "networks"}
+	networkGVK := schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Network"}
+
+	instance := &unstructured.Unstructured{}
+	instance.SetGroupVersionKind(gvk)
+	instance.SetNamespace("default")
+	instance.SetName("test-object")
+
+	other := &unstructured.Unstructured{}
+	other.SetGroupVersionKind(gvk)
+	other.SetNamespace("default")
+	other.SetName("other-object")
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		gvr:        "TestList",
+		networkGVR: "NetworkList",
+	}, instance, other)
+
+	dc := NewDynamicController(logger, Config{}, client)
+
+	handlerFunc := Handler(func(ctx context.Context, req controllerruntime.Request) error {
+		return nil
+	})
+	err := dc.StartServingGVK(context.Background(), gvr, handlerFunc)
+	require.NoError(t, err)
+
+	err = dc.WatchDependencies(gvr, []Dependency{{GVR: networkGVR}})
+	require.NoError(t, err)
+	assert.Contains(t, dc.dependencies, dependencyKey{gvr: networkGVR, scope: WatchScope{}.key()})
+
+	instanceID := ObjectIdentifiers{NamespacedKey: "default/test-object", GVR: gvr}
+	networkID := ObjectIdentifiers{NamespacedKey: "default/network", GVR: networkGVR}
+	dc.SetReferences(instanceID, []ObjectIdentifiers{networkID})
+
+	// Drain the events of the instance informer
+	for dc.queue.Len() > 0 {
+		item, _ := dc.queue.Get()
+		dc.queue.Done(item)
+		dc.queue.Forget(item)
+	}
+
+	network := &unstructured.Unstructured{}
+	network.SetGroupVersionKind(networkGVK)
+	network.SetNamespace("default")
+	network.SetName("network")
+	_, err = client.Resource(networkGVR).Namespace("default").Create(context.Background(), network, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// Only the instance referring to the object is enqueued
+	assert.Eventually(t, func() bool { return dc.queue.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	item, _ := dc.queue.Get()
+	assert.Equal(t, instanceID, item)
+	dc.queue.Done(item)
+	dc.queue.Forget(item)
+
+	dc.SetReferences(instanceID, nil)
+	assert.Empty(t, dc.references)
+	assert.Empty(t, dc.instanceReferences)
+
+	err = dc.WatchDependencies(gvr, nil)
+	require.NoError(t, err)
+	assert.Empty(t, dc.dependencies)
+}
+
+func TestWatchRemoteDependencies(t *testing.T) {
+	scheme := runtime.NewScheme()
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	networkGVR := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "networks"}
+	networkGVK := schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Network"}
+	listKinds := map[schema.GroupVersionResource]string{
+		gvr:        "TestList",
+		networkGVR: "NetworkList",
+	}
+
+	dc := NewDynamicController(noopLogger(), Config{}, fake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds))
+	remote := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds)
+	cluster := &Cluster{Name: "clusters/spoke", Version: "1", Client: remote}
+
+	err := dc.WatchDependencies(gvr, []Dependency{{GVR: networkGVR, Cluster: cluster}})
+	require.NoError(t, err)
+	assert.Contains(t, dc.dependencies, dependencyKey{gvr: networkGVR, scope: WatchScope{}.key(), cluster: "clusters/spoke@1"})
+
+	instanceID := ObjectIdentifiers{NamespacedKey: "default/test-object", GVR: gvr}
+	networkID := ObjectIdentifiers{NamespacedKey: "default/network", GVR: networkGVR, Cluster: "clusters/spoke"}
+	dc.SetReferences(instanceID, []ObjectIdentifiers{networkID})
+
+	network := &unstructured.Unstructured{}
+	network.SetGroupVersionKind(networkGVK)
+	network.SetNamespace("default")
+	network.SetName("network")
+	_, err = remote.Resource(networkGVR).Namespace("default").Create(context.Background(), network, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// The instance referring to the object of the remote cluster is enqueued
+	assert.Eventually(t, func() bool { return dc.queue.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	item, _ := dc.queue.Get()
+	assert.Equal(t, instanceID, item)
+	dc.queue.Done(item)
+	dc.queue.Forget(item)
+
+	// New credentials restart the watch
+	rotated := &Cluster{Name: "clusters/spoke", Version: "2", Client: remote}
+	err = dc.WatchDependencies(gvr, []Dependency{{GVR: networkGVR, Cluster: rotated}})
+	require.NoError(t, err)
+	assert.Len(t, dc.dependencies, 1)
+	assert.Contains(t, dc.dependencies, dependencyKey{gvr: networkGVR, scope: WatchScope{}.key(), cluster: "clusters/spoke@2"})
+
+	err = dc.WatchDependencies(gvr, nil)
+	require.NoError(t, err)
+	assert.Empty(t, dc.dependencies)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{MinRetryDelay: time.Hour, MaxRetryDelay: time.Hour, QueueMaxRetries: 5}, setupFakeClient())
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	item := ObjectIdentifiers{NamespacedKey: "default/test-object", GVR: gvr}
+
+	backoff := requeue.Backoff{InitialDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond, MaxAttempts: 3}
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 4 * time.Millisecond},
+		[]time.Duration{backoff.Delay(0), backoff.Delay(1), backoff.Delay(2), backoff.Delay(3)})
+
+	// Syncing the item fails, so that it's retried following the policy
+	// instead of the rate limiter.
+	var lastAttempts []bool
+	dc.setHandler(gvr, func(ctx context.Context, req controllerruntime.Request) error {
+		lastAttempts = append(lastAttempts, IsLastAttempt(ctx))
+		return fmt.Errorf("failed")
+	})
+	dc.SetRetryPolicy(gvr, &backoff)
+	dc.queue.Add(item)
+	for attempt := 1; attempt <= backoff.MaxAttempts; attempt++ {
+		require.True(t, dc.processNextWorkItem(context.Background(), dc.queue))
+		assert.Equal(t, attempt, dc.attempts[item])
+	}
+
+	// The item is dropped once it was retried the maximum number of times.
+	require.True(t, dc.processNextWorkItem(context.Background(), dc.queue))
+	assert.NotContains(t, dc.attempts, item)
+	assert.Equal(t, []bool{false, false, false, true}, lastAttempts)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, dc.queue.Len())
+
+	// Without a policy, the item is retried by the rate limiter.
+	dc.SetRetryPolicy(gvr, nil)
+	dc.queue.Add(item)
+	require.True(t, dc.processNextWorkItem(context.Background(), dc.queue))
+	assert.Equal(t, 1, dc.queue.NumRequeues(item))
+	assert.NotContains(t, dc.attempts, item)
+}
+
+func TestRetriesResetAfterSuccess(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		MinRetryDelay:   time.Millisecond,
+		MaxRetryDelay:   time.Millisecond,
+		RateLimit:       100,
+		BurstLimit:      100,
+		QueueMaxRetries: 5,
+	}, setupFakeClient())
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	item := ObjectIdentifiers{NamespacedKey: "default/test-object", GVR: gvr}
+
+	// The item fails, succeeds and is resynced, and fails again.
+	results := []error{
+		fmt.Errorf("failed"),
+		requeue.NeededAfter(nil, time.Millisecond),
+		fmt.Errorf("failed"),
+	}
+	dc.setHandler(gvr, func(ctx context.Context, req controllerruntime.Request) error {
+		err := results[0]
+		results = results[1:]
+		return err
+	})
+
+	backoff := requeue.Backoff{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxAttempts: 3}
+	dc.SetRetryPolicy(gvr, &backoff)
+	dc.queue.Add(item)
+	require.True(t, dc.processNextWorkItem(context.Background(), dc.queue))
+	assert.Equal(t, 1, dc.attempts[item])
+	require.True(t, dc.processNextWorkItem(context.Background(), dc.queue))
+	assert.NotContains(t, dc.attempts, item)
+	require.True(t, dc.processNextWorkItem(context.Background(), dc.queue))
+	assert.Equal(t, 1, dc.attempts[item])
+
+	// So do the items retried by the rate limiter.
+	results = []error{
+		fmt.Errorf("failed"),
+		requeue.NeededAfter(nil, time.Millisecond),
+		fmt.Errorf("failed"),
+	}
+	dc.SetRetryPolicy(gvr, nil)
+	for _, want := range []int{1, 0, 1} {
+		require.True(t, dc.processNextWorkItem(context.Background(), dc.queue))
+		assert.Equal(t, want, dc.queue.NumRequeues(item))
+	}
+}
+
+func TestSetConcurrency(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		MinRetryDelay: time.Hour, MaxRetryDelay: time.Hour, RateLimit: 10, BurstLimit: 100, ShutdownTimeout: 5 * time.Second,
+	}, setupFakeClient())
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+
+	reconciled := make(chan string, 1)
+	dc.setHandler(gvr, func(ctx context.Context, req controllerruntime.Request) error {
+		reconciled <- req.Name
+		return nil
+	})
+
+	obj := &unstructured.Unstructured{}
+	obj.SetName("test-object")
+	obj.SetNamespace("default")
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Test"})
+
+	// The items pending in the shared queue, including those waiting for a
+	// retry, are moved to the dedicated queue.
+	retried := ObjectIdentifiers{NamespacedKey: "default/retried-object", GVR: gvr}
+	dc.enqueueObject(obj, "add")
+	dc.queue.AddAfter(retried, time.Hour)
+	dc.SetConcurrency(gvr, 2)
+	value, ok := dc.dedicatedQueues.Load(gvr)
+	require.True(t, ok)
+	dedicated := value.(*dedicatedQueue).queue
+	assert.Contains(t, dedicated.pending, retried)
+	assert.Empty(t, dc.queue.pending)
+
+	// The items of a GVR with dedicated workers are processed by these
+	// workers once the controller runs, without the shared workers running.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- dc.Run(ctx) }()
+	select {
+	case name := <-reconciled:
+		assert.Equal(t, "default/test-object", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("item wasn't processed by the dedicated workers")
+	}
+	dc.enqueueObject(obj, "add")
+	assert.Empty(t, dc.queue.pending)
+	select {
+	case name := <-reconciled:
+		assert.Equal(t, "default/test-object", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("item wasn't processed by the dedicated workers")
+	}
+
+	// Without dedicated workers, the items go back to the shared queue.
+	dc.SetConcurrency(gvr, 0)
+	assert.Contains(t, dc.queue.pending, retried)
+	dc.enqueueObject(obj, "add")
+	assert.Equal(t, 1, dc.queue.Len())
+	_, ok = dc.dedicatedQueues.Load(gvr)
+	assert.False(t, ok)
+
+	// The dedicated workers are waited for on shutdown.
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("controller didn't shut down")
+	}
+}
+
+func TestSetConcurrencyWhileEnqueueing(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		MinRetryDelay: time.Hour, MaxRetryDelay: time.Hour, RateLimit: 10, BurstLimit: 100,
+	}, setupFakeClient())
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "switches"}
+
+	// Items added while the workers of their GVR are switched are never
+	// added to a queue whose items were already moved away.
+	const enqueuers, items = 4, 250
+	var wg sync.WaitGroup
+	for e := 0; e < enqueuers; e++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < items; i++ {
+				dc.enqueue(ObjectIdentifiers{NamespacedKey: fmt.Sprintf("default/object-%d-%d", e, i), GVR: gvr})
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		dc.SetConcurrency(gvr, 1+i%2)
+	}
+	wg.Wait()
+	dc.SetConcurrency(gvr, 0)
+
+	assert.Len(t, dc.queue.pending, enqueuers*items)
+}
+
+func TestQueueDepth(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{MinRetryDelay: time.Hour, MaxRetryDelay: time.Hour, RateLimit: 10, BurstLimit: 100}, setupFakeClient())
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "depths"}
+	dc.SetResourceGraphDefinition(gvr, "depth-test")
+	depth := queueDepth.WithLabelValues(gvr.String(), "depth-test")
+
+	first := ObjectIdentifiers{NamespacedKey: "default/first", GVR: gvr}
+	second := ObjectIdentifiers{NamespacedKey: "default/second", GVR: gvr}
+	dc.queue.Add(first)
+	dc.queue.Add(first)
+	dc.queue.Add(second)
+	assert.Equal(t, 2.0, testutil.ToFloat64(depth))
+
+	// Delayed items aren't counted until they're ready.
+	dc.queue.AddAfter(ObjectIdentifiers{NamespacedKey: "default/third", GVR: gvr}, time.Hour)
+	assert.Equal(t, 2.0, testutil.ToFloat64(depth))
+
+	item, _ := dc.queue.Get()
+	dc.queue.Done(item)
+	assert.Equal(t, 1.0, testutil.ToFloat64(depth))
+	dc.queue.ShutDown()
+}
+
+func TestRolloutProgress(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{MinRetryDelay: time.Hour, MaxRetryDelay: time.Hour, RateLimit: 10, BurstLimit: 100}, setupFakeClient())
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	ctx := context.Background()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	dc.setHandler(gvr, func(ctx context.Context, req controllerruntime.Request) error {
+		close(started)
+		<-release
+		return nil
+	})
+	dc.startRollout(gvr, []string{"default/a", "default/b"})
+	reconciled, total := dc.RolloutProgress(gvr)
+	assert.Equal(t, 0, reconciled)
+	assert.Equal(t, 2, total)
+
+	a := ObjectIdentifiers{NamespacedKey: "default/a", GVR: gvr}
+	done := make(chan error, 1)
+	go func() { done <- dc.syncFunc(ctx, a) }()
+	<-started
+
+	// Swapping the handler waits for the in-flight reconcile to drain.
+	swapped := make(chan struct{})
+	go func() {
+		dc.setHandler(gvr, func(ctx context.Context, req controllerruntime.Request) error {
+			return fmt.Errorf("failed")
+		})
+		close(swapped)
+	}()
+	select {
+	case <-swapped:
+		t.Fatal("handler was swapped during a reconcile")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	require.NoError(t, <-done)
+	<-swapped
+
+	reconciled, total = dc.RolloutProgress(gvr)
+	assert.Equal(t, 1, reconciled)
+	assert.Equal(t, 2, total)
+
+	// Failed reconciles don't count.
+	b := ObjectIdentifiers{NamespacedKey: "default/b", GVR: gvr}
+	require.Error(t, dc.syncFunc(ctx, b))
+	reconciled, _ = dc.RolloutProgress(gvr)
+	assert.Equal(t, 1, reconciled)
+
+	// Without a handler, the items are dropped.
+	dc.setHandler(gvr, nil)
+	require.NoError(t, dc.syncFunc(ctx, b))
+	_, ok := dc.handlers.Load(gvr)
+	assert.False(t, ok)
+}
+
+func TestWatchHealth(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{MinRetryDelay: time.Hour, MaxRetryDelay: time.Hour, RateLimit: 10, BurstLimit: 100}, setupFakeClient())
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	dependency := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	changed := make(chan schema.GroupVersionResource, 10)
+	dc.OnWatchHealthChange(func(gvr schema.GroupVersionResource) { changed <- gvr })
+	dc.informers.Store(gvr, &informerWrapper{})
+	dc.dependencies[dependencyKey{gvr: dependency}] = &dependencyWatch{
+		parents: map[schema.GroupVersionResource]struct{}{gvr: {}},
+	}
+
+	// The handler doesn't back off once the watch is stopped.
+	stop := make(chan struct{})
+	close(stop)
+	handler := dc.watchErrorHandler("", dependency, "default", stop)
+
+	// Closed watches aren't failures.
+	handler(nil, io.EOF)
+	handler(nil, apierrors.NewResourceExpired("too old resource version"))
+	assert.Empty(t, dc.WatchFailures(gvr))
+
+	forbidden := apierrors.NewForbidden(dependency.GroupResource(), "", fmt.Errorf("no access"))
+	handler(nil, forbidden)
+	handler(nil, forbidden)
+	failures := dc.WatchFailures(gvr)
+	require.Len(t, failures, 1)
+	assert.Equal(t, dependency, failures[0].GVR)
+	assert.Equal(t, "default", failures[0].Namespace)
+	assert.Equal(t, metav1.StatusReasonForbidden, failures[0].Reason)
+	assert.Equal(t, 2, failures[0].Failures)
+	assert.Equal(t, gvr, <-changed, "the served GVR depending on the failing GVR is notified")
+
+	// A successful list recovers the watch.
+	client := &listReportingClient{
+		Interface: setupFakeClient(),
+		onList: func(namespace string) {
+			dc.watchRecovered(watchKey{gvr: dependency, namespace: namespace})
+		},
+	}
+	_, err := client.Resource(gvr).Namespace("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, dc.WatchFailures(gvr))
+	assert.Equal(t, gvr, <-changed)
+}
+
+func TestDebugReport(t *testing.T) {
+	scheme := runtime.NewScheme()
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	gvk := schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Test"}
+	networkGVR := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "networks"}
+
+	instance := &unstructured.Unstructured{}
+	instance.SetGroupVersionKind(gvk)
+	instance.SetNamespace("default")
+	instance.SetName("test-object")
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		gvr:        "TestList",
+		networkGVR: "NetworkList",
+	}, instance)
+
+	dc := NewDynamicController(noopLogger(), Config{Workers: 2}, client)
+	dc.SetResourceGraphDefinition(gvr, "testrgd")
+	dc.SetWatchScope(gvr, &WatchScope{Namespaces: []string{"default"}})
+	require.NoError(t, dc.StartServingGVK(context.Background(), gvr, func(ctx context.Context, req controllerruntime.Request) error {
+		return nil
+	}))
+	require.NoError(t, dc.WatchDependencies(gvr, []Dependency{{GVR: networkGVR}}))
+	dc.SetConcurrency(gvr, 1)
+	defer dc.SetConcurrency(gvr, 0)
+	// The instance was added to the shared queue before the dedicated one,
+	// and moved to it. It's left behind in the shared queue, whose workers
+	// skip it.
+	assert.Eventually(t, func() bool { return dc.queueFor(gvr).Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	report := dc.Debug()
+	assert.Equal(t, []GVRReport{{
+		GVR:                     gvr.String(),
+		ResourceGraphDefinition: "testrgd",
+		Handler:                 true,
+		Informers:               []InformerReport{{Namespace: "default", Synced: true, Objects: 1}},
+		Queue:                   gvr.String(),
+		Total:                   1,
+	}}, report.GVRs)
+	assert.Equal(t, []DependencyReport{{
+		GVR:       networkGVR.String(),
+		Parents:   []string{gvr.String()},
+		Informers: []InformerReport{{Synced: true}},
+	}}, report.Dependencies)
+	require.Len(t, report.Queues, 2)
+	assert.Equal(t, QueueReport{Name: "shared", Depth: 1, Workers: 2}, report.Queues[0])
+	assert.Equal(t, QueueReport{Name: gvr.String(), Depth: 1, Workers: 1}, report.Queues[1])
+
+	// The report is only served to the users allowed to get its path.
+	kubeClient := kubefake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = review.Spec.Token != "invalid"
+		review.Status.User.Username = review.Spec.Token
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = review.Spec.User == "admin" &&
+			review.Spec.NonResourceAttributes.Path == DebugPath && review.Spec.NonResourceAttributes.Verb == "get"
+		return true, review, nil
+	})
+	handler := WithAuthorization(noopLogger(), kubeClient, dc.DebugHandler())
+	for token, code := range map[string]int{"": http.StatusUnauthorized, "invalid": http.StatusUnauthorized, "viewer": http.StatusForbidden, "admin": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodGet, DebugPath, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, code, rec.Code, token)
+		if code == http.StatusOK {
+			var served DebugReport
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+			assert.Equal(t, report.GVRs, served.GVRs)
+		}
+	}
+
+	require.NoError(t, dc.WatchDependencies(gvr, nil))
+	require.NoError(t, dc.StopServiceGVK(context.Background(), gvr))
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// watchBackoffBase and watchBackoffMax bound the delay before a failed
+	// watch is retried. It doubles on every consecutive failure, on top of
+	// the backoff of the reflector, which is capped to 30 seconds.
+	watchBackoffBase = time.Second
+	watchBackoffMax  = 5 * time.Minute
+)
+
+// WatchFailure is a watch of a GVR that keeps failing, e.g because its CRD
+// was deleted or the controller isn't allowed to list it anymore.
+type WatchFailure struct {
+	// Cluster is the remote cluster the GVR is watched in, empty for the
+	// cluster of the controller.
+	Cluster string
+	GVR     schema.GroupVersionResource
+	// Namespace is the namespace the GVR is watched in, empty if it's watched
+	// in all the namespaces.
+	Namespace string
+	// Reason is the reason of the last error, e.g Forbidden or NotFound. It's
+	// empty if the error isn't an API error.
+	Reason metav1.StatusReason
+	// Message is the message of the last error.
+	Message string
+	// Failures is the number of consecutive failures.
+	Failures int
+	// Since is the time of the first of these failures.
+	Since time.Time
+}
+
+type watchKey struct {
+	cluster   string
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// OnWatchHealthChange sets the function called with the GVRs served by the
+// controller whenever a watch of theirs starts failing or recovers, including
+// the watches of their dependencies. It must be set before the GVRs are
+// served.
+func (dc *DynamicController) OnWatchHealthChange(handler func(gvr schema.GroupVersionResource)) {
+	dc.watchHealthHandler = handler
+}
+
+// WatchFailures returns the failing watches of the given served GVR and of
+// its dependencies, sorted by cluster, GVR and namespace.
+func (dc *DynamicController) WatchFailures(gvr schema.GroupVersionResource) []WatchFailure {
+	watched := []schema.GroupVersionResource{gvr}
+	dc.dependenciesMu.Lock()
+	for key, watch := range dc.dependencies {
+		if _, ok := watch.parents[gvr]; ok {
+			watched = append(watched, key.gvr)
+		}
+	}
+	dc.dependenciesMu.Unlock()
+
+	dc.watchFailuresMu.Lock()
+	defer dc.watchFailuresMu.Unlock()
+	var failures []WatchFailure
+	for key, failure := range dc.watchFailures {
+		if slices.Contains(watched, key.gvr) {
+			failures = append(failures, *failure)
+		}
+	}
+	slices.SortFunc(failures, func(a, b WatchFailure) int {
+		if c := strings.Compare(a.Cluster, b.Cluster); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.GVR.String(), b.GVR.String()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+	return failures
+}
+
+// watchErrorHandler returns the handler of the errors of the watch of the
+// given GVR in the given namespace of the given cluster. It records the
+// failure and backs off with jitter before the watch is retried, until the
+// given channel is closed.
+func (dc *DynamicController) watchErrorHandler(
+	cluster string,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	stop <-chan struct{},
+) cache.WatchErrorHandler {
+	return func(_ *cache.Reflector, err error) {
+		// Watches are closed regularly, they're restarted right away.
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return
+		}
+		watchErrorsTotal.WithLabelValues(gvr.String()).Inc()
+
+		failures := dc.watchFailed(watchKey{cluster: cluster, gvr: gvr, namespace: namespace}, err)
+		// Log the first failure, then less and less often.
+		if failures&(failures-1) == 0 {
+			dc.log.Error(err, "Watch failed", "gvr", gvr, "namespace", namespace, "cluster", cluster, "failures", failures)
+		}
+
+		delay := wait.Jitter(min(watchBackoffBase<<min(failures-1, 16), watchBackoffMax), 1.0)
+		select {
+		case <-stop:
+		case <-time.After(delay):
+		}
+	}
+}
+
+// watchFailed records a failure of the given watch, and returns the number of
+// its consecutive failures.
+func (dc *DynamicController) watchFailed(key watchKey, err error) int {
+	dc.watchFailuresMu.Lock()
+	failure, ok := dc.watchFailures[key]
+	if !ok {
+		failure = &WatchFailure{Cluster: key.cluster, GVR: key.gvr, Namespace: key.namespace, Since: time.Now()}
+		dc.watchFailures[key] = failure
+	}
+	failure.Reason = apierrors.ReasonForError(err)
+	failure.Message = err.Error()
+	failure.Failures++
+	failures := failure.Failures
+	dc.watchFailuresMu.Unlock()
+
+	if !ok {
+		dc.watchHealthChanged(key.gvr)
+	}
+	return failures
+}
+
+// watchRecovered records that the given watch succeeded.
+func (dc *DynamicController) watchRecovered(key watchKey) {
+	dc.watchFailuresMu.Lock()
+	_, ok := dc.watchFailures[key]
+	delete(dc.watchFailures, key)
+	dc.watchFailuresMu.Unlock()
+
+	if ok {
+		dc.log.Info("Watch recovered", "gvr", key.gvr, "namespace", key.namespace, "cluster", key.cluster)
+		dc.watchHealthChanged(key.gvr)
+	}
+}
+
+// forgetWatches drops the failures of the watches of the given GVR in the
+// given namespaces of the given cluster, once they're stopped.
+func (dc *DynamicController) forgetWatches(cluster string, gvr schema.GroupVersionResource, namespaces []string) {
+	dc.watchFailuresMu.Lock()
+	defer dc.watchFailuresMu.Unlock()
+	for _, namespace := range namespaces {
+		delete(dc.watchFailures, watchKey{cluster: cluster, gvr: gvr, namespace: namespace})
+	}
+}
+
+// watchHealthChanged calls the health handler with the served GVRs watching
+// the given GVR, either directly or as a dependency.
+func (dc *DynamicController) watchHealthChanged(gvr schema.GroupVersionResource) {
+	if dc.watchHealthHandler == nil {
+		return
+	}
+
+	var parents []schema.GroupVersionResource
+	if _, ok := dc.informers.Load(gvr); ok {
+		parents = append(parents, gvr)
+	}
+	dc.dependenciesMu.Lock()
+	for key, watch := range dc.dependencies {
+		if key.gvr != gvr {
+			continue
+		}
+		for parent := range watch.parents {
+			if !slices.Contains(parents, parent) {
+				parents = append(parents, parent)
+			}
+		}
+	}
+	dc.dependenciesMu.Unlock()
+
+	// The handler is called outside of the reflector, which shouldn't be
+	// held up.
+	go func() {
+		for _, parent := range parents {
+			dc.watchHealthHandler(parent)
+		}
+	}()
+}
+
+// listReportingClient is a dynamic client reporting the successful lists of
+// the objects of a GVR, which tell that its watch recovered: the reflectors
+// list the objects again whenever their watch failed.
+type listReportingClient struct {
+	dynamic.Interface
+	onList func(namespace string)
+}
+
+func (c *listReportingClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &listReportingResource{NamespaceableResourceInterface: c.Interface.Resource(gvr), onList: c.onList}
+}
+
+type listReportingResource struct {
+	dynamic.NamespaceableResourceInterface
+	onList func(namespace string)
+}
+
+func (r *listReportingResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &listReportingNamespacedResource{
+		ResourceInterface: r.NamespaceableResourceInterface.Namespace(namespace),
+		namespace:         namespace,
+		onList:            r.onList,
+	}
+}
+
+type listReportingNamespacedResource struct {
+	dynamic.ResourceInterface
+	namespace string
+	onList    func(namespace string)
+}
+
+func (r *listReportingNamespacedResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list, err := r.ResourceInterface.List(ctx, opts)
+	if err == nil {
+		r.onList(r.namespace)
+	}
+	return list, err
+}
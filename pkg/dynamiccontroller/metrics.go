@@ -27,9 +27,11 @@ func init() {
 		reconcileDuration,
 		gvrCount,
 		queueLength,
+		queueDepth,
 		handlerErrorsTotal,
 		informerSyncDuration,
 		informerEventsTotal,
+		watchErrorsTotal,
 		// activeWorkersTotal,
 	)
 }
@@ -71,6 +73,15 @@ var (
 			Help: "Current length of the workqueue",
 		},
 	)
+	// queueDepth is the number of items of each GVR waiting to be processed,
+	// labeled by the resource graph definition serving the GVR
+	queueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dynamic_controller_queue_depth",
+			Help: "Number of items waiting to be processed per GVR and resource graph definition",
+		},
+		[]string{"gvr", "rgd"},
+	)
 	handlerErrorsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "dynamic_controller_handler_errors_total",
@@ -93,6 +104,13 @@ var (
 		},
 		[]string{"gvr", "event_type"},
 	)
+	watchErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dynamic_controller_watch_errors_total",
+			Help: "Total number of errors encountered by the watches of informers per GVR",
+		},
+		[]string{"gvr"},
+	)
 	/* activeWorkersTotal = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "dynamic_controller_active_workers_total",
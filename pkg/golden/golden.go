@@ -0,0 +1,256 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package golden compares the resources rendered for instances of
+// ResourceGraphDefinitions to golden files, so that the unintended changes to
+// their templates are caught by tests.
+//
+// A typical test looks like:
+//
+//	var update = flag.Bool("update", false, "update the golden files")
+//
+//	func TestWebApp(t *testing.T) {
+//		results, err := golden.Check(rgds, instances, "testdata/golden", golden.Options{Update: *update})
+//		...
+//		if err := golden.Mismatches(results); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+package golden
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/graph/crd"
+	"github.com/kro-run/kro/pkg/graph/schema"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// DefaultKubernetesVersion is the version of the cluster the expressions see.
+const DefaultKubernetesVersion = "v1.31.0"
+
+// Options configures the rendering of the instances.
+type Options struct {
+	// CRDs are the CustomResourceDefinitions of the custom kinds of the
+	// resources. The schemas of the built-in kinds are known.
+	CRDs []*extv1.CustomResourceDefinition
+	// TypeLibraries are the ResourceGraphTypeLibraries providing the shared
+	// types of the schemas.
+	TypeLibraries []v1alpha1.ResourceGraphTypeLibrary
+	// KubernetesVersion is the version of the cluster the expressions see.
+	// Defaults to DefaultKubernetesVersion.
+	KubernetesVersion string
+	// Values are the ConfigMaps and Secrets the valuesFrom of the
+	// ResourceGraphDefinitions are looked up among.
+	Values []*unstructured.Unstructured
+	// Update writes the rendered resources to the golden files, instead of
+	// comparing them.
+	Update bool
+}
+
+// Result is the result of the comparison of the resources rendered for an
+// instance to its golden file.
+type Result struct {
+	// Instance is the instance, as kind/namespace/name.
+	Instance string
+	// File is the path of the golden file.
+	File string
+	// Updated is true if the golden file was written.
+	Updated bool
+	// Diff is the difference between the golden file and the rendered
+	// resources, empty if they match.
+	Diff string
+}
+
+// Check renders the resources of each of the given instances, served by one of
+// the given ResourceGraphDefinitions, and compares them to their golden files
+// in the given directory. The golden file of an instance is named after its
+// kind and name, e.g webapp-my-app.yaml. It returns an error if an instance
+// can't be rendered, or a golden file is missing.
+func Check(
+	rgds []*v1alpha1.ResourceGraphDefinition,
+	instances []*unstructured.Unstructured,
+	dir string,
+	opts Options,
+) ([]Result, error) {
+	if opts.KubernetesVersion == "" {
+		opts.KubernetesVersion = DefaultKubernetesVersion
+	}
+	sharedTypes, err := graph.MergeTypeLibraries(opts.TypeLibraries)
+	if err != nil {
+		return nil, err
+	}
+
+	graphs := make(map[string]*graph.Graph)
+	results := make([]Result, 0, len(instances))
+	for _, instance := range instances {
+		instance = instance.DeepCopy()
+		// Instances are applied to the default namespace unless told otherwise.
+		if instance.GetNamespace() == "" {
+			instance.SetNamespace("default")
+		}
+		gvk := instance.GroupVersionKind()
+		rgd := findResourceGraphDefinition(rgds, gvk.Group, gvk.Kind)
+		if rgd == nil {
+			return nil, fmt.Errorf("no ResourceGraphDefinition found for the instances of %s", gvk.GroupKind())
+		}
+		rgdGraph, ok := graphs[rgd.Name]
+		if !ok {
+			// Each graph has its own resolver, which caches the schemas.
+			resolver, err := schema.NewOfflineResolver(opts.CRDs)
+			if err != nil {
+				return nil, err
+			}
+			builder := graph.NewOfflineBuilder(resolver, opts.KubernetesVersion)
+			rgdGraph, err = builder.NewResourceGraphDefinition(rgd, graph.WithSharedTypes(sharedTypes))
+			if err != nil {
+				return nil, fmt.Errorf("invalid ResourceGraphDefinition %s: %w", rgd.Name, err)
+			}
+			graphs[rgd.Name] = rgdGraph
+		}
+
+		values, err := rgdGraph.ResolveValues(instance, opts.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s %s: %w", gvk.Kind, instance.GetName(), err)
+		}
+		rendered, err := Render(rgdGraph, instance, graph.WithValues(values))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s %s: %w", gvk.Kind, instance.GetName(), err)
+		}
+		result := Result{
+			Instance: fmt.Sprintf("%s/%s/%s", gvk.Kind, instance.GetNamespace(), instance.GetName()),
+			File:     filepath.Join(dir, FileName(instance)),
+		}
+		if opts.Update {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(result.File, rendered, 0o644); err != nil {
+				return nil, err
+			}
+			result.Updated = true
+		} else {
+			expected, err := os.ReadFile(result.File)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the golden file of %s %s, it can be created with the "+
+					"update mode: %w", gvk.Kind, instance.GetName(), err)
+			}
+			result.Diff = Diff(string(expected), string(rendered))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Mismatches returns an error listing the instances whose rendered resources
+// differ from their golden files, with the differences, or nil if they all
+// match.
+func Mismatches(results []Result) error {
+	var errs []error
+	for _, result := range results {
+		if result.Diff != "" {
+			errs = append(errs, fmt.Errorf("resources of %s differ from %s:\n%s", result.Instance, result.File, result.Diff))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FileName returns the name of the golden file of the given instance.
+func FileName(instance *unstructured.Unstructured) string {
+	return strings.ToLower(instance.GetKind()) + "-" + instance.GetName() + ".yaml"
+}
+
+// Render renders the resources of the given instance of the graph, with the
+// defaults of its schema applied, and returns them as YAML documents.
+func Render(rgdGraph *graph.Graph, instance *unstructured.Unstructured, opts ...graph.RuntimeOption) ([]byte, error) {
+	instance = instance.DeepCopy()
+	if err := crd.ApplyDefaults(instance, rgdGraph.Instance.GetCRD(), instance.GroupVersionKind().Version); err != nil {
+		return nil, err
+	}
+	if _, err := rgdGraph.ApplyDefaults(instance, opts...); err != nil {
+		return nil, err
+	}
+	rt, err := rgdGraph.NewGraphRuntime(instance, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := rt.Render()
+	if err != nil {
+		return nil, err
+	}
+	return Marshal(rendered)
+}
+
+// Marshal returns the given rendered resources as YAML documents, each
+// preceded by comments telling which resource it is, and listing the
+// expressions evaluated at runtime. The external references and the excluded
+// resources are only listed in comments.
+func Marshal(rendered []runtime.RenderedResource) ([]byte, error) {
+	var b bytes.Buffer
+	for i, resource := range rendered {
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		fmt.Fprintf(&b, "# Source: %s\n", resource.ID)
+		switch {
+		case resource.External:
+			b.WriteString("# External reference, read from the cluster\n")
+		case resource.Excluded:
+			fmt.Fprintf(&b, "# Excluded: %s\n", resource.ExcludedReason)
+		}
+		if len(resource.Unresolved) > 0 {
+			b.WriteString("# Evaluated at runtime:\n")
+			for _, expr := range resource.Unresolved {
+				fmt.Fprintf(&b, "#   %s: ${%s}\n", expr.Field, expr.Expression)
+			}
+		}
+		if !resource.External && !resource.Excluded {
+			data, err := yaml.Marshal(resource.Object.Object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal resource %s: %w", resource.ID, err)
+			}
+			b.Write(data)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+func findResourceGraphDefinition(
+	rgds []*v1alpha1.ResourceGraphDefinition,
+	group, kind string,
+) *v1alpha1.ResourceGraphDefinition {
+	for _, rgd := range rgds {
+		if rgd.Spec.Schema == nil || rgd.Spec.Schema.Kind != kind {
+			continue
+		}
+		rgdGroup := rgd.Spec.Schema.Group
+		if rgdGroup == "" {
+			rgdGroup = v1alpha1.KRODomainName
+		}
+		if rgdGroup == group {
+			return rgd
+		}
+	}
+	return nil
+}
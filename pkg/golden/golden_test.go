@@ -0,0 +1,148 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+const webAppRGD = `
+apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: webapp
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: WebApp
+    spec:
+      name: string
+      replicas: integer | default=2
+  resources:
+  - id: deployment
+    template:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: ${schema.spec.name}
+      spec:
+        replicas: ${schema.spec.replicas}
+        selector:
+          matchLabels:
+            app: ${schema.spec.name}
+        template:
+          metadata:
+            labels:
+              app: ${schema.spec.name}
+          spec:
+            containers:
+            - name: app
+              image: nginx
+`
+
+func newWebApp(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kro.run/v1alpha1",
+		"kind":       "WebApp",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       spec,
+	}}
+}
+
+func TestCheck(t *testing.T) {
+	rgd := &v1alpha1.ResourceGraphDefinition{}
+	require.NoError(t, yaml.Unmarshal([]byte(webAppRGD), rgd))
+	rgds := []*v1alpha1.ResourceGraphDefinition{rgd}
+	instances := []*unstructured.Unstructured{newWebApp("my-app", map[string]interface{}{"name": "web"})}
+	dir := filepath.Join(t.TempDir(), "golden")
+
+	// The golden files don't exist yet.
+	_, err := Check(rgds, instances, dir, Options{})
+	require.Error(t, err)
+
+	results, err := Check(rgds, instances, dir, Options{Update: true})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Updated)
+	assert.Equal(t, "WebApp/default/my-app", results[0].Instance)
+	assert.Equal(t, filepath.Join(dir, "webapp-my-app.yaml"), results[0].File)
+
+	data, err := os.ReadFile(results[0].File)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Source: deployment\n")
+	assert.Contains(t, string(data), "replicas: 2\n")
+
+	results, err = Check(rgds, instances, dir, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, results[0].Diff)
+	assert.NoError(t, Mismatches(results))
+
+	// Changing the instance changes the rendered resources.
+	instances[0].Object["spec"] = map[string]interface{}{"name": "web", "replicas": int64(3)}
+	results, err = Check(rgds, instances, dir, Options{})
+	require.NoError(t, err)
+	assert.Contains(t, results[0].Diff, "-  replicas: 2\n+  replicas: 3\n")
+	assert.ErrorContains(t, Mismatches(results), "resources of WebApp/default/my-app differ")
+
+	_, err = Check(rgds, []*unstructured.Unstructured{{Object: map[string]interface{}{
+		"apiVersion": "kro.run/v1alpha1",
+		"kind":       "Database",
+		"metadata":   map[string]interface{}{"name": "db"},
+	}}}, dir, Options{})
+	assert.ErrorContains(t, err, "no ResourceGraphDefinition found")
+}
+
+func TestDiff(t *testing.T) {
+	assert.Empty(t, Diff("a\nb\n", "a\nb\n"))
+
+	lines := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		lines = append(lines, string(rune('a'+i)))
+	}
+	expected := strings.Join(lines, "\n") + "\n"
+	lines[1] = "B"
+	lines[15] = "P"
+	actual := strings.Join(lines, "\n") + "\n"
+	assert.Equal(t, `--- expected
++++ actual
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -13,7 +13,7 @@
+ m
+ n
+ o
+-p
++P
+ q
+ r
+ s
+`, Diff(expected, actual))
+
+	assert.Contains(t, Diff("a", "a\n"), "trailing newline")
+}
@@ -14,36 +14,57 @@
 package graph
 
 import (
+	"cmp"
 	"fmt"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
 	"golang.org/x/exp/maps"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
 	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/apiserver/pkg/cel/openapi/resolver"
 	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 
 	"github.com/kro-run/kro/api/v1alpha1"
 	krocel "github.com/kro-run/kro/pkg/cel"
 	"github.com/kro-run/kro/pkg/cel/ast"
+	"github.com/kro-run/kro/pkg/graph/conversion"
 	"github.com/kro-run/kro/pkg/graph/crd"
 	"github.com/kro-run/kro/pkg/graph/dag"
 	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
 	"github.com/kro-run/kro/pkg/graph/parser"
 	"github.com/kro-run/kro/pkg/graph/schema"
 	"github.com/kro-run/kro/pkg/graph/variable"
 	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/runtime"
 	"github.com/kro-run/kro/pkg/simpleschema"
 )
 
-// NewBuilder creates a new GraphBuilder instance.
+// NewBuilder creates a new GraphBuilder instance. The given facts of the
+// cluster are exposed to the expressions of the instances, along with the
+// version of the API server.
 func NewBuilder(
 	clientConfig *rest.Config,
+	cluster runtime.Cluster,
 ) (*Builder, error) {
 	schemaResolver, dc, err := schema.NewCombinedResolver(clientConfig)
 	if err != nil {
@@ -56,10 +77,28 @@ func NewBuilder(
 		resourceEmulator: resourceEmulator,
 		schemaResolver:   schemaResolver,
 		discoveryClient:  dc,
+		cluster:          cluster,
 	}
 	return rgBuilder, nil
 }
 
+// NewOfflineBuilder creates a new GraphBuilder instance that doesn't need
+// access to a cluster, to validate resource graph definitions offline. The
+// schemas of the resources are resolved with the given resolver, and the
+// expressions of the instances see the given version of Kubernetes. The scope
+// of the resources isn't known offline: the graphs it builds are meant to be
+// validated, not reconciled.
+func NewOfflineBuilder(schemaResolver resolver.SchemaResolver, kubernetesVersion string) *Builder {
+	return &Builder{
+		resourceEmulator: emulator.NewEmulator(),
+		schemaResolver:   schemaResolver,
+		discoveryClient: &fakediscovery.FakeDiscovery{
+			Fake:               &k8stesting.Fake{},
+			FakedServerVersion: &version.Info{GitVersion: kubernetesVersion},
+		},
+	}
+}
+
 // Builder is an object that is responsible of constructing and managing
 // resourceGraphDefinitions. It is responsible of transforming the resourceGraphDefinition CRD
 // into a runtime representation that can be used to create the resources in
@@ -94,13 +133,23 @@ type Builder struct {
 	// validate the CEL expressions. To revisit.
 	resourceEmulator *emulator.Emulator
 	discoveryClient  discovery.DiscoveryInterface
+	// cluster holds the facts of the cluster configured for the controller.
+	cluster runtime.Cluster
 }
 
 // NewResourceGraphDefinition creates a new ResourceGraphDefinition object from the given ResourceGraphDefinition
 // CRD. The ResourceGraphDefinition object is a fully processed and validated representation
 // of the resource graph definition CRD, it's underlying resources, and the relationships between
 // the resources.
-func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphDefinition) (*Graph, error) {
+func (b *Builder) NewResourceGraphDefinition(
+	originalCR *v1alpha1.ResourceGraphDefinition,
+	opts ...BuildOption,
+) (*Graph, error) {
+	options := &buildOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	// Before anything else, let's copy the resource graph definition to avoid modifying the
 	// original object.
 	rgd := originalCR.DeepCopy()
@@ -140,9 +189,63 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 		}
 	}
 
+	// The version of the API server changes when the cluster is upgraded, it's
+	// looked up whenever a resource graph definition is built.
+	serverVersion, err := b.discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve Kubernetes version: %w", err)
+	}
+
+	if err := validateRetryPolicy(rgd.Spec.Retry); err != nil {
+		return nil, fmt.Errorf("invalid retry policy: %w", err)
+	}
+	if rgd.Spec.ServiceAccount != nil && len(rgd.Spec.DefaultServiceAccounts) > 0 {
+		return nil, fmt.Errorf("serviceAccount and defaultServiceAccounts are mutually exclusive")
+	}
+	var namespaceSelector labels.Selector
+	if rgd.Spec.NamespaceSelector != nil {
+		namespaceSelector, err = metav1.LabelSelectorAsSelector(rgd.Spec.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespaceSelector: %w", err)
+		}
+	}
+	var resyncPeriod time.Duration
+	if rgd.Spec.Reconcile != nil && rgd.Spec.Reconcile.ResyncPeriod != nil {
+		resyncPeriod = rgd.Spec.Reconcile.ResyncPeriod.Duration
+		if resyncPeriod <= 0 {
+			return nil, fmt.Errorf("invalid reconcile policy: resyncPeriod must be positive, got %s", resyncPeriod)
+		}
+	}
+	var ttlAfterReady, ttlAfterFailure time.Duration
+	if ttl := rgd.Spec.TTL; ttl != nil {
+		if ttl.AfterReady != nil {
+			ttlAfterReady = ttl.AfterReady.Duration
+			if ttlAfterReady <= 0 {
+				return nil, fmt.Errorf("invalid ttl: afterReady must be positive, got %s", ttlAfterReady)
+			}
+		}
+		if ttl.AfterFailure != nil {
+			ttlAfterFailure = ttl.AfterFailure.Duration
+			if ttlAfterFailure <= 0 {
+				return nil, fmt.Errorf("invalid ttl: afterFailure must be positive, got %s", ttlAfterFailure)
+			}
+		}
+	}
+
+	// The Jobs of the hooks and the objects resources wait for are built like
+	// any other resource.
+	rgResources, postApplyJobs, err := expandHooks(&rgd.Spec)
+	if err != nil {
+		return nil, err
+	}
+	rgResources, waitedFor, err := expandWaitFor(rgResources)
+	if err != nil {
+		return nil, err
+	}
+
 	// we'll also store the resources in a map for easy access later.
 	resources := make(map[string]*Resource)
-	for i, rgResource := range rgd.Spec.Resources {
+	for i, rgResource := range rgResources {
 		id := rgResource.ID
 		order := i
 		r, err := b.buildRGResource(rgResource, namespacedResources, order)
@@ -152,6 +255,12 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 		if resources[id] != nil {
 			return nil, fmt.Errorf("found resources with duplicate id %q", id)
 		}
+		// Resources without their own retry policy follow the one of the
+		// resource graph definition.
+		r.retryPolicy = cmp.Or(r.retryPolicy, rgd.Spec.Retry)
+		// Likewise for the cluster they're applied to.
+		r.target = cmp.Or(r.target, rgd.Spec.Target)
+		r.gates = append(slices.Clone(postApplyJobs[id]), waitedFor[id]...)
 		resources[id] = r
 	}
 
@@ -194,7 +303,7 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 	// 3. Validate them against the resources defined in the resource graph definition.
 	// 4. Infer the status schema based on the CEL expressions.
 
-	instance, err := b.buildInstanceResource(
+	instance, specDefaults, err := b.buildInstanceResource(
 		rgd.Spec.Schema.Group,
 		rgd.Spec.Schema.APIVersion,
 		rgd.Spec.Schema.Kind,
@@ -202,20 +311,56 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 		// We need to pass the resources to the instance resource, so we can validate
 		// the CEL expressions in the context of the resources.
 		resources,
+		options.sharedTypes,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build resourcegraphdefinition '%v': %w", rgd.Name, err)
 	}
+	// The expressions can refer to the instance, the cluster, the values
+	// loaded from the ConfigMaps and Secrets of valuesFrom and the locals,
+	// besides the resources.
+	if _, ok := resources["values"]; ok && len(rgd.Spec.ValuesFrom) > 0 {
+		return nil, fmt.Errorf("resource id values is reserved when valuesFrom is set")
+	}
+	if _, ok := resources["locals"]; ok && len(rgd.Spec.Locals) > 0 {
+		return nil, fmt.Errorf("resource id locals is reserved when locals are declared")
+	}
+	variables := contextVariables(resources, len(rgd.Spec.ValuesFrom) > 0, len(rgd.Spec.Locals) > 0)
+
+	locals, err := buildLocals(rgd.Spec.Locals, variables, instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resourcegraphdefinition '%v' locals: %w", rgd.Name, err)
+	}
+
+	instance.hookAssertions, err = buildInstanceHooks(rgd.Spec.Hooks, variables, instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resourcegraphdefinition '%v' hooks: %w", rgd.Name, err)
+	}
+	// The defaults are applied before the locals are computed from the
+	// instance, so they can't refer to them.
+	defaults, err := buildInstanceDefaults(specDefaults,
+		contextVariables(resources, len(rgd.Spec.ValuesFrom) > 0, false), instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resourcegraphdefinition '%v' defaults: %w", rgd.Name, err)
+	}
+	if err := validateInstanceScope(rgd, resources); err != nil {
+		return nil, fmt.Errorf("failed to validate resourcegraphdefinition '%v': %w", rgd.Name, err)
+	}
 
 	// Before getting into the dependency graph, we need to validate the CEL expressions
 	// in the instance resource. In order to do that, we need to isolate each resource
 	// and evaluate the CEL expressions in the context of the resource graph definition. This is done
 	// by dry-running the CEL expressions against the emulated resources.
-	err = validateResourceCELExpressions(resources, instance)
+	err = validateResourceCELExpressions(resources, instance, variables)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate resource CEL expressions: %w", err)
 	}
 
+	conditions, err := buildInstanceConditions(rgd.Spec.Schema.Conditions, resources, instance, variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build instance conditions: %w", err)
+	}
+
 	// Now that we have the instance resource, we can move into the next stage of
 	// building the resource graph definition. Understanding the relationships between the
 	// resources in the resource graph definition a.k.a the dependency graph.
@@ -228,21 +373,78 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 	// The dependency graph is built by inspecting the CEL expressions in the
 	// resources and the instance resource, using a CEL AST (Abstract Syntax Tree)
 	// inspector.
-	dag, err := b.buildDependencyGraph(resources)
+	dag, edges, err := b.buildDependencyGraph(resources, variables)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
 	}
+	if err := validateForEachReferences(resources, instance, conditions); err != nil {
+		return nil, fmt.Errorf("failed to validate forEach references: %w", err)
+	}
 
 	topologicalOrder, err := dag.TopologicalSort()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get topological order: %w", err)
 	}
 
+	// Sensitive fields of the instance spec are redacted from the outputs of kro
+	// at runtime. Expressions writing them to anything but a Secret are likely
+	// to leak them, so we warn about those.
+	sensitiveFields := sensitiveFieldPaths(instanceSpecSchema(instance), "spec")
+	warnings, err := sensitiveFieldWarnings(instance, resources, variables, sensitiveFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check sensitive fields: %w", err)
+	}
+	// The linter reports the quality issues which don't prevent the resource
+	// graph definition from working, but are likely mistakes.
+	warnings = append(warnings, lintWarnings(instance, resources, variables, conditions, defaults, locals)...)
+
+	// Instances of the additional versions of the instance API are converted
+	// to and from the storage version.
+	var converter *conversion.Converter
+	if len(rgd.Spec.Schema.Versions) > 0 {
+		converter, err = conversion.NewConverter(rgd.Spec.Schema.APIVersion, rgd.Spec.Schema.Versions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build version conversion: %w", err)
+		}
+	}
+
+	var cluster *runtime.Cluster
+	if slices.Contains(variables, "cluster") {
+		cluster = &runtime.Cluster{
+			Domain:            b.cluster.Domain,
+			KubernetesVersion: serverVersion.GitVersion,
+			Region:            b.cluster.Region,
+			Environment:       b.cluster.Environment,
+		}
+	}
+
+	var serviceAccount *k8stypes.NamespacedName
+	if sa := rgd.Spec.ServiceAccount; sa != nil {
+		serviceAccount = &k8stypes.NamespacedName{Namespace: sa.Namespace, Name: sa.Name}
+	}
+
 	resourceGraphDefinition := &Graph{
-		DAG:              dag,
-		Instance:         instance,
-		Resources:        resources,
-		TopologicalOrder: topologicalOrder,
+		DAG:               dag,
+		Instance:          instance,
+		Resources:         resources,
+		TopologicalOrder:  topologicalOrder,
+		SensitiveFields:   sensitiveFields,
+		Warnings:          warnings,
+		Converter:         converter,
+		Conditions:        conditions,
+		RollbackOnFailure: rgd.Spec.RollbackOnFailure,
+		ResyncPeriod:      resyncPeriod,
+		TTLAfterReady:     ttlAfterReady,
+		TTLAfterFailure:   ttlAfterFailure,
+		Quota:             rgd.Spec.Quota,
+		ServiceAccount:    serviceAccount,
+		NamespaceSelector: namespaceSelector,
+		ValuesFrom:        rgd.Spec.ValuesFrom,
+		defaults:          defaults,
+		locals:            locals,
+		programs:          krocel.NewProgramCache(),
+		cluster:           cluster,
+		edges:             edges,
 	}
 	return resourceGraphDefinition, nil
 }
@@ -254,10 +456,9 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 func (b *Builder) buildRGResource(rgResource *v1alpha1.Resource, namespacedResources map[k8sschema.GroupKind]bool, order int) (*Resource, error) {
 	// 1. We need to unmarshal the resource into a map[string]interface{} to
 	//    make it easier to work with.
-	resourceObject := map[string]interface{}{}
-	err := yaml.UnmarshalStrict(rgResource.Template.Raw, &resourceObject)
+	resourceObject, err := parseResourceObject(rgResource)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal resource %s: %w", rgResource.ID, err)
+		return nil, err
 	}
 
 	// 1. Check if it looks like a valid Kubernetes resource.
@@ -300,11 +501,20 @@ func (b *Builder) buildRGResource(rgResource *v1alpha1.Resource, namespacedResou
 			return nil, fmt.Errorf("failed to generate dummy CR for resource %s: %w", rgResource.ID, err)
 		}
 
-		// 5. Extract CEL fieldDescriptors from the schema.
-		fieldDescriptors, err := parser.ParseResource(resourceObject, resourceSchema)
+		// 5. Extract CEL fieldDescriptors from the schema. The operations of
+		//    JSON patches don't follow the schema, they're parsed without.
+		object, jsonPatch := splitJSONPatch(resourceObject)
+		fieldDescriptors, err := parser.ParseResource(object, resourceSchema)
 		if err != nil {
 			return nil, fmt.Errorf("failed to extract CEL expressions from schema for resource %s: %w", rgResource.ID, err)
 		}
+		if jsonPatch != nil {
+			patchDescriptors, err := parser.ParseSchemalessResource(jsonPatch)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract CEL expressions from the patch of resource %s: %w", rgResource.ID, err)
+			}
+			fieldDescriptors = append(fieldDescriptors, patchDescriptors...)
+		}
 		for _, fieldDescriptor := range fieldDescriptors {
 			resourceVariables = append(resourceVariables, &variable.ResourceField{
 				// Assume variables are static, we'll validate them later
@@ -326,6 +536,59 @@ func (b *Builder) buildRGResource(rgResource *v1alpha1.Resource, namespacedResou
 		return nil, fmt.Errorf("failed to parse includeWhen expressions: %v", err)
 	}
 
+	// 8. Parse forEach expressions
+	forEach, forEachKey, err := parseForEach(rgResource.ForEach)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse forEach expressions: %v", err)
+	}
+
+	// 9. Parse the readiness timeout and failure policy
+	readinessTimeout, onFailure, err := parseReadinessPolicy(rgResource)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s: %w", rgResource.ID, err)
+	}
+
+	// 10. Validate the retry policy
+	if err := validateRetryPolicy(rgResource.Retry); err != nil {
+		return nil, fmt.Errorf("resource %s: invalid retry policy: %w", rgResource.ID, err)
+	}
+
+	// 11. External references are only read, there's nothing to heal
+	if rgResource.AutoHeal != nil && rgResource.ExternalRef != nil {
+		return nil, fmt.Errorf("resource %s: can't declare autoHeal with an externalRef", rgResource.ID)
+	}
+
+	// 12. Parse the conflict policy
+	conflictPolicy, err := parseConflictPolicy(rgResource)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s: %w", rgResource.ID, err)
+	}
+
+	// 13. Parse the paths of the ignored fields
+	ignoreFields, err := parseIgnoreFields(rgResource)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s: %w", rgResource.ID, err)
+	}
+
+	// 14. Parse the deletion policy
+	deletionPolicy, err := parseDeletionPolicy(rgResource)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s: %w", rgResource.ID, err)
+	}
+
+	// 15. Parse the adoption policy
+	adoptionPolicy, err := parseAdoptionPolicy(rgResource)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s: %w", rgResource.ID, err)
+	}
+
+	// 16. Parse the assertions of the hooks. Their Jobs were expanded into
+	//     resources beforehand.
+	hookAssertions, err := parseResourceHooks(rgResource)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s: %w", rgResource.ID, err)
+	}
+
 	_, isNamespaced := namespacedResources[gvk.GroupKind()]
 
 	// Note that at this point we don't inject the dependencies into the resource.
@@ -340,56 +603,213 @@ func (b *Builder) buildRGResource(rgResource *v1alpha1.Resource, namespacedResou
 		includeWhenExpressions: includeWhen,
 		namespaced:             isNamespaced,
 		order:                  order,
+		forEach:                forEach,
+		forEachKey:             forEachKey,
+		dependsOn:              rgResource.DependsOn,
+		external:               rgResource.ExternalRef != nil || rgResource.Patch != nil,
+		patchType:              patchType(rgResource),
+		readinessTimeout:       readinessTimeout,
+		onFailure:              onFailure,
+		retryPolicy:            rgResource.Retry,
+		autoHeal:               rgResource.AutoHeal == nil || *rgResource.AutoHeal,
+		conflictPolicy:         conflictPolicy,
+		ignoreFields:           ignoreFields,
+		deletionPolicy:         deletionPolicy,
+		adoptionPolicy:         adoptionPolicy,
+		hookAssertions:         hookAssertions,
+		target:                 rgResource.Target,
 	}, nil
 }
 
+// parseConflictPolicy returns the conflict policy of the given resource,
+// defaulting to Force.
+func parseConflictPolicy(rgResource *v1alpha1.Resource) (v1alpha1.ConflictPolicy, error) {
+	switch rgResource.ConflictPolicy {
+	case "":
+		return v1alpha1.ConflictPolicyForce, nil
+	case v1alpha1.ConflictPolicyFail, v1alpha1.ConflictPolicyForce, v1alpha1.ConflictPolicyIgnoreFields:
+		if rgResource.ExternalRef != nil {
+			return "", fmt.Errorf("can't declare a conflictPolicy with an externalRef")
+		}
+		return rgResource.ConflictPolicy, nil
+	default:
+		return "", fmt.Errorf("unknown conflictPolicy %q", rgResource.ConflictPolicy)
+	}
+}
+
+// parseIgnoreFields parses the paths of the ignored fields of the given
+// resource. The fields identifying the resource can't be ignored.
+func parseIgnoreFields(rgResource *v1alpha1.Resource) ([][]fieldpath.Segment, error) {
+	if len(rgResource.IgnoreFields) == 0 {
+		return nil, nil
+	}
+	if rgResource.ExternalRef != nil {
+		return nil, fmt.Errorf("can't declare ignoreFields with an externalRef")
+	}
+
+	paths := make([][]fieldpath.Segment, 0, len(rgResource.IgnoreFields))
+	for _, field := range rgResource.IgnoreFields {
+		segments, err := fieldpath.Parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignored field %q: %w", field, err)
+		}
+		if len(segments) == 0 || segments[0].Index != -1 {
+			return nil, fmt.Errorf("invalid ignored field %q", field)
+		}
+		switch fieldpath.Build(segments[:min(len(segments), 2)]) {
+		case "apiVersion", "kind", "metadata", "metadata.name", "metadata.namespace":
+			return nil, fmt.Errorf("can't ignore field %q identifying the resource", field)
+		}
+		paths = append(paths, segments)
+	}
+	return paths, nil
+}
+
+// parseDeletionPolicy returns the deletion policy of the given resource,
+// defaulting to Delete.
+func parseDeletionPolicy(rgResource *v1alpha1.Resource) (v1alpha1.DeletionPolicy, error) {
+	switch rgResource.DeletionPolicy {
+	case "":
+		return v1alpha1.DeletionPolicyDelete, nil
+	case v1alpha1.DeletionPolicyDelete, v1alpha1.DeletionPolicyOrphan, v1alpha1.DeletionPolicyRetain:
+		if rgResource.ExternalRef != nil {
+			return "", fmt.Errorf("can't declare a deletionPolicy with an externalRef")
+		}
+		return rgResource.DeletionPolicy, nil
+	default:
+		return "", fmt.Errorf("unknown deletionPolicy %q", rgResource.DeletionPolicy)
+	}
+}
+
+// parseAdoptionPolicy returns the adoption policy of the given resource,
+// defaulting to AdoptOrFail.
+func parseAdoptionPolicy(rgResource *v1alpha1.Resource) (v1alpha1.AdoptionPolicy, error) {
+	switch rgResource.AdoptionPolicy {
+	case "":
+		return v1alpha1.AdoptionPolicyAdoptOrFail, nil
+	case v1alpha1.AdoptionPolicyAdopt, v1alpha1.AdoptionPolicyAdoptOrFail, v1alpha1.AdoptionPolicyFail:
+		if rgResource.ExternalRef != nil {
+			return "", fmt.Errorf("can't declare an adoptionPolicy with an externalRef")
+		}
+		return rgResource.AdoptionPolicy, nil
+	default:
+		return "", fmt.Errorf("unknown adoptionPolicy %q", rgResource.AdoptionPolicy)
+	}
+}
+
+// validateRetryPolicy validates the delays of the given retry policy, if any.
+func validateRetryPolicy(policy *v1alpha1.RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.InitialDelay != nil && policy.InitialDelay.Duration <= 0 {
+		return fmt.Errorf("initialDelay must be positive, got %s", policy.InitialDelay.Duration)
+	}
+	if policy.MaxDelay != nil && policy.MaxDelay.Duration <= 0 {
+		return fmt.Errorf("maxDelay must be positive, got %s", policy.MaxDelay.Duration)
+	}
+	if policy.InitialDelay != nil && policy.MaxDelay != nil && policy.MaxDelay.Duration < policy.InitialDelay.Duration {
+		return fmt.Errorf("maxDelay %s can't be lower than initialDelay %s", policy.MaxDelay.Duration, policy.InitialDelay.Duration)
+	}
+	if policy.MaxAttempts < 0 {
+		return fmt.Errorf("maxAttempts must be positive, got %d", policy.MaxAttempts)
+	}
+	return nil
+}
+
+// parseReadinessPolicy returns the readiness timeout and the failure policy of
+// the given resource. The failure policy defaults to Retry, and only applies
+// to the resources kro creates.
+func parseReadinessPolicy(rgResource *v1alpha1.Resource) (time.Duration, v1alpha1.FailurePolicy, error) {
+	onFailure := rgResource.OnFailure
+	switch onFailure {
+	case "":
+		onFailure = v1alpha1.FailurePolicyRetry
+	case v1alpha1.FailurePolicyAbort, v1alpha1.FailurePolicyContinue, v1alpha1.FailurePolicyRetry:
+	default:
+		return 0, "", fmt.Errorf("unknown onFailure policy %q", onFailure)
+	}
+
+	if rgResource.ReadinessTimeout == nil {
+		if onFailure != v1alpha1.FailurePolicyRetry {
+			return 0, "", fmt.Errorf("onFailure policy %s requires a readinessTimeout", onFailure)
+		}
+		return 0, onFailure, nil
+	}
+	if rgResource.ExternalRef != nil {
+		return 0, "", fmt.Errorf("can't declare a readinessTimeout with an externalRef")
+	}
+	if rgResource.ReadinessTimeout.Duration <= 0 {
+		return 0, "", fmt.Errorf("readinessTimeout must be positive, got %s", rgResource.ReadinessTimeout.Duration)
+	}
+	return rgResource.ReadinessTimeout.Duration, onFailure, nil
+}
+
 // buildDependencyGraph builds the dependency graph between the resources in the
 // resource graph definition. The dependency graph is an directed acyclic graph that represents
 // the relationships between the resources in the resource graph definition. The graph is used
 // to determine the order in which the resources should be created in the cluster.
 //
-// This function returns the DAG, and a map of runtime variables per resource. later
-// on we'll use this map to resolve the runtime variables.
+// This function returns the DAG, and the origins of its edges.
 func (b *Builder) buildDependencyGraph(
 	resources map[string]*Resource,
+	variables []string,
 ) (
 	// directed acyclic graph
 	*dag.DirectedAcyclicGraph[string],
-	// map of runtime variables per resource
+	// origins of the edges of the graph
+	dependencyEdges,
 	error,
 ) {
 
 	resourceNames := maps.Keys(resources)
-	// We also want to allow users to refer to the instance spec in their expressions.
-	resourceNames = append(resourceNames, "schema")
+	// We also want to allow users to refer to the instance spec, the cluster
+	// and the values in their expressions.
+	resourceNames = append(resourceNames, variables...)
 
 	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(resourceNames))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+		return nil, nil, fmt.Errorf("failed to create CEL environment: %w", err)
 	}
 
 	directedAcyclicGraph := dag.NewDirectedAcyclicGraph[string]()
 	// Set the vertices of the graph to be the resources defined in the resource graph definition.
 	for _, resource := range resources {
 		if err := directedAcyclicGraph.AddVertex(resource.id, resource.order); err != nil {
-			return nil, fmt.Errorf("failed to add vertex to graph: %w", err)
+			return nil, nil, fmt.Errorf("failed to add vertex to graph: %w", err)
 		}
 	}
 
+	// Templates iterating over a collection can also refer to the current item.
+	forEachNames := append(slices.Clone(resourceNames), "each")
+	forEachVariables := append(slices.Clone(variables), "each")
+	forEachEnv, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(forEachNames))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	edges := make(dependencyEdges)
 	for _, resource := range resources {
+		env, resourceNames, variables := env, resourceNames, variables
+		if resource.IsForEach() {
+			env, resourceNames, variables = forEachEnv, forEachNames, forEachVariables
+		}
 		for _, resourceVariable := range resource.variables {
 			for _, expression := range resourceVariable.Expressions {
 				// We need to inspect the expression to understand how it relates to the
 				// resources defined in the resource graph definition.
+				field := "template." + resourceVariable.Path
 				err := validateCELExpressionContext(env, expression, resourceNames)
 				if err != nil {
-					return nil, fmt.Errorf("failed to validate expression context: %w", err)
+					return nil, nil, fmt.Errorf("failed to validate expression context: %w",
+						krocel.NewExpressionError(resource.id, field, expression, err))
 				}
 
 				// We need to extract the dependencies from the expression.
-				resourceDependencies, isStatic, err := extractDependencies(env, expression, resourceNames)
+				resourceDependencies, isStatic, err := extractDependencies(env, expression, resourceNames, variables)
 				if err != nil {
-					return nil, fmt.Errorf("failed to extract dependencies: %w", err)
+					return nil, nil, fmt.Errorf("failed to extract dependencies: %w",
+						krocel.NewExpressionError(resource.id, field, expression, err))
 				}
 
 				// Static until proven dynamic.
@@ -403,14 +823,86 @@ func (b *Builder) buildDependencyGraph(
 				resource.addDependencies(resourceDependencies...)
 				resourceVariable.AddDependencies(resourceDependencies...)
 				// We need to add the dependencies to the graph.
+				edges.add(resource.id, resourceDependencies, fmt.Sprintf("%s: ${%s}", resourceVariable.Path, expression))
 				if err := directedAcyclicGraph.AddDependencies(resource.id, resourceDependencies); err != nil {
-					return nil, err
+					return nil, nil, edges.explainCycle(err)
+				}
+			}
+		}
+
+		// The includeWhen conditions are evaluated once the resources they
+		// refer to are reconciled.
+		for i, expression := range resource.includeWhenExpressions {
+			field := fmt.Sprintf("includeWhen[%d]", i)
+			dependencies, _, err := extractDependencies(env, expression, resourceNames, variables)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to extract dependencies: %w",
+					krocel.NewExpressionError(resource.id, field, expression, err))
+			}
+			resource.addDependencies(dependencies...)
+			edges.add(resource.id, dependencies, fmt.Sprintf("%s: ${%s}", field, expression))
+			if err := directedAcyclicGraph.AddDependencies(resource.id, dependencies); err != nil {
+				return nil, nil, edges.explainCycle(err)
+			}
+		}
+
+		// Explicit dependencies order resources that don't refer to each
+		// other, e.g a Namespace and the resources created in it.
+		for _, dependency := range resource.dependsOn {
+			if dependency == resource.id {
+				return nil, nil, fmt.Errorf("resource %s can't depend on itself", resource.id)
+			}
+			if _, ok := resources[dependency]; !ok {
+				return nil, nil, fmt.Errorf("resource %s depends on unknown resource %s", resource.id, dependency)
+			}
+		}
+		resource.addDependencies(resource.dependsOn...)
+		edges.add(resource.id, resource.dependsOn, "dependsOn")
+		if err := directedAcyclicGraph.AddDependencies(resource.id, resource.dependsOn); err != nil {
+			return nil, nil, edges.explainCycle(err)
+		}
+
+		// The assertions of the hooks are checked once the resources they
+		// refer to are reconciled.
+		for _, phase := range hookPhases {
+			for _, expression := range resource.hookAssertions[phase] {
+				dependencies, _, err := extractDependencies(env, expression, resourceNames, variables)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to extract dependencies: %w",
+						krocel.NewExpressionError(resource.id, "hooks."+string(phase), expression, err))
+				}
+				if slices.Contains(dependencies, resource.id) {
+					if phase == runtime.HookPhasePreApply {
+						return nil, nil, fmt.Errorf("preApply assertion %s of resource %s can't refer to the resource", expression, resource.id)
+					}
+					dependencies = slices.DeleteFunc(dependencies, func(id string) bool { return id == resource.id })
+				}
+				resource.addDependencies(dependencies...)
+				edges.add(resource.id, dependencies, fmt.Sprintf("%s: ${%s}", phase, expression))
+				if err := directedAcyclicGraph.AddDependencies(resource.id, dependencies); err != nil {
+					return nil, nil, edges.explainCycle(err)
 				}
 			}
 		}
 	}
 
-	return directedAcyclicGraph, nil
+	// The resources depending on a resource also wait for the Jobs of its
+	// postApply hooks and the objects it waits for.
+	for _, resource := range resources {
+		for _, dependency := range slices.Clone(resource.dependencies) {
+			gates := resources[dependency].gates
+			if len(gates) == 0 || slices.Contains(gates, resource.id) {
+				continue
+			}
+			resource.addDependencies(gates...)
+			edges.add(resource.id, gates, "postApply hooks and waitFor of "+dependency)
+			if err := directedAcyclicGraph.AddDependencies(resource.id, gates); err != nil {
+				return nil, nil, edges.explainCycle(err)
+			}
+		}
+	}
+
+	return directedAcyclicGraph, edges, nil
 }
 
 // buildInstanceResource builds the instance resource. The instance resource is
@@ -423,7 +915,8 @@ func (b *Builder) buildInstanceResource(
 	group, apiVersion, kind string,
 	rgDefinition *v1alpha1.Schema,
 	resources map[string]*Resource,
-) (*Resource, error) {
+	sharedTypes map[string]interface{},
+) (*Resource, []simpleschema.ExpressionDefault, error) {
 	// The instance resource is the resource users will create in their cluster,
 	// to request the creation of the resources defined in the resource graph definition.
 	//
@@ -440,39 +933,87 @@ func (b *Builder) buildInstanceResource(
 	unstructuredInstance := map[string]interface{}{}
 	err := yaml.UnmarshalStrict(rgDefinition.Spec.Raw, &unstructuredInstance)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal instance schema: %w", err)
+		return nil, nil, fmt.Errorf("failed to unmarshal instance schema: %w", err)
 	}
 
 	// The instance resource has a schema defined using the "SimpleSchema" format.
-	instanceSpecSchema, err := buildInstanceSpecSchema(rgDefinition)
+	instanceSpecSchema, printerColumns, defaults, err := buildInstanceSpecSchema(rgDefinition, sharedTypes, b.resolveJSONSchema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build OpenAPI schema for instance: %w", err)
+		return nil, nil, fmt.Errorf("failed to build OpenAPI schema for instance: %w", err)
 	}
 
 	instanceStatusSchema, statusVariables, err := buildStatusSchema(rgDefinition, resources)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build OpenAPI schema for instance status: %w", err)
+		return nil, nil, fmt.Errorf("failed to build OpenAPI schema for instance status: %w", err)
 	}
 
 	// Synthesize the CRD for the instance resource.
 	overrideStatusFields := true
 	instanceCRD := crd.SynthesizeCRD(group, apiVersion, kind, *instanceSpecSchema, *instanceStatusSchema, overrideStatusFields)
+	if err := crd.AddPrinterColumns(instanceCRD, printerColumns); err != nil {
+		return nil, nil, fmt.Errorf("failed to add printer columns to instance CRD: %w", err)
+	}
+	namespaced := rgDefinition.Scope != v1alpha1.InstanceScopeCluster
+	if !namespaced {
+		instanceCRD.Spec.Scope = extv1.ClusterScoped
+	}
+	if err := setInstanceCRDMetadata(instanceCRD, rgDefinition); err != nil {
+		return nil, nil, fmt.Errorf("invalid instance CRD metadata: %w", err)
+	}
+
+	// Additional versions have their own spec, but share the types and the
+	// status of the storage version.
+	for _, version := range rgDefinition.Versions {
+		versionDefinition := rgDefinition.DeepCopy()
+		versionDefinition.Spec = version.Spec
+		// Validations reference the fields of the storage version.
+		versionDefinition.Validations = nil
+		versionSpecSchema, versionPrinterColumns, versionDefaults, err := buildInstanceSpecSchema(versionDefinition, sharedTypes, b.resolveJSONSchema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build OpenAPI schema for version %s: %w", version.Name, err)
+		}
+		// Instances are reconciled in the storage version, which holds the
+		// expression defaults.
+		if len(versionDefaults) > 0 {
+			return nil, nil, fmt.Errorf("version %s can't declare expression defaults, only the storage version can", version.Name)
+		}
+		err = crd.AddVersion(instanceCRD, version.Name, *versionSpecSchema, *instanceStatusSchema, overrideStatusFields, version.Deprecated)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to add version %s to instance CRD: %w", version.Name, err)
+		}
+		if err := crd.AddVersionPrinterColumns(instanceCRD, version.Name, versionPrinterColumns); err != nil {
+			return nil, nil, fmt.Errorf("failed to add printer columns to version %s: %w", version.Name, err)
+		}
+	}
+
+	// The scale subresource is served by the storage version, additional
+	// versions may not have the replicas fields.
+	if scale := rgDefinition.Scale; scale != nil {
+		err := crd.SetScale(instanceCRD, apiVersion, &extv1.CustomResourceSubresourceScale{
+			SpecReplicasPath:   scale.SpecReplicasPath,
+			StatusReplicasPath: scale.StatusReplicasPath,
+			LabelSelectorPath:  scale.LabelSelectorPath,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to set scale subresource of instance CRD: %w", err)
+		}
+	}
 
 	// Emulate the CRD
 	instanceSchemaExt := instanceCRD.Spec.Versions[0].Schema.OpenAPIV3Schema
 	instanceSchema, err := schema.ConvertJSONSchemaPropsToSpecSchema(instanceSchemaExt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert JSON schema to spec schema: %w", err)
+		return nil, nil, fmt.Errorf("failed to convert JSON schema to spec schema: %w", err)
 	}
 	emulatedInstance, err := b.resourceEmulator.GenerateDummyCR(gvk, instanceSchema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate dummy CR for instance: %w", err)
+		return nil, nil, fmt.Errorf("failed to generate dummy CR for instance: %w", err)
 	}
 
 	resourceNames := maps.Keys(resources)
 	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(resourceNames))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+		return nil, nil, fmt.Errorf("failed to create CEL environment: %w", err)
 	}
 
 	// The instance resource has a set of variables that need to be resolved.
@@ -482,6 +1023,7 @@ func (b *Builder) buildInstanceResource(
 		schema:         instanceSchema,
 		crd:            instanceCRD,
 		emulatedObject: emulatedInstance,
+		namespaced:     namespaced,
 	}
 
 	instanceStatusVariables := []*variable.ResourceField{}
@@ -490,12 +1032,12 @@ func (b *Builder) buildInstanceResource(
 		path := "status." + statusVariable.Path
 		statusVariable.Path = path
 
-		instanceDependencies, isStatic, err := extractDependencies(env, statusVariable.Expressions[0], resourceNames)
+		instanceDependencies, isStatic, err := extractDependencies(env, statusVariable.Expressions[0], resourceNames, nil)
 		if err != nil {
-			return nil, fmt.Errorf("failed to extract dependencies: %w", err)
+			return nil, nil, fmt.Errorf("failed to extract dependencies: %w", err)
 		}
 		if isStatic {
-			return nil, fmt.Errorf("instance status field must refer to a resource: %s", statusVariable.Path)
+			return nil, nil, fmt.Errorf("instance status field must refer to a resource: %s", statusVariable.Path)
 		}
 		instance.addDependencies(instanceDependencies...)
 
@@ -507,27 +1049,88 @@ func (b *Builder) buildInstanceResource(
 	}
 
 	instance.variables = instanceStatusVariables
-	return instance, nil
+	return instance, defaults, nil
 }
 
-// buildInstanceSpecSchema builds the instance spec schema that will be
+// BuildInstanceSpecSchema builds the instance spec schema that will be
 // used to generate the CRD for the instance resource. The instance spec
 // schema is expected to be defined using the "SimpleSchema" format.
-func buildInstanceSpecSchema(rgSchema *v1alpha1.Schema) (*extv1.JSONSchemaProps, error) {
+func BuildInstanceSpecSchema(rgSchema *v1alpha1.Schema) (*extv1.JSONSchemaProps, error) {
+	instanceSchema, _, _, err := buildInstanceSpecSchema(rgSchema, nil, nil)
+	return instanceSchema, err
+}
+
+// buildInstanceSpecSchema builds the instance spec schema, resolving custom
+// types from the given shared types and the types of the schema. Types defined
+// in the schema take precedence over shared types with the same name. Types
+// imported from existing kinds are resolved with the given schema resolver.
+//
+// It also returns the printer columns declared in the spec, with JSON paths
+// relative to the instance, and the expression defaults, with paths relative
+// to the spec.
+func buildInstanceSpecSchema(
+	rgSchema *v1alpha1.Schema,
+	sharedTypes map[string]interface{},
+	schemaResolver simpleschema.SchemaResolver,
+) (*extv1.JSONSchemaProps, []extv1.CustomResourceColumnDefinition, []simpleschema.ExpressionDefault, error) {
 	// We need to unmarshal the instance schema to a map[string]interface{} to
 	// make it easier to work with.
 	instanceSpec := map[string]interface{}{}
 	err := yaml.UnmarshalStrict(rgSchema.Spec.Raw, &instanceSpec)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal spec schema: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to unmarshal spec schema: %w", err)
 	}
 
+	// Custom types can be referenced by name in the spec.
+	localTypes := map[string]interface{}{}
+	if len(rgSchema.Types.Raw) > 0 {
+		if err := yaml.UnmarshalStrict(rgSchema.Types.Raw, &localTypes); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to unmarshal types: %w", err)
+		}
+	}
+	customTypes := make(map[string]interface{}, len(sharedTypes)+len(localTypes))
+	maps.Copy(customTypes, sharedTypes)
+	maps.Copy(customTypes, localTypes)
+
 	// The instance resource has a schema defined using the "SimpleSchema" format.
-	instanceSchema, err := simpleschema.ToOpenAPISpec(instanceSpec)
+	result, err := simpleschema.Convert(instanceSpec, simpleschema.Options{
+		Types:          customTypes,
+		SchemaResolver: schemaResolver,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to build OpenAPI schema for instance: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to build OpenAPI schema for instance: %v", err)
+	}
+	instanceSchema := result.Schema
+
+	// Printer columns are declared on spec fields.
+	printerColumns := make([]extv1.CustomResourceColumnDefinition, 0, len(result.PrinterColumns))
+	for _, column := range result.PrinterColumns {
+		column.JSONPath = ".spec" + column.JSONPath
+		printerColumns = append(printerColumns, column)
 	}
-	return instanceSchema, nil
+
+	// Block level validations are applied to the spec itself, which allows
+	// them to reference multiple fields.
+	for i, validation := range rgSchema.Validations {
+		if strings.TrimSpace(validation.Expression) == "" {
+			return nil, nil, nil, fmt.Errorf("validation %d: expression cannot be empty", i)
+		}
+		instanceSchema.XValidations = append(instanceSchema.XValidations, extv1.ValidationRule{
+			Rule:    validation.Expression,
+			Message: validation.Message,
+		})
+	}
+	return instanceSchema, printerColumns, result.ExpressionDefaults, nil
+}
+
+// resolveJSONSchema returns the schema of the given kind, as known by the API
+// server, in a form that can be embedded in the instance CRD.
+func (b *Builder) resolveJSONSchema(gvk k8sschema.GroupVersionKind) (*extv1.JSONSchemaProps, error) {
+	resolved, err := b.schemaResolver.ResolveSchema(gvk)
+	if err != nil {
+		return nil, err
+	}
+	return schema.ConvertSpecSchemaToJSONSchemaProps(resolved)
 }
 
 // buildStatusSchema builds the status schema for the instance resource. The
@@ -554,6 +1157,12 @@ func buildStatusSchema(
 		return nil, nil, fmt.Errorf("failed to extract CEL expressions from status: %w", err)
 	}
 
+	for _, found := range fieldDescriptors {
+		if found.Key {
+			return nil, nil, fmt.Errorf("status field %s: expressions can't be used in the keys of the status", found.Path)
+		}
+	}
+
 	// Inspection of the CEL expressions to infer the types of the status fields.
 	resourceNames := maps.Keys(resources)
 
@@ -573,13 +1182,15 @@ func buildStatusSchema(
 			// resources defined in the resource graph definition.
 			err := validateCELExpressionContext(env, expr, resourceNames)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to validate expression context: %w", err)
+				return nil, nil, fmt.Errorf("failed to validate expression context: %w",
+					krocel.NewExpressionError("", "schema.status."+found.Path, expr, err))
 			}
 
 			// resources is the context here.
 			value, err := dryRunExpression(env, expr, resources)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to dry-run expression: %w", err)
+				return nil, nil, fmt.Errorf("failed to dry-run expression: %w",
+					krocel.NewExpressionError("", "schema.status."+found.Path, expr, err))
 			}
 
 			evals = append(evals, value)
@@ -594,6 +1205,39 @@ func buildStatusSchema(
 	return statusSchema, fieldDescriptors, nil
 }
 
+// setInstanceCRDMetadata sets the short names, categories, labels and
+// annotations requested by the schema on the instance CRD. Labels in the kro
+// domain are rejected, they're set by kro to track the CRDs it owns.
+func setInstanceCRDMetadata(instanceCRD *extv1.CustomResourceDefinition, rgDefinition *v1alpha1.Schema) error {
+	for _, shortName := range rgDefinition.ShortNames {
+		if errs := validation.IsDNS1035Label(shortName); len(errs) > 0 {
+			return fmt.Errorf("invalid short name %q: %s", shortName, strings.Join(errs, ", "))
+		}
+	}
+	for _, category := range rgDefinition.Categories {
+		if errs := validation.IsDNS1035Label(category); len(errs) > 0 {
+			return fmt.Errorf("invalid category %q: %s", category, strings.Join(errs, ", "))
+		}
+	}
+	for key := range rgDefinition.Labels {
+		if strings.HasPrefix(key, metadata.LabelKROPrefix) {
+			return fmt.Errorf("label %q is reserved for kro", key)
+		}
+	}
+	if errs := metav1validation.ValidateLabels(rgDefinition.Labels, field.NewPath("labels")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	if errs := apivalidation.ValidateAnnotations(rgDefinition.Annotations, field.NewPath("annotations")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+
+	instanceCRD.Spec.Names.ShortNames = rgDefinition.ShortNames
+	instanceCRD.Spec.Names.Categories = rgDefinition.Categories
+	instanceCRD.Labels = maps.Clone(rgDefinition.Labels)
+	instanceCRD.Annotations = maps.Clone(rgDefinition.Annotations)
+	return nil
+}
+
 // validateCELExpressionContext validates the given CEL expression in the context
 // of the resources defined in the resource graph definition.
 func validateCELExpressionContext(env *cel.Env, expression string, resources []string) error {
@@ -621,11 +1265,10 @@ func validateCELExpressionContext(env *cel.Env, expression string, resources []s
 func dryRunExpression(env *cel.Env, expression string, resources map[string]*Resource) (ref.Val, error) {
 	ast, issues := env.Compile(expression)
 	if issues != nil && issues.Err() != nil {
-		return nil, fmt.Errorf("failed to compile expression: %w", issues.Err())
+		return nil, fmt.Errorf("failed to compile expression: %w", krocel.NewCompileError(issues))
 	}
 
-	// TODO(a-hilaly): thinking about a creating a library to hide this...
-	program, err := env.Program(ast)
+	program, err := krocel.NewProgram(env, ast, krocel.PerExpressionCostLimit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create program: %w", err)
 	}
@@ -633,21 +1276,117 @@ func dryRunExpression(env *cel.Env, expression string, resources map[string]*Res
 	context := map[string]interface{}{}
 	for resourceName, resource := range resources {
 		if resource.emulatedObject != nil {
-			context[resourceName] = resource.emulatedObject.Object
+			// Free-form maps, e.g the data of a Secret, can be accessed with
+			// any key.
+			object, err := emulator.OpenMaps(resource.emulatedObject.Object, resource.schema)
+			if err != nil {
+				return nil, fmt.Errorf("failed to emulate resource %s: %w", resourceName, err)
+			}
+			context[resourceName] = object
 		}
 	}
 
-	output, _, err := program.Eval(context)
+	// Expressions too costly to evaluate against the emulated resources would
+	// stall the reconciliation of the instances.
+	output, _, err := krocel.Evaluate(program, context)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate expression: %w", err)
 	}
 	return output, nil
 }
 
+// contextVariables returns the variables the expressions of the resources
+// can refer to besides the resources: the instance, the cluster, the values
+// with valuesFrom and the locals if any are declared. A resource with the id
+// `cluster` shadows the cluster variable, which was added once resources were
+// commonly named that way.
+func contextVariables(resources map[string]*Resource, values, locals bool) []string {
+	variables := []string{"schema"}
+	if _, ok := resources["cluster"]; !ok {
+		variables = append(variables, "cluster")
+	}
+	if values {
+		variables = append(variables, "values")
+	}
+	if locals {
+		variables = append(variables, "locals")
+	}
+	return variables
+}
+
+// emulatedVariables returns the emulated context variables among the given
+// ones, keyed by name.
+func emulatedVariables(variables []string, instance *Resource) map[string]*Resource {
+	context := map[string]*Resource{"schema": emulatedSchema(instance)}
+	if slices.Contains(variables, "cluster") {
+		context["cluster"] = emulatedCluster()
+	}
+	if slices.Contains(variables, "values") {
+		context["values"] = emulatedValues()
+	}
+	if slices.Contains(variables, "locals") {
+		context["locals"] = emulatedLocals(instance)
+	}
+	return context
+}
+
+// instanceMetadataSchema is the schema of the metadata of the instance, as
+// exposed by the schema variable. Its labels and annotations are open maps.
+var instanceMetadataSchema = &spec.Schema{
+	SchemaProps: spec.SchemaProps{
+		Type: []string{"object"},
+		Properties: map[string]spec.Schema{
+			"metadata": {
+				SchemaProps: spec.SchemaProps{
+					Type: []string{"object"},
+					Properties: map[string]spec.Schema{
+						"labels":      *spec.MapProperty(spec.StringProperty()),
+						"annotations": *spec.MapProperty(spec.StringProperty()),
+					},
+				},
+			},
+		},
+	},
+}
+
+// emulatedSchema returns the emulated instance, as exposed to the expressions
+// by the schema variable, without its status.
+func emulatedSchema(instance *Resource) *Resource {
+	emulated := instance.emulatedObject.DeepCopy()
+	delete(emulated.Object, "status")
+	return &Resource{
+		emulatedObject: &unstructured.Unstructured{Object: runtime.SchemaVariable(emulated)},
+		schema:         instanceMetadataSchema,
+	}
+}
+
+// emulatedCluster returns the emulated cluster variable. Expressions are
+// dry-run against sample facts, the facts of the cluster being only known at
+// runtime.
+func emulatedCluster() *Resource {
+	cluster := runtime.Cluster{
+		Domain:            "cluster.local",
+		KubernetesVersion: "v1.31.0",
+		Region:            "region",
+		Environment:       "environment",
+	}
+	return &Resource{emulatedObject: &unstructured.Unstructured{Object: cluster.Variable()}}
+}
+
+// emulatedValues returns the emulated values variable, an open map of
+// strings, the keys of the ConfigMaps and Secrets being only known at
+// runtime.
+func emulatedValues() *Resource {
+	return &Resource{
+		emulatedObject: &unstructured.Unstructured{Object: map[string]interface{}{}},
+		schema:         spec.MapProperty(spec.StringProperty()),
+	}
+}
+
 // extractDependencies extracts the dependencies from the given CEL expression.
 // It returns a list of dependencies and a boolea indicating if the expression
-// is static or not.
-func extractDependencies(env *cel.Env, expression string, resourceNames []string) ([]string, bool, error) {
+// is static or not. The given variables, e.g the instance, aren't dependencies.
+func extractDependencies(env *cel.Env, expression string, resourceNames, variables []string) ([]string, bool, error) {
 	// We also want to allow users to refer to the instance spec in their expressions.
 	inspector := ast.NewInspectorWithEnv(env, resourceNames, nil)
 
@@ -661,7 +1400,7 @@ func extractDependencies(env *cel.Env, expression string, resourceNames []string
 	isStatic := true
 	dependencies := make([]string, 0)
 	for _, resource := range inspectionResult.ResourceDependencies {
-		if resource.ID != "schema" && !slices.Contains(dependencies, resource.ID) {
+		if !slices.Contains(variables, resource.ID) && !slices.Contains(dependencies, resource.ID) {
 			isStatic = false
 			dependencies = append(dependencies, resource.ID)
 		}
@@ -683,40 +1422,31 @@ func extractDependencies(env *cel.Env, expression string, resourceNames []string
 // we evalute A's CEL expressions against 2 emulated resources B and C. Then
 // we evaluate B's CEL expressions against 2 emulated resources A and C, and so
 // on.
-func validateResourceCELExpressions(resources map[string]*Resource, instance *Resource) error {
+func validateResourceCELExpressions(resources map[string]*Resource, instance *Resource, variables []string) error {
 	resourceIDs := maps.Keys(resources)
-	// We also want to allow users to refer to the instance spec in their expressions.
-	resourceIDs = append(resourceIDs, "schema")
+	// We also want to allow users to refer to the instance spec, the cluster
+	// and the values in their expressions.
+	resourceIDs = append(resourceIDs, variables...)
 
 	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(resourceIDs))
 	if err != nil {
 		return fmt.Errorf("failed to create CEL environment: %w", err)
 	}
-	instanceEmulatedCopy := instance.emulatedObject.DeepCopy()
-	if instanceEmulatedCopy != nil && instanceEmulatedCopy.Object != nil {
-		delete(instanceEmulatedCopy.Object, "apiVersion")
-		delete(instanceEmulatedCopy.Object, "kind")
-		delete(instanceEmulatedCopy.Object, "status")
-	}
-
 	// create includeWhenContext
-	includeWhenContext := map[string]*Resource{}
-	// for now we will only support the instance context for includeWhen expressions.
-	// With this decision we will decide in creation time, and update time
-	// If we'll be creating resources or not
-	includeWhenContext["schema"] = &Resource{
-		emulatedObject: &unstructured.Unstructured{
-			Object: instanceEmulatedCopy.Object,
-		},
-	}
+	// The includeWhen expressions of the templates iterating over a collection
+	// can only refer to the instance and the current item, since they decide
+	// how many resources are created. The others can also refer to the
+	// resources they depend on.
+	includeWhenContext := emulatedVariables(variables, instance)
+	emulatedInstance := includeWhenContext["schema"]
+	delete(emulatedInstance.emulatedObject.Object, "apiVersion")
+	delete(emulatedInstance.emulatedObject.Object, "kind")
 
 	// create expressionsContext
 	expressionContext := map[string]*Resource{}
-	// add instance spec to the context
-	expressionContext["schema"] = &Resource{
-		emulatedObject: &unstructured.Unstructured{
-			Object: instanceEmulatedCopy.Object,
-		},
+	// add instance spec and the cluster to the context
+	for _, name := range variables {
+		expressionContext[name] = includeWhenContext[name]
 	}
 	// include all resources, and remove individual ones
 	// during the validation
@@ -729,9 +1459,28 @@ func validateResourceCELExpressions(resources map[string]*Resource, instance *Re
 		// exclude resource from the context
 		delete(expressionContext, resource.id)
 
-		err := ensureResourceExpressions(env, expressionContext, resource)
-		if err != nil {
-			return fmt.Errorf("failed to ensure resource %s expressions: %w", resource.id, err)
+		// Templates iterating over a collection are validated with an
+		// emulated item.
+		if resource.IsForEach() {
+			err := ensureForEachExpressions(expressionContext, includeWhenContext, resource)
+			if err != nil {
+				return fmt.Errorf("failed to ensure resource %s forEach expressions: %w", resource.id, err)
+			}
+		} else {
+			err := ensureResourceExpressions(env, expressionContext, resource)
+			if err != nil {
+				return fmt.Errorf("failed to ensure resource %s expressions: %w", resource.id, err)
+			}
+
+			err = ensureIncludeWhenExpressions(env, expressionContext, resource)
+			if err != nil {
+				return fmt.Errorf("failed to ensure resource %s includeWhen expressions: %w", resource.id, err)
+			}
+
+			err = ensureHookAssertions(env, expressionContext, resource)
+			if err != nil {
+				return fmt.Errorf("failed to ensure resource %s hook assertions: %w", resource.id, err)
+			}
 		}
 
 		err = ensureReadyWhenExpressions(resource)
@@ -739,11 +1488,6 @@ func validateResourceCELExpressions(resources map[string]*Resource, instance *Re
 			return fmt.Errorf("failed to ensure resource %s readyWhen expressions: %w", resource.id, err)
 		}
 
-		err = ensureIncludeWhenExpressions(env, includeWhenContext, resource)
-		if err != nil {
-			return fmt.Errorf("failed to ensure resource %s includeWhen expressions: %w", resource.id, err)
-		}
-
 		// include the resource back to the context
 		expressionContext[resource.id] = resource
 	}
@@ -756,10 +1500,16 @@ func validateResourceCELExpressions(resources map[string]*Resource, instance *Re
 func ensureResourceExpressions(env *cel.Env, context map[string]*Resource, resource *Resource) error {
 	// We need to validate the CEL expressions in the resource.
 	for _, resourceVariable := range resource.variables {
+		field := "template." + resourceVariable.Path
 		for _, expression := range resourceVariable.Expressions {
-			_, err := ensureExpression(env, expression, []string{resource.id}, context)
+			output, err := ensureExpression(env, expression, []string{resource.id}, context)
 			if err != nil {
-				return fmt.Errorf("failed to dry-run expression %s: %w", expression, err)
+				return krocel.NewExpressionError(resource.id, field, expression, err)
+			}
+			// Map keys are strings.
+			if resourceVariable.Key && resourceVariable.StandaloneExpression && output.Type() != types.StringType {
+				return krocel.NewExpressionError(resource.id, field, expression,
+					fmt.Errorf("output of key expression %s can only be of type string", expression))
 			}
 		}
 	}
@@ -770,7 +1520,7 @@ func ensureResourceExpressions(env *cel.Env, context map[string]*Resource, resou
 // against the resources defined in the resource graph definition.
 func ensureReadyWhenExpressions(resource *Resource) error {
 	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{resource.id}))
-	for _, expression := range resource.readyWhenExpressions {
+	for i, expression := range resource.readyWhenExpressions {
 		if err != nil {
 			return fmt.Errorf("failed to create CEL environment: %w", err)
 		}
@@ -784,14 +1534,17 @@ func ensureReadyWhenExpressions(resource *Resource) error {
 		context := map[string]*Resource{}
 		context[resource.id] = &Resource{
 			emulatedObject: resourceEmulatedCopy,
+			schema:         resource.schema,
 		}
 
+		field := fmt.Sprintf("readyWhen[%d]", i)
 		output, err := ensureExpression(env, expression, []string{resource.id}, context)
 		if err != nil {
-			return fmt.Errorf("failed to dry-run expression %s: %w", expression, err)
+			return krocel.NewExpressionError(resource.id, field, expression, err)
 		}
 		if !krocel.IsBoolType(output) {
-			return fmt.Errorf("output of readyWhen expression %s can only be of type bool", expression)
+			return krocel.NewExpressionError(resource.id, field, expression,
+				fmt.Errorf("output of readyWhen expression %s can only be of type bool", expression))
 		}
 	}
 	return nil
@@ -800,13 +1553,15 @@ func ensureReadyWhenExpressions(resource *Resource) error {
 // ensureIncludeWhenExpressions validates the includeWhen expressions in the resource
 func ensureIncludeWhenExpressions(env *cel.Env, context map[string]*Resource, resource *Resource) error {
 	// We need to validate the CEL expressions in the resource.
-	for _, expression := range resource.includeWhenExpressions {
+	for i, expression := range resource.includeWhenExpressions {
+		field := fmt.Sprintf("includeWhen[%d]", i)
 		output, err := ensureExpression(env, expression, []string{resource.id}, context)
 		if err != nil {
-			return fmt.Errorf("failed to dry-run expression %s: %w", expression, err)
+			return krocel.NewExpressionError(resource.id, field, expression, err)
 		}
 		if !krocel.IsBoolType(output) {
-			return fmt.Errorf("output of includeWhen expression %s can only be of type bool", expression)
+			return krocel.NewExpressionError(resource.id, field, expression,
+				fmt.Errorf("output of includeWhen expression %s can only be of type bool", expression))
 		}
 	}
 	return nil
@@ -15,13 +15,21 @@ package graph
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 
+	"github.com/kro-run/kro/api/v1alpha1"
 	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
 	"github.com/kro-run/kro/pkg/graph/variable"
+	kroruntime "github.com/kro-run/kro/pkg/runtime"
 	"github.com/kro-run/kro/pkg/testutil/generator"
 	"github.com/kro-run/kro/pkg/testutil/k8s"
 )
@@ -134,6 +142,48 @@ func TestGraphBuilder_Validation(t *testing.T) {
 			wantErr: true,
 			errMsg:  "failed to parse readyWhen expressions",
 		},
+		{
+			name: "expression exceeding the cost limit",
+			resourceGraphDefinitionOpts: []generator.ResourceGraphDefinitionOption{
+				generator.WithSchema(
+					"Test", "v1alpha1",
+					map[string]interface{}{
+						"name": "string",
+					},
+					nil,
+				),
+				generator.WithResource("vpc", map[string]interface{}{
+					"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+					"kind":       "VPC",
+					"metadata": map[string]interface{}{
+						"name": "${string(lists.range(1000).map(i, lists.range(1000).map(j, i * j)).size())}",
+					},
+				}, nil, nil),
+			},
+			wantErr: true,
+			errMsg:  "cost limit exceeded",
+		},
+		{
+			name: "invalid CEL syntax in a resource field",
+			resourceGraphDefinitionOpts: []generator.ResourceGraphDefinitionOption{
+				generator.WithSchema(
+					"Test", "v1alpha1",
+					map[string]interface{}{
+						"name": "string",
+					},
+					nil,
+				),
+				generator.WithResource("vpc", map[string]interface{}{
+					"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+					"kind":       "VPC",
+					"metadata": map[string]interface{}{
+						"name": "${schema.spec.name +}",
+					},
+				}, nil, nil),
+			},
+			wantErr: true,
+			errMsg:  "resource vpc, field template.metadata.name, line 1, column 19 of the expression",
+		},
 		{
 			name: "invalid CEL syntax in includeWhen expression",
 			resourceGraphDefinitionOpts: []generator.ResourceGraphDefinitionOption{
@@ -768,7 +818,11 @@ func TestGraphBuilder_DependencyValidation(t *testing.T) {
 				}, nil, nil),
 			},
 			wantErr: true,
-			errMsg:  "graph contains a cycle",
+			errMsg: "graph contains a cycle: pod1 -> pod4 -> pod3 -> pod2 -> pod1 " +
+				"(pod1 depends on pod4 through metadata.name: ${pod4.status.podIP}; " +
+				"pod4 depends on pod3 through metadata.name: ${pod3.status.podIP}; " +
+				"pod3 depends on pod2 through metadata.name: ${pod2.status.podIP}; " +
+				"pod2 depends on pod1 through metadata.name: ${pod1.status.podIP})",
 		},
 		{
 			name: "shared infrastructure dependencies",
@@ -1347,7 +1401,927 @@ func validateVariables(t *testing.T, actual []*variable.ResourceField, expected
 }
 
 func TestNewBuilder(t *testing.T) {
-	builder, err := NewBuilder(&rest.Config{})
+	builder, err := NewBuilder(&rest.Config{}, kroruntime.Cluster{})
 	assert.Nil(t, err)
 	assert.NotNil(t, builder)
 }
+
+func TestBuildInstanceSpecSchema_Validations(t *testing.T) {
+	tests := []struct {
+		name        string
+		validations []v1alpha1.Validation
+		want        extv1.ValidationRules
+		wantErr     bool
+	}{
+		{
+			name: "no validations",
+		},
+		{
+			name: "block level validations",
+			validations: []v1alpha1.Validation{
+				{Expression: "self.min <= self.max", Message: "min must be lower than max"},
+				{Expression: "self.min >= 0"},
+			},
+			want: extv1.ValidationRules{
+				{Rule: "self.min <= self.max", Message: "min must be lower than max"},
+				{Rule: "self.min >= 0"},
+			},
+		},
+		{
+			name: "empty expression",
+			validations: []v1alpha1.Validation{
+				{Expression: " ", Message: "oops"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := &v1alpha1.Schema{
+				Spec:        runtime.RawExtension{Raw: []byte(`{"min": "integer", "max": "integer"}`)},
+				Validations: tt.validations,
+			}
+			got, err := BuildInstanceSpecSchema(schema)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.XValidations)
+		})
+	}
+}
+
+func TestBuildInstanceSpecSchema_CustomTypes(t *testing.T) {
+	schema := &v1alpha1.Schema{
+		Spec: runtime.RawExtension{Raw: []byte(`{"source": "oneOf(GitSource, S3Source) | required=true"}`)},
+		Types: runtime.RawExtension{Raw: []byte(`{
+			"GitSource": {"url": "string | required=true", "revision": "string"},
+			"S3Source": {"bucket": "string | required=true"}
+		}`)},
+	}
+	got, err := BuildInstanceSpecSchema(schema)
+	require.NoError(t, err)
+
+	source := got.Properties["source"]
+	assert.Equal(t, []string{"source"}, got.Required)
+	assert.Contains(t, source.Properties, "gitSource")
+	assert.Contains(t, source.Properties, "s3Source")
+	assert.Equal(t, []extv1.JSONSchemaProps{
+		{Required: []string{"gitSource"}},
+		{Required: []string{"s3Source"}},
+	}, source.OneOf)
+	assert.Equal(t, []string{"url"}, source.Properties["gitSource"].Required)
+
+	schema.Types = runtime.RawExtension{}
+	_, err = BuildInstanceSpecSchema(schema)
+	assert.Error(t, err)
+}
+
+func TestBuildInstanceSpecSchema_CRDRefTypes(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	schema := &v1alpha1.Schema{
+		Spec: runtime.RawExtension{Raw: []byte(`{"tags": "crd(ec2.services.k8s.aws/v1alpha1, VPC, .spec.tags)"}`)},
+	}
+	got, _, _, err := buildInstanceSpecSchema(schema, nil, builder.resolveJSONSchema)
+	require.NoError(t, err)
+
+	tags := got.Properties["tags"]
+	assert.Equal(t, "array", tags.Type)
+	require.NotNil(t, tags.Items)
+	assert.Contains(t, tags.Items.Schema.Properties, "key")
+	assert.Contains(t, tags.Items.Schema.Properties, "value")
+
+	schema.Spec = runtime.RawExtension{Raw: []byte(`{"tags": "crd(ec2.services.k8s.aws/v1alpha1, Unknown, .spec.tags)"}`)}
+	_, _, _, err = buildInstanceSpecSchema(schema, nil, builder.resolveJSONSchema)
+	assert.Error(t, err)
+}
+
+func TestGraphBuilder_SensitiveFields(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	rgd := generator.NewResourceGraphDefinition("testrgd",
+		generator.WithSchema(
+			"Test", "v1alpha1",
+			map[string]interface{}{
+				"name":  "string",
+				"token": "string | sensitive=true",
+			},
+			map[string]interface{}{
+				"vpcID": "${vpc.status.vpcID}",
+			},
+		),
+		generator.WithResource("vpc", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "VPC",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}",
+			},
+			"spec": map[string]interface{}{
+				"cidrBlocks": []interface{}{"${schema.spec.token}"},
+			},
+		}, nil, nil),
+	)
+
+	g, err := builder.NewResourceGraphDefinition(rgd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spec.token"}, g.SensitiveFields)
+	// The lint warnings of the optional fields follow the sensitive fields
+	// warnings.
+	assert.Equal(t, []string{
+		"sensitive field spec.token is written to vpc.spec.cidrBlocks[0], which is not a Secret",
+		"vpc.metadata.name refers to optional field spec.name, which has no default",
+		"vpc.spec.cidrBlocks[0] refers to optional field spec.token, which has no default",
+	}, g.Warnings)
+}
+
+func TestGraphBuilder_PrinterColumns(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	rgd := generator.NewResourceGraphDefinition("testrgd",
+		generator.WithSchema(
+			"Test", "v1alpha1",
+			map[string]interface{}{
+				"name":     "string",
+				"replicas": "integer | printColumn=true",
+			},
+			nil,
+		),
+		generator.WithResource("vpc", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "VPC",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}",
+			},
+			"spec": map[string]interface{}{
+				"cidrBlocks": []interface{}{"192.168.0.0/16"},
+			},
+		}, nil, nil),
+	)
+
+	g, err := builder.NewResourceGraphDefinition(rgd)
+	require.NoError(t, err)
+
+	columns := g.Instance.GetCRD().Spec.Versions[0].AdditionalPrinterColumns
+	require.Len(t, columns, 4)
+	assert.Equal(t, extv1.CustomResourceColumnDefinition{
+		Name:     "Replicas",
+		Type:     "integer",
+		JSONPath: ".spec.replicas",
+	}, columns[2])
+	assert.Equal(t, "Age", columns[3].Name)
+}
+
+func TestGraphBuilder_ClusterScope(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(metadata map[string]interface{}) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema(
+				"Test", "v1alpha1",
+				map[string]interface{}{
+					"name":      "string",
+					"namespace": "string",
+				},
+				nil,
+			),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata":   metadata,
+				"spec": map[string]interface{}{
+					"cidrBlocks": []interface{}{"192.168.0.0/16"},
+				},
+			}, nil, nil),
+		)
+		rgd.Spec.Schema.Scope = v1alpha1.InstanceScopeCluster
+		return rgd
+	}
+
+	t.Run("namespaced resources set their namespace", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(map[string]interface{}{
+			"name":      "${schema.spec.name}",
+			"namespace": "${schema.spec.namespace}",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, extv1.ClusterScoped, g.Instance.GetCRD().Spec.Scope)
+		assert.False(t, g.Instance.IsNamespaced())
+	})
+
+	t.Run("default service accounts", func(t *testing.T) {
+		rgd := newRGD(map[string]interface{}{
+			"name":      "${schema.spec.name}",
+			"namespace": "${schema.spec.namespace}",
+		})
+		rgd.Spec.DefaultServiceAccounts = map[string]string{v1alpha1.DefaultServiceAccountKey: "kro"}
+		_, err := builder.NewResourceGraphDefinition(rgd)
+		assert.ErrorContains(t, err, "defaultServiceAccounts")
+	})
+}
+
+func TestGraphBuilder_InstanceCRDMetadata(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(mutate func(schema *v1alpha1.Schema)) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema(
+				"WebApp", "v1alpha1",
+				map[string]interface{}{"name": "string"},
+				nil,
+			),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+				"spec": map[string]interface{}{
+					"cidrBlocks": []interface{}{"192.168.0.0/16"},
+				},
+			}, nil, nil),
+		)
+		mutate(rgd.Spec.Schema)
+		return rgd
+	}
+
+	t.Run("metadata is set on the CRD", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(func(schema *v1alpha1.Schema) {
+			schema.ShortNames = []string{"wapp"}
+			schema.Categories = []string{"all", "kro"}
+			schema.Labels = map[string]string{"team": "platform"}
+			schema.Annotations = map[string]string{"example.com/owner": "platform"}
+		}))
+		require.NoError(t, err)
+
+		instanceCRD := g.Instance.GetCRD()
+		assert.Equal(t, []string{"wapp"}, instanceCRD.Spec.Names.ShortNames)
+		assert.Equal(t, []string{"all", "kro"}, instanceCRD.Spec.Names.Categories)
+		assert.Equal(t, map[string]string{"team": "platform"}, instanceCRD.Labels)
+		assert.Equal(t, map[string]string{"example.com/owner": "platform"}, instanceCRD.Annotations)
+	})
+
+	t.Run("invalid short name", func(t *testing.T) {
+		_, err := builder.NewResourceGraphDefinition(newRGD(func(schema *v1alpha1.Schema) {
+			schema.ShortNames = []string{"Web_App"}
+		}))
+		assert.ErrorContains(t, err, "invalid short name")
+	})
+
+	t.Run("reserved label", func(t *testing.T) {
+		_, err := builder.NewResourceGraphDefinition(newRGD(func(schema *v1alpha1.Schema) {
+			schema.Labels = map[string]string{"kro.run/owned": "false"}
+		}))
+		assert.ErrorContains(t, err, "reserved for kro")
+	})
+
+	t.Run("invalid label value", func(t *testing.T) {
+		_, err := builder.NewResourceGraphDefinition(newRGD(func(schema *v1alpha1.Schema) {
+			schema.Labels = map[string]string{"team": "platform team"}
+		}))
+		assert.Error(t, err)
+	})
+}
+
+func TestGraphBuilder_Conditions(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(conditions ...v1alpha1.StatusCondition) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema(
+				"Test", "v1alpha1",
+				map[string]interface{}{"name": "string"},
+				nil,
+			),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+				"spec": map[string]interface{}{
+					"cidrBlocks": []interface{}{"192.168.0.0/16"},
+				},
+			}, nil, nil),
+		)
+		rgd.Spec.Schema.Conditions = conditions
+		return rgd
+	}
+
+	t.Run("valid condition", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(v1alpha1.StatusCondition{
+			Type:       "NetworkReady",
+			Expression: "${vpc.status.state == 'available'}",
+			Message:    "the VPC is not available",
+		}))
+		require.NoError(t, err)
+		require.Len(t, g.Conditions, 1)
+		assert.Equal(t, "NetworkReady", g.Conditions[0].Type)
+		assert.Equal(t, "vpc.status.state == 'available'", g.Conditions[0].Expression)
+		assert.Equal(t, []string{"vpc"}, g.Conditions[0].Dependencies)
+	})
+
+	tests := []struct {
+		name       string
+		conditions []v1alpha1.StatusCondition
+		wantErr    string
+	}{
+		{
+			name:       "not a boolean",
+			conditions: []v1alpha1.StatusCondition{{Type: "NetworkReady", Expression: "${vpc.status.state}"}},
+			wantErr:    "can only be of type bool",
+		},
+		{
+			name:       "not a standalone expression",
+			conditions: []v1alpha1.StatusCondition{{Type: "NetworkReady", Expression: "ready-${vpc.status.state}"}},
+			wantErr:    "failed to parse expression",
+		},
+		{
+			name:       "unknown resource",
+			conditions: []v1alpha1.StatusCondition{{Type: "NetworkReady", Expression: "${subnet.status.state == 'available'}"}},
+			wantErr:    "NetworkReady",
+		},
+		{
+			name:       "reserved type",
+			conditions: []v1alpha1.StatusCondition{{Type: "InstanceSynced", Expression: "${true}"}},
+			wantErr:    "reserved",
+		},
+		{
+			name: "duplicate type",
+			conditions: []v1alpha1.StatusCondition{
+				{Type: "NetworkReady", Expression: "${true}"},
+				{Type: "NetworkReady", Expression: "${false}"},
+			},
+			wantErr: "duplicate condition type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := builder.NewResourceGraphDefinition(newRGD(tt.conditions...))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestGraphBuilder_ReadinessPolicy(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(timeout *metav1.Duration, onFailure v1alpha1.FailurePolicy) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema(
+				"Test", "v1alpha1",
+				map[string]interface{}{"name": "string"},
+				nil,
+			),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+			}, []string{"${vpc.status.state == 'available'}"}, nil),
+		)
+		rgd.Spec.Resources[0].ReadinessTimeout = timeout
+		rgd.Spec.Resources[0].OnFailure = onFailure
+		return rgd
+	}
+
+	tests := []struct {
+		name          string
+		timeout       *metav1.Duration
+		onFailure     v1alpha1.FailurePolicy
+		wantTimeout   time.Duration
+		wantOnFailure v1alpha1.FailurePolicy
+		wantErr       string
+	}{
+		{
+			name:          "no timeout",
+			wantOnFailure: v1alpha1.FailurePolicyRetry,
+		},
+		{
+			name:          "timeout with default policy",
+			timeout:       &metav1.Duration{Duration: 10 * time.Minute},
+			wantTimeout:   10 * time.Minute,
+			wantOnFailure: v1alpha1.FailurePolicyRetry,
+		},
+		{
+			name:          "timeout with continue policy",
+			timeout:       &metav1.Duration{Duration: time.Minute},
+			onFailure:     v1alpha1.FailurePolicyContinue,
+			wantTimeout:   time.Minute,
+			wantOnFailure: v1alpha1.FailurePolicyContinue,
+		},
+		{
+			name:      "policy without timeout",
+			onFailure: v1alpha1.FailurePolicyAbort,
+			wantErr:   "onFailure policy Abort requires a readinessTimeout",
+		},
+		{
+			name:    "negative timeout",
+			timeout: &metav1.Duration{Duration: -time.Minute},
+			wantErr: "readinessTimeout must be positive",
+		},
+		{
+			name:      "unknown policy",
+			timeout:   &metav1.Duration{Duration: time.Minute},
+			onFailure: "Ignore",
+			wantErr:   `unknown onFailure policy "Ignore"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := builder.NewResourceGraphDefinition(newRGD(tt.timeout, tt.onFailure))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTimeout, g.Resources["vpc"].GetReadinessTimeout())
+			assert.Equal(t, tt.wantOnFailure, g.Resources["vpc"].GetOnFailure())
+		})
+	}
+}
+
+func TestGraphBuilder_RetryPolicy(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(rgdPolicy, vpcPolicy *v1alpha1.RetryPolicy) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema(
+				"Test", "v1alpha1",
+				map[string]interface{}{"name": "string"},
+				nil,
+			),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+			}, nil, nil),
+			generator.WithResource("subnet", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "Subnet",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+			}, nil, nil),
+		)
+		rgd.Spec.Retry = rgdPolicy
+		rgd.Spec.Resources[0].Retry = vpcPolicy
+		return rgd
+	}
+	rgdPolicy := &v1alpha1.RetryPolicy{MaxAttempts: 10}
+	vpcPolicy := &v1alpha1.RetryPolicy{
+		InitialDelay: &metav1.Duration{Duration: time.Second},
+		MaxDelay:     &metav1.Duration{Duration: time.Minute},
+	}
+
+	tests := []struct {
+		name       string
+		rgdPolicy  *v1alpha1.RetryPolicy
+		vpcPolicy  *v1alpha1.RetryPolicy
+		wantVPC    *v1alpha1.RetryPolicy
+		wantSubnet *v1alpha1.RetryPolicy
+		wantErr    string
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name:       "resource graph definition policy",
+			rgdPolicy:  rgdPolicy,
+			wantVPC:    rgdPolicy,
+			wantSubnet: rgdPolicy,
+		},
+		{
+			name:       "resource policy",
+			rgdPolicy:  rgdPolicy,
+			vpcPolicy:  vpcPolicy,
+			wantVPC:    vpcPolicy,
+			wantSubnet: rgdPolicy,
+		},
+		{
+			name: "max delay lower than initial delay",
+			vpcPolicy: &v1alpha1.RetryPolicy{
+				InitialDelay: &metav1.Duration{Duration: time.Minute},
+				MaxDelay:     &metav1.Duration{Duration: time.Second},
+			},
+			wantErr: "maxDelay 1s can't be lower than initialDelay 1m0s",
+		},
+		{
+			name: "negative initial delay",
+			rgdPolicy: &v1alpha1.RetryPolicy{
+				InitialDelay: &metav1.Duration{Duration: -time.Second},
+			},
+			wantErr: "invalid retry policy: initialDelay must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := builder.NewResourceGraphDefinition(newRGD(tt.rgdPolicy, tt.vpcPolicy))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVPC, g.Resources["vpc"].GetRetryPolicy())
+			assert.Equal(t, tt.wantSubnet, g.Resources["subnet"].GetRetryPolicy())
+		})
+	}
+}
+
+func TestGraphBuilder_ResyncPeriod(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	tests := []struct {
+		name       string
+		reconcile  *v1alpha1.ReconcilePolicy
+		wantPeriod time.Duration
+		wantErr    string
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name:      "no resync period",
+			reconcile: &v1alpha1.ReconcilePolicy{},
+		},
+		{
+			name:       "resync period",
+			reconcile:  &v1alpha1.ReconcilePolicy{ResyncPeriod: &metav1.Duration{Duration: 30 * time.Second}},
+			wantPeriod: 30 * time.Second,
+		},
+		{
+			name:      "zero resync period",
+			reconcile: &v1alpha1.ReconcilePolicy{ResyncPeriod: &metav1.Duration{}},
+			wantErr:   "invalid reconcile policy: resyncPeriod must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rgd := generator.NewResourceGraphDefinition("testrgd",
+				generator.WithSchema("Test", "v1alpha1", map[string]interface{}{"name": "string"}, nil),
+			)
+			rgd.Spec.Reconcile = tt.reconcile
+			g, err := builder.NewResourceGraphDefinition(rgd)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPeriod, g.ResyncPeriod)
+		})
+	}
+}
+
+func TestGraphBuilder_ServiceAccount(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	tests := []struct {
+		name                   string
+		serviceAccount         *v1alpha1.ServiceAccountReference
+		defaultServiceAccounts map[string]string
+		want                   *k8stypes.NamespacedName
+		wantErr                string
+	}{
+		{
+			name: "no service account",
+		},
+		{
+			name:           "service account",
+			serviceAccount: &v1alpha1.ServiceAccountReference{Namespace: "tenant", Name: "deployer"},
+			want:           &k8stypes.NamespacedName{Namespace: "tenant", Name: "deployer"},
+		},
+		{
+			name:                   "service account and default service accounts",
+			serviceAccount:         &v1alpha1.ServiceAccountReference{Namespace: "tenant", Name: "deployer"},
+			defaultServiceAccounts: map[string]string{v1alpha1.DefaultServiceAccountKey: "kro"},
+			wantErr:                "serviceAccount and defaultServiceAccounts are mutually exclusive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rgd := generator.NewResourceGraphDefinition("testrgd",
+				generator.WithSchema("Test", "v1alpha1", map[string]interface{}{"name": "string"}, nil),
+			)
+			rgd.Spec.ServiceAccount = tt.serviceAccount
+			rgd.Spec.DefaultServiceAccounts = tt.defaultServiceAccounts
+			g, err := builder.NewResourceGraphDefinition(rgd)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, g.ServiceAccount)
+		})
+	}
+}
+
+func TestGraphBuilder_NamespaceSelector(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	tests := []struct {
+		name              string
+		namespaceSelector *metav1.LabelSelector
+		scope             v1alpha1.InstanceScope
+		want              string
+		wantErr           string
+	}{
+		{
+			name: "no namespace selector",
+		},
+		{
+			name:              "match labels",
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+			want:              "team=payments",
+		},
+		{
+			name: "match expressions",
+			namespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "env",
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{"prod", "staging"},
+			}}},
+			want: "env in (prod,staging)",
+		},
+		{
+			name: "invalid operator",
+			namespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "env",
+				Operator: "Like",
+			}}},
+			wantErr: "invalid namespaceSelector",
+		},
+		{
+			name:              "cluster-scoped instances",
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+			scope:             v1alpha1.InstanceScopeCluster,
+			wantErr:           "namespaceSelector is not supported for cluster-scoped instances",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rgd := generator.NewResourceGraphDefinition("testrgd",
+				generator.WithSchema("Test", "v1alpha1", map[string]interface{}{"name": "string"}, nil),
+			)
+			rgd.Spec.NamespaceSelector = tt.namespaceSelector
+			if tt.scope != "" {
+				rgd.Spec.Schema.Scope = tt.scope
+			}
+			g, err := builder.NewResourceGraphDefinition(rgd)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, g.NamespaceSelector)
+				return
+			}
+			assert.Equal(t, tt.want, g.NamespaceSelector.String())
+		})
+	}
+}
+
+func TestGraphBuilder_Target(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	spoke := &v1alpha1.Target{KubeconfigSecretRef: v1alpha1.KubeconfigSecretReference{Namespace: "clusters", Name: "spoke"}}
+	edge := &v1alpha1.Target{KubeconfigSecretRef: v1alpha1.KubeconfigSecretReference{Namespace: "clusters", Name: "edge"}}
+	newRGD := func(target *v1alpha1.Target) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema("Test", "v1alpha1", map[string]interface{}{"name": "string"}, nil),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata":   map[string]interface{}{"name": "${schema.spec.name}"},
+			}, nil, nil),
+			generator.WithResource("subnet", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "Subnet",
+				"metadata":   map[string]interface{}{"name": "${schema.spec.name}"},
+			}, nil, nil),
+		)
+		rgd.Spec.Target = target
+		rgd.Spec.Resources[0].Target = spoke
+		rgd.Spec.Resources[0].WaitFor = []*v1alpha1.WaitFor{{
+			ID: "credentials",
+			ExternalRef: v1alpha1.ExternalRef{
+				APIVersion: "v1",
+				Kind:       "Secret",
+				Metadata:   v1alpha1.ExternalRefMetadata{Name: "${schema.spec.name}"},
+			},
+		}}
+		rgd.Spec.Resources[0].Hooks = &v1alpha1.Hooks{PreApply: []*v1alpha1.Hook{{
+			ID: "migrate",
+			Job: &runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"${schema.spec.name}-migrate"},` +
+				`"spec":{"template":{"spec":{"restartPolicy":"Never","containers":[{"name":"migrate","image":"migrate"}]}}}}`)},
+		}}}
+		return rgd
+	}
+
+	t.Run("resources default to the cluster of kro", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(nil))
+		require.NoError(t, err)
+		assert.Equal(t, spoke, g.Resources["vpc"].GetTarget())
+		assert.Equal(t, spoke, g.Resources["credentials"].GetTarget())
+		assert.Equal(t, spoke, g.Resources["migrate"].GetTarget())
+		assert.Nil(t, g.Resources["subnet"].GetTarget())
+	})
+
+	t.Run("resources default to the target of the graph", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(edge))
+		require.NoError(t, err)
+		assert.Equal(t, spoke, g.Resources["vpc"].GetTarget())
+		assert.Equal(t, spoke, g.Resources["credentials"].GetTarget())
+		assert.Equal(t, spoke, g.Resources["migrate"].GetTarget())
+		assert.Equal(t, edge, g.Resources["subnet"].GetTarget())
+	})
+}
+
+func TestGraphBuilder_IgnoreFields(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(ignoreFields ...string) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema("Test", "v1alpha1", map[string]interface{}{"name": "string"}, nil),
+			generator.WithResource("secret", map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]interface{}{"name": "${schema.spec.name}"},
+				"data":       map[string]interface{}{"token": ""},
+			}, nil, nil),
+		)
+		rgd.Spec.Resources[0].IgnoreFields = ignoreFields
+		return rgd
+	}
+
+	g, err := builder.NewResourceGraphDefinition(newRGD("data.token", `metadata.annotations["example.com/id"]`))
+	require.NoError(t, err)
+	assert.Equal(t, [][]fieldpath.Segment{
+		{fieldpath.NewNamedSegment("data"), fieldpath.NewNamedSegment("token")},
+		{fieldpath.NewNamedSegment("metadata"), fieldpath.NewNamedSegment("annotations"), fieldpath.NewNamedSegment("example.com/id")},
+	}, g.Resources["secret"].GetIgnoreFields())
+
+	for field, wantErr := range map[string]string{
+		"data..token":   `invalid ignored field "data..token"`,
+		"[0]":           `invalid ignored field "[0]"`,
+		"metadata.name": `can't ignore field "metadata.name" identifying the resource`,
+		"kind":          `can't ignore field "kind" identifying the resource`,
+	} {
+		_, err := builder.NewResourceGraphDefinition(newRGD(field))
+		assert.ErrorContains(t, err, wantErr)
+	}
+}
+
+func TestGraphBuilder_ContextVariables(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+		cluster:          kroruntime.Cluster{Domain: "cluster.local", Region: "eu-west-1"},
+	}
+
+	secret := func(id string, data map[string]interface{}) generator.ResourceGraphDefinitionOption {
+		return generator.WithResource(id, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name": "${schema.metadata.name}-" + id,
+			},
+			"data": data,
+		}, nil, nil)
+	}
+	schemaOpt := generator.WithSchema("Test", "v1alpha1", map[string]interface{}{"name": "string"}, nil)
+
+	tests := []struct {
+		name        string
+		opts        []generator.ResourceGraphDefinitionOption
+		wantErr     string
+		wantCluster bool
+	}{
+		{
+			name: "instance metadata and cluster",
+			opts: []generator.ResourceGraphDefinitionOption{
+				schemaOpt,
+				secret("secret", map[string]interface{}{
+					"team":   "${schema.metadata.labels.team}",
+					"uid":    "${schema.metadata.uid}",
+					"region": "${cluster.region}",
+					"host":   "${schema.spec.name}.svc.${cluster.domain}",
+				}),
+			},
+			wantCluster: true,
+		},
+		{
+			name: "fields of the instance metadata that aren't exposed",
+			opts: []generator.ResourceGraphDefinitionOption{
+				schemaOpt,
+				secret("secret", map[string]interface{}{
+					"version": "${schema.metadata.resourceVersion}",
+				}),
+			},
+			wantErr: "no such key",
+		},
+		{
+			name: "resource shadowing the cluster variable",
+			opts: []generator.ResourceGraphDefinitionOption{
+				schemaOpt,
+				secret("cluster", nil),
+				secret("secret", map[string]interface{}{
+					"type": "${cluster.type}",
+				}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("test", tt.opts...))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if !tt.wantCluster {
+				assert.Nil(t, g.cluster)
+				assert.Equal(t, []string{"cluster"}, g.Resources["secret"].GetDependencies())
+				return
+			}
+			require.NotNil(t, g.cluster)
+			assert.Equal(t, "eu-west-1", g.cluster.Region)
+			assert.NotEmpty(t, g.cluster.KubernetesVersion)
+			assert.Empty(t, g.Resources["secret"].GetDependencies())
+			for _, v := range g.Resources["secret"].GetVariables() {
+				assert.Equal(t, variable.ResourceVariableKindStatic, v.Kind, v.Path)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// Cache caches the graphs built for the resource graph definitions, keyed by
+// their UID and generation, so that the graph of a generation is built once
+// and shared by every reconciliation of the resource graph definition and of
+// its instances, along with its program cache.
+//
+// A cache only holds the graph of the latest generation of each resource
+// graph definition. The graphs depending on something else than the resource
+// graph definition, such as the shared types, must be invalidated explicitly
+// when it changes. It is safe for concurrent use.
+type Cache struct {
+	mu     sync.Mutex
+	graphs map[types.UID]cachedGraph
+}
+
+type cachedGraph struct {
+	generation int64
+	graph      *Graph
+}
+
+// NewCache returns an empty graph cache.
+func NewCache() *Cache {
+	return &Cache{graphs: make(map[types.UID]cachedGraph)}
+}
+
+// Get returns the graph built for the current generation of the given
+// resource graph definition, if any.
+func (c *Cache) Get(rgd *v1alpha1.ResourceGraphDefinition) (*Graph, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.graphs[rgd.UID]
+	if !ok || rgd.UID == "" || cached.generation != rgd.Generation {
+		return nil, false
+	}
+	return cached.graph, true
+}
+
+// Set caches the graph built for the current generation of the given
+// resource graph definition, replacing the graph of its previous generation.
+func (c *Cache) Set(rgd *v1alpha1.ResourceGraphDefinition, graph *Graph) {
+	if rgd.UID == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.graphs[rgd.UID] = cachedGraph{generation: rgd.Generation, graph: graph}
+}
+
+// Invalidate drops the graph of the resource graph definition with the given
+// UID, e.g once it's deleted.
+func (c *Cache) Invalidate(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.graphs, uid)
+}
+
+// Reset drops every graph, e.g once the shared types they're built with
+// change.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.graphs)
+}
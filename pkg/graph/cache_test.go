@@ -0,0 +1,70 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+func TestCache(t *testing.T) {
+	rgd := func(uid types.UID, generation int64) *v1alpha1.ResourceGraphDefinition {
+		return &v1alpha1.ResourceGraphDefinition{ObjectMeta: metav1.ObjectMeta{UID: uid, Generation: generation}}
+	}
+	cache := NewCache()
+	first, second := &Graph{}, &Graph{}
+
+	_, ok := cache.Get(rgd("a", 1))
+	assert.False(t, ok)
+
+	cache.Set(rgd("a", 1), first)
+	cached, ok := cache.Get(rgd("a", 1))
+	assert.True(t, ok)
+	assert.Same(t, first, cached)
+
+	// Other generations and resource graph definitions miss.
+	_, ok = cache.Get(rgd("a", 2))
+	assert.False(t, ok)
+	_, ok = cache.Get(rgd("b", 1))
+	assert.False(t, ok)
+
+	cache.Set(rgd("a", 2), second)
+	_, ok = cache.Get(rgd("a", 1))
+	assert.False(t, ok)
+	cached, ok = cache.Get(rgd("a", 2))
+	assert.True(t, ok)
+	assert.Same(t, second, cached)
+
+	cache.Invalidate("a")
+	_, ok = cache.Get(rgd("a", 2))
+	assert.False(t, ok)
+
+	cache.Set(rgd("a", 2), second)
+	cache.Set(rgd("b", 1), first)
+	cache.Reset()
+	_, ok = cache.Get(rgd("a", 2))
+	assert.False(t, ok)
+	_, ok = cache.Get(rgd("b", 1))
+	assert.False(t, ok)
+
+	// Resource graph definitions without UID aren't cached.
+	cache.Set(rgd("", 1), first)
+	_, ok = cache.Get(rgd("", 1))
+	assert.False(t, ok)
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/parser"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// reservedConditionTypes are the condition types kro sets on every instance,
+// Ready, Reconciling and Stalled following the kstatus conventions.
+var reservedConditionTypes = []string{"InstanceSynced", "Ready", "Reconciling", "Stalled"}
+
+// buildInstanceConditions validates the conditions declared by the schema
+// and extracts the resources their expressions depend on. The expressions
+// are dry-run against the emulated instance and resources, and must evaluate
+// to a boolean.
+func buildInstanceConditions(
+	conditions []v1alpha1.StatusCondition,
+	resources map[string]*Resource,
+	instance *Resource,
+	variables []string,
+) ([]runtime.Condition, error) {
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	resourceIDs := append(maps.Keys(resources), variables...)
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(resourceIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	context := emulatedVariables(variables, instance)
+	for id, resource := range resources {
+		context[id] = resource
+	}
+
+	seen := map[string]struct{}{}
+	built := make([]runtime.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		for _, reserved := range reservedConditionTypes {
+			if condition.Type == reserved {
+				return nil, fmt.Errorf("condition type %s is reserved", condition.Type)
+			}
+		}
+		if _, ok := seen[condition.Type]; ok {
+			return nil, fmt.Errorf("duplicate condition type %s", condition.Type)
+		}
+		seen[condition.Type] = struct{}{}
+
+		expressions, err := parser.ParseConditionExpressions([]string{condition.Expression})
+		if err != nil {
+			return nil, fmt.Errorf("condition %s: failed to parse expression: %w", condition.Type, err)
+		}
+		expression := expressions[0]
+
+		dependencies, _, err := extractDependencies(env, expression, resourceIDs, variables)
+		if err != nil {
+			return nil, fmt.Errorf("condition %s: failed to extract dependencies: %w", condition.Type, err)
+		}
+		output, err := ensureExpression(env, expression, resourceIDs, context)
+		if err != nil {
+			return nil, fmt.Errorf("condition %s: %w", condition.Type, err)
+		}
+		if !krocel.IsBoolType(output) {
+			return nil, fmt.Errorf("condition %s: expression %s can only be of type bool", condition.Type, expression)
+		}
+
+		built = append(built, runtime.Condition{
+			Type:         condition.Type,
+			Expression:   expression,
+			Message:      condition.Message,
+			Dependencies: dependencies,
+		})
+	}
+	return built, nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package conversion converts instances between the versions of the instance
+// API of a ResourceGraphDefinition.
+package conversion
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	krocel "github.com/kro-run/kro/pkg/cel"
+)
+
+// Converter converts instances between the storage version of an instance
+// API and its additional versions. Instances are always converted through
+// the storage version, e.g converting from v1alpha1 to v1beta1 converts from
+// v1alpha1 to the storage version, then from the storage version to v1beta1.
+//
+// Only the spec is converted, the status is shared by all versions.
+type Converter struct {
+	storageVersion string
+	versions       map[string]*versionConverter
+}
+
+// versionConverter converts instances between a version and the storage
+// version.
+type versionConverter struct {
+	renames     []v1alpha1.FieldRename
+	toStorage   []fieldProgram
+	fromStorage []fieldProgram
+}
+
+// fieldProgram is a compiled FieldConversion.
+type fieldProgram struct {
+	path    []string
+	program cel.Program
+}
+
+// NewConverter creates a converter between the given storage version and
+// additional versions, compiling their conversion expressions.
+func NewConverter(storageVersion string, versions []v1alpha1.SchemaVersion) (*Converter, error) {
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{"self"}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	c := &Converter{
+		storageVersion: storageVersion,
+		versions:       make(map[string]*versionConverter, len(versions)),
+	}
+	for _, version := range versions {
+		if version.Name == storageVersion {
+			return nil, fmt.Errorf("version %s is already the storage version", version.Name)
+		}
+		if _, ok := c.versions[version.Name]; ok {
+			return nil, fmt.Errorf("duplicate version %s", version.Name)
+		}
+		vc := &versionConverter{}
+		if version.Conversion != nil {
+			for _, rename := range version.Conversion.Renames {
+				if err := validatePath(rename.From); err != nil {
+					return nil, fmt.Errorf("version %s: invalid rename: %w", version.Name, err)
+				}
+				if err := validatePath(rename.To); err != nil {
+					return nil, fmt.Errorf("version %s: invalid rename: %w", version.Name, err)
+				}
+			}
+			vc.renames = version.Conversion.Renames
+			if vc.toStorage, err = compileFields(env, version.Conversion.ToStorage); err != nil {
+				return nil, fmt.Errorf("version %s: invalid toStorage conversion: %w", version.Name, err)
+			}
+			if vc.fromStorage, err = compileFields(env, version.Conversion.FromStorage); err != nil {
+				return nil, fmt.Errorf("version %s: invalid fromStorage conversion: %w", version.Name, err)
+			}
+		}
+		c.versions[version.Name] = vc
+	}
+	return c, nil
+}
+
+// RequiresWebhook returns true if converting instances requires more than
+// changing their apiVersion, which the API server can't do on its own.
+func (c *Converter) RequiresWebhook() bool {
+	for _, vc := range c.versions {
+		if len(vc.renames) > 0 || len(vc.toStorage) > 0 || len(vc.fromStorage) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert converts the given instance to the given version, in place.
+func (c *Converter) Convert(obj *unstructured.Unstructured, version string) error {
+	gv, err := k8sschema.ParseGroupVersion(obj.GetAPIVersion())
+	if err != nil {
+		return fmt.Errorf("invalid apiVersion: %w", err)
+	}
+	if gv.Version == version {
+		return nil
+	}
+	if err := c.checkVersion(gv.Version); err != nil {
+		return err
+	}
+	if err := c.checkVersion(version); err != nil {
+		return err
+	}
+
+	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return fmt.Errorf("invalid spec: %w", err)
+	}
+	if spec == nil {
+		spec = map[string]interface{}{}
+	}
+	if gv.Version != c.storageVersion {
+		if spec, err = c.versions[gv.Version].convertToStorage(spec); err != nil {
+			return fmt.Errorf("failed to convert from %s to %s: %w", gv.Version, c.storageVersion, err)
+		}
+	}
+	if version != c.storageVersion {
+		if spec, err = c.versions[version].convertFromStorage(spec); err != nil {
+			return fmt.Errorf("failed to convert from %s to %s: %w", c.storageVersion, version, err)
+		}
+	}
+
+	if err := unstructured.SetNestedMap(obj.Object, spec, "spec"); err != nil {
+		return err
+	}
+	obj.SetAPIVersion(k8sschema.GroupVersion{Group: gv.Group, Version: version}.String())
+	return nil
+}
+
+func (c *Converter) checkVersion(version string) error {
+	if version == c.storageVersion {
+		return nil
+	}
+	if _, ok := c.versions[version]; !ok {
+		return fmt.Errorf("unknown version %s", version)
+	}
+	return nil
+}
+
+// convertToStorage converts the spec of an instance of the version to the
+// storage version.
+func (vc *versionConverter) convertToStorage(spec map[string]interface{}) (map[string]interface{}, error) {
+	converted := k8sruntime.DeepCopyJSON(spec)
+	for _, rename := range vc.renames {
+		moveField(converted, rename.From, rename.To)
+	}
+	if err := evaluateFields(converted, spec, vc.toStorage); err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+// convertFromStorage converts the spec of an instance of the storage version
+// to the version.
+func (vc *versionConverter) convertFromStorage(spec map[string]interface{}) (map[string]interface{}, error) {
+	converted := k8sruntime.DeepCopyJSON(spec)
+	for _, rename := range vc.renames {
+		moveField(converted, rename.To, rename.From)
+	}
+	if err := evaluateFields(converted, spec, vc.fromStorage); err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+// moveField moves the value at the given path to another path, if set.
+func moveField(obj map[string]interface{}, from, to string) {
+	value, ok, err := unstructured.NestedFieldCopy(obj, strings.Split(from, ".")...)
+	if err != nil || !ok {
+		return
+	}
+	unstructured.RemoveNestedField(obj, strings.Split(from, ".")...)
+	_ = unstructured.SetNestedField(obj, value, strings.Split(to, ".")...)
+}
+
+// evaluateFields sets the fields of the converted spec by evaluating their
+// programs against the original spec.
+func evaluateFields(converted, original map[string]interface{}, fields []fieldProgram) error {
+	for _, field := range fields {
+		out, _, err := krocel.Evaluate(field.program, map[string]interface{}{"self": original})
+		if err != nil {
+			return fmt.Errorf("failed to evaluate %s: %w", strings.Join(field.path, "."), err)
+		}
+		// Values are converted through protobuf values, which only hold JSON
+		// types, so that they can be set in the unstructured spec.
+		native, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+		if err != nil {
+			return fmt.Errorf("failed to convert value of %s: %w", strings.Join(field.path, "."), err)
+		}
+		value := native.(*structpb.Value).AsInterface()
+		if value == nil {
+			unstructured.RemoveNestedField(converted, field.path...)
+			continue
+		}
+		if err := unstructured.SetNestedField(converted, value, field.path...); err != nil {
+			return fmt.Errorf("failed to set %s: %w", strings.Join(field.path, "."), err)
+		}
+	}
+	return nil
+}
+
+func compileFields(env *cel.Env, fields []v1alpha1.FieldConversion) ([]fieldProgram, error) {
+	programs := make([]fieldProgram, 0, len(fields))
+	for _, field := range fields {
+		if err := validatePath(field.Field); err != nil {
+			return nil, err
+		}
+		ast, issues := env.Compile(field.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("failed to compile expression of %s: %w", field.Field, issues.Err())
+		}
+		program, err := krocel.NewProgram(env, ast, krocel.PerExpressionCostLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create program for %s: %w", field.Field, err)
+		}
+		programs = append(programs, fieldProgram{path: strings.Split(field.Field, "."), program: program})
+	}
+	return programs, nil
+}
+
+// validatePath validates a path relative to the spec, e.g `network.cidr`.
+func validatePath(path string) error {
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			return fmt.Errorf("invalid field path %q", path)
+		}
+	}
+	return nil
+}
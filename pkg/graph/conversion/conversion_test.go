@@ -0,0 +1,159 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package conversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+func newInstance(apiVersion string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       "WebApp",
+		"metadata":   map[string]interface{}{"name": "app"},
+		"spec":       spec,
+	}}
+}
+
+func TestConverter(t *testing.T) {
+	converter, err := NewConverter("v1", []v1alpha1.SchemaVersion{
+		{
+			Name: "v1alpha1",
+			Conversion: &v1alpha1.VersionConversion{
+				Renames: []v1alpha1.FieldRename{{From: "image", To: "container.image"}},
+				ToStorage: []v1alpha1.FieldConversion{
+					{Field: "replicas", Expression: "self.size == 'large' ? 5 : 1"},
+					{Field: "size", Expression: "null"},
+				},
+				FromStorage: []v1alpha1.FieldConversion{
+					{Field: "size", Expression: "self.replicas > 1 ? 'large' : 'small'"},
+					{Field: "replicas", Expression: "null"},
+				},
+			},
+		},
+		{Name: "v1beta1"},
+	})
+	require.NoError(t, err)
+	assert.True(t, converter.RequiresWebhook())
+
+	t.Run("to storage version", func(t *testing.T) {
+		obj := newInstance("kro.run/v1alpha1", map[string]interface{}{
+			"image": "nginx",
+			"size":  "large",
+		})
+		require.NoError(t, converter.Convert(obj, "v1"))
+		assert.Equal(t, "kro.run/v1", obj.GetAPIVersion())
+		assert.Equal(t, map[string]interface{}{
+			"container": map[string]interface{}{"image": "nginx"},
+			"replicas":  float64(5),
+		}, obj.Object["spec"])
+	})
+
+	t.Run("from storage version", func(t *testing.T) {
+		obj := newInstance("kro.run/v1", map[string]interface{}{
+			"container": map[string]interface{}{"image": "nginx"},
+			"replicas":  int64(1),
+		})
+		require.NoError(t, converter.Convert(obj, "v1alpha1"))
+		assert.Equal(t, "kro.run/v1alpha1", obj.GetAPIVersion())
+		assert.Equal(t, map[string]interface{}{
+			"container": map[string]interface{}{},
+			"image":     "nginx",
+			"size":      "small",
+		}, obj.Object["spec"])
+	})
+
+	t.Run("between additional versions", func(t *testing.T) {
+		obj := newInstance("kro.run/v1beta1", map[string]interface{}{
+			"container": map[string]interface{}{"image": "nginx"},
+			"replicas":  int64(3),
+		})
+		require.NoError(t, converter.Convert(obj, "v1alpha1"))
+		assert.Equal(t, "nginx", obj.Object["spec"].(map[string]interface{})["image"])
+		assert.Equal(t, "large", obj.Object["spec"].(map[string]interface{})["size"])
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		obj := newInstance("kro.run/v1", map[string]interface{}{})
+		assert.Error(t, converter.Convert(obj, "v2"))
+	})
+}
+
+func TestNewConverter(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []v1alpha1.SchemaVersion
+		webhook  bool
+		wantErr  bool
+	}{
+		{
+			name:     "versions without conversions",
+			versions: []v1alpha1.SchemaVersion{{Name: "v1alpha1"}},
+		},
+		{
+			name: "renames only",
+			versions: []v1alpha1.SchemaVersion{{
+				Name:       "v1alpha1",
+				Conversion: &v1alpha1.VersionConversion{Renames: []v1alpha1.FieldRename{{From: "a", To: "b"}}},
+			}},
+			webhook: true,
+		},
+		{
+			name:     "storage version",
+			versions: []v1alpha1.SchemaVersion{{Name: "v1"}},
+			wantErr:  true,
+		},
+		{
+			name:     "duplicate version",
+			versions: []v1alpha1.SchemaVersion{{Name: "v1alpha1"}, {Name: "v1alpha1"}},
+			wantErr:  true,
+		},
+		{
+			name: "invalid path",
+			versions: []v1alpha1.SchemaVersion{{
+				Name:       "v1alpha1",
+				Conversion: &v1alpha1.VersionConversion{Renames: []v1alpha1.FieldRename{{From: "a..b", To: "c"}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "invalid expression",
+			versions: []v1alpha1.SchemaVersion{{
+				Name: "v1alpha1",
+				Conversion: &v1alpha1.VersionConversion{
+					ToStorage: []v1alpha1.FieldConversion{{Field: "a", Expression: "self.a +"}},
+				},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter("v1", tt.versions)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.webhook, converter.RequiresWebhook())
+		})
+	}
+}
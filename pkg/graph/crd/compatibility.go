@@ -0,0 +1,369 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package crd
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// ChangeType is the type of a change between two versions of a schema.
+type ChangeType string
+
+const (
+	// ChangeTypeFieldAdded is used when a field is added.
+	ChangeTypeFieldAdded ChangeType = "FieldAdded"
+	// ChangeTypeFieldRemoved is used when a field is removed.
+	ChangeTypeFieldRemoved ChangeType = "FieldRemoved"
+	// ChangeTypeTypeChanged is used when the type of a field changes.
+	ChangeTypeTypeChanged ChangeType = "TypeChanged"
+	// ChangeTypeRequiredAdded is used when a field becomes required.
+	ChangeTypeRequiredAdded ChangeType = "RequiredAdded"
+	// ChangeTypeValidationTightened is used when a validation accepts less
+	// values than before, e.g a lower maximum or a new pattern.
+	ChangeTypeValidationTightened ChangeType = "ValidationTightened"
+	// ChangeTypeValidationLoosened is used when a validation accepts more
+	// values than before, e.g a higher maximum or a removed pattern.
+	ChangeTypeValidationLoosened ChangeType = "ValidationLoosened"
+	// ChangeTypeVersionRemoved is used when a served version is removed.
+	ChangeTypeVersionRemoved ChangeType = "VersionRemoved"
+)
+
+// Change describes a change between two versions of a schema.
+type Change struct {
+	// Path is the path of the changed field, e.g `spec.ports[*].port`.
+	Path string
+	// Type is the type of the change.
+	Type ChangeType
+	// Breaking is true if existing objects may no longer be valid, or lose
+	// data, after the change.
+	Breaking bool
+	// Message is a human readable description of the change.
+	Message string
+}
+
+// String returns a human readable representation of the change.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Path, c.Message)
+}
+
+// CompatibilityReport lists the changes between two versions of a schema.
+type CompatibilityReport struct {
+	Changes []Change
+}
+
+// IsBreaking returns true if the report contains at least one breaking change.
+func (r *CompatibilityReport) IsBreaking() bool {
+	return len(r.BreakingChanges()) > 0
+}
+
+// BreakingChanges returns the breaking changes of the report.
+func (r *CompatibilityReport) BreakingChanges() []Change {
+	var changes []Change
+	for _, change := range r.Changes {
+		if change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// String returns a human readable summary of the breaking changes.
+func (r *CompatibilityReport) String() string {
+	changes := r.BreakingChanges()
+	messages := make([]string, 0, len(changes))
+	for _, change := range changes {
+		messages = append(messages, change.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// CompareCRDs compares the schemas of the versions served by the old and the
+// new CustomResourceDefinitions.
+//
+// The status of versions with a status subresource is written by controllers,
+// not users, so status changes are not reported.
+func CompareCRDs(oldCRD, newCRD *extv1.CustomResourceDefinition) *CompatibilityReport {
+	report := &CompatibilityReport{}
+	for _, oldVersion := range oldCRD.Spec.Versions {
+		if !oldVersion.Served {
+			continue
+		}
+		newVersion := findVersion(newCRD, oldVersion.Name)
+		if newVersion == nil || !newVersion.Served {
+			report.add(oldVersion.Name, ChangeTypeVersionRemoved, true, "version is no longer served")
+			continue
+		}
+		oldSchema, newSchema := versionSchema(&oldVersion), versionSchema(newVersion)
+		if newVersion.Subresources != nil && newVersion.Subresources.Status != nil {
+			oldSchema, newSchema = withoutStatus(oldSchema), withoutStatus(newSchema)
+		}
+		report.compare(oldVersion.Name, oldSchema, newSchema)
+	}
+	return report
+}
+
+// CompareSchemas compares the old and new versions of a schema.
+func CompareSchemas(oldSchema, newSchema *extv1.JSONSchemaProps) *CompatibilityReport {
+	report := &CompatibilityReport{}
+	report.compare("", oldSchema, newSchema)
+	return report
+}
+
+func findVersion(crd *extv1.CustomResourceDefinition, name string) *extv1.CustomResourceDefinitionVersion {
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == name {
+			return &crd.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+func versionSchema(version *extv1.CustomResourceDefinitionVersion) *extv1.JSONSchemaProps {
+	if version.Schema == nil {
+		return nil
+	}
+	return version.Schema.OpenAPIV3Schema
+}
+
+// withoutStatus returns a copy of the given object schema without the status
+// property.
+func withoutStatus(schema *extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	if schema == nil {
+		return nil
+	}
+	schema = schema.DeepCopy()
+	delete(schema.Properties, "status")
+	return schema
+}
+
+func (r *CompatibilityReport) add(path string, changeType ChangeType, breaking bool, format string, args ...interface{}) {
+	r.Changes = append(r.Changes, Change{
+		Path:     path,
+		Type:     changeType,
+		Breaking: breaking,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// compare compares the old and new schemas of the field at the given path,
+// and their children.
+func (r *CompatibilityReport) compare(path string, oldSchema, newSchema *extv1.JSONSchemaProps) {
+	if oldSchema == nil || newSchema == nil {
+		return
+	}
+
+	if oldType, newType := schemaType(oldSchema), schemaType(newSchema); oldType != newType {
+		r.add(path, ChangeTypeTypeChanged, true, "type changed from %s to %s", oldType, newType)
+		return
+	}
+
+	r.compareValidations(path, oldSchema, newSchema)
+
+	// Fields preserving unknown fields accept anything, so their properties
+	// can't be removed or tightened in a breaking way.
+	oldPreserves := oldSchema.XPreserveUnknownFields != nil && *oldSchema.XPreserveUnknownFields
+	for _, name := range sortedKeys(oldSchema.Properties) {
+		oldProperty := oldSchema.Properties[name]
+		newProperty, ok := newSchema.Properties[name]
+		if !ok {
+			r.add(joinPath(path, name), ChangeTypeFieldRemoved, !oldPreserves, "field removed")
+			continue
+		}
+		r.compare(joinPath(path, name), &oldProperty, &newProperty)
+	}
+	for _, name := range sortedKeys(newSchema.Properties) {
+		if _, ok := oldSchema.Properties[name]; !ok {
+			r.add(joinPath(path, name), ChangeTypeFieldAdded, false, "field added")
+		}
+	}
+	for _, name := range newSchema.Required {
+		if slices.Contains(oldSchema.Required, name) {
+			continue
+		}
+		// New fields with a default are set on existing objects when they are
+		// read, so requiring them is safe.
+		property, ok := newSchema.Properties[name]
+		if ok && property.Default != nil {
+			continue
+		}
+		r.add(joinPath(path, name), ChangeTypeRequiredAdded, true, "field is now required")
+	}
+
+	if oldSchema.Items != nil && newSchema.Items != nil {
+		r.compare(path+"[*]", oldSchema.Items.Schema, newSchema.Items.Schema)
+	}
+	if oldSchema.AdditionalProperties != nil && newSchema.AdditionalProperties != nil {
+		r.compare(joinPath(path, "*"), oldSchema.AdditionalProperties.Schema, newSchema.AdditionalProperties.Schema)
+	}
+}
+
+// compareValidations compares the value validations of the old and new schemas.
+func (r *CompatibilityReport) compareValidations(path string, oldSchema, newSchema *extv1.JSONSchemaProps) {
+	compareLowerBound := func(name string, oldValue, newValue *float64) {
+		switch {
+		case oldValue == nil && newValue == nil:
+		case oldValue != nil && newValue == nil:
+			r.add(path, ChangeTypeValidationLoosened, false, "%s removed", name)
+		case oldValue == nil || *newValue > *oldValue:
+			r.add(path, ChangeTypeValidationTightened, true, "%s raised to %v", name, *newValue)
+		case *newValue < *oldValue:
+			r.add(path, ChangeTypeValidationLoosened, false, "%s lowered to %v", name, *newValue)
+		}
+	}
+	compareUpperBound := func(name string, oldValue, newValue *float64) {
+		switch {
+		case oldValue == nil && newValue == nil:
+		case oldValue != nil && newValue == nil:
+			r.add(path, ChangeTypeValidationLoosened, false, "%s removed", name)
+		case oldValue == nil || *newValue < *oldValue:
+			r.add(path, ChangeTypeValidationTightened, true, "%s lowered to %v", name, *newValue)
+		case *newValue > *oldValue:
+			r.add(path, ChangeTypeValidationLoosened, false, "%s raised to %v", name, *newValue)
+		}
+	}
+
+	compareLowerBound("minimum", oldSchema.Minimum, newSchema.Minimum)
+	compareUpperBound("maximum", oldSchema.Maximum, newSchema.Maximum)
+	compareLowerBound("minLength", int64ToFloat(oldSchema.MinLength), int64ToFloat(newSchema.MinLength))
+	compareUpperBound("maxLength", int64ToFloat(oldSchema.MaxLength), int64ToFloat(newSchema.MaxLength))
+	compareLowerBound("minItems", int64ToFloat(oldSchema.MinItems), int64ToFloat(newSchema.MinItems))
+	compareUpperBound("maxItems", int64ToFloat(oldSchema.MaxItems), int64ToFloat(newSchema.MaxItems))
+	compareLowerBound("minProperties", int64ToFloat(oldSchema.MinProperties), int64ToFloat(newSchema.MinProperties))
+	compareUpperBound("maxProperties", int64ToFloat(oldSchema.MaxProperties), int64ToFloat(newSchema.MaxProperties))
+
+	if oldSchema.Pattern != newSchema.Pattern {
+		if newSchema.Pattern == "" {
+			r.add(path, ChangeTypeValidationLoosened, false, "pattern removed")
+		} else {
+			r.add(path, ChangeTypeValidationTightened, true, "pattern changed to %q", newSchema.Pattern)
+		}
+	}
+	if oldSchema.Format != newSchema.Format {
+		if newSchema.Format == "" {
+			r.add(path, ChangeTypeValidationLoosened, false, "format removed")
+		} else {
+			r.add(path, ChangeTypeValidationTightened, true, "format changed to %s", newSchema.Format)
+		}
+	}
+	if oldSchema.Nullable && !newSchema.Nullable {
+		r.add(path, ChangeTypeValidationTightened, true, "field is no longer nullable")
+	}
+	if listType(oldSchema) != "set" && listType(newSchema) == "set" {
+		r.add(path, ChangeTypeValidationTightened, true, "items must now be unique")
+	}
+
+	if len(newSchema.Enum) > 0 {
+		var removed []string
+		for _, value := range oldSchema.Enum {
+			if !enumContains(newSchema.Enum, value) {
+				removed = append(removed, string(value.Raw))
+			}
+		}
+		switch {
+		case len(oldSchema.Enum) == 0:
+			r.add(path, ChangeTypeValidationTightened, true, "enum added")
+		case len(removed) > 0:
+			r.add(path, ChangeTypeValidationTightened, true, "enum values removed: %s", strings.Join(removed, ", "))
+		}
+	} else if len(oldSchema.Enum) > 0 {
+		r.add(path, ChangeTypeValidationLoosened, false, "enum removed")
+	}
+
+	for _, rule := range newSchema.XValidations {
+		if !slices.ContainsFunc(oldSchema.XValidations, func(oldRule extv1.ValidationRule) bool {
+			return oldRule.Rule == rule.Rule
+		}) {
+			r.add(path, ChangeTypeValidationTightened, true, "validation rule added: %s", rule.Rule)
+		}
+	}
+	for _, rule := range oldSchema.XValidations {
+		if !slices.ContainsFunc(newSchema.XValidations, func(newRule extv1.ValidationRule) bool {
+			return newRule.Rule == rule.Rule
+		}) {
+			r.add(path, ChangeTypeValidationLoosened, false, "validation rule removed: %s", rule.Rule)
+		}
+	}
+}
+
+// schemaType returns a description of the type of the given schema, taking
+// the Kubernetes extensions changing the accepted values into account.
+func schemaType(schema *extv1.JSONSchemaProps) string {
+	switch {
+	case schema.XIntOrString:
+		return "int-or-string"
+	case schema.XEmbeddedResource:
+		return "embedded-resource"
+	case schema.Type == "object" && schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+		return "map"
+	case schema.Type == "object" && schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields &&
+		len(schema.Properties) == 0:
+		return "free-form object"
+	default:
+		return schema.Type
+	}
+}
+
+func listType(schema *extv1.JSONSchemaProps) string {
+	if schema.XListType == nil {
+		return ""
+	}
+	return *schema.XListType
+}
+
+// enumContains returns true if the given value is one of the enum values. Values
+// are compared after JSON decoding.
+func enumContains(enum []extv1.JSON, value extv1.JSON) bool {
+	var want interface{}
+	if err := json.Unmarshal(value.Raw, &want); err != nil {
+		return false
+	}
+	return slices.ContainsFunc(enum, func(e extv1.JSON) bool {
+		var got interface{}
+		if err := json.Unmarshal(e.Raw, &got); err != nil {
+			return false
+		}
+		return reflect.DeepEqual(got, want)
+	})
+}
+
+func int64ToFloat(v *int64) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func sortedKeys(m map[string]extv1.JSONSchemaProps) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package crd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	int64Ptr := func(v int64) *int64 { return &v }
+	float64Ptr := func(v float64) *float64 { return &v }
+	object := func(properties map[string]extv1.JSONSchemaProps, required ...string) *extv1.JSONSchemaProps {
+		return &extv1.JSONSchemaProps{Type: "object", Properties: properties, Required: required}
+	}
+
+	tests := []struct {
+		name          string
+		oldSchema     *extv1.JSONSchemaProps
+		newSchema     *extv1.JSONSchemaProps
+		expected      []Change
+		expectedBreak bool
+	}{
+		{
+			name:      "identical schemas",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{"name": {Type: "string"}}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{"name": {Type: "string"}}),
+		},
+		{
+			name:      "optional field added",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{"name": {Type: "string"}}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{
+				"name":     {Type: "string"},
+				"replicas": {Type: "integer"},
+			}),
+			expected: []Change{
+				{Path: "replicas", Type: ChangeTypeFieldAdded, Message: "field added"},
+			},
+		},
+		{
+			name:      "required field added with a default",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{
+				"replicas": {Type: "integer", Default: &extv1.JSON{Raw: []byte("1")}},
+			}, "replicas"),
+			expected: []Change{
+				{Path: "replicas", Type: ChangeTypeFieldAdded, Message: "field added"},
+			},
+		},
+		{
+			name:      "required field added without a default",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{"name": {Type: "string"}}, "name"),
+			expected: []Change{
+				{Path: "name", Type: ChangeTypeFieldAdded, Message: "field added"},
+				{Path: "name", Type: ChangeTypeRequiredAdded, Breaking: true, Message: "field is now required"},
+			},
+			expectedBreak: true,
+		},
+		{
+			name: "nested field removed",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{
+				"ingress": *object(map[string]extv1.JSONSchemaProps{"host": {Type: "string"}}),
+			}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{
+				"ingress": *object(map[string]extv1.JSONSchemaProps{}),
+			}),
+			expected: []Change{
+				{Path: "ingress.host", Type: ChangeTypeFieldRemoved, Breaking: true, Message: "field removed"},
+			},
+			expectedBreak: true,
+		},
+		{
+			name: "array item type changed",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{
+				"ports": {Type: "array", Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "integer"}}},
+			}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{
+				"ports": {Type: "array", Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "string"}}},
+			}),
+			expected: []Change{
+				{Path: "ports[*]", Type: ChangeTypeTypeChanged, Breaking: true, Message: "type changed from integer to string"},
+			},
+			expectedBreak: true,
+		},
+		{
+			name: "validations tightened",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{
+				"replicas": {Type: "integer", Maximum: float64Ptr(10)},
+				"name":     {Type: "string"},
+				"mode": {Type: "string", Enum: []extv1.JSON{
+					{Raw: []byte(`"fast"`)}, {Raw: []byte(`"slow"`)},
+				}},
+			}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{
+				"replicas": {Type: "integer", Minimum: float64Ptr(1), Maximum: float64Ptr(5)},
+				"name": {Type: "string", MaxLength: int64Ptr(63), XValidations: extv1.ValidationRules{
+					{Rule: "self == oldSelf", Message: "field is immutable"},
+				}},
+				"mode": {Type: "string", Enum: []extv1.JSON{{Raw: []byte(`"fast"`)}}},
+			}),
+			expected: []Change{
+				{Path: "mode", Type: ChangeTypeValidationTightened, Breaking: true, Message: `enum values removed: "slow"`},
+				{Path: "name", Type: ChangeTypeValidationTightened, Breaking: true, Message: "maxLength lowered to 63"},
+				{Path: "name", Type: ChangeTypeValidationTightened, Breaking: true, Message: "validation rule added: self == oldSelf"},
+				{Path: "replicas", Type: ChangeTypeValidationTightened, Breaking: true, Message: "minimum raised to 1"},
+				{Path: "replicas", Type: ChangeTypeValidationTightened, Breaking: true, Message: "maximum lowered to 5"},
+			},
+			expectedBreak: true,
+		},
+		{
+			name: "validations loosened",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{
+				"replicas": {Type: "integer", Maximum: float64Ptr(5)},
+				"name":     {Type: "string", Pattern: "^[a-z]+$"},
+			}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{
+				"replicas": {Type: "integer", Maximum: float64Ptr(10)},
+				"name":     {Type: "string"},
+			}),
+			expected: []Change{
+				{Path: "name", Type: ChangeTypeValidationLoosened, Message: "pattern removed"},
+				{Path: "replicas", Type: ChangeTypeValidationLoosened, Message: "maximum raised to 10"},
+			},
+		},
+		{
+			name: "map to object",
+			oldSchema: object(map[string]extv1.JSONSchemaProps{
+				"labels": {Type: "object", AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+					Schema: &extv1.JSONSchemaProps{Type: "string"},
+				}},
+			}),
+			newSchema: object(map[string]extv1.JSONSchemaProps{
+				"labels": *object(map[string]extv1.JSONSchemaProps{"app": {Type: "string"}}),
+			}),
+			expected: []Change{
+				{Path: "labels", Type: ChangeTypeTypeChanged, Breaking: true, Message: "type changed from map to object"},
+			},
+			expectedBreak: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CompareSchemas(tt.oldSchema, tt.newSchema)
+			assert.Equal(t, tt.expected, report.Changes)
+			assert.Equal(t, tt.expectedBreak, report.IsBreaking())
+		})
+	}
+}
+
+func TestCompareCRDs(t *testing.T) {
+	oldCRD := SynthesizeCRD("kro.run", "v1alpha1", "WebApp", extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"name":  {Type: "string"},
+			"image": {Type: "string"},
+		},
+	}, extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"endpoint": {Type: "string"},
+		},
+	}, true)
+
+	t.Run("status changes are ignored", func(t *testing.T) {
+		newCRD := oldCRD.DeepCopy()
+		delete(newCRD.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["status"].Properties, "endpoint")
+
+		report := CompareCRDs(oldCRD, newCRD)
+		assert.Empty(t, report.Changes)
+	})
+
+	t.Run("spec field removed", func(t *testing.T) {
+		newCRD := oldCRD.DeepCopy()
+		delete(newCRD.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties, "image")
+
+		report := CompareCRDs(oldCRD, newCRD)
+		assert.True(t, report.IsBreaking())
+		assert.Equal(t, "v1alpha1.spec.image: field removed", report.String())
+	})
+
+	t.Run("version removed", func(t *testing.T) {
+		newCRD := oldCRD.DeepCopy()
+		newCRD.Spec.Versions[0].Name = "v1"
+
+		report := CompareCRDs(oldCRD, newCRD)
+		assert.True(t, report.IsBreaking())
+		assert.Equal(t, "v1alpha1: version is no longer served", report.String())
+	})
+}
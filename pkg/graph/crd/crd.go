@@ -49,25 +49,49 @@ func newCRD(group, apiVersion, kind string, schema *extv1.JSONSchemaProps) *extv
 				Plural:   pluralKind,
 				Singular: strings.ToLower(kind),
 			},
-			Scope: extv1.NamespaceScoped,
-			Versions: []extv1.CustomResourceDefinitionVersion{
-				{
-					Name:    apiVersion,
-					Served:  true,
-					Storage: true,
-					Schema: &extv1.CustomResourceValidation{
-						OpenAPIV3Schema: schema,
-					},
-					Subresources: &extv1.CustomResourceSubresources{
-						Status: &extv1.CustomResourceSubresourceStatus{},
-					},
-					AdditionalPrinterColumns: defaultAdditionalPrinterColumns,
-				},
-			},
+			Scope:    extv1.NamespaceScoped,
+			Versions: []extv1.CustomResourceDefinitionVersion{newCRDVersion(apiVersion, schema)},
+		},
+	}
+}
+
+func newCRDVersion(apiVersion string, schema *extv1.JSONSchemaProps) extv1.CustomResourceDefinitionVersion {
+	return extv1.CustomResourceDefinitionVersion{
+		Name:    apiVersion,
+		Served:  true,
+		Storage: true,
+		Schema: &extv1.CustomResourceValidation{
+			OpenAPIV3Schema: schema,
+		},
+		Subresources: &extv1.CustomResourceSubresources{
+			Status: &extv1.CustomResourceSubresourceStatus{},
 		},
+		AdditionalPrinterColumns: defaultAdditionalPrinterColumns,
 	}
 }
 
+// AddVersion adds a served version with the given spec and status schemas to
+// the CRD. The version isn't stored, instances of the version are converted
+// to the storage version, which is the version the CRD was synthesized with.
+func AddVersion(
+	crd *extv1.CustomResourceDefinition,
+	apiVersion string,
+	spec, status extv1.JSONSchemaProps,
+	statusFieldsOverride bool,
+	deprecated bool,
+) error {
+	for _, version := range crd.Spec.Versions {
+		if version.Name == apiVersion {
+			return fmt.Errorf("version %s already exists", apiVersion)
+		}
+	}
+	version := newCRDVersion(apiVersion, newCRDSchema(spec, *status.DeepCopy(), statusFieldsOverride))
+	version.Storage = false
+	version.Deprecated = deprecated
+	crd.Spec.Versions = append(crd.Spec.Versions, version)
+	return nil
+}
+
 func newCRDSchema(spec, status extv1.JSONSchemaProps, statusFieldsOverride bool) *extv1.JSONSchemaProps {
 	if status.Properties == nil {
 		status.Properties = make(map[string]extv1.JSONSchemaProps)
@@ -81,6 +105,15 @@ func newCRDSchema(spec, status extv1.JSONSchemaProps, statusFieldsOverride bool)
 		if _, ok := status.Properties["conditions"]; !ok {
 			status.Properties["conditions"] = defaultConditionsType
 		}
+		if _, ok := status.Properties["observedGeneration"]; !ok {
+			status.Properties["observedGeneration"] = defaultObservedGenerationType
+		}
+		if _, ok := status.Properties["plan"]; !ok {
+			status.Properties["plan"] = defaultPlanType
+		}
+		if _, ok := status.Properties["resources"]; !ok {
+			status.Properties["resources"] = defaultResourcesType
+		}
 	}
 
 	return &extv1.JSONSchemaProps{
@@ -88,10 +121,12 @@ func newCRDSchema(spec, status extv1.JSONSchemaProps, statusFieldsOverride bool)
 		Required: []string{},
 		Properties: map[string]extv1.JSONSchemaProps{
 			"apiVersion": {
-				Type: "string",
+				Type:        "string",
+				Description: apiVersionDescription,
 			},
 			"kind": {
-				Type: "string",
+				Type:        "string",
+				Description: kindDescription,
 			},
 			"metadata": {
 				Type: "object",
@@ -101,3 +136,99 @@ func newCRDSchema(spec, status extv1.JSONSchemaProps, statusFieldsOverride bool)
 		},
 	}
 }
+
+// AddPrinterColumns adds the given printer columns to every version of the
+// CRD. The columns are inserted before the default Age column, which kubectl
+// conventionally shows last. It returns an error if a column has the same name
+// as an existing column.
+func AddPrinterColumns(crd *extv1.CustomResourceDefinition, columns []extv1.CustomResourceColumnDefinition) error {
+	for i := range crd.Spec.Versions {
+		if err := addVersionPrinterColumns(&crd.Spec.Versions[i], columns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddVersionPrinterColumns adds the given printer columns to the given
+// version of the CRD, like AddPrinterColumns.
+func AddVersionPrinterColumns(crd *extv1.CustomResourceDefinition, apiVersion string, columns []extv1.CustomResourceColumnDefinition) error {
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == apiVersion {
+			return addVersionPrinterColumns(&crd.Spec.Versions[i], columns)
+		}
+	}
+	return fmt.Errorf("version %s not found", apiVersion)
+}
+
+func addVersionPrinterColumns(version *extv1.CustomResourceDefinitionVersion, columns []extv1.CustomResourceColumnDefinition) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	merged := make([]extv1.CustomResourceColumnDefinition, 0, len(version.AdditionalPrinterColumns)+len(columns))
+	inserted := false
+	for _, column := range version.AdditionalPrinterColumns {
+		for _, added := range columns {
+			if strings.EqualFold(added.Name, column.Name) {
+				return fmt.Errorf("printer column %s conflicts with a default printer column", added.Name)
+			}
+		}
+		if !inserted && column.Name == "Age" {
+			merged = append(merged, columns...)
+			inserted = true
+		}
+		merged = append(merged, column)
+	}
+	if !inserted {
+		merged = append(merged, columns...)
+	}
+	version.AdditionalPrinterColumns = merged
+	return nil
+}
+
+// SetScale enables the scale subresource on the given version of the CRD. The
+// replicas paths must point to integer fields of the version schema, and the
+// label selector path, if any, to a string field.
+func SetScale(crd *extv1.CustomResourceDefinition, apiVersion string, scale *extv1.CustomResourceSubresourceScale) error {
+	for i := range crd.Spec.Versions {
+		version := &crd.Spec.Versions[i]
+		if version.Name != apiVersion {
+			continue
+		}
+		openAPISchema := version.Schema.OpenAPIV3Schema
+		if err := checkFieldType(openAPISchema, scale.SpecReplicasPath, "integer"); err != nil {
+			return fmt.Errorf("invalid spec replicas path: %w", err)
+		}
+		if err := checkFieldType(openAPISchema, scale.StatusReplicasPath, "integer"); err != nil {
+			return fmt.Errorf("invalid status replicas path: %w", err)
+		}
+		if scale.LabelSelectorPath != nil {
+			if err := checkFieldType(openAPISchema, *scale.LabelSelectorPath, "string"); err != nil {
+				return fmt.Errorf("invalid label selector path: %w", err)
+			}
+		}
+		version.Subresources.Scale = scale.DeepCopy()
+		return nil
+	}
+	return fmt.Errorf("version %s not found", apiVersion)
+}
+
+// checkFieldType checks that the given path, e.g `.spec.replicas`, points to
+// a field of the given type in the schema.
+func checkFieldType(schema *extv1.JSONSchemaProps, path, fieldType string) error {
+	if !strings.HasPrefix(path, ".") {
+		return fmt.Errorf("path %q must start with a dot", path)
+	}
+	field := schema
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		property, ok := field.Properties[segment]
+		if !ok {
+			return fmt.Errorf("field %s not found", path)
+		}
+		field = &property
+	}
+	if field.Type != fieldType {
+		return fmt.Errorf("field %s must be of type %s, got %q", path, fieldType, field.Type)
+	}
+	return nil
+}
@@ -222,6 +222,9 @@ func TestNewCRDSchema(t *testing.T) {
 			if tt.expectedStateField {
 				assert.Contains(t, statusProps.Properties, "state")
 				assert.Equal(t, defaultConditionsType, statusProps.Properties["conditions"])
+				assert.Equal(t, defaultObservedGenerationType, statusProps.Properties["observedGeneration"])
+				assert.Equal(t, defaultPlanType, statusProps.Properties["plan"])
+				assert.Equal(t, defaultResourcesType, statusProps.Properties["resources"])
 			}
 
 			if tt.status.Properties != nil {
@@ -234,3 +237,133 @@ func TestNewCRDSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestAddPrinterColumns(t *testing.T) {
+	t.Run("columns are inserted before age", func(t *testing.T) {
+		crd := SynthesizeCRD("kro.com", "v1", "Widget", extv1.JSONSchemaProps{Type: "object"}, extv1.JSONSchemaProps{Type: "object"}, true)
+		err := AddPrinterColumns(crd, []extv1.CustomResourceColumnDefinition{
+			{Name: "Replicas", Type: "integer", JSONPath: ".spec.replicas"},
+		})
+		require.NoError(t, err)
+
+		var names []string
+		for _, column := range crd.Spec.Versions[0].AdditionalPrinterColumns {
+			names = append(names, column.Name)
+		}
+		assert.Equal(t, []string{"State", "Synced", "Replicas", "Age"}, names)
+
+		// The defaults are shared between CRDs and must not be modified.
+		assert.Len(t, defaultAdditionalPrinterColumns, 3)
+	})
+
+	t.Run("conflicting column name", func(t *testing.T) {
+		crd := SynthesizeCRD("kro.com", "v1", "Widget", extv1.JSONSchemaProps{Type: "object"}, extv1.JSONSchemaProps{Type: "object"}, true)
+		err := AddPrinterColumns(crd, []extv1.CustomResourceColumnDefinition{
+			{Name: "state", Type: "string", JSONPath: ".spec.state"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestAddVersion(t *testing.T) {
+	crd := SynthesizeCRD("kro.com", "v1", "Widget", extv1.JSONSchemaProps{Type: "object"}, extv1.JSONSchemaProps{Type: "object"}, true)
+	spec := extv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]extv1.JSONSchemaProps{"size": {Type: "string"}},
+	}
+	require.NoError(t, AddVersion(crd, "v1alpha1", spec, extv1.JSONSchemaProps{Type: "object"}, true, true))
+
+	require.Len(t, crd.Spec.Versions, 2)
+	assert.True(t, crd.Spec.Versions[0].Storage)
+	version := crd.Spec.Versions[1]
+	assert.Equal(t, "v1alpha1", version.Name)
+	assert.True(t, version.Served)
+	assert.False(t, version.Storage)
+	assert.True(t, version.Deprecated)
+	assert.Contains(t, version.Schema.OpenAPIV3Schema.Properties["spec"].Properties, "size")
+	assert.NotNil(t, version.Subresources.Status)
+
+	assert.Error(t, AddVersion(crd, "v1", spec, extv1.JSONSchemaProps{Type: "object"}, true, false))
+}
+
+func TestSetScale(t *testing.T) {
+	newScaleCRD := func() *extv1.CustomResourceDefinition {
+		return SynthesizeCRD("kro.com", "v1", "Widget",
+			extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"replicas": {Type: "integer"},
+					"name":     {Type: "string"},
+				},
+			},
+			extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"availableReplicas": {Type: "integer"},
+					"selector":          {Type: "string"},
+				},
+			},
+			true,
+		)
+	}
+	selector := ".status.selector"
+
+	tests := []struct {
+		name    string
+		version string
+		scale   extv1.CustomResourceSubresourceScale
+		wantErr string
+	}{
+		{
+			name:    "valid paths",
+			version: "v1",
+			scale: extv1.CustomResourceSubresourceScale{
+				SpecReplicasPath:   ".spec.replicas",
+				StatusReplicasPath: ".status.availableReplicas",
+				LabelSelectorPath:  &selector,
+			},
+		},
+		{
+			name:    "unknown field",
+			version: "v1",
+			scale: extv1.CustomResourceSubresourceScale{
+				SpecReplicasPath:   ".spec.size",
+				StatusReplicasPath: ".status.availableReplicas",
+			},
+			wantErr: "not found",
+		},
+		{
+			name:    "wrong type",
+			version: "v1",
+			scale: extv1.CustomResourceSubresourceScale{
+				SpecReplicasPath:   ".spec.name",
+				StatusReplicasPath: ".status.availableReplicas",
+			},
+			wantErr: "must be of type integer",
+		},
+		{
+			name:    "unknown version",
+			version: "v2",
+			scale: extv1.CustomResourceSubresourceScale{
+				SpecReplicasPath:   ".spec.replicas",
+				StatusReplicasPath: ".status.availableReplicas",
+			},
+			wantErr: "version v2 not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crd := newScaleCRD()
+			err := SetScale(crd, tt.version, &tt.scale)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, crd.Spec.Versions[0].Subresources.Scale)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &tt.scale, crd.Spec.Versions[0].Subresources.Scale)
+			assert.NotNil(t, crd.Spec.Versions[0].Subresources.Status)
+		})
+	}
+}
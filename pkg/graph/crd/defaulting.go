@@ -0,0 +1,46 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package crd
+
+import (
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ApplyDefaults applies the defaults of the given version of the CRD to the
+// object, as the API server does when it's created or updated.
+func ApplyDefaults(obj *unstructured.Unstructured, crd *extv1.CustomResourceDefinition, version string) error {
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		internal := &apiextensions.JSONSchemaProps{}
+		if err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+			v.Schema.OpenAPIV3Schema, internal, nil); err != nil {
+			return err
+		}
+		structural, err := structuralschema.NewStructural(internal)
+		if err != nil {
+			return fmt.Errorf("failed to build structural schema of %s: %w", crd.Spec.Names.Kind, err)
+		}
+		structuraldefaulting.Default(obj.Object, structural)
+		return nil
+	}
+	return fmt.Errorf("version %s of %s isn't served", version, crd.Spec.Names.Kind)
+}
@@ -17,33 +17,145 @@ import (
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
+const (
+	// apiVersionDescription and kindDescription are the standard descriptions of
+	// the apiVersion and kind fields, as found in the Kubernetes built-in types.
+	apiVersionDescription = "APIVersion defines the versioned schema of this representation of an object. " +
+		"Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. " +
+		"More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources"
+	kindDescription = "Kind is a string value representing the REST resource this object represents. " +
+		"Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. " +
+		"More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds"
+)
+
 var (
 	defaultStateType = extv1.JSONSchemaProps{
-		Type: "string",
+		Type:        "string",
+		Description: "State is a high level summary of the instance state, e.g ACTIVE, IN_PROGRESS, FAILED or DELETING.",
+	}
+	defaultObservedGenerationType = extv1.JSONSchemaProps{
+		Type:        "integer",
+		Description: "ObservedGeneration is the generation of the instance the status was last set for.",
 	}
 	defaultConditionsType = extv1.JSONSchemaProps{
-		Type: "array",
+		Type:        "array",
+		Description: "Conditions represent the latest available observations of the instance state.",
 		Items: &extv1.JSONSchemaPropsOrArray{
 			Schema: &extv1.JSONSchemaProps{
 				Type: "object",
 				Properties: map[string]extv1.JSONSchemaProps{
 					"type": {
-						Type: "string", // Boolean maybe?
+						Type:        "string", // Boolean maybe?
+						Description: "Type of the condition.",
 					},
 					"status": {
-						Type: "string",
+						Type:        "string",
+						Description: "Status of the condition, one of True, False or Unknown.",
 					},
 					"reason": {
-						Type: "string",
+						Type:        "string",
+						Description: "Reason is a machine readable explanation of the condition's last transition.",
 					},
 					"message": {
-						Type: "string",
+						Type:        "string",
+						Description: "Message is a human readable explanation of the condition's last transition.",
 					},
 					"lastTransitionTime": {
-						Type: "string",
+						Type:        "string",
+						Description: "LastTransitionTime is the last time the condition transitioned from one status to another.",
 					},
 					"observedGeneration": {
-						Type: "integer",
+						Type:        "integer",
+						Description: "ObservedGeneration is the generation of the instance the condition was set for.",
+					},
+				},
+			},
+		},
+	}
+	defaultPlanType = extv1.JSONSchemaProps{
+		Type:        "array",
+		Description: "Plan lists the changes kro would make to the resources of the instance, if it requests a plan.",
+		Items: &extv1.JSONSchemaPropsOrArray{
+			Schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"id": {
+						Type:        "string",
+						Description: "ID of the resource in the ResourceGraphDefinition.",
+					},
+					"kind": {
+						Type:        "string",
+						Description: "Kind of the resource.",
+					},
+					"name": {
+						Type:        "string",
+						Description: "Name of the resource.",
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace of the resource.",
+					},
+					"action": {
+						Type:        "string",
+						Description: "Action is the change made to the resource, one of Create, Update, Delete or Unknown.",
+					},
+					"fields": {
+						Type:        "array",
+						Description: "Fields are the paths of the fields an update changes.",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"message": {
+						Type:        "string",
+						Description: "Message explains why the change can't be planned, if it can't.",
+					},
+				},
+			},
+		},
+	}
+	defaultResourcesType = extv1.JSONSchemaProps{
+		Type:        "array",
+		Description: "Resources lists the resources managed by the instance, and where each of them stands.",
+		Items: &extv1.JSONSchemaPropsOrArray{
+			Schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"id": {
+						Type:        "string",
+						Description: "ID of the resource in the ResourceGraphDefinition.",
+					},
+					"apiVersion": {
+						Type:        "string",
+						Description: "APIVersion of the resource.",
+					},
+					"kind": {
+						Type:        "string",
+						Description: "Kind of the resource.",
+					},
+					"name": {
+						Type:        "string",
+						Description: "Name of the resource.",
+					},
+					"namespace": {
+						Type:        "string",
+						Description: "Namespace of the resource.",
+					},
+					"state": {
+						Type:        "string",
+						Description: "State of the resource in the last reconciliation, e.g SYNCED, WAITING_FOR_READINESS or ERROR.",
+					},
+					"ready": {
+						Type:        "boolean",
+						Description: "Ready reports whether the readiness conditions of the resource are met.",
+					},
+					"lastAppliedGeneration": {
+						Type:        "integer",
+						Description: "LastAppliedGeneration is the last generation of the instance applied to the resource.",
+					},
+					"message": {
+						Type:        "string",
+						Description: "Message explains the state of the resource, if it failed or is waiting.",
 					},
 				},
 			},
@@ -0,0 +1,60 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kro-run/kro/pkg/graph/dag"
+)
+
+// dependencyEdge is an edge of the dependency graph, from a resource to one of
+// its dependencies.
+type dependencyEdge struct {
+	from, to string
+}
+
+// dependencyEdges records the origins of the edges of the dependency graph,
+// i.e the fields and expressions, or the dependsOn entries, that created
+// them. They're used to explain dependency cycles.
+type dependencyEdges map[dependencyEdge][]string
+
+// add records the given origin for the edges from the resource to its
+// dependencies.
+func (e dependencyEdges) add(from string, dependencies []string, origin string) {
+	for _, to := range dependencies {
+		edge := dependencyEdge{from: from, to: to}
+		if !slices.Contains(e[edge], origin) {
+			e[edge] = append(e[edge], origin)
+		}
+	}
+}
+
+// explainCycle wraps the given error with the origins of the edges of the
+// cycle, if it is a cycle error.
+func (e dependencyEdges) explainCycle(err error) error {
+	cycleErr := dag.AsCycleError[string](err)
+	if cycleErr == nil || len(cycleErr.Cycle) < 2 {
+		return err
+	}
+	explanations := make([]string, 0, len(cycleErr.Cycle)-1)
+	for i := 0; i < len(cycleErr.Cycle)-1; i++ {
+		from, to := cycleErr.Cycle[i], cycleErr.Cycle[i+1]
+		explanations = append(explanations, fmt.Sprintf("%s depends on %s through %s",
+			from, to, strings.Join(e[dependencyEdge{from: from, to: to}], ", ")))
+	}
+	return fmt.Errorf("%w (%s)", err, strings.Join(explanations, "; "))
+}
@@ -200,7 +200,9 @@ func (d *DirectedAcyclicGraph[T]) hasCycle() (bool, []T) {
 		recStack[node] = true
 		cyclePath = append(cyclePath, node)
 
-		for dependency := range d.Vertices[node].DependsOn {
+		// Dependencies and nodes are visited in order, so that the same cycle
+		// is reported for the same graph.
+		for _, dependency := range slices.Sorted(maps.Keys(d.Vertices[node].DependsOn)) {
 			if !visited[dependency] {
 				if dfs(dependency) {
 					return true
@@ -217,7 +219,7 @@ func (d *DirectedAcyclicGraph[T]) hasCycle() (bool, []T) {
 		return false
 	}
 
-	for node := range d.Vertices {
+	for _, node := range slices.Sorted(maps.Keys(d.Vertices)) {
 		if !visited[node] {
 			cyclePath = []T{}
 			if dfs(node) {
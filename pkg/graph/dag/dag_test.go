@@ -83,6 +83,8 @@ func TestDAGHasCycle(t *testing.T) {
 
 	if err := d.AddDependencies("C", []string{"A"}); err == nil {
 		t.Error("Expected error when creating a cycle, but got nil")
+	} else if got, want := err.Error(), "graph contains a cycle: A -> B -> C -> A"; got != want {
+		t.Errorf("AddDependencies returned %q, want %q", got, want)
 	}
 
 	// pointless to test for the cycle here, so we need to emulate one
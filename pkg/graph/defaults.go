@@ -0,0 +1,187 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/parser"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/simpleschema"
+)
+
+// instanceDefault is the default of a field of the instance computed by an
+// expression.
+type instanceDefault struct {
+	// path is the path of the field in the instance, e.g
+	// `["spec", "storage", "size"]`.
+	path []string
+	// expression is the standalone expression computing the default, without
+	// the surrounding `${}`.
+	expression string
+}
+
+// buildInstanceDefaults parses and validates the expression defaults of the
+// fields of the instance spec. They can only refer to the instance, the
+// cluster and the values, and must return a value of the type of their field.
+func buildInstanceDefaults(
+	specDefaults []simpleschema.ExpressionDefault,
+	names []string,
+	instance *Resource,
+) ([]instanceDefault, error) {
+	if len(specDefaults) == 0 {
+		return nil, nil
+	}
+
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(names))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	context := emulatedVariables(names, instance)
+	defaults := make([]instanceDefault, 0, len(specDefaults))
+	for _, specDefault := range specDefaults {
+		field := "spec." + strings.Join(specDefault.Path, ".")
+		expressions, err := parser.ParseConditionExpressions([]string{specDefault.Expression})
+		if err != nil {
+			return nil, fmt.Errorf("invalid default of %s: %w", field, err)
+		}
+		output, err := ensureExpression(env, expressions[0], names, context)
+		if err != nil {
+			return nil, fmt.Errorf("default of %s can only refer to the instance, the cluster and the values: %w", field, err)
+		}
+		fieldSchema := instanceSpecSchema(instance)
+		for _, segment := range specDefault.Path {
+			property := fieldSchema.Properties[segment]
+			fieldSchema = &property
+		}
+		if !defaultTypeMatches(output, fieldSchema) {
+			return nil, fmt.Errorf("default of %s must be of type %s, got %s", field, fieldSchema.Type, output.Type().TypeName())
+		}
+		defaults = append(defaults, instanceDefault{
+			path:       append([]string{"spec"}, specDefault.Path...),
+			expression: expressions[0],
+		})
+	}
+	return defaults, nil
+}
+
+// defaultTypeMatches returns whether the given output of a default expression
+// can be the value of a field of the given schema. Objects, arrays and
+// int-or-string fields accept any output, their values are validated by the
+// API server.
+func defaultTypeMatches(output ref.Val, fieldSchema *extv1.JSONSchemaProps) bool {
+	switch fieldSchema.Type {
+	case "string":
+		return output.Type() == types.StringType
+	case "integer":
+		return output.Type() == types.IntType || output.Type() == types.UintType
+	case "number":
+		return output.Type() == types.DoubleType || output.Type() == types.IntType || output.Type() == types.UintType
+	case "boolean":
+		return output.Type() == types.BoolType
+	default:
+		return true
+	}
+}
+
+// ApplyDefaults sets the unset fields of the given instance that have a
+// default computed by an expression, and returns whether any was set. The
+// expressions are evaluated against the instance as given, so defaults can't
+// depend on each other. Like the defaults of the API server, fields of unset
+// objects are left unset, except the fields of the spec.
+func (rgd *Graph) ApplyDefaults(instance *unstructured.Unstructured, opts ...RuntimeOption) (bool, error) {
+	defaults, err := rgd.Defaults(instance, opts...)
+	if err != nil || len(defaults) == 0 {
+		return false, err
+	}
+	for _, d := range rgd.defaults {
+		value, found, _ := unstructured.NestedFieldNoCopy(defaults, d.path...)
+		if !found {
+			continue
+		}
+		if err := unstructured.SetNestedField(instance.Object, value, d.path...); err != nil {
+			return false, fmt.Errorf("failed to set default of %s: %w", strings.Join(d.path, "."), err)
+		}
+	}
+	return true, nil
+}
+
+// Defaults returns the fields ApplyDefaults would set on the given instance,
+// in an object holding only them, e.g to be applied as a merge patch. It's
+// empty if there are none.
+func (rgd *Graph) Defaults(instance *unstructured.Unstructured, opts ...RuntimeOption) (map[string]interface{}, error) {
+	defaults := map[string]interface{}{}
+	if len(rgd.defaults) == 0 {
+		return defaults, nil
+	}
+	options := &runtimeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	context := map[string]interface{}{
+		"schema": runtime.SchemaVariable(instance),
+	}
+	if rgd.cluster != nil {
+		context["cluster"] = rgd.cluster.Variable()
+	}
+	if len(rgd.ValuesFrom) > 0 {
+		context["values"] = valuesVariable(options.values)
+	}
+
+	for _, d := range rgd.defaults {
+		if !defaultApplies(instance, d.path) {
+			continue
+		}
+		program, err := rgd.programs.Program([]string{"schema", "cluster", "values"}, d.expression)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile default of %s: %w", strings.Join(d.path, "."), err)
+		}
+		output, _, err := krocel.Evaluate(program, context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate default of %s: %w", strings.Join(d.path, "."), err)
+		}
+		value, err := krocel.GoNativeType(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert default of %s: %w", strings.Join(d.path, "."), err)
+		}
+		if value == nil {
+			continue
+		}
+		if err := unstructured.SetNestedField(defaults, value, d.path...); err != nil {
+			return nil, fmt.Errorf("failed to set default of %s: %w", strings.Join(d.path, "."), err)
+		}
+	}
+	return defaults, nil
+}
+
+// defaultApplies returns whether the field of the given instance at the given
+// path is unset, and its object is set, or is the spec.
+func defaultApplies(instance *unstructured.Unstructured, path []string) bool {
+	if value, found, _ := unstructured.NestedFieldNoCopy(instance.Object, path...); found && value != nil {
+		return false
+	}
+	if len(path) == 2 {
+		return true
+	}
+	_, found, err := unstructured.NestedMap(instance.Object, path[:len(path)-1]...)
+	return found && err == nil
+}
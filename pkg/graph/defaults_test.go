@@ -0,0 +1,131 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_ExpressionDefaults(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	valuesFrom := func(rgd *v1alpha1.ResourceGraphDefinition) {
+		rgd.Spec.ValuesFrom = []v1alpha1.ValuesSource{
+			{ConfigMapRef: &v1alpha1.ValuesReference{Name: "defaults"}},
+		}
+	}
+	secret := generator.WithResource("secret", map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name": "${schema.spec.name}",
+		},
+	}, nil, nil)
+	instance := func(spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kro.run/v1alpha1",
+			"kind":       "Test",
+			"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+			"spec":       spec,
+		}}
+	}
+
+	t.Run("defaults of unset fields", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema("Test", "v1alpha1", map[string]interface{}{
+				"name": "string | default=${schema.metadata.name + '-db'}",
+				"storage": map[string]interface{}{
+					"size":     "string | default=${values.storageSize}",
+					"replicas": "integer | default=${size(schema.metadata.name)}",
+				},
+			}, nil),
+			valuesFrom,
+			secret,
+		))
+		require.NoError(t, err)
+
+		obj := instance(map[string]interface{}{"storage": map[string]interface{}{"replicas": int64(3)}})
+		defaults, err := g.Defaults(obj, WithValues(map[string]string{"storageSize": "10Gi"}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"spec": map[string]interface{}{
+				"name":    "test-db",
+				"storage": map[string]interface{}{"size": "10Gi"},
+			},
+		}, defaults)
+
+		changed, err := g.ApplyDefaults(obj, WithValues(map[string]string{"storageSize": "10Gi"}))
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, map[string]interface{}{
+			"name":    "test-db",
+			"storage": map[string]interface{}{"size": "10Gi", "replicas": int64(3)},
+		}, obj.Object["spec"])
+
+		changed, err = g.ApplyDefaults(obj, WithValues(map[string]string{"storageSize": "20Gi"}))
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		// The fields of unset objects are left unset.
+		obj = instance(map[string]interface{}{"name": "app"})
+		changed, err = g.ApplyDefaults(obj)
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, map[string]interface{}{"name": "app"}, obj.Object["spec"])
+	})
+
+	for _, tt := range []struct {
+		name    string
+		spec    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "default of another type",
+			spec:    map[string]interface{}{"name": "string", "replicas": "integer | default=${schema.metadata.name}"},
+			wantErr: "default of spec.replicas must be of type integer",
+		},
+		{
+			name:    "default referring to a resource",
+			spec:    map[string]interface{}{"name": "string | default=${secret.metadata.name}"},
+			wantErr: "default of spec.name can only refer to the instance, the cluster and the values",
+		},
+		{
+			name:    "default that isn't a standalone expression",
+			spec:    map[string]interface{}{"name": "string | default=${schema.metadata.name}-db"},
+			wantErr: "invalid default of spec.name",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+				generator.WithSchema("Test", "v1alpha1", tt.spec, nil),
+				secret,
+			))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_DependsOn(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(vpcDependsOn, subnetDependsOn []string) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema(
+				"Test", "v1alpha1",
+				map[string]interface{}{"name": "string"},
+				nil,
+			),
+			generator.WithResource("subnet", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "Subnet",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+			}, nil, nil),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+			}, nil, nil),
+		)
+		rgd.Spec.Resources[0].DependsOn = subnetDependsOn
+		rgd.Spec.Resources[1].DependsOn = vpcDependsOn
+		return rgd
+	}
+
+	t.Run("orders unrelated resources", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(nil, []string{"vpc"}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vpc", "subnet"}, g.TopologicalOrder)
+		assert.Equal(t, []string{"vpc"}, g.Resources["subnet"].GetDependencies())
+	})
+
+	tests := []struct {
+		name            string
+		vpcDependsOn    []string
+		subnetDependsOn []string
+		wantErr         string
+	}{
+		{
+			name:            "unknown resource",
+			subnetDependsOn: []string{"igw"},
+			wantErr:         "resource subnet depends on unknown resource igw",
+		},
+		{
+			name:            "self dependency",
+			subnetDependsOn: []string{"subnet"},
+			wantErr:         "resource subnet can't depend on itself",
+		},
+		{
+			name:            "cycle",
+			vpcDependsOn:    []string{"subnet"},
+			subnetDependsOn: []string{"vpc"},
+			wantErr:         "subnet depends on vpc through dependsOn; vpc depends on subnet through dependsOn",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := builder.NewResourceGraphDefinition(newRGD(tt.vpcDependsOn, tt.subnetDependsOn))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -14,6 +14,7 @@
 package emulator
 
 import (
+	"encoding/base64"
 	"fmt"
 	"math/rand"
 	"slices"
@@ -155,7 +156,12 @@ func (e *Emulator) generateString(schema *spec.Schema) string {
 	if len(schema.Enum) > 0 {
 		return schema.Enum[e.rand.Intn(len(schema.Enum))].(string)
 	}
-	return fmt.Sprintf("dummy-string-%d", e.rand.Intn(1000))
+	value := fmt.Sprintf("dummy-string-%d", e.rand.Intn(1000))
+	if schema.Format == "byte" {
+		// Binary data is encoded in base64.
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return value
 }
 
 func (e *Emulator) generateInteger(schema *spec.Schema) int64 {
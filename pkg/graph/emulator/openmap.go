@@ -0,0 +1,141 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// OpenMaps returns a copy of the given emulated value in which the maps with
+// free-form keys, such as the data of a Secret, hold an emulated value for any
+// key. Expressions accessing arbitrary keys of these maps, which the emulated
+// value can't know about, can then be dry-run.
+//
+// The returned value is meant to be evaluated by CEL: open maps are CEL
+// values, and can't be copied or serialized like the rest of the object.
+func OpenMaps(value interface{}, schema *spec.Schema) (interface{}, error) {
+	return NewEmulator().openMaps(value, schema)
+}
+
+func (e *Emulator) openMaps(value interface{}, schema *spec.Schema) (interface{}, error) {
+	if schema == nil {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(schema.Properties) == 0 && schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			return e.openMap(v, schema.AdditionalProperties.Schema)
+		}
+		result := make(map[string]interface{}, len(v))
+		for key, fieldValue := range v {
+			fieldSchema, ok := schema.Properties[key]
+			if !ok {
+				result[key] = fieldValue
+				continue
+			}
+			opened, err := e.openMaps(fieldValue, &fieldSchema)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = opened
+		}
+		return result, nil
+	case []interface{}:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return value, nil
+		}
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			opened, err := e.openMaps(item, schema.Items.Schema)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = opened
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// openMap returns the open map holding the given entries, and an emulated
+// value of the given schema for any other key.
+func (e *Emulator) openMap(entries map[string]interface{}, valueSchema *spec.Schema) (ref.Val, error) {
+	opened := make(map[string]interface{}, len(entries))
+	for key, value := range entries {
+		openedValue, err := e.openMaps(value, valueSchema)
+		if err != nil {
+			return nil, err
+		}
+		opened[key] = openedValue
+	}
+
+	sample, err := e.generateValue(valueSchema)
+	if err != nil {
+		return nil, fmt.Errorf("error generating map value: %w", err)
+	}
+	openedSample, err := e.openMaps(sample, valueSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper, ok := types.DefaultTypeAdapter.NativeToValue(opened).(traits.Mapper)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert map to a CEL map")
+	}
+	return &openMap{
+		Mapper: mapper,
+		sample: types.DefaultTypeAdapter.NativeToValue(openedSample),
+	}, nil
+}
+
+// openMap is a CEL map returning a sample value for the string keys it
+// doesn't hold.
+type openMap struct {
+	traits.Mapper
+	sample ref.Val
+}
+
+// Contains implements traits.Container.
+func (m *openMap) Contains(key ref.Val) ref.Val {
+	if key.Type() == types.StringType {
+		return types.True
+	}
+	return m.Mapper.Contains(key)
+}
+
+// Get implements traits.Indexer.
+func (m *openMap) Get(key ref.Val) ref.Val {
+	if value, found := m.Find(key); found {
+		return value
+	}
+	return m.Mapper.Get(key)
+}
+
+// Find implements traits.Mapper.
+func (m *openMap) Find(key ref.Val) (ref.Val, bool) {
+	if value, found := m.Mapper.Find(key); found {
+		return value, true
+	}
+	if key.Type() == types.StringType {
+		return m.sample, true
+	}
+	return nil, false
+}
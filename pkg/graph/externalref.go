@@ -0,0 +1,57 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// parseResourceObject returns the object declared by the given resource, which is
+// either its template, the object its external reference refers to or the
+// object it patches. The object of an external reference only holds its
+// apiVersion, kind, name and namespace: the rest of it is read from the
+// cluster.
+func parseResourceObject(rgResource *v1alpha1.Resource) (map[string]interface{}, error) {
+	if rgResource.Patch != nil {
+		return parsePatchObject(rgResource)
+	}
+	if rgResource.ExternalRef == nil {
+		object := map[string]interface{}{}
+		if err := yaml.UnmarshalStrict(rgResource.Template.Raw, &object); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal resource %s: %w", rgResource.ID, err)
+		}
+		return object, nil
+	}
+
+	if len(rgResource.Template.Raw) > 0 {
+		return nil, fmt.Errorf("resource %s can't declare both a template and an externalRef", rgResource.ID)
+	}
+	if rgResource.ForEach != nil {
+		return nil, fmt.Errorf("resource %s can't iterate over a collection with an externalRef", rgResource.ID)
+	}
+	ref := rgResource.ExternalRef
+	objectMeta := map[string]interface{}{"name": ref.Metadata.Name}
+	if ref.Metadata.Namespace != "" {
+		objectMeta["namespace"] = ref.Metadata.Namespace
+	}
+	return map[string]interface{}{
+		"apiVersion": ref.APIVersion,
+		"kind":       ref.Kind,
+		"metadata":   objectMeta,
+	}, nil
+}
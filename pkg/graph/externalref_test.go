@@ -0,0 +1,188 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_ExternalRef(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	schema := generator.WithSchema(
+		"Test", "v1alpha1",
+		map[string]interface{}{"name": "string", "vpcName": "string"},
+		nil,
+	)
+	vpcRef := &v1alpha1.ExternalRef{
+		APIVersion: "ec2.services.k8s.aws/v1alpha1",
+		Kind:       "VPC",
+		Metadata: v1alpha1.ExternalRefMetadata{
+			Name:      "${schema.spec.vpcName}",
+			Namespace: "network",
+		},
+	}
+	subnet := generator.WithResource("subnet", map[string]interface{}{
+		"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+		"kind":       "Subnet",
+		"metadata": map[string]interface{}{
+			"name": "${schema.spec.name}",
+		},
+		"spec": map[string]interface{}{
+			"vpcID": "${vpc.status.vpcID}",
+		},
+	}, nil, nil)
+
+	t.Run("resolved against the referenced object", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+			schema,
+			generator.WithExternalRef("vpc", vpcRef, []string{"${vpc.status.state == 'available'}"}, nil),
+			subnet,
+		))
+		require.NoError(t, err)
+		assert.True(t, g.Resources["vpc"].IsExternalRef())
+		assert.False(t, g.Resources["subnet"].IsExternalRef())
+		assert.Equal(t, []string{"vpc", "subnet"}, g.TopologicalOrder)
+		assert.Equal(t, []string{"vpc"}, g.Resources["subnet"].GetDependencies())
+
+		rt, err := g.NewGraphRuntime(&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kro.run/v1alpha1",
+			"kind":       "Test",
+			"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+			"spec":       map[string]interface{}{"name": "subnet", "vpcName": "shared"},
+		}})
+		require.NoError(t, err)
+
+		resource, state := rt.GetResource("vpc")
+		require.Equal(t, runtime.ResourceStateResolved, state)
+		assert.Equal(t, "shared", resource.GetName())
+		assert.Equal(t, "network", resource.GetNamespace())
+
+		rt.SetResource("vpc", &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"vpcID": "vpc-1", "state": "available"},
+		}})
+		_, err = rt.Synchronize()
+		require.NoError(t, err)
+
+		resource, state = rt.GetResource("subnet")
+		require.Equal(t, runtime.ResourceStateResolved, state)
+		vpcID, _, _ := unstructured.NestedString(resource.Object, "spec", "vpcID")
+		assert.Equal(t, "vpc-1", vpcID)
+	})
+
+	t.Run("template and externalRef", func(t *testing.T) {
+		rgd := generator.NewResourceGraphDefinition("testrgd", schema, subnet)
+		rgd.Spec.Resources[0].ExternalRef = vpcRef
+		_, err := builder.NewResourceGraphDefinition(rgd)
+		assert.ErrorContains(t, err, "can't declare both a template and an externalRef")
+	})
+
+	t.Run("externalRef with forEach", func(t *testing.T) {
+		rgd := generator.NewResourceGraphDefinition("testrgd", schema, generator.WithExternalRef("vpc", vpcRef, nil, nil))
+		rgd.Spec.Resources[0].ForEach = &v1alpha1.ForEach{Items: "${schema.spec.names}"}
+		_, err := builder.NewResourceGraphDefinition(rgd)
+		assert.ErrorContains(t, err, "can't iterate over a collection with an externalRef")
+	})
+
+	t.Run("unknown field of the referenced object", func(t *testing.T) {
+		_, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+			schema,
+			generator.WithExternalRef("vpc", vpcRef, []string{"${vpc.status.unknown == 'available'}"}, nil),
+		))
+		assert.Error(t, err)
+	})
+}
+
+func TestGraphBuilder_ExternalRefSecret(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+		generator.WithSchema(
+			"Test", "v1alpha1",
+			map[string]interface{}{"name": "string"},
+			nil,
+		),
+		generator.WithExternalRef("credentials", &v1alpha1.ExternalRef{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata:   v1alpha1.ExternalRefMetadata{Name: "${schema.spec.name}-credentials"},
+		}, nil, nil),
+		generator.WithResource("pod", map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}",
+			},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{
+						"name":  "app",
+						"image": "app",
+						"env": []interface{}{
+							map[string]interface{}{
+								"name":  "USERNAME",
+								"value": "${string(base64.decode(credentials.data.username))}",
+							},
+						},
+					},
+				},
+			},
+		}, nil, nil),
+	))
+	require.NoError(t, err)
+
+	rt, err := g.NewGraphRuntime(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kro.run/v1alpha1",
+		"kind":       "Test",
+		"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+		"spec":       map[string]interface{}{"name": "app"},
+	}})
+	require.NoError(t, err)
+
+	resource, state := rt.GetResource("credentials")
+	require.Equal(t, runtime.ResourceStateResolved, state)
+	assert.Equal(t, "app-credentials", resource.GetName())
+
+	rt.SetResource("credentials", &unstructured.Unstructured{Object: map[string]interface{}{
+		"data": map[string]interface{}{"username": "YWRtaW4="},
+	}})
+	_, err = rt.Synchronize()
+	require.NoError(t, err)
+
+	resource, state = rt.GetResource("pod")
+	require.Equal(t, runtime.ResourceStateResolved, state)
+	containers, _, _ := unstructured.NestedSlice(resource.Object, "spec", "containers")
+	env, _, _ := unstructured.NestedSlice(containers[0].(map[string]interface{}), "env")
+	assert.Equal(t, "admin", env[0].(map[string]interface{})["value"])
+}
@@ -33,6 +33,14 @@ func TestParse(t *testing.T) {
 				{Name: "containers", Index: -1},
 			},
 		},
+		{
+			name: "single character field",
+			path: "spec.x",
+			want: []Segment{
+				{Name: "spec", Index: -1},
+				{Name: "x", Index: -1},
+			},
+		},
 		{
 			name: "path with array",
 			path: "spec.containers[0]",
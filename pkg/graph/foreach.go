@@ -0,0 +1,295 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/types"
+	"golang.org/x/exp/maps"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/parser"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// parseForEach parses the standalone items and key expressions of the given
+// forEach, if any.
+func parseForEach(forEach *v1alpha1.ForEach) (string, string, error) {
+	if forEach == nil {
+		return "", "", nil
+	}
+	items, err := parser.ParseConditionExpressions([]string{forEach.Items})
+	if err != nil {
+		return "", "", fmt.Errorf("invalid items expression: %w", err)
+	}
+	if forEach.Key == "" {
+		return items[0], "", nil
+	}
+	key, err := parser.ParseConditionExpressions([]string{forEach.Key})
+	if err != nil {
+		return "", "", fmt.Errorf("invalid key expression: %w", err)
+	}
+	return items[0], key[0], nil
+}
+
+// ensureForEachExpressions validates the expressions of a resource template
+// iterating over a collection of the instance. The items expression can only
+// refer to the instance and must return a list or a map, and the key
+// expression must return a string.
+//
+// The template and includeWhen expressions are dry-run with the first item of
+// the emulated collection as `each`. If the emulated collection is empty,
+// which is usually the case of maps, they're only compiled.
+func ensureForEachExpressions(
+	expressionContext, includeWhenContext map[string]*Resource,
+	resource *Resource,
+) error {
+	names := append(maps.Keys(expressionContext), resource.id)
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(names))
+	if err != nil {
+		return fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	forEachEnv, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(append(names, "each")))
+	if err != nil {
+		return fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	// The includeWhen context only holds the variables, e.g the instance.
+	dependencies, _, err := extractDependencies(env, resource.forEach, names, maps.Keys(includeWhenContext))
+	if err != nil {
+		return fmt.Errorf("failed to extract dependencies of items expression %s: %w", resource.forEach, err)
+	}
+	if len(dependencies) > 0 {
+		return fmt.Errorf("items expression %s can only refer to the instance, found %v", resource.forEach, dependencies)
+	}
+	output, err := dryRunExpression(env, resource.forEach, includeWhenContext)
+	if err != nil {
+		return fmt.Errorf("failed to dry-run items expression %s: %w",
+			resource.forEach, krocel.NewExpressionError(resource.id, "forEach.items", resource.forEach, err))
+	}
+	collection, err := krocel.GoNativeType(output)
+	if err != nil {
+		return fmt.Errorf("items expression %s must return a list or a map: %w", resource.forEach, err)
+	}
+	items, err := collectionItems(collection)
+	if err != nil {
+		return fmt.Errorf("items expression %s must return a list or a map: %w", resource.forEach, err)
+	}
+
+	if len(items) == 0 {
+		expressions := slices.Clone(resource.includeWhenExpressions)
+		if resource.forEachKey != "" {
+			expressions = append(expressions, resource.forEachKey)
+		}
+		for _, v := range resource.variables {
+			expressions = append(expressions, v.Expressions...)
+		}
+		for _, expression := range expressions {
+			if _, issues := forEachEnv.Compile(expression); issues != nil && issues.Err() != nil {
+				return fmt.Errorf("failed to compile expression %s: %w", expression, krocel.NewCompileError(issues))
+			}
+		}
+		return nil
+	}
+
+	each := items[0]
+	withEach := func(context map[string]*Resource) map[string]*Resource {
+		copied := maps.Clone(context)
+		copied["each"] = &Resource{emulatedObject: &unstructured.Unstructured{Object: each}}
+		return copied
+	}
+	if resource.forEachKey != "" {
+		output, err := ensureExpression(forEachEnv, resource.forEachKey, []string{resource.id}, withEach(includeWhenContext))
+		if err != nil {
+			return fmt.Errorf("failed to dry-run key expression %s: %w",
+				resource.forEachKey, krocel.NewExpressionError(resource.id, "forEach.key", resource.forEachKey, err))
+		}
+		if output.Type() != types.StringType {
+			return fmt.Errorf("output of key expression %s can only be of type string", resource.forEachKey)
+		}
+	}
+	if err := ensureResourceExpressions(forEachEnv, withEach(expressionContext), resource); err != nil {
+		return err
+	}
+	return ensureIncludeWhenExpressions(forEachEnv, withEach(includeWhenContext), resource)
+}
+
+// validateForEachReferences ensures that neither the resources, the instance
+// status nor the instance conditions refer to a resource template iterating
+// over a collection: the resources it expands into are only known for a given
+// instance.
+func validateForEachReferences(resources map[string]*Resource, instance *Resource, conditions []runtime.Condition) error {
+	check := func(owner string, dependencies []string) error {
+		for _, dependency := range dependencies {
+			if resource, ok := resources[dependency]; ok && resource.IsForEach() {
+				return fmt.Errorf("%s can't refer to resource %s, which iterates over a collection", owner, dependency)
+			}
+		}
+		return nil
+	}
+	for id, resource := range resources {
+		if err := check("resource "+id, resource.dependencies); err != nil {
+			return err
+		}
+	}
+	if err := check("instance status", instance.dependencies); err != nil {
+		return err
+	}
+	for _, condition := range conditions {
+		if err := check("condition "+condition.Type, condition.Dependencies); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandResources returns the resources of the graph for the given instance,
+// along with their topological order. Resource templates iterating over a
+// collection of the instance are expanded into one resource per item,
+// identified by `<id>[<key>]`.
+func (rgd *Graph) expandResources(
+	instance *unstructured.Unstructured,
+	values map[string]interface{},
+	locals map[string]interface{},
+) (map[string]runtime.Resource, []string, error) {
+	resources := make(map[string]runtime.Resource, len(rgd.Resources))
+	order := make([]string, 0, len(rgd.TopologicalOrder))
+	context := map[string]interface{}{
+		"schema": runtime.SchemaVariable(instance),
+	}
+	if rgd.cluster != nil {
+		context["cluster"] = rgd.cluster.Variable()
+	}
+	if values != nil {
+		context["values"] = values
+	}
+	if locals != nil {
+		context["locals"] = locals
+	}
+	for _, id := range rgd.TopologicalOrder {
+		resource := rgd.Resources[id]
+		if !resource.IsForEach() {
+			resources[id] = resource.DeepCopy()
+			order = append(order, id)
+			continue
+		}
+
+		items, err := resource.forEachItems(rgd.programs, context)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to expand resource %s: %w", id, err)
+		}
+		for _, each := range items {
+			expanded := resource.DeepCopy()
+			expanded.id = fmt.Sprintf("%s[%s]", id, each["key"])
+			expanded.templateID = id
+			expanded.each = each
+			resources[expanded.id] = expanded
+			order = append(order, expanded.id)
+		}
+	}
+	return resources, order, nil
+}
+
+// forEachItems evaluates the items and key expressions of the resource
+// template in the given context, holding the instance, the cluster and the
+// values, and returns the `each` value of every item.
+func (r *Resource) forEachItems(programs *krocel.ProgramCache, context map[string]interface{}) ([]map[string]interface{}, error) {
+	collection, err := evaluateForEachExpression(programs, r.forEach, context)
+	if err != nil {
+		// The expression was validated against the instance schema, a missing
+		// key means the collection isn't set.
+		if strings.Contains(err.Error(), "no such key") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	items, err := collectionItems(collection)
+	if err != nil {
+		return nil, fmt.Errorf("items expression %s must return a list or a map: %w", r.forEach, err)
+	}
+
+	seen := make(map[string]struct{}, len(items))
+	for _, each := range items {
+		if r.forEachKey != "" {
+			keyContext := maps.Clone(context)
+			keyContext["each"] = each
+			key, err := evaluateForEachExpression(programs, r.forEachKey, keyContext)
+			if err != nil {
+				return nil, err
+			}
+			keyString, ok := key.(string)
+			if !ok || keyString == "" {
+				return nil, fmt.Errorf("key expression %s must return a non-empty string, got %v", r.forEachKey, key)
+			}
+			each["key"] = keyString
+		}
+		key := each["key"].(string)
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("duplicate key %q", key)
+		}
+		seen[key] = struct{}{}
+	}
+	return items, nil
+}
+
+// collectionItems returns the `each` value of the items of the given list or
+// map. List items are keyed by their index, and map entries by their key, in
+// lexical order.
+func collectionItems(collection interface{}) ([]map[string]interface{}, error) {
+	switch c := collection.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		items := make([]map[string]interface{}, 0, len(c))
+		for i, value := range c {
+			items = append(items, map[string]interface{}{"key": strconv.Itoa(i), "value": value})
+		}
+		return items, nil
+	case map[string]interface{}:
+		keys := maps.Keys(c)
+		sort.Strings(keys)
+		items := make([]map[string]interface{}, 0, len(c))
+		for _, key := range keys {
+			items = append(items, map[string]interface{}{"key": key, "value": c[key]})
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("got %T", collection)
+	}
+}
+
+// evaluateForEachExpression evaluates the given expression, which can refer
+// to the instance, the cluster and the item, and returns its value as a Go native type.
+func evaluateForEachExpression(
+	programs *krocel.ProgramCache,
+	expression string,
+	context map[string]interface{},
+) (interface{}, error) {
+	program, err := programs.Program([]string{"schema", "cluster", "values", "locals", "each"}, expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile expression %s: %w", expression, err)
+	}
+	output, _, err := krocel.Evaluate(program, context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression %s: %w", expression, err)
+	}
+	return krocel.GoNativeType(output)
+}
@@ -0,0 +1,220 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_ForEach(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	vpc := generator.WithResource("vpc", map[string]interface{}{
+		"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+		"kind":       "VPC",
+		"metadata": map[string]interface{}{
+			"name": "${schema.spec.name}",
+		},
+		"spec": map[string]interface{}{
+			"cidrBlocks": []interface{}{"192.168.0.0/16"},
+		},
+	}, nil, nil)
+	subnet := map[string]interface{}{
+		"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+		"kind":       "Subnet",
+		"metadata": map[string]interface{}{
+			"name": "${schema.spec.name + '-' + each.key}",
+		},
+		"spec": map[string]interface{}{
+			"cidrBlock": "${each.value}",
+			"vpcID":     "${vpc.status.vpcID}",
+		},
+	}
+	newRGD := func(forEach *v1alpha1.ForEach, opts ...generator.ResourceGraphDefinitionOption) *v1alpha1.ResourceGraphDefinition {
+		opts = append([]generator.ResourceGraphDefinitionOption{
+			generator.WithSchema(
+				"Test", "v1alpha1",
+				map[string]interface{}{
+					"name":    "string",
+					"subnets": "[]string",
+					"zones":   "map[string]string",
+				},
+				nil,
+			),
+			vpc,
+			generator.WithResource("subnets", subnet, []string{"${subnets.status.state == 'available'}"}, nil),
+		}, opts...)
+		rgd := generator.NewResourceGraphDefinition("testrgd", opts...)
+		rgd.Spec.Resources[1].ForEach = forEach
+		return rgd
+	}
+	instance := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kro.run/v1alpha1",
+			"kind":       "Test",
+			"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+			"spec": map[string]interface{}{
+				"name":    "net",
+				"subnets": []interface{}{"10.0.1.0/24", "10.0.2.0/24"},
+				"zones":   map[string]interface{}{"b": "10.0.4.0/24", "a": "10.0.3.0/24"},
+			},
+		}}
+	}
+
+	t.Run("list items", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(&v1alpha1.ForEach{Items: "${schema.spec.subnets}"}))
+		require.NoError(t, err)
+		assert.True(t, g.Resources["subnets"].IsForEach())
+		assert.Equal(t, []string{"vpc"}, g.Resources["subnets"].GetDependencies())
+
+		rt, err := g.NewGraphRuntime(instance())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vpc", "subnets[0]", "subnets[1]"}, rt.TopologicalOrder())
+
+		_, state := rt.GetResource("subnets[1]")
+		assert.Equal(t, runtime.ResourceStateWaitingOnDependencies, state)
+
+		rt.SetResource("vpc", &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"vpcID": "vpc-1"},
+		}})
+		_, err = rt.Synchronize()
+		require.NoError(t, err)
+
+		resource, state := rt.GetResource("subnets[1]")
+		require.Equal(t, runtime.ResourceStateResolved, state)
+		assert.Equal(t, "net-1", resource.GetName())
+		cidr, _, _ := unstructured.NestedString(resource.Object, "spec", "cidrBlock")
+		assert.Equal(t, "10.0.2.0/24", cidr)
+		vpcID, _, _ := unstructured.NestedString(resource.Object, "spec", "vpcID")
+		assert.Equal(t, "vpc-1", vpcID)
+
+		rt.SetResource("subnets[1]", &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"state": "available"},
+		}})
+		ready, _, err := rt.IsResourceReady("subnets[1]")
+		require.NoError(t, err)
+		assert.True(t, ready)
+	})
+
+	t.Run("map entries with key", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(&v1alpha1.ForEach{
+			Items: "${schema.spec.zones}",
+			Key:   "${'zone-' + each.key}",
+		}))
+		require.NoError(t, err)
+
+		rt, err := g.NewGraphRuntime(instance())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vpc", "subnets[zone-a]", "subnets[zone-b]"}, rt.TopologicalOrder())
+	})
+
+	t.Run("unset collection", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(&v1alpha1.ForEach{Items: "${schema.spec.subnets}"}))
+		require.NoError(t, err)
+
+		obj := instance()
+		unstructured.RemoveNestedField(obj.Object, "spec", "subnets")
+		rt, err := g.NewGraphRuntime(obj)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vpc"}, rt.TopologicalOrder())
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(&v1alpha1.ForEach{
+			Items: "${schema.spec.subnets}",
+			Key:   "${schema.spec.name}",
+		}))
+		require.NoError(t, err)
+
+		_, err = g.NewGraphRuntime(instance())
+		assert.ErrorContains(t, err, "duplicate key")
+	})
+
+	tests := []struct {
+		name    string
+		forEach *v1alpha1.ForEach
+		opts    []generator.ResourceGraphDefinitionOption
+		wantErr string
+	}{
+		{
+			name:    "not a standalone expression",
+			forEach: &v1alpha1.ForEach{Items: "subnets-${schema.spec.subnets}"},
+			wantErr: "invalid items expression",
+		},
+		{
+			name:    "items refer to a resource",
+			forEach: &v1alpha1.ForEach{Items: "${vpc.spec.cidrBlocks}"},
+			wantErr: "can only refer to the instance",
+		},
+		{
+			name:    "items are not a collection",
+			forEach: &v1alpha1.ForEach{Items: "${schema.spec.name}"},
+			wantErr: "must return a list or a map",
+		},
+		{
+			name:    "key is not a string",
+			forEach: &v1alpha1.ForEach{Items: "${schema.spec.subnets}", Key: "${size(each.value)}"},
+			wantErr: "can only be of type string",
+		},
+		{
+			name:    "each outside of forEach",
+			forEach: &v1alpha1.ForEach{Items: "${schema.spec.subnets}"},
+			opts: []generator.ResourceGraphDefinitionOption{
+				generator.WithResource("sg", map[string]interface{}{
+					"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+					"kind":       "SecurityGroup",
+					"metadata": map[string]interface{}{
+						"name": "${each.key}",
+					},
+				}, nil, nil),
+			},
+			wantErr: "each",
+		},
+		{
+			name:    "reference to a forEach resource",
+			forEach: &v1alpha1.ForEach{Items: "${schema.spec.subnets}"},
+			opts: []generator.ResourceGraphDefinitionOption{
+				generator.WithResource("sg", map[string]interface{}{
+					"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+					"kind":       "SecurityGroup",
+					"metadata": map[string]interface{}{
+						"name": "${subnets.metadata.name}",
+					},
+				}, nil, nil),
+			},
+			wantErr: "resource sg can't refer to resource subnets",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := builder.NewResourceGraphDefinition(newRGD(tt.forEach, tt.opts...))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
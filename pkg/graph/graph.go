@@ -14,8 +14,15 @@
 package graph
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/kro-run/kro/api/v1alpha1"
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/conversion"
 	"github.com/kro-run/kro/pkg/graph/dag"
 	"github.com/kro-run/kro/pkg/runtime"
 )
@@ -31,20 +38,94 @@ type Graph struct {
 	Resources map[string]*Resource
 	// TopologicalOrder is the topological order of the resources in the resource graph definition.
 	TopologicalOrder []string
+	// SensitiveFields are the paths of the sensitive fields of the instance, e.g
+	// `spec.database.password`. Items of arrays and values of maps are
+	// represented by a `*` segment.
+	SensitiveFields []string
+	// Warnings are the issues found while building the graph that don't prevent
+	// it from being used.
+	Warnings []string
+	// Converter converts instances between the versions of the instance API.
+	// It is nil if the instance API has a single version.
+	Converter *conversion.Converter
+	// Conditions are the conditions of the instances declared by the schema.
+	Conditions []runtime.Condition
+	// RollbackOnFailure rolls the resources of the instances back to their
+	// last ready generation when a new generation doesn't become ready.
+	RollbackOnFailure bool
+	// ResyncPeriod is the interval at which the instances are reconciled even
+	// without changes. It's 0 if the resource graph definition doesn't set
+	// it, so that the default of the controller applies.
+	ResyncPeriod time.Duration
+	// TTLAfterReady and TTLAfterFailure are the times after which the
+	// instances are deleted once ready or failed. They're 0 if the resource
+	// graph definition doesn't set them.
+	TTLAfterReady   time.Duration
+	TTLAfterFailure time.Duration
+	// Quota limits the instances and their resources. It's nil if the
+	// resource graph definition doesn't set it.
+	Quota *v1alpha1.QuotaPolicy
+	// ServiceAccount is the service account impersonated to manage the
+	// resources of every instance. It's nil if the resource graph definition
+	// doesn't set it, so that the default service accounts, if any, apply.
+	ServiceAccount *types.NamespacedName
+	// NamespaceSelector selects the namespaces the instances are reconciled
+	// in. It's nil if the resource graph definition doesn't set it, so that
+	// the instances are reconciled in every namespace.
+	NamespaceSelector labels.Selector
+	// ValuesFrom are the ConfigMaps and Secrets the values variable of the
+	// instances is loaded from, see ValuesObjects and LoadValues.
+	ValuesFrom []v1alpha1.ValuesSource
+
+	// defaults are the defaults of the fields of the instance computed by
+	// an expression, see ApplyDefaults.
+	defaults []instanceDefault
+	// locals are the locals of the resource graph definition, in order, see
+	// evaluateLocals.
+	locals []local
+
+	// programs caches the programs of the expressions evaluated for the
+	// instances. The graph is rebuilt on every change of the resource graph
+	// definition, so the programs are only shared by the instances of a
+	// generation.
+	programs *krocel.ProgramCache
+	// cluster holds the facts of the cluster exposed to the expressions of
+	// the instances. It's nil if a resource shadows the cluster variable.
+	cluster *runtime.Cluster
+	// edges records the origins of the edges of the DAG.
+	edges dependencyEdges
 }
 
 // NewGraphRuntime creates a new runtime resource graph definition from the resource graph definition instance.
-func (rgd *Graph) NewGraphRuntime(newInstance *unstructured.Unstructured) (*runtime.ResourceGraphDefinitionRuntime, error) {
+func (rgd *Graph) NewGraphRuntime(
+	newInstance *unstructured.Unstructured,
+	opts ...RuntimeOption,
+) (*runtime.ResourceGraphDefinitionRuntime, error) {
+	options := &runtimeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	var values map[string]interface{}
+	if len(rgd.ValuesFrom) > 0 {
+		values = valuesVariable(options.values)
+	}
+	locals, err := rgd.evaluateLocals(newInstance, values)
+	if err != nil {
+		return nil, err
+	}
+
 	// we need to copy the resources to the runtime resources, mainly focusing
-	// on the variables and dependencies.
-	resources := make(map[string]runtime.Resource)
-	for name, resource := range rgd.Resources {
-		resources[name] = resource.DeepCopy()
+	// on the variables and dependencies. Resource templates iterating over a
+	// collection are expanded for this instance.
+	resources, topologicalOrder, err := rgd.expandResources(newInstance, values, locals)
+	if err != nil {
+		return nil, err
 	}
 
 	instance := rgd.Instance.DeepCopy()
 	instance.originalObject = newInstance
-	rt, err := runtime.NewResourceGraphDefinitionRuntime(instance, resources, rgd.TopologicalOrder)
+	rt, err := runtime.NewResourceGraphDefinitionRuntime(
+		instance, resources, topologicalOrder, rgd.Conditions, rgd.programs, rgd.cluster, values, locals)
 	if err != nil {
 		return nil, err
 	}
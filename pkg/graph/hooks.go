@@ -0,0 +1,258 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	"golang.org/x/exp/maps"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/parser"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// hookPhases are the phases of the hooks, in the order they run.
+var hookPhases = []runtime.HookPhase{runtime.HookPhasePreApply, runtime.HookPhasePostApply}
+
+// expandHooks returns the resources of the resource graph definition along
+// with the Jobs of their hooks and of the hooks of the instance, which are
+// reconciled like resources and ready once they complete successfully:
+//
+//   - The preApply Jobs of a resource are dependencies of the resource, and
+//     the ones of the instance are dependencies of every resource.
+//   - The postApply Jobs of a resource depend on it, and the ones of the
+//     instance depend on every resource. The resources depending on a
+//     resource also wait for its postApply Jobs, which are returned by
+//     resource id.
+//
+// The given spec isn't modified.
+func expandHooks(spec *v1alpha1.ResourceGraphDefinitionSpec) ([]*v1alpha1.Resource, map[string][]string, error) {
+	hasHooks := spec.Hooks != nil
+	for _, rgResource := range spec.Resources {
+		hasHooks = hasHooks || rgResource.Hooks != nil
+	}
+	if !hasHooks {
+		return spec.Resources, nil, nil
+	}
+
+	var instancePreApply, instancePostApply []*v1alpha1.Resource
+	if spec.Hooks != nil {
+		var err error
+		if instancePreApply, err = hookJobs(spec.Hooks.PreApply); err != nil {
+			return nil, nil, fmt.Errorf("invalid preApply hooks: %w", err)
+		}
+		if instancePostApply, err = hookJobs(spec.Hooks.PostApply); err != nil {
+			return nil, nil, fmt.Errorf("invalid postApply hooks: %w", err)
+		}
+	}
+
+	resources := make([]*v1alpha1.Resource, 0, len(spec.Resources))
+	postApplyJobs := make(map[string][]string)
+	for _, rgResource := range spec.Resources {
+		resource := rgResource.DeepCopy()
+		var preApply, postApply []*v1alpha1.Resource
+		if resource.Hooks != nil {
+			var err error
+			if preApply, err = hookJobs(resource.Hooks.PreApply); err != nil {
+				return nil, nil, fmt.Errorf("resource %s: invalid preApply hooks: %w", resource.ID, err)
+			}
+			if postApply, err = hookJobs(resource.Hooks.PostApply); err != nil {
+				return nil, nil, fmt.Errorf("resource %s: invalid postApply hooks: %w", resource.ID, err)
+			}
+		}
+		// The Jobs run in the cluster of their resource.
+		for _, job := range slices.Concat(preApply, postApply) {
+			job.Target = resource.Target
+		}
+		for _, job := range preApply {
+			resource.DependsOn = append(resource.DependsOn, job.ID)
+		}
+		for _, job := range postApply {
+			job.DependsOn = append(job.DependsOn, resource.ID)
+			postApplyJobs[resource.ID] = append(postApplyJobs[resource.ID], job.ID)
+		}
+		resources = append(resources, preApply...)
+		resources = append(resources, resource)
+		resources = append(resources, postApply...)
+	}
+
+	// Resources iterating over a collection can't be depended on.
+	for _, job := range instancePostApply {
+		for _, resource := range resources {
+			if resource.ForEach == nil {
+				job.DependsOn = append(job.DependsOn, resource.ID)
+			}
+		}
+	}
+	for _, resource := range resources {
+		for _, job := range instancePreApply {
+			resource.DependsOn = append(resource.DependsOn, job.ID)
+		}
+	}
+
+	expanded := append(instancePreApply, resources...)
+	return append(expanded, instancePostApply...), postApplyJobs, nil
+}
+
+// hookJobs returns the resources of the Jobs of the given hooks. The apiVersion
+// and kind of the Jobs can be omitted.
+func hookJobs(hooks []*v1alpha1.Hook) ([]*v1alpha1.Resource, error) {
+	var jobs []*v1alpha1.Resource
+	for _, hook := range hooks {
+		if (hook.Assert == "") == (hook.Job == nil) {
+			return nil, fmt.Errorf("a hook must declare either an assertion or a job")
+		}
+		if hook.Job == nil {
+			continue
+		}
+		if hook.ID == "" {
+			return nil, fmt.Errorf("a job hook must declare an id")
+		}
+
+		template := map[string]interface{}{}
+		if err := yaml.UnmarshalStrict(hook.Job.Raw, &template); err != nil {
+			return nil, fmt.Errorf("hook %s: failed to unmarshal job: %w", hook.ID, err)
+		}
+		if _, ok := template["apiVersion"]; !ok {
+			template["apiVersion"] = "batch/v1"
+		}
+		if _, ok := template["kind"]; !ok {
+			template["kind"] = "Job"
+		}
+		if template["apiVersion"] != "batch/v1" || template["kind"] != "Job" {
+			return nil, fmt.Errorf("hook %s must be a batch/v1 Job, got %v %v", hook.ID, template["apiVersion"], template["kind"])
+		}
+		raw, err := json.Marshal(template)
+		if err != nil {
+			return nil, fmt.Errorf("hook %s: failed to marshal job: %w", hook.ID, err)
+		}
+
+		jobs = append(jobs, &v1alpha1.Resource{
+			ID:        hook.ID,
+			Template:  k8sruntime.RawExtension{Raw: raw},
+			ReadyWhen: []string{fmt.Sprintf("${%s.status.succeeded > 0}", hook.ID)},
+		})
+	}
+	return jobs, nil
+}
+
+// parseResourceHooks parses the assertions of the hooks of the given
+// resource, by phase. External references and resources iterating over a
+// collection can't declare hooks.
+func parseResourceHooks(rgResource *v1alpha1.Resource) (map[runtime.HookPhase][]string, error) {
+	if rgResource.Hooks == nil {
+		return nil, nil
+	}
+	if rgResource.ExternalRef != nil {
+		return nil, fmt.Errorf("can't declare hooks with an externalRef")
+	}
+	if rgResource.ForEach != nil {
+		return nil, fmt.Errorf("can't declare hooks with forEach")
+	}
+	return parseHookAssertions(rgResource.Hooks)
+}
+
+// parseHookAssertions parses the standalone expressions of the assertions of
+// the given hooks, by phase.
+func parseHookAssertions(hooks *v1alpha1.Hooks) (map[runtime.HookPhase][]string, error) {
+	if hooks == nil {
+		return nil, nil
+	}
+	assertions := make(map[runtime.HookPhase][]string)
+	for phase, phaseHooks := range map[runtime.HookPhase][]*v1alpha1.Hook{
+		runtime.HookPhasePreApply:  hooks.PreApply,
+		runtime.HookPhasePostApply: hooks.PostApply,
+	} {
+		for _, hook := range phaseHooks {
+			if hook.Assert == "" {
+				continue
+			}
+			expressions, err := parser.ParseConditionExpressions([]string{hook.Assert})
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s assertion: %w", phase, err)
+			}
+			assertions[phase] = append(assertions[phase], expressions[0])
+		}
+	}
+	return assertions, nil
+}
+
+// buildInstanceHooks parses and validates the assertions of the hooks of the
+// instance. They're checked before any resource is applied, and can only
+// refer to the instance, the cluster and the values.
+func buildInstanceHooks(
+	hooks *v1alpha1.Hooks,
+	names []string,
+	instance *Resource,
+) (map[runtime.HookPhase][]string, error) {
+	assertions, err := parseHookAssertions(hooks)
+	if err != nil {
+		return nil, err
+	}
+	if len(assertions[runtime.HookPhasePostApply]) > 0 {
+		return nil, fmt.Errorf("postApply hooks of the instance can only be jobs")
+	}
+	if len(assertions[runtime.HookPhasePreApply]) == 0 {
+		return nil, nil
+	}
+
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(names))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	context := emulatedVariables(names, instance)
+	for _, expression := range assertions[runtime.HookPhasePreApply] {
+		output, err := ensureExpression(env, expression, names, context)
+		if err != nil {
+			return nil, fmt.Errorf("preApply assertion %s can only refer to the instance: %w", expression, err)
+		}
+		if !krocel.IsBoolType(output) {
+			return nil, fmt.Errorf("output of preApply assertion %s can only be of type bool", expression)
+		}
+	}
+	return assertions, nil
+}
+
+// ensureHookAssertions validates the assertions of the hooks of the resource
+// against the emulated resources. The preApply assertions are checked before
+// the resource is applied, and can't refer to it, unlike the postApply ones.
+func ensureHookAssertions(env *cel.Env, context map[string]*Resource, resource *Resource) error {
+	for _, phase := range hookPhases {
+		phaseContext := context
+		if phase == runtime.HookPhasePostApply {
+			phaseContext = maps.Clone(context)
+			phaseContext[resource.id] = resource
+		}
+		for _, expression := range resource.hookAssertions[phase] {
+			field := "hooks." + string(phase)
+			output, err := ensureExpression(env, expression, []string{resource.id}, phaseContext)
+			if err != nil {
+				return fmt.Errorf("failed to dry-run %s assertion: %w",
+					phase, krocel.NewExpressionError(resource.id, field, expression, err))
+			}
+			if !krocel.IsBoolType(output) {
+				return krocel.NewExpressionError(resource.id, field, expression,
+					fmt.Errorf("output of %s assertion %s can only be of type bool", phase, expression))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_Hooks(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(vpcHooks, instanceHooks *v1alpha1.Hooks) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema(
+				"Test", "v1alpha1",
+				map[string]interface{}{"name": "string", "migrate": "boolean"},
+				nil,
+			),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+			}, nil, nil),
+			generator.WithResource("subnet", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "Subnet",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+				"spec": map[string]interface{}{
+					"vpcID": "${vpc.status.vpcID}",
+				},
+			}, nil, nil),
+		)
+		rgd.Spec.Resources[0].Hooks = vpcHooks
+		rgd.Spec.Hooks = instanceHooks
+		return rgd
+	}
+	job := func(name string) *k8sruntime.RawExtension {
+		return &k8sruntime.RawExtension{Raw: []byte(`{"metadata":{"name":"` + name + `"},` +
+			`"spec":{"template":{"spec":{"restartPolicy":"Never","containers":[{"name":"migrate","image":"migrate"}]}}}}`)}
+	}
+
+	t.Run("orders jobs around their resource", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(&v1alpha1.Hooks{
+			PreApply:  []*v1alpha1.Hook{{ID: "migrate", Job: job("${schema.spec.name}-migrate")}},
+			PostApply: []*v1alpha1.Hook{{ID: "smoketest", Job: job("${schema.spec.name}-smoketest")}},
+		}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"migrate", "vpc", "smoketest", "subnet"}, g.TopologicalOrder)
+		assert.Equal(t, []string{"migrate"}, g.Resources["vpc"].GetDependencies())
+		assert.ElementsMatch(t, []string{"vpc", "smoketest"}, g.Resources["subnet"].GetDependencies())
+		assert.Equal(t, "batch/v1", g.Resources["migrate"].Unstructured().GetAPIVersion())
+		assert.Equal(t, []string{"migrate.status.succeeded > 0"}, g.Resources["migrate"].GetReadyWhenExpressions())
+	})
+
+	t.Run("orders jobs of the instance around every resource", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(nil, &v1alpha1.Hooks{
+			PreApply: []*v1alpha1.Hook{
+				{Assert: "${schema.spec.migrate}"},
+				{ID: "migrate", Job: job("${schema.spec.name}-migrate")},
+			},
+			PostApply: []*v1alpha1.Hook{{ID: "smoketest", Job: job("${schema.spec.name}-smoketest")}},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"migrate", "vpc", "subnet", "smoketest"}, g.TopologicalOrder)
+		assert.ElementsMatch(t, []string{"migrate", "vpc"}, g.Resources["subnet"].GetDependencies())
+		assert.ElementsMatch(t, []string{"vpc", "subnet"}, g.Resources["smoketest"].GetDependencies())
+		assert.Equal(t, []string{"schema.spec.migrate"}, g.Instance.GetHookAssertions(runtime.HookPhasePreApply))
+	})
+
+	t.Run("depends on the resources of assertions", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD(&v1alpha1.Hooks{
+			PostApply: []*v1alpha1.Hook{{Assert: "${vpc.status.vpcID != ''}"}},
+		}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vpc.status.vpcID != ''"}, g.Resources["vpc"].GetHookAssertions(runtime.HookPhasePostApply))
+	})
+
+	tests := []struct {
+		name          string
+		vpcHooks      *v1alpha1.Hooks
+		instanceHooks *v1alpha1.Hooks
+		wantErr       string
+	}{
+		{
+			name:     "hook without assertion or job",
+			vpcHooks: &v1alpha1.Hooks{PreApply: []*v1alpha1.Hook{{ID: "migrate"}}},
+			wantErr:  "a hook must declare either an assertion or a job",
+		},
+		{
+			name:     "job without id",
+			vpcHooks: &v1alpha1.Hooks{PreApply: []*v1alpha1.Hook{{Job: job("migrate")}}},
+			wantErr:  "a job hook must declare an id",
+		},
+		{
+			name: "job of another kind",
+			vpcHooks: &v1alpha1.Hooks{PreApply: []*v1alpha1.Hook{{
+				ID:  "migrate",
+				Job: &k8sruntime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Pod"}`)},
+			}}},
+			wantErr: "hook migrate must be a batch/v1 Job",
+		},
+		{
+			name:     "preApply assertion on its own resource",
+			vpcHooks: &v1alpha1.Hooks{PreApply: []*v1alpha1.Hook{{Assert: "${vpc.status.vpcID != ''}"}}},
+			wantErr:  "failed to dry-run preApply assertion",
+		},
+		{
+			name:     "assertion not of type bool",
+			vpcHooks: &v1alpha1.Hooks{PostApply: []*v1alpha1.Hook{{Assert: "${vpc.status.vpcID}"}}},
+			wantErr:  "can only be of type bool",
+		},
+		{
+			name:          "postApply assertion on the instance",
+			instanceHooks: &v1alpha1.Hooks{PostApply: []*v1alpha1.Hook{{Assert: "${vpc.status.vpcID != ''}"}}},
+			wantErr:       "postApply hooks of the instance can only be jobs",
+		},
+		{
+			name:          "preApply assertion of the instance on a resource",
+			instanceHooks: &v1alpha1.Hooks{PreApply: []*v1alpha1.Hook{{Assert: "${vpc.status.vpcID != ''}"}}},
+			wantErr:       "can only refer to the instance",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := builder.NewResourceGraphDefinition(newRGD(tt.vpcHooks, tt.instanceHooks))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_IncludeWhenResources(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	schema := generator.WithSchema(
+		"Test", "v1alpha1",
+		map[string]interface{}{"name": "string"},
+		nil,
+	)
+	vpc := generator.WithResource("vpc", map[string]interface{}{
+		"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+		"kind":       "VPC",
+		"metadata": map[string]interface{}{
+			"name": "${schema.spec.name}",
+		},
+	}, nil, nil)
+	subnet := func(includeWhen ...string) generator.ResourceGraphDefinitionOption {
+		return generator.WithResource("subnet", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "Subnet",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}",
+			},
+		}, nil, includeWhen)
+	}
+
+	t.Run("sequenced after the resources they refer to", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+			schema,
+			subnet("${vpc.status.state == 'available'}"),
+			vpc,
+		))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vpc", "subnet"}, g.TopologicalOrder)
+		assert.Equal(t, []string{"vpc"}, g.Resources["subnet"].GetDependencies())
+
+		rt, err := g.NewGraphRuntime(&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kro.run/v1alpha1",
+			"kind":       "Test",
+			"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+			"spec":       map[string]interface{}{"name": "test"},
+		}})
+		require.NoError(t, err)
+
+		// The condition can't be evaluated until the VPC is observed.
+		_, err = rt.WantToCreateResource("subnet")
+		var exprErr *krocel.ExpressionError
+		assert.True(t, errors.As(err, &exprErr))
+
+		rt.SetResource("vpc", &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"state": "pending"},
+		}})
+		want, _ := rt.WantToCreateResource("subnet")
+		assert.False(t, want)
+
+		rt.SetResource("vpc", &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"state": "available"},
+		}})
+		want, err = rt.WantToCreateResource("subnet")
+		require.NoError(t, err)
+		assert.True(t, want)
+	})
+
+	t.Run("referring to the resource itself", func(t *testing.T) {
+		_, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+			schema,
+			subnet("${subnet.status.subnetID != ''}"),
+			vpc,
+		))
+		assert.Error(t, err)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			schema,
+			subnet("${vpc.status.state == 'available'}"),
+			vpc,
+		)
+		rgd.Spec.Resources[1].IncludeWhen = []string{"${subnet.status.subnetID != ''}"}
+		_, err := builder.NewResourceGraphDefinition(rgd)
+		assert.ErrorContains(t, err, "cycle")
+	})
+}
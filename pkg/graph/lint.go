@@ -0,0 +1,319 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/cel/ast"
+	"github.com/kro-run/kro/pkg/graph/parser"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+const (
+	// maxNameLength is the maximum length of the names of the kinds whose
+	// names are DNS labels, e.g Services, and of label values.
+	maxNameLength = 63
+	// estimatedExpressionLength is the length assumed for the value of an
+	// expression in a name, e.g the name of the instance.
+	estimatedExpressionLength = 20
+)
+
+// lintedExpression is an expression of the resource graph definition, with
+// the field declaring it, e.g `deployment.spec.replicas` or
+// `service.includeWhen[0]`.
+type lintedExpression struct {
+	field      string
+	expression string
+	// schemaPaths are the paths of the instance accessed by the expression,
+	// without the `schema.` prefix, e.g `spec.replicas`.
+	schemaPaths []string
+}
+
+// lintWarnings returns the quality issues of the resource graph definition,
+// which don't prevent it from working but are likely mistakes:
+//   - fields of the instance spec no expression uses.
+//   - resources other resources depend on, which have a status but no
+//     readyWhen expression, so that they don't wait for them to be ready.
+//   - expressions referring to optional fields of the instance spec without a
+//     default, which fail while the fields aren't set. Expressions using has()
+//     or optional field selection are assumed to handle them.
+//   - names of resources likely to exceed 63 characters.
+//
+// Linting never fails: the checks of the expressions are skipped if they
+// can't all be inspected, as the fields they use would be reported unused.
+func lintWarnings(
+	instance *Resource,
+	resources map[string]*Resource,
+	variables []string,
+	conditions []runtime.Condition,
+	defaults []instanceDefault,
+	locals []local,
+) []string {
+	resourceIDs := make([]string, 0, len(resources))
+	for id := range resources {
+		resourceIDs = append(resourceIDs, id)
+	}
+	sort.Strings(resourceIDs)
+
+	var warnings []string
+	if expressions, ok := lintedExpressions(instance, resources, resourceIDs, variables, conditions, defaults, locals); ok {
+		specSchema := instanceSpecSchema(instance)
+		warnings = append(warnings, unusedFieldWarnings(specSchema, "spec", expressions)...)
+		warnings = append(warnings, optionalFieldWarnings(specSchema, defaults, expressions)...)
+	}
+	warnings = append(warnings, readinessWarnings(resources, resourceIDs)...)
+	warnings = append(warnings, nameLengthWarnings(resources, resourceIDs)...)
+	return warnings
+}
+
+// lintedExpressions returns every expression of the resource graph
+// definition, with the paths of the instance they access, and whether they
+// could all be inspected.
+func lintedExpressions(
+	instance *Resource,
+	resources map[string]*Resource,
+	resourceIDs []string,
+	variables []string,
+	conditions []runtime.Condition,
+	defaults []instanceDefault,
+	locals []local,
+) ([]lintedExpression, bool) {
+	var expressions []lintedExpression
+	add := func(field string, resourceExpressions ...string) {
+		for _, expression := range resourceExpressions {
+			if expression != "" {
+				expressions = append(expressions, lintedExpression{field: field, expression: expression})
+			}
+		}
+	}
+	addResource := func(id string, resource *Resource) {
+		for _, v := range resource.variables {
+			add(id+"."+v.Path, v.Expressions...)
+		}
+		for i, expression := range resource.includeWhenExpressions {
+			add(fmt.Sprintf("%s.includeWhen[%d]", id, i), expression)
+		}
+		for i, expression := range resource.readyWhenExpressions {
+			add(fmt.Sprintf("%s.readyWhen[%d]", id, i), expression)
+		}
+		add(id+".forEach.items", resource.forEach)
+		add(id+".forEach.key", resource.forEachKey)
+		for _, phase := range hookPhases {
+			add(fmt.Sprintf("%s.hooks.%s", id, phase), resource.hookAssertions[phase]...)
+		}
+	}
+	for _, id := range resourceIDs {
+		addResource(id, resources[id])
+	}
+	addResource("instance", instance)
+	for _, condition := range conditions {
+		add("conditions."+condition.Type, condition.Expression)
+	}
+	for _, d := range defaults {
+		add("defaults."+strings.Join(d.path, "."), d.expression)
+	}
+	for _, l := range locals {
+		add("locals."+l.name, l.expression)
+	}
+
+	names := append(slices.Clone(resourceIDs), variables...)
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(names))
+	if err != nil {
+		return nil, false
+	}
+	inspector := ast.NewInspectorWithEnv(env, names, nil)
+	for i, e := range expressions {
+		inspection, err := inspector.Inspect(e.expression)
+		if err != nil {
+			return nil, false
+		}
+		for _, dependency := range inspection.ResourceDependencies {
+			if dependency.ID == "schema" {
+				expressions[i].schemaPaths = append(expressions[i].schemaPaths,
+					strings.TrimPrefix(strings.TrimPrefix(dependency.Path, "schema"), "."))
+			}
+		}
+	}
+	return expressions, true
+}
+
+// unusedFieldWarnings returns a warning for every field of the given schema,
+// prefixed by the given path, no expression uses. Only the top-most unused
+// fields are reported, and the fields of arrays and maps aren't checked, as
+// expressions can't address their items by path.
+func unusedFieldWarnings(fieldSchema *extv1.JSONSchemaProps, path string, expressions []lintedExpression) []string {
+	if fieldSchema == nil {
+		return nil
+	}
+	names := make([]string, 0, len(fieldSchema.Properties))
+	for name := range fieldSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		field := path + "." + name
+		used, whole := fieldUsage(field, expressions)
+		switch {
+		case !used:
+			warnings = append(warnings, fmt.Sprintf("field %s of the schema isn't used by any expression", field))
+		case !whole:
+			property := fieldSchema.Properties[name]
+			warnings = append(warnings, unusedFieldWarnings(&property, field, expressions)...)
+		}
+	}
+	return warnings
+}
+
+// fieldUsage returns whether the given field of the instance is used by an
+// expression, and whether it's used as a whole, including all its fields.
+func fieldUsage(field string, expressions []lintedExpression) (used, whole bool) {
+	for _, e := range expressions {
+		for _, path := range e.schemaPaths {
+			if path == field || strings.HasPrefix(field, path+".") {
+				return true, true
+			}
+			if strings.HasPrefix(path, field+".") {
+				used = true
+			}
+		}
+	}
+	return used, false
+}
+
+// optionalFieldWarnings returns a warning for every expression referring to
+// an optional field of the given spec schema that has no default.
+func optionalFieldWarnings(
+	specSchema *extv1.JSONSchemaProps,
+	defaults []instanceDefault,
+	expressions []lintedExpression,
+) []string {
+	if specSchema == nil {
+		return nil
+	}
+	defaulted := make(map[string]bool, len(defaults))
+	for _, d := range defaults {
+		defaulted[strings.Join(d.path, ".")] = true
+	}
+
+	var warnings []string
+	for _, e := range expressions {
+		if strings.Contains(e.expression, "has(") || strings.Contains(e.expression, ".?") {
+			continue
+		}
+		reported := map[string]bool{}
+		for _, path := range e.schemaPaths {
+			field, ok := optionalField(specSchema, defaulted, path)
+			if !ok || reported[field] {
+				continue
+			}
+			reported[field] = true
+			warnings = append(warnings, fmt.Sprintf(
+				"%s refers to optional field %s, which has no default", e.field, field,
+			))
+		}
+	}
+	return warnings
+}
+
+// optionalField returns the first optional field without a default along the
+// given path of the instance, e.g `spec.storage` for `spec.storage.size`.
+func optionalField(specSchema *extv1.JSONSchemaProps, defaulted map[string]bool, path string) (string, bool) {
+	segments := strings.Split(path, ".")
+	if len(segments) < 2 || segments[0] != "spec" {
+		return "", false
+	}
+	current := specSchema
+	field := segments[0]
+	for _, segment := range segments[1:] {
+		property, ok := current.Properties[segment]
+		if !ok {
+			return "", false
+		}
+		field += "." + segment
+		if !slices.Contains(current.Required, segment) && property.Default == nil && !defaulted[field] {
+			return field, true
+		}
+		current = &property
+	}
+	return "", false
+}
+
+// readinessWarnings returns a warning for every resource without a readyWhen
+// expression other resources depend on. Resources without a status, e.g
+// ConfigMaps, and external references are ready once they exist.
+func readinessWarnings(resources map[string]*Resource, resourceIDs []string) []string {
+	var warnings []string
+	for _, id := range resourceIDs {
+		resource := resources[id]
+		if len(resource.readyWhenExpressions) > 0 || resource.external || resource.schema == nil {
+			continue
+		}
+		if _, ok := resource.schema.Properties["status"]; !ok {
+			continue
+		}
+		var dependents []string
+		for _, other := range resourceIDs {
+			if slices.Contains(resources[other].dependencies, id) {
+				dependents = append(dependents, other)
+			}
+		}
+		if len(dependents) > 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"resource %s has no readyWhen expression, the resources depending on it (%s) don't wait for it to be ready",
+				id, strings.Join(dependents, ", "),
+			))
+		}
+	}
+	return warnings
+}
+
+// nameLengthWarnings returns a warning for every resource whose name is likely
+// to exceed 63 characters, assuming each expression of the name evaluates to
+// 20 characters.
+func nameLengthWarnings(resources map[string]*Resource, resourceIDs []string) []string {
+	var warnings []string
+	for _, id := range resourceIDs {
+		resource := resources[id]
+		if resource.external || resource.originalObject == nil {
+			continue
+		}
+		name := resource.originalObject.GetName()
+		length := len(name)
+		descriptors, err := parser.ParseSchemalessResource(map[string]interface{}{"name": name})
+		if err != nil {
+			continue
+		}
+		for _, descriptor := range descriptors {
+			for _, expression := range descriptor.Expressions {
+				length += estimatedExpressionLength - len("${"+expression+"}")
+			}
+		}
+		if length > maxNameLength {
+			warnings = append(warnings, fmt.Sprintf(
+				"name %s of resource %s is likely to exceed %d characters, the maximum length of the names of many kinds",
+				name, id, maxNameLength,
+			))
+		}
+	}
+	return warnings
+}
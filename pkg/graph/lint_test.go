@@ -0,0 +1,216 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_Lint(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	rgd := generator.NewResourceGraphDefinition("testrgd",
+		generator.WithSchema(
+			"Test", "v1alpha1",
+			map[string]interface{}{
+				"name":    "string | required=true",
+				"cidr":    "string | default=10.0.0.0/16",
+				"region":  "string",
+				"zone":    "string",
+				"comment": "string",
+			},
+			map[string]interface{}{
+				"subnetID": "${subnet.status.subnetID}",
+			},
+		),
+		generator.WithResource("vpc", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "VPC",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}-${schema.spec.region}-network-of-the-application",
+			},
+			"spec": map[string]interface{}{
+				"cidrBlocks": []interface{}{"${schema.spec.cidr}"},
+			},
+		}, nil, nil),
+		generator.WithResource("subnet", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "Subnet",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}",
+			},
+			"spec": map[string]interface{}{
+				"cidrBlock": "${has(schema.spec.zone) ? schema.spec.zone : schema.spec.cidr}",
+				"vpcID":     "${vpc.status.vpcID}",
+			},
+		}, []string{"${subnet.status.state == 'available'}"}, nil),
+	)
+
+	g, err := builder.NewResourceGraphDefinition(rgd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"field spec.comment of the schema isn't used by any expression",
+		"vpc.metadata.name refers to optional field spec.region, which has no default",
+		"resource vpc has no readyWhen expression, the resources depending on it (subnet) don't wait for it to be ready",
+		"name ${schema.spec.name}-${schema.spec.region}-network-of-the-application of resource vpc " +
+			"is likely to exceed 63 characters, the maximum length of the names of many kinds",
+	}, g.Warnings)
+}
+
+func TestGraphBuilder_LintNeverFails(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	vpc := func(name string) generator.ResourceGraphDefinitionOption {
+		return generator.WithResource("vpc", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "VPC",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"cidrBlocks": []interface{}{"${schema.spec.cidr}"},
+			},
+		}, nil, nil)
+	}
+	subnet := generator.WithResource("subnet", map[string]interface{}{
+		"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+		"kind":       "Subnet",
+		"metadata": map[string]interface{}{
+			"name": "${schema.spec.name}",
+		},
+		"spec": map[string]interface{}{
+			"cidrBlock": "${schema.spec.cidr}",
+			"vpcID":     "${vpc.status.vpcID}",
+		},
+	}, []string{"${subnet.status.state == 'available'}"}, nil)
+
+	tests := []struct {
+		name        string
+		spec        map[string]interface{}
+		resources   []generator.ResourceGraphDefinitionOption
+		wantWarning string
+	}{
+		{
+			name:        "unused field",
+			spec:        map[string]interface{}{"name": "string | required=true", "cidr": "string | required=true", "comment": "string"},
+			resources:   []generator.ResourceGraphDefinitionOption{vpc("${schema.spec.name}")},
+			wantWarning: "field spec.comment of the schema isn't used by any expression",
+		},
+		{
+			name:        "optional field without default",
+			spec:        map[string]interface{}{"name": "string", "cidr": "string | required=true"},
+			resources:   []generator.ResourceGraphDefinitionOption{vpc("${schema.spec.name}")},
+			wantWarning: "vpc.metadata.name refers to optional field spec.name, which has no default",
+		},
+		{
+			name:        "dependency without readyWhen",
+			spec:        map[string]interface{}{"name": "string | required=true", "cidr": "string | required=true"},
+			resources:   []generator.ResourceGraphDefinitionOption{vpc("${schema.spec.name}"), subnet},
+			wantWarning: "resource vpc has no readyWhen expression, the resources depending on it (subnet) don't wait for it to be ready",
+		},
+		{
+			name:      "long name",
+			spec:      map[string]interface{}{"name": "string | required=true", "cidr": "string | required=true"},
+			resources: []generator.ResourceGraphDefinitionOption{vpc("${schema.spec.name}-${schema.spec.name}-network-of-the-application")},
+			wantWarning: "name ${schema.spec.name}-${schema.spec.name}-network-of-the-application of resource vpc " +
+				"is likely to exceed 63 characters, the maximum length of the names of many kinds",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]generator.ResourceGraphDefinitionOption{
+				generator.WithSchema("Test", "v1alpha1", tt.spec, nil),
+			}, tt.resources...)
+			rgd := generator.NewResourceGraphDefinition("testrgd", options...)
+
+			// Lint warnings are reported along with the graph, never as a
+			// build error.
+			g, err := builder.NewResourceGraphDefinition(rgd)
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.wantWarning}, g.Warnings)
+		})
+	}
+}
+
+func TestUnusedFieldWarnings(t *testing.T) {
+	fieldSchema := &extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"name": {Type: "string"},
+			"database": {
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"user":     {Type: "string"},
+					"password": {Type: "string"},
+				},
+			},
+			"network": {
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"cidr": {Type: "string"},
+				},
+			},
+			"tags": {Type: "array", Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "string"}}},
+		},
+	}
+
+	expressions := []lintedExpression{
+		{schemaPaths: []string{"spec.name", "spec.database.user"}},
+		{schemaPaths: []string{"spec.network"}},
+	}
+	assert.Equal(t, []string{
+		"field spec.database.password of the schema isn't used by any expression",
+		"field spec.tags of the schema isn't used by any expression",
+	}, unusedFieldWarnings(fieldSchema, "spec", expressions))
+
+	// Using the whole spec uses all its fields.
+	assert.Empty(t, unusedFieldWarnings(fieldSchema, "spec", []lintedExpression{{schemaPaths: []string{"spec"}}}))
+}
+
+func TestLintWarnings_UninspectableExpression(t *testing.T) {
+	instance := &Resource{crd: &extv1.CustomResourceDefinition{Spec: extv1.CustomResourceDefinitionSpec{
+		Versions: []extv1.CustomResourceDefinitionVersion{{
+			Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"spec": {Type: "object", Properties: map[string]extv1.JSONSchemaProps{"name": {Type: "string"}}},
+				},
+			}},
+		}},
+	}}}
+	conditions := []runtime.Condition{{Type: "Valid", Expression: "schema.spec.name =="}}
+
+	// The checks of the expressions are skipped rather than failing the
+	// build, or reporting the fields of the expression unused.
+	assert.Empty(t, lintWarnings(instance, map[string]*Resource{}, nil, conditions, nil, nil))
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/parser"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// local is a named expression of the resource graph definition, computed
+// once per reconciliation of an instance.
+type local struct {
+	// name is the name of the local in the locals variable.
+	name string
+	// expression is the standalone expression computing the local, without
+	// the surrounding `${}`.
+	expression string
+}
+
+// buildLocals parses and validates the locals of the resource graph
+// definition, in order. A local can refer to the instance, the cluster, the
+// values and the locals declared before it. The outputs of their dry-runs are
+// recorded on the instance, to validate the expressions referring to them.
+func buildLocals(specLocals []v1alpha1.Local, names []string, instance *Resource) ([]local, error) {
+	if len(specLocals) == 0 {
+		return nil, nil
+	}
+
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(names))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	instance.emulatedLocals = map[string]interface{}{}
+	locals := make([]local, 0, len(specLocals))
+	for _, specLocal := range specLocals {
+		if _, ok := instance.emulatedLocals[specLocal.Name]; ok {
+			return nil, fmt.Errorf("local %s is declared twice", specLocal.Name)
+		}
+		expressions, err := parser.ParseConditionExpressions([]string{specLocal.Expression})
+		if err != nil {
+			return nil, fmt.Errorf("invalid local %s: %w", specLocal.Name, err)
+		}
+		// The emulated locals only hold the locals declared so far.
+		output, err := ensureExpression(env, expressions[0], names, emulatedVariables(names, instance))
+		if err != nil {
+			return nil, fmt.Errorf("local %s can only refer to the instance, the cluster, the values and the previous locals: %w",
+				specLocal.Name, err)
+		}
+		value, err := krocel.GoNativeType(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert local %s: %w", specLocal.Name, err)
+		}
+		instance.emulatedLocals[specLocal.Name] = value
+		locals = append(locals, local{name: specLocal.Name, expression: expressions[0]})
+	}
+	return locals, nil
+}
+
+// emulatedLocals returns the emulated locals variable, holding the outputs of
+// the dry-runs of the locals against the emulated instance.
+func emulatedLocals(instance *Resource) *Resource {
+	return &Resource{emulatedObject: &unstructured.Unstructured{Object: instance.emulatedLocals}}
+}
+
+// evaluateLocals evaluates the locals of the graph for the given instance, in
+// order. It returns nil if the resource graph definition declares none.
+func (rgd *Graph) evaluateLocals(
+	instance *unstructured.Unstructured,
+	values map[string]interface{},
+) (map[string]interface{}, error) {
+	if len(rgd.locals) == 0 {
+		return nil, nil
+	}
+
+	locals := make(map[string]interface{}, len(rgd.locals))
+	context := map[string]interface{}{
+		"schema": runtime.SchemaVariable(instance),
+		"locals": locals,
+	}
+	if rgd.cluster != nil {
+		context["cluster"] = rgd.cluster.Variable()
+	}
+	if values != nil {
+		context["values"] = values
+	}
+	for _, l := range rgd.locals {
+		program, err := rgd.programs.Program([]string{"schema", "cluster", "values", "locals"}, l.expression)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile local %s: %w", l.name, err)
+		}
+		output, _, err := krocel.Evaluate(program, context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate local %s: %w", l.name, err)
+		}
+		locals[l.name], err = krocel.GoNativeType(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert local %s: %w", l.name, err)
+		}
+	}
+	return locals, nil
+}
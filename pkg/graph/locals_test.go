@@ -0,0 +1,162 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_Locals(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	schema := generator.WithSchema("Test", "v1alpha1", map[string]interface{}{
+		"name": "string",
+		"env":  "string | default=dev",
+		"apps": "[]string",
+	}, nil)
+	withLocals := func(locals ...v1alpha1.Local) generator.ResourceGraphDefinitionOption {
+		return func(rgd *v1alpha1.ResourceGraphDefinition) {
+			rgd.Spec.Locals = locals
+		}
+	}
+	secret := func(id, name string, includeWhen ...string) generator.ResourceGraphDefinitionOption {
+		return generator.WithResource(id, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":   name,
+				"labels": "${locals.commonLabels}",
+			},
+		}, nil, includeWhen)
+	}
+	locals := withLocals(
+		v1alpha1.Local{Name: "fullName", Expression: "${schema.spec.name + '-' + schema.spec.env}"},
+		v1alpha1.Local{Name: "commonLabels", Expression: "${ {'app.kubernetes.io/name': locals.fullName} }"},
+	)
+
+	t.Run("locals shared by the resources", func(t *testing.T) {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			schema,
+			locals,
+			secret("credentials", "${locals.fullName}"),
+			secret("apps", "${locals.fullName + '-' + each.value}", "${locals.fullName != ''}"),
+		)
+		rgd.Spec.Resources[1].ForEach = &v1alpha1.ForEach{Items: "${schema.spec.apps}"}
+		g, err := builder.NewResourceGraphDefinition(rgd)
+		require.NoError(t, err)
+		assert.Empty(t, g.Resources["credentials"].GetDependencies())
+
+		rt, err := g.NewGraphRuntime(&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kro.run/v1alpha1",
+			"kind":       "Test",
+			"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+			"spec": map[string]interface{}{
+				"name": "shop",
+				"env":  "prod",
+				"apps": []interface{}{"web"},
+			},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"credentials", "apps[0]"}, rt.TopologicalOrder())
+
+		for id, name := range map[string]string{"credentials": "shop-prod", "apps[0]": "shop-prod-web"} {
+			resource, state := rt.GetResource(id)
+			require.Equal(t, runtime.ResourceStateResolved, state)
+			assert.Equal(t, name, resource.GetName())
+			assert.Equal(t, map[string]string{"app.kubernetes.io/name": "shop-prod"}, resource.GetLabels())
+		}
+		want, err := rt.WantToCreateResource("apps[0]")
+		require.NoError(t, err)
+		assert.True(t, want)
+	})
+
+	for _, tt := range []struct {
+		name    string
+		opts    []generator.ResourceGraphDefinitionOption
+		wantErr string
+	}{
+		{
+			name: "local referring to a later local",
+			opts: []generator.ResourceGraphDefinitionOption{withLocals(
+				v1alpha1.Local{Name: "commonLabels", Expression: "${ {'app': locals.fullName} }"},
+				v1alpha1.Local{Name: "fullName", Expression: "${schema.spec.name}"},
+			)},
+			wantErr: "local commonLabels can only refer to the instance, the cluster, the values and the previous locals",
+		},
+		{
+			name: "local referring to a resource",
+			opts: []generator.ResourceGraphDefinitionOption{withLocals(
+				v1alpha1.Local{Name: "fullName", Expression: "${credentials.metadata.name}"},
+				v1alpha1.Local{Name: "commonLabels", Expression: "${ {} }"},
+			)},
+			wantErr: "local fullName can only refer to",
+		},
+		{
+			name: "local declared twice",
+			opts: []generator.ResourceGraphDefinitionOption{withLocals(
+				v1alpha1.Local{Name: "fullName", Expression: "${schema.spec.name}"},
+				v1alpha1.Local{Name: "fullName", Expression: "${schema.spec.env}"},
+				v1alpha1.Local{Name: "commonLabels", Expression: "${ {} }"},
+			)},
+			wantErr: "local fullName is declared twice",
+		},
+		{
+			name: "local that isn't a standalone expression",
+			opts: []generator.ResourceGraphDefinitionOption{withLocals(
+				v1alpha1.Local{Name: "fullName", Expression: "${schema.spec.name}-db"},
+				v1alpha1.Local{Name: "commonLabels", Expression: "${ {} }"},
+			)},
+			wantErr: "invalid local fullName",
+		},
+		{
+			name: "default referring to a local",
+			opts: []generator.ResourceGraphDefinitionOption{
+				generator.WithSchema("Test", "v1alpha1", map[string]interface{}{
+					"name": "string | default=${locals.fullName}",
+					"env":  "string",
+					"apps": "[]string",
+				}, nil),
+				locals,
+			},
+			wantErr: "default of spec.name can only refer to the instance, the cluster and the values",
+		},
+		{
+			name:    "resource with the id locals",
+			opts:    []generator.ResourceGraphDefinitionOption{locals, secret("locals", "${schema.spec.name}")},
+			wantErr: "resource id locals is reserved when locals are declared",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]generator.ResourceGraphDefinitionOption{schema}, tt.opts...)
+			opts = append(opts, secret("credentials", "${schema.spec.name}"))
+			_, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd", opts...))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -202,6 +202,11 @@ func parseObject(field map[string]interface{}, schema *spec.Schema, path string,
 			return nil, fmt.Errorf("error getting field schema for path %s: %v", path+"."+fieldName, err)
 		}
 		fieldPath := joinPathAndFieldName(path, fieldName)
+		keyExpressions, err := parseKey(fieldName, fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		expressionsFields = append(expressionsFields, keyExpressions...)
 		fieldExpressions, err := parseResource(value, fieldSchema, fieldPath)
 		if err != nil {
 			return nil, err
@@ -267,6 +272,30 @@ func parseString(field string, schema *spec.Schema, path string, expectedTypes [
 	return nil, nil
 }
 
+// parseKey extracts the CEL expressions of a map key, e.g a label key derived
+// from the instance. Keys are resolved to strings.
+func parseKey(key string, path string) ([]variable.FieldDescriptor, error) {
+	expressions, err := extractExpressions(key)
+	if err != nil || len(expressions) == 0 {
+		return nil, err
+	}
+	// Paths quote the keys without escaping them.
+	if strings.Contains(key, `"`) {
+		return nil, fmt.Errorf("key %s at path %s can't contain double quotes, use single quoted strings", key, path)
+	}
+	standalone, err := isStandaloneExpression(key)
+	if err != nil {
+		return nil, err
+	}
+	return []variable.FieldDescriptor{{
+		Expressions:          expressions,
+		ExpectedTypes:        []string{"string"},
+		Path:                 path,
+		StandaloneExpression: standalone,
+		Key:                  true,
+	}}, nil
+}
+
 func parseScalarTypes(field interface{}, _ *spec.Schema, path string, expectedTypes []string) ([]variable.FieldDescriptor, error) {
 	// perform type checks for scalar types
 	switch {
@@ -344,10 +373,11 @@ func isInteger(v interface{}) bool {
 }
 
 // joinPathAndField appends a field name to a path. If the fieldName contains
-// a dot or is empty, the path will be appended using ["fieldName"] instead of
-// .fieldName to avoid ambiguity and simplify parsing back the path.
+// a dot or a bracket, or is empty, the path will be appended using
+// ["fieldName"] instead of .fieldName to avoid ambiguity and simplify parsing
+// back the path.
 func joinPathAndFieldName(path, fieldName string) string {
-	if fieldName == "" || strings.Contains(fieldName, ".") {
+	if fieldName == "" || strings.ContainsAny(fieldName, ".[") {
 		return fmt.Sprintf("%s[%q]", path, fieldName)
 	}
 	if path == "" {
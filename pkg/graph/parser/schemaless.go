@@ -35,6 +35,11 @@ func parseSchemalessResource(resource interface{}, path string) ([]variable.Fiel
 	case map[string]interface{}:
 		for field, value := range field {
 			fieldPath := joinPathAndFieldName(path, field)
+			keyExpressions, err := parseKey(field, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			expressionsFields = append(expressionsFields, keyExpressions...)
 			fieldExpressions, err := parseSchemalessResource(value, fieldPath)
 			if err != nil {
 				return nil, err
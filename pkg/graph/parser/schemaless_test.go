@@ -25,14 +25,23 @@ func areEqualExpressionFields(a, b []variable.FieldDescriptor) bool {
 		return false
 	}
 
-	sort.Slice(a, func(i, j int) bool { return a[i].Path < a[j].Path })
-	sort.Slice(b, func(i, j int) bool { return b[i].Path < b[j].Path })
+	less := func(fields []variable.FieldDescriptor) func(i, j int) bool {
+		return func(i, j int) bool {
+			if fields[i].Path != fields[j].Path {
+				return fields[i].Path < fields[j].Path
+			}
+			return !fields[i].Key && fields[j].Key
+		}
+	}
+	sort.Slice(a, less(a))
+	sort.Slice(b, less(b))
 
 	for i := range a {
 		if !equalStrings(a[i].Expressions, b[i].Expressions) ||
 			!areEqualSlices(a[i].ExpectedTypes, b[i].ExpectedTypes) ||
 			a[i].Path != b[i].Path ||
-			a[i].StandaloneExpression != b[i].StandaloneExpression {
+			a[i].StandaloneExpression != b[i].StandaloneExpression ||
+			a[i].Key != b[i].Key {
 			return false
 		}
 	}
@@ -92,6 +101,45 @@ func TestParseSchemalessResource(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Expressions in keys",
+			resource: map[string]interface{}{
+				"labels": map[string]interface{}{
+					"${schema.spec.team}":             "${schema.spec.owner}",
+					"example.com/${schema.spec.tier}": "true",
+				},
+			},
+			want: []variable.FieldDescriptor{
+				{
+					Expressions:          []string{"schema.spec.team"},
+					ExpectedTypes:        []string{"string"},
+					Path:                 `labels["${schema.spec.team}"]`,
+					StandaloneExpression: true,
+					Key:                  true,
+				},
+				{
+					Expressions:          []string{"schema.spec.owner"},
+					ExpectedTypes:        []string{"any"},
+					Path:                 `labels["${schema.spec.team}"]`,
+					StandaloneExpression: true,
+				},
+				{
+					Expressions:   []string{"schema.spec.tier"},
+					ExpectedTypes: []string{"string"},
+					Path:          `labels["example.com/${schema.spec.tier}"]`,
+					Key:           true,
+				},
+			},
+		},
+		{
+			name: "Double quotes in keys",
+			resource: map[string]interface{}{
+				"labels": map[string]interface{}{
+					`${schema.spec.team + "-owner"}`: "true",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "Nested map",
 			resource: map[string]interface{}{
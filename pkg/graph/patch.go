@@ -0,0 +1,164 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// jsonPatchField is the field of the object of a JSON patch holding its
+// operations, so that their expressions are resolved along with the object.
+const jsonPatchField = "jsonPatch"
+
+// jsonPatchOperations are the operations of a JSON patch, see RFC 6902.
+var jsonPatchOperations = map[string]bool{
+	"add": true, "remove": true, "replace": true, "move": true, "copy": true, "test": true,
+}
+
+// parsePatchObject returns the object declared by the given patch resource.
+// The object of a strategic merge patch is the patch itself, with the
+// apiVersion, kind, name and namespace of the patched object. The object of a
+// JSON patch only identifies the patched object, and holds the operations of
+// the patch under jsonPatchField.
+//
+// Patches only change some fields of objects kro doesn't own: they can't
+// declare the policies of the resources kro creates.
+func parsePatchObject(rgResource *v1alpha1.Resource) (map[string]interface{}, error) {
+	switch {
+	case len(rgResource.Template.Raw) > 0:
+		return nil, fmt.Errorf("resource %s can't declare both a template and a patch", rgResource.ID)
+	case rgResource.ExternalRef != nil:
+		return nil, fmt.Errorf("resource %s can't declare both an externalRef and a patch", rgResource.ID)
+	case rgResource.ForEach != nil:
+		return nil, fmt.Errorf("resource %s can't iterate over a collection with a patch", rgResource.ID)
+	case rgResource.Hooks != nil:
+		return nil, fmt.Errorf("resource %s can't declare hooks with a patch", rgResource.ID)
+	case rgResource.ReadinessTimeout != nil:
+		return nil, fmt.Errorf("resource %s can't declare a readinessTimeout with a patch", rgResource.ID)
+	case rgResource.AutoHeal != nil, rgResource.ConflictPolicy != "", rgResource.AdoptionPolicy != "",
+		len(rgResource.IgnoreFields) > 0:
+		return nil, fmt.Errorf("resource %s can't declare autoHeal, a conflictPolicy, an adoptionPolicy or ignoreFields with a patch",
+			rgResource.ID)
+	}
+
+	patch := rgResource.Patch
+	objectMeta := map[string]interface{}{"name": patch.Metadata.Name}
+	if patch.Metadata.Namespace != "" {
+		objectMeta["namespace"] = patch.Metadata.Namespace
+	}
+	object := map[string]interface{}{}
+	switch patch.Type {
+	case "", v1alpha1.PatchTypeStrategicMerge:
+		if err := yaml.UnmarshalStrict(patch.Patch.Raw, &object); err != nil {
+			return nil, fmt.Errorf("the patch of resource %s must be an object: %w", rgResource.ID, err)
+		}
+		if _, ok := object["apiVersion"]; ok {
+			return nil, fmt.Errorf("the patch of resource %s can't set the apiVersion of the patched object", rgResource.ID)
+		}
+		if _, ok := object["kind"]; ok {
+			return nil, fmt.Errorf("the patch of resource %s can't set the kind of the patched object", rgResource.ID)
+		}
+		if patchMeta, ok := object["metadata"].(map[string]interface{}); ok {
+			for _, field := range []string{"name", "namespace"} {
+				if _, ok := patchMeta[field]; ok {
+					return nil, fmt.Errorf("the patch of resource %s can't set the %s of the patched object", rgResource.ID, field)
+				}
+			}
+			for field, value := range patchMeta {
+				objectMeta[field] = value
+			}
+		}
+	case v1alpha1.PatchTypeJSON:
+		var operations []interface{}
+		if err := yaml.UnmarshalStrict(patch.Patch.Raw, &operations); err != nil {
+			return nil, fmt.Errorf("the patch of resource %s must be a list of operations: %w", rgResource.ID, err)
+		}
+		for i, rawOperation := range operations {
+			operation, ok := rawOperation.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("operation %d of the patch of resource %s is not an object", i, rgResource.ID)
+			}
+			if op, _ := operation["op"].(string); !jsonPatchOperations[op] {
+				return nil, fmt.Errorf("operation %d of the patch of resource %s has an unknown op %q", i, rgResource.ID, operation["op"])
+			}
+			if path, _ := operation["path"].(string); path == "" {
+				return nil, fmt.Errorf("operation %d of the patch of resource %s must declare a path", i, rgResource.ID)
+			}
+		}
+		object[jsonPatchField] = operations
+	default:
+		return nil, fmt.Errorf("unknown patch type %q of resource %s", patch.Type, rgResource.ID)
+	}
+	object["apiVersion"] = patch.APIVersion
+	object["kind"] = patch.Kind
+	object["metadata"] = objectMeta
+	return object, nil
+}
+
+// patchType returns the type of the patch of the given resource, or an empty
+// string if it doesn't declare one.
+func patchType(rgResource *v1alpha1.Resource) v1alpha1.PatchType {
+	switch {
+	case rgResource.Patch == nil:
+		return ""
+	case rgResource.Patch.Type == "":
+		return v1alpha1.PatchTypeStrategicMerge
+	default:
+		return rgResource.Patch.Type
+	}
+}
+
+// splitJSONPatch returns the given object without the operations of its JSON
+// patch, and the operations, under jsonPatchField, if there are any. The
+// operations don't follow the schema of the patched object.
+func splitJSONPatch(object map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	operations, ok := object[jsonPatchField]
+	if !ok {
+		return object, nil
+	}
+	rest := make(map[string]interface{}, len(object)-1)
+	for field, value := range object {
+		if field != jsonPatchField {
+			rest[field] = value
+		}
+	}
+	return rest, map[string]interface{}{jsonPatchField: operations}
+}
+
+// PatchData returns the type and the content of the patch applied to the
+// given resolved object of a patch resource of the given type.
+func PatchData(object *unstructured.Unstructured, pt v1alpha1.PatchType) (types.PatchType, []byte, error) {
+	if pt == v1alpha1.PatchTypeJSON {
+		data, err := json.Marshal(object.Object[jsonPatchField])
+		return types.JSONPatchType, data, err
+	}
+
+	patch := object.DeepCopy()
+	delete(patch.Object, "apiVersion")
+	delete(patch.Object, "kind")
+	unstructured.RemoveNestedField(patch.Object, "metadata", "name")
+	unstructured.RemoveNestedField(patch.Object, "metadata", "namespace")
+	if objectMeta, _, _ := unstructured.NestedMap(patch.Object, "metadata"); len(objectMeta) == 0 {
+		delete(patch.Object, "metadata")
+	}
+	data, err := json.Marshal(patch.Object)
+	return types.StrategicMergePatchType, data, err
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_Patch(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	schema := generator.WithSchema(
+		"Test", "v1alpha1",
+		map[string]interface{}{"name": "string", "owner": "string"},
+		nil,
+	)
+	credentials := v1alpha1.ExternalRef{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   v1alpha1.ExternalRefMetadata{Name: "${schema.spec.name}-credentials"},
+	}
+	instance := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kro.run/v1alpha1",
+		"kind":       "Test",
+		"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+		"spec":       map[string]interface{}{"name": "app", "owner": "team-a"},
+	}}
+
+	t.Run("strategic merge patch", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+			schema,
+			generator.WithPatch("credentials", credentials, "", map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"owner": "${schema.spec.owner}"},
+				},
+			}),
+		))
+		require.NoError(t, err)
+		assert.True(t, g.Resources["credentials"].IsExternalRef())
+		assert.Equal(t, v1alpha1.PatchTypeStrategicMerge, g.Resources["credentials"].GetPatchType())
+
+		rt, err := g.NewGraphRuntime(instance)
+		require.NoError(t, err)
+		resource, state := rt.GetResource("credentials")
+		require.Equal(t, runtime.ResourceStateResolved, state)
+		assert.Equal(t, "app-credentials", resource.GetName())
+
+		patchType, data, err := PatchData(resource, g.Resources["credentials"].GetPatchType())
+		require.NoError(t, err)
+		assert.Equal(t, types.StrategicMergePatchType, patchType)
+		assert.JSONEq(t, `{"metadata":{"annotations":{"owner":"team-a"}}}`, string(data))
+	})
+
+	t.Run("JSON patch", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+			schema,
+			generator.WithPatch("credentials", credentials, v1alpha1.PatchTypeJSON, []interface{}{
+				map[string]interface{}{"op": "add", "path": "/metadata/labels/owner", "value": "${schema.spec.owner}"},
+			}),
+		))
+		require.NoError(t, err)
+
+		rt, err := g.NewGraphRuntime(instance)
+		require.NoError(t, err)
+		resource, state := rt.GetResource("credentials")
+		require.Equal(t, runtime.ResourceStateResolved, state)
+
+		patchType, data, err := PatchData(resource, g.Resources["credentials"].GetPatchType())
+		require.NoError(t, err)
+		assert.Equal(t, types.JSONPatchType, patchType)
+		assert.JSONEq(t, `[{"op":"add","path":"/metadata/labels/owner","value":"team-a"}]`, string(data))
+	})
+
+	for _, tt := range []struct {
+		name      string
+		patchType v1alpha1.PatchType
+		patch     interface{}
+		modify    func(*v1alpha1.Resource)
+		wantErr   string
+	}{
+		{
+			name:    "patch setting the kind",
+			patch:   map[string]interface{}{"kind": "ConfigMap"},
+			wantErr: "can't set the kind of the patched object",
+		},
+		{
+			name:    "patch setting the name",
+			patch:   map[string]interface{}{"metadata": map[string]interface{}{"name": "other"}},
+			wantErr: "can't set the name of the patched object",
+		},
+		{
+			name:      "JSON patch with an unknown op",
+			patchType: v1alpha1.PatchTypeJSON,
+			patch:     []interface{}{map[string]interface{}{"op": "merge", "path": "/data"}},
+			wantErr:   `unknown op "merge"`,
+		},
+		{
+			name:      "JSON patch that isn't a list",
+			patchType: v1alpha1.PatchTypeJSON,
+			patch:     map[string]interface{}{"data": map[string]interface{}{}},
+			wantErr:   "must be a list of operations",
+		},
+		{
+			name:    "patch with an adoption policy",
+			patch:   map[string]interface{}{},
+			modify:  func(r *v1alpha1.Resource) { r.AdoptionPolicy = v1alpha1.AdoptionPolicyAdopt },
+			wantErr: "can't declare autoHeal, a conflictPolicy, an adoptionPolicy or ignoreFields with a patch",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rgd := generator.NewResourceGraphDefinition("testrgd", schema,
+				generator.WithPatch("credentials", credentials, tt.patchType, tt.patch))
+			if tt.modify != nil {
+				tt.modify(rgd.Spec.Resources[0])
+			}
+			_, err := builder.NewResourceGraphDefinition(rgd)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// RenderFormat is a format the dependency graph can be rendered in.
+type RenderFormat string
+
+const (
+	// RenderFormatDOT renders the graph in the Graphviz DOT language.
+	RenderFormatDOT RenderFormat = "dot"
+	// RenderFormatMermaid renders the graph as a Mermaid flowchart.
+	RenderFormatMermaid RenderFormat = "mermaid"
+)
+
+// RenderOption customizes the rendering of the dependency graph.
+type RenderOption func(*renderOptions)
+
+type renderOptions struct {
+	edgeOrigins bool
+}
+
+// WithEdgeOrigins labels the edges with their origins, i.e the expressions
+// or the dependsOn entries that created them.
+func WithEdgeOrigins() RenderOption {
+	return func(o *renderOptions) {
+		o.edgeOrigins = true
+	}
+}
+
+// Render renders the dependency graph of the resources in the given format.
+// Edges go from a resource to the resources waiting on it, and nodes are
+// labeled with the id and kind of their resource. The output is stable, so
+// that it only changes with the graph.
+func (rgd *Graph) Render(format RenderFormat, opts ...RenderOption) (string, error) {
+	options := &renderOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	ids := rgd.TopologicalOrder
+	type edge struct{ from, to string }
+	var edges []edge
+	for _, id := range ids {
+		dependencies := maps.Keys(rgd.DAG.Vertices[id].DependsOn)
+		slices.Sort(dependencies)
+		for _, dependency := range dependencies {
+			edges = append(edges, edge{from: dependency, to: id})
+		}
+	}
+
+	var b strings.Builder
+	switch format {
+	case RenderFormatDOT:
+		b.WriteString("digraph {\n")
+		b.WriteString("  rankdir=LR;\n")
+		for _, id := range ids {
+			fmt.Fprintf(&b, "  %s [label=\"%s\"];\n", id, rgd.nodeLabel(id, `\n`))
+		}
+		for _, e := range edges {
+			if options.edgeOrigins {
+				fmt.Fprintf(&b, "  %s -> %s [label=\"%s\"];\n", e.from, e.to,
+					escapeLabel(rgd.DependencyOrigins(e.to, e.from), dotEscaper, `\n`))
+				continue
+			}
+			fmt.Fprintf(&b, "  %s -> %s;\n", e.from, e.to)
+		}
+		b.WriteString("}\n")
+	case RenderFormatMermaid:
+		b.WriteString("flowchart LR\n")
+		for _, id := range ids {
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", id, rgd.nodeLabel(id, "<br/>"))
+		}
+		for _, e := range edges {
+			if options.edgeOrigins {
+				fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", e.from,
+					escapeLabel(rgd.DependencyOrigins(e.to, e.from), mermaidEscaper, "<br/>"), e.to)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s --> %s\n", e.from, e.to)
+		}
+	default:
+		return "", fmt.Errorf("unsupported graph format %q, must be one of %q or %q",
+			format, RenderFormatDOT, RenderFormatMermaid)
+	}
+	return b.String(), nil
+}
+
+// nodeLabel returns the label of the node of the given resource, made of its
+// id and kind joined by the given line break. Resources iterating over a
+// collection are suffixed with `[*]`.
+func (rgd *Graph) nodeLabel(id, lineBreak string) string {
+	resource := rgd.Resources[id]
+	label := id
+	if resource.IsForEach() {
+		label += "[*]"
+	}
+	if kind := resource.Unstructured().GetKind(); kind != "" {
+		label += lineBreak + kind
+	}
+	return label
+}
+
+// DependencyOrigins returns the origins of the dependency of the given
+// resource on the given dependency, i.e the fields and expressions, or the
+// dependsOn entries, that created it, sorted.
+func (rgd *Graph) DependencyOrigins(id, dependency string) []string {
+	origins := slices.Clone(rgd.edges[dependencyEdge{from: id, to: dependency}])
+	slices.Sort(origins)
+	return origins
+}
+
+// The escapers of the quoted labels of the formats.
+var (
+	dotEscaper     = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	mermaidEscaper = strings.NewReplacer(`"`, "#quot;")
+)
+
+// escapeLabel joins the given lines of a label with the given line break,
+// escaping them with the given escaper.
+func escapeLabel(lines []string, escaper *strings.Replacer, lineBreak string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = escaper.Replace(line)
+	}
+	return strings.Join(escaped, lineBreak)
+}
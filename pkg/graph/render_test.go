@@ -0,0 +1,120 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraph_Render(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("testrgd",
+		generator.WithSchema(
+			"Test", "v1alpha1",
+			map[string]interface{}{"name": "string"},
+			nil,
+		),
+		generator.WithResource("vpc", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "VPC",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}",
+			},
+		}, nil, nil),
+		generator.WithResource("subnet", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "Subnet",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}",
+			},
+			"spec": map[string]interface{}{
+				"vpcID": "${vpc.status.vpcID}",
+			},
+		}, nil, nil),
+		generator.WithResource("sg", map[string]interface{}{
+			"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+			"kind":       "SecurityGroup",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}",
+			},
+			"spec": map[string]interface{}{
+				"vpcID":       "${vpc.status.vpcID}",
+				"description": "${subnet.status.subnetID}",
+			},
+		}, nil, nil),
+	))
+	require.NoError(t, err)
+
+	dot, err := g.Render(RenderFormatDOT)
+	require.NoError(t, err)
+	assert.Equal(t, `digraph {
+  rankdir=LR;
+  vpc [label="vpc\nVPC"];
+  subnet [label="subnet\nSubnet"];
+  sg [label="sg\nSecurityGroup"];
+  vpc -> subnet;
+  subnet -> sg;
+  vpc -> sg;
+}
+`, dot)
+
+	mermaid, err := g.Render(RenderFormatMermaid)
+	require.NoError(t, err)
+	assert.Equal(t, `flowchart LR
+  vpc["vpc<br/>VPC"]
+  subnet["subnet<br/>Subnet"]
+  sg["sg<br/>SecurityGroup"]
+  vpc --> subnet
+  subnet --> sg
+  vpc --> sg
+`, mermaid)
+
+	assert.Equal(t, []string{
+		"spec.description: ${subnet.status.subnetID}",
+	}, g.DependencyOrigins("sg", "subnet"))
+	assert.Empty(t, g.DependencyOrigins("vpc", "sg"))
+
+	dot, err = g.Render(RenderFormatDOT, WithEdgeOrigins())
+	require.NoError(t, err)
+	assert.Equal(t, `digraph {
+  rankdir=LR;
+  vpc [label="vpc\nVPC"];
+  subnet [label="subnet\nSubnet"];
+  sg [label="sg\nSecurityGroup"];
+  vpc -> subnet [label="spec.vpcID: ${vpc.status.vpcID}"];
+  subnet -> sg [label="spec.description: ${subnet.status.subnetID}"];
+  vpc -> sg [label="spec.vpcID: ${vpc.status.vpcID}"];
+}
+`, dot)
+
+	mermaid, err = g.Render(RenderFormatMermaid, WithEdgeOrigins())
+	require.NoError(t, err)
+	assert.Contains(t, mermaid, `  subnet -->|"spec.description: ${subnet.status.subnetID}"| sg`)
+
+	_, err = g.Render("svg")
+	assert.ErrorContains(t, err, "unsupported graph format")
+}
@@ -15,13 +15,17 @@ package graph
 
 import (
 	"slices"
+	"time"
 
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
 	"github.com/kro-run/kro/pkg/graph/variable"
+	"github.com/kro-run/kro/pkg/runtime"
 )
 
 // Resource represents a resource in a resource graph definition, it hholds
@@ -72,6 +76,63 @@ type Resource struct {
 	// order reflects the original order in which the resources were specified,
 	// and lets us keep the client-specified ordering where the dependencies allow.
 	order int
+	// forEach is the standalone expression returning the collection of the
+	// instance the resource template iterates over, if any.
+	forEach string
+	// forEachKey is the standalone expression returning the key of an item
+	// of the collection. Items are identified by their index or map key if
+	// it's empty.
+	forEachKey string
+	// templateID is the id of the resource template the resource was
+	// expanded from, if any.
+	templateID string
+	// each is the item of the collection the resource was expanded from.
+	each map[string]interface{}
+	// dependsOn is the list of the resources this resource explicitly depends
+	// on, regardless of its expressions.
+	dependsOn []string
+	// external indicates if the resource is a reference to an existing object,
+	// which is read or patched instead of being created.
+	external bool
+	// patchType is the type of the patch the resource applies to an existing
+	// object, if any.
+	patchType v1alpha1.PatchType
+	// readinessTimeout is how long the resource can take to become ready once
+	// created, zero meaning forever.
+	readinessTimeout time.Duration
+	// onFailure is what to do once the readiness timeout has passed.
+	onFailure v1alpha1.FailurePolicy
+	// retryPolicy is the policy of the retries while waiting for the
+	// resource, either its own or the one of the resource graph definition.
+	retryPolicy *v1alpha1.RetryPolicy
+	// autoHeal reports whether changes made to the resource outside of kro
+	// are reverted.
+	autoHeal bool
+	// conflictPolicy is what to do with the fields of the resource managed
+	// by other field managers.
+	conflictPolicy v1alpha1.ConflictPolicy
+	// ignoreFields are the paths of the fields of the resource kept as they
+	// are once it's created.
+	ignoreFields [][]fieldpath.Segment
+	// deletionPolicy is what to do with the resource when it's no longer
+	// part of the instance.
+	deletionPolicy v1alpha1.DeletionPolicy
+	// adoptionPolicy is what to do when the resource already exists but
+	// isn't managed by the instance.
+	adoptionPolicy v1alpha1.AdoptionPolicy
+	// hookAssertions are the expressions of the assertions of the hooks of
+	// the resource, by phase.
+	hookAssertions map[runtime.HookPhase][]string
+	// emulatedLocals are the outputs of the dry-runs of the locals, recorded
+	// on the instance to validate the expressions referring to them.
+	emulatedLocals map[string]interface{}
+	// gates are the ids of the resources the resources depending on the
+	// resource also wait for: the Jobs of its postApply hooks and the objects
+	// it waits for.
+	gates []string
+	// target is the remote cluster the resource is applied to, nil for the
+	// cluster of kro.
+	target *v1alpha1.Target
 }
 
 // GetDependencies returns the dependencies of the resource.
@@ -158,6 +219,95 @@ func (r *Resource) IsNamespaced() bool {
 	return r.namespaced
 }
 
+// IsForEach returns true if the resource is a template iterating over a
+// collection of the instance.
+func (r *Resource) IsForEach() bool {
+	return r.forEach != ""
+}
+
+// IsExternalRef returns true if the resource is a reference to an existing
+// object, which kro reads or patches but doesn't manage.
+func (r *Resource) IsExternalRef() bool {
+	return r.external
+}
+
+// GetPatchType returns the type of the patch the resource applies to an
+// existing object, or an empty string if it isn't a patch.
+func (r *Resource) GetPatchType() v1alpha1.PatchType {
+	return r.patchType
+}
+
+// GetReadinessTimeout returns how long the resource can take to become ready
+// once created, zero meaning forever.
+func (r *Resource) GetReadinessTimeout() time.Duration {
+	return r.readinessTimeout
+}
+
+// GetOnFailure returns what to do once the readiness timeout of the resource
+// has passed.
+func (r *Resource) GetOnFailure() v1alpha1.FailurePolicy {
+	return r.onFailure
+}
+
+// IsAutoHeal returns true if the changes made to the resource outside of kro
+// are reverted.
+func (r *Resource) IsAutoHeal() bool {
+	return r.autoHeal
+}
+
+// GetConflictPolicy returns what to do with the fields of the resource
+// managed by other field managers.
+func (r *Resource) GetConflictPolicy() v1alpha1.ConflictPolicy {
+	return r.conflictPolicy
+}
+
+// GetIgnoreFields returns the paths of the fields of the resource kept as
+// they are once it's created.
+func (r *Resource) GetIgnoreFields() [][]fieldpath.Segment {
+	return r.ignoreFields
+}
+
+// GetAdoptionPolicy returns what to do when the resource already exists but
+// isn't managed by the instance.
+func (r *Resource) GetAdoptionPolicy() v1alpha1.AdoptionPolicy {
+	return r.adoptionPolicy
+}
+
+// GetDeletionPolicy returns what to do with the resource when it's no longer
+// part of the instance.
+func (r *Resource) GetDeletionPolicy() v1alpha1.DeletionPolicy {
+	return r.deletionPolicy
+}
+
+// GetHookAssertions returns the expressions of the assertions of the hooks
+// of the given phase.
+func (r *Resource) GetHookAssertions(phase runtime.HookPhase) []string {
+	return r.hookAssertions[phase]
+}
+
+// GetRetryPolicy returns the policy of the retries while waiting for the
+// resource, or nil if there's none.
+func (r *Resource) GetRetryPolicy() *v1alpha1.RetryPolicy {
+	return r.retryPolicy
+}
+
+// GetTarget returns the remote cluster the resource is applied to and
+// watched in, or nil if it's the cluster of kro.
+func (r *Resource) GetTarget() *v1alpha1.Target {
+	return r.target
+}
+
+// GetTemplateID returns the id of the resource template the resource was
+// expanded from.
+func (r *Resource) GetTemplateID() string {
+	return r.templateID
+}
+
+// GetEach returns the item of the collection the resource was expanded from.
+func (r *Resource) GetEach() map[string]interface{} {
+	return r.each
+}
+
 // DeepCopy returns a deep copy of the resource.
 func (r *Resource) DeepCopy() *Resource {
 	return &Resource{
@@ -171,5 +321,23 @@ func (r *Resource) DeepCopy() *Resource {
 		readyWhenExpressions:   slices.Clone(r.readyWhenExpressions),
 		includeWhenExpressions: slices.Clone(r.includeWhenExpressions),
 		namespaced:             r.namespaced,
+		forEach:                r.forEach,
+		forEachKey:             r.forEachKey,
+		templateID:             r.templateID,
+		each:                   r.each,
+		dependsOn:              slices.Clone(r.dependsOn),
+		external:               r.external,
+		patchType:              r.patchType,
+		readinessTimeout:       r.readinessTimeout,
+		onFailure:              r.onFailure,
+		retryPolicy:            r.retryPolicy,
+		autoHeal:               r.autoHeal,
+		conflictPolicy:         r.conflictPolicy,
+		ignoreFields:           r.ignoreFields,
+		deletionPolicy:         r.deletionPolicy,
+		adoptionPolicy:         r.adoptionPolicy,
+		hookAssertions:         r.hookAssertions,
+		gates:                  slices.Clone(r.gates),
+		target:                 r.target,
 	}
 }
@@ -14,6 +14,7 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
 
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -158,3 +159,52 @@ func ConvertJSONSchemaPropsToSpecSchema(props *extv1.JSONSchemaProps) (*spec.Sch
 
 	return schema, nil
 }
+
+// ConvertSpecSchemaToJSONSchemaProps converts a spec.Schema, as returned by the
+// schema resolvers, to an extv1.JSONSchemaProps that can be embedded in a CRD.
+//
+// The schemas of built-in types don't always follow the rules of structural
+// schemas, so they are adjusted on the way: int-or-string fields are turned
+// into the equivalent CRD construct, and objects without properties preserve
+// unknown fields instead of pruning them.
+func ConvertSpecSchemaToJSONSchemaProps(schema *spec.Schema) (*extv1.JSONSchemaProps, error) {
+	if schema == nil {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling schema: %w", err)
+	}
+	props := &extv1.JSONSchemaProps{}
+	if err := json.Unmarshal(raw, props); err != nil {
+		return nil, fmt.Errorf("error unmarshaling schema: %w", err)
+	}
+	makeStructural(props)
+	return props, nil
+}
+
+// makeStructural adjusts the given schema, and its sub schemas, to follow the
+// rules of CRD structural schemas.
+func makeStructural(props *extv1.JSONSchemaProps) {
+	if props.XIntOrString {
+		props.Type = ""
+		props.Format = ""
+		props.AnyOf = []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}}
+	}
+	if props.Type == "object" && len(props.Properties) == 0 && props.AdditionalProperties == nil {
+		preserveUnknownFields := true
+		props.XPreserveUnknownFields = &preserveUnknownFields
+	}
+
+	for name, property := range props.Properties {
+		makeStructural(&property)
+		props.Properties[name] = property
+	}
+	if props.Items != nil && props.Items.Schema != nil {
+		makeStructural(props.Items.Schema)
+	}
+	if props.AdditionalProperties != nil && props.AdditionalProperties.Schema != nil {
+		makeStructural(props.AdditionalProperties.Schema)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestConvertSpecSchemaToJSONSchemaProps(t *testing.T) {
+	preserveUnknownFields := true
+	input := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type:     []string{"object"},
+			Required: []string{"port"},
+			Properties: map[string]spec.Schema{
+				"port": {
+					SchemaProps: spec.SchemaProps{Type: []string{"string"}, Format: "int-or-string"},
+					VendorExtensible: spec.VendorExtensible{
+						Extensions: spec.Extensions{"x-kubernetes-int-or-string": true},
+					},
+				},
+				"labels": {
+					SchemaProps: spec.SchemaProps{
+						Type: []string{"object"},
+						AdditionalProperties: &spec.SchemaOrBool{
+							Allows: true,
+							Schema: &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+						},
+					},
+				},
+				"raw": {SchemaProps: spec.SchemaProps{Type: []string{"object"}}},
+				"ports": {
+					SchemaProps: spec.SchemaProps{
+						Type: []string{"array"},
+						Items: &spec.SchemaOrArray{Schema: &spec.Schema{
+							SchemaProps: spec.SchemaProps{Type: []string{"integer"}, Format: "int32"},
+						}},
+					},
+					VendorExtensible: spec.VendorExtensible{
+						Extensions: spec.Extensions{"x-kubernetes-list-type": "set"},
+					},
+				},
+			},
+		},
+	}
+
+	got, err := ConvertSpecSchemaToJSONSchemaProps(input)
+	require.NoError(t, err)
+
+	listType := "set"
+	assert.Equal(t, &extv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"port"},
+		Properties: map[string]extv1.JSONSchemaProps{
+			"port": {
+				XIntOrString: true,
+				AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+			},
+			"labels": {
+				Type: "object",
+				AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &extv1.JSONSchemaProps{Type: "string"},
+				},
+			},
+			"raw": {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+			"ports": {
+				Type:      "array",
+				XListType: &listType,
+				Items: &extv1.JSONSchemaPropsOrArray{
+					Schema: &extv1.JSONSchemaProps{Type: "integer", Format: "int32"},
+				},
+			},
+		},
+	}, got)
+
+	got, err = ConvertSpecSchemaToJSONSchemaProps(nil)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,257 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/applyconfigurations"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	smdschema "sigs.k8s.io/structured-merge-diff/v4/schema"
+)
+
+// typeResolver resolves the structured merge schemas of the built-in types,
+// by their OpenAPI name.
+var typeResolver = applyconfigurations.NewTypeConverter(scheme.Scheme).TypeResolver
+
+const objectMetaName = "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+
+// OfflineResolver resolves the schemas of the kinds without access to a
+// cluster, so that resource graph definitions can be validated offline:
+//
+//   - the kinds of the given CRDs are resolved from the schemas of their
+//     versions
+//   - the built-in kinds of Kubernetes are resolved from the schemas client-go
+//     is built with, in which numbers are all integers and the timestamps are
+//     strings
+//
+// Any other kind is resolved as an object whose fields aren't type checked,
+// and is reported by Unresolved.
+type OfflineResolver struct {
+	crds map[k8sschema.GroupVersionKind]*spec.Schema
+
+	mu         sync.Mutex
+	unresolved map[k8sschema.GroupVersionKind]bool
+}
+
+// NewOfflineResolver returns an offline resolver of the given CRDs and the
+// built-in kinds.
+func NewOfflineResolver(crds []*extv1.CustomResourceDefinition) (*OfflineResolver, error) {
+	r := &OfflineResolver{
+		crds:       make(map[k8sschema.GroupVersionKind]*spec.Schema),
+		unresolved: make(map[k8sschema.GroupVersionKind]bool),
+	}
+	for _, crd := range crds {
+		for _, version := range crd.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			gvk := k8sschema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			// The schemas are converted through their JSON form, which keeps
+			// the x-kubernetes extensions as the published schemas do.
+			raw, err := json.Marshal(version.Schema.OpenAPIV3Schema)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema of %s: %w", gvk, err)
+			}
+			resolved := &spec.Schema{}
+			if err := json.Unmarshal(raw, resolved); err != nil {
+				return nil, fmt.Errorf("invalid schema of %s: %w", gvk, err)
+			}
+			withObjectFields(resolved)
+			r.crds[gvk] = resolved
+		}
+	}
+	return r, nil
+}
+
+// ResolveSchema returns the schema of the given kind.
+func (r *OfflineResolver) ResolveSchema(gvk k8sschema.GroupVersionKind) (*spec.Schema, error) {
+	if resolved, ok := r.crds[gvk]; ok {
+		return resolved, nil
+	}
+	if resolved, ok := builtinSchema(gvk); ok {
+		return resolved, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unresolved[gvk] = true
+	return schemalessObject(), nil
+}
+
+// Unresolved returns the kinds resolved so far without a schema, sorted.
+func (r *OfflineResolver) Unresolved() []k8sschema.GroupVersionKind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	unresolved := make([]k8sschema.GroupVersionKind, 0, len(r.unresolved))
+	for gvk := range r.unresolved {
+		unresolved = append(unresolved, gvk)
+	}
+	slices.SortFunc(unresolved, func(a, b k8sschema.GroupVersionKind) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return unresolved
+}
+
+// builtinSchema returns the schema of the given built-in kind, if it is one.
+func builtinSchema(gvk k8sschema.GroupVersionKind) (*spec.Schema, bool) {
+	obj, err := scheme.Scheme.New(gvk)
+	if err != nil {
+		return nil, false
+	}
+	// The OpenAPI names of the types are their Go names, with the domain of
+	// their package reversed, e.g io.k8s.api.apps.v1.Deployment.
+	t := reflect.TypeOf(obj).Elem()
+	path := strings.Split(t.PkgPath(), "/")
+	domain := strings.Split(path[0], ".")
+	slices.Reverse(domain)
+	name := strings.Join(append(domain, append(path[1:], t.Name())...), ".")
+
+	parseable := typeResolver.Type(name)
+	if !parseable.IsValid() {
+		return nil, false
+	}
+	c := &smdConverter{schema: parseable.Schema, visiting: map[string]bool{}}
+	resolved := c.convert(parseable.TypeRef)
+	return &resolved, true
+}
+
+// withObjectFields adds the fields every object has to the given schema of a
+// CRD version, as the API server does when publishing it.
+func withObjectFields(s *spec.Schema) {
+	if s.Properties == nil {
+		s.Properties = make(map[string]spec.Schema)
+	}
+	s.Properties["apiVersion"] = ofType("string")
+	s.Properties["kind"] = ofType("string")
+	if metadata := typeResolver.Type(objectMetaName); metadata.IsValid() {
+		c := &smdConverter{schema: metadata.Schema, visiting: map[string]bool{}}
+		s.Properties["metadata"] = c.convert(metadata.TypeRef)
+	}
+}
+
+// smdConverter converts structured merge schemas to OpenAPI schemas.
+type smdConverter struct {
+	schema *smdschema.Schema
+	// visiting are the named types being converted, to break the cycles of
+	// the recursive types.
+	visiting map[string]bool
+}
+
+// wellKnownTypes are the schemas of the named types whose structured merge
+// schema is untyped.
+var wellKnownTypes = map[string]func() spec.Schema{
+	"io.k8s.apimachinery.pkg.apis.meta.v1.Time":       dateTime,
+	"io.k8s.apimachinery.pkg.apis.meta.v1.MicroTime":  dateTime,
+	"io.k8s.apimachinery.pkg.api.resource.Quantity":   intOrString,
+	"io.k8s.apimachinery.pkg.util.intstr.IntOrString": intOrString,
+}
+
+func (c *smdConverter) convert(ref smdschema.TypeRef) spec.Schema {
+	if ref.NamedType != nil {
+		name := *ref.NamedType
+		if wellKnown, ok := wellKnownTypes[name]; ok {
+			return wellKnown()
+		}
+		if c.visiting[name] {
+			return *schemalessObject()
+		}
+		c.visiting[name] = true
+		defer delete(c.visiting, name)
+	}
+
+	atom, ok := c.schema.Resolve(ref)
+	if !ok {
+		return untyped()
+	}
+	kinds := 0
+	for _, set := range []bool{atom.Scalar != nil, atom.List != nil, atom.Map != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return untyped()
+	}
+
+	switch {
+	case atom.Scalar != nil:
+		switch *atom.Scalar {
+		case smdschema.Numeric:
+			return ofType("integer")
+		case smdschema.String:
+			return ofType("string")
+		case smdschema.Boolean:
+			return ofType("boolean")
+		default:
+			return untyped()
+		}
+	case atom.List != nil:
+		items := c.convert(atom.List.ElementType)
+		s := ofType("array")
+		s.Items = &spec.SchemaOrArray{Schema: &items}
+		return s
+	default:
+		s := ofType("object")
+		if len(atom.Map.Fields) == 0 {
+			additional := c.convert(atom.Map.ElementType)
+			s.AdditionalProperties = &spec.SchemaOrBool{Allows: true, Schema: &additional}
+			return s
+		}
+		s.Properties = make(map[string]spec.Schema, len(atom.Map.Fields))
+		for _, field := range atom.Map.Fields {
+			s.Properties[field.Name] = c.convert(field.Type)
+		}
+		return s
+	}
+}
+
+// ofType returns a schema of the given type.
+func ofType(t string) spec.Schema {
+	return spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{t}}}
+}
+
+func dateTime() spec.Schema {
+	s := ofType("string")
+	s.Format = "date-time"
+	return s
+}
+
+func intOrString() spec.Schema {
+	s := spec.Schema{SchemaProps: spec.SchemaProps{AnyOf: []spec.Schema{ofType("integer"), ofType("string")}}}
+	s.AddExtension("x-kubernetes-int-or-string", true)
+	return s
+}
+
+// untyped returns a schema accepting any value.
+func untyped() spec.Schema {
+	s := spec.Schema{}
+	s.AddExtension("x-kubernetes-preserve-unknown-fields", true)
+	return s
+}
+
+// schemalessObject returns the schema of an object whose fields aren't known.
+func schemalessObject() *spec.Schema {
+	s := ofType("object")
+	s.AddExtension("x-kubernetes-preserve-unknown-fields", true)
+	return &s
+}
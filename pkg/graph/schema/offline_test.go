@@ -0,0 +1,79 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestOfflineResolver(t *testing.T) {
+	preserveUnknownFields := true
+	crd := &extv1.CustomResourceDefinition{
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: "s3.services.k8s.aws",
+			Names: extv1.CustomResourceDefinitionNames{Kind: "Bucket"},
+			Versions: []extv1.CustomResourceDefinitionVersion{{
+				Name: "v1alpha1",
+				Schema: &extv1.CustomResourceValidation{
+					OpenAPIV3Schema: &extv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"spec": {Type: "object", XPreserveUnknownFields: &preserveUnknownFields},
+						},
+					},
+				},
+			}},
+		},
+	}
+	r, err := NewOfflineResolver([]*extv1.CustomResourceDefinition{crd})
+	require.NoError(t, err)
+
+	t.Run("built-in kind", func(t *testing.T) {
+		resolved, err := r.ResolveSchema(k8sschema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+		require.NoError(t, err)
+
+		spec := resolved.Properties["spec"]
+		assert.Equal(t, []string{"integer"}, []string(spec.Properties["replicas"].Type))
+		assert.Equal(t, []string{"object"}, []string(spec.Properties["selector"].Type))
+		metadata := resolved.Properties["metadata"]
+		assert.Equal(t, "date-time", metadata.Properties["creationTimestamp"].Format)
+
+		container := spec.Properties["template"].Properties["spec"].Properties["containers"].Items.Schema
+		limits := container.Properties["resources"].Properties["limits"]
+		assert.Equal(t, true, limits.AdditionalProperties.Schema.Extensions["x-kubernetes-int-or-string"])
+	})
+
+	t.Run("custom kind", func(t *testing.T) {
+		resolved, err := r.ResolveSchema(k8sschema.GroupVersionKind{
+			Group: "s3.services.k8s.aws", Version: "v1alpha1", Kind: "Bucket",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, true, resolved.Properties["spec"].Extensions["x-kubernetes-preserve-unknown-fields"])
+		assert.Equal(t, []string{"string"}, []string(resolved.Properties["apiVersion"].Type))
+		assert.Contains(t, resolved.Properties["metadata"].Properties, "labels")
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		gvk := k8sschema.GroupVersionKind{Group: "iam.services.k8s.aws", Version: "v1alpha1", Kind: "Policy"}
+		resolved, err := r.ResolveSchema(gvk)
+		require.NoError(t, err)
+		assert.Equal(t, true, resolved.Extensions["x-kubernetes-preserve-unknown-fields"])
+		assert.Equal(t, []k8sschema.GroupVersionKind{gvk}, r.Unresolved())
+	})
+}
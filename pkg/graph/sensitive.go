@@ -0,0 +1,164 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/cel/ast"
+	"github.com/kro-run/kro/pkg/simpleschema"
+)
+
+// secretGVR is the only destination sensitive fields can be written to
+// without a warning.
+var secretGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// sensitiveFieldPaths returns the paths of the sensitive fields declared in the
+// given schema, prefixed by the given path. Items of arrays and values of maps
+// are represented by a `*` segment, e.g `spec.tokens.*`.
+func sensitiveFieldPaths(fieldSchema *extv1.JSONSchemaProps, path string) []string {
+	if fieldSchema == nil {
+		return nil
+	}
+	if fieldSchema.Type == "string" && fieldSchema.Format == simpleschema.SensitiveFormat {
+		return []string{path}
+	}
+
+	var paths []string
+	names := make([]string, 0, len(fieldSchema.Properties))
+	for name := range fieldSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property := fieldSchema.Properties[name]
+		paths = append(paths, sensitiveFieldPaths(&property, path+"."+name)...)
+	}
+	if fieldSchema.Items != nil {
+		paths = append(paths, sensitiveFieldPaths(fieldSchema.Items.Schema, path+".*")...)
+	}
+	if fieldSchema.AdditionalProperties != nil {
+		paths = append(paths, sensitiveFieldPaths(fieldSchema.AdditionalProperties.Schema, path+".*")...)
+	}
+	return paths
+}
+
+// referencedSensitiveField returns the sensitive field exposed by the given
+// access path of a CEL expression, e.g `schema.spec.db.password`. Accessing a
+// parent of a sensitive field exposes it as well.
+func referencedSensitiveField(accessPath string, sensitiveFields []string) (string, bool) {
+	if accessPath != "schema" && !strings.HasPrefix(accessPath, "schema.") {
+		return "", false
+	}
+	accessPath = strings.TrimPrefix(strings.TrimPrefix(accessPath, "schema"), ".")
+
+	for _, field := range sensitiveFields {
+		// Expressions can't address a specific item or value of a collection
+		// by path, so we match against the collection itself.
+		base, _, _ := strings.Cut(field, ".*")
+		if accessPath == "" ||
+			accessPath == base ||
+			strings.HasPrefix(accessPath, base+".") ||
+			strings.HasPrefix(base, accessPath+".") {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// sensitiveFieldWarnings returns a warning for every expression exposing a
+// sensitive field of the instance spec to something other than a Secret,
+// including the status of the instance.
+func sensitiveFieldWarnings(
+	instance *Resource,
+	resources map[string]*Resource,
+	variables []string,
+	sensitiveFields []string,
+) ([]string, error) {
+	if len(sensitiveFields) == 0 {
+		return nil, nil
+	}
+
+	resourceIDs := make([]string, 0, len(resources)+1)
+	for id := range resources {
+		resourceIDs = append(resourceIDs, id)
+	}
+	sort.Strings(resourceIDs)
+	// We also want to allow users to refer to the instance spec in their expressions.
+	names := append(slices.Clone(resourceIDs), variables...)
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(names))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	inspector := ast.NewInspectorWithEnv(env, names, nil)
+
+	var warnings []string
+	check := func(destination string, resource *Resource) error {
+		for _, v := range resource.variables {
+			for _, expression := range v.Expressions {
+				inspection, err := inspector.Inspect(expression)
+				if err != nil {
+					return fmt.Errorf("failed to inspect expression %s: %w", expression, err)
+				}
+				for _, dependency := range inspection.ResourceDependencies {
+					if field, ok := referencedSensitiveField(dependency.Path, sensitiveFields); ok {
+						warnings = append(warnings, fmt.Sprintf(
+							"sensitive field %s is written to %s.%s, which is not a Secret",
+							field, destination, v.Path,
+						))
+						break
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, id := range resourceIDs {
+		resource := resources[id]
+		if resource.gvr == secretGVR {
+			continue
+		}
+		if err := check(id, resource); err != nil {
+			return nil, err
+		}
+	}
+	if err := check("instance", instance); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// instanceSpecSchema returns the schema of the spec of the given instance.
+func instanceSpecSchema(instance *Resource) *extv1.JSONSchemaProps {
+	if instance.crd == nil || len(instance.crd.Spec.Versions) == 0 {
+		return nil
+	}
+	validation := instance.crd.Spec.Versions[0].Schema
+	if validation == nil || validation.OpenAPIV3Schema == nil {
+		return nil
+	}
+	spec, ok := validation.OpenAPIV3Schema.Properties["spec"]
+	if !ok {
+		return nil
+	}
+	return &spec
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestSensitiveFieldPaths(t *testing.T) {
+	sensitive := extv1.JSONSchemaProps{Type: "string", Format: "password"}
+	schema := &extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"name": {Type: "string"},
+			"database": {
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"user":     {Type: "string"},
+					"password": sensitive,
+				},
+			},
+			"tokens": {Type: "array", Items: &extv1.JSONSchemaPropsOrArray{Schema: &sensitive}},
+			"apiKeys": {
+				Type:                 "object",
+				AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Schema: &sensitive},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"spec.apiKeys.*",
+		"spec.database.password",
+		"spec.tokens.*",
+	}, sensitiveFieldPaths(schema, "spec"))
+	assert.Empty(t, sensitiveFieldPaths(&extv1.JSONSchemaProps{Type: "string"}, "spec"))
+}
+
+func TestReferencedSensitiveField(t *testing.T) {
+	sensitiveFields := []string{"spec.database.password", "spec.tokens.*"}
+
+	tests := []struct {
+		accessPath string
+		want       string
+		wantOK     bool
+	}{
+		{"schema.spec.database.password", "spec.database.password", true},
+		{"schema.spec.database", "spec.database.password", true},
+		{"schema.spec", "spec.database.password", true},
+		{"schema", "spec.database.password", true},
+		{"schema.spec.tokens", "spec.tokens.*", true},
+		{"schema.spec.database.user", "", false},
+		{"schema.spec.databases", "", false},
+		{"deployment.spec.database.password", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accessPath, func(t *testing.T) {
+			got, ok := referencedSensitiveField(tt.accessPath, sensitiveFields)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// BuildOption configures how a resource graph definition is built.
+type BuildOption func(*buildOptions)
+
+type buildOptions struct {
+	// sharedTypes are custom simpleschema types available to the instance
+	// schema, in addition to the types it defines itself.
+	sharedTypes map[string]interface{}
+}
+
+// WithSharedTypes makes the given custom types available to the instance
+// schema. Types defined by the resource graph definition take precedence
+// over shared types with the same name.
+func WithSharedTypes(types map[string]interface{}) BuildOption {
+	return func(o *buildOptions) {
+		o.sharedTypes = types
+	}
+}
+
+// MergeTypeLibraries merges the types of the given type libraries into a
+// single set of types. A type name defined by more than one library is an
+// error, since kro cannot tell which definition should be used.
+func MergeTypeLibraries(libraries []v1alpha1.ResourceGraphTypeLibrary) (map[string]interface{}, error) {
+	// Sort the libraries to report conflicts deterministically.
+	sorted := make([]v1alpha1.ResourceGraphTypeLibrary, len(libraries))
+	copy(sorted, libraries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	types := map[string]interface{}{}
+	definedBy := map[string]string{}
+	for _, library := range sorted {
+		if len(library.Spec.Types.Raw) == 0 {
+			continue
+		}
+		libraryTypes := map[string]interface{}{}
+		if err := yaml.UnmarshalStrict(library.Spec.Types.Raw, &libraryTypes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal types of type library %s: %w", library.Name, err)
+		}
+		for name, definition := range libraryTypes {
+			if other, ok := definedBy[name]; ok {
+				return nil, fmt.Errorf("type %s is defined by both type libraries %s and %s", name, other, library.Name)
+			}
+			definedBy[name] = library.Name
+			types[name] = definition
+		}
+	}
+	return types, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+func newTypeLibrary(name, types string) v1alpha1.ResourceGraphTypeLibrary {
+	return v1alpha1.ResourceGraphTypeLibrary{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.ResourceGraphTypeLibrarySpec{
+			Types: runtime.RawExtension{Raw: []byte(types)},
+		},
+	}
+}
+
+func TestMergeTypeLibraries(t *testing.T) {
+	tests := []struct {
+		name      string
+		libraries []v1alpha1.ResourceGraphTypeLibrary
+		want      map[string]interface{}
+		wantErr   string
+	}{
+		{
+			name: "no libraries",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "types of all libraries are merged",
+			libraries: []v1alpha1.ResourceGraphTypeLibrary{
+				newTypeLibrary("networking", `{"Port": {"number": "integer | required=true"}}`),
+				newTypeLibrary("storage", `{"Volume": {"size": "quantity"}}`),
+			},
+			want: map[string]interface{}{
+				"Port":   map[string]interface{}{"number": "integer | required=true"},
+				"Volume": map[string]interface{}{"size": "quantity"},
+			},
+		},
+		{
+			name: "library without types",
+			libraries: []v1alpha1.ResourceGraphTypeLibrary{
+				{ObjectMeta: metav1.ObjectMeta{Name: "empty"}},
+			},
+			want: map[string]interface{}{},
+		},
+		{
+			name: "type defined by two libraries",
+			libraries: []v1alpha1.ResourceGraphTypeLibrary{
+				newTypeLibrary("storage", `{"Port": {"name": "string"}}`),
+				newTypeLibrary("networking", `{"Port": {"number": "integer"}}`),
+			},
+			wantErr: "type Port is defined by both type libraries networking and storage",
+		},
+		{
+			name: "invalid types",
+			libraries: []v1alpha1.ResourceGraphTypeLibrary{
+				newTypeLibrary("broken", `["Port"]`),
+			},
+			wantErr: "failed to unmarshal types of type library broken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeTypeLibraries(tt.libraries)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildInstanceSpecSchema_SharedTypes(t *testing.T) {
+	sharedTypes := map[string]interface{}{
+		"Port":   map[string]interface{}{"number": "integer | required=true"},
+		"Volume": map[string]interface{}{"size": "quantity"},
+	}
+	schema := &v1alpha1.Schema{
+		Spec: runtime.RawExtension{Raw: []byte(`{"port": "Port", "volume": "Volume"}`)},
+		// Types defined by the resource graph definition shadow shared types.
+		Types: runtime.RawExtension{Raw: []byte(`{"Volume": {"claimName": "string"}}`)},
+	}
+
+	got, _, _, err := buildInstanceSpecSchema(schema, sharedTypes, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"number"}, got.Properties["port"].Required)
+	assert.Contains(t, got.Properties["volume"].Properties, "claimName")
+	assert.NotContains(t, got.Properties["volume"].Properties, "size")
+
+	_, _, _, err = buildInstanceSpecSchema(schema, nil, nil)
+	assert.Error(t, err)
+}
@@ -39,6 +39,7 @@ var (
 		"context",
 		"dependency",
 		"dependencies",
+		"each",
 		"externalRef",
 		"externalReference",
 		"externalRefs",
@@ -61,6 +62,15 @@ var (
 		"variables",
 		"vars",
 		"version",
+		// namespaces of the CEL helper functions
+		"base64",
+		"cidr",
+		"hash",
+		"maps",
+		"names",
+		"regex",
+		"semver",
+		"yaml",
 	}
 )
 
@@ -124,6 +134,40 @@ func validateResourceIDs(rgd *v1alpha1.ResourceGraphDefinition) error {
 	return nil
 }
 
+// validateInstanceScope validates that the resources of cluster-scoped
+// instances can be created without the namespace of the instance: namespaced
+// resources and the sources of valuesFrom must set their namespace, and
+// service accounts, which are looked up by the namespace of the instance,
+// can't be used.
+func validateInstanceScope(rgd *v1alpha1.ResourceGraphDefinition, resources map[string]*Resource) error {
+	if rgd.Spec.Schema.Scope != v1alpha1.InstanceScopeCluster {
+		return nil
+	}
+	if len(rgd.Spec.DefaultServiceAccounts) > 0 {
+		return fmt.Errorf("defaultServiceAccounts are not supported for cluster-scoped instances")
+	}
+	if rgd.Spec.NamespaceSelector != nil {
+		return fmt.Errorf("namespaceSelector is not supported for cluster-scoped instances")
+	}
+	// The revisions are recorded in Secrets in the namespace of the instance.
+	if rgd.Spec.RollbackOnFailure {
+		return fmt.Errorf("rollbackOnFailure is not supported for cluster-scoped instances")
+	}
+	for _, source := range rgd.Spec.ValuesFrom {
+		for _, reference := range []*v1alpha1.ValuesReference{source.ConfigMapRef, source.SecretRef} {
+			if reference != nil && reference.Namespace == "" {
+				return fmt.Errorf("valuesFrom %s must set its namespace, instances are cluster-scoped", reference.Name)
+			}
+		}
+	}
+	for id, resource := range resources {
+		if resource.namespaced && resource.originalObject.GetNamespace() == "" {
+			return fmt.Errorf("resource %s is namespaced and must set metadata.namespace, instances are cluster-scoped", id)
+		}
+	}
+	return nil
+}
+
 // validateKubernetesObjectStructure checks if the given object is a Kubernetes object.
 // This is done by checking if the object has the following fields:
 // - apiVersion
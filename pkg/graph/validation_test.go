@@ -16,6 +16,8 @@ package graph
 import (
 	"testing"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
 	"github.com/kro-run/kro/api/v1alpha1"
 )
 
@@ -296,3 +298,82 @@ func TestValidateResourceGraphDefinitionNamingConventions(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateInstanceScope(t *testing.T) {
+	newResource := func(namespaced bool, namespace string) *Resource {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetNamespace(namespace)
+		return &Resource{namespaced: namespaced, originalObject: obj}
+	}
+
+	tests := []struct {
+		name                   string
+		scope                  v1alpha1.InstanceScope
+		defaultServiceAccounts map[string]string
+		rollbackOnFailure      bool
+		resource               *Resource
+		wantErr                bool
+	}{
+		{
+			name:     "Namespaced instances",
+			scope:    v1alpha1.InstanceScopeNamespaced,
+			resource: newResource(true, ""),
+			wantErr:  false,
+		},
+		{
+			name:     "Namespaced resource with namespace",
+			scope:    v1alpha1.InstanceScopeCluster,
+			resource: newResource(true, "${schema.spec.namespace}"),
+			wantErr:  false,
+		},
+		{
+			name:     "Cluster-scoped resource",
+			scope:    v1alpha1.InstanceScopeCluster,
+			resource: newResource(false, ""),
+			wantErr:  false,
+		},
+		{
+			name:     "Namespaced resource without namespace",
+			scope:    v1alpha1.InstanceScopeCluster,
+			resource: newResource(true, ""),
+			wantErr:  true,
+		},
+		{
+			name:                   "Default service accounts",
+			scope:                  v1alpha1.InstanceScopeCluster,
+			defaultServiceAccounts: map[string]string{v1alpha1.DefaultServiceAccountKey: "kro"},
+			resource:               newResource(false, ""),
+			wantErr:                true,
+		},
+		{
+			name:              "Rollback on failure",
+			scope:             v1alpha1.InstanceScopeCluster,
+			rollbackOnFailure: true,
+			resource:          newResource(false, ""),
+			wantErr:           true,
+		},
+		{
+			name:              "Rollback on failure of namespaced instances",
+			scope:             v1alpha1.InstanceScopeNamespaced,
+			rollbackOnFailure: true,
+			resource:          newResource(true, ""),
+			wantErr:           false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rgd := &v1alpha1.ResourceGraphDefinition{
+				Spec: v1alpha1.ResourceGraphDefinitionSpec{
+					Schema:                 &v1alpha1.Schema{Scope: tt.scope},
+					DefaultServiceAccounts: tt.defaultServiceAccounts,
+					RollbackOnFailure:      tt.rollbackOnFailure,
+				},
+			}
+			err := validateInstanceScope(rgd, map[string]*Resource{"resource": tt.resource})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateInstanceScope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
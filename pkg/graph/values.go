@@ -0,0 +1,163 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// ConfigMapGVR is the resource of the ConfigMaps values are loaded from.
+	ConfigMapGVR = k8sschema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	// SecretGVR is the resource of the Secrets values are loaded from.
+	SecretGVR = k8sschema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+)
+
+// RuntimeOption configures the runtime of an instance.
+type RuntimeOption func(*runtimeOptions)
+
+type runtimeOptions struct {
+	// values are the values loaded from the sources of valuesFrom.
+	values map[string]string
+}
+
+// WithValues exposes the given values to the expressions of the instance,
+// through the values variable. Without it, the values variable of graphs
+// with valuesFrom is empty.
+func WithValues(values map[string]string) RuntimeOption {
+	return func(o *runtimeOptions) {
+		o.values = values
+	}
+}
+
+// ValuesObject is a ConfigMap or Secret the values of an instance are loaded
+// from.
+type ValuesObject struct {
+	GVR       k8sschema.GroupVersionResource
+	Namespace string
+	Name      string
+	// Optional objects that don't exist are skipped.
+	Optional bool
+}
+
+// ValuesObjects returns the ConfigMaps and Secrets the values of the given
+// instance are loaded from, in the order of valuesFrom. Sources without
+// namespace default to the namespace of the instance.
+func (rgd *Graph) ValuesObjects(instance *unstructured.Unstructured) ([]ValuesObject, error) {
+	objects := make([]ValuesObject, 0, len(rgd.ValuesFrom))
+	for _, source := range rgd.ValuesFrom {
+		object := ValuesObject{GVR: ConfigMapGVR, Optional: source.Optional}
+		reference := source.ConfigMapRef
+		if source.SecretRef != nil {
+			object.GVR = SecretGVR
+			reference = source.SecretRef
+		}
+		if reference == nil {
+			return nil, fmt.Errorf("valuesFrom source must set configMapRef or secretRef")
+		}
+		object.Name = reference.Name
+		object.Namespace = reference.Namespace
+		if object.Namespace == "" {
+			object.Namespace = instance.GetNamespace()
+		}
+		if object.Namespace == "" {
+			return nil, fmt.Errorf("valuesFrom %s %s must set its namespace for cluster-scoped instances",
+				object.GVR.Resource, object.Name)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// LoadValues merges the keys of the given ConfigMaps and Secrets, in order,
+// the keys of later objects overriding the ones of earlier objects. The
+// binary data of ConfigMaps is ignored, and the data of Secrets is decoded.
+func LoadValues(objects []*unstructured.Unstructured) (map[string]string, error) {
+	values := map[string]string{}
+	for _, object := range objects {
+		data, _, err := unstructured.NestedStringMap(object.Object, "data")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the data of %s %s: %w", object.GetKind(), object.GetName(), err)
+		}
+		for key, value := range data {
+			if object.GetKind() == "Secret" {
+				decoded, err := base64.StdEncoding.DecodeString(value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode key %s of Secret %s: %w", key, object.GetName(), err)
+				}
+				value = string(decoded)
+			}
+			values[key] = value
+		}
+		if object.GetKind() == "Secret" {
+			// stringData is only set by the manifests of Secrets, it's
+			// merged into data by the API server.
+			stringData, _, _ := unstructured.NestedStringMap(object.Object, "stringData")
+			for key, value := range stringData {
+				values[key] = value
+			}
+		}
+	}
+	return values, nil
+}
+
+// ResolveValues loads the values of the given instance from the ConfigMaps
+// and Secrets among the given objects, for rendering instances without a
+// cluster. The objects without namespace are in the default namespace.
+func (rgd *Graph) ResolveValues(
+	instance *unstructured.Unstructured,
+	objects []*unstructured.Unstructured,
+) (map[string]string, error) {
+	sources, err := rgd.ValuesObjects(instance)
+	if err != nil {
+		return nil, err
+	}
+	var found []*unstructured.Unstructured
+	for _, source := range sources {
+		kind := "ConfigMap"
+		if source.GVR == SecretGVR {
+			kind = "Secret"
+		}
+		i := slices.IndexFunc(objects, func(object *unstructured.Unstructured) bool {
+			namespace := object.GetNamespace()
+			if namespace == "" {
+				namespace = metav1.NamespaceDefault
+			}
+			return object.GetAPIVersion() == "v1" && object.GetKind() == kind &&
+				namespace == source.Namespace && object.GetName() == source.Name
+		})
+		switch {
+		case i >= 0:
+			found = append(found, objects[i])
+		case !source.Optional:
+			return nil, fmt.Errorf("%s %s/%s of valuesFrom not found", kind, source.Namespace, source.Name)
+		}
+	}
+	return LoadValues(found)
+}
+
+// valuesVariable returns the values variable from the given values.
+func valuesVariable(values map[string]string) map[string]interface{} {
+	variable := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		variable[key] = value
+	}
+	return variable
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_Values(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	valuesFrom := func(sources ...v1alpha1.ValuesSource) generator.ResourceGraphDefinitionOption {
+		return func(rgd *v1alpha1.ResourceGraphDefinition) {
+			rgd.Spec.ValuesFrom = sources
+		}
+	}
+	secret := func(id string, data map[string]interface{}) generator.ResourceGraphDefinitionOption {
+		return generator.WithResource(id, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.name}-" + id,
+			},
+			"data": data,
+		}, nil, nil)
+	}
+	schemaOpt := generator.WithSchema("Test", "v1alpha1", map[string]interface{}{"name": "string"}, nil)
+	defaults := v1alpha1.ValuesSource{ConfigMapRef: &v1alpha1.ValuesReference{Name: "defaults"}}
+
+	t.Run("values variable", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("test",
+			schemaOpt,
+			valuesFrom(defaults),
+			secret("config", map[string]interface{}{
+				"region": "${values.region}",
+				"tier":   "${'tier' in values ? values.tier : 'standard'}",
+			}),
+		))
+		require.NoError(t, err)
+		assert.Empty(t, g.Resources["config"].GetDependencies())
+
+		rt, err := g.NewGraphRuntime(&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kro.run/v1alpha1",
+			"kind":       "Test",
+			"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+			"spec":       map[string]interface{}{"name": "app"},
+		}}, WithValues(map[string]string{"region": "eu-west-1"}))
+		require.NoError(t, err)
+		_, err = rt.Synchronize()
+		require.NoError(t, err)
+
+		resource, state := rt.GetResource("config")
+		require.Equal(t, runtime.ResourceStateResolved, state)
+		assert.Equal(t, map[string]interface{}{"region": "eu-west-1", "tier": "standard"}, resource.Object["data"])
+	})
+
+	t.Run("values variable without valuesFrom", func(t *testing.T) {
+		_, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("test",
+			schemaOpt,
+			secret("config", map[string]interface{}{"region": "${values.region}"}),
+		))
+		assert.ErrorContains(t, err, "undeclared reference to 'values'")
+	})
+
+	t.Run("resource named values", func(t *testing.T) {
+		_, err := builder.NewResourceGraphDefinition(generator.NewResourceGraphDefinition("test",
+			schemaOpt,
+			valuesFrom(defaults),
+			secret("values", nil),
+		))
+		assert.ErrorContains(t, err, "resource id values is reserved")
+	})
+}
+
+func TestGraph_ValuesObjects(t *testing.T) {
+	g := &Graph{ValuesFrom: []v1alpha1.ValuesSource{
+		{ConfigMapRef: &v1alpha1.ValuesReference{Name: "defaults"}},
+		{SecretRef: &v1alpha1.ValuesReference{Name: "credentials", Namespace: "shared"}, Optional: true},
+	}}
+
+	instance := &unstructured.Unstructured{}
+	instance.SetNamespace("team")
+	objects, err := g.ValuesObjects(instance)
+	require.NoError(t, err)
+	assert.Equal(t, []ValuesObject{
+		{GVR: ConfigMapGVR, Namespace: "team", Name: "defaults"},
+		{GVR: SecretGVR, Namespace: "shared", Name: "credentials", Optional: true},
+	}, objects)
+
+	_, err = g.ValuesObjects(&unstructured.Unstructured{})
+	assert.ErrorContains(t, err, "must set its namespace")
+}
+
+func TestLoadValues(t *testing.T) {
+	values, err := LoadValues([]*unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"kind": "ConfigMap",
+			"data": map[string]interface{}{"region": "us-east-1", "tier": "standard"},
+		}},
+		{Object: map[string]interface{}{
+			"kind": "Secret",
+			"data": map[string]interface{}{"tier": "cHJlbWl1bQ=="},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "us-east-1", "tier": "premium"}, values)
+
+	_, err = LoadValues([]*unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"kind": "Secret",
+			"data": map[string]interface{}{"tier": "not base64"},
+		}},
+	})
+	assert.ErrorContains(t, err, "failed to decode")
+}
@@ -41,6 +41,10 @@ type FieldDescriptor struct {
 	// that is not part of a larger string. example: "${foo}" is a standalone expression
 	// but not "hello-${foo}" or "${foo}${bar}"
 	StandaloneExpression bool
+	// Key is true if the expressions are in the key of a map entry, e.g a
+	// label key, rather than in its value. Path is then the path of the entry,
+	// and the expressions must return a string.
+	Key bool
 }
 
 // ResourceVariable represents a variable in a resource. Variables are any
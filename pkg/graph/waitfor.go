@@ -0,0 +1,59 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// expandWaitFor returns the given resources along with external references to
+// the objects they wait for, which are read like any other external reference
+// once their resource is reconciled. The resources depending on a resource
+// also wait for these objects, whose ids are returned by resource id. The
+// objects are read in the cluster of their resource.
+//
+// The given resources aren't modified.
+func expandWaitFor(rgResources []*v1alpha1.Resource) ([]*v1alpha1.Resource, map[string][]string, error) {
+	resources := make([]*v1alpha1.Resource, 0, len(rgResources))
+	waitedFor := make(map[string][]string)
+	for _, rgResource := range rgResources {
+		resources = append(resources, rgResource)
+		if len(rgResource.WaitFor) == 0 {
+			continue
+		}
+		if rgResource.ExternalRef != nil {
+			return nil, nil, fmt.Errorf("resource %s: can't declare waitFor with an externalRef", rgResource.ID)
+		}
+		if rgResource.ForEach != nil {
+			return nil, nil, fmt.Errorf("resource %s: can't declare waitFor with forEach", rgResource.ID)
+		}
+		for _, waitFor := range rgResource.WaitFor {
+			if waitFor.ID == "" {
+				return nil, nil, fmt.Errorf("resource %s: waitFor objects must declare an id", rgResource.ID)
+			}
+			ref := waitFor.ExternalRef
+			resources = append(resources, &v1alpha1.Resource{
+				ID:          waitFor.ID,
+				ExternalRef: &ref,
+				ReadyWhen:   waitFor.ReadyWhen,
+				DependsOn:   []string{rgResource.ID},
+				Target:      rgResource.Target,
+			})
+			waitedFor[rgResource.ID] = append(waitedFor[rgResource.ID], waitFor.ID)
+		}
+	}
+	return resources, waitedFor, nil
+}
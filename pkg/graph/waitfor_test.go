@@ -0,0 +1,109 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/emulator"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+	"github.com/kro-run/kro/pkg/testutil/k8s"
+)
+
+func TestGraphBuilder_WaitFor(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func(waitFor []*v1alpha1.WaitFor) *v1alpha1.ResourceGraphDefinition {
+		rgd := generator.NewResourceGraphDefinition("testrgd",
+			generator.WithSchema(
+				"Test", "v1alpha1",
+				map[string]interface{}{"name": "string"},
+				nil,
+			),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+			}, nil, nil),
+			generator.WithResource("subnet", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "Subnet",
+				"metadata": map[string]interface{}{
+					"name": "${schema.spec.name}",
+				},
+				"spec": map[string]interface{}{
+					"vpcID": "${vpc.status.vpcID}",
+				},
+			}, nil, nil),
+		)
+		rgd.Spec.Resources[0].WaitFor = waitFor
+		return rgd
+	}
+	credentials := func(id string) *v1alpha1.WaitFor {
+		return &v1alpha1.WaitFor{
+			ID: id,
+			ExternalRef: v1alpha1.ExternalRef{
+				APIVersion: "v1",
+				Kind:       "Secret",
+				Metadata:   v1alpha1.ExternalRefMetadata{Name: "${vpc.status.vpcID}"},
+			},
+			ReadyWhen: []string{"${has(" + id + ".data.password)}"},
+		}
+	}
+
+	t.Run("waits for the objects before the dependents", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(newRGD([]*v1alpha1.WaitFor{credentials("credentials")}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"vpc", "credentials", "subnet"}, g.TopologicalOrder)
+		assert.True(t, g.Resources["credentials"].IsExternalRef())
+		assert.Equal(t, []string{"vpc"}, g.Resources["credentials"].GetDependencies())
+		assert.ElementsMatch(t, []string{"vpc", "credentials"}, g.Resources["subnet"].GetDependencies())
+		assert.Equal(t, []string{"has(credentials.data.password)"}, g.Resources["credentials"].GetReadyWhenExpressions())
+	})
+
+	tests := []struct {
+		name    string
+		waitFor []*v1alpha1.WaitFor
+		wantErr string
+	}{
+		{
+			name:    "object without id",
+			waitFor: []*v1alpha1.WaitFor{credentials("")},
+			wantErr: "waitFor objects must declare an id",
+		},
+		{
+			name:    "object with the id of a resource",
+			waitFor: []*v1alpha1.WaitFor{credentials("subnet")},
+			wantErr: "found resources with duplicate id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := builder.NewResourceGraphDefinition(newRGD(tt.waitFor))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
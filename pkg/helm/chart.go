@@ -0,0 +1,232 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package helm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// chartMetadata is the content of the Chart.yaml file of a chart.
+type chartMetadata struct {
+	APIVersion   string            `json:"apiVersion"`
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	AppVersion   string            `json:"appVersion"`
+	Description  string            `json:"description"`
+	Type         string            `json:"type"`
+	KubeVersion  string            `json:"kubeVersion"`
+	Annotations  map[string]string `json:"annotations"`
+	Dependencies []struct {
+		Name string `json:"name"`
+	} `json:"dependencies"`
+}
+
+// chart is a Helm chart, read from a directory or an archive.
+type chart struct {
+	metadata chartMetadata
+	values   map[string]interface{}
+	// files are the files of the chart, keyed by their path relative to the
+	// chart root, e.g templates/deployment.yaml.
+	files map[string][]byte
+}
+
+// loadChart reads the chart of the given directory or archive.
+func loadChart(chartPath string) (*chart, error) {
+	info, err := os.Stat(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	var files map[string][]byte
+	if info.IsDir() {
+		files, err = readChartDirectory(chartPath)
+	} else {
+		files, err = readChartArchive(chartPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &chart{files: files}
+	metadata, ok := files["Chart.yaml"]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a Helm chart, Chart.yaml not found", chartPath)
+	}
+	if err := yaml.Unmarshal(metadata, &c.metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse Chart.yaml: %w", err)
+	}
+	if c.metadata.Name == "" {
+		return nil, fmt.Errorf("chart name is missing from Chart.yaml")
+	}
+	c.values, err = parseValues(files["values.yaml"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse values.yaml: %w", err)
+	}
+	return c, nil
+}
+
+func readChartDirectory(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+// readChartArchive reads a packaged chart, whose files are all in a directory
+// named after the chart.
+func readChartArchive(file string) (map[string][]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart archive %s: %w", file, err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart archive %s: %w", file, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		_, rel, ok := strings.Cut(name, "/")
+		if !ok {
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		files[rel] = data
+	}
+}
+
+// parseValues parses the given values, keeping the integers apart from the
+// floating point numbers.
+func parseValues(data []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return values, nil
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, err
+	}
+	if parsed == nil {
+		return values, nil
+	}
+	object, ok := convertNumbers(parsed).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %T", parsed)
+	}
+	return object, nil
+}
+
+func convertNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = convertNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertNumbers(item)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+// templates returns the paths of the templates of the chart rendering
+// resources, in the order Helm renders them. The partials, whose name starts
+// with an underscore, only define named templates.
+func (c *chart) templates() (rendered, partials []string) {
+	for name := range c.files {
+		if !strings.HasPrefix(name, "templates/") {
+			continue
+		}
+		base := path.Base(name)
+		switch {
+		case strings.HasPrefix(base, "_"):
+			partials = append(partials, name)
+		case base == "NOTES.txt":
+		default:
+			rendered = append(rendered, name)
+		}
+	}
+	slices.Sort(rendered)
+	slices.Sort(partials)
+	return rendered, partials
+}
+
+// hasDependencies returns true if the chart has subcharts.
+func (c *chart) hasDependencies() bool {
+	if len(c.metadata.Dependencies) > 0 {
+		return true
+	}
+	for name := range c.files {
+		if strings.HasPrefix(name, "charts/") {
+			return true
+		}
+	}
+	return false
+}
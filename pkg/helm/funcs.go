@@ -0,0 +1,807 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"sigs.k8s.io/yaml"
+)
+
+// errUnsupported is returned by the functions which can't be evaluated when
+// importing a chart.
+var errUnsupported = errors.New("not supported when importing a chart")
+
+// funcs returns the functions of the templates: the functions of Helm, and the
+// most common functions of the Sprig library. The functions given markers
+// instead of strings keep them, so that the values are still read from the
+// instance: the arithmetic returns expressions, and the comparisons compare the
+// defaults.
+func (r *renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		// Helm
+		"include": r.include,
+		"tpl":     r.tpl,
+		"required": func(message string, v interface{}) (interface{}, error) {
+			if isEmpty(v) {
+				return nil, errors.New(message)
+			}
+			return v, nil
+		},
+		"fail":          func(message string) (string, error) { return "", errors.New(message) },
+		"lookup":        func(...interface{}) map[string]interface{} { return map[string]interface{}{} },
+		"toYaml":        r.toYaml,
+		"toJson":        r.toJSON,
+		"toPrettyJson":  r.toJSON,
+		"fromYaml":      fromYAML,
+		"fromJson":      fromYAML,
+		"semverCompare": semverCompare,
+
+		// Strings
+		"quote":    func(v ...interface{}) string { return r.joinStrings(v, `"%s"`, strconv.Quote) },
+		"squote":   func(v ...interface{}) string { return r.joinStrings(v, `'%s'`, nil) },
+		"toString": r.toString,
+		"cat":      func(v ...interface{}) string { return r.joinStrings(v, "%s", nil) },
+		"printf":   r.printf,
+		"upper":    r.deriveFunc(strings.ToUpper, func(e string) string { return e + ".upperAscii()" }),
+		"lower":    r.deriveFunc(strings.ToLower, func(e string) string { return e + ".lowerAscii()" }),
+		"trim":     r.deriveFunc(strings.TrimSpace, func(e string) string { return e + ".trim()" }),
+		"b64enc": r.deriveFunc(func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+			func(e string) string { return "base64.encode(bytes(" + e + "))" }),
+		"replace": func(old, replacement, s string) string {
+			return r.tracker.derive(s, func(s string) string { return strings.ReplaceAll(s, old, replacement) },
+				func(e string) string {
+					return e + ".replace(" + strconv.Quote(old) + ", " + strconv.Quote(replacement) + ")"
+				})
+		},
+		"title":      r.withoutMarkers("title", title),
+		"trimAll":    func(cutset, s string) string { return strings.Trim(s, cutset) },
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"trunc":      trunc,
+		"contains": func(substr, s string) bool {
+			return strings.Contains(r.tracker.resolveString(s), r.tracker.resolveString(substr))
+		},
+		"hasPrefix": func(prefix, s string) bool {
+			return strings.HasPrefix(r.tracker.resolveString(s), r.tracker.resolveString(prefix))
+		},
+		"hasSuffix": func(suffix, s string) bool {
+			return strings.HasSuffix(r.tracker.resolveString(s), r.tracker.resolveString(suffix))
+		},
+		"repeat":    func(count int, s string) string { return strings.Repeat(s, count) },
+		"nospace":   r.withoutMarkers("nospace", func(s string) string { return strings.Join(strings.Fields(s), "") }),
+		"indent":    indent,
+		"nindent":   func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"splitList": func(sep, s string) []interface{} { return toList(strings.Split(s, sep)) },
+		"join":      join,
+		"kebabcase": r.withoutMarkers("kebabcase", func(s string) string { return caseWords(s, "-") }),
+		"snakecase": r.withoutMarkers("snakecase", func(s string) string { return caseWords(s, "_") }),
+		"camelcase": r.withoutMarkers("camelcase", pascalCase),
+		"b64dec": r.withoutMarkers("b64dec", func(s string) string {
+			data, _ := base64.StdEncoding.DecodeString(s)
+			return string(data)
+		}),
+		"sha256sum": r.withoutMarkers("sha256sum", func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}),
+		"regexMatch": func(expr, s string) (bool, error) { return regexp.MatchString(expr, r.tracker.resolveString(s)) },
+		"regexReplaceAll": func(expr, s, replacement string) (string, error) {
+			if markerRegexp.MatchString(s) {
+				return "", fmt.Errorf("regexReplaceAll on values is %w", errUnsupported)
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return "", err
+			}
+			return re.ReplaceAllString(s, replacement), nil
+		},
+
+		// Comparisons, of the defaults of the values.
+		"eq": func(a interface{}, b ...interface{}) bool {
+			return slices.ContainsFunc(b, func(v interface{}) bool {
+				order, err := r.compare(a, v)
+				return err == nil && order == 0
+			})
+		},
+		"ne": func(a, b interface{}) bool {
+			order, err := r.compare(a, b)
+			return err != nil || order != 0
+		},
+		"lt": r.comparison(func(order int) bool { return order < 0 }),
+		"le": r.comparison(func(order int) bool { return order <= 0 }),
+		"gt": r.comparison(func(order int) bool { return order > 0 }),
+		"ge": r.comparison(func(order int) bool { return order >= 0 }),
+
+		// Defaults and conditions
+		"default": func(defaultValue interface{}, given ...interface{}) interface{} {
+			if len(given) == 0 || isEmpty(given[0]) {
+				return defaultValue
+			}
+			return given[0]
+		},
+		"empty": isEmpty,
+		"coalesce": func(v ...interface{}) interface{} {
+			for _, item := range v {
+				if !isEmpty(item) {
+					return item
+				}
+			}
+			return nil
+		},
+		"ternary": func(whenTrue, whenFalse interface{}, condition bool) interface{} {
+			if condition {
+				return whenTrue
+			}
+			return whenFalse
+		},
+
+		// Types
+		"int":     r.toInt,
+		"int64":   r.toInt,
+		"atoi":    r.toInt,
+		"float64": r.toFloat,
+		"kindOf":  kindOf,
+		"kindIs":  func(kind string, v interface{}) bool { return kindOf(v) == kind },
+		"typeOf":  func(v interface{}) string { return fmt.Sprintf("%T", v) },
+		"toStrings": func(v interface{}) []string {
+			var strs []string
+			for _, item := range toSlice(v) {
+				strs = append(strs, r.toString(item))
+			}
+			return strs
+		},
+
+		// Lists
+		"list":    func(v ...interface{}) []interface{} { return v },
+		"append":  func(list interface{}, v interface{}) []interface{} { return append(toSlice(list), v) },
+		"prepend": func(list interface{}, v interface{}) []interface{} { return append([]interface{}{v}, toSlice(list)...) },
+		"first": func(list interface{}) interface{} {
+			if items := toSlice(list); len(items) > 0 {
+				return items[0]
+			}
+			return nil
+		},
+		"last": func(list interface{}) interface{} {
+			if items := toSlice(list); len(items) > 0 {
+				return items[len(items)-1]
+			}
+			return nil
+		},
+		"rest": func(list interface{}) []interface{} {
+			if items := toSlice(list); len(items) > 0 {
+				return items[1:]
+			}
+			return nil
+		},
+		"has": func(needle, list interface{}) bool {
+			return slices.ContainsFunc(toSlice(list), func(item interface{}) bool { return reflect.DeepEqual(item, needle) })
+		},
+		"uniq": func(list interface{}) []interface{} {
+			var unique []interface{}
+			for _, item := range toSlice(list) {
+				if !slices.ContainsFunc(unique, func(u interface{}) bool { return reflect.DeepEqual(u, item) }) {
+					unique = append(unique, item)
+				}
+			}
+			return unique
+		},
+		"compact": func(list interface{}) []interface{} {
+			var compacted []interface{}
+			for _, item := range toSlice(list) {
+				if !isEmpty(item) {
+					compacted = append(compacted, item)
+				}
+			}
+			return compacted
+		},
+		"concat": func(lists ...interface{}) []interface{} {
+			var concatenated []interface{}
+			for _, list := range lists {
+				concatenated = append(concatenated, toSlice(list)...)
+			}
+			return concatenated
+		},
+		"sortAlpha": func(list interface{}) []string {
+			var strs []string
+			for _, item := range toSlice(list) {
+				strs = append(strs, fmt.Sprint(item))
+			}
+			sort.Strings(strs)
+			return strs
+		},
+		"until": func(count int) []int {
+			seq := make([]int, 0, max(count, 0))
+			for i := 0; i < count; i++ {
+				seq = append(seq, i)
+			}
+			return seq
+		},
+
+		// Dictionaries
+		"dict": func(v ...interface{}) map[string]interface{} {
+			dict := make(map[string]interface{}, len(v)/2)
+			for i := 0; i+1 < len(v); i += 2 {
+				dict[fmt.Sprint(v[i])] = v[i+1]
+			}
+			return dict
+		},
+		"set": func(dict map[string]interface{}, key string, v interface{}) map[string]interface{} {
+			dict[key] = v
+			return dict
+		},
+		"unset": func(dict map[string]interface{}, key string) map[string]interface{} {
+			delete(dict, key)
+			return dict
+		},
+		"hasKey": func(dict map[string]interface{}, key string) bool {
+			_, ok := dict[key]
+			return ok
+		},
+		"get": func(dict map[string]interface{}, key string) interface{} {
+			if v, ok := dict[key]; ok {
+				return v
+			}
+			return ""
+		},
+		"keys": func(dicts ...map[string]interface{}) []string {
+			var keys []string
+			for _, dict := range dicts {
+				for key := range dict {
+					keys = append(keys, key)
+				}
+			}
+			return keys
+		},
+		"pick": func(dict map[string]interface{}, keys ...string) map[string]interface{} {
+			picked := make(map[string]interface{})
+			for _, key := range keys {
+				if v, ok := dict[key]; ok {
+					picked[key] = v
+				}
+			}
+			return picked
+		},
+		"omit": func(dict map[string]interface{}, keys ...string) map[string]interface{} {
+			omitted := make(map[string]interface{})
+			for key, v := range dict {
+				if !slices.Contains(keys, key) {
+					omitted[key] = v
+				}
+			}
+			return omitted
+		},
+		"merge":          merge(false),
+		"mergeOverwrite": merge(true),
+		"deepCopy":       func(v interface{}) interface{} { return v },
+		"dig": func(args ...interface{}) (interface{}, error) {
+			if len(args) < 3 {
+				return nil, fmt.Errorf("dig expects at least 3 arguments")
+			}
+			dict, ok := args[len(args)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("dig expects a dictionary")
+			}
+			v := interface{}(dict)
+			for _, key := range args[:len(args)-2] {
+				object, ok := v.(map[string]interface{})
+				if !ok {
+					return args[len(args)-2], nil
+				}
+				if v, ok = object[fmt.Sprint(key)]; !ok {
+					return args[len(args)-2], nil
+				}
+			}
+			return v, nil
+		},
+
+		// Arithmetic
+		"add": r.arithmetic("+", func(a, b int64) (int64, error) { return a + b, nil }),
+		"sub": r.arithmetic("-", func(a, b int64) (int64, error) { return a - b, nil }),
+		"mul": r.arithmetic("*", func(a, b int64) (int64, error) { return a * b, nil }),
+		"div": r.arithmetic("/", func(a, b int64) (int64, error) {
+			if b == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return a / b, nil
+		}),
+		"mod": r.arithmetic("%", func(a, b int64) (int64, error) {
+			if b == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return a % b, nil
+		}),
+		"max": r.arithmetic("", func(a, b int64) (int64, error) { return max(a, b), nil }),
+		"min": r.arithmetic("", func(a, b int64) (int64, error) { return min(a, b), nil }),
+		"add1": func(v interface{}) (interface{}, error) {
+			return r.arithmetic("+", func(a, b int64) (int64, error) { return a + b, nil })(v, 1)
+		},
+
+		// Functions whose result can't be reproduced by kro.
+		"randAlphaNum":      unsupported("randAlphaNum"),
+		"randAlpha":         unsupported("randAlpha"),
+		"randNumeric":       unsupported("randNumeric"),
+		"uuidv4":            unsupported("uuidv4"),
+		"now":               unsupported("now"),
+		"genCA":             unsupported("genCA"),
+		"genSignedCert":     unsupported("genSignedCert"),
+		"genSelfSignedCert": unsupported("genSelfSignedCert"),
+	}
+}
+
+func unsupported(name string) func(...interface{}) (string, error) {
+	return func(...interface{}) (string, error) {
+		return "", fmt.Errorf("%s is %w", name, errUnsupported)
+	}
+}
+
+// include renders the named template with the given data.
+func (r *renderer) include(name string, data interface{}) (string, error) {
+	var b bytes.Buffer
+	if err := r.tmpl.ExecuteTemplate(&b, name, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// tpl renders the given template text with the given data.
+func (r *renderer) tpl(text string, data interface{}) (string, error) {
+	t, err := r.tmpl.New("tpl").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// toYaml returns the YAML of the given value. The maps and lists of the values
+// are read from the instance as a whole.
+func (r *renderer) toYaml(v interface{}) (string, error) {
+	if marker := r.tracker.collectionMarker(v); r.collectionMarkers && marker != "" {
+		return marker, nil
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func (r *renderer) toJSON(v interface{}) (string, error) {
+	if marker := r.tracker.collectionMarker(v); r.collectionMarkers && marker != "" {
+		return marker, nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func fromYAML(s string) map[string]interface{} {
+	object := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(s), &object); err != nil {
+		object["Error"] = err.Error()
+	}
+	return object
+}
+
+// toString returns the given value as a string. The markers of the values
+// which aren't strings are converted to string markers.
+func (r *renderer) toString(v interface{}) string {
+	if marker, ok := r.tracker.stringMarker(v); ok {
+		return marker
+	}
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// joinStrings formats each of the non-nil values with the given format, and
+// joins them with spaces.
+func (r *renderer) joinStrings(values []interface{}, format string, escape func(string) string) string {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		s := r.toString(v)
+		if escape != nil {
+			strs = append(strs, escape(s))
+			continue
+		}
+		strs = append(strs, fmt.Sprintf(format, s))
+	}
+	return strings.Join(strs, " ")
+}
+
+// int64 converts the given value, or the default of the given marker, to an
+// integer.
+func (r *renderer) int64(v interface{}) int64 {
+	switch v := r.tracker.resolve(v).(type) {
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case string:
+		i, _ := strconv.ParseInt(v, 10, 64)
+		return i
+	default:
+		return 0
+	}
+}
+
+// toInt converts the given value to an integer. The markers are converted by
+// their expression.
+func (r *renderer) toInt(v interface{}) interface{} {
+	if value := r.tracker.lookup(v); value != nil {
+		if value.schemaType == "integer" {
+			return v
+		}
+		return r.tracker.marker("int("+value.expression+")", "integer", r.int64(v))
+	}
+	return int(r.int64(v))
+}
+
+// toFloat converts the given value to a floating point number. The markers are
+// converted by their expression.
+func (r *renderer) toFloat(v interface{}) interface{} {
+	f := 0.0
+	switch resolved := r.tracker.resolve(v).(type) {
+	case int:
+		f = float64(resolved)
+	case int64:
+		f = float64(resolved)
+	case float64:
+		f = resolved
+	case string:
+		f, _ = strconv.ParseFloat(resolved, 64)
+	}
+	if value := r.tracker.lookup(v); value != nil {
+		if value.schemaType == "float" {
+			return v
+		}
+		return r.tracker.marker("double("+value.expression+")", "float", f)
+	}
+	return f
+}
+
+// arithmetic returns a function applying the given operation to integers. If
+// some of the integers are markers, the result is the marker of the
+// expression applying the given operator, or an error if the operation has no
+// operator.
+func (r *renderer) arithmetic(operator string, op func(a, b int64) (int64, error)) func(...interface{}) (interface{}, error) {
+	return func(values ...interface{}) (interface{}, error) {
+		if len(values) == 0 {
+			return int64(0), nil
+		}
+		result := r.int64(values[0])
+		for _, v := range values[1:] {
+			var err error
+			if result, err = op(result, r.int64(v)); err != nil {
+				return nil, err
+			}
+		}
+		if !slices.ContainsFunc(values, func(v interface{}) bool { return r.tracker.lookup(v) != nil }) {
+			return result, nil
+		}
+		if operator == "" {
+			return nil, fmt.Errorf("this arithmetic on values is %w", errUnsupported)
+		}
+		operands := make([]string, 0, len(values))
+		for _, v := range values {
+			value := r.tracker.lookup(v)
+			switch {
+			case value == nil:
+				operands = append(operands, strconv.FormatInt(r.int64(v), 10))
+			case value.schemaType == "integer":
+				operands = append(operands, value.expression)
+			default:
+				operands = append(operands, "int("+value.expression+")")
+			}
+		}
+		return r.tracker.marker("("+strings.Join(operands, " "+operator+" ")+")", "integer", result), nil
+	}
+}
+
+// compare compares the given values, or the defaults of the given markers.
+func (r *renderer) compare(a, b interface{}) (int, error) {
+	a, b = r.tracker.resolve(a), r.tracker.resolve(b)
+	if fa, ok := toNumber(a); ok {
+		if fb, ok := toNumber(b); ok {
+			return cmp.Compare(fa, fb), nil
+		}
+	}
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if okA && okB {
+		return strings.Compare(sa, sb), nil
+	}
+	if reflect.DeepEqual(a, b) {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("incompatible types for comparison: %T and %T", a, b)
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// comparison returns a function comparing two values with the given test.
+func (r *renderer) comparison(test func(int) bool) func(a, b interface{}) (bool, error) {
+	return func(a, b interface{}) (bool, error) {
+		order, err := r.compare(a, b)
+		if err != nil {
+			return false, err
+		}
+		return test(order), nil
+	}
+}
+
+// markerArg formats a marker as is, whatever the verb, so that printf keeps
+// the markers of the values which aren't strings.
+type markerArg string
+
+func (m markerArg) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, string(m))
+}
+
+func (r *renderer) printf(format string, args ...interface{}) string {
+	for i, arg := range args {
+		if r.tracker.lookup(arg) != nil {
+			args[i] = markerArg(arg.(string))
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// deriveFunc returns a string function, applying the given expression to the
+// strings holding markers.
+func (r *renderer) deriveFunc(fn func(string) string, expression func(string) string) func(string) string {
+	return func(s string) string {
+		return r.tracker.derive(s, fn, expression)
+	}
+}
+
+// withoutMarkers returns a string function failing on the strings holding
+// markers, for the functions which have no equivalent expression.
+func (r *renderer) withoutMarkers(name string, fn func(string) string) func(string) (string, error) {
+	return func(s string) (string, error) {
+		if markerRegexp.MatchString(s) {
+			return "", fmt.Errorf("%s on values is %w", name, errUnsupported)
+		}
+		return fn(s), nil
+	}
+}
+
+// isEmpty returns true if the given value is empty, as defined by Sprig.
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+func kindOf(v interface{}) string {
+	if v == nil {
+		return "invalid"
+	}
+	return reflect.ValueOf(v).Kind().String()
+}
+
+func toSlice(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items
+}
+
+func toList(strs []string) []interface{} {
+	items := make([]interface{}, len(strs))
+	for i, s := range strs {
+		items[i] = s
+	}
+	return items
+}
+
+func join(sep string, v interface{}) string {
+	items := toSlice(v)
+	strs := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			strs = append(strs, fmt.Sprint(item))
+		}
+	}
+	return strings.Join(strs, sep)
+}
+
+func merge(overwrite bool) func(map[string]interface{}, ...map[string]interface{}) map[string]interface{} {
+	var mergeInto func(dst, src map[string]interface{})
+	mergeInto = func(dst, src map[string]interface{}) {
+		for key, v := range src {
+			existing, ok := dst[key]
+			dstObject, dstIsObject := existing.(map[string]interface{})
+			srcObject, srcIsObject := v.(map[string]interface{})
+			switch {
+			case ok && dstIsObject && srcIsObject:
+				mergeInto(dstObject, srcObject)
+			case !ok || overwrite:
+				dst[key] = v
+			}
+		}
+	}
+	return func(dst map[string]interface{}, srcs ...map[string]interface{}) map[string]interface{} {
+		for _, src := range srcs {
+			mergeInto(dst, src)
+		}
+		return dst
+	}
+}
+
+func trunc(length int, s string) string {
+	switch {
+	case length < 0 && len(s)+length > 0:
+		return s[len(s)+length:]
+	case length >= 0 && len(s) > length:
+		return s[:length]
+	default:
+		return s
+	}
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func title(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// caseWords splits the given camelCase or PascalCase string into lowercase
+// words, joined by the given separator.
+func caseWords(s, sep string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// semverCompare returns true if the given version satisfies the given
+// constraints, e.g `>=1.19-0`. Constraints separated by commas or spaces must
+// all be satisfied, and constraints separated by || are alternatives.
+func semverCompare(constraints, version string) (bool, error) {
+	v, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	for _, alternative := range strings.Split(constraints, "||") {
+		satisfied := true
+		for _, constraint := range strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' }) {
+			op := constraint[:len(constraint)-len(strings.TrimLeft(constraint, "<>=!~^"))]
+			c, err := parseVersion(strings.TrimPrefix(constraint, op))
+			if err != nil {
+				return false, err
+			}
+			order := slices.Compare(v[:], c[:])
+			switch op {
+			case ">=":
+				satisfied = satisfied && order >= 0
+			case ">":
+				satisfied = satisfied && order > 0
+			case "<=":
+				satisfied = satisfied && order <= 0
+			case "<":
+				satisfied = satisfied && order < 0
+			case "!=":
+				satisfied = satisfied && order != 0
+			case "^":
+				satisfied = satisfied && order >= 0 && v[0] == c[0]
+			case "~":
+				satisfied = satisfied && order >= 0 && v[0] == c[0] && v[1] == c[1]
+			default:
+				satisfied = satisfied && order == 0
+			}
+		}
+		if satisfied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseVersion parses the major, minor and patch numbers of a version, e.g
+// v1.31.0-eks-1234, ignoring its pre-release and build metadata.
+func parseVersion(version string) ([3]int64, error) {
+	var v [3]int64
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	for i, part := range strings.SplitN(version, ".", 3) {
+		if part == "x" || part == "*" {
+			break
+		}
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q", version)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
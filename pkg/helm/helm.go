@@ -0,0 +1,491 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package helm converts Helm charts to ResourceGraphDefinitions, to ease the
+// migration of existing charts to kro.
+//
+// The conversion is best-effort: the values of the chart become the fields of
+// the instance spec, with their values as defaults, and the templates are
+// rendered into the resources of the ResourceGraphDefinition, where the values
+// they read are replaced by expressions reading the instance. The templates
+// are rendered once, with the default values, so the resources of the
+// conditions not met by the defaults are not imported, and the values only
+// read by conditions are not bound to the resources. The warnings of the
+// import list what needs to be reviewed.
+package helm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// DefaultKubernetesVersion is the version of the cluster the templates see.
+const DefaultKubernetesVersion = "v1.31.0"
+
+// Options configures the import of a chart.
+type Options struct {
+	// Name is the name of the ResourceGraphDefinition. Defaults to the name of
+	// the chart.
+	Name string
+	// Kind is the kind of the instances. Defaults to the name of the chart in
+	// PascalCase, e.g my-app becomes MyApp.
+	Kind string
+	// APIVersion is the version of the instances. Defaults to v1alpha1.
+	APIVersion string
+	// Group is the group of the instances. Defaults to kro.run.
+	Group string
+	// KubernetesVersion is the version of the cluster the templates see, in
+	// .Capabilities. Defaults to DefaultKubernetesVersion.
+	KubernetesVersion string
+}
+
+// Result is the result of the import of a chart.
+type Result struct {
+	// ResourceGraphDefinition is the ResourceGraphDefinition converted from
+	// the chart.
+	ResourceGraphDefinition *v1alpha1.ResourceGraphDefinition
+	// Warnings are the parts of the chart which couldn't be converted, and
+	// need to be reviewed.
+	Warnings []string
+}
+
+// Import converts the chart of the given directory or archive to a
+// ResourceGraphDefinition.
+func Import(chartPath string, opts Options) (*Result, error) {
+	c, err := loadChart(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Name == "" {
+		opts.Name = c.metadata.Name
+	}
+	if opts.Kind == "" {
+		opts.Kind = pascalCase(c.metadata.Name)
+	}
+	if opts.APIVersion == "" {
+		opts.APIVersion = "v1alpha1"
+	}
+	if opts.KubernetesVersion == "" {
+		opts.KubernetesVersion = DefaultKubernetesVersion
+	}
+
+	result := &Result{}
+	if c.metadata.Type == "library" {
+		return nil, fmt.Errorf("chart %s is a library chart, which has no resources", c.metadata.Name)
+	}
+	if c.hasDependencies() {
+		result.Warnings = append(result.Warnings, "the subcharts of the chart are not imported, "+
+			"import them separately and reference them as resources")
+	}
+
+	r, err := newRenderer(c, opts.KubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+	resources, warnings := r.renderResources()
+	result.Warnings = append(result.Warnings, warnings...)
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("no resource rendered from the templates of chart %s", c.metadata.Name)
+	}
+	if unused := r.tracker.unused(c.values); len(unused) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("the following values are not read by the "+
+			"resources, they may be read by conditions, or by the resources which couldn't be imported: %s",
+			strings.Join(unused, ", ")))
+	}
+
+	builder := &schemaBuilder{types: make(map[string]interface{})}
+	spec := builder.build(c.values, nil)
+	result.Warnings = append(result.Warnings, builder.warnings...)
+
+	schema := &v1alpha1.Schema{
+		Kind:       opts.Kind,
+		APIVersion: opts.APIVersion,
+		Group:      opts.Group,
+	}
+	if schema.Spec.Raw, err = json.Marshal(spec); err != nil {
+		return nil, err
+	}
+	if len(builder.types) > 0 {
+		if schema.Types.Raw, err = json.Marshal(builder.types); err != nil {
+			return nil, err
+		}
+	}
+	rgd := &v1alpha1.ResourceGraphDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "ResourceGraphDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name},
+		Spec: v1alpha1.ResourceGraphDefinitionSpec{
+			Schema:    schema,
+			Resources: resources,
+		},
+	}
+	result.ResourceGraphDefinition = rgd
+	return result, nil
+}
+
+// renderer renders the templates of a chart.
+type renderer struct {
+	chart   *chart
+	tracker *tracker
+	tmpl    *template.Template
+	// values are the values with markers, and defaults the values the
+	// templates are rendered with when they can't be rendered with markers.
+	values   interface{}
+	defaults map[string]interface{}
+	// release is the release the templates see, whose name and namespace are
+	// read from the instance.
+	release      map[string]interface{}
+	capabilities *capabilities
+	// collectionMarkers is true if toYaml and toJson replace the maps and
+	// lists of the values by markers.
+	collectionMarkers bool
+}
+
+// undefinedFunctionRegexp matches the parse errors of the templates calling an
+// undefined function.
+var undefinedFunctionRegexp = regexp.MustCompile(`function "([^"]+)" not defined`)
+
+func newRenderer(c *chart, kubernetesVersion string) (*renderer, error) {
+	t := newTracker()
+	r := &renderer{
+		chart:    c,
+		tracker:  t,
+		defaults: c.values,
+		release: map[string]interface{}{
+			"Name":      t.marker("schema.metadata.name", "string", "release-name"),
+			"Namespace": t.marker("schema.metadata.namespace", "string", "default"),
+			"Service":   "kro",
+			"IsInstall": true,
+			"IsUpgrade": false,
+			"Revision":  1,
+		},
+	}
+	r.values = t.mark(c.values, "schema.spec")
+	var err error
+	if r.capabilities, err = newCapabilities(kubernetesVersion); err != nil {
+		return nil, err
+	}
+
+	// The functions not supported fail when the templates call them, rather
+	// than when they are parsed.
+	funcs := r.funcs()
+	rendered, partials := c.templates()
+	for {
+		r.tmpl = template.New(c.metadata.Name).Funcs(funcs).Option("missingkey=zero")
+		err = nil
+		for _, name := range append(partials, rendered...) {
+			if _, err = r.tmpl.New(name).Parse(string(c.files[name])); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			return r, nil
+		}
+		match := undefinedFunctionRegexp.FindStringSubmatch(err.Error())
+		if match == nil {
+			return nil, fmt.Errorf("failed to parse the templates: %w", err)
+		}
+		funcs[match[1]] = unsupported(match[1])
+	}
+}
+
+// renderResources renders the templates, and returns the resources they
+// render, with the values they read replaced by expressions.
+func (r *renderer) renderResources() ([]*v1alpha1.Resource, []string) {
+	var warnings []string
+	var resources []*v1alpha1.Resource
+	ids := make(map[string]int)
+	rendered, _ := r.chart.templates()
+	for _, name := range rendered {
+		objects, defaults, err := r.renderObjects(name)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s is not imported: %v", name, err))
+			continue
+		}
+		if defaults {
+			warnings = append(warnings, fmt.Sprintf("%s is rendered with the default values, "+
+				"the values it reads are not read from the instance", name))
+		}
+		if len(objects) == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s renders no resource with the default values", name))
+			continue
+		}
+		for _, object := range objects {
+			kind, _ := object["kind"].(string)
+			if kind == "" {
+				warnings = append(warnings, fmt.Sprintf("%s renders an object without kind, which is not imported", name))
+				continue
+			}
+			if hook := hookAnnotation(object); hook != "" {
+				if strings.Contains(hook, "test") {
+					warnings = append(warnings, fmt.Sprintf("the %s test of %s is not imported", kind, name))
+					continue
+				}
+				warnings = append(warnings, fmt.Sprintf("the %s hook of %s is imported as a regular resource", kind, name))
+			}
+
+			id := lowerCamelCase(kind)
+			ids[id]++
+			if ids[id] > 1 {
+				id = fmt.Sprintf("%s%d", id, ids[id])
+			}
+			template, err := json.Marshal(r.tracker.replaceAll(object))
+			if err != nil {
+				return nil, append(warnings, err.Error())
+			}
+			resources = append(resources, &v1alpha1.Resource{
+				ID:       id,
+				Template: runtime.RawExtension{Raw: template},
+			})
+		}
+	}
+	return resources, warnings
+}
+
+// renderObjects renders the objects of the given template. The maps and lists
+// of the values are first read as a whole, which renders invalid YAML when
+// the template merges them in other maps or lists, and then field by field.
+// The templates which can't be rendered with markers, e.g because they call a
+// function which can't be expressed, are rendered with the defaults.
+func (r *renderer) renderObjects(name string) (objects []map[string]interface{}, defaults bool, err error) {
+	r.collectionMarkers = true
+	defer func() { r.collectionMarkers = false }()
+	attempts := []struct {
+		values            interface{}
+		collectionMarkers bool
+	}{
+		{r.values, true},
+		{r.values, false},
+		{r.defaults, false},
+	}
+	for i, attempt := range attempts {
+		r.collectionMarkers = attempt.collectionMarkers
+		var output string
+		if output, err = r.render(name, attempt.values); err != nil {
+			continue
+		}
+		if objects, err = parseObjects(output); err != nil {
+			err = fmt.Errorf("it renders invalid YAML: %w", err)
+			continue
+		}
+		return objects, i == len(attempts)-1, nil
+	}
+	return nil, false, err
+}
+
+// render renders the given template with the given values.
+func (r *renderer) render(name string, values interface{}) (string, error) {
+	var b bytes.Buffer
+	err := r.tmpl.ExecuteTemplate(&b, name, map[string]interface{}{
+		"Values":       values,
+		"Release":      r.release,
+		"Chart":        r.chartObject(),
+		"Capabilities": r.capabilities,
+		"Template": map[string]interface{}{
+			"Name":     path.Join(r.chart.metadata.Name, name),
+			"BasePath": path.Join(r.chart.metadata.Name, "templates"),
+		},
+		"Files": files(r.chart.files),
+	})
+	if err != nil {
+		return "", err
+	}
+	// Missing values are rendered as empty strings, as Helm does.
+	return strings.ReplaceAll(b.String(), "<no value>", ""), nil
+}
+
+func (r *renderer) chartObject() map[string]interface{} {
+	m := r.chart.metadata
+	return map[string]interface{}{
+		"Name":        m.Name,
+		"Version":     m.Version,
+		"AppVersion":  m.AppVersion,
+		"Description": m.Description,
+		"Type":        m.Type,
+		"KubeVersion": m.KubeVersion,
+		"Annotations": m.Annotations,
+	}
+}
+
+// parseObjects parses the YAML documents rendered by a template.
+func parseObjects(output string) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(strings.NewReader(output)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		object := map[string]interface{}{}
+		if err := yaml.Unmarshal(doc, &object); err != nil {
+			return nil, err
+		}
+		if len(object) > 0 {
+			objects = append(objects, object)
+		}
+	}
+}
+
+func hookAnnotation(object map[string]interface{}) string {
+	metadata, _ := object["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	hook, _ := annotations["helm.sh/hook"].(string)
+	return hook
+}
+
+// lowerCamelCase converts the given kind to lowerCamelCase, e.g
+// ServiceAccount becomes serviceAccount.
+func lowerCamelCase(kind string) string {
+	if kind == "" {
+		return kind
+	}
+	return strings.ToLower(kind[:1]) + kind[1:]
+}
+
+// capabilities are the capabilities of the cluster the templates see.
+type capabilities struct {
+	KubeVersion kubeVersion
+	APIVersions apiVersions
+}
+
+type kubeVersion struct {
+	Version    string
+	GitVersion string
+	Major      string
+	Minor      string
+}
+
+func (v kubeVersion) String() string {
+	return v.Version
+}
+
+func newCapabilities(version string) (*capabilities, error) {
+	v, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	version = fmt.Sprintf("v%d.%d.%d", v[0], v[1], v[2])
+	return &capabilities{
+		KubeVersion: kubeVersion{
+			Version:    version,
+			GitVersion: version,
+			Major:      fmt.Sprint(v[0]),
+			Minor:      fmt.Sprint(v[1]),
+		},
+		APIVersions: builtinAPIVersions,
+	}, nil
+}
+
+// apiVersions are the group versions served by the cluster.
+type apiVersions []string
+
+// builtinAPIVersions are the group versions of the built-in kinds.
+var builtinAPIVersions = apiVersions{
+	"v1",
+	"admissionregistration.k8s.io/v1",
+	"apiextensions.k8s.io/v1",
+	"apps/v1",
+	"autoscaling/v1",
+	"autoscaling/v2",
+	"batch/v1",
+	"certificates.k8s.io/v1",
+	"coordination.k8s.io/v1",
+	"discovery.k8s.io/v1",
+	"events.k8s.io/v1",
+	"networking.k8s.io/v1",
+	"node.k8s.io/v1",
+	"policy/v1",
+	"rbac.authorization.k8s.io/v1",
+	"scheduling.k8s.io/v1",
+	"storage.k8s.io/v1",
+}
+
+// Has returns true if the given group version, or group version and kind, e.g
+// apps/v1/Deployment, is served.
+func (a apiVersions) Has(version string) bool {
+	for _, v := range a {
+		if version == v || strings.HasPrefix(version, v+"/") && !strings.Contains(strings.TrimPrefix(version, v+"/"), "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// files are the files of a chart, read by the templates.
+type files map[string][]byte
+
+// Get returns the content of the given file.
+func (f files) Get(name string) string {
+	return string(f[name])
+}
+
+// GetBytes returns the content of the given file.
+func (f files) GetBytes(name string) []byte {
+	return f[name]
+}
+
+// Glob returns the files matching the given pattern.
+func (f files) Glob(pattern string) files {
+	matched := make(files)
+	for name, data := range f {
+		if ok, _ := path.Match(pattern, name); ok {
+			matched[name] = data
+		}
+	}
+	return matched
+}
+
+// Lines returns the lines of the given file.
+func (f files) Lines(name string) []string {
+	return strings.Split(strings.TrimSuffix(string(f[name]), "\n"), "\n")
+}
+
+// AsConfig returns the files as the data of a ConfigMap.
+func (f files) AsConfig() string {
+	data := make(map[string]string, len(f))
+	for name, content := range f {
+		data[path.Base(name)] = string(content)
+	}
+	out, _ := yaml.Marshal(data)
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// AsSecrets returns the files as the data of a Secret.
+func (f files) AsSecrets() string {
+	data := make(map[string][]byte, len(f))
+	for name, content := range f {
+		data[path.Base(name)] = content
+	}
+	out, _ := yaml.Marshal(data)
+	return strings.TrimSuffix(string(out), "\n")
+}
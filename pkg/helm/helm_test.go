@@ -0,0 +1,158 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package helm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/pkg/golden"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/graph/schema"
+)
+
+func TestImport(t *testing.T) {
+	result, err := Import("testdata/webapp", Options{})
+	require.NoError(t, err)
+	rgd := result.ResourceGraphDefinition
+
+	assert.Equal(t, "webapp", rgd.Name)
+	assert.Equal(t, "Webapp", rgd.Spec.Schema.Kind)
+	assert.Equal(t, "v1alpha1", rgd.Spec.Schema.APIVersion)
+
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(rgd.Spec.Schema.Spec.Raw, &spec))
+	assert.Equal(t, "integer | default=1", spec["replicaCount"])
+	assert.Equal(t, "Image | default={}", spec["image"])
+	assert.Equal(t, "any | default={}", spec["podAnnotations"])
+	var types map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(rgd.Spec.Schema.Types.Raw, &types))
+	assert.Equal(t, "string | default=nginx", types["Image"]["repository"])
+	assert.Equal(t, `string | default="Hello, world"`, types["Config"]["greeting"])
+	assert.Equal(t, "Limits | default={}", types["Resources"]["limits"])
+
+	templates := map[string]map[string]interface{}{}
+	for _, resource := range rgd.Spec.Resources {
+		var template map[string]interface{}
+		require.NoError(t, json.Unmarshal(resource.Template.Raw, &template))
+		templates[resource.ID] = template
+	}
+	require.Len(t, templates, 3)
+	deployment := &unstructured.Unstructured{Object: templates["deployment"]}
+	field := func(fields ...string) interface{} {
+		v, _, _ := unstructured.NestedFieldNoCopy(deployment.Object, fields...)
+		return v
+	}
+	assert.Equal(t, "${schema.metadata.name}-webapp", deployment.GetName())
+	assert.Equal(t, "${schema.spec.replicaCount}", field("spec", "replicas"))
+	assert.Equal(t, "${schema.spec.podLabels.team}", field("spec", "template", "metadata", "labels", "team"))
+	container := field("spec", "template", "spec", "containers").([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "${schema.spec.image.repository}:1.16.0", container["image"])
+	assert.Equal(t, "${string(schema.spec.service.port)}",
+		container["env"].([]interface{})[0].(map[string]interface{})["value"])
+	assert.Equal(t, "${schema.spec.config.logLevel.upperAscii()}",
+		templates["configMap"]["data"].(map[string]interface{})["LOG_LEVEL"])
+	assert.Contains(t, templates, "service")
+
+	assert.Equal(t, []string{
+		"templates/ingress.yaml renders no resource with the default values",
+		"the Pod test of templates/tests/test-connection.yaml is not imported",
+		"the following values are not read by the resources, they may be read by conditions, or by the " +
+			"resources which couldn't be imported: spec.fullnameOverride, spec.image.tag, spec.ingress.enabled, " +
+			"spec.ingress.host, spec.nameOverride, spec.podAnnotations",
+	}, result.Warnings)
+
+	// The imported ResourceGraphDefinition renders the resources of the chart.
+	resolver, err := schema.NewOfflineResolver(nil)
+	require.NoError(t, err)
+	g, err := graph.NewOfflineBuilder(resolver, DefaultKubernetesVersion).NewResourceGraphDefinition(rgd)
+	require.NoError(t, err)
+	instance := &unstructured.Unstructured{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+apiVersion: kro.run/v1alpha1
+kind: Webapp
+metadata:
+  name: demo
+  namespace: default
+spec:
+  replicaCount: 3
+  image:
+    repository: httpd
+`), &instance.Object))
+	rendered, err := golden.Render(g, instance)
+	require.NoError(t, err)
+	assert.Contains(t, string(rendered), "name: demo-webapp\n")
+	assert.Contains(t, string(rendered), "replicas: 3\n")
+	assert.Contains(t, string(rendered), "image: httpd:1.16.0\n")
+	assert.Contains(t, string(rendered), "LOG_LEVEL: INFO\n")
+}
+
+func TestImportOptions(t *testing.T) {
+	result, err := Import("testdata/webapp", Options{Name: "web", Kind: "WebApplication", APIVersion: "v1beta1"})
+	require.NoError(t, err)
+	rgd := result.ResourceGraphDefinition
+	assert.Equal(t, "web", rgd.Name)
+	assert.Equal(t, "WebApplication", rgd.Spec.Schema.Kind)
+	assert.Equal(t, "v1beta1", rgd.Spec.Schema.APIVersion)
+
+	_, err = Import("testdata/missing", Options{})
+	assert.Error(t, err)
+}
+
+func TestTrackerReplace(t *testing.T) {
+	tracker := newTracker()
+	name := tracker.marker("schema.spec.name", "string", "web")
+	port := tracker.marker("schema.spec.port", "integer", int64(80))
+	portString, _ := tracker.stringMarker(port)
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: name, expected: "${schema.spec.name}"},
+		{value: "app-" + name, expected: "app-${schema.spec.name}"},
+		{value: port, expected: "${schema.spec.port}"},
+		{value: portString, expected: "${string(schema.spec.port)}"},
+		{value: name + ":" + portString, expected: "${schema.spec.name}:${string(schema.spec.port)}"},
+		{value: "constant", expected: "constant"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tracker.replace(tt.value))
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		constraints string
+		version     string
+		expected    bool
+	}{
+		{constraints: ">=1.19-0", version: "v1.31.0", expected: true},
+		{constraints: "<1.19", version: "v1.31.0", expected: false},
+		{constraints: ">=1.14, <1.20", version: "1.16.3", expected: true},
+		{constraints: "<1.14 || >=1.30", version: "v1.31.0-eks-1234", expected: true},
+		{constraints: "~1.31.0", version: "v1.31.4", expected: true},
+		{constraints: "^2.0.0", version: "v1.31.0", expected: false},
+	}
+	for _, tt := range tests {
+		satisfied, err := semverCompare(tt.constraints, tt.version)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, satisfied, "%s %s", tt.constraints, tt.version)
+	}
+}
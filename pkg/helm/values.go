@@ -0,0 +1,501 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The templates are rendered with the values replaced by markers, e.g
+// __kro3__, which are replaced by the expressions reading the values from the
+// instance in the rendered resources. The string markers, e.g __kros3__, are
+// left by the functions converting values to strings, such as quote.
+var markerRegexp = regexp.MustCompile(`(?i)__kro(s?)(\d+)__`)
+
+// identifierRegexp matches the keys that can be fields of the schema, and be
+// selected in expressions.
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// value is a value of the chart, read from the instance.
+type value struct {
+	// expression is the expression reading the value, e.g
+	// schema.spec.image.repository.
+	expression string
+	// schemaType is the SimpleSchema type of the value.
+	schemaType string
+	// defaultValue is the default of the value, which the conditions of the
+	// templates compare.
+	defaultValue interface{}
+	// used is true if the value is read by the rendered resources.
+	used bool
+}
+
+// tracker replaces the values of the chart by markers, and maps the markers
+// back to the values.
+type tracker struct {
+	values []*value
+	// collections maps the maps and slices of the values, by address, to
+	// their index in values, so that toYaml can read them as a whole.
+	collections map[uintptr]int
+}
+
+func newTracker() *tracker {
+	return &tracker{collections: make(map[uintptr]int)}
+}
+
+// marker returns the marker of a new value read by the given expression.
+func (t *tracker) marker(expression, schemaType string, defaultValue interface{}) string {
+	t.values = append(t.values, &value{expression: expression, schemaType: schemaType, defaultValue: defaultValue})
+	return fmt.Sprintf("__kro%d__", len(t.values)-1)
+}
+
+// mark returns a copy of the given values, where the non-empty strings and the
+// numbers are replaced by markers. The booleans and empty values are kept, so
+// that the conditions of the templates evaluate as with the defaults.
+func (t *tracker) mark(v interface{}, expression string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		marked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			marked[key] = t.mark(item, selectField(expression, key))
+		}
+		t.track(marked, expression, "any", v)
+		return marked
+	case []interface{}:
+		// The items of lists aren't read one by one from the instance, as
+		// the instance can change their number.
+		copied := make([]interface{}, len(v), len(v)+1)
+		copy(copied, v)
+		t.track(copied, expression, "[]any", v)
+		return copied
+	case string:
+		if v == "" {
+			return v
+		}
+		return t.marker(expression, "string", v)
+	case int64:
+		return t.marker(expression, "integer", v)
+	case float64:
+		return t.marker(expression, "float", v)
+	default:
+		return v
+	}
+}
+
+func (t *tracker) track(collection interface{}, expression, schemaType string, defaultValue interface{}) {
+	t.values = append(t.values, &value{expression: expression, schemaType: schemaType, defaultValue: defaultValue})
+	t.collections[reflect.ValueOf(collection).Pointer()] = len(t.values) - 1
+}
+
+// collectionMarker returns the marker of the given map or slice if it is one
+// of the values, or an empty string.
+func (t *tracker) collectionMarker(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map && rv.Kind() != reflect.Slice {
+		return ""
+	}
+	index, ok := t.collections[rv.Pointer()]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("__kro%d__", index)
+}
+
+// lookup returns the value of the given marker, or nil if the given value
+// isn't a single marker.
+func (t *tracker) lookup(v interface{}) *value {
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	match := markerRegexp.FindStringSubmatch(s)
+	if match == nil || match[0] != s {
+		return nil
+	}
+	index, err := strconv.Atoi(match[2])
+	if err != nil || index >= len(t.values) {
+		return nil
+	}
+	return t.values[index]
+}
+
+// stringMarker returns the string marker of the given value if it is the
+// marker of a value which isn't a string, so that it is converted to a string
+// in the rendered resources.
+func (t *tracker) stringMarker(v interface{}) (string, bool) {
+	if t.lookup(v) == nil {
+		return "", false
+	}
+	match := markerRegexp.FindStringSubmatch(v.(string))
+	return fmt.Sprintf("__kros%s__", match[2]), true
+}
+
+// resolve returns the default of the given value if it is a marker, so that
+// the conditions of the templates evaluate as with the defaults.
+func (t *tracker) resolve(v interface{}) interface{} {
+	if value := t.lookup(v); value != nil {
+		return value.defaultValue
+	}
+	if s, ok := v.(string); ok && markerRegexp.MatchString(s) {
+		return t.resolveString(s)
+	}
+	return v
+}
+
+// resolveString replaces the markers of the given string by their defaults.
+func (t *tracker) resolveString(s string) string {
+	return markerRegexp.ReplaceAllStringFunc(s, func(marker string) string {
+		if value := t.lookup(marker); value != nil {
+			return fmt.Sprint(value.defaultValue)
+		}
+		return marker
+	})
+}
+
+// expression returns the expression of the given string holding markers, e.g
+// `schema.metadata.name + "-web"`, and whether it holds markers.
+func (t *tracker) expression(s string) (string, bool) {
+	matches := markerRegexp.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	if value := t.lookup(s); value != nil && s[matches[0][2]:matches[0][3]] == "" {
+		return value.expression, true
+	}
+	var parts []string
+	last := 0
+	for _, match := range matches {
+		if match[0] > last {
+			parts = append(parts, strconv.Quote(s[last:match[0]]))
+		}
+		value := t.lookup(s[match[0]:match[1]])
+		if value.schemaType == "string" {
+			parts = append(parts, value.expression)
+		} else {
+			parts = append(parts, "string("+value.expression+")")
+		}
+		last = match[1]
+	}
+	if last < len(s) {
+		parts = append(parts, strconv.Quote(s[last:]))
+	}
+	if len(parts) == 1 {
+		return parts[0], true
+	}
+	return "(" + strings.Join(parts, " + ") + ")", true
+}
+
+// derive returns the result of the given function applied to the given
+// string. If the string holds markers, the result is the marker of the given
+// expression applied to the expression of the string.
+func (t *tracker) derive(s string, fn func(string) string, expression func(string) string) string {
+	e, ok := t.expression(s)
+	if !ok {
+		return fn(s)
+	}
+	return t.marker(expression(e), "string", fn(t.resolveString(s)))
+}
+
+// replace replaces the markers of the given rendered string by the expressions
+// reading the values. A string holding a single marker becomes a standalone
+// expression, keeping the type of the value. The values which aren't strings
+// are converted to strings when they are part of a larger string.
+func (t *tracker) replace(s string) string {
+	matches := markerRegexp.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	standalone := len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s)
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		index, err := strconv.Atoi(s[match[4]:match[5]])
+		if err != nil || index >= len(t.values) {
+			continue
+		}
+		v := t.values[index]
+		v.used = true
+		expression := v.expression
+		if v.schemaType != "string" && (!standalone || match[3] > match[2]) {
+			expression = "string(" + expression + ")"
+		}
+		b.WriteString(s[last:match[0]])
+		b.WriteString("${" + expression + "}")
+		last = match[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// replaceAll replaces the markers of the strings of the given rendered object.
+func (t *tracker) replaceAll(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = t.replaceAll(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = t.replaceAll(item)
+		}
+	case string:
+		return t.replace(v)
+	}
+	return v
+}
+
+// unused returns the expressions of the values which aren't read by the
+// rendered resources, nor as part of a map or a list.
+func (t *tracker) unused(values map[string]interface{}) []string {
+	var used []string
+	for _, v := range t.values {
+		if v.used {
+			used = append(used, v.expression)
+		}
+	}
+	var unused []string
+	var walk func(v interface{}, expression string)
+	walk = func(v interface{}, expression string) {
+		if slices.ContainsFunc(used, func(u string) bool { return reads(u, expression) }) {
+			return
+		}
+		if object, ok := v.(map[string]interface{}); ok && len(object) > 0 {
+			for key, item := range object {
+				walk(item, selectField(expression, key))
+			}
+			return
+		}
+		unused = append(unused, strings.TrimPrefix(expression, "schema."))
+	}
+	for key, item := range values {
+		walk(item, selectField("schema.spec", key))
+	}
+	slices.Sort(unused)
+	return unused
+}
+
+// readsRegexp matches what can follow a value read by an expression: the end
+// of the expression, an operator, or a method call, but not the selection of
+// a field of the value.
+var readsRegexp = regexp.MustCompile(`^($|[^.\[a-zA-Z0-9_]|\.[a-zA-Z_][a-zA-Z0-9_]*\()`)
+
+// reads returns true if the given expression reads the value of the given
+// expression as a whole, e.g schema.spec.name.upperAscii() reads
+// schema.spec.name.
+func reads(expression, value string) bool {
+	for i := 0; ; {
+		j := strings.Index(expression[i:], value)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(value)
+		before := rune(0)
+		if start > 0 {
+			before = rune(expression[start-1])
+		}
+		if before != '.' && before != '_' && !unicode.IsLetter(before) && !unicode.IsDigit(before) &&
+			readsRegexp.MatchString(expression[end:]) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// selectField returns the expression selecting the given field of an object.
+func selectField(expression, key string) string {
+	if identifierRegexp.MatchString(key) {
+		return expression + "." + key
+	}
+	return expression + "[" + strconv.Quote(key) + "]"
+}
+
+// schemaBuilder builds the SimpleSchema of the values of a chart.
+type schemaBuilder struct {
+	types    map[string]interface{}
+	warnings []string
+}
+
+// build returns the SimpleSchema of the given object of values. The objects
+// are extracted into custom types, defaulting to an empty object, so that the
+// defaults of their fields apply when they are omitted.
+func (b *schemaBuilder) build(values map[string]interface{}, path []string) map[string]interface{} {
+	// The fields are built in order, so that the names of the types are
+	// deterministic.
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	schema := make(map[string]interface{}, len(values))
+	for _, key := range keys {
+		v := values[key]
+		fieldPath := append(slices.Clone(path), key)
+		if object, ok := v.(map[string]interface{}); ok && isStructured(object) {
+			name := b.typeName(fieldPath)
+			b.types[name] = b.build(object, fieldPath)
+			schema[key] = name + " | default={}"
+			continue
+		}
+		schema[key] = b.field(v, fieldPath)
+	}
+	return schema
+}
+
+// field returns the SimpleSchema of a field, with its value as default.
+func (b *schemaBuilder) field(v interface{}, path []string) string {
+	switch v := v.(type) {
+	case bool:
+		return fmt.Sprintf("boolean | default=%t", v)
+	case int64:
+		return fmt.Sprintf("integer | default=%d", v)
+	case float64:
+		return "float | default=" + strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		// The default of strings can't hold quotes, backslashes nor control
+		// characters.
+		if strings.ContainsAny(v, `"\`) || strings.ContainsFunc(v, unicode.IsControl) {
+			b.warnings = append(b.warnings, fmt.Sprintf(
+				"the default of %s can't be expressed in the schema, set it in the instances", strings.Join(path, ".")))
+			return "string"
+		}
+		return "string | default=" + quoteMarkerValue(v)
+	case map[string]interface{}:
+		return mapType(v) + " | default=" + compactJSON(v)
+	case []interface{}:
+		return "[]" + elementType(v) + " | default=" + compactJSON(v)
+	default:
+		// Null values are most often optional strings, e.g the name of an
+		// existing secret.
+		return "string"
+	}
+}
+
+// typeName returns a unique name for the custom type of the object at the
+// given path, named after its field, or after its whole path on conflicts.
+func (b *schemaBuilder) typeName(path []string) string {
+	name := pascalCase(path[len(path)-1])
+	if _, ok := b.types[name]; !ok {
+		return name
+	}
+	full := ""
+	for _, key := range path {
+		full += pascalCase(key)
+	}
+	name = full
+	for i := 2; ; i++ {
+		if _, ok := b.types[name]; !ok {
+			return name
+		}
+		name = fmt.Sprintf("%s%d", full, i)
+	}
+}
+
+// isStructured returns true if the given object has fields, rather than being
+// a map, e.g of labels.
+func isStructured(object map[string]interface{}) bool {
+	if len(object) == 0 {
+		return false
+	}
+	for key := range object {
+		if !identifierRegexp.MatchString(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// mapType returns the SimpleSchema type of a map, which is a map of scalars
+// if all its values have the same scalar type.
+func mapType(object map[string]interface{}) string {
+	values := make([]interface{}, 0, len(object))
+	for _, v := range object {
+		values = append(values, v)
+	}
+	if elementType := elementType(values); elementType != "any" {
+		return "map[string]" + elementType
+	}
+	return "any"
+}
+
+// elementType returns the SimpleSchema type shared by the given values, or any.
+func elementType(values []interface{}) string {
+	elementType := ""
+	for _, v := range values {
+		var t string
+		switch v.(type) {
+		case bool:
+			t = "boolean"
+		case int64:
+			t = "integer"
+		case float64:
+			t = "float"
+		case string:
+			t = "string"
+		default:
+			return "any"
+		}
+		if elementType != "" && elementType != t {
+			return "any"
+		}
+		elementType = t
+	}
+	if elementType == "" {
+		return "any"
+	}
+	return elementType
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// quoteMarkerValue quotes the given marker value if needed.
+func quoteMarkerValue(value string) string {
+	if value != "" && !strings.ContainsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`=[]{}`, r)
+	}) {
+		return value
+	}
+	return `"` + value + `"`
+}
+
+// pascalCase converts the given name to PascalCase, e.g my-app becomes MyApp.
+func pascalCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
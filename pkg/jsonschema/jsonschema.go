@@ -0,0 +1,196 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package jsonschema converts the OpenAPI schemas kro generates for the
+// instances of ResourceGraphDefinitions to standalone JSON Schema documents,
+// so that the instance manifests can be validated before they reach the
+// cluster, by IDEs, kubeconform or any other JSON Schema validator.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/codegen"
+)
+
+// Draft is the version of JSON Schema of the generated documents.
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// Document is the JSON Schema of a version of the instances.
+type Document struct {
+	// APIVersion is the group and version of the instances, e.g
+	// kro.run/v1alpha1.
+	APIVersion string
+	// Kind is the kind of the instances.
+	Kind string
+	// FileName is the name the document is published with, see FileName.
+	FileName string
+	// Data is the indented JSON of the document.
+	Data []byte
+}
+
+// FileName returns the name of the document of the given kind and version,
+// e.g webapp_v1alpha1.json, which is the naming kubeconform expects with the
+// {{.ResourceKind}}_{{.ResourceAPIVersion}}.json schema location.
+func FileName(kind, version string) string {
+	return fmt.Sprintf("%s_%s.json", strings.ToLower(kind), version)
+}
+
+// GenerateFromResourceGraphDefinition generates the JSON Schema of the
+// instances of the given ResourceGraphDefinition, without access to a cluster.
+// Status fields set by a standalone expression have no type.
+func GenerateFromResourceGraphDefinition(rgd *v1alpha1.ResourceGraphDefinition) ([]Document, error) {
+	instanceCRD, err := codegen.InstanceCRD(rgd)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateFromCRD(instanceCRD)
+}
+
+// GenerateFromCRD generates the JSON Schema of each served version of the
+// given CustomResourceDefinition.
+func GenerateFromCRD(crd *extv1.CustomResourceDefinition) ([]Document, error) {
+	var documents []Document
+	for _, version := range crd.Spec.Versions {
+		if !version.Served {
+			continue
+		}
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			return nil, fmt.Errorf("CRD %s version %s has no schema", crd.Name, version.Name)
+		}
+		apiVersion := crd.Spec.Group + "/" + version.Name
+		data, err := generate(version.Schema.OpenAPIV3Schema, apiVersion, crd.Spec.Names.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate the schema of version %s: %w", version.Name, err)
+		}
+		documents = append(documents, Document{
+			APIVersion: apiVersion,
+			Kind:       crd.Spec.Names.Kind,
+			FileName:   FileName(crd.Spec.Names.Kind, version.Name),
+			Data:       data,
+		})
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("CRD %s has no served version", crd.Name)
+	}
+	return documents, nil
+}
+
+func generate(schema *extv1.JSONSchemaProps, apiVersion, kind string) ([]byte, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	document := map[string]interface{}{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	convert(document)
+
+	// The API server sets apiVersion and kind, but they are required in the
+	// manifests, and select the schema of the instances.
+	properties, _ := document["properties"].(map[string]interface{})
+	if properties == nil {
+		properties = map[string]interface{}{}
+		document["properties"] = properties
+	}
+	properties["apiVersion"] = withEnum(properties["apiVersion"], apiVersion)
+	properties["kind"] = withEnum(properties["kind"], kind)
+	document["required"] = appendMissing(document["required"], "apiVersion", "kind")
+	document["$schema"] = Draft
+	document["title"] = fmt.Sprintf("%s %s", kind, apiVersion)
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// convert converts the given OpenAPI schema to JSON Schema, in place:
+//   - nullable fields also accept null,
+//   - int-or-string fields accept integers and strings,
+//   - objects with known fields reject the unknown ones, as the API server
+//     does with strict field validation.
+func convert(schema map[string]interface{}) {
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		if t, ok := schema["type"].(string); ok {
+			schema["type"] = []interface{}{t, "null"}
+		}
+	}
+	delete(schema, "nullable")
+
+	if intOrString, _ := schema["x-kubernetes-int-or-string"].(bool); intOrString {
+		delete(schema, "type")
+		if _, ok := schema["anyOf"]; !ok {
+			schema["anyOf"] = []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string"},
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	preserveUnknownFields, _ := schema["x-kubernetes-preserve-unknown-fields"].(bool)
+	if len(properties) > 0 && !preserveUnknownFields {
+		if _, ok := schema["additionalProperties"]; !ok {
+			schema["additionalProperties"] = false
+		}
+	}
+
+	for _, property := range properties {
+		if property, ok := property.(map[string]interface{}); ok {
+			convert(property)
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		if nested, ok := schema[key].(map[string]interface{}); ok {
+			convert(nested)
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		nested, _ := schema[key].([]interface{})
+		for _, item := range nested {
+			if item, ok := item.(map[string]interface{}); ok {
+				convert(item)
+			}
+		}
+	}
+}
+
+func withEnum(schema interface{}, value string) map[string]interface{} {
+	property, _ := schema.(map[string]interface{})
+	if property == nil {
+		property = map[string]interface{}{"type": "string"}
+	}
+	property["enum"] = []interface{}{value}
+	return property
+}
+
+func appendMissing(list interface{}, values ...string) []interface{} {
+	items, _ := list.([]interface{})
+	for _, value := range values {
+		found := false
+		for _, item := range items {
+			if item == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			items = append(items, value)
+		}
+	}
+	return items
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+func TestGenerateFromResourceGraphDefinition(t *testing.T) {
+	rgd := &v1alpha1.ResourceGraphDefinition{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: webapp
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: WebApp
+    spec:
+      name: string | required=true
+      replicas: integer | default=1 minimum=1
+      labels: map[string]string
+`), rgd))
+
+	documents, err := GenerateFromResourceGraphDefinition(rgd)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, "kro.run/v1alpha1", documents[0].APIVersion)
+	assert.Equal(t, "WebApp", documents[0].Kind)
+	assert.Equal(t, "webapp_v1alpha1.json", documents[0].FileName)
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(documents[0].Data, &document))
+	assert.Equal(t, Draft, document["$schema"])
+	assert.ElementsMatch(t, []interface{}{"apiVersion", "kind"}, document["required"])
+	assert.Equal(t, false, document["additionalProperties"])
+
+	properties := document["properties"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"kro.run/v1alpha1"}, properties["apiVersion"].(map[string]interface{})["enum"])
+	assert.Equal(t, []interface{}{"WebApp"}, properties["kind"].(map[string]interface{})["enum"])
+
+	spec := properties["spec"].(map[string]interface{})
+	assert.Equal(t, false, spec["additionalProperties"])
+	assert.Equal(t, []interface{}{"name"}, spec["required"])
+	specProperties := spec["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "integer", "default": float64(1), "minimum": float64(1)},
+		specProperties["replicas"])
+	assert.Equal(t, map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "string"},
+	}, specProperties["labels"])
+}
+
+func TestGenerateFromCRD(t *testing.T) {
+	crd := &extv1.CustomResourceDefinition{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: webapps.example.com
+spec:
+  group: example.com
+  names:
+    kind: WebApp
+    plural: webapps
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              port:
+                x-kubernetes-int-or-string: true
+              owner:
+                type: string
+                nullable: true
+              values:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+                properties:
+                  known:
+                    type: string
+  - name: v1alpha1
+    served: false
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+`), crd))
+
+	documents, err := GenerateFromCRD(crd)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, "example.com/v1", documents[0].APIVersion)
+	assert.Equal(t, "webapp_v1.json", documents[0].FileName)
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(documents[0].Data, &document))
+	spec := document["properties"].(map[string]interface{})["spec"].(map[string]interface{})
+	properties := spec["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"x-kubernetes-int-or-string": true,
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "integer"},
+			map[string]interface{}{"type": "string"},
+		},
+	}, properties["port"])
+	assert.Equal(t, map[string]interface{}{"type": []interface{}{"string", "null"}}, properties["owner"])
+	assert.NotContains(t, properties["values"], "additionalProperties")
+
+	crd.Spec.Versions[0].Served = false
+	_, err = GenerateFromCRD(crd)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AllowBreakingChangesAnnotation can be set to "true" on a
+	// ResourceGraphDefinition to apply schema changes that are not compatible
+	// with the existing instances.
+	AllowBreakingChangesAnnotation = LabelKROPrefix + "allow-breaking-changes"
+	// ForceDeleteAnnotation can be set to "true" on a ResourceGraphDefinition
+	// to delete it while instances remain. The instances are left without a
+	// controller.
+	ForceDeleteAnnotation = LabelKROPrefix + "force-delete"
+	// GraphFormatAnnotation can be set on a ResourceGraphDefinition to render
+	// the dependency graph of its resources in its status, either as "dot" or
+	// "mermaid".
+	GraphFormatAnnotation = LabelKROPrefix + "graph-format"
+	// DesiredHashAnnotation is set by kro on the resources it manages to the
+	// hash of the desired state it last applied, to tell the changes made
+	// outside of kro from the changes of the desired state.
+	DesiredHashAnnotation = LabelKROPrefix + "desired-hash"
+	// PausedAnnotation can be set to "true" on an instance to stop kro from
+	// creating, updating or deleting its resources. The instance status keeps
+	// reporting their observed state.
+	PausedAnnotation = LabelKROPrefix + "paused"
+	// PlanAnnotation can be set to "true" on an instance to preview the
+	// changes kro would make to its resources, in its status, without
+	// applying any of them.
+	PlanAnnotation = LabelKROPrefix + "plan"
+	// TTLAfterReadyAnnotation can be set on an instance to the time after
+	// which it's deleted once ready, e.g "72h", overriding the TTL of its
+	// ResourceGraphDefinition. "0" keeps the instance.
+	TTLAfterReadyAnnotation = LabelKROPrefix + "ttl-after-ready"
+	// TTLAfterFailureAnnotation can be set on an instance to the time after
+	// which it's deleted once failed, overriding the TTL of its
+	// ResourceGraphDefinition. "0" keeps the instance.
+	TTLAfterFailureAnnotation = LabelKROPrefix + "ttl-after-failure"
+	// PatchRevertAnnotationPrefix prefixes the UID of an instance in the
+	// annotation kro sets on the objects the instance patches, holding the
+	// JSON merge patch restoring their values before the patch.
+	PatchRevertAnnotationPrefix = LabelKROPrefix + "revert-"
+)
+
+// IsBreakingChangesAllowed returns true if the object allows breaking schema
+// changes through the AllowBreakingChangesAnnotation.
+func IsBreakingChangesAllowed(obj metav1.Object) bool {
+	allowed, _ := strconv.ParseBool(obj.GetAnnotations()[AllowBreakingChangesAnnotation])
+	return allowed
+}
+
+// IsForceDeleted returns true if the object is deleted regardless of its
+// instances through the ForceDeleteAnnotation.
+func IsForceDeleted(obj metav1.Object) bool {
+	forced, _ := strconv.ParseBool(obj.GetAnnotations()[ForceDeleteAnnotation])
+	return forced
+}
+
+// IsPaused returns true if the reconciliation of the object is paused through
+// the PausedAnnotation.
+func IsPaused(obj metav1.Object) bool {
+	paused, _ := strconv.ParseBool(obj.GetAnnotations()[PausedAnnotation])
+	return paused
+}
+
+// IsPlanned returns true if the object only requests a preview of the changes
+// to its resources through the PlanAnnotation.
+func IsPlanned(obj metav1.Object) bool {
+	planned, _ := strconv.ParseBool(obj.GetAnnotations()[PlanAnnotation])
+	return planned
+}
+
+// GetTTL returns the TTL set through the given annotation of the object,
+// TTLAfterReadyAnnotation or TTLAfterFailureAnnotation. It returns false if
+// the object doesn't set it, and 0 if it keeps the object.
+func GetTTL(obj metav1.Object, annotation string) (time.Duration, bool, error) {
+	raw, ok := obj.GetAnnotations()[annotation]
+	if !ok {
+		return 0, false, nil
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s annotation %q: %w", annotation, raw, err)
+	}
+	if ttl < 0 {
+		return 0, false, fmt.Errorf("invalid %s annotation %q: must not be negative", annotation, raw)
+	}
+	return ttl, true, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsBreakingChangesAllowed(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{name: "no annotations", expected: false},
+		{name: "allowed", annotations: map[string]string{AllowBreakingChangesAnnotation: "true"}, expected: true},
+		{name: "disallowed", annotations: map[string]string{AllowBreakingChangesAnnotation: "false"}, expected: false},
+		{name: "invalid value", annotations: map[string]string{AllowBreakingChangesAnnotation: "yes please"}, expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tc.annotations}
+			assert.Equal(t, tc.expected, IsBreakingChangesAllowed(obj))
+		})
+	}
+}
+
+func TestGetTTL(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expected    time.Duration
+		expectSet   bool
+		expectErr   bool
+	}{
+		{name: "no annotations"},
+		{name: "ttl", annotations: map[string]string{TTLAfterReadyAnnotation: "72h"}, expected: 72 * time.Hour, expectSet: true},
+		{name: "kept", annotations: map[string]string{TTLAfterReadyAnnotation: "0"}, expectSet: true},
+		{name: "other annotation", annotations: map[string]string{TTLAfterFailureAnnotation: "1h"}},
+		{name: "invalid ttl", annotations: map[string]string{TTLAfterReadyAnnotation: "tomorrow"}, expectErr: true},
+		{name: "negative ttl", annotations: map[string]string{TTLAfterReadyAnnotation: "-1h"}, expectErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tc.annotations}
+			ttl, set, err := GetTTL(obj, TTLAfterReadyAnnotation)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectSet, set)
+			assert.Equal(t, tc.expected, ttl)
+		})
+	}
+}
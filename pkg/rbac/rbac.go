@@ -0,0 +1,289 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package rbac computes the permissions kro needs to reconcile the instances
+// of a ResourceGraphDefinition, so that kro can be granted these permissions
+// rather than cluster-admin.
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+var (
+	// readVerbs are the verbs needed to read and watch the objects kro
+	// doesn't manage, e.g the external references.
+	readVerbs = []string{"get", "list", "watch"}
+	// manageVerbs are the verbs needed to apply, adopt and delete the
+	// resources of the instances.
+	manageVerbs = []string{"create", "delete", "get", "list", "patch", "update", "watch"}
+	// instanceVerbs are the verbs needed to reconcile the instances, which
+	// kro watches and updates, e.g to set their finalizer.
+	instanceVerbs = []string{"get", "list", "patch", "update", "watch"}
+)
+
+// AggregateToControllerLabel is the label of the ClusterRoles aggregated into
+// the ClusterRole of kro, when the Helm chart is installed in the aggregation
+// access mode.
+const AggregateToControllerLabel = "rbac.kro.run/aggregate-to-controller"
+
+// jobGVK is the kind of the Job hooks.
+var jobGVK = schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}
+
+// Resolver returns the resource of the given kind.
+type Resolver func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error)
+
+// PluralResolver resolves the resource of a kind by pluralizing the kind, the
+// way kro names the resources of the instances. It doesn't need access to a
+// cluster, but is wrong for the kinds whose resource isn't their plural, which
+// a RESTMapper resolves.
+func PluralResolver(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+	return metadata.GetResourceGraphDefinitionInstanceGVR(gvk.Group, gvk.Version, gvk.Kind), nil
+}
+
+// Rules returns the minimal rules granting the permissions kro needs to
+// reconcile the instances of the given ResourceGraphDefinition:
+//
+//   - its instances, and their status and finalizers
+//   - the resources of the templates and of the Job hooks, which kro manages
+//   - the external references and the objects waited for, which kro reads
+//   - the Secrets holding the kubeconfigs of the remote clusters resources
+//     are applied to, whose own permissions are those of these kubeconfigs
+//   - the namespaces of the instances, whose labels are matched against the
+//     namespace selector, if any
+//   - the Secrets recording the revisions of the instances, with
+//     rollbackOnFailure
+//   - the ConfigMaps and Secrets values are loaded from, which kro watches
+//
+// The rules are sorted by API group, and merge the resources of a group
+// needing the same verbs.
+func Rules(rgd *v1alpha1.ResourceGraphDefinition, resolve Resolver) ([]rbacv1.PolicyRule, error) {
+	if rgd.Spec.Schema == nil {
+		return nil, fmt.Errorf("resource graph definition %s has no schema", rgd.Name)
+	}
+
+	// verbs are the verbs needed on each resource, keyed by group and
+	// resource.
+	verbs := map[schema.GroupResource][]string{}
+	grant := func(gr schema.GroupResource, granted []string) {
+		verbs[gr] = slices.Compact(slices.Sorted(slices.Values(append(verbs[gr], granted...))))
+	}
+	grantKind := func(gvk schema.GroupVersionKind, granted []string) error {
+		gvr, err := resolve(gvk)
+		if err != nil {
+			return fmt.Errorf("failed to resolve the resource of %s: %w", gvk, err)
+		}
+		grant(gvr.GroupResource(), granted)
+		return nil
+	}
+
+	group := rgd.Spec.Schema.Group
+	if group == "" {
+		group = v1alpha1.KRODomainName
+	}
+	instances := metadata.GetResourceGraphDefinitionInstanceGVR(group, rgd.Spec.Schema.APIVersion, rgd.Spec.Schema.Kind).GroupResource()
+	grant(instances, instanceVerbs)
+	grant(schema.GroupResource{Group: instances.Group, Resource: instances.Resource + "/status"}, []string{"get", "patch", "update"})
+	grant(schema.GroupResource{Group: instances.Group, Resource: instances.Resource + "/finalizers"}, []string{"update"})
+
+	// The resources applied to remote clusters are managed with the
+	// credentials of their kubeconfig, which kro reads from a Secret.
+	var targets bool
+	if rgd.Spec.Target != nil {
+		targets = true
+	} else if hasJobHooks(rgd.Spec.Hooks) {
+		if err := grantKind(jobGVK, manageVerbs); err != nil {
+			return nil, err
+		}
+	}
+	for _, resource := range rgd.Spec.Resources {
+		if resource == nil {
+			continue
+		}
+		if resource.Target != nil || rgd.Spec.Target != nil {
+			targets = true
+			continue
+		}
+		switch {
+		case resource.ExternalRef != nil:
+			gvk, err := externalRefGVK(resource.ExternalRef)
+			if err != nil {
+				return nil, fmt.Errorf("resource %s: %w", resource.ID, err)
+			}
+			if err := grantKind(gvk, readVerbs); err != nil {
+				return nil, err
+			}
+		default:
+			gvk, err := templateGVK(resource.Template.Raw)
+			if err != nil {
+				return nil, fmt.Errorf("resource %s: %w", resource.ID, err)
+			}
+			if err := grantKind(gvk, manageVerbs); err != nil {
+				return nil, err
+			}
+		}
+		for _, waitFor := range resource.WaitFor {
+			if waitFor == nil {
+				continue
+			}
+			gvk, err := externalRefGVK(&waitFor.ExternalRef)
+			if err != nil {
+				return nil, fmt.Errorf("resource %s, waitFor %s: %w", resource.ID, waitFor.ID, err)
+			}
+			if err := grantKind(gvk, readVerbs); err != nil {
+				return nil, err
+			}
+		}
+		if hasJobHooks(resource.Hooks) {
+			if err := grantKind(jobGVK, manageVerbs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if targets {
+		grant(schema.GroupResource{Resource: "secrets"}, []string{"get"})
+	}
+	if rgd.Spec.NamespaceSelector != nil {
+		grant(schema.GroupResource{Resource: "namespaces"}, []string{"get"})
+	}
+	if rgd.Spec.RollbackOnFailure {
+		grant(schema.GroupResource{Resource: "secrets"}, []string{"get", "patch"})
+	}
+	for _, source := range rgd.Spec.ValuesFrom {
+		if source.ConfigMapRef != nil {
+			grant(schema.GroupResource{Resource: "configmaps"}, readVerbs)
+		}
+		if source.SecretRef != nil {
+			grant(schema.GroupResource{Resource: "secrets"}, readVerbs)
+		}
+	}
+
+	return mergeRules(verbs), nil
+}
+
+// mergeRules returns one rule per API group and set of verbs.
+func mergeRules(verbs map[schema.GroupResource][]string) []rbacv1.PolicyRule {
+	type ruleKey struct {
+		group string
+		verbs string
+	}
+	rules := map[ruleKey]*rbacv1.PolicyRule{}
+	for gr, grVerbs := range verbs {
+		key := ruleKey{group: gr.Group, verbs: strings.Join(grVerbs, ",")}
+		rule, ok := rules[key]
+		if !ok {
+			rule = &rbacv1.PolicyRule{APIGroups: []string{gr.Group}, Verbs: grVerbs}
+			rules[key] = rule
+		}
+		rule.Resources = append(rule.Resources, gr.Resource)
+	}
+
+	merged := make([]rbacv1.PolicyRule, 0, len(rules))
+	for _, rule := range rules {
+		slices.Sort(rule.Resources)
+		merged = append(merged, *rule)
+	}
+	slices.SortFunc(merged, func(a, b rbacv1.PolicyRule) int {
+		if c := strings.Compare(a.APIGroups[0], b.APIGroups[0]); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Resources[0], b.Resources[0])
+	})
+	return merged
+}
+
+// ClusterRole returns a ClusterRole with the given rules, named after the
+// given ResourceGraphDefinition. It's labeled to be aggregated into the
+// ClusterRole of kro.
+func ClusterRole(rgd *v1alpha1.ResourceGraphDefinition, rules []rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   roleName(rgd),
+			Labels: map[string]string{AggregateToControllerLabel: "true"},
+		},
+		Rules: rules,
+	}
+}
+
+// ClusterRoleBinding returns a ClusterRoleBinding granting the ClusterRole of
+// the given ResourceGraphDefinition to the given service account.
+func ClusterRoleBinding(rgd *v1alpha1.ResourceGraphDefinition, serviceAccount types.NamespacedName) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{Name: roleName(rgd)},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     roleName(rgd),
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      serviceAccount.Name,
+			Namespace: serviceAccount.Namespace,
+		}},
+	}
+}
+
+func roleName(rgd *v1alpha1.ResourceGraphDefinition) string {
+	return "kro:controller:" + rgd.Name
+}
+
+func hasJobHooks(hooks *v1alpha1.Hooks) bool {
+	if hooks == nil {
+		return false
+	}
+	isJob := func(hook *v1alpha1.Hook) bool { return hook != nil && hook.Job != nil }
+	return slices.ContainsFunc(hooks.PreApply, isJob) || slices.ContainsFunc(hooks.PostApply, isJob)
+}
+
+func externalRefGVK(ref *v1alpha1.ExternalRef) (schema.GroupVersionKind, error) {
+	return parseGVK(ref.APIVersion, ref.Kind)
+}
+
+func templateGVK(raw []byte) (schema.GroupVersionKind, error) {
+	var template struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := json.Unmarshal(raw, &template); err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return parseGVK(template.APIVersion, template.Kind)
+}
+
+func parseGVK(apiVersion, kind string) (schema.GroupVersionKind, error) {
+	if apiVersion == "" || kind == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("apiVersion and kind are required")
+	}
+	if strings.Contains(apiVersion, "${") || strings.Contains(kind, "${") {
+		return schema.GroupVersionKind{}, fmt.Errorf("apiVersion and kind can't be expressions")
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid apiVersion %q: %w", apiVersion, err)
+	}
+	return gv.WithKind(kind), nil
+}
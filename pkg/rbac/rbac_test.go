@@ -0,0 +1,198 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rbac
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+func newRGD(resources ...*v1alpha1.Resource) *v1alpha1.ResourceGraphDefinition {
+	return &v1alpha1.ResourceGraphDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "webapp"},
+		Spec: v1alpha1.ResourceGraphDefinitionSpec{
+			Schema:    &v1alpha1.Schema{APIVersion: "v1alpha1", Kind: "WebApp"},
+			Resources: resources,
+		},
+	}
+}
+
+func template(apiVersion, kind string) runtime.RawExtension {
+	return runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"apiVersion":%q,"kind":%q,"metadata":{"name":"${schema.metadata.name}"}}`, apiVersion, kind))}
+}
+
+func TestRules(t *testing.T) {
+	rgd := newRGD(
+		&v1alpha1.Resource{ID: "deployment", Template: template("apps/v1", "Deployment")},
+		&v1alpha1.Resource{ID: "service", Template: template("v1", "Service")},
+		&v1alpha1.Resource{
+			ID:       "config",
+			Template: template("v1", "ConfigMap"),
+			WaitFor: []*v1alpha1.WaitFor{{
+				ID:          "certificate",
+				ExternalRef: v1alpha1.ExternalRef{APIVersion: "cert-manager.io/v1", Kind: "Certificate"},
+			}},
+			Hooks: &v1alpha1.Hooks{PreApply: []*v1alpha1.Hook{{ID: "migrate", Job: &runtime.RawExtension{}}}},
+		},
+		&v1alpha1.Resource{
+			ID:          "secret",
+			ExternalRef: &v1alpha1.ExternalRef{APIVersion: "v1", Kind: "Secret"},
+		},
+		&v1alpha1.Resource{
+			ID:          "database",
+			ExternalRef: &v1alpha1.ExternalRef{APIVersion: "kro.run/v1alpha1", Kind: "Database"},
+		},
+	)
+
+	rules, err := Rules(rgd, PluralResolver)
+	require.NoError(t, err)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "services"}, Verbs: manageVerbs},
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: readVerbs},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: manageVerbs},
+		{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: manageVerbs},
+		{APIGroups: []string{"cert-manager.io"}, Resources: []string{"certificates"}, Verbs: readVerbs},
+		{APIGroups: []string{"kro.run"}, Resources: []string{"databases"}, Verbs: readVerbs},
+		{APIGroups: []string{"kro.run"}, Resources: []string{"webapps"}, Verbs: instanceVerbs},
+		{APIGroups: []string{"kro.run"}, Resources: []string{"webapps/finalizers"}, Verbs: []string{"update"}},
+		{APIGroups: []string{"kro.run"}, Resources: []string{"webapps/status"}, Verbs: []string{"get", "patch", "update"}},
+	}, rules)
+}
+
+func TestRulesMergeVerbs(t *testing.T) {
+	// A kind both read and managed is granted the verbs of both.
+	rgd := newRGD(
+		&v1alpha1.Resource{ID: "config", Template: template("v1", "ConfigMap")},
+		&v1alpha1.Resource{ID: "shared", ExternalRef: &v1alpha1.ExternalRef{APIVersion: "v1", Kind: "ConfigMap"}},
+	)
+	rules, err := Rules(rgd, PluralResolver)
+	require.NoError(t, err)
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: manageVerbs})
+}
+
+func TestRulesTargets(t *testing.T) {
+	// The resources of remote clusters only need their kubeconfig Secret.
+	target := &v1alpha1.Target{KubeconfigSecretRef: v1alpha1.KubeconfigSecretReference{Namespace: "clusters", Name: "spoke"}}
+	rgd := newRGD(
+		&v1alpha1.Resource{ID: "config", Template: template("v1", "ConfigMap")},
+		&v1alpha1.Resource{ID: "deployment", Template: template("apps/v1", "Deployment"), Target: target},
+	)
+	rules, err := Rules(rgd, PluralResolver)
+	require.NoError(t, err)
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: manageVerbs})
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}})
+	assert.NotContains(t, rules, rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: manageVerbs})
+}
+
+func TestRulesNamespaceSelector(t *testing.T) {
+	rgd := newRGD(&v1alpha1.Resource{ID: "config", Template: template("v1", "ConfigMap")})
+	rgd.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}
+	rules, err := Rules(rgd, PluralResolver)
+	require.NoError(t, err)
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get"}})
+}
+
+func TestRulesRollbackOnFailure(t *testing.T) {
+	rgd := newRGD(&v1alpha1.Resource{ID: "config", Template: template("v1", "ConfigMap")})
+	rgd.Spec.RollbackOnFailure = true
+	rules, err := Rules(rgd, PluralResolver)
+	require.NoError(t, err)
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "patch"}})
+}
+
+func TestRulesValuesFrom(t *testing.T) {
+	rgd := newRGD(&v1alpha1.Resource{ID: "deployment", Template: template("apps/v1", "Deployment")})
+	rgd.Spec.ValuesFrom = []v1alpha1.ValuesSource{
+		{ConfigMapRef: &v1alpha1.ValuesReference{Name: "environment"}},
+		{SecretRef: &v1alpha1.ValuesReference{Name: "credentials"}},
+	}
+	rules, err := Rules(rgd, PluralResolver)
+	require.NoError(t, err)
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: readVerbs})
+
+	// The Secrets of the revisions are granted the verbs of both.
+	rgd.Spec.RollbackOnFailure = true
+	rules, err = Rules(rgd, PluralResolver)
+	require.NoError(t, err)
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: readVerbs})
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list", "patch", "watch"}})
+}
+
+func TestRulesResolver(t *testing.T) {
+	rgd := newRGD(&v1alpha1.Resource{ID: "policy", Template: template("networking.k8s.io/v1", "NetworkPolicy")})
+
+	rules, err := Rules(rgd, func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+		return gvk.GroupVersion().WithResource("netpols"), nil
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rules, rbacv1.PolicyRule{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"netpols"}, Verbs: manageVerbs})
+
+	_, err = Rules(rgd, func(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+		return schema.GroupVersionResource{}, fmt.Errorf("no matches for kind %s", gvk.Kind)
+	})
+	assert.ErrorContains(t, err, "no matches for kind NetworkPolicy")
+}
+
+func TestRulesErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		resource *v1alpha1.Resource
+		err      string
+	}{
+		{
+			name:     "missing kind",
+			resource: &v1alpha1.Resource{ID: "a", Template: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1"}`)}},
+			err:      "resource a: apiVersion and kind are required",
+		},
+		{
+			name:     "expression kind",
+			resource: &v1alpha1.Resource{ID: "a", Template: template("v1", "${schema.spec.kind}")},
+			err:      "resource a: apiVersion and kind can't be expressions",
+		},
+		{
+			name:     "invalid apiVersion",
+			resource: &v1alpha1.Resource{ID: "a", ExternalRef: &v1alpha1.ExternalRef{APIVersion: "a/b/c", Kind: "Secret"}},
+			err:      `resource a: invalid apiVersion "a/b/c"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Rules(newRGD(tc.resource), PluralResolver)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestManifests(t *testing.T) {
+	rgd := newRGD()
+	role := ClusterRole(rgd, []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: readVerbs}})
+	assert.Equal(t, "kro:controller:webapp", role.Name)
+	assert.Equal(t, "ClusterRole", role.Kind)
+	assert.Equal(t, map[string]string{AggregateToControllerLabel: "true"}, role.Labels)
+
+	binding := ClusterRoleBinding(rgd, types.NamespacedName{Namespace: "kro", Name: "kro"})
+	assert.Equal(t, "kro:controller:webapp", binding.Name)
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "kro:controller:webapp"}, binding.RoleRef)
+	assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Namespace: "kro", Name: "kro"}}, binding.Subjects)
+}
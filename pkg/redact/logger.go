@@ -0,0 +1,86 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package redact
+
+import (
+	"github.com/go-logr/logr"
+)
+
+// Logger returns a logger redacting the sensitive values from the messages,
+// errors and values logged through the given logger.
+func (r *Redactor) Logger(log logr.Logger) logr.Logger {
+	if r == nil || log.GetSink() == nil {
+		return log
+	}
+	inner := log.GetSink()
+	// The sink adds a frame between the logger and the inner sink.
+	if callDepthSink, ok := inner.(logr.CallDepthLogSink); ok {
+		inner = callDepthSink.WithCallDepth(1)
+	}
+	return log.WithSink(&sink{inner: inner, redactor: r})
+}
+
+// sink is a logr.LogSink redacting the sensitive values before handing them
+// over to the inner sink.
+type sink struct {
+	inner    logr.LogSink
+	redactor *Redactor
+}
+
+var _ logr.CallDepthLogSink = &sink{}
+
+func (s *sink) Init(info logr.RuntimeInfo) {
+	// The inner sink is already initialized by the logger it comes from.
+}
+
+func (s *sink) Enabled(level int) bool {
+	return s.inner.Enabled(level)
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.inner.Info(level, s.redactor.String(msg), s.keysAndValues(keysAndValues)...)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.inner.Error(s.redactor.Error(err), s.redactor.String(msg), s.keysAndValues(keysAndValues)...)
+}
+
+func (s *sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &sink{inner: s.inner.WithValues(s.keysAndValues(keysAndValues)...), redactor: s.redactor}
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	return &sink{inner: s.inner.WithName(name), redactor: s.redactor}
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	if callDepthSink, ok := s.inner.(logr.CallDepthLogSink); ok {
+		return &sink{inner: callDepthSink.WithCallDepth(depth), redactor: s.redactor}
+	}
+	return s
+}
+
+// keysAndValues redacts the values of the given key and value pairs.
+func (s *sink) keysAndValues(keysAndValues []interface{}) []interface{} {
+	redacted := make([]interface{}, len(keysAndValues))
+	for i, value := range keysAndValues {
+		if i%2 == 0 {
+			// keys are never sensitive.
+			redacted[i] = value
+			continue
+		}
+		redacted[i] = s.redactor.redactAny(value)
+	}
+	return redacted
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package redact removes sensitive values from the outputs of kro, such as
+// the status of instances, events and logs.
+package redact
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Placeholder is the text sensitive values are replaced with.
+const Placeholder = "[REDACTED]"
+
+// Redactor replaces a set of sensitive values with the Placeholder. A nil
+// Redactor leaves values untouched.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// New creates a Redactor of the given sensitive values. Empty values are
+// ignored.
+func New(values []string) *Redactor {
+	seen := make(map[string]struct{}, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok || value == "" {
+			continue
+		}
+		seen[value] = struct{}{}
+		unique = append(unique, value)
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	// Longer values are replaced first, so that values containing other
+	// values are fully redacted.
+	sort.Slice(unique, func(i, j int) bool {
+		return len(unique[i]) > len(unique[j])
+	})
+	replacements := make([]string, 0, 2*len(unique))
+	for _, value := range unique {
+		replacements = append(replacements, value, Placeholder)
+	}
+	return &Redactor{replacer: strings.NewReplacer(replacements...)}
+}
+
+// String returns the given string with the sensitive values redacted.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+// Value returns a copy of the given value, with the sensitive values redacted
+// from all the strings it contains. Values are expected to be made of maps,
+// arrays and scalars, like unstructured objects.
+func (r *Redactor) Value(value interface{}) interface{} {
+	if r == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case string:
+		return r.String(v)
+	case map[string]interface{}:
+		return r.Object(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.Value(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// Object returns a copy of the given object, with the sensitive values
+// redacted from all the strings it contains.
+func (r *Redactor) Object(obj map[string]interface{}) map[string]interface{} {
+	if r == nil || obj == nil {
+		return obj
+	}
+	redacted := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		redacted[key] = r.Value(value)
+	}
+	return redacted
+}
+
+// Error returns an error with the message of the given error, with the
+// sensitive values redacted. The original error is returned if its message
+// has no sensitive value.
+func (r *Redactor) Error(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+	message := err.Error()
+	redacted := r.String(message)
+	if redacted == message {
+		return err
+	}
+	return &redactedError{message: redacted, err: err}
+}
+
+// redactedError is an error whose message is redacted. It wraps the original
+// error, so that it can still be inspected with errors.Is and errors.As.
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactAny redacts the given value if its string representation contains a
+// sensitive value. It is used for values of unknown types, e.g the key and
+// values of log lines.
+func (r *Redactor) redactAny(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return r.String(v)
+	case error:
+		return r.Error(v)
+	default:
+		s := fmt.Sprint(v)
+		if redacted := r.String(s); redacted != s {
+			return redacted
+		}
+		return value
+	}
+}
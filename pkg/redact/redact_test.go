@@ -0,0 +1,108 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor(t *testing.T) {
+	r := New([]string{"s3cr3t", "", "s3cr3t-token", "s3cr3t"})
+
+	assert.Equal(t, "password is [REDACTED], token is [REDACTED]", r.String("password is s3cr3t, token is s3cr3t-token"))
+	assert.Equal(t, "nothing to hide", r.String("nothing to hide"))
+
+	obj := map[string]interface{}{
+		"connection": "postgres://admin:s3cr3t@db",
+		"replicas":   int64(3),
+		"endpoints":  []interface{}{"s3cr3t", map[string]interface{}{"url": "s3cr3t-token"}},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"connection": "postgres://admin:[REDACTED]@db",
+		"replicas":   int64(3),
+		"endpoints":  []interface{}{"[REDACTED]", map[string]interface{}{"url": "[REDACTED]"}},
+	}, r.Object(obj))
+	// The original object is left untouched.
+	assert.Equal(t, "postgres://admin:s3cr3t@db", obj["connection"])
+}
+
+func TestRedactorError(t *testing.T) {
+	r := New([]string{"s3cr3t"})
+	sentinel := errors.New("evaluation failed")
+
+	err := r.Error(fmt.Errorf("value s3cr3t is invalid: %w", sentinel))
+	assert.EqualError(t, err, "value [REDACTED] is invalid: evaluation failed")
+	assert.ErrorIs(t, err, sentinel)
+
+	clean := errors.New("nothing to hide")
+	assert.Same(t, clean, r.Error(clean))
+	assert.Nil(t, r.Error(nil))
+}
+
+func TestNilRedactor(t *testing.T) {
+	var r *Redactor
+	assert.Nil(t, New(nil))
+	assert.Nil(t, New([]string{""}))
+	assert.Equal(t, "s3cr3t", r.String("s3cr3t"))
+	assert.Equal(t, map[string]interface{}{"a": "s3cr3t"}, r.Object(map[string]interface{}{"a": "s3cr3t"}))
+}
+
+func TestRedactorLogger(t *testing.T) {
+	var lines []string
+	log := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+
+	r := New([]string{"s3cr3t"})
+	redacted := r.Logger(log).WithValues("password", "s3cr3t")
+	redacted.Info("using s3cr3t", "value", []string{"s3cr3t"})
+	redacted.Error(errors.New("bad s3cr3t"), "failed")
+
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.False(t, strings.Contains(line, "s3cr3t"), "line leaks the sensitive value: %s", line)
+		assert.Contains(t, line, Placeholder)
+	}
+}
+
+func TestValuesAt(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"database": map[string]interface{}{
+				"user":     "admin",
+				"password": "s3cr3t",
+			},
+			"tokens": []interface{}{"t1", "t2"},
+			"apiKeys": map[string]interface{}{
+				"github": "k1",
+			},
+			"replicas": int64(3),
+		},
+	}
+
+	got := ValuesAt(obj, []string{
+		"spec.database.password",
+		"spec.tokens.*",
+		"spec.apiKeys.*",
+		"spec.replicas",
+		"spec.missing.field",
+	})
+	assert.ElementsMatch(t, []string{"s3cr3t", "t1", "t2", "k1"}, got)
+}
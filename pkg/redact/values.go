@@ -0,0 +1,73 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package redact
+
+import (
+	"strings"
+)
+
+// ValuesAt returns the string values found at the given paths of the given
+// object. A `*` segment matches all the items of an array or the values of a
+// map, e.g `spec.tokens.*`. Strings nested in the values found are returned
+// as well.
+func ValuesAt(obj map[string]interface{}, paths []string) []string {
+	var values []string
+	for _, path := range paths {
+		values = collect(obj, strings.Split(path, "."), values)
+	}
+	return values
+}
+
+func collect(value interface{}, segments []string, values []string) []string {
+	if len(segments) == 0 {
+		return collectStrings(value, values)
+	}
+
+	segment, rest := segments[0], segments[1:]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if segment == "*" {
+			for _, item := range v {
+				values = collect(item, rest, values)
+			}
+			return values
+		}
+		if item, ok := v[segment]; ok {
+			return collect(item, rest, values)
+		}
+	case []interface{}:
+		if segment == "*" {
+			for _, item := range v {
+				values = collect(item, rest, values)
+			}
+		}
+	}
+	return values
+}
+
+func collectStrings(value interface{}, values []string) []string {
+	switch v := value.(type) {
+	case string:
+		return append(values, v)
+	case map[string]interface{}:
+		for _, item := range v {
+			values = collectStrings(item, values)
+		}
+	case []interface{}:
+		for _, item := range v {
+			values = collectStrings(item, values)
+		}
+	}
+	return values
+}
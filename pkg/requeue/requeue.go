@@ -45,10 +45,10 @@ func NeededAfter(
 	duration time.Duration,
 ) *RequeueNeededAfter {
 	return &RequeueNeededAfter{
-		RequeueNeeded{
+		RequeueNeeded: RequeueNeeded{
 			err: err,
 		},
-		duration,
+		duration: duration,
 	}
 }
 
@@ -111,6 +111,7 @@ var _ error = &RequeueNeeded{}
 type RequeueNeededAfter struct {
 	RequeueNeeded
 	duration time.Duration
+	backoff  *Backoff
 }
 
 func (e *RequeueNeededAfter) Error() string {
@@ -127,6 +128,22 @@ func (e *RequeueNeededAfter) Duration() time.Duration {
 	return e.duration
 }
 
+// WithBackoff sets the backoff policy the processing item is requeued with,
+// instead of the duration.
+func (e *RequeueNeededAfter) WithBackoff(backoff *Backoff) *RequeueNeededAfter {
+	e.backoff = backoff
+	return e
+}
+
+// Backoff returns the backoff policy the processing item is requeued with, or
+// nil if it's requeued after the duration.
+func (e *RequeueNeededAfter) Backoff() *Backoff {
+	if e == nil {
+		return nil
+	}
+	return e.backoff
+}
+
 func (e *RequeueNeededAfter) Unwrap() error {
 	if e == nil {
 		return nil
@@ -136,3 +153,25 @@ func (e *RequeueNeededAfter) Unwrap() error {
 
 // Ensure RequeueNeededAfter implements the error interface
 var _ error = &RequeueNeededAfter{}
+
+// Backoff is an exponential backoff policy: the delay starts at InitialDelay
+// and doubles with each consecutive retry, up to MaxDelay. Once MaxAttempts
+// consecutive retries happened, if set, the processing item isn't retried
+// anymore.
+type Backoff struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	MaxAttempts  int
+}
+
+// Delay returns the delay before the given retry, starting at 0.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.InitialDelay
+	for i := 0; i < attempt && delay < b.MaxDelay; i++ {
+		delay *= 2
+	}
+	if b.MaxDelay > 0 && delay > b.MaxDelay {
+		return b.MaxDelay
+	}
+	return delay
+}
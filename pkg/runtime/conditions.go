@@ -0,0 +1,119 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReasonSatisfied is the reason of the conditions whose
+	// expression evaluated to true.
+	ConditionReasonSatisfied = "Satisfied"
+	// ConditionReasonNotSatisfied is the reason of the conditions whose
+	// expression evaluated to false.
+	ConditionReasonNotSatisfied = "NotSatisfied"
+	// ConditionReasonAwaitingResources is the reason of the conditions
+	// depending on resources that aren't resolved yet.
+	ConditionReasonAwaitingResources = "AwaitingResources"
+	// ConditionReasonEvaluationFailed is the reason of the conditions whose
+	// expression couldn't be evaluated, typically because a field it
+	// references isn't set yet.
+	ConditionReasonEvaluationFailed = "EvaluationFailed"
+)
+
+// Condition is a condition of the instances declared by the resource graph
+// definition.
+type Condition struct {
+	// Type is the type of the condition.
+	Type string
+	// Expression is the CEL expression deciding the status of the condition.
+	// It evaluates to a boolean.
+	Expression string
+	// Message is the message of the condition when the expression evaluates
+	// to false.
+	Message string
+	// Dependencies are the resources the expression references.
+	Dependencies []string
+}
+
+// ConditionStatus is the evaluated status of a Condition.
+type ConditionStatus struct {
+	Type    string
+	Status  metav1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+// EvaluateConditions evaluates the conditions declared by the resource graph
+// definition against the instance and the resolved resources. Conditions
+// depending on resources that aren't resolved yet are Unknown.
+func (rt *ResourceGraphDefinitionRuntime) EvaluateConditions() []ConditionStatus {
+	statuses := make([]ConditionStatus, 0, len(rt.conditions))
+	for _, condition := range rt.conditions {
+		statuses = append(statuses, rt.evaluateCondition(condition))
+	}
+	return statuses
+}
+
+func (rt *ResourceGraphDefinitionRuntime) evaluateCondition(condition Condition) ConditionStatus {
+	status := ConditionStatus{Type: condition.Type, Status: metav1.ConditionUnknown}
+
+	var missing []string
+	for _, dep := range condition.Dependencies {
+		if _, ok := rt.resolvedResources[dep]; !ok {
+			missing = append(missing, dep)
+		}
+	}
+	if len(missing) > 0 {
+		status.Reason = ConditionReasonAwaitingResources
+		status.Message = fmt.Sprintf("waiting for resources %s", strings.Join(missing, ", "))
+		return status
+	}
+
+	variables := append([]string{"schema", "cluster", "values", "locals"}, condition.Dependencies...)
+	context := rt.schemaContext()
+	for _, dep := range condition.Dependencies {
+		context[dep] = rt.resolvedResources[dep].Object
+	}
+
+	value, err := rt.evaluate(variables, context, condition.Expression)
+	if err != nil {
+		status.Reason = ConditionReasonEvaluationFailed
+		status.Message = err.Error()
+		return status
+	}
+	satisfied, ok := value.(bool)
+	if !ok {
+		status.Reason = ConditionReasonEvaluationFailed
+		status.Message = fmt.Sprintf("expression %s did not evaluate to a boolean", condition.Expression)
+		return status
+	}
+
+	if satisfied {
+		status.Status = metav1.ConditionTrue
+		status.Reason = ConditionReasonSatisfied
+		return status
+	}
+	status.Status = metav1.ConditionFalse
+	status.Reason = ConditionReasonNotSatisfied
+	status.Message = condition.Message
+	if status.Message == "" {
+		status.Message = fmt.Sprintf("expression %s evaluated to false", condition.Expression)
+	}
+	return status
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runtime
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_EvaluateConditions(t *testing.T) {
+	database := Condition{
+		Type:         "DatabaseReady",
+		Expression:   "database.status.state == 'available' && schema.spec.enabled",
+		Dependencies: []string{"database"},
+	}
+
+	tests := []struct {
+		name           string
+		condition      Condition
+		resolvedObject map[string]interface{}
+		wantStatus     metav1.ConditionStatus
+		wantReason     string
+		wantMessage    string
+	}{
+		{
+			name:        "resource not resolved",
+			condition:   database,
+			wantStatus:  metav1.ConditionUnknown,
+			wantReason:  ConditionReasonAwaitingResources,
+			wantMessage: "waiting for resources database",
+		},
+		{
+			name:      "expression true",
+			condition: database,
+			resolvedObject: map[string]interface{}{
+				"status": map[string]interface{}{"state": "available"},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: ConditionReasonSatisfied,
+		},
+		{
+			name:      "expression false",
+			condition: database,
+			resolvedObject: map[string]interface{}{
+				"status": map[string]interface{}{"state": "creating"},
+			},
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  ConditionReasonNotSatisfied,
+			wantMessage: "expression database.status.state == 'available' && schema.spec.enabled evaluated to false",
+		},
+		{
+			name: "expression false with message",
+			condition: Condition{
+				Type:         "DatabaseReady",
+				Expression:   database.Expression,
+				Message:      "the database is not available yet",
+				Dependencies: database.Dependencies,
+			},
+			resolvedObject: map[string]interface{}{
+				"status": map[string]interface{}{"state": "creating"},
+			},
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  ConditionReasonNotSatisfied,
+			wantMessage: "the database is not available yet",
+		},
+		{
+			name:           "field not set yet",
+			condition:      database,
+			resolvedObject: map[string]interface{}{},
+			wantStatus:     metav1.ConditionUnknown,
+			wantReason:     ConditionReasonEvaluationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestResource(withObject(map[string]interface{}{
+				"spec": map[string]interface{}{"enabled": true},
+			}))
+			rt := &ResourceGraphDefinitionRuntime{
+				instance:          instance,
+				resolvedResources: map[string]*unstructured.Unstructured{},
+				conditions:        []Condition{tt.condition},
+			}
+			if tt.resolvedObject != nil {
+				rt.resolvedResources["database"] = &unstructured.Unstructured{Object: tt.resolvedObject}
+			}
+
+			statuses := rt.EvaluateConditions()
+			if len(statuses) != 1 {
+				t.Fatalf("EvaluateConditions() returned %d statuses, want 1", len(statuses))
+			}
+			got := statuses[0]
+			if got.Type != tt.condition.Type {
+				t.Errorf("EvaluateConditions() type = %v, want %v", got.Type, tt.condition.Type)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("EvaluateConditions() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if got.Reason != tt.wantReason {
+				t.Errorf("EvaluateConditions() reason = %v, want %v", got.Reason, tt.wantReason)
+			}
+			if tt.wantMessage != "" && got.Message != tt.wantMessage {
+				t.Errorf("EvaluateConditions() message = %v, want %v", got.Message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+
+	krocel "github.com/kro-run/kro/pkg/cel"
+)
+
+// HookPhase is the phase of the apply of a resource a hook runs at.
+type HookPhase string
+
+const (
+	// HookPhasePreApply hooks must succeed before the resource is applied.
+	HookPhasePreApply HookPhase = "preApply"
+	// HookPhasePostApply hooks must succeed once the resource is ready,
+	// before the resources depending on it are applied.
+	HookPhasePostApply HookPhase = "postApply"
+)
+
+// CheckHooks evaluates the assertions of the hooks of the given phase of a
+// resource, or of the instance if the resource id is empty, against the
+// instance and the resolved resources. It returns false, along with the
+// reason, if an assertion doesn't hold.
+func (rt *ResourceGraphDefinitionRuntime) CheckHooks(resourceID string, phase HookPhase) (bool, string, error) {
+	var descriptor ResourceDescriptor = rt.instance
+	if resourceID != "" {
+		descriptor = rt.resources[resourceID]
+	}
+	expressions := descriptor.GetHookAssertions(phase)
+	if len(expressions) == 0 {
+		return true, "", nil
+	}
+
+	// Assertions can't refer to the resources expanded from a collection,
+	// whose ids aren't valid identifiers.
+	names := []string{"schema", "cluster", "values", "locals"}
+	context := rt.schemaContext()
+	for id, resource := range rt.resolvedResources {
+		if _, _, ok := expansionOf(rt.resources[id]); ok {
+			continue
+		}
+		names = append(names, id)
+		context[id] = resource.Object
+	}
+
+	for _, expression := range expressions {
+		out, err := rt.evaluate(names, context, expression)
+		if err != nil {
+			return false, "", krocel.NewExpressionError(resourceID, "hooks."+string(phase), expression, err)
+		}
+		if satisfied, ok := out.(bool); !ok || !satisfied {
+			return false, fmt.Sprintf("%s hook %s evaluated to false", phase, expression), nil
+		}
+	}
+	return true, "", nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_CheckHooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		phase      HookPhase
+		database   map[string]interface{}
+		wantOK     bool
+		wantReason string
+		wantErr    bool
+	}{
+		{
+			name:   "no assertions",
+			phase:  HookPhasePostApply,
+			wantOK: true,
+		},
+		{
+			name:     "assertion holds",
+			phase:    HookPhasePreApply,
+			database: map[string]interface{}{"status": map[string]interface{}{"endpoint": "db:5432"}},
+			wantOK:   true,
+		},
+		{
+			name:       "assertion doesn't hold",
+			phase:      HookPhasePreApply,
+			database:   map[string]interface{}{"status": map[string]interface{}{"endpoint": ""}},
+			wantReason: "preApply hook database.status.endpoint != '' && schema.spec.enabled evaluated to false",
+		},
+		{
+			name:     "field not set yet",
+			phase:    HookPhasePreApply,
+			database: map[string]interface{}{},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &ResourceGraphDefinitionRuntime{
+				instance: newTestResource(withObject(map[string]interface{}{
+					"spec": map[string]interface{}{"enabled": true},
+				})),
+				resources: map[string]Resource{
+					"app": newTestResource(withHooks(HookPhasePreApply, []string{
+						"database.status.endpoint != '' && schema.spec.enabled",
+					})),
+				},
+				resolvedResources: map[string]*unstructured.Unstructured{},
+			}
+			if tt.database != nil {
+				rt.resolvedResources["database"] = &unstructured.Unstructured{Object: tt.database}
+			}
+
+			ok, reason, err := rt.CheckHooks("app", tt.phase)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
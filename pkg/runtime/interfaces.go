@@ -14,9 +14,13 @@
 package runtime
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
 	"github.com/kro-run/kro/pkg/graph/variable"
 )
 
@@ -66,8 +70,16 @@ type Interface interface {
 	WantToCreateResource(resourceID string) (bool, error)
 
 	// IgnoreResource ignores resource that has a condition expressison that evaluated
-	// to false
-	IgnoreResource(resourceID string)
+	// to false, or failed to evaluate with the given error, as returned by
+	// WantToCreateResource.
+	IgnoreResource(resourceID string, err error)
+
+	// EvaluateConditions returns the status of the conditions of the instance
+	// declared by the resource graph definition.
+	EvaluateConditions() []ConditionStatus
+	// CheckHooks returns true if the assertions of the hooks of the given
+	// phase of a resource, or of the instance if the id is empty, hold.
+	CheckHooks(resourceID string, phase HookPhase) (bool, string, error)
 }
 
 // ResourceDescriptor provides metadata about a resource.
@@ -112,6 +124,54 @@ type ResourceDescriptor interface {
 	// IsNamespaced returns true if the resource is namespaced, and false if it's
 	// cluster-scoped.
 	IsNamespaced() bool
+
+	// IsExternalRef returns true if the resource is a reference to an existing
+	// object, which is read but never created, updated or deleted. Patches
+	// are external references too, whose objects are patched.
+	IsExternalRef() bool
+
+	// GetPatchType returns the type of the patch the resource applies to an
+	// existing object, or an empty string if it isn't a patch.
+	GetPatchType() v1alpha1.PatchType
+
+	// GetReadinessTimeout returns how long the resource can take to become
+	// ready once created, zero meaning forever.
+	GetReadinessTimeout() time.Duration
+
+	// GetOnFailure returns what to do once the readiness timeout of the
+	// resource has passed.
+	GetOnFailure() v1alpha1.FailurePolicy
+
+	// GetRetryPolicy returns the policy of the retries while waiting for the
+	// resource, or nil if there's none.
+	GetRetryPolicy() *v1alpha1.RetryPolicy
+
+	// IsAutoHeal returns true if the changes made to the resource outside of
+	// kro are reverted.
+	IsAutoHeal() bool
+
+	// GetConflictPolicy returns what to do with the fields of the resource
+	// managed by other field managers.
+	GetConflictPolicy() v1alpha1.ConflictPolicy
+
+	// GetIgnoreFields returns the paths of the fields of the resource kept as
+	// they are once it's created.
+	GetIgnoreFields() [][]fieldpath.Segment
+
+	// GetDeletionPolicy returns what to do with the resource when it's no
+	// longer part of the instance.
+	GetDeletionPolicy() v1alpha1.DeletionPolicy
+
+	// GetAdoptionPolicy returns what to do when the resource already exists
+	// but isn't managed by the instance.
+	GetAdoptionPolicy() v1alpha1.AdoptionPolicy
+	// GetHookAssertions returns the expressions of the assertions of the
+	// hooks of the given phase.
+	GetHookAssertions(phase HookPhase) []string
+
+	// GetTarget returns the remote cluster the resource is applied to and
+	// watched in, or nil if it's the cluster of kro.
+	GetTarget() *v1alpha1.Target
 }
 
 // Resource extends `ResourceDescriptor` to include the actual resource data.
@@ -122,3 +182,27 @@ type Resource interface {
 	// object.
 	Unstructured() *unstructured.Unstructured
 }
+
+// ExpandedResource is implemented by the resources expanded from a resource
+// template iterating over a collection of the instance. The expressions of
+// their template are evaluated with their item, available as `each`.
+type ExpandedResource interface {
+	// GetTemplateID returns the id of the resource template the resource was
+	// expanded from. The readyWhen expressions refer to the resource by this
+	// id.
+	GetTemplateID() string
+
+	// GetEach returns the value of `each` for the resource, or nil if the
+	// resource wasn't expanded from a template.
+	GetEach() map[string]interface{}
+}
+
+// expansionOf returns the template id and the `each` value of the given
+// resource, if it was expanded from a template.
+func expansionOf(r ResourceDescriptor) (string, map[string]interface{}, bool) {
+	expanded, ok := r.(ExpandedResource)
+	if !ok || expanded.GetEach() == nil {
+		return "", nil, false
+	}
+	return expanded.GetTemplateID(), expanded.GetEach(), true
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/variable"
+	"github.com/kro-run/kro/pkg/runtime/resolver"
+)
+
+// RenderedResource is a resource of the instance rendered without a cluster.
+type RenderedResource struct {
+	// ID is the id of the resource.
+	ID string
+	// Object is the rendered object. The fields set by unresolved expressions
+	// still hold them.
+	Object *unstructured.Unstructured
+	// External is true if the resource is an external reference, which is
+	// read from the cluster instead of being rendered.
+	External bool
+	// Excluded is true if the resource isn't created for the instance, and
+	// ExcludedReason tells why.
+	Excluded       bool
+	ExcludedReason string
+	// Unresolved are the expressions of the resource which can only be
+	// evaluated at runtime.
+	Unresolved []UnresolvedExpression
+}
+
+// UnresolvedExpression is an expression of a resource which can only be
+// evaluated at runtime.
+type UnresolvedExpression struct {
+	// Field is the path of the field the expression sets, e.g
+	// `template.spec.host`.
+	Field string
+	// Expression is the expression, without its `${}` delimiters.
+	Expression string
+}
+
+// Render renders the resources of the instance without a cluster, in
+// topological order.
+//
+// Every rendered resource is observed by its dependents as it's rendered, so
+// that the expressions reading the fields of their templates are evaluated.
+// The expressions reading what only the cluster sets, e.g the status of the
+// resources, or reading the external references or the resources not fully
+// rendered, can only be evaluated at runtime: they are left in the rendered
+// objects and reported as unresolved.
+func (rt *ResourceGraphDefinitionRuntime) Render() ([]RenderedResource, error) {
+	rendered := make([]RenderedResource, 0, len(rt.topologicalOrder))
+	for _, id := range rt.topologicalOrder {
+		resource := RenderedResource{ID: id}
+		if rt.resources[id].IsExternalRef() {
+			resource.External = true
+			resource.Object = rt.resources[id].Unstructured().DeepCopy()
+			rendered = append(rendered, resource)
+			continue
+		}
+		if want, err := rt.WantToCreateResource(id); err != nil || !want {
+			rt.IgnoreResource(id, err)
+			resource.Excluded = true
+			if err != nil {
+				resource.ExcludedReason = err.Error()
+			} else {
+				resource.ExcludedReason = "a dependency is excluded"
+			}
+			rendered = append(rendered, resource)
+			continue
+		}
+
+		unresolved, err := rt.renderDynamicVariables(id)
+		if err != nil {
+			return nil, err
+		}
+		resource.Unresolved = unresolved
+		if len(unresolved) == 0 {
+			if err := rt.propagateResourceVariables(); err != nil {
+				return nil, err
+			}
+		}
+		if obj, state := rt.GetResource(id); state == ResourceStateResolved {
+			resource.Object = obj.DeepCopy()
+			rt.SetResource(id, resource.Object)
+		} else {
+			obj, err := rt.renderPartially(id)
+			if err != nil {
+				return nil, err
+			}
+			resource.Object = obj
+		}
+		rendered = append(rendered, resource)
+	}
+	return rendered, nil
+}
+
+// renderDynamicVariables evaluates the dynamic variables of the given
+// resource against the resources rendered so far, and returns the ones that
+// can only be evaluated at runtime.
+func (rt *ResourceGraphDefinitionRuntime) renderDynamicVariables(id string) ([]UnresolvedExpression, error) {
+	var unresolved []UnresolvedExpression
+	// The variables sharing an expression share its state.
+	seen := make(map[*expressionEvaluationState]bool)
+	for _, variable := range rt.runtimeVariables[id] {
+		if !variable.Kind.IsDynamic() || variable.Resolved || seen[variable] {
+			continue
+		}
+		seen[variable] = true
+		runtimeOnly := UnresolvedExpression{Field: variable.Field, Expression: variable.Expression}
+		if !containsAllElements(maps.Keys(rt.resolvedResources), variable.Dependencies) {
+			unresolved = append(unresolved, runtimeOnly)
+			continue
+		}
+		value, err := rt.evaluateDynamicVariable(variable)
+		if err != nil {
+			// The fields set by the cluster are missing from the rendered
+			// resources.
+			if strings.Contains(err.Error(), "no such key") {
+				unresolved = append(unresolved, runtimeOnly)
+				continue
+			}
+			return nil, krocel.NewExpressionError(variable.ResourceID, variable.Field, variable.Expression, err)
+		}
+		variable.Resolved = true
+		variable.ResolvedValue = value
+	}
+	return unresolved, nil
+}
+
+// renderPartially returns the object of the given resource with the fields
+// whose expressions are all resolved set.
+func (rt *ResourceGraphDefinitionRuntime) renderPartially(id string) (*unstructured.Unstructured, error) {
+	exprValues := make(map[string]interface{})
+	for _, v := range rt.runtimeVariables[id] {
+		if v.Resolved {
+			exprValues[v.Expression] = v.ResolvedValue
+		}
+	}
+
+	var fields []variable.FieldDescriptor
+	for _, v := range rt.resources[id].GetVariables() {
+		resolved := true
+		for _, expr := range v.Expressions {
+			if _, ok := exprValues[expr]; !ok {
+				resolved = false
+				break
+			}
+		}
+		if resolved {
+			fields = append(fields, v.FieldDescriptor)
+		}
+	}
+
+	obj := rt.resources[id].Unstructured().DeepCopy()
+	summary := resolver.NewResolver(obj.Object, exprValues).Resolve(fields)
+	if summary.Errors != nil {
+		return nil, fmt.Errorf("failed to resolve resource %s: %v", id, summary.Errors)
+	}
+	return obj, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kro-run/kro/pkg/graph/variable"
+)
+
+func Test_Render(t *testing.T) {
+	instance := newTestResource(
+		withObject(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"appName": "myapp",
+				"debug":   false,
+			},
+		}),
+	)
+
+	configMap := newTestResource(
+		withObject(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "${schema.spec.appName}",
+			},
+		}),
+		withVariables([]*variable.ResourceField{
+			{
+				FieldDescriptor: variable.FieldDescriptor{
+					Path:                 "metadata.name",
+					Expressions:          []string{"schema.spec.appName"},
+					StandaloneExpression: true,
+				},
+				Kind: variable.ResourceVariableKindStatic,
+			},
+		}),
+	)
+
+	// The secret reads the name of the configmap, set by its template, and its
+	// uid, only set by the cluster.
+	secret := newTestResource(
+		withObject(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "${configmap.metadata.name}-secret",
+			},
+			"stringData": map[string]interface{}{
+				"owner": "${configmap.metadata.uid}",
+			},
+		}),
+		withVariables([]*variable.ResourceField{
+			{
+				FieldDescriptor: variable.FieldDescriptor{
+					Path:        "metadata.name",
+					Expressions: []string{"configmap.metadata.name"},
+				},
+				Kind:         variable.ResourceVariableKindDynamic,
+				Dependencies: []string{"configmap"},
+			},
+			{
+				FieldDescriptor: variable.FieldDescriptor{
+					Path:                 "stringData.owner",
+					Expressions:          []string{"configmap.metadata.uid"},
+					StandaloneExpression: true,
+				},
+				Kind:         variable.ResourceVariableKindDynamic,
+				Dependencies: []string{"configmap"},
+			},
+		}),
+		withDependencies([]string{"configmap"}),
+	)
+
+	// The service reads the secret, which isn't fully rendered.
+	service := newTestResource(
+		withObject(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "${secret.metadata.name}",
+			},
+		}),
+		withVariables([]*variable.ResourceField{
+			{
+				FieldDescriptor: variable.FieldDescriptor{
+					Path:                 "metadata.name",
+					Expressions:          []string{"secret.metadata.name"},
+					StandaloneExpression: true,
+				},
+				Kind:         variable.ResourceVariableKindDynamic,
+				Dependencies: []string{"secret"},
+			},
+		}),
+		withDependencies([]string{"secret"}),
+	)
+
+	debugPod := newTestResource(
+		withObject(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "debug",
+			},
+		}),
+		withConditions([]string{"schema.spec.debug"}),
+	)
+
+	resources := map[string]Resource{
+		"configmap": configMap,
+		"secret":    secret,
+		"service":   service,
+		"debug":     debugPod,
+	}
+	rt, err := NewResourceGraphDefinitionRuntime(instance, resources,
+		[]string{"configmap", "secret", "service", "debug"}, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	rendered, err := rt.Render()
+	require.NoError(t, err)
+	require.Len(t, rendered, 4)
+
+	assert.Equal(t, "configmap", rendered[0].ID)
+	assert.Equal(t, "myapp", rendered[0].Object.GetName())
+	assert.Empty(t, rendered[0].Unresolved)
+
+	assert.Equal(t, "myapp-secret", rendered[1].Object.GetName())
+	assert.Equal(t, "${configmap.metadata.uid}", rendered[1].Object.Object["stringData"].(map[string]interface{})["owner"])
+	assert.Equal(t, []UnresolvedExpression{
+		{Field: "template.stringData.owner", Expression: "configmap.metadata.uid"},
+	}, rendered[1].Unresolved)
+
+	assert.Equal(t, "${secret.metadata.name}", rendered[2].Object.GetName())
+	assert.Equal(t, []UnresolvedExpression{
+		{Field: "template.metadata.name", Expression: "secret.metadata.name"},
+	}, rendered[2].Unresolved)
+
+	assert.True(t, rendered[3].Excluded)
+	assert.Nil(t, rendered[3].Object)
+}
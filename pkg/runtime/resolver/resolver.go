@@ -15,6 +15,7 @@ package resolver
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/kro-run/kro/pkg/graph/fieldpath"
@@ -65,8 +66,28 @@ func (r *Resolver) Resolve(expressions []variable.FieldDescriptor) ResolutionSum
 		Results:          make([]ResolutionResult, 0, len(expressions)),
 	}
 
-	for _, field := range expressions {
-		result := r.resolveField(field)
+	// The keys are renamed once the values under them are resolved, deepest
+	// first, so that the paths of the other fields stay valid.
+	fields := slices.Clone(expressions)
+	slices.SortStableFunc(fields, func(a, b variable.FieldDescriptor) int {
+		if a.Key != b.Key {
+			if a.Key {
+				return 1
+			}
+			return -1
+		}
+		if !a.Key {
+			return 0
+		}
+		return pathDepth(b.Path) - pathDepth(a.Path)
+	})
+
+	for _, field := range fields {
+		resolve := r.resolveField
+		if field.Key {
+			resolve = r.resolveKey
+		}
+		result := resolve(field)
 		summary.Results = append(summary.Results, result)
 		if result.Resolved {
 			summary.ResolvedExpressions++
@@ -144,6 +165,68 @@ func (r *Resolver) resolveField(field variable.FieldDescriptor) ResolutionResult
 	return result
 }
 
+// resolveKey handles the resolution of the expressions of a map key, renaming
+// the entry of the map.
+func (r *Resolver) resolveKey(field variable.FieldDescriptor) ResolutionResult {
+	result := ResolutionResult{
+		Path:     field.Path,
+		Original: fmt.Sprintf("%v", field.Expressions),
+	}
+
+	segments, err := fieldpath.Parse(field.Path)
+	if err != nil || len(segments) == 0 || segments[len(segments)-1].Index >= 0 {
+		result.Error = fmt.Errorf("invalid key path %s", field.Path)
+		return result
+	}
+	parent, err := r.getValueFromSegments(segments[:len(segments)-1])
+	if err != nil {
+		result.Error = fmt.Errorf("error getting value: %v", err)
+		return result
+	}
+	parentMap, ok := parent.(map[string]interface{})
+	if !ok {
+		result.Error = fmt.Errorf("expected map at path %s", field.Path)
+		return result
+	}
+
+	original := segments[len(segments)-1].Name
+	key := original
+	for _, expr := range field.Expressions {
+		replacement, ok := r.data[expr]
+		if !ok {
+			result.Error = fmt.Errorf("no data provided for expression: %s", expr)
+			return result
+		}
+		if _, ok := replacement.(string); !ok && field.StandaloneExpression {
+			result.Error = fmt.Errorf("expected string key for path %s, got %T", field.Path, replacement)
+			return result
+		}
+		key = strings.Replace(key, "${"+expr+"}", fmt.Sprintf("%v", replacement), -1)
+	}
+	if key == "" {
+		result.Error = fmt.Errorf("key at path %s resolved to an empty string", field.Path)
+		return result
+	}
+	if _, exists := parentMap[key]; exists && key != original {
+		result.Error = fmt.Errorf("key at path %s resolved to %q, which is already set", field.Path, key)
+		return result
+	}
+
+	parentMap[key] = parentMap[original]
+	if key != original {
+		delete(parentMap, original)
+	}
+	result.Resolved = true
+	result.Replaced = key
+	return result
+}
+
+// pathDepth returns the number of segments of the given path.
+func pathDepth(path string) int {
+	segments, _ := fieldpath.Parse(path)
+	return len(segments)
+}
+
 // getValueFromPath retrieves a value from the resource using a dot separated path.
 // NOTE(a-hilaly): this is very similar to the `setValueAtPath` function maybe
 // we can refactor something here.
@@ -154,7 +237,12 @@ func (r *Resolver) getValueFromPath(path string) (interface{}, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid path '%s': %v", path, err)
 	}
+	return r.getValueFromSegments(segments)
+}
 
+// getValueFromSegments retrieves a value from the resource using the segments
+// of a path.
+func (r *Resolver) getValueFromSegments(segments []fieldpath.Segment) (interface{}, error) {
 	current := interface{}(r.resource)
 
 	for _, segment := range segments {
@@ -662,3 +662,69 @@ func TestResolver(t *testing.T) {
 	assert.Equal(t, summary.ResolvedExpressions, 1)
 	assert.Equal(t, "resolved-done", summary.Results[0].Replaced)
 }
+
+func TestResolveKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]interface{}
+		fields  []variable.FieldDescriptor
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "key and value expressions",
+			labels: map[string]interface{}{
+				"${team}":  "${owner}",
+				"static":   "value",
+				"app/${x}": "true",
+			},
+			fields: []variable.FieldDescriptor{
+				{Path: `metadata.labels["${team}"]`, Expressions: []string{"team"}, StandaloneExpression: true, Key: true},
+				{Path: `metadata.labels["${team}"]`, Expressions: []string{"owner"}, StandaloneExpression: true},
+				{Path: `metadata.labels["app/${x}"]`, Expressions: []string{"x"}, Key: true},
+			},
+			want: map[string]interface{}{
+				"platform": "alice",
+				"static":   "value",
+				"app/web":  "true",
+			},
+		},
+		{
+			name:   "duplicate key",
+			labels: map[string]interface{}{"${team}": "a", "platform": "b"},
+			fields: []variable.FieldDescriptor{
+				{Path: `metadata.labels["${team}"]`, Expressions: []string{"team"}, StandaloneExpression: true, Key: true},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "non string key",
+			labels: map[string]interface{}{"${count}": "a"},
+			fields: []variable.FieldDescriptor{
+				{Path: `metadata.labels["${count}"]`, Expressions: []string{"count"}, StandaloneExpression: true, Key: true},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": tt.labels},
+			}
+			r := NewResolver(resource, map[string]interface{}{
+				"team":  "platform",
+				"owner": "alice",
+				"x":     "web",
+				"count": int64(1),
+			})
+			summary := r.Resolve(tt.fields)
+			if tt.wantErr {
+				assert.NotEmpty(t, summary.Errors)
+				return
+			}
+			assert.Empty(t, summary.Errors)
+			assert.Equal(t, tt.want, resource["metadata"].(map[string]interface{})["labels"])
+		})
+	}
+}
@@ -14,13 +14,15 @@
 package runtime
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
-	"github.com/google/cel-go/cel"
 	"golang.org/x/exp/maps"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	krocel "github.com/kro-run/kro/pkg/cel"
 	"github.com/kro-run/kro/pkg/graph/variable"
@@ -45,24 +47,46 @@ func NewResourceGraphDefinitionRuntime(
 	instance Resource,
 	resources map[string]Resource,
 	topologicalOrder []string,
+	conditions []Condition,
+	programs *krocel.ProgramCache,
+	cluster *Cluster,
+	values map[string]interface{},
+	locals map[string]interface{},
 ) (*ResourceGraphDefinitionRuntime, error) {
 	r := &ResourceGraphDefinitionRuntime{
 		instance:                     instance,
 		resources:                    resources,
 		topologicalOrder:             topologicalOrder,
+		conditions:                   conditions,
+		programs:                     programs,
+		cluster:                      cluster,
+		values:                       values,
+		locals:                       locals,
 		resolvedResources:            make(map[string]*unstructured.Unstructured),
 		runtimeVariables:             make(map[string][]*expressionEvaluationState),
 		expressionsCache:             make(map[string]*expressionEvaluationState),
 		ignoredByConditionsResources: make(map[string]bool),
+		ignoredByErrorsResources:     make(map[string]error),
 	}
 	// make sure to copy the variables and the dependencies, to avoid
 	// modifying the original resource.
-	for id, resource := range resources {
+	ids := maps.Keys(resources)
+	slices.Sort(ids)
+	for _, id := range ids {
+		resource := resources[id]
+		// Expressions of expanded resources are evaluated with their item,
+		// their states are only shared within the resource.
+		_, each, expanded := expansionOf(resource)
+
 		// Process the resource variables.
 		for _, variable := range resource.GetVariables() {
 			for _, expr := range variable.Expressions {
+				key := expr
+				if expanded {
+					key = id + "/" + expr
+				}
 				// If cached use the same pointer.
-				if ec, seen := r.expressionsCache[expr]; seen {
+				if ec, seen := r.expressionsCache[key]; seen {
 					// NOTE(a-hilaly): This strikes me as an early optimization, but
 					// it's a good one, i believe... We can always remove it if it's
 					// too magical.
@@ -73,9 +97,12 @@ func NewResourceGraphDefinitionRuntime(
 					Expression:   expr,
 					Dependencies: variable.Dependencies,
 					Kind:         variable.Kind,
+					Each:         each,
+					ResourceID:   id,
+					Field:        "template." + variable.Path,
 				}
 				r.runtimeVariables[id] = append(r.runtimeVariables[id], ees)
-				r.expressionsCache[expr] = ees
+				r.expressionsCache[key] = ees
 			}
 		}
 		// Process the readyWhenExpressions.
@@ -101,6 +128,7 @@ func NewResourceGraphDefinitionRuntime(
 				Expression:   expr,
 				Dependencies: variable.Dependencies,
 				Kind:         variable.Kind,
+				Field:        "schema." + variable.Path,
 			}
 			r.runtimeVariables["instance"] = append(r.runtimeVariables["instance"], ees)
 			r.expressionsCache[expr] = ees
@@ -166,6 +194,46 @@ type ResourceGraphDefinitionRuntime struct {
 	// ignoredByConditionsResources holds the resources whos defined conditions returned false
 	// or who's dependencies are ignored
 	ignoredByConditionsResources map[string]bool
+	// ignoredByErrorsResources holds the resources whose conditions, or the
+	// conditions of their dependencies, failed to evaluate, with the
+	// expression error. Whether they're included isn't known yet.
+	ignoredByErrorsResources map[string]error
+
+	// conditions are the conditions of the instance declared by the resource
+	// graph definition.
+	conditions []Condition
+
+	// renderedResources holds the resources whose variables were all
+	// resolved and set in their object.
+	renderedResources map[string]bool
+
+	// programs caches the programs of the expressions of the resource graph
+	// definition, across the runtimes of its instances.
+	programs *krocel.ProgramCache
+
+	// cluster holds the facts of the cluster exposed to the expressions. It's
+	// nil if a resource shadows the cluster variable.
+	cluster *Cluster
+
+	// values holds the values loaded from the ConfigMaps and Secrets of the
+	// resource graph definition. It's nil without valuesFrom.
+	values map[string]interface{}
+
+	// locals holds the locals of the resource graph definition computed for
+	// the instance. It's nil if the resource graph definition declares none.
+	locals map[string]interface{}
+
+	// evaluationCost is the total cost of the expressions evaluated by the
+	// runtime, bounded by the evaluation budget of the instance.
+	evaluationCost uint64
+	// evaluationDuration is the total time spent evaluating the expressions.
+	evaluationDuration time.Duration
+}
+
+// EvaluationDuration returns the total time spent by the runtime evaluating
+// expressions.
+func (rt *ResourceGraphDefinitionRuntime) EvaluationDuration() time.Duration {
+	return rt.evaluationDuration
 }
 
 // TopologicalOrder returns the topological order of resources.
@@ -256,15 +324,21 @@ func (rt *ResourceGraphDefinitionRuntime) Synchronize() (bool, error) {
 }
 
 // propagateResourceVariables iterates over all resources and evaluates their
-// variables if all dependencies are resolved.
+// variables if all dependencies are resolved. The values of the variables
+// don't change once resolved, each resource is only rendered once: rendering
+// renames the map keys holding expressions.
 func (rt *ResourceGraphDefinitionRuntime) propagateResourceVariables() error {
+	if rt.renderedResources == nil {
+		rt.renderedResources = make(map[string]bool)
+	}
 	for id := range rt.resources {
-		if rt.canProcessResource(id) {
+		if !rt.renderedResources[id] && rt.canProcessResource(id) {
 			// evaluate the resource variables
 			err := rt.evaluateResourceExpressions(id)
 			if err != nil {
 				return fmt.Errorf("failed to evaluate resource variables for %s: %w", id, err)
 			}
+			rt.renderedResources[id] = true
 		}
 	}
 	return nil
@@ -302,19 +376,15 @@ func (rt *ResourceGraphDefinitionRuntime) resourceVariablesResolved(resource str
 // depending only on the initial configuration. This function is usually
 // called once during runtime initialization to set up the baseline state
 func (rt *ResourceGraphDefinitionRuntime) evaluateStaticVariables() error {
-	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{"schema"}))
-	if err != nil {
-		return err
-	}
-
-	evalContext := map[string]interface{}{
-		"schema": rt.instance.Unstructured().Object,
-	}
 	for _, variable := range rt.expressionsCache {
 		if variable.Kind.IsStatic() {
-			value, err := evaluateExpression(env, evalContext, variable.Expression)
+			evalContext := rt.schemaContext()
+			if variable.Each != nil {
+				evalContext["each"] = variable.Each
+			}
+			value, err := rt.evaluate([]string{"schema", "cluster", "values", "locals", "each"}, evalContext, variable.Expression)
 			if err != nil {
-				return err
+				return krocel.NewExpressionError(variable.ResourceID, variable.Field, variable.Expression, err)
 			}
 
 			variable.Resolved = true
@@ -347,10 +417,6 @@ func (rt *ResourceGraphDefinitionRuntime) evaluateDynamicVariables() error {
 
 	resolvedResources := maps.Keys(rt.resolvedResources)
 	resolvedResources = append(resolvedResources, "schema")
-	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs(resolvedResources))
-	if err != nil {
-		return err
-	}
 
 	// let's iterate over any resolved resource and try to resolve
 	// the dynamic variables that depend on it.
@@ -370,15 +436,9 @@ func (rt *ResourceGraphDefinitionRuntime) evaluateDynamicVariables() error {
 				continue
 			}
 
-			evalContext := make(map[string]interface{})
-			for _, dep := range variable.Dependencies {
-				evalContext[dep] = rt.resolvedResources[dep].Object
-			}
-
-			evalContext["schema"] = rt.instance.Unstructured().Object
-
-			value, err := evaluateExpression(env, evalContext, variable.Expression)
+			value, err := rt.evaluateDynamicVariable(variable)
 			if err != nil {
+				err = krocel.NewExpressionError(variable.ResourceID, variable.Field, variable.Expression, err)
 				if strings.Contains(err.Error(), "no such key") {
 					// TODO(a-hilaly): I'm not sure if this is the best way to handle
 					// these. Probably need to reiterate here.
@@ -400,6 +460,21 @@ func (rt *ResourceGraphDefinitionRuntime) evaluateDynamicVariables() error {
 	return nil
 }
 
+// evaluateDynamicVariable evaluates the given dynamic variable against the
+// resolved resources it depends on.
+func (rt *ResourceGraphDefinitionRuntime) evaluateDynamicVariable(variable *expressionEvaluationState) (interface{}, error) {
+	evalContext := rt.schemaContext()
+	for _, dep := range variable.Dependencies {
+		evalContext[dep] = rt.resolvedResources[dep].Object
+	}
+	if variable.Each != nil {
+		evalContext["each"] = variable.Each
+	}
+
+	variables := append([]string{"schema", "cluster", "values", "locals", "each"}, variable.Dependencies...)
+	return rt.evaluate(variables, evalContext, variable.Expression)
+}
+
 // evaluateInstanceStatuses updates the status of the main instance based on
 // the current state of all resources. This function aggregates information
 // from all managed resources to provide an overall status of the runtime,
@@ -428,10 +503,18 @@ func (rt *ResourceGraphDefinitionRuntime) evaluateInstanceStatuses() error {
 func (rt *ResourceGraphDefinitionRuntime) evaluateResourceExpressions(resource string) error {
 	exprValues := make(map[string]interface{})
 	for _, v := range rt.expressionsCache {
-		if v.Resolved {
+		if v.Resolved && v.Each == nil {
 			exprValues[v.Expression] = v.ResolvedValue
 		}
 	}
+	// The expressions of expanded resources are evaluated with their item.
+	if _, _, ok := expansionOf(rt.resources[resource]); ok {
+		for _, v := range rt.runtimeVariables[resource] {
+			if v.Resolved {
+				exprValues[v.Expression] = v.ResolvedValue
+			}
+		}
+	}
 
 	variables := rt.resources[resource].GetVariables()
 	exprFields := make([]variable.FieldDescriptor, len(variables))
@@ -445,6 +528,43 @@ func (rt *ResourceGraphDefinitionRuntime) evaluateResourceExpressions(resource s
 	if summary.Errors != nil {
 		return fmt.Errorf("failed to resolve resource %s: %v", resource, summary.Errors)
 	}
+	if err := validateRenderedMetadata(rt.resources[resource].Unstructured(), exprFields); err != nil {
+		return fmt.Errorf("invalid resource %s: %w", resource, err)
+	}
+	return nil
+}
+
+// validateRenderedMetadata validates the metadata of a resource set by
+// expressions, which isn't validated when the resource graph definition is
+// built: names must be DNS-1123 subdomains, namespaces DNS-1123 labels, and
+// the keys of labels and annotations qualified names.
+func validateRenderedMetadata(obj *unstructured.Unstructured, fields []variable.FieldDescriptor) error {
+	var errs []string
+	invalid := func(what, value string, msgs []string) {
+		for _, msg := range msgs {
+			errs = append(errs, fmt.Sprintf("%s %q: %s", what, value, msg))
+		}
+	}
+	for _, field := range fields {
+		switch {
+		case field.Path == "metadata.name" && !field.Key:
+			invalid("name", obj.GetName(), validation.IsDNS1123Subdomain(obj.GetName()))
+		case field.Path == "metadata.namespace" && !field.Key:
+			invalid("namespace", obj.GetNamespace(), validation.IsDNS1123Label(obj.GetNamespace()))
+		case field.Key && strings.HasPrefix(field.Path, "metadata.labels"):
+			for key := range obj.GetLabels() {
+				invalid("label key", key, validation.IsQualifiedName(key))
+			}
+		case field.Key && strings.HasPrefix(field.Path, "metadata.annotations"):
+			for key := range obj.GetAnnotations() {
+				invalid("annotation key", key, validation.IsQualifiedName(key))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		slices.Sort(errs)
+		return fmt.Errorf("invalid metadata: %s", strings.Join(slices.Compact(errs), ", "))
+	}
 	return nil
 }
 
@@ -475,20 +595,21 @@ func (rt *ResourceGraphDefinitionRuntime) IsResourceReady(resourceID string) (bo
 		return true, "", nil
 	}
 
-	// we should not expect errors here since we already compiled it
-	// in the dryRun
-	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{resourceID}))
-	if err != nil {
-		return false, "", fmt.Errorf("failed creating new Environment: %w", err)
+	// Expressions of expanded resources refer to them by their template id.
+	name := resourceID
+	if templateID, _, ok := expansionOf(rt.resources[resourceID]); ok {
+		name = templateID
 	}
+
 	context := map[string]interface{}{
-		resourceID: observed.Object,
+		name: observed.Object,
 	}
 
-	for _, expression := range expressions {
-		out, err := evaluateExpression(env, context, expression)
+	for i, expression := range expressions {
+		out, err := rt.evaluate([]string{name}, context, expression)
 		if err != nil {
-			return false, "", fmt.Errorf("failed evaluating expressison %s: %w", expression, err)
+			return false, "", fmt.Errorf("failed evaluating expressison %s: %w",
+				expression, krocel.NewExpressionError(resourceID, fmt.Sprintf("readyWhen[%d]", i), expression, err))
 		}
 		// returning a reason here to point out which expression is not ready yet
 		if !out.(bool) {
@@ -499,8 +620,19 @@ func (rt *ResourceGraphDefinitionRuntime) IsResourceReady(resourceID string) (bo
 }
 
 // IgnoreResource ignores resource that has a conditions expressison that evaluated
-// to false or whose dependencies are ignored
-func (rt *ResourceGraphDefinitionRuntime) IgnoreResource(resourceID string) {
+// to false or whose dependencies are ignored. The error is the one returned by
+// WantToCreateResource: the resources skipped because of an expression error
+// are recorded as such, so that their dependents get the error too rather
+// than being excluded.
+func (rt *ResourceGraphDefinitionRuntime) IgnoreResource(resourceID string, err error) {
+	var exprErr *krocel.ExpressionError
+	if errors.As(err, &exprErr) {
+		if rt.ignoredByErrorsResources == nil {
+			rt.ignoredByErrorsResources = make(map[string]error)
+		}
+		rt.ignoredByErrorsResources[resourceID] = err
+		return
+	}
 	rt.ignoredByConditionsResources[resourceID] = true
 }
 
@@ -519,34 +651,59 @@ func (rt *ResourceGraphDefinitionRuntime) areDependenciesIgnored(resourceID stri
 	return false
 }
 
+// dependencyError returns the expression error of the first dependency of the
+// resource skipped because its conditions failed to evaluate, nil if there's
+// none.
+func (rt *ResourceGraphDefinitionRuntime) dependencyError(resourceID string) error {
+	for _, p := range rt.resources[resourceID].GetDependencies() {
+		if err, ok := rt.ignoredByErrorsResources[p]; ok {
+			return err
+		}
+	}
+	return nil
+}
+
 // WantToCreateResource returns true if all the condition expressions return true
 // if not it will add itself to the ignored resources
+//
+// The conditions can refer to the resources the resource depends on, e.g to
+// their status, once they were observed.
+//
+// The resources whose dependencies were excluded by their conditions are
+// excluded too: it returns false and no error. When the conditions of a
+// dependency failed to evaluate instead, it returns the expression error of
+// the dependency, as whether the resource is included isn't known.
 func (rt *ResourceGraphDefinitionRuntime) WantToCreateResource(resourceID string) (bool, error) {
 	if rt.areDependenciesIgnored(resourceID) {
 		return false, nil
 	}
+	if err := rt.dependencyError(resourceID); err != nil {
+		return false, err
+	}
 
 	conditions := rt.resources[resourceID].GetIncludeWhenExpressions()
 	if len(conditions) == 0 {
 		return true, nil
 	}
 
-	// we should not expect errors here since we already compiled it
-	// in the dryRun
-	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{"schema"}))
-	if err != nil {
-		return false, nil
+	names := []string{"schema", "cluster", "values", "locals", "each"}
+	context := rt.schemaContext()
+	if _, each, ok := expansionOf(rt.resources[resourceID]); ok {
+		context["each"] = each
 	}
-
-	context := map[string]interface{}{
-		"schema": rt.instance.Unstructured().Object,
+	for _, dependency := range rt.resources[resourceID].GetDependencies() {
+		if observed, ok := rt.resolvedResources[dependency]; ok {
+			names = append(names, dependency)
+			context[dependency] = observed.Object
+		}
 	}
 
-	for _, condition := range conditions {
-		// We should not expect an error here as well since we checked during dry-run
-		value, err := evaluateExpression(env, context, condition)
+	for i, condition := range conditions {
+		// The conditions referring to resources that weren't observed yet
+		// fail to compile.
+		value, err := rt.evaluate(names, context, condition)
 		if err != nil {
-			return false, err
+			return false, krocel.NewExpressionError(resourceID, fmt.Sprintf("includeWhen[%d]", i), condition, err)
 		}
 		// returning a reason here to point out which expression is not ready yet
 		if !value.(bool) {
@@ -556,25 +713,58 @@ func (rt *ResourceGraphDefinitionRuntime) WantToCreateResource(resourceID string
 	return true, nil
 }
 
-// evaluateExpression evaluates an CEL expression and returns a value if successful, or error
-func evaluateExpression(env *cel.Env, context map[string]interface{}, expression string) (interface{}, error) {
-	ast, issues := env.Compile(expression)
-	if issues != nil && issues.Err() != nil {
-		return nil, fmt.Errorf("failed compiling expression %s: %w", expression, issues.Err())
+// schemaContext returns the context of the expressions holding the variables
+// every expression can refer to: the instance, the cluster, the values and the
+// locals.
+func (rt *ResourceGraphDefinitionRuntime) schemaContext() map[string]interface{} {
+	context := map[string]interface{}{
+		"schema": SchemaVariable(rt.instance.Unstructured()),
+	}
+	if rt.cluster != nil {
+		context["cluster"] = rt.cluster.Variable()
+	}
+	if rt.values != nil {
+		context["values"] = rt.values
+	}
+	if rt.locals != nil {
+		context["locals"] = rt.locals
+	}
+	return context
+}
+
+// evaluate evaluates a CEL expression in an environment declaring the given
+// variables, charging its cost to the evaluation budget of the instance. Once
+// the budget is spent, the evaluations fail until the instance is reconciled
+// again with a new runtime.
+func (rt *ResourceGraphDefinitionRuntime) evaluate(
+	variables []string,
+	context map[string]interface{},
+	expression string,
+) (interface{}, error) {
+	if rt.evaluationCost >= krocel.InstanceCostBudget {
+		return nil, fmt.Errorf("failed evaluating expression %s: evaluation budget of the instance exceeded: %w",
+			expression, krocel.ErrCostLimitExceeded)
 	}
-	// Here as well
-	program, err := env.Program(ast)
+	// we should not expect errors here since we already compiled it
+	// in the dryRun
+	program, err := rt.programs.Program(variables, expression)
 	if err != nil {
-		return nil, fmt.Errorf("failed programming expression %s: %w", expression, err)
+		return nil, fmt.Errorf("failed compiling expression %s: %w", expression, err)
 	}
 	// We get an error here when the value field we're looking for is not yet defined
 	// For now leaving it as error, in the future when we see different scenarios
 	// of this error we can make some a reason, and others an error
-	val, _, err := program.Eval(context)
+	start := time.Now()
+	val, cost, err := krocel.Evaluate(program, context)
+	rt.evaluationDuration += time.Since(start)
+	rt.evaluationCost += cost
 	if err != nil {
 		return nil, fmt.Errorf("failed evaluating expression %s: %w", expression, err)
 	}
-
+	if rt.evaluationCost > krocel.InstanceCostBudget {
+		return nil, fmt.Errorf("failed evaluating expression %s: evaluation budget of the instance exceeded: %w",
+			expression, krocel.ErrCostLimitExceeded)
+	}
 	return krocel.GoNativeType(val)
 }
 
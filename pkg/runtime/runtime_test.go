@@ -15,15 +15,18 @@ package runtime
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/google/cel-go/cel"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	"github.com/kro-run/kro/api/v1alpha1"
 	krocel "github.com/kro-run/kro/pkg/cel"
+	"github.com/kro-run/kro/pkg/graph/fieldpath"
 	"github.com/kro-run/kro/pkg/graph/variable"
 )
 
@@ -212,7 +215,7 @@ func Test_RuntimeWorkflow(t *testing.T) {
 	}
 
 	// 2. Create runtime
-	rt, err := NewResourceGraphDefinitionRuntime(instance, resources, []string{"configmap", "secret", "deployment", "service"})
+	rt, err := NewResourceGraphDefinitionRuntime(instance, resources, []string{"configmap", "secret", "deployment", "service"}, nil, nil, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("NewResourceGraphDefinitionRuntime() error = %v", err)
 	}
@@ -439,7 +442,7 @@ func Test_NewResourceGraphDefinitionRuntime(t *testing.T) {
 		"service":    service,
 	}
 
-	rt, err := NewResourceGraphDefinitionRuntime(instance, resources, []string{"deployment", "service"})
+	rt, err := NewResourceGraphDefinitionRuntime(instance, resources, []string{"deployment", "service"}, nil, nil, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("NewResourceGraphDefinitionRuntime() error = %v", err)
 	}
@@ -2392,6 +2395,41 @@ func Test_WantToCreateResource(t *testing.T) {
 	}
 }
 
+func Test_WantToCreateResource_DependencyErrored(t *testing.T) {
+	rt := &ResourceGraphDefinitionRuntime{
+		ignoredByConditionsResources: map[string]bool{},
+		instance:                     newTestResource(withObject(map[string]interface{}{"spec": map[string]interface{}{}})),
+		resources: map[string]Resource{
+			"vpc":     newTestResource(withConditions([]string{"vpc.status.ready"})),
+			"subnet":  newTestResource(withDependencies([]string{"vpc"})),
+			"skipped": newTestResource(withConditions([]string{"false"})),
+			"other":   newTestResource(withDependencies([]string{"vpc", "skipped"})),
+		},
+	}
+
+	// The condition of the dependency fails to evaluate: whether it's
+	// included isn't known, and its dependents get its expression error.
+	exprErr := krocel.NewExpressionError("vpc", "includeWhen[0]", "vpc.status.ready", errors.New("no such key: ready"))
+	rt.IgnoreResource("vpc", exprErr)
+	want, err := rt.WantToCreateResource("subnet")
+	var gotErr *krocel.ExpressionError
+	if want || !errors.As(err, &gotErr) || gotErr.ResourceID != "vpc" {
+		t.Errorf("WantToCreateResource() = %v, %v, want false and the expression error of vpc", want, err)
+	}
+
+	// The dependents of a resource excluded by its condition are excluded,
+	// whatever their other dependencies.
+	want, err = rt.WantToCreateResource("skipped")
+	if want || err == nil {
+		t.Fatalf("WantToCreateResource() = %v, %v, want false and a skip message", want, err)
+	}
+	rt.IgnoreResource("skipped", err)
+	want, err = rt.WantToCreateResource("other")
+	if want || err != nil {
+		t.Errorf("WantToCreateResource() = %v, %v, want false and no error", want, err)
+	}
+}
+
 func Test_areDependenciesIgnored(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -2465,16 +2503,7 @@ func Test_areDependenciesIgnored(t *testing.T) {
 	}
 }
 
-func setupTestEnv(names []string) (*cel.Env, error) {
-	return krocel.DefaultEnvironment(krocel.WithResourceIDs(names))
-}
-
-func Test_evaluateExpression(t *testing.T) {
-	env, err := setupTestEnv([]string{"data"})
-	if err != nil {
-		t.Fatalf("failed to create environment: %v", err)
-	}
-
+func Test_evaluate(t *testing.T) {
 	tests := []struct {
 		name       string
 		context    map[string]interface{}
@@ -2514,13 +2543,126 @@ func Test_evaluateExpression(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := evaluateExpression(env, tt.context, tt.expression)
+			rt := &ResourceGraphDefinitionRuntime{programs: krocel.NewProgramCache()}
+			got, err := rt.evaluate([]string{"data"}, tt.context, tt.expression)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("evaluateExpression() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("evaluate() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if err == nil && got != tt.want {
-				t.Errorf("evaluateExpression() = %v, want %v", got, tt.want)
+				t.Errorf("evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_evaluateBudget(t *testing.T) {
+	tests := []struct {
+		name           string
+		evaluationCost uint64
+		expression     string
+		want           interface{}
+		wantErr        bool
+	}{
+		{
+			name:       "within budget",
+			expression: "[1, 2, 3].map(x, x * 2).size()",
+			want:       int64(3),
+		},
+		{
+			name:       "expression exceeding its cost limit",
+			expression: "lists.range(1000).map(i, lists.range(1000).map(j, i * j)).size()",
+			wantErr:    true,
+		},
+		{
+			name:           "expression exceeding the remaining budget",
+			evaluationCost: krocel.InstanceCostBudget - 5,
+			expression:     "[1, 2, 3].map(x, x * 2).size()",
+			wantErr:        true,
+		},
+		{
+			name:           "budget spent",
+			evaluationCost: krocel.InstanceCostBudget,
+			expression:     "1 + 1",
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &ResourceGraphDefinitionRuntime{evaluationCost: tt.evaluationCost}
+			got, err := rt.evaluate([]string{"data"}, map[string]interface{}{}, tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("evaluate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				if !errors.Is(err, krocel.ErrCostLimitExceeded) {
+					t.Errorf("evaluate() error = %v, want %v", err, krocel.ErrCostLimitExceeded)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("evaluate() = %v, want %v", got, tt.want)
+			}
+			if rt.evaluationCost <= tt.evaluationCost {
+				t.Errorf("evaluate() didn't charge the cost of the expression")
+			}
+		})
+	}
+}
+
+func Test_validateRenderedMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		fields   []variable.FieldDescriptor
+		wantErr  string
+	}{
+		{
+			name:     "valid name and namespace",
+			metadata: map[string]interface{}{"name": "web-1", "namespace": "team-a"},
+			fields:   []variable.FieldDescriptor{{Path: "metadata.name"}, {Path: "metadata.namespace"}},
+		},
+		{
+			name:     "invalid name",
+			metadata: map[string]interface{}{"name": "Web_1"},
+			fields:   []variable.FieldDescriptor{{Path: "metadata.name"}},
+			wantErr:  `name "Web_1"`,
+		},
+		{
+			name:     "invalid namespace",
+			metadata: map[string]interface{}{"namespace": "team.a"},
+			fields:   []variable.FieldDescriptor{{Path: "metadata.namespace"}},
+			wantErr:  `namespace "team.a"`,
+		},
+		{
+			name: "invalid label key",
+			metadata: map[string]interface{}{
+				"labels": map[string]interface{}{"team a": "x"},
+			},
+			fields:  []variable.FieldDescriptor{{Path: `metadata.labels["${key}"]`, Key: true}},
+			wantErr: `label key "team a"`,
+		},
+		{
+			name:     "name without expressions isn't validated",
+			metadata: map[string]interface{}{"name": "Web_1"},
+			fields:   []variable.FieldDescriptor{{Path: "spec.replicas"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": tt.metadata}}
+			err := validateRenderedMetadata(obj, tt.fields)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateRenderedMetadata() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateRenderedMetadata() error = %v, want %q", err, tt.wantErr)
 			}
 		})
 	}
@@ -2613,6 +2755,7 @@ type mockResource struct {
 	conditions       []string
 	topLevelFields   []string
 	namespaced       bool
+	hooks            map[HookPhase][]string
 	obj              *unstructured.Unstructured
 }
 
@@ -2648,6 +2791,54 @@ func (m *mockResource) IsNamespaced() bool {
 	return m.namespaced
 }
 
+func (m *mockResource) IsExternalRef() bool {
+	return false
+}
+
+func (m *mockResource) GetPatchType() v1alpha1.PatchType {
+	return ""
+}
+
+func (m *mockResource) GetReadinessTimeout() time.Duration {
+	return 0
+}
+
+func (m *mockResource) GetOnFailure() v1alpha1.FailurePolicy {
+	return v1alpha1.FailurePolicyRetry
+}
+
+func (m *mockResource) GetRetryPolicy() *v1alpha1.RetryPolicy {
+	return nil
+}
+
+func (m *mockResource) IsAutoHeal() bool {
+	return true
+}
+
+func (m *mockResource) GetConflictPolicy() v1alpha1.ConflictPolicy {
+	return v1alpha1.ConflictPolicyForce
+}
+
+func (m *mockResource) GetIgnoreFields() [][]fieldpath.Segment {
+	return nil
+}
+
+func (m *mockResource) GetDeletionPolicy() v1alpha1.DeletionPolicy {
+	return v1alpha1.DeletionPolicyDelete
+}
+
+func (m *mockResource) GetAdoptionPolicy() v1alpha1.AdoptionPolicy {
+	return v1alpha1.AdoptionPolicyAdoptOrFail
+}
+
+func (m *mockResource) GetHookAssertions(phase HookPhase) []string {
+	return m.hooks[phase]
+}
+
+func (m *mockResource) GetTarget() *v1alpha1.Target {
+	return nil
+}
+
 func (m *mockResource) Unstructured() *unstructured.Unstructured {
 	return m.obj
 }
@@ -2700,6 +2891,15 @@ func withTopLevelFields(fields []string) mockResourceOption {
 	}
 } */
 
+func withHooks(phase HookPhase, assertions []string) mockResourceOption {
+	return func(m *mockResource) {
+		if m.hooks == nil {
+			m.hooks = make(map[HookPhase][]string)
+		}
+		m.hooks[phase] = assertions
+	}
+}
+
 func withObject(obj map[string]interface{}) mockResourceOption {
 	return func(m *mockResource) {
 		m.obj.Object = obj
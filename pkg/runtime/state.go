@@ -80,4 +80,17 @@ type expressionEvaluationState struct {
 	// if the expression hasn't been resolved yet. The type of this value
 	// depends on the expression and could be any valid Go type.
 	ResolvedValue interface{}
+
+	// Each is the item the expression is evaluated with, available as
+	// `each`, if the expression belongs to a resource expanded from a
+	// template. Since the same expression evaluates to different values for
+	// each item, these states aren't shared between resources.
+	Each map[string]interface{}
+
+	// ResourceID and Field locate the expression in the resource graph
+	// definition, for its errors. An expression shared by several resources
+	// is located in the first one, in lexical order. The resource id is empty
+	// for the expressions of the instance status.
+	ResourceID string
+	Field      string
 }
@@ -0,0 +1,78 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runtime
+
+import (
+	"maps"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Cluster holds the facts of the cluster kro runs in. They're exposed to the
+// expressions as the `cluster` variable, e.g `cluster.region`, so that the
+// templates don't need to repeat them in the spec of every instance.
+type Cluster struct {
+	// Domain is the DNS domain of the cluster, e.g cluster.local.
+	Domain string
+	// KubernetesVersion is the version of the API server, e.g v1.31.2.
+	KubernetesVersion string
+	// Region and Environment are configured with the flags of the
+	// controller. They're empty if they aren't configured.
+	Region      string
+	Environment string
+}
+
+// Variable returns the value of the cluster variable.
+func (c Cluster) Variable() map[string]interface{} {
+	return map[string]interface{}{
+		"domain":            c.Domain,
+		"kubernetesVersion": c.KubernetesVersion,
+		"region":            c.Region,
+		"environment":       c.Environment,
+	}
+}
+
+// SchemaVariable returns the value of the schema variable of the given
+// instance. Its metadata only holds the name, namespace, labels, annotations
+// and uid of the instance, which are always set: the other fields, e.g the
+// resource version, change on every update and would make the resources
+// drift.
+func SchemaVariable(instance *unstructured.Unstructured) map[string]interface{} {
+	labels := instance.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := instance.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	schema := maps.Clone(instance.Object)
+	schema["metadata"] = map[string]interface{}{
+		"name":        instance.GetName(),
+		"namespace":   instance.GetNamespace(),
+		"labels":      stringMap(labels),
+		"annotations": stringMap(annotations),
+		"uid":         string(instance.GetUID()),
+	}
+	return schema
+}
+
+func stringMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
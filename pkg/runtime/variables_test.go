@@ -0,0 +1,88 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package runtime
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_SchemaVariable(t *testing.T) {
+	instance := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"namespace":       "team-a",
+			"uid":             "1234",
+			"resourceVersion": "42",
+			"labels":          map[string]interface{}{"team": "platform"},
+		},
+		"spec": map[string]interface{}{"replicas": int64(3)},
+	}}
+
+	got := SchemaVariable(instance)
+	want := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":        "web",
+			"namespace":   "team-a",
+			"uid":         "1234",
+			"labels":      map[string]interface{}{"team": "platform"},
+			"annotations": map[string]interface{}{},
+		},
+		"spec": map[string]interface{}{"replicas": int64(3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SchemaVariable() = %v, want %v", got, want)
+	}
+	if _, ok := instance.Object["metadata"].(map[string]interface{})["resourceVersion"]; !ok {
+		t.Errorf("SchemaVariable() modified the instance")
+	}
+}
+
+func Test_evaluateContextVariables(t *testing.T) {
+	instance := newTestResource(withObject(map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web", "namespace": "team-a"},
+	}))
+
+	tests := []struct {
+		name    string
+		cluster *Cluster
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "cluster facts",
+			cluster: &Cluster{Domain: "cluster.local", Region: "eu-west-1"},
+			want:    "web.team-a.svc.cluster.local/eu-west-1",
+		},
+		{
+			name:    "cluster variable shadowed",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &ResourceGraphDefinitionRuntime{instance: instance, cluster: tt.cluster}
+			got, err := rt.evaluate([]string{"schema", "cluster"}, rt.schemaContext(),
+				"schema.metadata.name + '.' + schema.metadata.namespace + '.svc.' + cluster.domain + '/' + cluster.region")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
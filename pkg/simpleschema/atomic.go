@@ -16,6 +16,8 @@ package simpleschema
 import (
 	"fmt"
 	"strings"
+
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // AtomicType represents the type of an atomic value that can be used
@@ -34,11 +36,25 @@ const (
 	AtomicTypeFloat AtomicType = "float"
 	// AtomicTypeString represents a string value.
 	AtomicTypeString AtomicType = "string"
+	// AtomicTypeQuantity represents a Kubernetes resource quantity, e.g `500m`
+	// or `1Gi`. Like resource.Quantity, it accepts both integers and strings.
+	AtomicTypeQuantity AtomicType = "quantity"
+	// AtomicTypeIntOrString represents a value that can be either an integer
+	// or a string, e.g a port number or name, or a percentage.
+	AtomicTypeIntOrString AtomicType = "intorstring"
+	// AtomicTypeDuration represents a duration string, e.g `1h30m`.
+	AtomicTypeDuration AtomicType = "duration"
+	// AtomicTypeDateTime represents an RFC 3339 date-time string.
+	AtomicTypeDateTime AtomicType = "datetime"
+	// AtomicTypeAny represents a free-form object, accepting any set of keys.
+	// It is useful to pass through arbitrary configuration blocks.
+	AtomicTypeAny AtomicType = "any"
 )
 
 func isAtomicType(s string) bool {
 	switch AtomicType(s) {
-	case AtomicTypeBool, AtomicTypeInteger, AtomicTypeFloat, AtomicTypeString:
+	case AtomicTypeBool, AtomicTypeInteger, AtomicTypeFloat, AtomicTypeString,
+		AtomicTypeQuantity, AtomicTypeIntOrString, AtomicTypeDuration, AtomicTypeDateTime, AtomicTypeAny:
 		return true
 	default:
 		return false
@@ -78,6 +94,19 @@ func isSliceType(s string) bool {
 	return strings.HasPrefix(s, "[]")
 }
 
+// nullableSuffix is the suffix marking a type as nullable, e.g `string?`.
+const nullableSuffix = "?"
+
+// parseNullableType strips the nullable suffix from the given non collection
+// type, and returns true if it was present. For collections, the suffix binds
+// to the element or value type, e.g `[]string?` is an array of nullable strings.
+func parseNullableType(s string) (string, bool) {
+	if isCollectionType(s) || !strings.HasSuffix(s, nullableSuffix) {
+		return s, false
+	}
+	return strings.TrimSuffix(s, nullableSuffix), true
+}
+
 // parseMapType parses a map type string and returns the key and value types.
 func parseMapType(s string) (string, string, error) {
 	if !strings.HasPrefix(s, "map[") {
@@ -135,3 +164,82 @@ func parseSliceType(s string) (string, error) {
 	}
 	return s, nil
 }
+
+// oneOfPrefix is the prefix of union types, e.g `oneOf(GitSource, S3Source)`.
+const oneOfPrefix = "oneOf("
+
+// isOneOfType returns true if the given type is a union type.
+func isOneOfType(s string) bool {
+	return strings.HasPrefix(s, oneOfPrefix) && strings.HasSuffix(s, ")")
+}
+
+// parseOneOfType parses a union type string and returns its member types.
+func parseOneOfType(s string) ([]string, error) {
+	if !isOneOfType(s) {
+		return nil, fmt.Errorf("invalid oneOf type: %s", s)
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, oneOfPrefix), ")")
+	var memberTypes []string
+	for _, memberType := range strings.Split(s, ",") {
+		memberType = strings.TrimSpace(memberType)
+		if memberType == "" {
+			return nil, fmt.Errorf("empty oneOf member type")
+		}
+		memberTypes = append(memberTypes, memberType)
+	}
+	if len(memberTypes) < 2 {
+		return nil, fmt.Errorf("oneOf requires at least two member types")
+	}
+	return memberTypes, nil
+}
+
+// oneOfMemberKey returns the name of the field holding the given member type
+// of a union, which is the type name with its first letter lowercased.
+func oneOfMemberKey(memberType string) string {
+	return strings.ToLower(memberType[:1]) + memberType[1:]
+}
+
+// crdRefPrefix is the prefix of types imported from the schema of an existing
+// kind, e.g `crd(apps/v1, Deployment, .spec.template)`.
+const crdRefPrefix = "crd("
+
+// isCRDRefType returns true if the given type is imported from an existing kind.
+func isCRDRefType(s string) bool {
+	return strings.HasPrefix(s, crdRefPrefix) && strings.HasSuffix(s, ")")
+}
+
+// parseCRDRefType parses an imported type string and returns the kind it is
+// imported from, and the path of the imported field in the schema of the kind.
+// The path is optional, and defaults to the whole schema.
+func parseCRDRefType(s string) (k8sschema.GroupVersionKind, string, error) {
+	if !isCRDRefType(s) {
+		return k8sschema.GroupVersionKind{}, "", fmt.Errorf("invalid crd type: %s", s)
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, crdRefPrefix), ")")
+	args := strings.Split(s, ",")
+	if len(args) < 2 || len(args) > 3 {
+		return k8sschema.GroupVersionKind{}, "", fmt.Errorf("crd type requires an apiVersion, a kind and an optional path")
+	}
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+	}
+
+	gv, err := k8sschema.ParseGroupVersion(args[0])
+	if err != nil || gv.Version == "" {
+		return k8sschema.GroupVersionKind{}, "", fmt.Errorf("invalid apiVersion in crd type: %s", args[0])
+	}
+	if args[1] == "" {
+		return k8sschema.GroupVersionKind{}, "", fmt.Errorf("empty kind in crd type")
+	}
+
+	var path string
+	if len(args) == 3 {
+		path = args[2]
+		if !strings.HasPrefix(path, ".") {
+			return k8sschema.GroupVersionKind{}, "", fmt.Errorf("path in crd type must start with '.': %s", path)
+		}
+	}
+	return gv.WithKind(args[1]), path, nil
+}
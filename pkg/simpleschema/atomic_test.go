@@ -15,6 +15,8 @@ package simpleschema
 
 import (
 	"testing"
+
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestIsAtomicType(t *testing.T) {
@@ -27,6 +29,11 @@ func TestIsAtomicType(t *testing.T) {
 		{"Integer", "integer", true},
 		{"Float", "float", true},
 		{"String", "string", true},
+		{"Quantity", "quantity", true},
+		{"IntOrString", "intorstring", true},
+		{"Duration", "duration", true},
+		{"DateTime", "datetime", true},
+		{"Any", "any", true},
 		{"Invalid", "invalid", false},
 		{"Empty", "", false},
 		{"", "", false},
@@ -163,3 +170,67 @@ func TestParseSliceType(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNullableType(t *testing.T) {
+	tests := []struct {
+		name         string
+		typeName     string
+		wantType     string
+		wantNullable bool
+	}{
+		{"nullable atomic", "string?", "string", true},
+		{"non nullable atomic", "integer", "integer", false},
+		{"nullable custom type", "Person?", "Person", true},
+		{"slice binds to element", "[]string?", "[]string?", false},
+		{"map binds to value", "map[string]string?", "map[string]string?", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotNullable := parseNullableType(tt.typeName)
+			if gotType != tt.wantType || gotNullable != tt.wantNullable {
+				t.Errorf("parseNullableType() = (%v, %v), want (%v, %v)", gotType, gotNullable, tt.wantType, tt.wantNullable)
+			}
+		})
+	}
+}
+
+func TestParseCRDRefType(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeName string
+		wantGVK  k8sschema.GroupVersionKind
+		wantPath string
+		wantErr  bool
+	}{
+		{
+			name:     "group kind and path",
+			typeName: "crd(apps/v1, Deployment, .spec.template)",
+			wantGVK:  k8sschema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			wantPath: ".spec.template",
+		},
+		{
+			name:     "core group without path",
+			typeName: "crd(v1, PersistentVolumeClaim)",
+			wantGVK:  k8sschema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"},
+		},
+		{"missing kind", "crd(apps/v1)", k8sschema.GroupVersionKind{}, "", true},
+		{"empty kind", "crd(apps/v1, , .spec)", k8sschema.GroupVersionKind{}, "", true},
+		{"invalid apiVersion", "crd(apps/v1/v2, Deployment)", k8sschema.GroupVersionKind{}, "", true},
+		{"relative path", "crd(apps/v1, Deployment, spec)", k8sschema.GroupVersionKind{}, "", true},
+		{"too many arguments", "crd(apps/v1, Deployment, .spec, .status)", k8sschema.GroupVersionKind{}, "", true},
+		{"not a crd type", "Deployment", k8sschema.GroupVersionKind{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotGVK, gotPath, err := parseCRDRefType(tt.typeName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCRDRefType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotGVK != tt.wantGVK || gotPath != tt.wantPath {
+				t.Errorf("parseCRDRefType() = (%v, %v), want (%v, %v)", gotGVK, gotPath, tt.wantGVK, tt.wantPath)
+			}
+		})
+	}
+}
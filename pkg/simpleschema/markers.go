@@ -50,16 +50,86 @@ const (
 	MarkerTypeMinimum MarkerType = "minimum"
 	// MarkerTypeMaximum represents the `maximum` marker.
 	MarkerTypeMaximum MarkerType = "maximum"
+	// MarkerTypeExclusiveMinimum represents the `exclusiveMinimum` marker. It
+	// sets a minimum the value must be strictly greater than.
+	MarkerTypeExclusiveMinimum MarkerType = "exclusiveMinimum"
+	// MarkerTypeExclusiveMaximum represents the `exclusiveMaximum` marker. It
+	// sets a maximum the value must be strictly less than.
+	MarkerTypeExclusiveMaximum MarkerType = "exclusiveMaximum"
+	// MarkerTypeMultipleOf represents the `multipleOf` marker.
+	MarkerTypeMultipleOf MarkerType = "multipleOf"
 	// MarkerTypeValidation represents the `validation` marker.
 	MarkerTypeValidation MarkerType = "validation"
 	// MarkerTypeEnum represents the `enum` marker.
 	MarkerTypeEnum MarkerType = "enum"
+	// MarkerTypePattern represents the `pattern` marker.
+	MarkerTypePattern MarkerType = "pattern"
+	// MarkerTypeImmutable represents the `immutable` marker.
+	MarkerTypeImmutable MarkerType = "immutable"
+	// MarkerTypeValidationMessage represents the `validationMessage` marker. It
+	// sets the message of the rule declared with the `validation` marker.
+	MarkerTypeValidationMessage MarkerType = "validationMessage"
+	// MarkerTypeNullable represents the `nullable` marker.
+	MarkerTypeNullable MarkerType = "nullable"
+	// MarkerTypeMinItems represents the `minItems` marker.
+	MarkerTypeMinItems MarkerType = "minItems"
+	// MarkerTypeMaxItems represents the `maxItems` marker.
+	MarkerTypeMaxItems MarkerType = "maxItems"
+	// MarkerTypeUniqueItems represents the `uniqueItems` marker.
+	MarkerTypeUniqueItems MarkerType = "uniqueItems"
+	// MarkerTypeListType represents the `listType` marker. It sets the
+	// x-kubernetes-list-type of arrays, which drives how server-side apply
+	// merges them.
+	MarkerTypeListType MarkerType = "listType"
+	// MarkerTypeListMapKeys represents the `listMapKeys` marker. It sets the
+	// keys identifying the items of arrays of type map.
+	MarkerTypeListMapKeys MarkerType = "listMapKeys"
+	// MarkerTypeMinLength represents the `minLength` marker.
+	MarkerTypeMinLength MarkerType = "minLength"
+	// MarkerTypeMaxLength represents the `maxLength` marker.
+	MarkerTypeMaxLength MarkerType = "maxLength"
+	// MarkerTypeFormat represents the `format` marker.
+	MarkerTypeFormat MarkerType = "format"
+	// MarkerTypeSensitive represents the `sensitive` marker. Values of
+	// sensitive fields are redacted from the outputs of kro.
+	MarkerTypeSensitive MarkerType = "sensitive"
+	// MarkerTypeDeprecated represents the `deprecated` marker. Setting a
+	// deprecated field triggers a warning.
+	MarkerTypeDeprecated MarkerType = "deprecated"
+	// MarkerTypeDeprecationMessage represents the `deprecationMessage` marker.
+	// It sets the message of the warning declared with the `deprecated` marker.
+	MarkerTypeDeprecationMessage MarkerType = "deprecationMessage"
+	// MarkerTypePrintColumn represents the `printColumn` marker. Fields with
+	// this marker are shown by `kubectl get`.
+	MarkerTypePrintColumn MarkerType = "printColumn"
+	// MarkerTypePrintColumnName represents the `printColumnName` marker. It
+	// sets the name of the column declared with the `printColumn` marker.
+	MarkerTypePrintColumnName MarkerType = "printColumnName"
+	// MarkerTypePrintColumnPriority represents the `printColumnPriority`
+	// marker. It sets the priority of the column declared with the
+	// `printColumn` marker.
+	MarkerTypePrintColumnPriority MarkerType = "printColumnPriority"
+	// MarkerTypeExactlyOneOf represents the `exactlyOneOf` marker. Exactly one
+	// of the fields of an object declaring the same group must be set.
+	MarkerTypeExactlyOneOf MarkerType = "exactlyOneOf"
+	// MarkerTypeAtMostOneOf represents the `atMostOneOf` marker. At most one
+	// of the fields of an object declaring the same group can be set.
+	MarkerTypeAtMostOneOf MarkerType = "atMostOneOf"
+	// MarkerTypeAtLeastOneOf represents the `atLeastOneOf` marker. At least
+	// one of the fields of an object declaring the same group must be set.
+	MarkerTypeAtLeastOneOf MarkerType = "atLeastOneOf"
 )
 
 func markerTypeFromString(s string) (MarkerType, error) {
 	switch MarkerType(s) {
 	case MarkerTypeRequired, MarkerTypeDefault, MarkerTypeDescription,
-		MarkerTypeMinimum, MarkerTypeMaximum, MarkerTypeValidation, MarkerTypeEnum:
+		MarkerTypeMinimum, MarkerTypeMaximum, MarkerTypeExclusiveMinimum, MarkerTypeExclusiveMaximum,
+		MarkerTypeMultipleOf, MarkerTypeValidation, MarkerTypeEnum,
+		MarkerTypePattern, MarkerTypeImmutable, MarkerTypeValidationMessage,
+		MarkerTypeNullable, MarkerTypeMinItems, MarkerTypeMaxItems, MarkerTypeUniqueItems,
+		MarkerTypeListType, MarkerTypeListMapKeys, MarkerTypeMinLength, MarkerTypeMaxLength, MarkerTypeFormat, MarkerTypeSensitive,
+		MarkerTypeDeprecated, MarkerTypeDeprecationMessage, MarkerTypePrintColumn, MarkerTypePrintColumnName, MarkerTypePrintColumnPriority,
+		MarkerTypeExactlyOneOf, MarkerTypeAtMostOneOf, MarkerTypeAtLeastOneOf:
 		return MarkerType(s), nil
 	default:
 		return "", fmt.Errorf("unknown marker type: %s", s)
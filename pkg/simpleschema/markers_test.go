@@ -104,6 +104,15 @@ func TestParseMarkers(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "pattern marker",
+			input: "pattern=^[a-z0-9-]+$ required=true",
+			want: []*Marker{
+				{MarkerType: MarkerTypePattern, Key: "pattern", Value: "^[a-z0-9-]+$"},
+				{MarkerType: MarkerTypeRequired, Key: "required", Value: "true"},
+			},
+			wantErr: false,
+		},
 		{
 			name:  "Markers with spaces in values",
 			input: "description=\"This has spaces\" default=5 required=true",
@@ -0,0 +1,485 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package simpleschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// reverseTransformer converts OpenAPI schemas back to SimpleSchema objects.
+//
+// Objects nested in arrays and maps can't be written inline in SimpleSchema,
+// so they are extracted into custom types, named after the field holding them.
+type reverseTransformer struct {
+	// types holds the extracted custom types, keyed by type name.
+	types map[string]interface{}
+	// typeSchemas holds the schema each custom type was extracted from, used
+	// to reuse a type when the same object schema is found multiple times.
+	typeSchemas map[string]extv1.JSONSchemaProps
+}
+
+// newReverseTransformer creates a new reverse transformer.
+func newReverseTransformer() *reverseTransformer {
+	return &reverseTransformer{
+		types:       make(map[string]interface{}),
+		typeSchemas: make(map[string]extv1.JSONSchemaProps),
+	}
+}
+
+// buildSimpleSchema builds a SimpleSchema object from the given object schema.
+func (rt *reverseTransformer) buildSimpleSchema(schema *extv1.JSONSchemaProps) (map[string]interface{}, error) {
+	if schema.Type != "object" {
+		return nil, fmt.Errorf("expected an object schema, got type: %s", schema.Type)
+	}
+
+	// Properties are converted in order, so that the names of the extracted
+	// types are deterministic.
+	keys := make([]string, 0, len(schema.Properties))
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	groupMarkers := fieldGroupMarkers(schema.XValidations)
+
+	obj := make(map[string]interface{}, len(schema.Properties))
+	for _, key := range keys {
+		property := schema.Properties[key]
+		value, err := rt.transformProperty(key, &property, slices.Contains(schema.Required, key), groupMarkers[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %s: %w", key, err)
+		}
+		obj[key] = value
+	}
+	return obj, nil
+}
+
+// transformProperty converts the schema of a field to either a nested
+// SimpleSchema object, or a field schema string such as `string | required=true`.
+//
+// NOTE: the required marker, field group markers and descriptions can't be
+// expressed on nested objects, so they are dropped.
+func (rt *reverseTransformer) transformProperty(
+	key string, schema *extv1.JSONSchemaProps, required bool, groupMarkers []string,
+) (interface{}, error) {
+	if isNestedObjectSchema(schema) {
+		return rt.buildSimpleSchema(schema)
+	}
+
+	fieldType, err := rt.fieldType(key, schema)
+	if err != nil {
+		return nil, err
+	}
+	markers, err := fieldMarkers(schema, required)
+	if err != nil {
+		return nil, err
+	}
+	markers = append(markers, groupMarkers...)
+	if len(markers) == 0 {
+		return fieldType, nil
+	}
+	return fieldType + " | " + strings.Join(markers, " "), nil
+}
+
+// fieldType returns the SimpleSchema type of the given schema.
+func (rt *reverseTransformer) fieldType(key string, schema *extv1.JSONSchemaProps) (string, error) {
+	if err := checkUnsupportedKeywords(schema); err != nil {
+		return "", err
+	}
+
+	switch {
+	case schema.XIntOrString:
+		switch schema.Pattern {
+		case QuantityPattern:
+			return string(AtomicTypeQuantity), nil
+		case "":
+			return string(AtomicTypeIntOrString), nil
+		default:
+			return "", fmt.Errorf("int-or-string fields with a pattern are only supported as quantities")
+		}
+	case schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields:
+		if schema.Type != "object" || len(schema.Properties) > 0 {
+			return "", fmt.Errorf("x-kubernetes-preserve-unknown-fields is only supported on free-form objects")
+		}
+		return string(AtomicTypeAny), nil
+	}
+
+	switch schema.Type {
+	case "string":
+		switch strings.ReplaceAll(schema.Format, "-", "") {
+		case "duration":
+			return string(AtomicTypeDuration), nil
+		case "datetime":
+			return string(AtomicTypeDateTime), nil
+		}
+		return string(AtomicTypeString), nil
+	case "integer":
+		return string(AtomicTypeInteger), nil
+	case "number":
+		return string(AtomicTypeFloat), nil
+	case "boolean":
+		return string(AtomicTypeBool), nil
+	case "array":
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return "", fmt.Errorf("arrays must have a single items schema")
+		}
+		elementType, err := rt.elementType(singularize(key), schema.Items.Schema)
+		if err != nil {
+			return "", fmt.Errorf("invalid array items: %w", err)
+		}
+		return "[]" + elementType, nil
+	case "object":
+		if schema.AdditionalProperties == nil || schema.AdditionalProperties.Schema == nil {
+			return "", fmt.Errorf("objects must have either properties or an additionalProperties schema")
+		}
+		valueType, err := rt.elementType(key, schema.AdditionalProperties.Schema)
+		if err != nil {
+			return "", fmt.Errorf("invalid map values: %w", err)
+		}
+		return "map[string]" + valueType, nil
+	default:
+		return "", fmt.Errorf("unsupported type: %s", schema.Type)
+	}
+}
+
+// elementType returns the SimpleSchema type of array items and map values.
+// Elements can't hold markers, so they only support the nullable suffix, and
+// the string and numeric constraints propagated from their field (see
+// stringConstraintsTarget and numericConstraintsTarget).
+func (rt *reverseTransformer) elementType(key string, schema *extv1.JSONSchemaProps) (string, error) {
+	if isNestedObjectSchema(schema) {
+		return rt.extractType(key, schema)
+	}
+
+	elementType, err := rt.fieldType(key, schema)
+	if err != nil {
+		return "", err
+	}
+
+	// Everything but what is implied by the element type must be expressed
+	// by the field markers.
+	residual := *schema
+	residual.Description = ""
+	residual.Nullable = false
+	var implied *extv1.JSONSchemaProps
+	switch {
+	case isCollectionType(elementType):
+		residual.Items, residual.AdditionalProperties = nil, nil
+		implied = &extv1.JSONSchemaProps{Type: schema.Type}
+	case schema.Type == "string":
+		residual.Pattern, residual.MinLength, residual.MaxLength = "", nil, nil
+		residual.Format = ""
+		implied = &extv1.JSONSchemaProps{Type: "string"}
+	case schema.Type == "integer", schema.Type == "number":
+		residual.Minimum, residual.Maximum, residual.MultipleOf = nil, nil, nil
+		residual.ExclusiveMinimum, residual.ExclusiveMaximum = false, false
+		implied = &extv1.JSONSchemaProps{Type: schema.Type}
+	default:
+		implied = atomicTypeSchema(elementType)
+		if elementType == string(AtomicTypeFloat) {
+			implied.Type = "number"
+		}
+	}
+	if !reflect.DeepEqual(residual, *implied) {
+		return "", fmt.Errorf("markers are not supported on %s elements", elementType)
+	}
+
+	if schema.Nullable {
+		if isCollectionType(elementType) {
+			return "", fmt.Errorf("nullable collections are only supported on fields")
+		}
+		elementType += nullableSuffix
+	}
+	return elementType, nil
+}
+
+// extractType extracts the given object schema into a custom type, and returns
+// the name of the type. Identical schemas share the same type.
+func (rt *reverseTransformer) extractType(key string, schema *extv1.JSONSchemaProps) (string, error) {
+	for name, existing := range rt.typeSchemas {
+		if reflect.DeepEqual(existing, *schema) {
+			return name, nil
+		}
+	}
+
+	baseName := strings.ToUpper(key[:1]) + key[1:]
+	name := baseName
+	for i := 2; ; i++ {
+		if _, ok := rt.typeSchemas[name]; !ok {
+			break
+		}
+		name = fmt.Sprintf("%s%d", baseName, i)
+	}
+
+	// Reserve the name before converting the type, so that nested types
+	// don't reuse it.
+	rt.typeSchemas[name] = *schema
+	typ, err := rt.buildSimpleSchema(schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert type %s: %w", name, err)
+	}
+	rt.types[name] = typ
+	return name, nil
+}
+
+// fieldMarkers returns the markers of the given field schema.
+func fieldMarkers(schema *extv1.JSONSchemaProps, required bool) ([]string, error) {
+	var markers []string
+	addMarker := func(markerType MarkerType, value string) {
+		markers = append(markers, fmt.Sprintf("%s=%s", markerType, value))
+	}
+
+	if required {
+		addMarker(MarkerTypeRequired, "true")
+	}
+	if schema.Default != nil {
+		value, err := defaultMarkerValue(schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default value: %w", err)
+		}
+		addMarker(MarkerTypeDefault, value)
+	}
+	description := schema.Description
+	if message, ok := ParseDeprecation(description); ok {
+		addMarker(MarkerTypeDeprecated, "true")
+		if message != "" {
+			addMarker(MarkerTypeDeprecationMessage, quoteMarkerValue(message))
+		}
+		_, description, _ = strings.Cut(description, "\n\n")
+	}
+	if description != "" {
+		addMarker(MarkerTypeDescription, quoteMarkerValue(description))
+	}
+	if len(schema.Enum) > 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, e := range schema.Enum {
+			var value interface{}
+			if err := json.Unmarshal(e.Raw, &value); err != nil {
+				return nil, fmt.Errorf("invalid enum value %s: %w", string(e.Raw), err)
+			}
+			s := fmt.Sprint(value)
+			if strings.Contains(s, ",") {
+				return nil, fmt.Errorf("enum values can't contain ','")
+			}
+			values = append(values, s)
+		}
+		addMarker(MarkerTypeEnum, quoteMarkerValue(strings.Join(values, ",")))
+	}
+	if numericSchema := numericConstraintsTarget(schema); numericSchema != nil {
+		if numericSchema.Minimum != nil {
+			markerType := MarkerTypeMinimum
+			if numericSchema.ExclusiveMinimum {
+				markerType = MarkerTypeExclusiveMinimum
+			}
+			addMarker(markerType, strconv.FormatFloat(*numericSchema.Minimum, 'f', -1, 64))
+		}
+		if numericSchema.Maximum != nil {
+			markerType := MarkerTypeMaximum
+			if numericSchema.ExclusiveMaximum {
+				markerType = MarkerTypeExclusiveMaximum
+			}
+			addMarker(markerType, strconv.FormatFloat(*numericSchema.Maximum, 'f', -1, 64))
+		}
+		if numericSchema.MultipleOf != nil {
+			addMarker(MarkerTypeMultipleOf, strconv.FormatFloat(*numericSchema.MultipleOf, 'f', -1, 64))
+		}
+	}
+	if schema.MinItems != nil {
+		addMarker(MarkerTypeMinItems, strconv.FormatInt(*schema.MinItems, 10))
+	}
+	if schema.MaxItems != nil {
+		addMarker(MarkerTypeMaxItems, strconv.FormatInt(*schema.MaxItems, 10))
+	}
+	if schema.XListType != nil {
+		switch *schema.XListType {
+		case "set":
+			addMarker(MarkerTypeUniqueItems, "true")
+		case "map":
+			addMarker(MarkerTypeListType, "map")
+			addMarker(MarkerTypeListMapKeys, quoteMarkerValue(strings.Join(schema.XListMapKeys, ",")))
+		case "atomic":
+			// atomic is the default list type.
+		default:
+			return nil, fmt.Errorf("unsupported list type: %s", *schema.XListType)
+		}
+	}
+	if stringSchema := stringConstraintsTarget(schema); stringSchema != nil {
+		if stringSchema.Pattern != "" {
+			addMarker(MarkerTypePattern, quoteMarkerValue(stringSchema.Pattern))
+		}
+		if stringSchema.MinLength != nil {
+			addMarker(MarkerTypeMinLength, strconv.FormatInt(*stringSchema.MinLength, 10))
+		}
+		if stringSchema.MaxLength != nil {
+			addMarker(MarkerTypeMaxLength, strconv.FormatInt(*stringSchema.MaxLength, 10))
+		}
+		switch format := strings.ReplaceAll(stringSchema.Format, "-", ""); {
+		case format == "", format == "duration", format == "datetime":
+			// implied by the duration and datetime types.
+		case format == SensitiveFormat:
+			addMarker(MarkerTypeSensitive, "true")
+		case isSupportedFormat(format):
+			addMarker(MarkerTypeFormat, stringSchema.Format)
+		default:
+			return nil, fmt.Errorf("unsupported format: %s", stringSchema.Format)
+		}
+	}
+	if schema.Nullable {
+		addMarker(MarkerTypeNullable, "true")
+	}
+
+	validationMessage := ""
+	for _, rule := range schema.XValidations {
+		if rule.Rule == "self == oldSelf" && rule.Message == "field is immutable" {
+			addMarker(MarkerTypeImmutable, "true")
+			continue
+		}
+		if rule.MessageExpression != "" || rule.Reason != nil || rule.FieldPath != "" || rule.OptionalOldSelf != nil {
+			return nil, fmt.Errorf("validation rule %q: only rules with a message are supported", rule.Rule)
+		}
+		if validationMessage != "" && rule.Message != validationMessage {
+			return nil, fmt.Errorf("validation rules with different messages are not supported")
+		}
+		validationMessage = rule.Message
+		addMarker(MarkerTypeValidation, quoteMarkerValue(rule.Rule))
+	}
+	if validationMessage != "" && validationMessage != defaultValidationMessage {
+		addMarker(MarkerTypeValidationMessage, quoteMarkerValue(validationMessage))
+	}
+
+	// The field type and its markers are separated by '|', which can't be
+	// escaped.
+	for _, marker := range markers {
+		if strings.Contains(marker, "|") {
+			return nil, fmt.Errorf("marker %s can't contain '|'", marker)
+		}
+	}
+	return markers, nil
+}
+
+// defaultMarkerValue returns the default marker value of the given schema.
+// Strings are written unquoted, as the transformer quotes them, and other
+// values are written in compact JSON.
+func defaultMarkerValue(schema *extv1.JSONSchemaProps) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(schema.Default.Raw, &value); err != nil {
+		return "", err
+	}
+	if s, ok := value.(string); ok && (schema.Type == "string" || schema.XIntOrString) {
+		if strings.ContainsAny(s, `"\`) {
+			return "", fmt.Errorf("string defaults can't contain quotes or backslashes")
+		}
+		return quoteMarkerValue(s), nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, schema.Default.Raw); err != nil {
+		return "", err
+	}
+	return compact.String(), nil
+}
+
+// quoteMarkerValue quotes the given marker value if needed, escaping quotes
+// and backslashes.
+func quoteMarkerValue(value string) string {
+	if value != "" && !strings.ContainsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`"\=[]{}`, r)
+	}) {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// checkUnsupportedKeywords returns an error if the given schema uses keywords
+// that can't be expressed in SimpleSchema.
+func checkUnsupportedKeywords(schema *extv1.JSONSchemaProps) error {
+	switch {
+	case len(schema.OneOf) > 0, len(schema.AllOf) > 0, schema.Not != nil:
+		return fmt.Errorf("oneOf, allOf and not are not supported")
+	case len(schema.AnyOf) > 0 && !schema.XIntOrString:
+		return fmt.Errorf("anyOf is only supported for int-or-string fields")
+	case schema.ExclusiveMinimum && schema.Minimum == nil, schema.ExclusiveMaximum && schema.Maximum == nil:
+		return fmt.Errorf("exclusiveMinimum and exclusiveMaximum require a minimum and a maximum")
+	case schema.MinProperties != nil, schema.MaxProperties != nil:
+		return fmt.Errorf("minProperties and maxProperties are not supported")
+	case schema.XEmbeddedResource, schema.XMapType != nil:
+		return fmt.Errorf("x-kubernetes-embedded-resource and x-kubernetes-map-type are not supported")
+	case schema.UniqueItems:
+		return fmt.Errorf("uniqueItems is not supported by structural schemas")
+	}
+	return nil
+}
+
+// fieldGroupRuleRegexp matches the validation rules generated for field
+// groups, see fieldGroupValidations.
+var fieldGroupRuleRegexp = regexp.MustCompile(
+	`^\[(has\(self\.[a-zA-Z_][a-zA-Z0-9_]*\)(?:, has\(self\.[a-zA-Z_][a-zA-Z0-9_]*\))+)\]` +
+		`(\.exists_one\(x, x\)|\.filter\(x, x\)\.size\(\) <= 1|\.exists\(x, x\))$`)
+
+// fieldGroupMarkers returns the field group markers of the fields of an
+// object, keyed by field name, recovered from the validation rules of the
+// object. The groups are named after their position in the rules, e.g
+// `group1`.
+func fieldGroupMarkers(rules extv1.ValidationRules) map[string][]string {
+	markers := make(map[string][]string)
+	groups := 0
+	for _, rule := range rules {
+		match := fieldGroupRuleRegexp.FindStringSubmatch(rule.Rule)
+		if match == nil {
+			continue
+		}
+		var markerType MarkerType
+		switch match[2] {
+		case ".exists_one(x, x)":
+			markerType = MarkerTypeExactlyOneOf
+		case ".filter(x, x).size() <= 1":
+			markerType = MarkerTypeAtMostOneOf
+		default:
+			markerType = MarkerTypeAtLeastOneOf
+		}
+		groups++
+		for _, condition := range strings.Split(match[1], ", ") {
+			field := strings.TrimSuffix(strings.TrimPrefix(condition, "has(self."), ")")
+			markers[field] = append(markers[field], fmt.Sprintf("%s=group%d", markerType, groups))
+		}
+	}
+	return markers
+}
+
+// isNestedObjectSchema returns true if the given schema is an object with
+// properties, that is written as a nested SimpleSchema object.
+func isNestedObjectSchema(schema *extv1.JSONSchemaProps) bool {
+	return schema.Type == "object" && len(schema.Properties) > 0 &&
+		(schema.XPreserveUnknownFields == nil || !*schema.XPreserveUnknownFields)
+}
+
+// singularize returns a naive singular form of the given field name, used to
+// name types extracted from arrays, e.g `containers` becomes `container`.
+func singularize(key string) string {
+	if len(key) > 1 && strings.HasSuffix(key, "s") && !strings.HasSuffix(key, "ss") {
+		return strings.TrimSuffix(key, "s")
+	}
+	return key
+}
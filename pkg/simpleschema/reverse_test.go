@@ -0,0 +1,383 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package simpleschema
+
+import (
+	"reflect"
+	"testing"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestFromOpenAPISpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  *extv1.JSONSchemaProps
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "Simple fields",
+			schema: &extv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"name"},
+				Properties: map[string]extv1.JSONSchemaProps{
+					"name": {
+						Type:        "string",
+						Description: "The name of the app",
+					},
+					"replicas": {
+						Type:    "integer",
+						Default: &extv1.JSON{Raw: []byte("3")},
+						Minimum: float64Ptr(1),
+					},
+					"enabled": {Type: "boolean"},
+					"image": {
+						Type:    "string",
+						Default: &extv1.JSON{Raw: []byte(`"nginx"`)},
+						Pattern: `^[a-z]+(:\d+)?$`,
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"name":     `string | required=true description="The name of the app"`,
+				"replicas": "integer | default=3 minimum=1",
+				"enabled":  "boolean",
+				"image":    `string | default=nginx pattern="^[a-z]+(:\\d+)?$"`,
+			},
+		},
+		{
+			name: "Nested objects, arrays and maps",
+			schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"ingress": {
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"hosts": {
+								Type:     "array",
+								MinItems: int64Ptr(1),
+								Items: &extv1.JSONSchemaPropsOrArray{
+									Schema: &extv1.JSONSchemaProps{Type: "string", Format: "hostname"},
+								},
+							},
+						},
+					},
+					"labels": {
+						Type: "object",
+						AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+							Schema: &extv1.JSONSchemaProps{Type: "string", Nullable: true},
+						},
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"ingress": map[string]interface{}{
+					"hosts": "[]string | minItems=1 format=hostname",
+				},
+				"labels": "map[string]string?",
+			},
+		},
+		{
+			name: "Kubernetes types",
+			schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"memory":     *atomicTypeSchema(string(AtomicTypeQuantity)),
+					"targetPort": *atomicTypeSchema(string(AtomicTypeIntOrString)),
+					"timeout":    *atomicTypeSchema(string(AtomicTypeDuration)),
+					"deadline":   *atomicTypeSchema(string(AtomicTypeDateTime)),
+					"values":     *atomicTypeSchema(string(AtomicTypeAny)),
+				},
+			},
+			want: map[string]interface{}{
+				"memory":     "quantity",
+				"targetPort": "intorstring",
+				"timeout":    "duration",
+				"deadline":   "datetime",
+				"values":     "any",
+			},
+		},
+		{
+			name: "Validations",
+			schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"name": {
+						Type: "string",
+						XValidations: extv1.ValidationRules{
+							{Rule: "self == oldSelf", Message: "field is immutable"},
+							{Rule: "self.startsWith('app-')", Message: "must start with app-"},
+						},
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"name": `string | immutable=true validation=self.startsWith('app-') validationMessage="must start with app-"`,
+			},
+		},
+		{
+			name: "Objects in arrays require custom types",
+			schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"ports": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]extv1.JSONSchemaProps{
+									"port": {Type: "integer"},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unsupported keyword",
+			schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"value": {
+						Type:  "object",
+						AllOf: []extv1.JSONSchemaProps{{Type: "object"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Markers on array items",
+			schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"ports": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "integer", Enum: []extv1.JSON{{Raw: []byte("80")}}},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Marker containing a pipe",
+			schema: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"protocol": {Type: "string", Pattern: "^(TCP|UDP)$"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Not an object",
+			schema:  &extv1.JSONSchemaProps{Type: "string"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromOpenAPISpec(tt.schema)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FromOpenAPISpec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromOpenAPISpec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromOpenAPISpecWithTypes(t *testing.T) {
+	container := extv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"image"},
+		Properties: map[string]extv1.JSONSchemaProps{
+			"image": {Type: "string"},
+			"ports": {
+				Type: "array",
+				Items: &extv1.JSONSchemaPropsOrArray{
+					Schema: &extv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"port": {Type: "integer"},
+						},
+					},
+				},
+			},
+		},
+	}
+	schema := &extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"containers": {
+				Type:  "array",
+				Items: &extv1.JSONSchemaPropsOrArray{Schema: &container},
+			},
+			"sidecars": {
+				Type:  "array",
+				Items: &extv1.JSONSchemaPropsOrArray{Schema: &container},
+			},
+			"volumes": {
+				Type: "object",
+				AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+					Schema: &extv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"size": *atomicTypeSchema(string(AtomicTypeQuantity)),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	obj, types, err := FromOpenAPISpecWithTypes(schema)
+	if err != nil {
+		t.Fatalf("FromOpenAPISpecWithTypes() error = %v", err)
+	}
+
+	wantObj := map[string]interface{}{
+		"containers": "[]Container",
+		"sidecars":   "[]Container",
+		"volumes":    "map[string]Volumes",
+	}
+	if !reflect.DeepEqual(obj, wantObj) {
+		t.Errorf("FromOpenAPISpecWithTypes() obj = %v, want %v", obj, wantObj)
+	}
+	wantTypes := map[string]interface{}{
+		"Container": map[string]interface{}{
+			"image": "string | required=true",
+			"ports": "[]Port",
+		},
+		"Port": map[string]interface{}{
+			"port": "integer",
+		},
+		"Volumes": map[string]interface{}{
+			"size": "quantity",
+		},
+	}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Errorf("FromOpenAPISpecWithTypes() types = %v, want %v", types, wantTypes)
+	}
+
+	// Converting the result back must give the original schema.
+	roundTrip, err := ToOpenAPISpecWithTypes(obj, types)
+	if err != nil {
+		t.Fatalf("ToOpenAPISpecWithTypes() error = %v", err)
+	}
+	if !reflect.DeepEqual(roundTrip, schema) {
+		t.Errorf("ToOpenAPISpecWithTypes() = %v, want %v", roundTrip, schema)
+	}
+}
+
+func TestFromOpenAPISpecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+	}{
+		{
+			name: "Markers",
+			obj: map[string]interface{}{
+				"name":     `string | required=true description="The \"name\" of the app" minLength=1 maxLength=63`,
+				"replicas": "integer | default=1 minimum=0 maximum=10",
+				"percent":  "integer | exclusiveMinimum=0 exclusiveMaximum=100 multipleOf=5",
+				"ports":    "[]integer | exclusiveMinimum=0 maximum=65535",
+				"mode":     "string | enum=fast,slow default=fast",
+				"tags":     "[]string | default=[\"a\",\"b\"] uniqueItems=true maxItems=5",
+				"env":      "map[string]string | default={\"FOO\":\"bar\"}",
+				"owner":    "string? | format=email",
+				"id":       `string | immutable=true validation="self.size() > 2"`,
+				"password": "string | sensitive=true",
+				"size":     `string | deprecated=true deprecationMessage="use replicas instead" description="Size of the app"`,
+				"legacy":   "boolean | deprecated=true",
+			},
+		},
+		{
+			name: "Field groups",
+			obj: map[string]interface{}{
+				"cpu":         "integer | atMostOneOf=size atLeastOneOf=limits",
+				"preset":      "string | atMostOneOf=size",
+				"memory":      "integer | atLeastOneOf=limits",
+				"existingVPC": "string | exactlyOneOf=vpc",
+				"vpcCIDR":     "string | exactlyOneOf=vpc",
+			},
+		},
+		{
+			name: "Nested collections",
+			obj: map[string]interface{}{
+				"matrix": "[][]integer",
+				"groups": "map[string][]string | pattern=^[a-z]+$",
+				"nested": map[string]interface{}{
+					"deadline": "datetime | required=true",
+					"timeouts": "[]duration",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := ToOpenAPISpec(tt.obj)
+			if err != nil {
+				t.Fatalf("ToOpenAPISpec() error = %v", err)
+			}
+			obj, err := FromOpenAPISpec(want)
+			if err != nil {
+				t.Fatalf("FromOpenAPISpec() error = %v", err)
+			}
+			got, err := ToOpenAPISpec(obj)
+			if err != nil {
+				t.Fatalf("ToOpenAPISpec() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFromOpenAPISpecWithTypesListMap(t *testing.T) {
+	want, err := ToOpenAPISpecWithTypes(map[string]interface{}{
+		"ports": "[]Port | listType=map listMapKeys=name,protocol",
+	}, map[string]interface{}{
+		"Port": map[string]interface{}{
+			"name":     "string | required=true",
+			"protocol": "string | default=TCP",
+			"port":     "integer",
+		},
+	})
+	if err != nil {
+		t.Fatalf("ToOpenAPISpecWithTypes() error = %v", err)
+	}
+
+	obj, types, err := FromOpenAPISpecWithTypes(want)
+	if err != nil {
+		t.Fatalf("FromOpenAPISpecWithTypes() error = %v", err)
+	}
+	got, err := ToOpenAPISpecWithTypes(obj, types)
+	if err != nil {
+		t.Fatalf("ToOpenAPISpecWithTypes() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %v, want %v", got, want)
+	}
+}
@@ -15,8 +15,10 @@ package simpleschema
 
 import (
 	"fmt"
+	"strings"
 
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // ToOpenAPISpec converts a SimpleSchema object to an OpenAPI schema.
@@ -24,11 +26,178 @@ import (
 // The input object is a map[string]interface{} where the key is the field name
 // and the value is the field type.
 func ToOpenAPISpec(obj map[string]interface{}) (*extv1.JSONSchemaProps, error) {
+	return ToOpenAPISpecWithTypes(obj, nil)
+}
+
+// ToOpenAPISpecWithTypes converts a SimpleSchema object to an OpenAPI schema,
+// resolving the custom types referenced in the object from the given types.
+//
+// The types object is a map[string]interface{} where the key is the type name
+// and the value is the type definition, itself a SimpleSchema object.
+func ToOpenAPISpecWithTypes(obj, types map[string]interface{}) (*extv1.JSONSchemaProps, error) {
+	return ToOpenAPISpecWithOptions(obj, Options{Types: types})
+}
+
+// SensitiveFormat is the format of the string fields declared with the
+// `sensitive` marker.
+const SensitiveFormat = "password"
+
+// deprecatedPrefix starts the description of the fields declared with the
+// `deprecated` marker. OpenAPI v3.0 schemas have no deprecated keyword, so the
+// deprecation is recorded in the description, following the Go and Kubernetes
+// API conventions.
+const deprecatedPrefix = "Deprecated"
+
+// ParseDeprecation returns whether the field with the given description is
+// deprecated, and the message of its deprecation notice, if any.
+func ParseDeprecation(description string) (string, bool) {
+	notice, _, _ := strings.Cut(description, "\n\n")
+	switch {
+	case notice == deprecatedPrefix+".":
+		return "", true
+	case strings.HasPrefix(notice, deprecatedPrefix+": "):
+		return strings.TrimPrefix(notice, deprecatedPrefix+": "), true
+	default:
+		return "", false
+	}
+}
+
+// deprecatedDescription returns the given description, started with a
+// deprecation notice carrying the given message.
+func deprecatedDescription(description, message string) string {
+	notice := deprecatedPrefix + "."
+	if message != "" {
+		notice = deprecatedPrefix + ": " + message
+	}
+	if description == "" {
+		return notice
+	}
+	return notice + "\n\n" + description
+}
+
+// DefaultMaxRecursionDepth is the default number of times a recursive custom
+// type can be nested in itself.
+const DefaultMaxRecursionDepth = 5
+
+// SchemaResolver returns the OpenAPI schema of the given kind. It is used to
+// resolve the types imported from existing kinds, e.g
+// `crd(apps/v1, Deployment, .spec.template)`.
+type SchemaResolver func(gvk k8sschema.GroupVersionKind) (*extv1.JSONSchemaProps, error)
+
+// Options configures the conversion of SimpleSchema objects.
+type Options struct {
+	// Types are the custom types referenced in the object, where the key is
+	// the type name and the value is the type definition.
+	Types map[string]interface{}
+	// MaxRecursionDepth is the number of times a recursive custom type, e.g
+	// a `TreeNode` with `children: "[]TreeNode"`, can be nested in itself.
+	// Deeper values are rejected. Defaults to DefaultMaxRecursionDepth.
+	MaxRecursionDepth int
+	// SchemaResolver resolves the types imported from existing kinds. Without
+	// a resolver, imported types are rejected.
+	SchemaResolver SchemaResolver
+}
+
+// ToOpenAPISpecWithOptions converts a SimpleSchema object to an OpenAPI schema,
+// using the given options.
+func ToOpenAPISpecWithOptions(obj map[string]interface{}, opts Options) (*extv1.JSONSchemaProps, error) {
+	result, err := Convert(obj, opts)
+	if err != nil {
+		return nil, err
+	}
+	return result.Schema, nil
+}
+
+// Result is the result of the conversion of a SimpleSchema object.
+type Result struct {
+	// Schema is the OpenAPI schema of the object.
+	Schema *extv1.JSONSchemaProps
+	// PrinterColumns are the columns declared with the `printColumn` marker.
+	// Their JSON paths are relative to the object, e.g `.replicas`.
+	PrinterColumns []extv1.CustomResourceColumnDefinition
+	// ExpressionDefaults are the defaults declared with an expression, e.g
+	// `default=${schema.metadata.name}`, in the order of their fields.
+	ExpressionDefaults []ExpressionDefault
+}
+
+// ExpressionDefault is the default of a field computed by an expression. It
+// isn't part of the schema, since it's only known once the object exists.
+type ExpressionDefault struct {
+	// Path is the path of the field, relative to the object, e.g
+	// `["storage", "size"]`.
+	Path []string
+	// Expression is the expression computing the default, e.g
+	// `${schema.metadata.name}`.
+	Expression string
+}
+
+// Convert converts a SimpleSchema object to an OpenAPI schema, and collects
+// the information of the object that doesn't belong to the schema, like its
+// printer columns.
+func Convert(obj map[string]interface{}, opts Options) (*Result, error) {
 	tf := newTransformer()
-	return tf.buildOpenAPISchema(obj)
+	if opts.MaxRecursionDepth < 0 {
+		return nil, fmt.Errorf("max recursion depth cannot be negative")
+	}
+	if opts.MaxRecursionDepth > 0 {
+		tf.maxRecursionDepth = opts.MaxRecursionDepth
+	}
+	tf.schemaResolver = opts.SchemaResolver
+	if err := tf.loadPreDefinedTypes(opts.Types); err != nil {
+		return nil, err
+	}
+
+	schema, err := tf.buildOpenAPISchema(obj)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(tf.printerColumns))
+	for _, column := range tf.printerColumns {
+		if _, ok := seen[column.Name]; ok {
+			return nil, fmt.Errorf("duplicate printer column name: %s", column.Name)
+		}
+		seen[column.Name] = struct{}{}
+	}
+	return &Result{
+		Schema:             schema,
+		PrinterColumns:     tf.printerColumns,
+		ExpressionDefaults: tf.expressionDefaults,
+	}, nil
 }
 
 // FromOpenAPISpec converts an OpenAPI schema to a SimpleSchema object.
+//
+// The schema must be an object schema, such as the spec of a CRD. It returns an
+// error if the schema contains objects nested in arrays or maps, which can only
+// be expressed using custom types, see FromOpenAPISpecWithTypes.
 func FromOpenAPISpec(schema *extv1.JSONSchemaProps) (map[string]interface{}, error) {
-	return nil, fmt.Errorf("not implemented")
+	obj, types, err := FromOpenAPISpecWithTypes(schema)
+	if err != nil {
+		return nil, err
+	}
+	if len(types) > 0 {
+		return nil, fmt.Errorf("schema requires custom types, use FromOpenAPISpecWithTypes instead")
+	}
+	return obj, nil
+}
+
+// FromOpenAPISpecWithTypes converts an OpenAPI schema to a SimpleSchema object
+// and the custom types it references, in the format expected by
+// ToOpenAPISpecWithTypes.
+//
+// Objects nested in arrays and maps are extracted into custom types, named
+// after the field holding them, e.g the items of `containers` become the
+// `Container` type. Keywords that can't be expressed in SimpleSchema cause an
+// error, except documentation and the required marker of nested objects, which
+// are dropped.
+func FromOpenAPISpecWithTypes(schema *extv1.JSONSchemaProps) (map[string]interface{}, map[string]interface{}, error) {
+	if schema == nil {
+		return nil, nil, fmt.Errorf("schema is nil")
+	}
+	rt := newReverseTransformer()
+	obj, err := rt.buildSimpleSchema(schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, rt.types, nil
 }
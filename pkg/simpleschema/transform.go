@@ -14,44 +14,143 @@
 package simpleschema
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
 )
 
+// defaultValidationMessage is the message used for validation rules declared
+// without a validationMessage marker.
+const defaultValidationMessage = "validation failed"
+
 // transformer is a transformer for OpenAPI schemas
 type transformer struct {
+	// preDefinedTypes holds the schemas of the pre-defined types.
 	preDefinedTypes map[string]extv1.JSONSchemaProps
+	// typeDefinitions holds the SimpleSchema definitions of the pre-defined
+	// types. Types are expanded from their definition every time they are
+	// referenced, which allows recursive types.
+	typeDefinitions map[string]interface{}
+	// expanding counts the expansions in progress of each type, to bound the
+	// expansion of recursive types.
+	expanding map[string]int
+	// maxRecursionDepth is the number of times a type can be nested in itself.
+	maxRecursionDepth int
+	// schemaResolver resolves the schemas of the types imported from existing
+	// kinds.
+	schemaResolver SchemaResolver
+
+	// path is the path of the field being transformed.
+	path []string
+	// collectionDepth counts the arrays and maps the field being transformed
+	// is nested in.
+	collectionDepth int
+	// loadingTypes is true while the pre-defined types are loaded, outside of
+	// the context of any field.
+	loadingTypes bool
+	// printerColumns are the columns declared with the printColumn marker.
+	printerColumns []extv1.CustomResourceColumnDefinition
+	// expressionDefaults are the defaults declared with an expression.
+	expressionDefaults []ExpressionDefault
+	// fieldGroups are the field groups declared in the object being built,
+	// holding the fields of each group.
+	fieldGroups map[fieldGroup][]string
+}
+
+// fieldGroup identifies a group of fields of an object, declared with the
+// exactlyOneOf, atMostOneOf or atLeastOneOf markers.
+type fieldGroup struct {
+	markerType MarkerType
+	name       string
 }
 
 // newTransformer creates a new transformer
 func newTransformer() *transformer {
 	return &transformer{
-		preDefinedTypes: make(map[string]extv1.JSONSchemaProps),
+		preDefinedTypes:   make(map[string]extv1.JSONSchemaProps),
+		typeDefinitions:   make(map[string]interface{}),
+		expanding:         make(map[string]int),
+		maxRecursionDepth: DefaultMaxRecursionDepth,
 	}
 }
 
 // loadPreDefinedTypes loads pre-defined types into the transformer.
 // The pre-defined types are used to resolve references in the schema.
 //
-// As of today, kro doesn't support custom types in the schema - do
-// not use this function.
+// Types can reference each other, and themselves, in which case they are
+// expanded up to the maximum recursion depth. Types that can't be resolved
+// cause an error.
 func (t *transformer) loadPreDefinedTypes(obj map[string]interface{}) error {
 	t.preDefinedTypes = make(map[string]extv1.JSONSchemaProps)
+	t.typeDefinitions = make(map[string]interface{}, len(obj))
 
-	jsonSchemaProps, err := t.buildOpenAPISchema(obj)
-	if err != nil {
-		return fmt.Errorf("failed to build pre-defined types schema: %w", err)
+	names := make([]string, 0, len(obj))
+	for name, definition := range obj {
+		if isAtomicType(name) || isCollectionType(name) || isOneOfType(name) || isCRDRefType(name) {
+			t.typeDefinitions = make(map[string]interface{})
+			return fmt.Errorf("invalid type name %s: conflicts with a built-in type", name)
+		}
+		t.typeDefinitions[name] = definition
+		names = append(names, name)
 	}
+	// Sort the types so that errors are deterministic.
+	sort.Strings(names)
+
+	t.loadingTypes = true
+	defer func() { t.loadingTypes = false }()
 
-	for k, properties := range jsonSchemaProps.Properties {
-		t.preDefinedTypes[k] = properties
+	for _, name := range names {
+		schema, err := t.resolveType(name)
+		if err != nil {
+			t.preDefinedTypes = make(map[string]extv1.JSONSchemaProps)
+			t.typeDefinitions = make(map[string]interface{})
+			return fmt.Errorf("failed to build pre-defined type %s: %w", name, err)
+		}
+		t.preDefinedTypes[name] = *schema
 	}
 	return nil
 }
 
+// isPreDefinedType returns true if the given type is a pre-defined type.
+func (t *transformer) isPreDefinedType(name string) bool {
+	_, ok := t.typeDefinitions[name]
+	return ok
+}
+
+// resolveType builds the schema of the given pre-defined type. Once a type is
+// nested in itself more than the maximum recursion depth, it is replaced by a
+// schema rejecting any value, so that instances nesting it deeper get a clear
+// validation error.
+func (t *transformer) resolveType(name string) (*extv1.JSONSchemaProps, error) {
+	definition, ok := t.typeDefinitions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type: %s", name)
+	}
+	if t.expanding[name] > t.maxRecursionDepth {
+		preserveUnknownFields := true
+		return &extv1.JSONSchemaProps{
+			Type:                   "object",
+			XPreserveUnknownFields: &preserveUnknownFields,
+			XValidations: extv1.ValidationRules{{
+				Rule:    "false",
+				Message: fmt.Sprintf("type %s exceeds the maximum nesting depth of %d", name, t.maxRecursionDepth),
+			}},
+		}, nil
+	}
+
+	t.expanding[name]++
+	defer func() { t.expanding[name]-- }()
+	return t.transformField(name, definition, nil)
+}
+
 // buildOpenAPISchema builds an OpenAPI schema from the given object
 // of a SimpleSchema.
 func (tf *transformer) buildOpenAPISchema(obj map[string]interface{}) (*extv1.JSONSchemaProps, error) {
@@ -60,14 +159,34 @@ func (tf *transformer) buildOpenAPISchema(obj map[string]interface{}) (*extv1.JS
 		Properties: map[string]extv1.JSONSchemaProps{},
 	}
 
-	for key, value := range obj {
-		fieldSchema, err := tf.transformField(key, value, schema)
+	// Sort the keys so that the printer columns are deterministic.
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Nested objects declare their own field groups.
+	fieldGroups := tf.fieldGroups
+	tf.fieldGroups = make(map[fieldGroup][]string)
+	defer func() { tf.fieldGroups = fieldGroups }()
+
+	for _, key := range keys {
+		tf.path = append(tf.path, key)
+		fieldSchema, err := tf.transformField(key, obj[key], schema)
+		tf.path = tf.path[:len(tf.path)-1]
 		if err != nil {
 			return nil, err
 		}
 		schema.Properties[key] = *fieldSchema
 	}
 
+	rules, err := fieldGroupValidations(tf.fieldGroups)
+	if err != nil {
+		return nil, err
+	}
+	schema.XValidations = append(schema.XValidations, rules...)
+
 	return schema, nil
 }
 func (tf *transformer) transformField(
@@ -78,8 +197,14 @@ func (tf *transformer) transformField(
 	switch v := value.(type) {
 	case map[interface{}]interface{}:
 		nMap := transformMap(v)
+		if isFieldDeclaration(nMap) {
+			return tf.transformFieldDeclaration(key, nMap, parentSchema)
+		}
 		return tf.buildOpenAPISchema(nMap)
 	case map[string]interface{}:
+		if isFieldDeclaration(v) {
+			return tf.transformFieldDeclaration(key, v, parentSchema)
+		}
 		return tf.buildOpenAPISchema(v)
 	case string:
 		return tf.parseFieldSchema(key, v, parentSchema)
@@ -88,16 +213,101 @@ func (tf *transformer) transformField(
 	}
 }
 
+// isFieldDeclaration returns true if the given object declares a single field
+// with a structured default, e.g
+//
+//	ports:
+//	  type: "[]integer | minItems=1"
+//	  default: [80, 443]
+//
+// rather than an object with fields.
+func isFieldDeclaration(obj map[string]interface{}) bool {
+	if len(obj) != 2 {
+		return false
+	}
+	_, hasType := obj["type"]
+	_, hasDefault := obj["default"]
+	return hasType && hasDefault
+}
+
+// transformFieldDeclaration transforms a field declared with its type and a
+// structured default, which can be written in YAML rather than in a default
+// marker.
+func (tf *transformer) transformFieldDeclaration(
+	key string, declaration map[string]interface{}, parentSchema *extv1.JSONSchemaProps,
+) (*extv1.JSONSchemaProps, error) {
+	defaultMarker, err := structuredDefaultMarker(declaration["default"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid default value for %s: %w", key, err)
+	}
+	switch fieldValue := declaration["type"].(type) {
+	case string:
+		fieldType, markers, err := parseFieldSchema(fieldValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse field schema for %s: %v", key, err)
+		}
+		for _, marker := range markers {
+			if marker.MarkerType == MarkerTypeDefault {
+				return nil, fmt.Errorf("field %s declares both a default marker and a default value", key)
+			}
+		}
+		return tf.fieldSchema(key, fieldType, append(markers, defaultMarker), parentSchema)
+	case map[string]interface{}, map[interface{}]interface{}:
+		fieldSchema, err := tf.transformField(key, fieldValue, parentSchema)
+		if err != nil {
+			return nil, err
+		}
+		if err := tf.applyMarkers(fieldSchema, []*Marker{defaultMarker}, key, parentSchema); err != nil {
+			return nil, fmt.Errorf("failed to apply markers: %w", err)
+		}
+		return fieldSchema, nil
+	default:
+		return nil, fmt.Errorf("unknown type in schema: key: %s, value: %v", key, fieldValue)
+	}
+}
+
+// structuredDefaultMarker returns the default marker of the given structured
+// default value. Strings are kept as is, as in `default=` markers, and other
+// values are converted to JSON.
+func structuredDefaultMarker(value interface{}) (*Marker, error) {
+	if s, ok := value.(string); ok {
+		return &Marker{MarkerType: MarkerTypeDefault, Key: string(MarkerTypeDefault), Value: s}, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return &Marker{MarkerType: MarkerTypeDefault, Key: string(MarkerTypeDefault), Value: string(raw)}, nil
+}
+
 func (tf *transformer) parseFieldSchema(key, fieldValue string, parentSchema *extv1.JSONSchemaProps) (*extv1.JSONSchemaProps, error) {
 	fieldType, markers, err := parseFieldSchema(fieldValue)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse field schema for %s: %v", key, err)
 	}
+	return tf.fieldSchema(key, fieldType, markers, parentSchema)
+}
 
+// fieldSchema returns the schema of a field of the given type and markers.
+func (tf *transformer) fieldSchema(
+	key, fieldType string, markers []*Marker, parentSchema *extv1.JSONSchemaProps,
+) (*extv1.JSONSchemaProps, error) {
+	var err error
+	fieldType, nullable := parseNullableType(fieldType)
 	fieldJSONSchemaProps := &extv1.JSONSchemaProps{}
 
 	if isAtomicType(fieldType) {
-		fieldJSONSchemaProps.Type = string(fieldType)
+		fieldJSONSchemaProps = atomicTypeSchema(fieldType)
+	} else if isOneOfType(fieldType) {
+		fieldJSONSchemaProps, err = tf.handleOneOfType(key, fieldType)
+		if err != nil {
+			return nil, err
+		}
+	} else if isCRDRefType(fieldType) {
+		fieldJSONSchemaProps, err = tf.handleCRDRefType(key, fieldType)
+		if err != nil {
+			return nil, err
+		}
 	} else if isCollectionType(fieldType) {
 		if isMapType(fieldType) {
 			fieldJSONSchemaProps, err = tf.handleMapType(key, fieldType)
@@ -110,11 +320,13 @@ func (tf *transformer) parseFieldSchema(key, fieldValue string, parentSchema *ex
 			return nil, err
 		}
 	} else {
-		preDefinedType, ok := tf.preDefinedTypes[fieldType]
-		if !ok {
-			return nil, fmt.Errorf("unknown type: %s", fieldType)
+		fieldJSONSchemaProps, err = tf.resolveType(fieldType)
+		if err != nil {
+			return nil, err
 		}
-		fieldJSONSchemaProps = &preDefinedType
+	}
+	if nullable {
+		fieldJSONSchemaProps.Nullable = true
 	}
 
 	if err := tf.applyMarkers(fieldJSONSchemaProps, markers, key, parentSchema); err != nil {
@@ -124,7 +336,46 @@ func (tf *transformer) parseFieldSchema(key, fieldValue string, parentSchema *ex
 	return fieldJSONSchemaProps, nil
 }
 
+// QuantityPattern is the pattern used by Kubernetes to validate
+// resource.Quantity values. It identifies the fields of the quantity type.
+const QuantityPattern = `^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$`
+
+// atomicTypeSchema returns the OpenAPI schema of the given atomic type.
+func atomicTypeSchema(atomicType string) *extv1.JSONSchemaProps {
+	switch AtomicType(atomicType) {
+	case AtomicTypeQuantity:
+		return &extv1.JSONSchemaProps{
+			AnyOf: []extv1.JSONSchemaProps{
+				{Type: "integer"},
+				{Type: "string"},
+			},
+			Pattern:      QuantityPattern,
+			XIntOrString: true,
+		}
+	case AtomicTypeIntOrString:
+		return &extv1.JSONSchemaProps{
+			AnyOf: []extv1.JSONSchemaProps{
+				{Type: "integer"},
+				{Type: "string"},
+			},
+			XIntOrString: true,
+		}
+	case AtomicTypeDuration:
+		return &extv1.JSONSchemaProps{Type: "string", Format: "duration"}
+	case AtomicTypeDateTime:
+		return &extv1.JSONSchemaProps{Type: "string", Format: "date-time"}
+	case AtomicTypeAny:
+		preserveUnknownFields := true
+		return &extv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserveUnknownFields}
+	default:
+		return &extv1.JSONSchemaProps{Type: atomicType}
+	}
+}
+
 func (tf *transformer) handleMapType(key, fieldType string) (*extv1.JSONSchemaProps, error) {
+	tf.collectionDepth++
+	defer func() { tf.collectionDepth-- }()
+
 	keyType, valueType, err := parseMapType(fieldType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse map type for %s: %w", key, err)
@@ -140,24 +391,101 @@ func (tf *transformer) handleMapType(key, fieldType string) (*extv1.JSONSchemaPr
 		},
 	}
 
-	if isCollectionType(valueType) {
+	valueType, nullable := parseNullableType(valueType)
+	if isCollectionType(valueType) || isOneOfType(valueType) || isCRDRefType(valueType) {
 		valueSchema, err := tf.parseFieldSchema(key, valueType, fieldJSONSchemaProps)
 		if err != nil {
 			return nil, err
 		}
 		fieldJSONSchemaProps.AdditionalProperties.Schema = valueSchema
-	} else if preDefinedType, ok := tf.preDefinedTypes[valueType]; ok {
-		fieldJSONSchemaProps.AdditionalProperties.Schema = &preDefinedType
+	} else if tf.isPreDefinedType(valueType) {
+		valueSchema, err := tf.resolveType(valueType)
+		if err != nil {
+			return nil, err
+		}
+		fieldJSONSchemaProps.AdditionalProperties.Schema = valueSchema
 	} else if isAtomicType(valueType) {
-		fieldJSONSchemaProps.AdditionalProperties.Schema.Type = valueType
+		fieldJSONSchemaProps.AdditionalProperties.Schema = atomicTypeSchema(valueType)
 	} else {
 		return nil, fmt.Errorf("unknown type: %s", valueType)
 	}
+	if nullable {
+		fieldJSONSchemaProps.AdditionalProperties.Schema.Nullable = true
+	}
 
 	return fieldJSONSchemaProps, nil
 }
 
+// handleOneOfType builds the schema of a union type. The union is represented
+// as an object with one optional field per member type, named after the type,
+// and a oneOf constraint requiring exactly one of them to be set. For example
+// `oneOf(GitSource, S3Source)` accepts either `{gitSource: {...}}` or
+// `{s3Source: {...}}`.
+func (tf *transformer) handleOneOfType(key, fieldType string) (*extv1.JSONSchemaProps, error) {
+	memberTypes, err := parseOneOfType(fieldType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse oneOf type for %s: %w", key, err)
+	}
+
+	fieldJSONSchemaProps := &extv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]extv1.JSONSchemaProps{},
+	}
+	for _, memberType := range memberTypes {
+		memberKey := oneOfMemberKey(memberType)
+		if _, ok := fieldJSONSchemaProps.Properties[memberKey]; ok {
+			return nil, fmt.Errorf("duplicate oneOf member type %s for %s", memberType, key)
+		}
+		tf.path = append(tf.path, memberKey)
+		memberSchema, err := tf.parseFieldSchema(key, memberType, nil)
+		tf.path = tf.path[:len(tf.path)-1]
+		if err != nil {
+			return nil, err
+		}
+		fieldJSONSchemaProps.Properties[memberKey] = *memberSchema
+		fieldJSONSchemaProps.OneOf = append(fieldJSONSchemaProps.OneOf, extv1.JSONSchemaProps{
+			Required: []string{memberKey},
+		})
+	}
+	return fieldJSONSchemaProps, nil
+}
+
+// handleCRDRefType builds the schema of a type imported from an existing kind,
+// by looking up the field at the given path in the schema of the kind.
+func (tf *transformer) handleCRDRefType(key, fieldType string) (*extv1.JSONSchemaProps, error) {
+	gvk, path, err := parseCRDRefType(fieldType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse crd type for %s: %w", key, err)
+	}
+	if tf.schemaResolver == nil {
+		return nil, fmt.Errorf("cannot resolve crd type for %s: no schema resolver available", key)
+	}
+
+	kindSchema, err := tf.schemaResolver(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve schema of %s for %s: %w", gvk, key, err)
+	}
+	if kindSchema == nil {
+		return nil, fmt.Errorf("schema of %s not found for %s", gvk, key)
+	}
+
+	fieldSchema := kindSchema
+	if path != "" {
+		for _, segment := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+			property, ok := fieldSchema.Properties[segment]
+			if !ok {
+				return nil, fmt.Errorf("field %s not found in schema of %s for %s", path, gvk, key)
+			}
+			fieldSchema = &property
+		}
+	}
+	return fieldSchema.DeepCopy(), nil
+}
+
 func (tf *transformer) handleSliceType(key, fieldType string) (*extv1.JSONSchemaProps, error) {
+	tf.collectionDepth++
+	defer func() { tf.collectionDepth-- }()
+
 	elementType, err := parseSliceType(fieldType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse slice type for %s: %w", key, err)
@@ -170,75 +498,158 @@ func (tf *transformer) handleSliceType(key, fieldType string) (*extv1.JSONSchema
 		},
 	}
 
-	if isCollectionType(elementType) {
+	elementType, nullable := parseNullableType(elementType)
+	if isCollectionType(elementType) || isOneOfType(elementType) || isCRDRefType(elementType) {
 		elementSchema, err := tf.parseFieldSchema(key, elementType, fieldJSONSchemaProps)
 		if err != nil {
 			return nil, err
 		}
 		fieldJSONSchemaProps.Items.Schema = elementSchema
 	} else if isAtomicType(elementType) {
-		fieldJSONSchemaProps.Items.Schema.Type = elementType
-	} else if preDefinedType, ok := tf.preDefinedTypes[elementType]; ok {
-		fieldJSONSchemaProps.Items.Schema = &preDefinedType
+		fieldJSONSchemaProps.Items.Schema = atomicTypeSchema(elementType)
+	} else if tf.isPreDefinedType(elementType) {
+		elementSchema, err := tf.resolveType(elementType)
+		if err != nil {
+			return nil, err
+		}
+		fieldJSONSchemaProps.Items.Schema = elementSchema
 	} else {
 		return nil, fmt.Errorf("unknown type: %s", elementType)
 	}
+	if nullable {
+		fieldJSONSchemaProps.Items.Schema.Nullable = true
+	}
 
 	return fieldJSONSchemaProps, nil
 }
 
 func (tf *transformer) applyMarkers(schema *extv1.JSONSchemaProps, markers []*Marker, key string, parentSchema *extv1.JSONSchemaProps) error {
+	// validationMessage can be declared before or after the validation marker,
+	// so we look it up first.
+	validationMessage := defaultValidationMessage
+	for _, marker := range markers {
+		if marker.MarkerType == MarkerTypeValidationMessage {
+			if marker.Value == "" {
+				return fmt.Errorf("validation message cannot be empty")
+			}
+			validationMessage = marker.Value
+		}
+	}
+
+	hasValidation := false
+	required := false
+	var groups []fieldGroup
+	sensitive := false
+	var listType string
+	var listMapKeys []string
+	deprecated := false
+	var deprecationMessage string
+	printColumn := false
+	var printColumnName string
+	var printColumnPriority *int32
 	for _, marker := range markers {
 		switch marker.MarkerType {
 		case MarkerTypeRequired:
+			required = true
 			if parentSchema != nil {
 				parentSchema.Required = append(parentSchema.Required, key)
 			}
 		case MarkerTypeDefault:
+			// Defaults computed by an expression aren't part of the schema,
+			// they're applied when the object is reconciled.
+			if strings.HasPrefix(marker.Value, "${") {
+				if err := tf.addExpressionDefault(marker.Value); err != nil {
+					return err
+				}
+				continue
+			}
 			var defaultValue []byte
-			switch schema.Type {
-			case "string":
+			switch {
+			case schema.Type == "array" || schema.Type == "object":
+				structuredDefault, err := parseStructuredDefault(schema.Type, marker.Value)
+				if err != nil {
+					return fmt.Errorf("invalid default value for %s: %w", key, err)
+				}
+				defaultValue = structuredDefault
+			case schema.Type == "string":
 				defaultValue = []byte(fmt.Sprintf("\"%s\"", marker.Value))
-			case "integer", "number", "boolean":
-				defaultValue = []byte(marker.Value)
+			case schema.XIntOrString:
+				// int-or-string values are only left unquoted if they are integers.
+				if _, err := strconv.ParseInt(marker.Value, 10, 64); err == nil {
+					defaultValue = []byte(marker.Value)
+				} else {
+					defaultValue = []byte(fmt.Sprintf("\"%s\"", marker.Value))
+				}
 			default:
 				defaultValue = []byte(marker.Value)
 			}
 			schema.Default = &extv1.JSON{Raw: defaultValue}
 		case MarkerTypeDescription:
 			schema.Description = marker.Value
-		case MarkerTypeMinimum:
+		case MarkerTypeMinimum, MarkerTypeExclusiveMinimum:
+			numericSchema := numericConstraintsTarget(schema)
+			if numericSchema == nil {
+				return fmt.Errorf("%s is only supported for numeric types, got type: %s", marker.Key, schema.Type)
+			}
 			val, err := strconv.ParseFloat(marker.Value, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse minimum enum value: %w", err)
+				return fmt.Errorf("failed to parse %s value: %w", marker.Key, err)
+			}
+			if numericSchema.Minimum != nil {
+				return fmt.Errorf("minimum and exclusiveMinimum are mutually exclusive")
+			}
+			numericSchema.Minimum = &val
+			numericSchema.ExclusiveMinimum = marker.MarkerType == MarkerTypeExclusiveMinimum
+		case MarkerTypeMaximum, MarkerTypeExclusiveMaximum:
+			numericSchema := numericConstraintsTarget(schema)
+			if numericSchema == nil {
+				return fmt.Errorf("%s is only supported for numeric types, got type: %s", marker.Key, schema.Type)
+			}
+			val, err := strconv.ParseFloat(marker.Value, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s value: %w", marker.Key, err)
+			}
+			if numericSchema.Maximum != nil {
+				return fmt.Errorf("maximum and exclusiveMaximum are mutually exclusive")
+			}
+			numericSchema.Maximum = &val
+			numericSchema.ExclusiveMaximum = marker.MarkerType == MarkerTypeExclusiveMaximum
+		case MarkerTypeMultipleOf:
+			numericSchema := numericConstraintsTarget(schema)
+			if numericSchema == nil {
+				return fmt.Errorf("multipleOf is only supported for numeric types, got type: %s", schema.Type)
 			}
-			schema.Minimum = &val
-		case MarkerTypeMaximum:
 			val, err := strconv.ParseFloat(marker.Value, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse maximum enum value: %w", err)
+				return fmt.Errorf("failed to parse multipleOf value: %w", err)
 			}
-			schema.Maximum = &val
+			if val <= 0 {
+				return fmt.Errorf("multipleOf must be greater than 0")
+			}
+			numericSchema.MultipleOf = &val
 		case MarkerTypeValidation:
 			if marker.Value == "" {
 				return fmt.Errorf("validation failed")
 			}
-			validation := []extv1.ValidationRule{
-				{
-					Rule:    marker.Value,
-					Message: "validation failed",
-				},
-			}
-			schema.XValidations = validation
+			schema.XValidations = append(schema.XValidations, extv1.ValidationRule{
+				Rule:    marker.Value,
+				Message: validationMessage,
+			})
+			hasValidation = true
 		case MarkerTypeEnum:
 			var enumJSONValues []extv1.JSON
 
+			seen := make(map[string]struct{})
 			enumValues := strings.Split(marker.Value, ",")
 			for _, val := range enumValues {
 				val = strings.TrimSpace(val)
 				if val == "" {
 					return fmt.Errorf("empty enum values are not allowed")
 				}
+				if _, ok := seen[val]; ok {
+					return fmt.Errorf("duplicate enum value: %s", val)
+				}
+				seen[val] = struct{}{}
 
 				var rawValue []byte
 				switch schema.Type {
@@ -249,19 +660,553 @@ func (tf *transformer) applyMarkers(schema *extv1.JSONSchemaProps, markers []*Ma
 						return fmt.Errorf("failed to parse integer enum value: %w", err)
 					}
 					rawValue = []byte(val)
+				case "number", "float":
+					if _, err := strconv.ParseFloat(val, 64); err != nil {
+						return fmt.Errorf("failed to parse number enum value: %w", err)
+					}
+					rawValue = []byte(val)
 				default:
-					return fmt.Errorf("enum values only supported for string and integer types, got type: %s", schema.Type)
+					return fmt.Errorf("enum values only supported for string, integer and number types, got type: %s", schema.Type)
 				}
 				enumJSONValues = append(enumJSONValues, extv1.JSON{Raw: rawValue})
 			}
 			if len(enumJSONValues) > 0 {
 				schema.Enum = enumJSONValues
 			}
+		case MarkerTypeImmutable:
+			immutable, err := strconv.ParseBool(marker.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse immutable value: %w", err)
+			}
+			if immutable {
+				schema.XValidations = append(schema.XValidations, extv1.ValidationRule{
+					Rule:    "self == oldSelf",
+					Message: "field is immutable",
+				})
+			}
+		case MarkerTypeNullable:
+			nullable, err := strconv.ParseBool(marker.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse nullable value: %w", err)
+			}
+			schema.Nullable = nullable
+		case MarkerTypeMinItems, MarkerTypeMaxItems:
+			if schema.Type != "array" {
+				return fmt.Errorf("%s is only supported for array types, got type: %s", marker.Key, schema.Type)
+			}
+			val, err := strconv.ParseInt(marker.Value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s value: %w", marker.Key, err)
+			}
+			if val < 0 {
+				return fmt.Errorf("%s cannot be negative", marker.Key)
+			}
+			if marker.MarkerType == MarkerTypeMinItems {
+				schema.MinItems = &val
+			} else {
+				schema.MaxItems = &val
+			}
+		case MarkerTypeUniqueItems:
+			if schema.Type != "array" {
+				return fmt.Errorf("uniqueItems is only supported for array types, got type: %s", schema.Type)
+			}
+			unique, err := strconv.ParseBool(marker.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse uniqueItems value: %w", err)
+			}
+			if unique {
+				// Kubernetes structural schemas forbid uniqueItems, the equivalent
+				// is a list of type set, which only supports scalar items.
+				if !isScalarSchema(schema.Items.Schema) {
+					return fmt.Errorf("uniqueItems is only supported for arrays of scalar values")
+				}
+				listType := "set"
+				schema.XListType = &listType
+			}
+		case MarkerTypeListType:
+			if schema.Type != "array" {
+				return fmt.Errorf("listType is only supported for array types, got type: %s", schema.Type)
+			}
+			listType = marker.Value
+		case MarkerTypeListMapKeys:
+			for _, mapKey := range strings.Split(marker.Value, ",") {
+				mapKey = strings.TrimSpace(mapKey)
+				if mapKey == "" {
+					return fmt.Errorf("empty list map keys are not allowed")
+				}
+				listMapKeys = append(listMapKeys, mapKey)
+			}
+		case MarkerTypePattern:
+			stringSchema := stringConstraintsTarget(schema)
+			if stringSchema == nil {
+				return fmt.Errorf("pattern is only supported for string types, got type: %s", schema.Type)
+			}
+			if marker.Value == "" {
+				return fmt.Errorf("pattern cannot be empty")
+			}
+			if _, err := regexp.Compile(marker.Value); err != nil {
+				return fmt.Errorf("failed to compile pattern %q: %w", marker.Value, err)
+			}
+			stringSchema.Pattern = marker.Value
+		case MarkerTypeMinLength, MarkerTypeMaxLength:
+			stringSchema := stringConstraintsTarget(schema)
+			if stringSchema == nil {
+				return fmt.Errorf("%s is only supported for string types, got type: %s", marker.Key, schema.Type)
+			}
+			val, err := strconv.ParseInt(marker.Value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s value: %w", marker.Key, err)
+			}
+			if val < 0 {
+				return fmt.Errorf("%s cannot be negative", marker.Key)
+			}
+			if marker.MarkerType == MarkerTypeMinLength {
+				stringSchema.MinLength = &val
+			} else {
+				stringSchema.MaxLength = &val
+			}
+		case MarkerTypeFormat:
+			stringSchema := stringConstraintsTarget(schema)
+			if stringSchema == nil {
+				return fmt.Errorf("format is only supported for string types, got type: %s", schema.Type)
+			}
+			if !isSupportedFormat(marker.Value) {
+				return fmt.Errorf("unsupported format: %s", marker.Value)
+			}
+			stringSchema.Format = marker.Value
+		case MarkerTypeSensitive:
+			val, err := strconv.ParseBool(marker.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse sensitive value: %w", err)
+			}
+			sensitive = val
+		case MarkerTypeDeprecated:
+			val, err := strconv.ParseBool(marker.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse deprecated value: %w", err)
+			}
+			deprecated = val
+		case MarkerTypeDeprecationMessage:
+			if marker.Value == "" {
+				return fmt.Errorf("deprecationMessage cannot be empty")
+			}
+			if strings.Contains(marker.Value, "\n") {
+				return fmt.Errorf("deprecationMessage cannot span multiple lines")
+			}
+			deprecationMessage = marker.Value
+		case MarkerTypePrintColumn:
+			val, err := strconv.ParseBool(marker.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse printColumn value: %w", err)
+			}
+			printColumn = val
+		case MarkerTypePrintColumnName:
+			if marker.Value == "" {
+				return fmt.Errorf("printColumnName cannot be empty")
+			}
+			printColumnName = marker.Value
+		case MarkerTypePrintColumnPriority:
+			val, err := strconv.ParseInt(marker.Value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("failed to parse printColumnPriority value: %w", err)
+			}
+			if val < 0 {
+				return fmt.Errorf("printColumnPriority cannot be negative")
+			}
+			priority := int32(val)
+			printColumnPriority = &priority
+		case MarkerTypeExactlyOneOf, MarkerTypeAtMostOneOf, MarkerTypeAtLeastOneOf:
+			if marker.Value == "" {
+				return fmt.Errorf("%s group name cannot be empty", marker.Key)
+			}
+			groups = append(groups, fieldGroup{markerType: marker.MarkerType, name: marker.Value})
 		}
 	}
+
+	if len(groups) > 0 {
+		if err := tf.addToFieldGroups(schema, key, parentSchema, groups, required); err != nil {
+			return err
+		}
+	}
+
+	// Sensitive fields are marked with the password format, which tells
+	// clients to hide the value, and kro to redact it.
+	if sensitive {
+		stringSchema := stringConstraintsTarget(schema)
+		if stringSchema == nil {
+			return fmt.Errorf("sensitive is only supported for string types, got type: %s", schema.Type)
+		}
+		if stringSchema.Format != "" && stringSchema.Format != SensitiveFormat {
+			return fmt.Errorf("sensitive fields can't declare the %s format", stringSchema.Format)
+		}
+		stringSchema.Format = SensitiveFormat
+	}
+
+	if deprecated {
+		schema.Description = deprecatedDescription(schema.Description, deprecationMessage)
+	} else if deprecationMessage != "" {
+		return fmt.Errorf("deprecationMessage marker requires a deprecated marker")
+	}
+
+	if listType != "" || len(listMapKeys) > 0 {
+		if err := applyListType(schema, listType, listMapKeys); err != nil {
+			return err
+		}
+	}
+
+	if printColumn {
+		if err := tf.addPrinterColumn(schema, printColumnName, printColumnPriority); err != nil {
+			return err
+		}
+	} else if printColumnName != "" || printColumnPriority != nil {
+		return fmt.Errorf("printColumnName and printColumnPriority markers require a printColumn marker")
+	}
+
+	if validationMessage != defaultValidationMessage && !hasValidation {
+		return fmt.Errorf("validationMessage marker requires a validation marker")
+	}
+	if schema.MinItems != nil && schema.MaxItems != nil && *schema.MinItems > *schema.MaxItems {
+		return fmt.Errorf("minItems (%d) cannot be greater than maxItems (%d)", *schema.MinItems, *schema.MaxItems)
+	}
+	if stringSchema := stringConstraintsTarget(schema); stringSchema != nil &&
+		stringSchema.MinLength != nil && stringSchema.MaxLength != nil && *stringSchema.MinLength > *stringSchema.MaxLength {
+		return fmt.Errorf("minLength (%d) cannot be greater than maxLength (%d)", *stringSchema.MinLength, *stringSchema.MaxLength)
+	}
+	if numericSchema := numericConstraintsTarget(schema); numericSchema != nil &&
+		numericSchema.Minimum != nil && numericSchema.Maximum != nil {
+		minimum, maximum := *numericSchema.Minimum, *numericSchema.Maximum
+		if minimum > maximum {
+			return fmt.Errorf("minimum (%v) cannot be greater than maximum (%v)", minimum, maximum)
+		}
+		if minimum == maximum && (numericSchema.ExclusiveMinimum || numericSchema.ExclusiveMaximum) {
+			return fmt.Errorf("exclusive bounds (%v) leave no valid value", minimum)
+		}
+	}
+	if schema.Default != nil && len(schema.Enum) > 0 && !enumContains(schema.Enum, schema.Default) {
+		return fmt.Errorf("default value %s is not one of the enum values", string(schema.Default.Raw))
+	}
+	return nil
+}
+
+// applyListType sets the list type of the given array schema. Lists of type
+// map are merged item by item by server-side apply, the items being identified
+// by the given keys.
+func applyListType(schema *extv1.JSONSchemaProps, listType string, listMapKeys []string) error {
+	if listType == "" {
+		return fmt.Errorf("listMapKeys marker requires a listType=map marker")
+	}
+	if schema.XListType != nil && *schema.XListType != listType {
+		return fmt.Errorf("listType %s conflicts with uniqueItems, which implies listType set", listType)
+	}
+
+	items := schema.Items.Schema
+	switch listType {
+	case "atomic":
+	case "set":
+		if !isScalarSchema(items) {
+			return fmt.Errorf("listType set is only supported for arrays of scalar values")
+		}
+	case "map":
+		if items == nil || items.Type != "object" || len(items.Properties) == 0 {
+			return fmt.Errorf("listType map is only supported for arrays of objects")
+		}
+		if len(listMapKeys) == 0 {
+			return fmt.Errorf("listType map requires a listMapKeys marker")
+		}
+		seen := make(map[string]struct{}, len(listMapKeys))
+		for _, mapKey := range listMapKeys {
+			if _, ok := seen[mapKey]; ok {
+				return fmt.Errorf("duplicate list map key: %s", mapKey)
+			}
+			seen[mapKey] = struct{}{}
+
+			property, ok := items.Properties[mapKey]
+			if !ok {
+				return fmt.Errorf("list map key %s is not a field of the array items", mapKey)
+			}
+			if !isScalarSchema(&property) {
+				return fmt.Errorf("list map key %s must be a scalar field", mapKey)
+			}
+			// The API server requires map keys to always be set.
+			if property.Default == nil && !slices.Contains(items.Required, mapKey) {
+				return fmt.Errorf("list map key %s must be required or have a default value", mapKey)
+			}
+		}
+		schema.XListMapKeys = listMapKeys
+	default:
+		return fmt.Errorf("unsupported list type: %s, must be one of atomic, set or map", listType)
+	}
+	if listType != "map" && len(listMapKeys) > 0 {
+		return fmt.Errorf("listMapKeys marker requires a listType=map marker")
+	}
+	schema.XListType = &listType
 	return nil
 }
 
+// addToFieldGroups adds the field being transformed to the given groups of
+// its parent object.
+func (tf *transformer) addToFieldGroups(
+	schema *extv1.JSONSchemaProps, key string, parentSchema *extv1.JSONSchemaProps,
+	groups []fieldGroup, required bool,
+) error {
+	if parentSchema == nil || tf.fieldGroups == nil {
+		return fmt.Errorf("field groups are only supported for fields of objects")
+	}
+	if !celIdentifierRegexp.MatchString(key) {
+		return fmt.Errorf("field %s can't be part of a field group, its name is not a valid CEL identifier", key)
+	}
+	// Required fields and fields with a default value are always set, which
+	// defeats the purpose of the group.
+	if required {
+		return fmt.Errorf("fields of a field group can't be required")
+	}
+	if schema.Default != nil {
+		return fmt.Errorf("fields of a field group can't have a default value")
+	}
+	for _, group := range groups {
+		if slices.Contains(tf.fieldGroups[group], key) {
+			return fmt.Errorf("duplicate %s marker for group %s", group.markerType, group.name)
+		}
+		tf.fieldGroups[group] = append(tf.fieldGroups[group], key)
+	}
+	return nil
+}
+
+// celIdentifierRegexp matches the field names that can be referenced in CEL
+// without escaping.
+var celIdentifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// fieldGroupValidations returns the validation rules enforcing the given field
+// groups on their object. For example, the group of `exactlyOneOf=vpc` fields
+// `existingVPC` and `vpcConfig` results in the rule
+// `[has(self.existingVPC), has(self.vpcConfig)].exists_one(x, x)`.
+func fieldGroupValidations(groups map[fieldGroup][]string) (extv1.ValidationRules, error) {
+	// Sort the groups so that the rules are deterministic.
+	keys := make([]fieldGroup, 0, len(groups))
+	for group := range groups {
+		keys = append(keys, group)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].markerType != keys[j].markerType {
+			return keys[i].markerType < keys[j].markerType
+		}
+		return keys[i].name < keys[j].name
+	})
+
+	var rules extv1.ValidationRules
+	for _, group := range keys {
+		fields := groups[group]
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s group %s must have at least two fields", group.markerType, group.name)
+		}
+		sort.Strings(fields)
+
+		conditions := make([]string, 0, len(fields))
+		for _, field := range fields {
+			conditions = append(conditions, fmt.Sprintf("has(self.%s)", field))
+		}
+		list := "[" + strings.Join(conditions, ", ") + "]"
+		names := strings.Join(fields, ", ")
+
+		var rule extv1.ValidationRule
+		switch group.markerType {
+		case MarkerTypeExactlyOneOf:
+			rule = extv1.ValidationRule{
+				Rule:    list + ".exists_one(x, x)",
+				Message: fmt.Sprintf("exactly one of %s must be set", names),
+			}
+		case MarkerTypeAtMostOneOf:
+			rule = extv1.ValidationRule{
+				Rule:    list + ".filter(x, x).size() <= 1",
+				Message: fmt.Sprintf("at most one of %s can be set", names),
+			}
+		case MarkerTypeAtLeastOneOf:
+			rule = extv1.ValidationRule{
+				Rule:    list + ".exists(x, x)",
+				Message: fmt.Sprintf("at least one of %s must be set", names),
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// addPrinterColumn adds a printer column showing the field being transformed.
+// The column is named after the field unless a name is given.
+func (tf *transformer) addPrinterColumn(schema *extv1.JSONSchemaProps, name string, priority *int32) error {
+	// Types are loaded outside of the context of any field, the columns are
+	// added when the types are referenced.
+	if tf.loadingTypes {
+		return nil
+	}
+	if tf.collectionDepth > 0 {
+		return fmt.Errorf("printColumn is not supported for fields nested in arrays and maps")
+	}
+
+	var columnType string
+	switch {
+	case schema.XIntOrString:
+		columnType = "string"
+	case schema.Type == "string" && schema.Format == SensitiveFormat:
+		return fmt.Errorf("printColumn is not supported for sensitive fields")
+	case schema.Type == "string" && schema.Format == "date-time":
+		columnType = "date"
+	case schema.Type == "string", schema.Type == "integer", schema.Type == "number", schema.Type == "boolean":
+		columnType = schema.Type
+	case schema.Type == "float":
+		columnType = "number"
+	default:
+		return fmt.Errorf("printColumn is only supported for scalar fields, got type: %s", schema.Type)
+	}
+
+	fieldName := tf.path[len(tf.path)-1]
+	if name == "" {
+		name = strings.ToUpper(fieldName[:1]) + fieldName[1:]
+	}
+	column := extv1.CustomResourceColumnDefinition{
+		Name:        name,
+		Type:        columnType,
+		Description: schema.Description,
+		JSONPath:    "." + strings.Join(tf.path, "."),
+	}
+	if priority != nil {
+		column.Priority = *priority
+	}
+	tf.printerColumns = append(tf.printerColumns, column)
+	return nil
+}
+
+// addExpressionDefault records the default of the field being transformed,
+// computed by the given expression.
+func (tf *transformer) addExpressionDefault(expression string) error {
+	// Like printer columns, the defaults of the types are recorded when the
+	// types are referenced.
+	if tf.loadingTypes {
+		return nil
+	}
+	if tf.collectionDepth > 0 {
+		return fmt.Errorf("expression defaults are not supported for fields nested in arrays and maps")
+	}
+	tf.expressionDefaults = append(tf.expressionDefaults, ExpressionDefault{
+		Path:       slices.Clone(tf.path),
+		Expression: expression,
+	})
+	return nil
+}
+
+// stringConstraintsTarget returns the schema string constraints (pattern,
+// minLength, maxLength and format) apply to. For strings, this is the schema
+// itself. For arrays and maps, the constraints are propagated to the string
+// items and values, e.g `[]string | format=email` is a list of emails. It
+// returns nil if the constraints can't be applied.
+func stringConstraintsTarget(schema *extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	switch {
+	case schema == nil:
+		return nil
+	case schema.Type == "string":
+		return schema
+	case schema.Type == "array" && schema.Items != nil:
+		return stringConstraintsTarget(schema.Items.Schema)
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		return stringConstraintsTarget(schema.AdditionalProperties.Schema)
+	default:
+		return nil
+	}
+}
+
+// numericConstraintsTarget returns the schema numeric constraints (minimum,
+// maximum, their exclusive variants and multipleOf) apply to. Like string
+// constraints, they are propagated to the items and values of arrays and maps,
+// e.g `[]integer | minimum=1`. It returns nil if the constraints can't be
+// applied.
+func numericConstraintsTarget(schema *extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	switch {
+	case schema == nil:
+		return nil
+	case schema.Type == "integer", schema.Type == "number", schema.Type == "float":
+		return schema
+	case schema.Type == "array" && schema.Items != nil:
+		return numericConstraintsTarget(schema.Items.Schema)
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		return numericConstraintsTarget(schema.AdditionalProperties.Schema)
+	default:
+		return nil
+	}
+}
+
+// supportedFormats is the list of string formats validated by the Kubernetes
+// API server. Other formats are silently ignored, so we reject them.
+var supportedFormats = map[string]struct{}{
+	"bsonobjectid": {}, "uri": {}, "email": {}, "hostname": {}, "ipv4": {}, "ipv6": {},
+	"cidr": {}, "mac": {}, "uuid": {}, "uuid3": {}, "uuid4": {}, "uuid5": {},
+	"isbn": {}, "isbn10": {}, "isbn13": {}, "creditcard": {}, "ssn": {},
+	"hexcolor": {}, "rgbcolor": {}, "byte": {}, "password": {},
+	"date": {}, "duration": {}, "datetime": {},
+}
+
+// isSupportedFormat returns true if the given format is validated by the
+// Kubernetes API server. Like the API server, dashes are ignored, e.g
+// `date-time` and `datetime` are the same format.
+func isSupportedFormat(format string) bool {
+	_, ok := supportedFormats[strings.ReplaceAll(format, "-", "")]
+	return ok
+}
+
+// isScalarSchema returns true if the given schema describes a scalar value.
+func isScalarSchema(schema *extv1.JSONSchemaProps) bool {
+	if schema == nil {
+		return false
+	}
+	switch schema.Type {
+	case "string", "integer", "number", "boolean", "float":
+		return true
+	default:
+		return schema.XIntOrString
+	}
+}
+
+// parseStructuredDefault parses the default value of an array or object field.
+// The value can be written either in JSON or in YAML flow style, e.g
+// `[a, b]` or `{cpu: 100m}`, and is converted to JSON.
+func parseStructuredDefault(schemaType, value string) ([]byte, error) {
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, err
+	}
+	switch parsed.(type) {
+	case []interface{}:
+		if schemaType != "array" {
+			return nil, fmt.Errorf("expected an object, got an array")
+		}
+	case map[string]interface{}:
+		if schemaType != "object" {
+			return nil, fmt.Errorf("expected an array, got an object")
+		}
+	default:
+		return nil, fmt.Errorf("expected an %s, got %q", schemaType, value)
+	}
+	return json.Marshal(parsed)
+}
+
+// enumContains returns true if the given value is one of the enum values. Values
+// are compared after JSON decoding, so that e.g 1 and 1.0 are considered equal.
+func enumContains(enum []extv1.JSON, value *extv1.JSON) bool {
+	var want interface{}
+	if err := json.Unmarshal(value.Raw, &want); err != nil {
+		return false
+	}
+	for _, e := range enum {
+		var got interface{}
+		if err := json.Unmarshal(e.Raw, &got); err != nil {
+			continue
+		}
+		if reflect.DeepEqual(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
 // Other functions (LoadPreDefinedTypes, transformMap) remain unchanged
 func transformMap(original map[interface{}]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
@@ -14,12 +14,30 @@
 package simpleschema
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
 func TestBuildOpenAPISchema(t *testing.T) {
 	transformer := newTransformer()
 
@@ -328,6 +346,53 @@ func TestBuildOpenAPISchema(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Unquoted string enum and number enum",
+			obj: map[string]interface{}{
+				"size":  "string | enum=small,medium,large default=medium",
+				"ratio": "float | enum=\"0.5,1,1.5\" default=1.0",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"size": {
+						Type:    "string",
+						Default: &extv1.JSON{Raw: []byte("\"medium\"")},
+						Enum: []extv1.JSON{
+							{Raw: []byte("\"small\"")},
+							{Raw: []byte("\"medium\"")},
+							{Raw: []byte("\"large\"")},
+						},
+					},
+					"ratio": {
+						Type:    "float",
+						Default: &extv1.JSON{Raw: []byte("1.0")},
+						Enum: []extv1.JSON{
+							{Raw: []byte("0.5")},
+							{Raw: []byte("1")},
+							{Raw: []byte("1.5")},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Duplicate enum values",
+			obj: map[string]interface{}{
+				"size": "string | enum=small,small",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Default not in enum",
+			obj: map[string]interface{}{
+				"size": "string | enum=small,medium default=huge",
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "invalid enum type",
 			obj: map[string]interface{}{
@@ -420,71 +485,176 @@ func TestBuildOpenAPISchema(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := transformer.buildOpenAPISchema(tt.obj)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("BuildOpenAPISchema() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("BuildOpenAPISchema() = %+v, want %+v", got, tt.want)
-			}
-		})
-	}
-}
-
-func TestLoadPreDefinedTypes(t *testing.T) {
-	transformer := newTransformer()
-
-	tests := []struct {
-		name    string
-		obj     map[string]interface{}
-		want    map[string]extv1.JSONSchemaProps
-		wantErr bool
-	}{
 		{
-			name: "Valid types",
+			name: "Minimum, maximum and pattern",
 			obj: map[string]interface{}{
-				"Person": map[string]interface{}{
-					"name": "string",
-					"age":  "integer",
-					"address": map[string]interface{}{
-						"street": "string",
-						"city":   "string",
+				"replicas": "integer | minimum=1 maximum=100",
+				"name":     "string | pattern=^[a-z0-9-]+$",
+				"version":  `string | pattern="^v\\d+$"`,
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"replicas": {
+						Type:    "integer",
+						Minimum: float64Ptr(1),
+						Maximum: float64Ptr(100),
+					},
+					"name": {
+						Type:    "string",
+						Pattern: "^[a-z0-9-]+$",
+					},
+					"version": {
+						Type:    "string",
+						Pattern: "^v\\d+$",
 					},
 				},
-				"Company": map[string]interface{}{
-					"name":      "string",
-					"employees": "[]string",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Descriptions on atomic and collection fields",
+			obj: map[string]interface{}{
+				"image": `string | description="Container image to deploy"`,
+				"ports": `[]integer | description="Ports exposed by the container"`,
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"image": {
+						Type:        "string",
+						Description: "Container image to deploy",
+					},
+					"ports": {
+						Type:        "array",
+						Description: "Ports exposed by the container",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "integer"},
+						},
+					},
 				},
 			},
-			want: map[string]extv1.JSONSchemaProps{
-				"Person": {
-					Type: "object",
-					Properties: map[string]extv1.JSONSchemaProps{
-						"name": {Type: "string"},
-						"age":  {Type: "integer"},
-						"address": {
-							Type: "object",
-							Properties: map[string]extv1.JSONSchemaProps{
-								"street": {Type: "string"},
-								"city":   {Type: "string"},
-							},
+			wantErr: false,
+		},
+		{
+			name: "Immutable fields",
+			obj: map[string]interface{}{
+				"storageClass": "string | immutable=true",
+				"size":         `integer | immutable=true validation="self > 0"`,
+				"tier":         "string | immutable=false",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"storageClass": {
+						Type: "string",
+						XValidations: []extv1.ValidationRule{
+							{Rule: "self == oldSelf", Message: "field is immutable"},
+						},
+					},
+					"size": {
+						Type: "integer",
+						XValidations: []extv1.ValidationRule{
+							{Rule: "self == oldSelf", Message: "field is immutable"},
+							{Rule: "self > 0", Message: "validation failed"},
 						},
 					},
+					"tier": {
+						Type: "string",
+					},
 				},
-				"Company": {
-					Type: "object",
-					Properties: map[string]extv1.JSONSchemaProps{
-						"name": {Type: "string"},
-						"employees": {
-							Type: "array",
-							Items: &extv1.JSONSchemaPropsOrArray{
-								Schema: &extv1.JSONSchemaProps{
-									Type: "string",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid immutable value",
+			obj: map[string]interface{}{
+				"storageClass": "string | immutable=yes",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Validation with message",
+			obj: map[string]interface{}{
+				"range": map[string]interface{}{
+					"min": `integer`,
+					"max": `integer`,
+				},
+				"name": `string | validationMessage="name must not be empty" validation="self.size() > 0"`,
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"range": {
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"min": {Type: "integer"},
+							"max": {Type: "integer"},
+						},
+					},
+					"name": {
+						Type: "string",
+						XValidations: []extv1.ValidationRule{
+							{Rule: "self.size() > 0", Message: "name must not be empty"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Validation message without validation",
+			obj: map[string]interface{}{
+				"name": `string | validationMessage="name must not be empty"`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Nullable fields",
+			obj: map[string]interface{}{
+				"nickname": "string?",
+				"tags":     "[]string?",
+				"labels":   "map[string]string? | nullable=true",
+				"owner":    "Person?",
+				"matrix":   "[][]integer?",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"nickname": {
+						Type:     "string",
+						Nullable: true,
+					},
+					"tags": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "string", Nullable: true},
+						},
+					},
+					"labels": {
+						Type:     "object",
+						Nullable: true,
+						AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+							Schema: &extv1.JSONSchemaProps{Type: "string", Nullable: true},
+						},
+					},
+					"owner": {
+						Type:     "object",
+						Nullable: true,
+						Properties: map[string]extv1.JSONSchemaProps{
+							"name": {Type: "string"},
+							"age":  {Type: "integer"},
+						},
+					},
+					"matrix": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{
+								Type: "array",
+								Items: &extv1.JSONSchemaPropsOrArray{
+									Schema: &extv1.JSONSchemaProps{Type: "integer", Nullable: true},
 								},
 							},
 						},
@@ -494,25 +664,1399 @@ func TestLoadPreDefinedTypes(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "Invalid type",
+			name: "Invalid nullable marker",
 			obj: map[string]interface{}{
-				"invalid": 123,
+				"nickname": "string | nullable=maybe",
 			},
-			want:    map[string]extv1.JSONSchemaProps{},
+			want:    nil,
 			wantErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := transformer.loadPreDefinedTypes(tt.obj)
-			if (err != nil) != tt.wantErr {
-				t.Fatalf("LoadPreDefinedTypes() error = %v", err)
-				return
-			}
-			if !reflect.DeepEqual(transformer.preDefinedTypes, tt.want) {
-				t.Errorf("LoadPreDefinedTypes() = %+v, want %+v", transformer.preDefinedTypes, tt.want)
-			}
-		})
+		{
+			name: "Quantity, duration and datetime types",
+			obj: map[string]interface{}{
+				"memory":    `quantity | default="512Mi"`,
+				"cpu":       "quantity | default=2",
+				"timeout":   `duration | default="30s"`,
+				"expiresAt": "datetime",
+				"limits":    "map[string]quantity",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"memory": {
+						AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+						Pattern:      QuantityPattern,
+						XIntOrString: true,
+						Default:      &extv1.JSON{Raw: []byte(`"512Mi"`)},
+					},
+					"cpu": {
+						AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+						Pattern:      QuantityPattern,
+						XIntOrString: true,
+						Default:      &extv1.JSON{Raw: []byte(`2`)},
+					},
+					"timeout": {
+						Type:    "string",
+						Format:  "duration",
+						Default: &extv1.JSON{Raw: []byte(`"30s"`)},
+					},
+					"expiresAt": {
+						Type:   "string",
+						Format: "date-time",
+					},
+					"limits": {
+						Type: "object",
+						AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+							Schema: &extv1.JSONSchemaProps{
+								AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+								Pattern:      QuantityPattern,
+								XIntOrString: true,
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Int or string type",
+			obj: map[string]interface{}{
+				"targetPort":     "intorstring | required=true",
+				"maxUnavailable": `intorstring | default="25%"`,
+				"maxSurge":       "intorstring | default=1",
+				"ports":          "[]intorstring",
+				"limits":         "map[string]intorstring",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"targetPort"},
+				Properties: map[string]extv1.JSONSchemaProps{
+					"targetPort": {
+						AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+						XIntOrString: true,
+					},
+					"maxUnavailable": {
+						AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+						XIntOrString: true,
+						Default:      &extv1.JSON{Raw: []byte(`"25%"`)},
+					},
+					"maxSurge": {
+						AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+						XIntOrString: true,
+						Default:      &extv1.JSON{Raw: []byte(`1`)},
+					},
+					"ports": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{
+								AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+								XIntOrString: true,
+							},
+						},
+					},
+					"limits": {
+						Type: "object",
+						AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+							Schema: &extv1.JSONSchemaProps{
+								AnyOf:        []extv1.JSONSchemaProps{{Type: "integer"}, {Type: "string"}},
+								XIntOrString: true,
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Sensitive fields",
+			obj: map[string]interface{}{
+				"password": "string | sensitive=true required=true",
+				"tokens":   "[]string | sensitive=true",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"password"},
+				Properties: map[string]extv1.JSONSchemaProps{
+					"password": {Type: "string", Format: "password"},
+					"tokens": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "string", Format: "password"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Sensitive marker on a non string field",
+			obj: map[string]interface{}{
+				"port": "integer | sensitive=true",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Sensitive marker with another format",
+			obj: map[string]interface{}{
+				"email": "string | sensitive=true format=email",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Free-form any type",
+			obj: map[string]interface{}{
+				"values":      "any",
+				"annotations": `any | default={"team": "platform"}`,
+				"extra":       "[]any",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"values": {
+						Type:                   "object",
+						XPreserveUnknownFields: boolPtr(true),
+					},
+					"annotations": {
+						Type:                   "object",
+						XPreserveUnknownFields: boolPtr(true),
+						Default:                &extv1.JSON{Raw: []byte(`{"team":"platform"}`)},
+					},
+					"extra": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{
+								Type:                   "object",
+								XPreserveUnknownFields: boolPtr(true),
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "oneOf type",
+			obj: map[string]interface{}{
+				"owner":   "oneOf(Person, Address) | required=true",
+				"holders": "[]oneOf(Person, Address)",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"owner"},
+				Properties: map[string]extv1.JSONSchemaProps{
+					"owner": {
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"person":  transformer.preDefinedTypes["Person"],
+							"address": transformer.preDefinedTypes["Address"],
+						},
+						OneOf: []extv1.JSONSchemaProps{
+							{Required: []string{"person"}},
+							{Required: []string{"address"}},
+						},
+					},
+					"holders": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]extv1.JSONSchemaProps{
+									"person":  transformer.preDefinedTypes["Person"],
+									"address": transformer.preDefinedTypes["Address"],
+								},
+								OneOf: []extv1.JSONSchemaProps{
+									{Required: []string{"person"}},
+									{Required: []string{"address"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "oneOf with a single member",
+			obj: map[string]interface{}{
+				"owner": "oneOf(Person)",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "oneOf with duplicate members",
+			obj: map[string]interface{}{
+				"owner": "oneOf(Person, Person)",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "oneOf with unknown member",
+			obj: map[string]interface{}{
+				"owner": "oneOf(Person, Robot)",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Structured defaults",
+			obj: map[string]interface{}{
+				"tags":      `[]string | default=["a", "b"]`,
+				"zones":     "[]string | default=[us-east-1a, us-east-1b]",
+				"labels":    "map[string]string | default={team: platform, tier: web}",
+				"resources": `Person | default={"name": "default", "age": 1}`,
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"tags": {
+						Type:    "array",
+						Default: &extv1.JSON{Raw: []byte(`["a","b"]`)},
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"zones": {
+						Type:    "array",
+						Default: &extv1.JSON{Raw: []byte(`["us-east-1a","us-east-1b"]`)},
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"labels": {
+						Type:    "object",
+						Default: &extv1.JSON{Raw: []byte(`{"team":"platform","tier":"web"}`)},
+						AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+							Schema: &extv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"resources": {
+						Type:    "object",
+						Default: &extv1.JSON{Raw: []byte(`{"age":1,"name":"default"}`)},
+						Properties: map[string]extv1.JSONSchemaProps{
+							"name": {Type: "string"},
+							"age":  {Type: "integer"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Structured default with the wrong shape",
+			obj: map[string]interface{}{
+				"tags": "[]string | default={a: b}",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Structured defaults declared in YAML",
+			obj: map[string]interface{}{
+				"tags": map[string]interface{}{
+					"type":    "[]string | minItems=1",
+					"default": []interface{}{"a", "b"},
+				},
+				"labels": map[string]interface{}{
+					"type":    "map[string]string",
+					"default": map[string]interface{}{"team": "platform", "tier": "web"},
+				},
+				"owner": map[string]interface{}{
+					"type":    "Person",
+					"default": map[string]interface{}{"name": "default", "age": int64(1)},
+				},
+				"resources": map[string]interface{}{
+					"type": map[string]interface{}{
+						"cpu":    "string",
+						"memory": "string",
+					},
+					"default": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+				},
+				"image": map[string]interface{}{
+					"type":    "string",
+					"default": "nginx",
+				},
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"tags": {
+						Type:     "array",
+						MinItems: int64Ptr(1),
+						Default:  &extv1.JSON{Raw: []byte(`["a","b"]`)},
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"labels": {
+						Type:    "object",
+						Default: &extv1.JSON{Raw: []byte(`{"team":"platform","tier":"web"}`)},
+						AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+							Schema: &extv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"owner": {
+						Type:    "object",
+						Default: &extv1.JSON{Raw: []byte(`{"age":1,"name":"default"}`)},
+						Properties: map[string]extv1.JSONSchemaProps{
+							"name": {Type: "string"},
+							"age":  {Type: "integer"},
+						},
+					},
+					"resources": {
+						Type:    "object",
+						Default: &extv1.JSON{Raw: []byte(`{"cpu":"100m","memory":"128Mi"}`)},
+						Properties: map[string]extv1.JSONSchemaProps{
+							"cpu":    {Type: "string"},
+							"memory": {Type: "string"},
+						},
+					},
+					"image": {
+						Type:    "string",
+						Default: &extv1.JSON{Raw: []byte(`"nginx"`)},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Structured default declared in YAML with the wrong shape",
+			obj: map[string]interface{}{
+				"tags": map[string]interface{}{
+					"type":    "[]string",
+					"default": map[string]interface{}{"a": "b"},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Structured default declared in YAML and in a marker",
+			obj: map[string]interface{}{
+				"tags": map[string]interface{}{
+					"type":    "[]string | default=[a]",
+					"default": []interface{}{"b"},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Scalar default for an array",
+			obj: map[string]interface{}{
+				"tags": "[]string | default=a",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Array constraints",
+			obj: map[string]interface{}{
+				"tags": "[]string | minItems=1 maxItems=10 uniqueItems=true",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"tags": {
+						Type:      "array",
+						MinItems:  int64Ptr(1),
+						MaxItems:  int64Ptr(10),
+						XListType: stringPtr("set"),
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "minItems greater than maxItems",
+			obj: map[string]interface{}{
+				"tags": "[]string | minItems=5 maxItems=1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "maxItems on non array type",
+			obj: map[string]interface{}{
+				"name": "string | maxItems=1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "uniqueItems on array of objects",
+			obj: map[string]interface{}{
+				"people": "[]Person | uniqueItems=true",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "String constraints",
+			obj: map[string]interface{}{
+				"name":    "string | minLength=3 maxLength=63",
+				"website": "string | format=uri",
+				"emails":  "[]string | format=email maxLength=254",
+				"hosts":   "map[string]string | format=hostname",
+				"owner": map[string]interface{}{
+					"email": "string | format=email",
+				},
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"name": {
+						Type:      "string",
+						MinLength: int64Ptr(3),
+						MaxLength: int64Ptr(63),
+					},
+					"website": {
+						Type:   "string",
+						Format: "uri",
+					},
+					"emails": {
+						Type: "array",
+						Items: &extv1.JSONSchemaPropsOrArray{
+							Schema: &extv1.JSONSchemaProps{
+								Type:      "string",
+								Format:    "email",
+								MaxLength: int64Ptr(254),
+							},
+						},
+					},
+					"hosts": {
+						Type: "object",
+						AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+							Schema: &extv1.JSONSchemaProps{
+								Type:   "string",
+								Format: "hostname",
+							},
+						},
+					},
+					"owner": {
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"email": {
+								Type:   "string",
+								Format: "email",
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Unsupported format",
+			obj: map[string]interface{}{
+				"website": "string | format=url",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "minLength greater than maxLength",
+			obj: map[string]interface{}{
+				"name": "string | minLength=10 maxLength=1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "maxLength on integer",
+			obj: map[string]interface{}{
+				"count": "integer | maxLength=1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Minimum greater than maximum",
+			obj: map[string]interface{}{
+				"replicas": "integer | minimum=10 maximum=1",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Invalid pattern",
+			obj: map[string]interface{}{
+				"name": "string | pattern=(abc",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Pattern on non string type",
+			obj: map[string]interface{}{
+				"replicas": "integer | pattern=^[0-9]+$",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transformer.buildOpenAPISchema(tt.obj)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BuildOpenAPISchema() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildOpenAPISchema() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPreDefinedTypes(t *testing.T) {
+	transformer := newTransformer()
+
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		want    map[string]extv1.JSONSchemaProps
+		wantErr bool
+	}{
+		{
+			name: "Valid types",
+			obj: map[string]interface{}{
+				"Person": map[string]interface{}{
+					"name": "string",
+					"age":  "integer",
+					"address": map[string]interface{}{
+						"street": "string",
+						"city":   "string",
+					},
+				},
+				"Company": map[string]interface{}{
+					"name":      "string",
+					"employees": "[]string",
+				},
+			},
+			want: map[string]extv1.JSONSchemaProps{
+				"Person": {
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"name": {Type: "string"},
+						"age":  {Type: "integer"},
+						"address": {
+							Type: "object",
+							Properties: map[string]extv1.JSONSchemaProps{
+								"street": {Type: "string"},
+								"city":   {Type: "string"},
+							},
+						},
+					},
+				},
+				"Company": {
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"name": {Type: "string"},
+						"employees": {
+							Type: "array",
+							Items: &extv1.JSONSchemaPropsOrArray{
+								Schema: &extv1.JSONSchemaProps{
+									Type: "string",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Types referencing each other",
+			obj: map[string]interface{}{
+				"Team": map[string]interface{}{
+					"lead":    "Member",
+					"members": "[]Member",
+				},
+				"Member": map[string]interface{}{
+					"name": "string",
+				},
+			},
+			want: map[string]extv1.JSONSchemaProps{
+				"Member": {
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"name": {Type: "string"},
+					},
+				},
+				"Team": {
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"lead": {
+							Type: "object",
+							Properties: map[string]extv1.JSONSchemaProps{
+								"name": {Type: "string"},
+							},
+						},
+						"members": {
+							Type: "array",
+							Items: &extv1.JSONSchemaPropsOrArray{
+								Schema: &extv1.JSONSchemaProps{
+									Type: "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"name": {Type: "string"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Type name conflicting with built-in type",
+			obj: map[string]interface{}{
+				"string": map[string]interface{}{
+					"name": "string",
+				},
+			},
+			want:    map[string]extv1.JSONSchemaProps{},
+			wantErr: true,
+		},
+		{
+			name: "Unresolvable type reference",
+			obj: map[string]interface{}{
+				"Team": map[string]interface{}{
+					"lead": "Member",
+				},
+			},
+			want:    map[string]extv1.JSONSchemaProps{},
+			wantErr: true,
+		},
+		{
+			name: "Invalid type",
+			obj: map[string]interface{}{
+				"invalid": 123,
+			},
+			want:    map[string]extv1.JSONSchemaProps{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := transformer.loadPreDefinedTypes(tt.obj)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPreDefinedTypes() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(transformer.preDefinedTypes, tt.want) {
+				t.Errorf("LoadPreDefinedTypes() = %+v, want %+v", transformer.preDefinedTypes, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecursiveTypes(t *testing.T) {
+	preserveUnknownFields := true
+	depthExceeded := func(name string, depth int) extv1.JSONSchemaProps {
+		return extv1.JSONSchemaProps{
+			Type:                   "object",
+			XPreserveUnknownFields: &preserveUnknownFields,
+			XValidations: extv1.ValidationRules{{
+				Rule:    "false",
+				Message: fmt.Sprintf("type %s exceeds the maximum nesting depth of %d", name, depth),
+			}},
+		}
+	}
+	treeNode := func(children extv1.JSONSchemaProps) extv1.JSONSchemaProps {
+		return extv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]extv1.JSONSchemaProps{
+				"value": {Type: "string"},
+				"children": {
+					Type:  "array",
+					Items: &extv1.JSONSchemaPropsOrArray{Schema: &children},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		opts    Options
+		want    *extv1.JSONSchemaProps
+		wantErr bool
+	}{
+		{
+			name: "Self referencing type",
+			obj: map[string]interface{}{
+				"root": "TreeNode",
+			},
+			opts: Options{
+				Types: map[string]interface{}{
+					"TreeNode": map[string]interface{}{
+						"value":    "string",
+						"children": "[]TreeNode",
+					},
+				},
+				MaxRecursionDepth: 1,
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"root": treeNode(treeNode(depthExceeded("TreeNode", 1))),
+				},
+			},
+		},
+		{
+			name: "Mutually recursive types",
+			obj: map[string]interface{}{
+				"dir": "Directory",
+			},
+			opts: Options{
+				Types: map[string]interface{}{
+					"Directory": map[string]interface{}{
+						"entries": "map[string]Entry",
+					},
+					"Entry": map[string]interface{}{
+						"subdir": "Directory",
+					},
+				},
+				MaxRecursionDepth: 1,
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"dir": {
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"entries": {
+								Type: "object",
+								AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Schema: &extv1.JSONSchemaProps{
+									Type: "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"subdir": {
+											Type: "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"entries": {
+													Type: "object",
+													AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Schema: &extv1.JSONSchemaProps{
+														Type: "object",
+														Properties: map[string]extv1.JSONSchemaProps{
+															"subdir": depthExceeded("Directory", 1),
+														},
+													}},
+												},
+											},
+										},
+									},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Negative recursion depth",
+			obj: map[string]interface{}{
+				"name": "string",
+			},
+			opts:    Options{MaxRecursionDepth: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToOpenAPISpecWithOptions(tt.obj, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToOpenAPISpecWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToOpenAPISpecWithOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecursiveTypesDefaultDepth(t *testing.T) {
+	got, err := ToOpenAPISpecWithTypes(map[string]interface{}{
+		"root": "TreeNode",
+	}, map[string]interface{}{
+		"TreeNode": map[string]interface{}{
+			"children": "[]TreeNode",
+		},
+	})
+	if err != nil {
+		t.Fatalf("ToOpenAPISpecWithTypes() error = %v", err)
+	}
+
+	depth := 0
+	node := got.Properties["root"]
+	for node.Properties != nil {
+		node = *node.Properties["children"].Items.Schema
+		depth++
+	}
+	if depth != DefaultMaxRecursionDepth+1 {
+		t.Errorf("expected the type to be expanded %d times, got %d", DefaultMaxRecursionDepth+1, depth)
+	}
+	if len(node.XValidations) != 1 || node.XValidations[0].Rule != "false" {
+		t.Errorf("expected the last level to reject values, got %+v", node)
+	}
+}
+
+func TestCRDRefTypes(t *testing.T) {
+	deploymentGVK := k8sschema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	templateSchema := extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"spec": {
+				Type:     "object",
+				Required: []string{"containers"},
+				Properties: map[string]extv1.JSONSchemaProps{
+					"containers": {Type: "array", Items: &extv1.JSONSchemaPropsOrArray{
+						Schema: &extv1.JSONSchemaProps{Type: "object"},
+					}},
+				},
+			},
+		},
+	}
+	resolver := func(gvk k8sschema.GroupVersionKind) (*extv1.JSONSchemaProps, error) {
+		if gvk != deploymentGVK {
+			return nil, fmt.Errorf("unknown kind %s", gvk)
+		}
+		return &extv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]extv1.JSONSchemaProps{
+				"spec": {
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"template": templateSchema,
+					},
+				},
+			},
+		}, nil
+	}
+
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		want    *extv1.JSONSchemaProps
+		wantErr bool
+	}{
+		{
+			name: "imported field with markers",
+			obj: map[string]interface{}{
+				"podTemplate": "crd(apps/v1, Deployment, .spec.template) | required=true",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type:       "object",
+				Required:   []string{"podTemplate"},
+				Properties: map[string]extv1.JSONSchemaProps{"podTemplate": templateSchema},
+			},
+		},
+		{
+			name: "array of imported fields",
+			obj: map[string]interface{}{
+				"templates": "[]crd(apps/v1, Deployment, .spec.template)",
+			},
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"templates": {Type: "array", Items: &extv1.JSONSchemaPropsOrArray{Schema: &templateSchema}},
+				},
+			},
+		},
+		{
+			name:    "unknown field",
+			obj:     map[string]interface{}{"podTemplate": "crd(apps/v1, Deployment, .spec.selector)"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			obj:     map[string]interface{}{"pvc": "crd(v1, PersistentVolumeClaim)"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToOpenAPISpecWithOptions(tt.obj, Options{SchemaResolver: resolver})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToOpenAPISpecWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToOpenAPISpecWithOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Imported types can't be resolved without a resolver.
+	if _, err := ToOpenAPISpec(map[string]interface{}{"podTemplate": "crd(apps/v1, Deployment)"}); err == nil {
+		t.Error("expected an error without a schema resolver")
+	}
+}
+
+func TestPrinterColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		types   map[string]interface{}
+		want    []extv1.CustomResourceColumnDefinition
+		wantErr bool
+	}{
+		{
+			name: "scalar fields",
+			obj: map[string]interface{}{
+				"replicas": "integer | printColumn=true description=\"Number of replicas\"",
+				"image":    "string | printColumn=true printColumnName=Image-Tag printColumnPriority=1",
+				"expires":  "datetime | printColumn=true",
+				"port":     "intorstring | printColumn=true",
+				"name":     "string",
+			},
+			want: []extv1.CustomResourceColumnDefinition{
+				{Name: "Expires", Type: "date", JSONPath: ".expires"},
+				{Name: "Image-Tag", Type: "string", Priority: 1, JSONPath: ".image"},
+				{Name: "Port", Type: "string", JSONPath: ".port"},
+				{Name: "Replicas", Type: "integer", Description: "Number of replicas", JSONPath: ".replicas"},
+			},
+		},
+		{
+			name: "nested and custom type fields",
+			obj: map[string]interface{}{
+				"database": map[string]interface{}{
+					"engine": "string | printColumn=true",
+				},
+				"server": "Server",
+			},
+			types: map[string]interface{}{
+				"Server": map[string]interface{}{
+					"host": "string | printColumn=true printColumnName=Host",
+				},
+			},
+			want: []extv1.CustomResourceColumnDefinition{
+				{Name: "Engine", Type: "string", JSONPath: ".database.engine"},
+				{Name: "Host", Type: "string", JSONPath: ".server.host"},
+			},
+		},
+		{
+			name: "unused custom type",
+			obj:  map[string]interface{}{"name": "string"},
+			types: map[string]interface{}{
+				"Server": map[string]interface{}{
+					"host": "string | printColumn=true",
+				},
+			},
+		},
+		{
+			name:    "field in an array",
+			obj:     map[string]interface{}{"servers": "[]Server"},
+			types:   map[string]interface{}{"Server": map[string]interface{}{"host": "string | printColumn=true"}},
+			wantErr: true,
+		},
+		{
+			name:    "object field",
+			obj:     map[string]interface{}{"labels": "map[string]string | printColumn=true"},
+			wantErr: true,
+		},
+		{
+			name:    "sensitive field",
+			obj:     map[string]interface{}{"password": "string | sensitive=true printColumn=true"},
+			wantErr: true,
+		},
+		{
+			name:    "column name without printColumn",
+			obj:     map[string]interface{}{"name": "string | printColumnName=Name"},
+			wantErr: true,
+		},
+		{
+			name:    "negative priority",
+			obj:     map[string]interface{}{"name": "string | printColumn=true printColumnPriority=-1"},
+			wantErr: true,
+		},
+		{
+			name: "duplicate column name",
+			obj: map[string]interface{}{
+				"a": "string | printColumn=true printColumnName=Name",
+				"b": "string | printColumn=true printColumnName=Name",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.obj, Options{Types: tt.types})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.PrinterColumns, tt.want) {
+				t.Errorf("Convert() printer columns = %+v, want %+v", got.PrinterColumns, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpressionDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		types   map[string]interface{}
+		want    []ExpressionDefault
+		wantErr bool
+	}{
+		{
+			name: "scalar and nested fields",
+			obj: map[string]interface{}{
+				"name":     "string | default=${schema.metadata.name + '-db'}",
+				"replicas": "integer | default=1",
+				"storage": map[string]interface{}{
+					"size": "string | default=${values.defaultStorageSize}",
+				},
+			},
+			want: []ExpressionDefault{
+				{Path: []string{"name"}, Expression: "${schema.metadata.name + '-db'}"},
+				{Path: []string{"storage", "size"}, Expression: "${values.defaultStorageSize}"},
+			},
+		},
+		{
+			name: "custom type field",
+			obj:  map[string]interface{}{"server": "Server"},
+			types: map[string]interface{}{
+				"Server": map[string]interface{}{
+					"host": "string | default=${schema.metadata.name}",
+				},
+			},
+			want: []ExpressionDefault{
+				{Path: []string{"server", "host"}, Expression: "${schema.metadata.name}"},
+			},
+		},
+		{
+			name:    "field in an array",
+			obj:     map[string]interface{}{"servers": "[]Server"},
+			types:   map[string]interface{}{"Server": map[string]interface{}{"host": "string | default=${schema.metadata.name}"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.obj, Options{Types: tt.types})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.ExpressionDefaults, tt.want) {
+				t.Errorf("Convert() expression defaults = %+v, want %+v", got.ExpressionDefaults, tt.want)
+			}
+			for _, def := range tt.want {
+				field := got.Schema
+				for _, segment := range def.Path {
+					property := field.Properties[segment]
+					field = &property
+				}
+				if field.Default != nil {
+					t.Errorf("Convert() set the schema default of %v to %s", def.Path, field.Default.Raw)
+				}
+			}
+		})
+	}
+}
+
+func TestListTypes(t *testing.T) {
+	types := map[string]interface{}{
+		"Port": map[string]interface{}{
+			"name":     "string | required=true",
+			"protocol": "string | default=TCP",
+			"port":     "integer",
+			"labels":   "map[string]string",
+		},
+	}
+
+	tests := []struct {
+		name         string
+		field        string
+		wantListType string
+		wantMapKeys  []string
+		wantErr      bool
+	}{
+		{
+			name:         "map",
+			field:        "[]Port | listType=map listMapKeys=name,protocol",
+			wantListType: "map",
+			wantMapKeys:  []string{"name", "protocol"},
+		},
+		{name: "set", field: "[]string | listType=set", wantListType: "set"},
+		{name: "atomic", field: "[]Port | listType=atomic", wantListType: "atomic"},
+		{name: "set with uniqueItems", field: "[]string | uniqueItems=true listType=set", wantListType: "set"},
+		{name: "atomic with uniqueItems", field: "[]string | uniqueItems=true listType=atomic", wantErr: true},
+		{name: "set of objects", field: "[]Port | listType=set", wantErr: true},
+		{name: "map of scalars", field: "[]string | listType=map listMapKeys=name", wantErr: true},
+		{name: "map without keys", field: "[]Port | listType=map", wantErr: true},
+		{name: "keys without map", field: "[]Port | listMapKeys=name", wantErr: true},
+		{name: "keys with atomic", field: "[]Port | listType=atomic listMapKeys=name", wantErr: true},
+		{name: "unknown key", field: "[]Port | listType=map listMapKeys=id", wantErr: true},
+		{name: "optional key", field: "[]Port | listType=map listMapKeys=port", wantErr: true},
+		{name: "non scalar key", field: "[]Port | listType=map listMapKeys=labels", wantErr: true},
+		{name: "duplicate key", field: "[]Port | listType=map listMapKeys=name,name", wantErr: true},
+		{name: "unknown list type", field: "[]Port | listType=granular", wantErr: true},
+		{name: "non array", field: "string | listType=atomic", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToOpenAPISpecWithTypes(map[string]interface{}{"values": tt.field}, types)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToOpenAPISpecWithTypes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			values := got.Properties["values"]
+			if values.XListType == nil || *values.XListType != tt.wantListType {
+				t.Errorf("expected list type %s, got %v", tt.wantListType, values.XListType)
+			}
+			if !reflect.DeepEqual(values.XListMapKeys, tt.wantMapKeys) {
+				t.Errorf("expected list map keys %v, got %v", tt.wantMapKeys, values.XListMapKeys)
+			}
+		})
+	}
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		field           string
+		wantDescription string
+		wantErr         bool
+	}{
+		{name: "without message", field: "string | deprecated=true", wantDescription: "Deprecated."},
+		{
+			name:            "with message",
+			field:           `string | deprecated=true deprecationMessage="use replicas instead"`,
+			wantDescription: "Deprecated: use replicas instead",
+		},
+		{
+			name:            "with description",
+			field:           `string | description="Size of the app" deprecated=true`,
+			wantDescription: "Deprecated.\n\nSize of the app",
+		},
+		{name: "not deprecated", field: "string | deprecated=false description=Size", wantDescription: "Size"},
+		{name: "message without deprecated", field: `string | deprecationMessage="use replicas"`, wantErr: true},
+		{name: "invalid value", field: "string | deprecated=yes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToOpenAPISpec(map[string]interface{}{"size": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToOpenAPISpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if description := got.Properties["size"].Description; description != tt.wantDescription {
+				t.Errorf("expected description %q, got %q", tt.wantDescription, description)
+			}
+		})
+	}
+}
+
+func TestNumericConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		want    *extv1.JSONSchemaProps
+		wantErr bool
+	}{
+		{
+			name:  "exclusive bounds",
+			field: "integer | exclusiveMinimum=0 exclusiveMaximum=10",
+			want: &extv1.JSONSchemaProps{
+				Type:             "integer",
+				Minimum:          float64Ptr(0),
+				ExclusiveMinimum: true,
+				Maximum:          float64Ptr(10),
+				ExclusiveMaximum: true,
+			},
+		},
+		{
+			name:  "multipleOf",
+			field: "float | minimum=0 multipleOf=0.5",
+			want:  &extv1.JSONSchemaProps{Type: "float", Minimum: float64Ptr(0), MultipleOf: float64Ptr(0.5)},
+		},
+		{
+			name:  "array items",
+			field: "[]integer | exclusiveMinimum=0 multipleOf=2",
+			want: &extv1.JSONSchemaProps{
+				Type: "array",
+				Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{
+					Type:             "integer",
+					Minimum:          float64Ptr(0),
+					ExclusiveMinimum: true,
+					MultipleOf:       float64Ptr(2),
+				}},
+			},
+		},
+		{
+			name:  "map values",
+			field: "map[string]float | maximum=1 exclusiveMinimum=0",
+			want: &extv1.JSONSchemaProps{
+				Type: "object",
+				AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Schema: &extv1.JSONSchemaProps{
+					Type:             "float",
+					Minimum:          float64Ptr(0),
+					ExclusiveMinimum: true,
+					Maximum:          float64Ptr(1),
+				}},
+			},
+		},
+		{name: "minimum and exclusiveMinimum", field: "integer | minimum=0 exclusiveMinimum=0", wantErr: true},
+		{name: "exclusiveMaximum and maximum", field: "integer | exclusiveMaximum=1 maximum=1", wantErr: true},
+		{name: "empty exclusive range", field: "integer | minimum=1 exclusiveMaximum=1", wantErr: true},
+		{name: "zero multipleOf", field: "integer | multipleOf=0", wantErr: true},
+		{name: "string", field: "string | multipleOf=2", wantErr: true},
+		{name: "array of strings", field: "[]string | exclusiveMinimum=2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToOpenAPISpec(map[string]interface{}{"value": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToOpenAPISpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if value := got.Properties["value"]; !reflect.DeepEqual(&value, tt.want) {
+				t.Errorf("ToOpenAPISpec() = %+v, want %+v", value, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		want    extv1.ValidationRules
+		wantErr bool
+	}{
+		{
+			name: "exactly one of",
+			obj: map[string]interface{}{
+				"vpcConfig":   "VPCConfig | exactlyOneOf=vpc",
+				"existingVPC": "string | exactlyOneOf=vpc",
+			},
+			want: extv1.ValidationRules{{
+				Rule:    "[has(self.existingVPC), has(self.vpcConfig)].exists_one(x, x)",
+				Message: "exactly one of existingVPC, vpcConfig must be set",
+			}},
+		},
+		{
+			name: "at most one and at least one of",
+			obj: map[string]interface{}{
+				"cpu":    "integer | atMostOneOf=size atLeastOneOf=limits",
+				"preset": "string | atMostOneOf=size",
+				"memory": "integer | atLeastOneOf=limits",
+			},
+			want: extv1.ValidationRules{
+				{
+					Rule:    "[has(self.cpu), has(self.memory)].exists(x, x)",
+					Message: "at least one of cpu, memory must be set",
+				},
+				{
+					Rule:    "[has(self.cpu), has(self.preset)].filter(x, x).size() <= 1",
+					Message: "at most one of cpu, preset can be set",
+				},
+			},
+		},
+		{
+			name:    "single field",
+			obj:     map[string]interface{}{"existingVPC": "string | exactlyOneOf=vpc"},
+			wantErr: true,
+		},
+		{
+			name: "required field",
+			obj: map[string]interface{}{
+				"a": "string | exactlyOneOf=g required=true",
+				"b": "string | exactlyOneOf=g",
+			},
+			wantErr: true,
+		},
+		{
+			name: "field with a default value",
+			obj: map[string]interface{}{
+				"a": "string | atLeastOneOf=g default=foo",
+				"b": "string | atLeastOneOf=g",
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty group name",
+			obj: map[string]interface{}{
+				"a": "string | atMostOneOf=\"\"",
+				"b": "string | atMostOneOf=g",
+			},
+			wantErr: true,
+		},
+	}
+
+	types := map[string]interface{}{
+		"VPCConfig": map[string]interface{}{"cidr": "string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToOpenAPISpecWithTypes(tt.obj, types)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToOpenAPISpecWithTypes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.XValidations, tt.want) {
+				t.Errorf("expected validations %v, got %v", tt.want, got.XValidations)
+			}
+		})
+	}
+}
+
+func TestFieldGroupsNestedObject(t *testing.T) {
+	got, err := ToOpenAPISpec(map[string]interface{}{
+		"name": "string | exactlyOneOf=g",
+		"network": map[string]interface{}{
+			"name":   "string | exactlyOneOf=g",
+			"subnet": "string | exactlyOneOf=g",
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected an error for a group with a single field, got %v", got)
+	}
+
+	got, err = ToOpenAPISpec(map[string]interface{}{
+		"network": map[string]interface{}{
+			"name":   "string | exactlyOneOf=g",
+			"subnet": "string | exactlyOneOf=g",
+		},
+	})
+	if err != nil {
+		t.Fatalf("ToOpenAPISpec() error = %v", err)
+	}
+	if len(got.XValidations) != 0 {
+		t.Errorf("expected no validations on the parent object, got %v", got.XValidations)
+	}
+	if rules := got.Properties["network"].XValidations; len(rules) != 1 {
+		t.Errorf("expected one validation on the nested object, got %v", rules)
 	}
 }
@@ -0,0 +1,278 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package testing provides harnesses for ResourceGraphDefinition authors to
+// write Go tests for their own graphs.
+//
+// The Simulator reconciles an instance without a cluster, against an
+// in-memory one, for fast unit tests of the rendered resources and of the
+// status transitions of the instance.
+//
+// The Environment is an envtest based harness that runs kro's controllers
+// against a real API server, for integration tests. A typical test looks like:
+//
+//	env, err := krotesting.NewEnvironment(krotesting.Options{})
+//	...
+//	defer env.Stop()
+//
+//	rgd, err := krotesting.LoadResourceGraphDefinition("testdata/webapp.yaml")
+//	...
+//	err = env.InstallResourceGraphDefinition(ctx, rgd)
+//	...
+//	err = env.CreateInstance(ctx, instance)
+//	...
+//	_, err = env.WaitForInstanceCondition(ctx, instance, "InstanceSynced", metav1.ConditionTrue)
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	goruntime "runtime"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+	kroclient "github.com/kro-run/kro/pkg/client"
+	ctrlresourcegraphdefinition "github.com/kro-run/kro/pkg/controller/resourcegraphdefinition"
+	"github.com/kro-run/kro/pkg/dynamiccontroller"
+	"github.com/kro-run/kro/pkg/graph"
+	kroruntime "github.com/kro-run/kro/pkg/runtime"
+)
+
+const (
+	// DefaultPollInterval is the default interval used by the Wait* helpers.
+	DefaultPollInterval = 250 * time.Millisecond
+	// DefaultTimeout is the default timeout used by the Wait* helpers.
+	DefaultTimeout = 30 * time.Second
+)
+
+// Options configures the test environment.
+type Options struct {
+	// CRDDirectoryPaths is a list of additional directories containing CRDs
+	// to install in the test API server, e.g the CRDs of the controllers the
+	// RGD under test is composing. kro's own CRDs are always installed.
+	CRDDirectoryPaths []string
+	// AllowCRDDeletion allows the ResourceGraphDefinition controller to delete
+	// the generated CRDs when an RGD is deleted.
+	AllowCRDDeletion bool
+	// DynamicControllerConfig is the configuration of the dynamic controller.
+	// If left empty, sensible defaults for tests are used.
+	DynamicControllerConfig dynamiccontroller.Config
+	// PollInterval is the interval used by the Wait* helpers. Defaults to
+	// DefaultPollInterval.
+	PollInterval time.Duration
+	// Timeout is the timeout used by the Wait* helpers. Defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+	// Logger is the logger used by the controllers. Defaults to a logger
+	// discarding everything.
+	Logger *logr.Logger
+}
+
+// Environment is a running envtest API server with kro's controllers
+// registered and started.
+type Environment struct {
+	context context.Context
+	cancel  context.CancelFunc
+
+	options Options
+
+	// Client is a controller-runtime client configured with kro's scheme.
+	Client client.Client
+	// ClientSet is the kro client set pointing to the test API server.
+	ClientSet *kroclient.Set
+	// TestEnv is the underlying envtest environment.
+	TestEnv *envtest.Environment
+	// CtrlManager is the manager running the ResourceGraphDefinition controller.
+	CtrlManager ctrl.Manager
+	// DynamicController is the dynamic controller serving the instance APIs.
+	DynamicController *dynamiccontroller.DynamicController
+	// GraphBuilder is the graph builder used by the controllers.
+	GraphBuilder *graph.Builder
+}
+
+// NewEnvironment starts a new envtest API server, installs kro's CRDs and the
+// CRDs found in opts.CRDDirectoryPaths, and starts kro's controllers.
+func NewEnvironment(opts Options) (_ *Environment, err error) {
+	opts = withDefaults(opts)
+	env := &Environment{
+		options: opts,
+	}
+	env.context, env.cancel = context.WithCancel(context.Background())
+
+	crdPaths := append([]string{kroCRDDirectory()}, opts.CRDDirectoryPaths...)
+	env.TestEnv = &envtest.Environment{
+		CRDDirectoryPaths:       crdPaths,
+		ErrorIfCRDPathMissing:   true,
+		ControlPlaneStopTimeout: 2 * time.Minute,
+	}
+
+	cfg, err := env.TestEnv.Start()
+	if err != nil {
+		return nil, fmt.Errorf("starting test environment: %w", err)
+	}
+	// Don't leak the API server and etcd if anything else fails.
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, env.Stop())
+		}
+	}()
+
+	env.ClientSet, err = kroclient.NewSet(kroclient.Config{
+		RestConfig: cfg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating client set: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(krov1alpha1.AddToScheme(scheme))
+
+	env.Client, err = client.New(env.ClientSet.RESTConfig(), client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("creating client: %w", err)
+	}
+
+	env.GraphBuilder, err = graph.NewBuilder(env.ClientSet.RESTConfig(), kroruntime.Cluster{Domain: "cluster.local"})
+	if err != nil {
+		return nil, fmt.Errorf("creating graph builder: %w", err)
+	}
+
+	if err = env.startControllers(scheme); err != nil {
+		return nil, fmt.Errorf("starting controllers: %w", err)
+	}
+	return env, nil
+}
+
+func (e *Environment) startControllers(scheme *runtime.Scheme) error {
+	log := *e.options.Logger
+
+	e.DynamicController = dynamiccontroller.NewDynamicController(
+		log,
+		e.options.DynamicControllerConfig,
+		e.ClientSet.Dynamic(),
+	)
+	go func() {
+		if err := e.DynamicController.Run(e.context); err != nil {
+			log.Error(err, "dynamic controller failed to run")
+		}
+	}()
+
+	var err error
+	e.CtrlManager, err = ctrl.NewManager(e.ClientSet.RESTConfig(), ctrl.Options{
+		Scheme: scheme,
+		Metrics: server.Options{
+			// Disable the metrics server
+			BindAddress: "0",
+		},
+		Logger: log,
+	})
+	if err != nil {
+		return fmt.Errorf("creating manager: %w", err)
+	}
+
+	rgdReconciler := ctrlresourcegraphdefinition.NewResourceGraphDefinitionReconciler(
+		e.ClientSet,
+		e.options.AllowCRDDeletion,
+		e.DynamicController,
+		e.GraphBuilder,
+		ctrlresourcegraphdefinition.ReconcilerConfig{
+			MaxConcurrentReconciles:         1,
+			MaxConcurrentResourceReconciles: 4,
+			FieldManager:                    "kro",
+		},
+	)
+	if err := rgdReconciler.SetupWithManager(e.CtrlManager); err != nil {
+		return fmt.Errorf("setting up reconciler: %w", err)
+	}
+
+	go func() {
+		if err := e.CtrlManager.Start(e.context); err != nil {
+			log.Error(err, "manager failed to start")
+		}
+	}()
+	return nil
+}
+
+// Stop stops the controllers and the test API server.
+func (e *Environment) Stop() error {
+	e.cancel()
+
+	// Stopping the control plane can fail on the first attempts while the
+	// controllers are still shutting down, so we retry with an exponential
+	// backoff (up to ~4s).
+	// See https://github.com/kubernetes-sigs/controller-runtime/issues/1571
+	var err error
+	sleepTime := 1 * time.Millisecond
+	for i := 0; i < 12; i++ {
+		if err = e.TestEnv.Stop(); err == nil {
+			return nil
+		}
+		sleepTime *= 2
+		time.Sleep(sleepTime)
+	}
+	return err
+}
+
+// Context returns the context the controllers are running with. It is
+// cancelled when Stop is called.
+func (e *Environment) Context() context.Context {
+	return e.context
+}
+
+func withDefaults(opts Options) Options {
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Logger == nil {
+		logger := zap.New(zap.WriteTo(io.Discard), zap.UseDevMode(true))
+		opts.Logger = &logger
+	}
+	if opts.DynamicControllerConfig == (dynamiccontroller.Config{}) {
+		opts.DynamicControllerConfig = dynamiccontroller.Config{
+			Workers:         3,
+			ResyncPeriod:    60 * time.Second,
+			QueueMaxRetries: 20,
+			ShutdownTimeout: 60 * time.Second,
+			MinRetryDelay:   200 * time.Millisecond,
+			MaxRetryDelay:   1000 * time.Second,
+			RateLimit:       10,
+			BurstLimit:      100,
+		}
+	}
+	return opts
+}
+
+// kroCRDDirectory returns the directory containing kro's CRDs. The path is
+// resolved relative to this source file, which works both from within the kro
+// repository and from the Go module cache of downstream users.
+func kroCRDDirectory() string {
+	_, file, _, _ := goruntime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "config", "crd", "bases")
+}
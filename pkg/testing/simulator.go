@@ -0,0 +1,473 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testing
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/graph/crd"
+	"github.com/kro-run/kro/pkg/graph/schema"
+	kroruntime "github.com/kro-run/kro/pkg/runtime"
+)
+
+// DefaultKubernetesVersion is the version of the cluster the expressions see
+// in a Simulator.
+const DefaultKubernetesVersion = "v1.31.0"
+
+// The states of the instances and of their resources reported by a
+// Simulator, as kro reports them.
+const (
+	InstanceStateInProgress = "IN_PROGRESS"
+	InstanceStateActive     = "ACTIVE"
+	InstanceStateError      = "ERROR"
+
+	ResourceStatePending             = "PENDING"
+	ResourceStateInProgress          = "IN_PROGRESS"
+	ResourceStateSkipped             = "SKIPPED"
+	ResourceStateCreated             = "CREATED"
+	ResourceStateSynced              = "SYNCED"
+	ResourceStateWaitingForReadiness = "WAITING_FOR_READINESS"
+	ResourceStateWaitingForHooks     = "WAITING_FOR_HOOKS"
+	ResourceStateWaitingForExternal  = "WAITING_FOR_EXTERNAL_REF"
+)
+
+// SimulatorOptions configures a Simulator.
+type SimulatorOptions struct {
+	// CRDs are the CustomResourceDefinitions of the custom kinds of the
+	// resources, which type check their templates and the expressions
+	// reading them. The schemas of the built-in kinds are known.
+	CRDs []*extv1.CustomResourceDefinition
+	// TypeLibraries are the ResourceGraphTypeLibraries providing the shared
+	// types of the schema.
+	TypeLibraries []krov1alpha1.ResourceGraphTypeLibrary
+	// KubernetesVersion is the version of the cluster the expressions see.
+	// Defaults to DefaultKubernetesVersion.
+	KubernetesVersion string
+	// Values are the ConfigMaps and Secrets the valuesFrom of the
+	// ResourceGraphDefinition are looked up among.
+	Values []*unstructured.Unstructured
+	// MaxSteps is the number of steps after which Run gives up. Defaults to
+	// 100.
+	MaxSteps int
+}
+
+// ResourceStatus is the status of a resource of the instance after a step.
+type ResourceStatus struct {
+	// State is the state of the resource, e.g SYNCED or
+	// WAITING_FOR_READINESS.
+	State string
+	// Ready reports whether the readiness conditions of the resource are
+	// met.
+	Ready bool
+	// Message explains the state of the resource, if it failed or is waiting.
+	Message string
+}
+
+// Simulator reconciles an instance of a ResourceGraphDefinition the way kro
+// does, without a cluster, so that the authors of ResourceGraphDefinitions
+// can unit test them.
+//
+// Each step reconciles the instance once: the resources are rendered in
+// topological order and applied to an in-memory cluster, and the instance
+// status is evaluated. As in a cluster, a resource is only applied once its
+// dependencies are ready, and it takes a step for a created resource to be
+// observed. The fields set by the controllers of the resources, such as their
+// status, are set by the test with SetResourceStatus, and the external
+// references are provided with SetResource.
+//
+// A typical test looks like:
+//
+//	sim, err := krotesting.NewSimulator(rgd, instance, krotesting.SimulatorOptions{})
+//	...
+//	err = sim.Run()
+//	...
+//	err = sim.SetResourceStatus("deployment", map[string]interface{}{"availableReplicas": int64(3)})
+//	...
+//	err = sim.Run()
+//	...
+//	assert.Equal(t, krotesting.InstanceStateActive, sim.InstanceState())
+type Simulator struct {
+	graph    *graph.Graph
+	instance *unstructured.Unstructured
+	maxSteps int
+	// values are the ConfigMaps and Secrets the values of the instance are
+	// loaded from, at each step.
+	values []*unstructured.Unstructured
+	// objects are the objects of the resources in the in-memory cluster, by
+	// id.
+	objects map[string]*unstructured.Unstructured
+	// resources are the statuses of the resources after the last step.
+	resources map[string]ResourceStatus
+	// order is the topological order of the resources in the last step.
+	order []string
+}
+
+// NewSimulator builds the given ResourceGraphDefinition, as kro does once it's
+// applied, and returns a Simulator of the given instance. The instance must
+// have its apiVersion and kind set; its namespace defaults to "default".
+func NewSimulator(
+	rgd *krov1alpha1.ResourceGraphDefinition,
+	instance *unstructured.Unstructured,
+	opts SimulatorOptions,
+) (*Simulator, error) {
+	if opts.KubernetesVersion == "" {
+		opts.KubernetesVersion = DefaultKubernetesVersion
+	}
+	if opts.MaxSteps == 0 {
+		opts.MaxSteps = 100
+	}
+	sharedTypes, err := graph.MergeTypeLibraries(opts.TypeLibraries)
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := schema.NewOfflineResolver(opts.CRDs)
+	if err != nil {
+		return nil, err
+	}
+	rgdGraph, err := graph.NewOfflineBuilder(resolver, opts.KubernetesVersion).
+		NewResourceGraphDefinition(rgd, graph.WithSharedTypes(sharedTypes))
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource graph definition %s: %w", rgd.Name, err)
+	}
+
+	s := &Simulator{
+		graph:     rgdGraph,
+		maxSteps:  opts.MaxSteps,
+		values:    opts.Values,
+		objects:   make(map[string]*unstructured.Unstructured),
+		resources: make(map[string]ResourceStatus),
+	}
+	instance = instance.DeepCopy()
+	if instance.GetNamespace() == "" {
+		instance.SetNamespace("default")
+	}
+	instance.SetGeneration(1)
+	if err := s.setInstance(instance); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// setInstance applies the defaults of the instance schema to the instance, as
+// the API server does, and keeps its status.
+func (s *Simulator) setInstance(instance *unstructured.Unstructured) error {
+	gvk := instance.GroupVersionKind()
+	if err := crd.ApplyDefaults(instance, s.graph.Instance.GetCRD(), gvk.Version); err != nil {
+		return err
+	}
+	if s.instance != nil {
+		if status, ok := s.instance.Object["status"]; ok {
+			instance.Object["status"] = status
+		}
+	}
+	s.instance = instance
+	return nil
+}
+
+// UpdateInstance replaces the spec, labels and annotations of the instance, as
+// a user updating it would. The new generation is reconciled by the next
+// step.
+func (s *Simulator) UpdateInstance(instance *unstructured.Unstructured) error {
+	updated := instance.DeepCopy()
+	updated.SetNamespace(s.instance.GetNamespace())
+	updated.SetName(s.instance.GetName())
+	updated.SetGeneration(s.instance.GetGeneration() + 1)
+	delete(updated.Object, "status")
+	return s.setInstance(updated)
+}
+
+// Step reconciles the instance once. It returns the error that failed the
+// reconciliation, if any, which is also reported in the instance status. The
+// resources waiting for their dependencies, their readiness or their hooks
+// don't fail the reconciliation.
+func (s *Simulator) Step() error {
+	s.resources = make(map[string]ResourceStatus)
+	values, err := s.graph.ResolveValues(s.instance, s.values)
+	if err != nil {
+		return s.finishStep(nil, err, "")
+	}
+	// Like kro, the expression defaults are written to the instance.
+	if _, err := s.graph.ApplyDefaults(s.instance, graph.WithValues(values)); err != nil {
+		return s.finishStep(nil, err, "")
+	}
+	rt, err := s.graph.NewGraphRuntime(s.instance.DeepCopy(), graph.WithValues(values))
+	if err != nil {
+		return s.finishStep(rt, err, "")
+	}
+	s.order = rt.TopologicalOrder()
+	for _, id := range s.order {
+		s.resources[id] = ResourceStatus{State: ResourceStatePending}
+	}
+
+	if ok, reason, err := rt.CheckHooks("", kroruntime.HookPhasePreApply); err != nil || !ok {
+		if err != nil {
+			return s.finishStep(rt, err, "")
+		}
+		return s.finishStep(rt, nil, "waiting for preApply hooks of the instance: "+reason)
+	}
+
+	// Resources are reconciled once their dependencies are, as kro does.
+	reconciled := make(map[string]bool, len(s.order))
+	var failures []error
+	waiting := ""
+	for _, id := range s.order {
+		blocked := false
+		for _, dependency := range rt.ResourceDescriptor(id).GetDependencies() {
+			if _, known := s.resources[dependency]; known && !reconciled[dependency] {
+				blocked = true
+			}
+		}
+		if blocked {
+			continue
+		}
+
+		done, err := s.reconcileResource(rt, id)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("resource %s: %w", id, err))
+			continue
+		}
+		if !done {
+			waiting = "waiting for the resources to be ready"
+			continue
+		}
+		if _, err := rt.Synchronize(); err != nil {
+			failures = append(failures, fmt.Errorf("failed to synchronize reconciling resource %s: %w", id, err))
+			continue
+		}
+		reconciled[id] = true
+	}
+	return s.finishStep(rt, errors.Join(failures...), waiting)
+}
+
+// reconcileResource reconciles the given resource, and returns true once the
+// resources depending on it can be reconciled.
+func (s *Simulator) reconcileResource(rt *kroruntime.ResourceGraphDefinitionRuntime, id string) (bool, error) {
+	status := ResourceStatus{State: ResourceStateInProgress}
+	defer func() { s.resources[id] = status }()
+
+	if want, err := rt.WantToCreateResource(id); err != nil || !want {
+		status.State = ResourceStateSkipped
+		rt.IgnoreResource(id, err)
+		return true, nil
+	}
+
+	desired, state := rt.GetResource(id)
+	if state != kroruntime.ResourceStateResolved {
+		status.Message = fmt.Sprintf("resource %s not resolved: state=%v", id, state)
+		return false, nil
+	}
+
+	observed, exists := s.objects[id]
+	if rt.ResourceDescriptor(id).IsExternalRef() {
+		if !exists {
+			status.State = ResourceStateWaitingForExternal
+			status.Message = "external reference not found, it's provided with SetResource"
+			return false, nil
+		}
+		rt.SetResource(id, observed.DeepCopy())
+		return s.checkReadiness(rt, id, &status)
+	}
+
+	if ok, reason, err := rt.CheckHooks(id, kroruntime.HookPhasePreApply); err != nil || !ok {
+		status.State = ResourceStateWaitingForHooks
+		status.Message = fmt.Sprintf("preApply hooks not satisfied: %s: %v", reason, err)
+		return false, nil
+	}
+
+	// Created resources are observed by the next step.
+	if !exists {
+		s.objects[id] = desired.DeepCopy()
+		status.State = ResourceStateCreated
+		return false, nil
+	}
+
+	// The fields the cluster sets on the resource are kept when it's updated.
+	applied := desired.DeepCopy()
+	if observedStatus, ok := observed.Object["status"]; ok {
+		applied.Object["status"] = observedStatus
+	}
+	s.objects[id] = applied
+	rt.SetResource(id, applied.DeepCopy())
+	if ready, err := s.checkReadiness(rt, id, &status); err != nil || !ready {
+		return ready, err
+	}
+
+	if ok, reason, err := rt.CheckHooks(id, kroruntime.HookPhasePostApply); err != nil || !ok {
+		status.State = ResourceStateWaitingForHooks
+		status.Message = fmt.Sprintf("postApply hooks not satisfied: %s: %v", reason, err)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *Simulator) checkReadiness(
+	rt *kroruntime.ResourceGraphDefinitionRuntime,
+	id string,
+	status *ResourceStatus,
+) (bool, error) {
+	ready, reason, err := rt.IsResourceReady(id)
+	if err != nil || !ready {
+		status.State = ResourceStateWaitingForReadiness
+		status.Message = fmt.Sprintf("resource not ready: %s: %v", reason, err)
+		return false, nil
+	}
+	status.State = ResourceStateSynced
+	status.Ready = true
+	return true, nil
+}
+
+// finishStep sets the status of the instance at the end of a step, given the
+// error that failed the reconciliation, if any, or why it's waiting, and
+// returns the error.
+func (s *Simulator) finishStep(rt *kroruntime.ResourceGraphDefinitionRuntime, failure error, waiting string) error {
+	status := map[string]interface{}{}
+	if rt != nil {
+		if evaluated, ok := rt.GetInstance().Object["status"].(map[string]interface{}); ok {
+			maps.Copy(status, evaluated)
+		}
+	}
+
+	condition := func(conditionType, conditionStatus, reason, message string) interface{} {
+		return map[string]interface{}{
+			"type":               conditionType,
+			"status":             conditionStatus,
+			"reason":             reason,
+			"message":            message,
+			"lastTransitionTime": time.Now().Format(time.RFC3339),
+			"observedGeneration": s.instance.GetGeneration(),
+		}
+	}
+	// Like kro, the Ready, Reconciling and Stalled conditions follow the
+	// kstatus conventions.
+	var state string
+	var conditions []interface{}
+	switch {
+	case failure != nil:
+		state = InstanceStateError
+		conditions = []interface{}{
+			condition("InstanceSynced", "False", "ReconciliationFailed", failure.Error()),
+			condition("Ready", "False", "ReconciliationFailed", failure.Error()),
+			condition("Stalled", "True", "ReconciliationFailed", failure.Error()),
+		}
+	case waiting != "":
+		state = InstanceStateInProgress
+		conditions = []interface{}{
+			condition("InstanceSynced", "False", "ReconciliationFailed", waiting),
+			condition("Ready", "False", "Progressing", waiting),
+			condition("Reconciling", "True", "Progressing", waiting),
+		}
+	default:
+		state = InstanceStateActive
+		conditions = []interface{}{
+			condition("InstanceSynced", "True", "ReconciliationSucceeded", "Instance reconciled successfully"),
+			condition("Ready", "True", "ReconciliationSucceeded", "Instance is ready"),
+		}
+	}
+	status["state"] = state
+	status["observedGeneration"] = s.instance.GetGeneration()
+	status["conditions"] = conditions
+
+	resources := []interface{}{}
+	for _, id := range s.order {
+		resource := s.resources[id]
+		entry := map[string]interface{}{
+			"id":    id,
+			"state": resource.State,
+			"ready": resource.Ready,
+		}
+		if obj, ok := s.objects[id]; ok {
+			entry["apiVersion"] = obj.GetAPIVersion()
+			entry["kind"] = obj.GetKind()
+			entry["name"] = obj.GetName()
+			if obj.GetNamespace() != "" {
+				entry["namespace"] = obj.GetNamespace()
+			}
+		}
+		if resource.Message != "" {
+			entry["message"] = resource.Message
+		}
+		resources = append(resources, entry)
+	}
+	status["resources"] = resources
+	s.instance.Object["status"] = status
+	return failure
+}
+
+// Run steps until the instance is active, its reconciliation fails, or a step
+// doesn't change anything, e.g a resource waits for its readiness conditions
+// to be met by a status set with SetResourceStatus.
+func (s *Simulator) Run() error {
+	for i := 0; i < s.maxSteps; i++ {
+		before := maps.Clone(s.resources)
+		if err := s.Step(); err != nil {
+			return err
+		}
+		if s.InstanceState() == InstanceStateActive || maps.Equal(before, s.resources) {
+			return nil
+		}
+	}
+	return fmt.Errorf("instance not settled after %d steps", s.maxSteps)
+}
+
+// Instance returns the instance, with the status kro reports for it.
+func (s *Simulator) Instance() *unstructured.Unstructured {
+	return s.instance.DeepCopy()
+}
+
+// InstanceState returns the state kro reports for the instance, e.g ACTIVE.
+func (s *Simulator) InstanceState() string {
+	state, _, _ := unstructured.NestedString(s.instance.Object, "status", "state")
+	return state
+}
+
+// Resource returns the object of the given resource in the in-memory
+// cluster, or nil if it wasn't created.
+func (s *Simulator) Resource(id string) *unstructured.Unstructured {
+	obj, ok := s.objects[id]
+	if !ok {
+		return nil
+	}
+	return obj.DeepCopy()
+}
+
+// ResourceStatus returns the status of the given resource after the last
+// step.
+func (s *Simulator) ResourceStatus(id string) ResourceStatus {
+	return s.resources[id]
+}
+
+// SetResource sets the object of the given resource in the in-memory cluster.
+// It provides the objects of the external references, or simulates changes
+// made to the resources outside of kro.
+func (s *Simulator) SetResource(id string, obj *unstructured.Unstructured) {
+	s.objects[id] = obj.DeepCopy()
+}
+
+// SetResourceStatus sets the status of the given resource in the in-memory
+// cluster, as the controller of the resource would.
+func (s *Simulator) SetResourceStatus(id string, status map[string]interface{}) error {
+	obj, ok := s.objects[id]
+	if !ok {
+		return fmt.Errorf("resource %s wasn't created", id)
+	}
+	return unstructured.SetNestedField(obj.Object, status, "status")
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testing
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+)
+
+const webAppRGD = `
+apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: webapp
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: WebApp
+    spec:
+      name: string
+      replicas: integer | default=2
+      exposed: boolean | default=true
+    status:
+      availableReplicas: ${deployment.status.availableReplicas}
+  resources:
+  - id: deployment
+    readyWhen:
+    - ${deployment.status.availableReplicas == deployment.spec.replicas}
+    template:
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: ${schema.spec.name}
+      spec:
+        replicas: ${schema.spec.replicas}
+        selector:
+          matchLabels:
+            app: ${schema.spec.name}
+        template:
+          metadata:
+            labels:
+              app: ${schema.spec.name}
+          spec:
+            containers:
+            - name: app
+              image: nginx
+  - id: service
+    includeWhen:
+    - ${schema.spec.exposed}
+    template:
+      apiVersion: v1
+      kind: Service
+      metadata:
+        name: ${deployment.metadata.name}
+      spec:
+        selector: ${deployment.spec.selector.matchLabels}
+        ports:
+        - port: 80
+`
+
+func newWebAppSimulator(t *testing.T, spec map[string]interface{}) *Simulator {
+	t.Helper()
+	rgd := &krov1alpha1.ResourceGraphDefinition{}
+	if err := yaml.Unmarshal([]byte(webAppRGD), rgd); err != nil {
+		t.Fatal(err)
+	}
+	instance := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kro.run/v1alpha1",
+		"kind":       "WebApp",
+		"metadata":   map[string]interface{}{"name": "my-app"},
+		"spec":       spec,
+	}}
+	sim, err := NewSimulator(rgd, instance, SimulatorOptions{})
+	if err != nil {
+		t.Fatalf("NewSimulator() error = %v", err)
+	}
+	return sim
+}
+
+func TestSimulator(t *testing.T) {
+	sim := newWebAppSimulator(t, map[string]interface{}{"name": "web"})
+
+	// The deployment is created, and waits to be ready.
+	if err := sim.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := sim.InstanceState(); got != InstanceStateInProgress {
+		t.Errorf("InstanceState() = %s, want %s", got, InstanceStateInProgress)
+	}
+	if got := sim.ResourceStatus("deployment").State; got != ResourceStateWaitingForReadiness {
+		t.Errorf("deployment state = %s, want %s", got, ResourceStateWaitingForReadiness)
+	}
+	if got := sim.ResourceStatus("service").State; got != ResourceStatePending {
+		t.Errorf("service state = %s, want %s", got, ResourceStatePending)
+	}
+	deployment := sim.Resource("deployment")
+	if deployment == nil {
+		t.Fatal("deployment wasn't created")
+	}
+	replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	if deployment.GetName() != "web" || replicas != 2 {
+		t.Errorf("unexpected deployment %s with %d replicas", deployment.GetName(), replicas)
+	}
+	if sim.Resource("service") != nil {
+		t.Error("service was created before the deployment is ready")
+	}
+
+	// Once the deployment is ready, the service is created and the instance
+	// status is evaluated.
+	if err := sim.SetResourceStatus("deployment", map[string]interface{}{"availableReplicas": int64(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := sim.InstanceState(); got != InstanceStateActive {
+		t.Errorf("InstanceState() = %s, want %s", got, InstanceStateActive)
+	}
+	service := sim.Resource("service")
+	if service == nil || service.GetName() != "web" {
+		t.Fatalf("unexpected service %v", service)
+	}
+	available, _, _ := unstructured.NestedInt64(sim.Instance().Object, "status", "availableReplicas")
+	if available != 2 {
+		t.Errorf("status.availableReplicas = %d, want 2", available)
+	}
+	status, _ := conditionStatus(sim.Instance(), "InstanceSynced")
+	if status != "True" {
+		t.Errorf("InstanceSynced = %s, want True", status)
+	}
+
+	// Scaling the instance updates the deployment, which waits to be ready
+	// again.
+	updated := sim.Instance()
+	if err := unstructured.SetNestedField(updated.Object, int64(3), "spec", "replicas"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.UpdateInstance(updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Step(); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	replicas, _, _ = unstructured.NestedInt64(sim.Resource("deployment").Object, "spec", "replicas")
+	if replicas != 3 {
+		t.Errorf("deployment replicas = %d, want 3", replicas)
+	}
+	if got := sim.InstanceState(); got != InstanceStateInProgress {
+		t.Errorf("InstanceState() = %s, want %s", got, InstanceStateInProgress)
+	}
+	if sim.Instance().GetGeneration() != 2 {
+		t.Errorf("generation = %d, want 2", sim.Instance().GetGeneration())
+	}
+}
+
+func TestSimulatorIncludeWhen(t *testing.T) {
+	sim := newWebAppSimulator(t, map[string]interface{}{"name": "web", "exposed": false})
+	if err := sim.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := sim.SetResourceStatus("deployment", map[string]interface{}{"availableReplicas": int64(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := sim.InstanceState(); got != InstanceStateActive {
+		t.Errorf("InstanceState() = %s, want %s", got, InstanceStateActive)
+	}
+	if got := sim.ResourceStatus("service").State; got != ResourceStateSkipped {
+		t.Errorf("service state = %s, want %s", got, ResourceStateSkipped)
+	}
+	if sim.Resource("service") != nil {
+		t.Error("excluded service was created")
+	}
+}
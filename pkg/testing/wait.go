@@ -0,0 +1,185 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// LoadResourceGraphDefinition reads a ResourceGraphDefinition from a YAML
+// file.
+func LoadResourceGraphDefinition(path string) (*krov1alpha1.ResourceGraphDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	rgd := &krov1alpha1.ResourceGraphDefinition{}
+	if err := yaml.UnmarshalStrict(data, rgd); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return rgd, nil
+}
+
+// LoadUnstructured reads a single Kubernetes object from a YAML file.
+func LoadUnstructured(path string) (*unstructured.Unstructured, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return obj, nil
+}
+
+// InstallResourceGraphDefinition creates the given ResourceGraphDefinition and
+// waits until it becomes Active, meaning that its CRD is established and its
+// instance controller is running.
+func (e *Environment) InstallResourceGraphDefinition(ctx context.Context, rgd *krov1alpha1.ResourceGraphDefinition) error {
+	if err := e.Client.Create(ctx, rgd); err != nil {
+		return fmt.Errorf("creating resource graph definition: %w", err)
+	}
+	_, err := e.WaitForResourceGraphDefinitionState(ctx, rgd.Name, krov1alpha1.ResourceGraphDefinitionStateActive)
+	return err
+}
+
+// WaitForResourceGraphDefinitionState waits until the named
+// ResourceGraphDefinition reaches the given state and returns it.
+func (e *Environment) WaitForResourceGraphDefinitionState(
+	ctx context.Context,
+	name string,
+	state krov1alpha1.ResourceGraphDefinitionState,
+) (*krov1alpha1.ResourceGraphDefinition, error) {
+	rgd := &krov1alpha1.ResourceGraphDefinition{}
+	err := e.poll(ctx, func(ctx context.Context) (bool, error) {
+		if err := e.Client.Get(ctx, types.NamespacedName{Name: name}, rgd); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return rgd.Status.State == state, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("waiting for resource graph definition %s to be %s (current state %q): %w",
+			name, state, rgd.Status.State, err)
+	}
+	return rgd, nil
+}
+
+// CreateInstance creates an instance of a ResourceGraphDefinition. The object
+// must have its apiVersion and kind set.
+func (e *Environment) CreateInstance(ctx context.Context, instance *unstructured.Unstructured) error {
+	gvr := metadata.GVKtoGVR(instance.GroupVersionKind())
+	created, err := e.ClientSet.Dynamic().Resource(gvr).
+		Namespace(instance.GetNamespace()).
+		Create(ctx, instance, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating instance: %w", err)
+	}
+	instance.Object = created.Object
+	return nil
+}
+
+// GetInstance fetches the latest version of the given instance.
+func (e *Environment) GetInstance(ctx context.Context, instance *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	gvr := metadata.GVKtoGVR(instance.GroupVersionKind())
+	return e.ClientSet.Dynamic().Resource(gvr).
+		Namespace(instance.GetNamespace()).
+		Get(ctx, instance.GetName(), metav1.GetOptions{})
+}
+
+// WaitForInstanceCondition waits until the given instance reports a condition
+// of the given type and status, and returns the latest version of the
+// instance.
+func (e *Environment) WaitForInstanceCondition(
+	ctx context.Context,
+	instance *unstructured.Unstructured,
+	conditionType string,
+	status metav1.ConditionStatus,
+) (*unstructured.Unstructured, error) {
+	var latest *unstructured.Unstructured
+	err := e.poll(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		latest, err = e.GetInstance(ctx, instance)
+		if err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		got, found := conditionStatus(latest, conditionType)
+		return found && got == status, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("waiting for instance %s condition %s=%s: %w",
+			instance.GetName(), conditionType, status, err)
+	}
+	return latest, nil
+}
+
+// WaitForInstanceState waits until the given instance reports the given
+// status.state, and returns the latest version of the instance.
+func (e *Environment) WaitForInstanceState(
+	ctx context.Context,
+	instance *unstructured.Unstructured,
+	state string,
+) (*unstructured.Unstructured, error) {
+	var latest *unstructured.Unstructured
+	err := e.poll(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		latest, err = e.GetInstance(ctx, instance)
+		if err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		got, _, _ := unstructured.NestedString(latest.Object, "status", "state")
+		return got == state, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("waiting for instance %s to be %s: %w", instance.GetName(), state, err)
+	}
+	return latest, nil
+}
+
+func (e *Environment) poll(ctx context.Context, condition wait.ConditionWithContextFunc) error {
+	return wait.PollUntilContextTimeout(ctx, e.options.PollInterval, e.options.Timeout, true, condition)
+}
+
+// conditionStatus returns the status of the condition of the given type found
+// in the object's status.conditions.
+func conditionStatus(obj *unstructured.Unstructured, conditionType string) (metav1.ConditionStatus, bool) {
+	conditions, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || !found {
+		return "", false
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] != conditionType {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		return metav1.ConditionStatus(status), true
+	}
+	return "", false
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConditionStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		obj           map[string]interface{}
+		conditionType string
+		wantStatus    metav1.ConditionStatus
+		wantFound     bool
+	}{
+		{
+			name:          "no status",
+			obj:           map[string]interface{}{},
+			conditionType: "InstanceSynced",
+		},
+		{
+			name: "condition present",
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Other", "status": "False"},
+						map[string]interface{}{"type": "InstanceSynced", "status": "True"},
+					},
+				},
+			},
+			conditionType: "InstanceSynced",
+			wantStatus:    metav1.ConditionTrue,
+			wantFound:     true,
+		},
+		{
+			name: "condition missing",
+			obj: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Other", "status": "False"},
+					},
+				},
+			},
+			conditionType: "InstanceSynced",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, found := conditionStatus(&unstructured.Unstructured{Object: tt.obj}, tt.conditionType)
+			if status != tt.wantStatus || found != tt.wantFound {
+				t.Errorf("conditionStatus() = (%v, %v), want (%v, %v)", status, found, tt.wantStatus, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestKroCRDDirectory(t *testing.T) {
+	path := filepath.Join(kroCRDDirectory(), "kro.run_resourcegraphdefinitions.yaml")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected kro CRD at %s: %v", path, err)
+	}
+}
+
+func TestLoadResourceGraphDefinition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rgd.yaml")
+	content := `apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: test
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: Test
+    spec:
+      name: string
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rgd, err := LoadResourceGraphDefinition(path)
+	if err != nil {
+		t.Fatalf("LoadResourceGraphDefinition() error = %v", err)
+	}
+	if rgd.Name != "test" || rgd.Spec.Schema.Kind != "Test" {
+		t.Errorf("unexpected resource graph definition: %+v", rgd)
+	}
+}
@@ -91,3 +91,46 @@ func WithResource(
 		})
 	}
 }
+
+// WithExternalRef adds a resource referring to an existing object to the
+// ResourceGraphDefinition.
+func WithExternalRef(
+	id string,
+	externalRef *krov1alpha1.ExternalRef,
+	readyWhen []string,
+	includeWhen []string,
+) ResourceGraphDefinitionOption {
+	return func(rgd *krov1alpha1.ResourceGraphDefinition) {
+		rgd.Spec.Resources = append(rgd.Spec.Resources, &krov1alpha1.Resource{
+			ID:          id,
+			ReadyWhen:   readyWhen,
+			IncludeWhen: includeWhen,
+			ExternalRef: externalRef,
+		})
+	}
+}
+
+// WithPatch adds a resource patching an existing object to the
+// ResourceGraphDefinition. The patch is a partial object or a list of
+// operations, depending on the patch type.
+func WithPatch(
+	id string,
+	ref krov1alpha1.ExternalRef,
+	patchType krov1alpha1.PatchType,
+	patch interface{},
+) ResourceGraphDefinitionOption {
+	return func(rgd *krov1alpha1.ResourceGraphDefinition) {
+		raw, err := json.Marshal(patch)
+		if err != nil {
+			panic(err)
+		}
+		rgd.Spec.Resources = append(rgd.Spec.Resources, &krov1alpha1.Resource{
+			ID: id,
+			Patch: &krov1alpha1.Patch{
+				ExternalRef: ref,
+				Type:        patchType,
+				Patch:       runtime.RawExtension{Raw: raw},
+			},
+		})
+	}
+}
@@ -385,6 +385,59 @@ func NewFakeResolver() (*FakeResolver, *fake.FakeDiscovery) {
 				},
 			},
 		},
+		{Version: "v1", Kind: "Secret"}: {
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"apiVersion": {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+					"kind":       {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+					"metadata":   metadataSchema(),
+					"type":       {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+					"data": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"string"}, Format: "byte"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{Group: "batch", Version: "v1", Kind: "Job"}: {
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"apiVersion": {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+					"kind":       {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+					"metadata":   metadataSchema(),
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							Properties: map[string]spec.Schema{
+								"backoffLimit": {SchemaProps: spec.SchemaProps{Type: []string{"integer"}}},
+								"template": {
+									SchemaProps: spec.SchemaProps{Type: []string{"object"}},
+									VendorExtensible: spec.VendorExtensible{
+										Extensions: spec.Extensions{"x-kubernetes-preserve-unknown-fields": true},
+									},
+								},
+							},
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"object"},
+							Properties: map[string]spec.Schema{
+								"succeeded": {SchemaProps: spec.SchemaProps{Type: []string{"integer"}}},
+								"failed":    {SchemaProps: spec.SchemaProps{Type: []string{"integer"}}},
+							},
+						},
+					},
+				},
+			},
+		},
 		// CRDs
 		{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}: {
 			SchemaProps: spec.SchemaProps{
@@ -488,6 +541,23 @@ func NewFakeResolver() (*FakeResolver, *fake.FakeDiscovery) {
 					Kind:       "Pod",
 					Verbs:      []string{"get", "list", "watch", "create", "update", "patch", "delete"},
 				},
+				{
+					Name:       "secrets",
+					Namespaced: true,
+					Kind:       "Secret",
+					Verbs:      []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+				},
+			},
+		},
+		{
+			GroupVersion: "batch/v1",
+			APIResources: []metav1.APIResource{
+				{
+					Name:       "jobs",
+					Namespaced: true,
+					Kind:       "Job",
+					Verbs:      []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+				},
 			},
 		},
 		// CRD
@@ -0,0 +1,139 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The OTLP exporter settings are read from the environment variables of the
+// OpenTelemetry specification, the traces specific variables taking
+// precedence over the generic ones.
+const (
+	envEndpoint                = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	envTracesEndpoint          = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	envHeaders                 = "OTEL_EXPORTER_OTLP_HEADERS"
+	envTracesHeaders           = "OTEL_EXPORTER_OTLP_TRACES_HEADERS"
+	envTimeout                 = "OTEL_EXPORTER_OTLP_TIMEOUT"
+	envTracesTimeout           = "OTEL_EXPORTER_OTLP_TRACES_TIMEOUT"
+	envCertificate             = "OTEL_EXPORTER_OTLP_CERTIFICATE"
+	envTracesCertificate       = "OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE"
+	envClientCertificate       = "OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"
+	envTracesClientCertificate = "OTEL_EXPORTER_OTLP_TRACES_CLIENT_CERTIFICATE"
+	envClientKey               = "OTEL_EXPORTER_OTLP_CLIENT_KEY"
+	envTracesClientKey         = "OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY"
+
+	// tracesPath is the path of the traces endpoint, appended to the generic
+	// endpoint.
+	tracesPath = "/v1/traces"
+)
+
+// EnvEndpoint returns the traces endpoint set in the environment, empty if
+// there's none.
+func EnvEndpoint() string {
+	if endpoint := os.Getenv(envTracesEndpoint); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := os.Getenv(envEndpoint); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/") + tracesPath
+	}
+	return ""
+}
+
+// lookupEnv returns the value of the traces specific variable, or of the
+// generic one if it's not set.
+func lookupEnv(traces, generic string) string {
+	if value := os.Getenv(traces); value != "" {
+		return value
+	}
+	return os.Getenv(generic)
+}
+
+// envHeadersValue returns the headers sent to the collector, e.g for its
+// authentication, set in the environment as a list of URL encoded key=value
+// pairs, separated by commas.
+func envHeadersValue() (map[string]string, error) {
+	value := lookupEnv(envTracesHeaders, envHeaders)
+	if value == "" {
+		return nil, nil
+	}
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid header %q", pair)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("invalid header %q: %w", pair, err)
+		}
+		headers[key] = decoded
+	}
+	return headers, nil
+}
+
+// envTimeoutValue returns the timeout of the export requests, set in the
+// environment in milliseconds.
+func envTimeoutValue() (time.Duration, error) {
+	value := lookupEnv(envTracesTimeout, envTimeout)
+	if value == "" {
+		return exportTimeout, nil
+	}
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be a positive number of milliseconds", value)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// envTLSConfig returns the TLS configuration of the connections to the
+// collector: the CA certificate verifying it, and the client certificate
+// presented to it, set in the environment as paths to PEM files. It's nil if
+// none is set.
+func envTLSConfig() (*tls.Config, error) {
+	caFile := lookupEnv(envTracesCertificate, envCertificate)
+	certFile := lookupEnv(envTracesClientCertificate, envClientCertificate)
+	keyFile := lookupEnv(envTracesClientKey, envClientKey)
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the certificate of the collector: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
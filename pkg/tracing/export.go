@@ -0,0 +1,321 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// defaultBatchSize is the maximum number of spans exported at once.
+	defaultBatchSize = 512
+	// defaultBatchTimeout is the maximum time a span waits to be exported.
+	defaultBatchTimeout = 5 * time.Second
+	// defaultQueueSize is the maximum number of spans waiting to be exported,
+	// the spans ended while the queue is full are dropped.
+	defaultQueueSize = 2048
+	// exportTimeout bounds the duration of an export request, unless set in
+	// the environment.
+	exportTimeout = 10 * time.Second
+
+	// spanKindInternal is the OTLP kind of the spans, which are all internal
+	// operations of kro.
+	spanKindInternal = 1
+	// statusCodeError is the OTLP status code of the spans recording an
+	// error.
+	statusCodeError = 2
+)
+
+// Config is the configuration of the tracer.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP traces endpoint of the collector,
+	// e.g http://otel-collector:4318/v1/traces. It defaults to the endpoint
+	// set in the environment.
+	Endpoint string
+	// ServiceName is the service.name resource attribute of the spans.
+	ServiceName string
+	// SampleRatio is the ratio of the traces exported, between 0 and 1.
+	SampleRatio float64
+}
+
+// Tracer batches the spans ended and exports them to a collector.
+type Tracer struct {
+	log         logr.Logger
+	endpoint    string
+	serviceName string
+	sampleRatio float64
+	client      *http.Client
+	// headers are sent along with the spans, e.g to authenticate to the
+	// collector.
+	headers map[string]string
+
+	// mu guards the queue of spans, which is closed once the tracer is
+	// stopped.
+	mu      sync.RWMutex
+	stopped bool
+	spans   chan *Span
+	done    chan struct{}
+}
+
+// Setup sets up the tracer the spans are started with, following the given
+// configuration, and starts exporting the spans in the background. The
+// headers, timeout and TLS certificates of the exporter are read from the
+// OTEL_EXPORTER_OTLP_* environment variables. The returned function stops the
+// tracer, once the spans ended are exported or the given context is done.
+func Setup(log logr.Logger, config Config) (func(context.Context) error, error) {
+	if config.Endpoint == "" {
+		config.Endpoint = EnvEndpoint()
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid tracing endpoint %q: must be an http or https URL", config.Endpoint)
+	}
+	if !validSampleRatio(config.SampleRatio) {
+		return nil, fmt.Errorf("invalid tracing sample ratio %v: must be between 0 and 1", config.SampleRatio)
+	}
+	headers, err := envHeadersValue()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing headers: %w", err)
+	}
+	timeout, err := envTimeoutValue()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing timeout: %w", err)
+	}
+	tlsConfig, err := envTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing TLS configuration: %w", err)
+	}
+	client := &http.Client{Timeout: timeout}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	tracer := &Tracer{
+		log:         log.WithName("tracing"),
+		endpoint:    endpoint.String(),
+		serviceName: config.ServiceName,
+		sampleRatio: config.SampleRatio,
+		client:      client,
+		headers:     headers,
+		spans:       make(chan *Span, defaultQueueSize),
+		done:        make(chan struct{}),
+	}
+	go tracer.run()
+	global.Store(tracer)
+
+	return func(ctx context.Context) error {
+		global.CompareAndSwap(tracer, nil)
+		tracer.mu.Lock()
+		if !tracer.stopped {
+			tracer.stopped = true
+			close(tracer.spans)
+		}
+		tracer.mu.Unlock()
+		select {
+		case <-tracer.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, nil
+}
+
+// export queues the given span to be exported, or drops it if the queue is
+// full: tracing must not hold up the reconciliations.
+func (t *Tracer) export(span *Span) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.stopped {
+		return
+	}
+	select {
+	case t.spans <- span:
+	default:
+	}
+}
+
+// run exports the queued spans in batches, until the tracer is stopped.
+func (t *Tracer) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(defaultBatchTimeout)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, defaultBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.send(batch); err != nil {
+			t.log.Error(err, "Failed to export spans", "spans", len(batch))
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case span, ok := <-t.spans:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) == defaultBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send exports the given spans to the collector.
+func (t *Tracer) send(spans []*Span) error {
+	body, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), t.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// The types below are the JSON encoding of an OTLP export request of traces.
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []spanData `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type spanData struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            *status    `json:"status,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+// request returns the export request of the given spans.
+func (t *Tracer) request(spans []*Span) exportRequest {
+	data := make([]spanData, 0, len(spans))
+	for _, span := range spans {
+		span.mu.Lock()
+		encoded := spanData{
+			TraceID:           fmt.Sprintf("%x", span.traceID),
+			SpanID:            fmt.Sprintf("%x", span.spanID),
+			Name:              span.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Attributes:        keyValues(span.attributes),
+		}
+		if span.parentID != [8]byte{} {
+			encoded.ParentSpanID = fmt.Sprintf("%x", span.parentID)
+		}
+		if span.err != nil {
+			encoded.Status = &status{Code: statusCodeError, Message: span.err.Error()}
+		}
+		span.mu.Unlock()
+		data = append(data, encoded)
+	}
+
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: keyValues([]Attribute{String("service.name", t.serviceName)})},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: "github.com/kro-run/kro"}, Spans: data}},
+	}}}
+}
+
+func keyValues(attributes []Attribute) []keyValue {
+	values := make([]keyValue, 0, len(attributes))
+	for _, attribute := range attributes {
+		var value anyValue
+		switch v := attribute.Value.(type) {
+		case string:
+			value.StringValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			value.IntValue = &s
+		case bool:
+			value.BoolValue = &v
+		default:
+			s := fmt.Sprint(v)
+			value.StringValue = &s
+		}
+		values = append(values, keyValue{Key: attribute.Key, Value: value})
+	}
+	return values
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tracing traces the reconciliations of the instances, from the
+// resolution of their graph to the rendering, apply and readiness of each of
+// their resources. The spans are exported to an OpenTelemetry collector with
+// the OTLP/HTTP protocol.
+//
+// Tracing is opt-in: until a tracer is set up, Start returns a nil span, whose
+// methods do nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// global is the tracer the spans are started with, nil if tracing isn't set
+// up.
+var global atomic.Pointer[Tracer]
+
+// Attribute is an attribute of a span, whose value is a string, an int64 or
+// a bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is an operation of a trace. A nil span is a valid span that isn't
+// recorded, e.g because tracing isn't set up.
+type Span struct {
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	// sampled reports whether the span, and its children, are exported.
+	sampled bool
+	name    string
+	start   time.Time
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes []Attribute
+	err        error
+	ended      bool
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the span of the given context, nil if there's none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start starts a span with the given name and attributes, child of the span
+// of the given context if any, and returns a context holding it. The span must
+// be ended by the caller.
+func Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	tracer := global.Load()
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer:     tracer,
+		name:       name,
+		start:      time.Now(),
+		attributes: attributes,
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+		span.sampled = parent.sampled
+	} else {
+		span.traceID = randomTraceID()
+		span.sampled = tracer.sample(span.traceID)
+	}
+	span.spanID = randomSpanID()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds the given attributes to the span.
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil || !s.sampled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, attributes...)
+}
+
+// RecordError sets the status of the span to the given error, if it's not
+// nil.
+func (s *Span) RecordError(err error) {
+	if s == nil || !s.sampled || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// End ends the span, which is then exported. Only the first call has an
+// effect.
+func (s *Span) End() {
+	if s == nil || !s.sampled {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.tracer.export(s)
+}
+
+// TraceID returns the hex encoded ID of the trace of the span, empty for a
+// nil span.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", s.traceID)
+}
+
+// sample reports whether the trace of the given ID is exported, following the
+// sample ratio of the tracer. The decision only depends on the trace ID, like
+// the TraceIDRatioBased sampler of OpenTelemetry.
+func (t *Tracer) sample(traceID [16]byte) bool {
+	switch {
+	case t.sampleRatio >= 1:
+		return true
+	case t.sampleRatio <= 0:
+		return false
+	}
+	bound := uint64(t.sampleRatio * (1 << 63))
+	return binary.BigEndian.Uint64(traceID[8:])>>1 < bound
+}
+
+func randomTraceID() [16]byte {
+	var id [16]byte
+	for id == [16]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func randomSpanID() [8]byte {
+	var id [8]byte
+	for id == [8]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// validSampleRatio reports whether the given ratio is a valid sample ratio.
+func validSampleRatio(ratio float64) bool {
+	return !math.IsNaN(ratio) && ratio >= 0 && ratio <= 1
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector is an OTLP/HTTP collector recording the spans it receives.
+type collector struct {
+	mu    sync.Mutex
+	spans []spanData
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request exportRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, resourceSpans := range request.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			c.spans = append(c.spans, scopeSpans.Spans...)
+		}
+	}
+}
+
+func TestStartWithoutTracer(t *testing.T) {
+	ctx, span := Start(context.Background(), "reconcile")
+	assert.Nil(t, span)
+	assert.Nil(t, SpanFromContext(ctx))
+
+	// A nil span can be used as any other.
+	span.SetAttributes(String("key", "value"))
+	span.RecordError(errors.New("failed"))
+	span.End()
+	assert.Empty(t, span.TraceID())
+}
+
+func TestExport(t *testing.T) {
+	spans := &collector{}
+	server := httptest.NewServer(spans)
+	defer server.Close()
+
+	shutdown, err := Setup(logr.Discard(), Config{Endpoint: server.URL + "/v1/traces", ServiceName: "kro", SampleRatio: 1})
+	require.NoError(t, err)
+
+	ctx, parent := Start(context.Background(), "reconcile", String("uid", "1234"))
+	_, child := Start(ctx, "apply", Int("attempt", 2))
+	child.RecordError(errors.New("forbidden"))
+	child.End()
+	parent.End()
+	parent.End()
+
+	require.NoError(t, shutdown(context.Background()))
+	require.Len(t, spans.spans, 2)
+
+	apply, reconcile := spans.spans[0], spans.spans[1]
+	assert.Equal(t, "reconcile", reconcile.Name)
+	assert.Equal(t, parent.TraceID(), reconcile.TraceID)
+	assert.Empty(t, reconcile.ParentSpanID)
+	assert.Nil(t, reconcile.Status)
+	assert.Equal(t, "uid", reconcile.Attributes[0].Key)
+	assert.Equal(t, "1234", *reconcile.Attributes[0].Value.StringValue)
+
+	assert.Equal(t, "apply", apply.Name)
+	assert.Equal(t, reconcile.TraceID, apply.TraceID)
+	assert.Equal(t, reconcile.SpanID, apply.ParentSpanID)
+	assert.Equal(t, "2", *apply.Attributes[0].Value.IntValue)
+	assert.Equal(t, &status{Code: statusCodeError, Message: "forbidden"}, apply.Status)
+
+	// Once stopped, no span is recorded.
+	_, span := Start(context.Background(), "reconcile")
+	assert.Nil(t, span)
+}
+
+func TestSampling(t *testing.T) {
+	tracer := &Tracer{sampleRatio: 0}
+	global.Store(tracer)
+	defer global.Store(nil)
+
+	// The children of a trace that isn't sampled aren't sampled either.
+	ctx, parent := Start(context.Background(), "reconcile")
+	_, child := Start(ctx, "apply")
+	assert.False(t, parent.sampled)
+	assert.False(t, child.sampled)
+	assert.Equal(t, parent.TraceID(), child.TraceID())
+
+	tracer.sampleRatio = 0.5
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if tracer.sample(randomTraceID()) {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
+
+func TestSetupErrors(t *testing.T) {
+	_, err := Setup(logr.Discard(), Config{Endpoint: "otel-collector:4318", SampleRatio: 1})
+	assert.ErrorContains(t, err, "invalid tracing endpoint")
+
+	_, err = Setup(logr.Discard(), Config{Endpoint: "http://otel-collector:4318/v1/traces", SampleRatio: 2})
+	assert.ErrorContains(t, err, "invalid tracing sample ratio")
+}
+
+func TestExportEnv(t *testing.T) {
+	var header string
+	spans := &collector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+		spans.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	// The endpoint and headers default to the environment.
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "Authorization=Bearer%20token")
+	assert.Equal(t, server.URL+"/v1/traces", EnvEndpoint())
+
+	shutdown, err := Setup(logr.Discard(), Config{ServiceName: "kro", SampleRatio: 1})
+	require.NoError(t, err)
+	_, span := Start(context.Background(), "reconcile")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	require.Len(t, spans.spans, 1)
+	assert.Equal(t, "Bearer token", header)
+}
+
+func TestSetupEnvErrors(t *testing.T) {
+	config := Config{Endpoint: "http://otel-collector:4318/v1/traces", SampleRatio: 1}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization")
+	_, err := Setup(logr.Discard(), config)
+	assert.ErrorContains(t, err, "invalid tracing headers")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "")
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "10s")
+	_, err = Setup(logr.Discard(), config)
+	assert.ErrorContains(t, err, "invalid tracing timeout")
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "")
+
+	t.Setenv("OTEL_EXPORTER_OTLP_CERTIFICATE", "/nonexistent/ca.crt")
+	_, err = Setup(logr.Discard(), config)
+	assert.ErrorContains(t, err, "invalid tracing TLS configuration")
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-logr/logr"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kro-run/kro/pkg/graph/conversion"
+)
+
+// ConversionWebhookPath is the path the conversion webhook is served on.
+const ConversionWebhookPath = "/convert-kro-run-instance"
+
+// ConversionWebhook is an HTTP handler converting instances between the
+// versions of the instance API of a ResourceGraphDefinition, for the CRDs
+// declaring conversions that the API server can't do on its own, like field
+// renames.
+//
+// Converters are registered by the ResourceGraphDefinition controller when it
+// (re)builds the instance CRD, and unregistered when the
+// ResourceGraphDefinition is deleted.
+type ConversionWebhook struct {
+	log logr.Logger
+	// clientConfig is how the API server reaches the webhook.
+	clientConfig extv1.WebhookClientConfig
+	// converters maps a GroupKind to its converter.
+	converters sync.Map
+}
+
+// NewConversionWebhook creates a new ConversionWebhook, reachable by the API
+// server with the given client config.
+func NewConversionWebhook(log logr.Logger, clientConfig extv1.WebhookClientConfig) *ConversionWebhook {
+	return &ConversionWebhook{
+		log:          log.WithName("conversion-webhook"),
+		clientConfig: clientConfig,
+	}
+}
+
+// Register registers the converter of the instances of the given GroupKind.
+func (w *ConversionWebhook) Register(gk schema.GroupKind, converter *conversion.Converter) {
+	w.converters.Store(gk, converter)
+}
+
+// Unregister stops converting objects of the given GroupKind.
+func (w *ConversionWebhook) Unregister(gk schema.GroupKind) {
+	w.converters.Delete(gk)
+}
+
+// CustomResourceConversion returns the conversion settings of the CRDs whose
+// instances are converted by the webhook.
+func (w *ConversionWebhook) CustomResourceConversion() *extv1.CustomResourceConversion {
+	return &extv1.CustomResourceConversion{
+		Strategy: extv1.WebhookConverter,
+		Webhook: &extv1.WebhookConversion{
+			ClientConfig:             w.clientConfig.DeepCopy(),
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (w *ConversionWebhook) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	review := &extv1.ConversionReview{}
+	if err := json.NewDecoder(req.Body).Decode(review); err != nil {
+		http.Error(rw, fmt.Sprintf("failed to decode conversion review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(rw, "conversion review has no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = w.convert(review.Request)
+	review.Request = nil
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		w.log.Error(err, "failed to encode conversion review")
+	}
+}
+
+// convert converts the objects of the given request to the desired version.
+func (w *ConversionWebhook) convert(req *extv1.ConversionRequest) *extv1.ConversionResponse {
+	resp := &extv1.ConversionResponse{UID: req.UID}
+	desired, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		resp.Result = failureStatus(fmt.Errorf("invalid desired apiVersion: %w", err))
+		return resp
+	}
+
+	converted := make([]runtime.RawExtension, 0, len(req.Objects))
+	for _, raw := range req.Objects {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+			resp.Result = failureStatus(fmt.Errorf("failed to decode object: %w", err))
+			return resp
+		}
+		gk := schema.GroupKind{Group: desired.Group, Kind: obj.GetKind()}
+		value, ok := w.converters.Load(gk)
+		if !ok {
+			resp.Result = failureStatus(fmt.Errorf("no converter registered for %s", gk))
+			return resp
+		}
+		if err := value.(*conversion.Converter).Convert(obj, desired.Version); err != nil {
+			resp.Result = failureStatus(fmt.Errorf("failed to convert %s %s: %w", gk, obj.GetName(), err))
+			return resp
+		}
+		data, err := obj.MarshalJSON()
+		if err != nil {
+			resp.Result = failureStatus(err)
+			return resp
+		}
+		converted = append(converted, runtime.RawExtension{Raw: data})
+	}
+
+	w.log.V(2).Info("converted instances", "count", len(converted), "version", req.DesiredAPIVersion)
+	resp.ConvertedObjects = converted
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+func failureStatus(err error) metav1.Status {
+	return metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/conversion"
+)
+
+func reviewConversion(t *testing.T, w *ConversionWebhook, desiredAPIVersion string, objects ...string) *extv1.ConversionResponse {
+	review := extv1.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request: &extv1.ConversionRequest{
+			UID:               types.UID("uid"),
+			DesiredAPIVersion: desiredAPIVersion,
+		},
+	}
+	for _, obj := range objects {
+		review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{Raw: []byte(obj)})
+	}
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ConversionWebhookPath, bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response extv1.ConversionReview
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.NotNil(t, response.Response)
+	assert.Equal(t, types.UID("uid"), response.Response.UID)
+	return response.Response
+}
+
+func TestConversionWebhook(t *testing.T) {
+	converter, err := conversion.NewConverter("v1", []v1alpha1.SchemaVersion{{
+		Name: "v1alpha1",
+		Conversion: &v1alpha1.VersionConversion{
+			Renames: []v1alpha1.FieldRename{{From: "image", To: "container.image"}},
+		},
+	}})
+	require.NoError(t, err)
+
+	w := NewConversionWebhook(logr.Discard(), extv1.WebhookClientConfig{})
+	w.Register(schema.GroupKind{Group: "kro.run", Kind: "WebApp"}, converter)
+
+	t.Run("converts objects", func(t *testing.T) {
+		resp := reviewConversion(t, w, "kro.run/v1",
+			`{"apiVersion":"kro.run/v1alpha1","kind":"WebApp","metadata":{"name":"app"},"spec":{"image":"nginx"}}`)
+		require.Equal(t, metav1.StatusSuccess, resp.Result.Status)
+		require.Len(t, resp.ConvertedObjects, 1)
+		assert.JSONEq(t,
+			`{"apiVersion":"kro.run/v1","kind":"WebApp","metadata":{"name":"app"},"spec":{"container":{"image":"nginx"}}}`,
+			string(resp.ConvertedObjects[0].Raw))
+	})
+
+	t.Run("unregistered kind", func(t *testing.T) {
+		resp := reviewConversion(t, w, "kro.run/v1",
+			`{"apiVersion":"kro.run/v1alpha1","kind":"Database","metadata":{"name":"db"},"spec":{}}`)
+		assert.Equal(t, metav1.StatusFailure, resp.Result.Status)
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		w.Unregister(schema.GroupKind{Group: "kro.run", Kind: "WebApp"})
+		resp := reviewConversion(t, w, "kro.run/v1",
+			`{"apiVersion":"kro.run/v1alpha1","kind":"WebApp","metadata":{"name":"app"},"spec":{}}`)
+		assert.Equal(t, metav1.StatusFailure, resp.Result.Status)
+	})
+}
+
+func TestConversionWebhookCustomResourceConversion(t *testing.T) {
+	path := ConversionWebhookPath
+	w := NewConversionWebhook(logr.Discard(), extv1.WebhookClientConfig{
+		Service:  &extv1.ServiceReference{Name: "kro-webhook", Namespace: "kro-system", Path: &path},
+		CABundle: []byte("ca"),
+	})
+	conv := w.CustomResourceConversion()
+	assert.Equal(t, extv1.WebhookConverter, conv.Strategy)
+	require.NotNil(t, conv.Webhook)
+	assert.Equal(t, []string{"v1"}, conv.Webhook.ConversionReviewVersions)
+	assert.Equal(t, "kro-webhook", conv.Webhook.ClientConfig.Service.Name)
+	assert.Equal(t, []byte("ca"), conv.Webhook.ClientConfig.CABundle)
+}
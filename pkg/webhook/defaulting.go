@@ -0,0 +1,149 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultingWebhookPath is the path the defaulting webhook is served on.
+const DefaultingWebhookPath = "/mutate-kro-run-instance"
+
+// DefaultingWebhook is an admission handler applying the defaults declared in
+// the simpleschema of a ResourceGraphDefinition to its instances. Defaults are
+// applied at admission so that the stored object, and everything observing
+// it, immediately reflects the effective spec. Quantities, durations and
+// date-times are normalized to their canonical form along the way. Setting
+// fields declared as deprecated in the schema returns warnings to the client.
+//
+// Schemas are registered on every replica when the ResourceGraphDefinitions
+// are built, and unregistered once they're deleted.
+type DefaultingWebhook struct {
+	log logr.Logger
+	// schemas maps a GroupVersionKind to its registration.
+	schemas sync.Map
+}
+
+// Defaulter computes the defaults of instances that their schema can't
+// declare, e.g defaults computed from other fields. They're applied after
+// the defaults of the schema.
+type Defaulter interface {
+	Default(ctx context.Context, instance *unstructured.Unstructured) error
+}
+
+// registration is the schema of a GroupVersionKind, and its defaulter if any.
+type registration struct {
+	structural *structuralschema.Structural
+	defaulter  Defaulter
+}
+
+// NewDefaultingWebhook creates a new DefaultingWebhook.
+func NewDefaultingWebhook(log logr.Logger) *DefaultingWebhook {
+	return &DefaultingWebhook{
+		log: log.WithName("defaulting-webhook"),
+	}
+}
+
+// Register registers the schema of the given CRD version for defaulting. The
+// defaulter is optional.
+func (w *DefaultingWebhook) Register(gvk schema.GroupVersionKind, crd *extv1.CustomResourceDefinition, defaulter Defaulter) error {
+	var openAPISchema *extv1.JSONSchemaProps
+	for _, version := range crd.Spec.Versions {
+		if version.Name == gvk.Version && version.Schema != nil {
+			openAPISchema = version.Schema.OpenAPIV3Schema
+			break
+		}
+	}
+	if openAPISchema == nil {
+		return fmt.Errorf("no schema found for version %s in CRD %s", gvk.Version, crd.Name)
+	}
+
+	structural, err := toStructural(openAPISchema)
+	if err != nil {
+		return fmt.Errorf("failed to build structural schema for %s: %w", gvk, err)
+	}
+	w.schemas.Store(gvk, &registration{structural: structural, defaulter: defaulter})
+	return nil
+}
+
+// Unregister stops defaulting objects of the given GroupVersionKind.
+func (w *DefaultingWebhook) Unregister(gvk schema.GroupVersionKind) {
+	w.schemas.Delete(gvk)
+}
+
+// Handle implements admission.Handler.
+func (w *DefaultingWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	gvk := schema.GroupVersionKind{
+		Group:   req.Kind.Group,
+		Version: req.Kind.Version,
+		Kind:    req.Kind.Kind,
+	}
+	value, ok := w.schemas.Load(gvk)
+	if !ok {
+		// Not one of ours (anymore), let it through untouched.
+		return admission.Allowed("no schema registered")
+	}
+	registered := value.(*registration)
+	structural := registered.structural
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// Deprecated fields are checked before defaulting, so that defaults don't
+	// trigger warnings.
+	warnings := deprecationWarnings(obj, structural, "")
+
+	structuraldefaulting.Default(obj, structural)
+	if registered.defaulter != nil {
+		// Failing to compute defaults doesn't block the write, the fields are
+		// left unset.
+		if err := registered.defaulter.Default(ctx, &unstructured.Unstructured{Object: obj}); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to compute defaults: %v", err))
+		}
+	}
+	normalize(obj, structural)
+
+	defaulted, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	w.log.V(2).Info("defaulted instance", "gvk", gvk, "name", req.Name, "namespace", req.Namespace)
+	resp := admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+	resp.Warnings = warnings
+	return resp
+}
+
+// toStructural converts a v1 JSONSchemaProps to a structural schema.
+func toStructural(openAPISchema *extv1.JSONSchemaProps) (*structuralschema.Structural, error) {
+	internal := &apiextensions.JSONSchemaProps{}
+	if err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(openAPISchema, internal, nil); err != nil {
+		return nil, err
+	}
+	return structuralschema.NewStructural(internal)
+}
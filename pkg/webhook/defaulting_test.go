@@ -0,0 +1,208 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kro-run/kro/pkg/graph/crd"
+	"github.com/kro-run/kro/pkg/simpleschema"
+)
+
+func newTestCRD(t *testing.T) *extv1.CustomResourceDefinition {
+	spec, err := simpleschema.ToOpenAPISpec(map[string]interface{}{
+		"name":     "string | required=true",
+		"replicas": "integer | default=3",
+		"image":    `string | default="nginx"`,
+	})
+	require.NoError(t, err)
+	return crd.SynthesizeCRD("kro.run", "v1alpha1", "WebApp", *spec, extv1.JSONSchemaProps{}, true)
+}
+
+func newAdmissionRequest(gvk schema.GroupVersionKind, raw string) admission.Request {
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte(raw)},
+		},
+	}
+}
+
+func TestDefaultingWebhook(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "kro.run", Version: "v1alpha1", Kind: "WebApp"}
+	w := NewDefaultingWebhook(logr.Discard())
+	require.NoError(t, w.Register(gvk, newTestCRD(t), nil))
+
+	t.Run("applies missing defaults", func(t *testing.T) {
+		resp := w.Handle(context.Background(), newAdmissionRequest(gvk,
+			`{"apiVersion":"kro.run/v1alpha1","kind":"WebApp","spec":{"name":"app","image":"httpd"}}`))
+		assert.True(t, resp.Allowed)
+		require.Len(t, resp.Patches, 1)
+		assert.Equal(t, "/spec/replicas", resp.Patches[0].Path)
+		assert.EqualValues(t, 3, resp.Patches[0].Value)
+	})
+
+	t.Run("unknown kind is allowed untouched", func(t *testing.T) {
+		other := schema.GroupVersionKind{Group: "kro.run", Version: "v1alpha1", Kind: "Other"}
+		resp := w.Handle(context.Background(), newAdmissionRequest(other, `{"spec":{}}`))
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("unregistered kind is allowed untouched", func(t *testing.T) {
+		w.Unregister(gvk)
+		resp := w.Handle(context.Background(), newAdmissionRequest(gvk, `{"spec":{"name":"app"}}`))
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Patches)
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		err := w.Register(schema.GroupVersionKind{Group: "kro.run", Version: "v2", Kind: "WebApp"}, newTestCRD(t), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestDefaultingWebhookDeprecatedFields(t *testing.T) {
+	spec, err := simpleschema.ToOpenAPISpecWithTypes(map[string]interface{}{
+		"name":      "string",
+		"size":      `string | deprecated=true deprecationMessage="use replicas instead"`,
+		"legacy":    "boolean | deprecated=true default=false",
+		"endpoints": "[]Endpoint",
+	}, map[string]interface{}{
+		"Endpoint": map[string]interface{}{
+			"url":  "string",
+			"port": "integer | deprecated=true",
+		},
+	})
+	require.NoError(t, err)
+	gvk := schema.GroupVersionKind{Group: "kro.run", Version: "v1alpha1", Kind: "WebApp"}
+	w := NewDefaultingWebhook(logr.Discard())
+	require.NoError(t, w.Register(gvk, crd.SynthesizeCRD("kro.run", "v1alpha1", "WebApp", *spec, extv1.JSONSchemaProps{}, true), nil))
+
+	t.Run("set deprecated fields", func(t *testing.T) {
+		resp := w.Handle(context.Background(), newAdmissionRequest(gvk,
+			`{"spec":{"name":"app","size":"large","endpoints":[{"url":"a"},{"url":"b","port":80}]}}`))
+		assert.True(t, resp.Allowed)
+		assert.Equal(t, []string{
+			"spec.endpoints[1].port is deprecated",
+			"spec.size is deprecated: use replicas instead",
+		}, resp.Warnings)
+	})
+
+	t.Run("defaulted deprecated fields", func(t *testing.T) {
+		resp := w.Handle(context.Background(), newAdmissionRequest(gvk, `{"spec":{"name":"app"}}`))
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Warnings)
+	})
+}
+
+func TestDefaultingWebhookNormalization(t *testing.T) {
+	spec, err := simpleschema.ToOpenAPISpecWithTypes(map[string]interface{}{
+		"memory":    "quantity | default=\"1024Mi\"",
+		"timeout":   "duration",
+		"expiresAt": "datetime",
+		"limits":    "map[string]quantity",
+		"name":      "string",
+	}, nil)
+	require.NoError(t, err)
+	gvk := schema.GroupVersionKind{Group: "kro.run", Version: "v1alpha1", Kind: "WebApp"}
+	w := NewDefaultingWebhook(logr.Discard())
+	require.NoError(t, w.Register(gvk, crd.SynthesizeCRD("kro.run", "v1alpha1", "WebApp", *spec, extv1.JSONSchemaProps{}, true), nil))
+
+	patches := func(t *testing.T, raw string) map[string]interface{} {
+		resp := w.Handle(context.Background(), newAdmissionRequest(gvk, raw))
+		require.True(t, resp.Allowed)
+		values := map[string]interface{}{}
+		for _, patch := range resp.Patches {
+			values[patch.Path] = patch.Value
+		}
+		return values
+	}
+
+	t.Run("normalizes known types", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{
+			"/spec/memory":         "2Gi",
+			"/spec/timeout":        "1m30s",
+			"/spec/expiresAt":      "2025-01-01T09:00:00Z",
+			"/spec/limits/cpu":     "1",
+			"/spec/limits/storage": "1500M",
+		}, patches(t, `{"spec":{"memory":"2048Mi","timeout":"90s","expiresAt":"2025-01-01T10:00:00+01:00",`+
+			`"limits":{"cpu":"1000m","storage":"1.5G"},"name":"90s"}}`))
+	})
+
+	t.Run("normalizes defaults", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{"/spec/memory": "1Gi"}, patches(t, `{"spec":{}}`))
+	})
+
+	t.Run("canonical values are untouched", func(t *testing.T) {
+		assert.Empty(t, patches(t, `{"spec":{"memory":"1Gi","timeout":"1h0m0s","expiresAt":"2025-01-01T00:00:00Z"}}`))
+	})
+
+	t.Run("invalid values are untouched", func(t *testing.T) {
+		assert.Empty(t, patches(t, `{"spec":{"memory":"lots","timeout":"1 day"}}`))
+	})
+}
+
+type defaulterFunc func(ctx context.Context, instance *unstructured.Unstructured) error
+
+func (f defaulterFunc) Default(ctx context.Context, instance *unstructured.Unstructured) error {
+	return f(ctx, instance)
+}
+
+func TestDefaultingWebhookDefaulter(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "kro.run", Version: "v1alpha1", Kind: "WebApp"}
+	w := NewDefaultingWebhook(logr.Discard())
+
+	t.Run("applies computed defaults after the schema defaults", func(t *testing.T) {
+		require.NoError(t, w.Register(gvk, newTestCRD(t), defaulterFunc(func(_ context.Context, u *unstructured.Unstructured) error {
+			replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+			return unstructured.SetNestedField(u.Object, fmt.Sprintf("%s-%d", u.GetName(), replicas), "spec", "name")
+		})))
+		resp := w.Handle(context.Background(), newAdmissionRequest(gvk,
+			`{"apiVersion":"kro.run/v1alpha1","kind":"WebApp","metadata":{"name":"app"},"spec":{"image":"httpd"}}`))
+		assert.True(t, resp.Allowed)
+		values := map[string]interface{}{}
+		for _, patch := range resp.Patches {
+			values[patch.Path] = patch.Value
+		}
+		assert.EqualValues(t, 3, values["/spec/replicas"])
+		assert.Equal(t, "app-3", values["/spec/name"])
+	})
+
+	t.Run("failures are warnings", func(t *testing.T) {
+		require.NoError(t, w.Register(gvk, newTestCRD(t), defaulterFunc(func(context.Context, *unstructured.Unstructured) error {
+			return errors.New("no such key: name")
+		})))
+		resp := w.Handle(context.Background(), newAdmissionRequest(gvk, `{"spec":{"name":"app","image":"httpd"}}`))
+		assert.True(t, resp.Allowed)
+		require.Len(t, resp.Patches, 1)
+		assert.Equal(t, "/spec/replicas", resp.Patches[0].Path)
+		assert.Equal(t, []string{"failed to compute defaults: no such key: name"}, resp.Warnings)
+	})
+}
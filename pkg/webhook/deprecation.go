@@ -0,0 +1,72 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package webhook
+
+import (
+	"fmt"
+	"sort"
+
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+
+	"github.com/kro-run/kro/pkg/simpleschema"
+)
+
+// deprecationWarnings returns a warning for each field of the given value
+// declared as deprecated in its schema. Deprecated fields are only reported
+// when they are set.
+func deprecationWarnings(value interface{}, s *structuralschema.Structural, path string) []string {
+	if s == nil || value == nil {
+		return nil
+	}
+
+	var warnings []string
+	if path != "" {
+		if message, ok := simpleschema.ParseDeprecation(s.Generic.Description); ok {
+			warning := fmt.Sprintf("%s is deprecated", path)
+			if message != "" {
+				warning += ": " + message
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := s.Properties[key]; ok {
+				warnings = append(warnings, deprecationWarnings(v[key], &property, joinPath(path, key))...)
+			} else if s.AdditionalProperties != nil {
+				warnings = append(warnings, deprecationWarnings(v[key], s.AdditionalProperties.Structural,
+					fmt.Sprintf("%s[%s]", path, key))...)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			warnings = append(warnings, deprecationWarnings(item, s.Items, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return warnings
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
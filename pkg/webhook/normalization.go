@@ -0,0 +1,75 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package webhook
+
+import (
+	"time"
+
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kro-run/kro/pkg/simpleschema"
+)
+
+// normalize rewrites the values of the given value whose type has a canonical
+// form in their canonical form, so that equal values are stored, and compared
+// by the expressions, the same way:
+//
+//   - quantities, e.g `1024Mi` becomes `1Gi`
+//   - durations, e.g `90s` becomes `1m30s`
+//   - date-times, which are converted to UTC
+//
+// Values that don't parse are left as is, the API server rejects them. Maps
+// and arrays are normalized in place.
+func normalize(value interface{}, s *structuralschema.Structural) interface{} {
+	if s == nil || value == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if property, ok := s.Properties[key]; ok {
+				v[key] = normalize(item, &property)
+			} else if s.AdditionalProperties != nil {
+				v[key] = normalize(item, s.AdditionalProperties.Structural)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item, s.Items)
+		}
+	case string:
+		return normalizeString(v, s)
+	}
+	return value
+}
+
+func normalizeString(value string, s *structuralschema.Structural) string {
+	switch {
+	case s.Extensions.XIntOrString && s.ValueValidation != nil && s.ValueValidation.Pattern == simpleschema.QuantityPattern:
+		if quantity, err := resource.ParseQuantity(value); err == nil {
+			return quantity.String()
+		}
+	case s.Type == "string" && s.ValueValidation != nil && s.ValueValidation.Format == "duration":
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration.String()
+		}
+	case s.Type == "string" && s.ValueValidation != nil && s.ValueValidation.Format == "date-time":
+		if dateTime, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return dateTime.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return value
+}
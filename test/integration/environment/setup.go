@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -34,11 +35,15 @@ import (
 	ctrlresourcegraphdefinition "github.com/kro-run/kro/pkg/controller/resourcegraphdefinition"
 	"github.com/kro-run/kro/pkg/dynamiccontroller"
 	"github.com/kro-run/kro/pkg/graph"
+	kroruntime "github.com/kro-run/kro/pkg/runtime"
 )
 
 type Environment struct {
 	context context.Context
 	cancel  context.CancelFunc
+	// running tracks the goroutines of the dynamic controller and the
+	// manager, until they exit.
+	running sync.WaitGroup
 
 	ControllerConfig ControllerConfig
 	Client           client.Client
@@ -122,7 +127,7 @@ func (e *Environment) initializeClients() error {
 	e.CRDManager = e.ClientSet.CRD(kroclient.CRDWrapperConfig{})
 
 	restConfig := e.ClientSet.RESTConfig()
-	e.GraphBuilder, err = graph.NewBuilder(restConfig)
+	e.GraphBuilder, err = graph.NewBuilder(restConfig, kroruntime.Cluster{Domain: "cluster.local"})
 	if err != nil {
 		return fmt.Errorf("creating graph builder: %w", err)
 	}
@@ -145,7 +150,9 @@ func (e *Environment) setupController() error {
 		},
 		e.ClientSet.Dynamic())
 
+	e.running.Add(1)
 	go func() {
+		defer e.running.Done()
 		err := dc.Run(e.context)
 		if err != nil {
 			panic(fmt.Sprintf("failed to run dynamic controller: %v", err))
@@ -157,7 +164,11 @@ func (e *Environment) setupController() error {
 		e.ControllerConfig.AllowCRDDeletion,
 		dc,
 		e.GraphBuilder,
-		1,
+		ctrlresourcegraphdefinition.ReconcilerConfig{
+			MaxConcurrentReconciles:         1,
+			MaxConcurrentResourceReconciles: 4,
+			FieldManager:                    "kro",
+		},
 	)
 
 	var err error
@@ -176,7 +187,9 @@ func (e *Environment) setupController() error {
 		return fmt.Errorf("setting up reconciler: %w", err)
 	}
 
+	e.running.Add(1)
 	go func() {
+		defer e.running.Done()
 		if err := e.CtrlManager.Start(e.context); err != nil {
 			panic(fmt.Sprintf("failed to start manager: %v", err))
 		}
@@ -186,8 +199,15 @@ func (e *Environment) setupController() error {
 }
 
 func (e *Environment) Stop() error {
-	e.cancel()
-	time.Sleep(1 * time.Second)
+	if e.cancel != nil {
+		e.cancel()
+	}
+	// Wait for the controllers to exit before stopping the API server they
+	// talk to.
+	e.running.Wait()
+	if e.TestEnv == nil {
+		return nil
+	}
 	return e.TestEnv.Stop()
 }
 